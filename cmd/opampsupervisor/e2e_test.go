@@ -1581,6 +1581,91 @@ func TestSupervisorRemoteConfigApplyStatus(t *testing.T) {
 	}, 5*time.Second, 10*time.Millisecond, "Remote config status was not set to APPLIED for empty config")
 }
 
+func TestSupervisorRejectsInvalidRemoteConfig(t *testing.T) {
+	var agentConfig atomic.Value
+	var healthReport atomic.Value
+	var remoteConfigStatus atomic.Value
+	server := newOpAMPServer(
+		t,
+		defaultConnectingHandler,
+		server.ConnectionCallbacksStruct{
+			OnMessageFunc: func(_ context.Context, _ types.Connection, message *protobufs.AgentToServer) *protobufs.ServerToAgent {
+				if message.EffectiveConfig != nil {
+					config := message.EffectiveConfig.ConfigMap.ConfigMap[""]
+					if config != nil {
+						agentConfig.Store(string(config.Body))
+					}
+				}
+				if message.Health != nil {
+					healthReport.Store(message.Health)
+				}
+				if message.RemoteConfigStatus != nil {
+					remoteConfigStatus.Store(message.RemoteConfigStatus)
+				}
+
+				return &protobufs.ServerToAgent{}
+			},
+		})
+
+	s := newSupervisor(t, "validate_config", map[string]string{
+		"url":                  server.addr,
+		"config_apply_timeout": "3s",
+	})
+	require.Nil(t, s.Start())
+	defer s.Shutdown()
+
+	waitForSupervisorConnection(server.supervisorConnected, true)
+
+	cfg, hash, _, _ := createSimplePipelineCollectorConf(t)
+
+	server.sendToSupervisor(&protobufs.ServerToAgent{
+		RemoteConfig: &protobufs.AgentRemoteConfig{
+			Config: &protobufs.AgentConfigMap{
+				ConfigMap: map[string]*protobufs.AgentConfigFile{
+					"": {Body: cfg.Bytes()},
+				},
+			},
+			ConfigHash: hash,
+		},
+	})
+
+	require.Eventually(t, func() bool {
+		status, ok := remoteConfigStatus.Load().(*protobufs.RemoteConfigStatus)
+		return ok && status.Status == protobufs.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED
+	}, 15*time.Second, 100*time.Millisecond, "Remote config status was not set to APPLIED")
+
+	badCfg, badHash := createBadCollectorConf(t)
+
+	server.sendToSupervisor(&protobufs.ServerToAgent{
+		RemoteConfig: &protobufs.AgentRemoteConfig{
+			Config: &protobufs.AgentConfigMap{
+				ConfigMap: map[string]*protobufs.AgentConfigFile{
+					"": {Body: badCfg.Bytes()},
+				},
+			},
+			ConfigHash: badHash,
+		},
+	})
+
+	// The bad config is rejected without being applied
+	require.Eventually(t, func() bool {
+		status, ok := remoteConfigStatus.Load().(*protobufs.RemoteConfigStatus)
+		return ok && status.Status == protobufs.RemoteConfigStatuses_RemoteConfigStatuses_FAILED &&
+			bytes.Equal(status.LastRemoteConfigHash, badHash) &&
+			strings.Contains(status.ErrorMessage, "Config validation failed")
+	}, 15*time.Second, 100*time.Millisecond, "Remote config status was not set to FAILED for invalid config")
+
+	require.Never(t, func() bool {
+		health, ok := healthReport.Load().(*protobufs.ComponentHealth)
+		return !ok || !health.Healthy
+	}, 3*time.Second, 100*time.Millisecond, "Collector became unhealthy")
+
+	cfgStr, ok := agentConfig.Load().(string)
+	require.True(t, ok)
+	require.Contains(t, cfgStr, "filelog")
+	require.NotContains(t, cfgStr, "doesntexist")
+}
+
 func TestSupervisorOpAmpServerPort(t *testing.T) {
 	var agentConfig atomic.Value
 	server := newOpAMPServer(
@@ -29,6 +29,7 @@ type Supervisor struct {
 	Capabilities Capabilities `mapstructure:"capabilities"`
 	Storage      Storage      `mapstructure:"storage"`
 	Telemetry    Telemetry    `mapstructure:"telemetry"`
+	Packages     Packages     `mapstructure:"packages"`
 }
 
 // Load loads the Supervisor config from a file.
@@ -78,6 +79,12 @@ func (s Supervisor) Validate() error {
 		return err
 	}
 
+	if s.Capabilities.AcceptsPackages {
+		if err := s.Packages.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -91,6 +98,7 @@ type Capabilities struct {
 	AcceptsRemoteConfig            bool `mapstructure:"accepts_remote_config"`
 	AcceptsRestartCommand          bool `mapstructure:"accepts_restart_command"`
 	AcceptsOpAMPConnectionSettings bool `mapstructure:"accepts_opamp_connection_settings"`
+	AcceptsPackages                bool `mapstructure:"accepts_packages"`
 	ReportsEffectiveConfig         bool `mapstructure:"reports_effective_config"`
 	ReportsOwnMetrics              bool `mapstructure:"reports_own_metrics"`
 	ReportsHealth                  bool `mapstructure:"reports_health"`
@@ -128,6 +136,11 @@ func (c Capabilities) SupportedCapabilities() protobufs.AgentCapabilities {
 		supportedCapabilities |= protobufs.AgentCapabilities_AgentCapabilities_AcceptsOpAMPConnectionSettings
 	}
 
+	if c.AcceptsPackages {
+		supportedCapabilities |= protobufs.AgentCapabilities_AgentCapabilities_AcceptsPackages |
+			protobufs.AgentCapabilities_AgentCapabilities_ReportsPackageStatuses
+	}
+
 	return supportedCapabilities
 }
 
@@ -170,6 +183,12 @@ type Agent struct {
 	HealthCheckPort         int              `mapstructure:"health_check_port"`
 	OpAMPServerPort         int              `mapstructure:"opamp_server_port"`
 	PassthroughLogs         bool             `mapstructure:"passthrough_logs"`
+	// ValidateConfig makes the Supervisor validate the merged config with the Collector's
+	// validate command before applying it.
+	ValidateConfig bool `mapstructure:"validate_config"`
+	// RevertConfigOnFailure makes the Supervisor revert to the last known good config when the
+	// Collector is not healthy within ConfigApplyTimeout after applying a new config.
+	RevertConfigOnFailure bool `mapstructure:"revert_config_on_failure"`
 }
 
 func (a Agent) Validate() error {
@@ -205,6 +224,35 @@ func (a Agent) Validate() error {
 	return nil
 }
 
+// Packages is the configuration of the Collector executable updates offered by the OpAMP server.
+type Packages struct {
+	// PublicKeys are paths to PEM encoded public keys used to verify the signature of the downloaded packages.
+	PublicKeys []string `mapstructure:"public_keys"`
+	// AllowUnsigned allows installing packages without verifying their signature when no public key is
+	// configured, so that they are only verified against the content hash sent by the OpAMP server.
+	AllowUnsigned bool `mapstructure:"allow_unsigned"`
+	// HealthCheckTimeout is the time the updated Collector has to become healthy before the update is rolled back.
+	HealthCheckTimeout time.Duration `mapstructure:"health_check_timeout"`
+}
+
+func (p Packages) Validate() error {
+	if len(p.PublicKeys) == 0 && !p.AllowUnsigned {
+		return errors.New("packages::public_keys must be set to verify the signature of the packages, or packages::allow_unsigned enabled")
+	}
+
+	for _, key := range p.PublicKeys {
+		if _, err := os.Stat(key); err != nil {
+			return fmt.Errorf("could not stat packages::public_keys path: %w", err)
+		}
+	}
+
+	if p.HealthCheckTimeout <= 0 {
+		return errors.New("packages::health_check_timeout must be positive")
+	}
+
+	return nil
+}
+
 type AgentDescription struct {
 	IdentifyingAttributes    map[string]string `mapstructure:"identifying_attributes"`
 	NonIdentifyingAttributes map[string]string `mapstructure:"non_identifying_attributes"`
@@ -241,6 +289,7 @@ func DefaultSupervisor() Supervisor {
 			AcceptsRemoteConfig:            false,
 			AcceptsRestartCommand:          false,
 			AcceptsOpAMPConnectionSettings: false,
+			AcceptsPackages:                false,
 			ReportsEffectiveConfig:         true,
 			ReportsOwnMetrics:              true,
 			ReportsHealth:                  true,
@@ -254,6 +303,8 @@ func DefaultSupervisor() Supervisor {
 			ConfigApplyTimeout:      5 * time.Second,
 			BootstrapTimeout:        3 * time.Second,
 			PassthroughLogs:         false,
+			ValidateConfig:          false,
+			RevertConfigOnFailure:   false,
 		},
 		Telemetry: Telemetry{
 			Logs: Logs{
@@ -261,5 +312,8 @@ func DefaultSupervisor() Supervisor {
 				OutputPaths: []string{"stderr"},
 			},
 		},
+		Packages: Packages{
+			HealthCheckTimeout: 30 * time.Second,
+		},
 	}
 }
@@ -422,6 +422,105 @@ func TestValidate(t *testing.T) {
 			},
 			expectedError: "agent::config_apply_timeout must be valid duration",
 		},
+		{
+			name: "Invalid packages health check timeout",
+			config: Supervisor{
+				Server: OpAMPServer{
+					Endpoint: "wss://localhost:9090/opamp",
+				},
+				Agent: Agent{
+					Executable:              "${file_path}",
+					OrphanDetectionInterval: 5 * time.Second,
+					ConfigApplyTimeout:      2 * time.Second,
+					BootstrapTimeout:        5 * time.Second,
+				},
+				Capabilities: Capabilities{
+					AcceptsPackages: true,
+				},
+				Packages: Packages{
+					AllowUnsigned: true,
+				},
+			},
+			expectedError: "packages::health_check_timeout must be positive",
+		},
+		{
+			name: "Packages public key does not exist",
+			config: Supervisor{
+				Server: OpAMPServer{
+					Endpoint: "wss://localhost:9090/opamp",
+				},
+				Agent: Agent{
+					Executable:              "${file_path}",
+					OrphanDetectionInterval: 5 * time.Second,
+					ConfigApplyTimeout:      2 * time.Second,
+					BootstrapTimeout:        5 * time.Second,
+				},
+				Capabilities: Capabilities{
+					AcceptsPackages: true,
+				},
+				Packages: Packages{
+					PublicKeys:         []string{"/does/not/exist.pem"},
+					HealthCheckTimeout: 30 * time.Second,
+				},
+			},
+			expectedError: "could not stat packages::public_keys path:",
+		},
+		{
+			name: "Packages without public keys",
+			config: Supervisor{
+				Server: OpAMPServer{
+					Endpoint: "wss://localhost:9090/opamp",
+				},
+				Agent: Agent{
+					Executable:              "${file_path}",
+					OrphanDetectionInterval: 5 * time.Second,
+					ConfigApplyTimeout:      2 * time.Second,
+					BootstrapTimeout:        5 * time.Second,
+				},
+				Capabilities: Capabilities{
+					AcceptsPackages: true,
+				},
+				Packages: Packages{
+					HealthCheckTimeout: 30 * time.Second,
+				},
+			},
+			expectedError: "packages::public_keys must be set to verify the signature of the packages, or packages::allow_unsigned enabled",
+		},
+		{
+			name: "Unsigned packages allowed",
+			config: Supervisor{
+				Server: OpAMPServer{
+					Endpoint: "wss://localhost:9090/opamp",
+				},
+				Agent: Agent{
+					Executable:              "${file_path}",
+					OrphanDetectionInterval: 5 * time.Second,
+					ConfigApplyTimeout:      2 * time.Second,
+					BootstrapTimeout:        5 * time.Second,
+				},
+				Capabilities: Capabilities{
+					AcceptsPackages: true,
+				},
+				Packages: Packages{
+					AllowUnsigned:      true,
+					HealthCheckTimeout: 30 * time.Second,
+				},
+			},
+		},
+		{
+			name: "Packages are not validated when not accepted",
+			config: Supervisor{
+				Server: OpAMPServer{
+					Endpoint: "wss://localhost:9090/opamp",
+				},
+				Agent: Agent{
+					Executable:              "${file_path}",
+					OrphanDetectionInterval: 5 * time.Second,
+					ConfigApplyTimeout:      2 * time.Second,
+					BootstrapTimeout:        5 * time.Second,
+				},
+			},
+		},
 	}
 
 	// create some fake files for validating agent config
@@ -477,6 +576,7 @@ func TestCapabilities_SupportedCapabilities(t *testing.T) {
 				AcceptsRemoteConfig:            true,
 				AcceptsRestartCommand:          true,
 				AcceptsOpAMPConnectionSettings: true,
+				AcceptsPackages:                true,
 				ReportsEffectiveConfig:         true,
 				ReportsOwnMetrics:              true,
 				ReportsHealth:                  true,
@@ -489,7 +589,9 @@ func TestCapabilities_SupportedCapabilities(t *testing.T) {
 				protobufs.AgentCapabilities_AgentCapabilities_AcceptsRemoteConfig |
 				protobufs.AgentCapabilities_AgentCapabilities_ReportsRemoteConfig |
 				protobufs.AgentCapabilities_AgentCapabilities_AcceptsRestartCommand |
-				protobufs.AgentCapabilities_AgentCapabilities_AcceptsOpAMPConnectionSettings,
+				protobufs.AgentCapabilities_AgentCapabilities_AcceptsOpAMPConnectionSettings |
+				protobufs.AgentCapabilities_AgentCapabilities_AcceptsPackages |
+				protobufs.AgentCapabilities_AgentCapabilities_ReportsPackageStatuses,
 		},
 	}
 
@@ -542,6 +644,7 @@ agent:
 						BootstrapTimeout:        DefaultSupervisor().Agent.BootstrapTimeout,
 					},
 					Telemetry: DefaultSupervisor().Telemetry,
+					Packages:  DefaultSupervisor().Packages,
 				}
 
 				cfgPath := setupSupervisorConfigFile(t, tmpDir, config)
@@ -565,6 +668,7 @@ capabilities:
   reports_remote_config: true
   accepts_restart_command: true
   accepts_opamp_connection_settings: true
+  accepts_packages: true
 
 storage:
   directory: %s
@@ -582,13 +686,19 @@ agent:
   health_check_port: 8089
   opamp_server_port: 8090
   passthrough_logs: true
+  validate_config: true
+  revert_config_on_failure: true
 
 telemetry:
   logs:
     level: warn
     output_paths: ["stdout"]
+
+packages:
+  public_keys: [%s]
+  health_check_timeout: 1m
 `
-				config = fmt.Sprintf(config, filepath.Join(tmpDir, "storage"), executablePath)
+				config = fmt.Sprintf(config, filepath.Join(tmpDir, "storage"), executablePath, executablePath)
 
 				expected := Supervisor{
 					Server: OpAMPServer{
@@ -605,6 +715,7 @@ telemetry:
 						ReportsRemoteConfig:            true,
 						AcceptsRestartCommand:          true,
 						AcceptsOpAMPConnectionSettings: true,
+						AcceptsPackages:                true,
 					},
 					Storage: Storage{
 						Directory: filepath.Join(tmpDir, "storage"),
@@ -625,6 +736,8 @@ telemetry:
 						HealthCheckPort:         8089,
 						OpAMPServerPort:         8090,
 						PassthroughLogs:         true,
+						ValidateConfig:          true,
+						RevertConfigOnFailure:   true,
 					},
 					Telemetry: Telemetry{
 						Logs: Logs{
@@ -632,6 +745,10 @@ telemetry:
 							OutputPaths: []string{"stdout"},
 						},
 					},
+					Packages: Packages{
+						PublicKeys:         []string{executablePath},
+						HealthCheckTimeout: time.Minute,
+					},
 				}
 
 				cfgPath := setupSupervisorConfigFile(t, tmpDir, config)
@@ -661,6 +778,7 @@ agent:
 						BootstrapTimeout:        DefaultSupervisor().Agent.BootstrapTimeout,
 					},
 					Telemetry: DefaultSupervisor().Telemetry,
+					Packages:  DefaultSupervisor().Packages,
 				}
 
 				t.Setenv("TEST_ENDPOINT", "ws://localhost/v1/opamp")
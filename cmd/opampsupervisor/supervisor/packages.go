@@ -0,0 +1,429 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/open-telemetry/opamp-go/client/types"
+	"github.com/open-telemetry/opamp-go/protobufs"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/config"
+)
+
+const (
+	// agentPackageName is the name of the top-level package, which is the Collector executable.
+	agentPackageName = ""
+
+	packagesDirName       = "packages"
+	previousAgentFileName = "collector.previous"
+
+	// stagedAgentCheckTimeout is the time a downloaded Collector executable has to print its version.
+	stagedAgentCheckTimeout = 10 * time.Second
+)
+
+var (
+	errAddonsNotSupported = errors.New("only the top-level Collector package is supported")
+	errUpdateRolledBack   = errors.New("the Collector executable update was rolled back")
+	errShuttingDown       = errors.New("the Supervisor is shutting down")
+)
+
+// installFunc installs the verified Collector executable at the given path.
+type installFunc func(ctx context.Context, stagedPath string) error
+
+var _ types.PackagesStateProvider = (*packageManager)(nil)
+
+// packageManager implements the OpAMP packages state provider for the Collector
+// executable. The state is kept in the Supervisor's persistent state, while the
+// downloaded executables are verified and staged in the packages directory
+// before being handed over to the Supervisor to be installed.
+type packageManager struct {
+	logger          *zap.Logger
+	persistentState *persistentState
+	agentExecutable string
+	dir             string
+	publicKeys      []crypto.PublicKey
+	allowUnsigned   bool
+	install         installFunc
+}
+
+func newPackageManager(
+	logger *zap.Logger,
+	state *persistentState,
+	agentExecutable string,
+	dir string,
+	cfg config.Packages,
+	install installFunc,
+) (*packageManager, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("error creating packages dir: %w", err)
+	}
+
+	publicKeys, err := loadPublicKeys(cfg.PublicKeys)
+	if err != nil {
+		return nil, err
+	}
+	if len(publicKeys) == 0 && cfg.AllowUnsigned {
+		logger.Warn("The signature of the packages is not verified, any executable offered by the OpAMP server will be installed")
+	}
+
+	return &packageManager{
+		logger:          logger,
+		persistentState: state,
+		agentExecutable: agentExecutable,
+		dir:             dir,
+		publicKeys:      publicKeys,
+		allowUnsigned:   cfg.AllowUnsigned,
+		install:         install,
+	}, nil
+}
+
+func loadPublicKeys(paths []string) ([]crypto.PublicKey, error) {
+	keys := make([]crypto.PublicKey, 0, len(paths))
+	for _, path := range paths {
+		by, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key %s: %w", path, err)
+		}
+
+		block, _ := pem.Decode(by)
+		if block == nil {
+			return nil, fmt.Errorf("failed to decode public key %s: no PEM data found", path)
+		}
+
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+		}
+
+		switch key.(type) {
+		case ed25519.PublicKey, *ecdsa.PublicKey, *rsa.PublicKey:
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T in %s", key, path)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (p *packageManager) AllPackagesHash() ([]byte, error) {
+	return p.persistentState.packages().AllPackagesHash, nil
+}
+
+func (p *packageManager) SetAllPackagesHash(hash []byte) error {
+	return p.persistentState.updatePackages(func(state *packagesState) {
+		state.AllPackagesHash = hash
+	})
+}
+
+func (p *packageManager) Packages() ([]string, error) {
+	state := p.persistentState.packages()
+	names := make([]string, 0, len(state.Packages))
+	for name := range state.Packages {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (p *packageManager) PackageState(packageName string) (types.PackageState, error) {
+	pkg, ok := p.persistentState.packages().Packages[packageName]
+	if !ok {
+		return types.PackageState{Exists: false}, nil
+	}
+
+	return types.PackageState{
+		Exists:  true,
+		Type:    protobufs.PackageType(pkg.Type),
+		Hash:    pkg.Hash,
+		Version: pkg.Version,
+	}, nil
+}
+
+func (p *packageManager) SetPackageState(packageName string, pkgState types.PackageState) error {
+	if packageName != agentPackageName {
+		return errAddonsNotSupported
+	}
+
+	return p.persistentState.updatePackages(func(state *packagesState) {
+		if state.Packages == nil {
+			state.Packages = map[string]packageState{}
+		}
+		state.Packages[packageName] = packageState{
+			Type:    int32(pkgState.Type),
+			Hash:    pkgState.Hash,
+			Version: pkgState.Version,
+		}
+	})
+}
+
+func (p *packageManager) CreatePackage(packageName string, typ protobufs.PackageType) error {
+	if packageName != agentPackageName || typ != protobufs.PackageType_PackageType_TopLevel {
+		return errAddonsNotSupported
+	}
+
+	if _, ok := p.persistentState.packages().Packages[packageName]; ok {
+		return fmt.Errorf("package %q already exists", packageName)
+	}
+
+	return p.persistentState.updatePackages(func(state *packagesState) {
+		if state.Packages == nil {
+			state.Packages = map[string]packageState{}
+		}
+		state.Packages[packageName] = packageState{Type: int32(typ)}
+	})
+}
+
+// FileContentHash returns the SHA-256 hash of the Collector executable.
+func (p *packageManager) FileContentHash(packageName string) ([]byte, error) {
+	if packageName != agentPackageName {
+		return nil, nil
+	}
+
+	f, err := os.Open(p.agentExecutable)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// UpdateContent downloads the Collector executable to the packages directory,
+// verifies it and installs it.
+func (p *packageManager) UpdateContent(ctx context.Context, packageName string, data io.Reader, contentHash, signature []byte) error {
+	if packageName != agentPackageName {
+		return errAddonsNotSupported
+	}
+
+	if len(contentHash) == 0 {
+		return errors.New("the package does not have a content hash")
+	}
+
+	if p.persistentState.packages().isBadContentHash(contentHash) {
+		return fmt.Errorf("the Collector executable with content hash %x failed to be installed before", contentHash)
+	}
+
+	stagedPath, err := p.stage(ctx, data, contentHash, signature)
+	if stagedPath != "" {
+		defer os.Remove(stagedPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	p.logger.Info("Installing new Collector executable", zap.String("content_hash", fmt.Sprintf("%x", contentHash)))
+	err = p.install(ctx, stagedPath)
+	if errors.Is(err, errUpdateRolledBack) {
+		if markErr := p.persistentState.updatePackages(func(state *packagesState) {
+			state.BadContentHashes = append(state.BadContentHashes, contentHash)
+		}); markErr != nil {
+			p.logger.Error("Could not mark the Collector executable as bad", zap.Error(markErr))
+		}
+	}
+	return err
+}
+
+// stage writes the data to a file in the packages directory and verifies it,
+// returning the path to the file.
+func (p *packageManager) stage(ctx context.Context, data io.Reader, contentHash, signature []byte) (string, error) {
+	f, err := os.CreateTemp(p.dir, "collector-*.download")
+	if err != nil {
+		return "", fmt.Errorf("failed to create the package file: %w", err)
+	}
+	stagedPath := f.Name()
+
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, h), &contextReader{ctx: ctx, r: data})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return stagedPath, fmt.Errorf("failed to download the package: %w", err)
+	}
+
+	digest := h.Sum(nil)
+	if !bytes.Equal(digest, contentHash) {
+		return stagedPath, fmt.Errorf("the content hash of the package %x does not match the expected hash %x", digest, contentHash)
+	}
+
+	if err := p.verifySignature(digest, signature); err != nil {
+		return stagedPath, err
+	}
+
+	if err := os.Chmod(stagedPath, 0o700); err != nil {
+		return stagedPath, err
+	}
+
+	if err := checkAgentExecutable(ctx, stagedPath); err != nil {
+		return stagedPath, err
+	}
+
+	return stagedPath, nil
+}
+
+// verifySignature verifies that the signature of the SHA-256 digest of the package
+// was made by one of the configured keys. No signature is required if no key is configured
+// and unsigned packages are allowed.
+func (p *packageManager) verifySignature(digest, signature []byte) error {
+	if len(p.publicKeys) == 0 {
+		if p.allowUnsigned {
+			return nil
+		}
+		return errors.New("no public key is configured to verify the signature of the package")
+	}
+
+	if len(signature) == 0 {
+		return errors.New("the package is not signed")
+	}
+
+	for _, key := range p.publicKeys {
+		switch k := key.(type) {
+		case ed25519.PublicKey:
+			if ed25519.Verify(k, digest, signature) {
+				return nil
+			}
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(k, digest, signature) {
+				return nil
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, signature) == nil {
+				return nil
+			}
+		}
+	}
+
+	return errors.New("the signature of the package could not be verified with any of the configured public keys")
+}
+
+// checkAgentExecutable makes sure that the downloaded file can be executed on this system.
+func checkAgentExecutable(ctx context.Context, path string) error {
+	ctx, cancel := context.WithTimeout(ctx, stagedAgentCheckTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, "--version").CombinedOutput() // #nosec G204
+	if err != nil {
+		return fmt.Errorf("the downloaded Collector executable cannot be run: %w: %s", err, out)
+	}
+	return nil
+}
+
+// DeletePackage forgets the state of the package. The Collector executable is kept.
+func (p *packageManager) DeletePackage(packageName string) error {
+	return p.persistentState.updatePackages(func(state *packagesState) {
+		delete(state.Packages, packageName)
+	})
+}
+
+func (p *packageManager) LastReportedStatuses() (*protobufs.PackageStatuses, error) {
+	by := p.persistentState.packages().LastReportedStatuses
+	if len(by) == 0 {
+		return nil, nil
+	}
+
+	statuses := &protobufs.PackageStatuses{}
+	if err := proto.Unmarshal(by, statuses); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+func (p *packageManager) SetLastReportedStatuses(statuses *protobufs.PackageStatuses) error {
+	by, err := proto.Marshal(statuses)
+	if err != nil {
+		return err
+	}
+
+	return p.persistentState.updatePackages(func(state *packagesState) {
+		state.LastReportedStatuses = by
+	})
+}
+
+// contextReader aborts reading once the context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// replaceFile atomically replaces dst with a copy of src, keeping the file mode of dst.
+func replaceFile(src, dst string) error {
+	mode := os.FileMode(0o755)
+	if info, err := os.Stat(dst); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	// The copy is made in the same directory as dst, so that it can be renamed atomically.
+	tmp := dst + ".new"
+	if err := copyFile(src, tmp, mode); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, dst); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to replace %s: %w", dst, err)
+	}
+	return nil
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, in); err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+	}
+
+	// The mode given to OpenFile is not applied to an existing file and is subject to the umask.
+	return os.Chmod(dst, mode)
+}
+
+func packagesDir(storageDir string) string {
+	return filepath.Join(storageDir, packagesDirName)
+}
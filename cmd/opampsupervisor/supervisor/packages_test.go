@@ -0,0 +1,341 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/open-telemetry/opamp-go/client/types"
+	"github.com/open-telemetry/opamp-go/protobufs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/cmd/opampsupervisor/supervisor/config"
+)
+
+const testAgentExecutable = "#!/bin/sh\necho otelcol version 1.0.0\n"
+
+func newTestPackageManager(t *testing.T, cfg config.Packages, install installFunc) (*packageManager, *persistentState) {
+	t.Helper()
+
+	dir := t.TempDir()
+	state, err := createNewPersistentState(filepath.Join(dir, persistentStateFileName))
+	require.NoError(t, err)
+
+	executable := filepath.Join(dir, "otelcol")
+	require.NoError(t, os.WriteFile(executable, []byte("old executable"), 0o700))
+
+	pm, err := newPackageManager(zap.NewNop(), state, executable, packagesDir(dir), cfg, install)
+	require.NoError(t, err)
+	return pm, state
+}
+
+func writePublicKey(t *testing.T, pub ed25519.PublicKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+	return path
+}
+
+func TestPackageManager_State(t *testing.T) {
+	pm, state := newTestPackageManager(t, config.Packages{AllowUnsigned: true}, nil)
+
+	names, err := pm.Packages()
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	pkgState, err := pm.PackageState(agentPackageName)
+	require.NoError(t, err)
+	assert.False(t, pkgState.Exists)
+
+	assert.ErrorIs(t, pm.CreatePackage("addon", protobufs.PackageType_PackageType_Addon), errAddonsNotSupported)
+	require.NoError(t, pm.CreatePackage(agentPackageName, protobufs.PackageType_PackageType_TopLevel))
+	assert.Error(t, pm.CreatePackage(agentPackageName, protobufs.PackageType_PackageType_TopLevel))
+
+	require.NoError(t, pm.SetPackageState(agentPackageName, types.PackageState{
+		Exists:  true,
+		Type:    protobufs.PackageType_PackageType_TopLevel,
+		Hash:    []byte{1, 2, 3},
+		Version: "1.0.0",
+	}))
+	require.NoError(t, pm.SetAllPackagesHash([]byte{4, 5, 6}))
+	require.NoError(t, pm.SetLastReportedStatuses(&protobufs.PackageStatuses{
+		ServerProvidedAllPackagesHash: []byte{4, 5, 6},
+	}))
+
+	// The state is persisted
+	loaded, err := loadPersistentState(state.configPath)
+	require.NoError(t, err)
+	pm.persistentState = loaded
+
+	names, err = pm.Packages()
+	require.NoError(t, err)
+	assert.Equal(t, []string{agentPackageName}, names)
+
+	pkgState, err = pm.PackageState(agentPackageName)
+	require.NoError(t, err)
+	assert.Equal(t, types.PackageState{
+		Exists:  true,
+		Type:    protobufs.PackageType_PackageType_TopLevel,
+		Hash:    []byte{1, 2, 3},
+		Version: "1.0.0",
+	}, pkgState)
+
+	hash, err := pm.AllPackagesHash()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{4, 5, 6}, hash)
+
+	statuses, err := pm.LastReportedStatuses()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{4, 5, 6}, statuses.ServerProvidedAllPackagesHash)
+
+	// Deleting the package does not delete the Collector executable
+	require.NoError(t, pm.DeletePackage(agentPackageName))
+	names, err = pm.Packages()
+	require.NoError(t, err)
+	assert.Empty(t, names)
+	assert.FileExists(t, pm.agentExecutable)
+}
+
+func TestPackageManager_FileContentHash(t *testing.T) {
+	pm, _ := newTestPackageManager(t, config.Packages{AllowUnsigned: true}, nil)
+
+	expected := sha256.Sum256([]byte("old executable"))
+	hash, err := pm.FileContentHash(agentPackageName)
+	require.NoError(t, err)
+	assert.Equal(t, expected[:], hash)
+
+	hash, err = pm.FileContentHash("addon")
+	require.NoError(t, err)
+	assert.Nil(t, hash)
+}
+
+func TestPackageManager_UpdateContent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows because the test executable is a shell script.")
+	}
+
+	content := []byte(testAgentExecutable)
+	digest := sha256.Sum256(content)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signature := ed25519.Sign(priv, digest[:])
+	_, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		publicKeys bool
+		// rejectUnsigned doesn't allow unsigned packages when there are no public keys.
+		rejectUnsigned bool
+		contentHash    []byte
+		signature      []byte
+		installErr     error
+		expectedError  string
+		installed      bool
+		bad            bool
+	}{
+		{
+			name:        "Verified by content hash",
+			contentHash: digest[:],
+			installed:   true,
+		},
+		{
+			name:           "Unsigned packages not allowed",
+			rejectUnsigned: true,
+			contentHash:    digest[:],
+			expectedError:  "no public key is configured to verify the signature of the package",
+		},
+		{
+			name:        "Verified by signature",
+			publicKeys:  true,
+			contentHash: digest[:],
+			signature:   signature,
+			installed:   true,
+		},
+		{
+			name:          "Missing content hash",
+			expectedError: "the package does not have a content hash",
+		},
+		{
+			name:          "Content hash mismatch",
+			contentHash:   []byte{1, 2, 3},
+			expectedError: "does not match the expected hash 010203",
+		},
+		{
+			name:          "Missing signature",
+			publicKeys:    true,
+			contentHash:   digest[:],
+			expectedError: "the package is not signed",
+		},
+		{
+			name:          "Invalid signature",
+			publicKeys:    true,
+			contentHash:   digest[:],
+			signature:     ed25519.Sign(otherPriv, digest[:]),
+			expectedError: "could not be verified",
+		},
+		{
+			name:          "Rolled back update",
+			contentHash:   digest[:],
+			installErr:    errUpdateRolledBack,
+			expectedError: errUpdateRolledBack.Error(),
+			installed:     true,
+			bad:           true,
+		},
+		{
+			name:          "Install failure",
+			contentHash:   digest[:],
+			installErr:    errors.New("stop failed"),
+			expectedError: "stop failed",
+			installed:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.Packages{AllowUnsigned: !tc.rejectUnsigned}
+			if tc.publicKeys {
+				cfg.PublicKeys = []string{writePublicKey(t, pub)}
+			}
+
+			var installed []byte
+			pm, state := newTestPackageManager(t, cfg, func(_ context.Context, stagedPath string) error {
+				var err error
+				installed, err = os.ReadFile(stagedPath)
+				require.NoError(t, err)
+				return tc.installErr
+			})
+
+			err := pm.UpdateContent(context.Background(), agentPackageName, bytes.NewReader(content), tc.contentHash, tc.signature)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.expectedError)
+			}
+
+			if tc.installed {
+				assert.Equal(t, content, installed)
+			} else {
+				assert.Nil(t, installed)
+			}
+			assert.Equal(t, tc.bad, state.packages().isBadContentHash(digest[:]))
+
+			// The staged files are removed
+			entries, err := os.ReadDir(pm.dir)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
+
+func TestPackageManager_UpdateContentBadExecutable(t *testing.T) {
+	pm, _ := newTestPackageManager(t, config.Packages{AllowUnsigned: true}, func(context.Context, string) error {
+		t.Fatal("the executable must not be installed")
+		return nil
+	})
+
+	content := []byte("not an executable")
+	digest := sha256.Sum256(content)
+
+	err := pm.UpdateContent(context.Background(), agentPackageName, bytes.NewReader(content), digest[:], nil)
+	require.ErrorContains(t, err, "the downloaded Collector executable cannot be run")
+
+	// Executables that failed to be installed are not installed again
+	require.NoError(t, pm.persistentState.updatePackages(func(state *packagesState) {
+		state.BadContentHashes = [][]byte{digest[:]}
+	}))
+	err = pm.UpdateContent(context.Background(), agentPackageName, bytes.NewReader(content), digest[:], nil)
+	require.ErrorContains(t, err, "failed to be installed before")
+}
+
+func TestLoadPublicKeys(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keys, err := loadPublicKeys([]string{writePublicKey(t, pub)})
+	require.NoError(t, err)
+	assert.Equal(t, pub, keys[0])
+
+	invalid := filepath.Join(t.TempDir(), "invalid.pem")
+	require.NoError(t, os.WriteFile(invalid, []byte("invalid"), 0o600))
+	_, err = loadPublicKeys([]string{invalid})
+	assert.ErrorContains(t, err, "no PEM data found")
+
+	_, err = loadPublicKeys([]string{filepath.Join(t.TempDir(), "missing.pem")})
+	assert.ErrorContains(t, err, "failed to read public key")
+}
+
+func TestReplaceFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	require.NoError(t, os.WriteFile(src, []byte("new"), 0o600))
+	require.NoError(t, os.WriteFile(dst, []byte("old"), 0o700))
+
+	require.NoError(t, replaceFile(src, dst))
+
+	content, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new"), content)
+	assert.NoFileExists(t, dst+".new")
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(dst)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+	}
+}
+
+func TestSupervisorRecoverPendingAgentUpdate(t *testing.T) {
+	dir := t.TempDir()
+	state, err := createNewPersistentState(filepath.Join(dir, persistentStateFileName))
+	require.NoError(t, err)
+
+	executable := filepath.Join(dir, "otelcol")
+	backup := filepath.Join(dir, previousAgentFileName)
+	require.NoError(t, os.WriteFile(executable, []byte("new executable"), 0o700))
+	require.NoError(t, os.WriteFile(backup, []byte("old executable"), 0o700))
+
+	s := Supervisor{
+		logger:          zap.NewNop(),
+		persistentState: state,
+		config: config.Supervisor{
+			Agent: config.Agent{Executable: executable},
+		},
+	}
+
+	// Nothing to recover
+	require.NoError(t, s.recoverPendingAgentUpdate())
+	assert.FileExists(t, backup)
+
+	require.NoError(t, state.updatePackages(func(state *packagesState) {
+		state.PendingUpdate = &pendingUpdate{BackupPath: backup}
+	}))
+	require.NoError(t, s.recoverPendingAgentUpdate())
+
+	content, err := os.ReadFile(executable)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("old executable"), content)
+	assert.NoFileExists(t, backup)
+
+	loaded, err := loadPersistentState(state.configPath)
+	require.NoError(t, err)
+	assert.Nil(t, loaded.Packages.PendingUpdate)
+}
@@ -4,8 +4,10 @@
 package supervisor
 
 import (
+	"bytes"
 	"errors"
 	"os"
+	"sync"
 
 	"github.com/google/uuid"
 	"gopkg.in/yaml.v3"
@@ -15,16 +17,83 @@ import (
 type persistentState struct {
 	InstanceID uuid.UUID `yaml:"instance_id"`
 
+	// Packages is the state of the packages offered by the OpAMP server.
+	Packages packagesState `yaml:"packages,omitempty"`
+
 	// Path to the config file that the state should be saved to.
 	// This is not marshaled.
 	configPath string `yaml:"-"`
+
+	// mu guards the state, which is updated by the OpAMP client callbacks
+	// and by the agent process loop.
+	mu sync.Mutex
+}
+
+// packagesState is the local state of the packages synced with the OpAMP server.
+type packagesState struct {
+	AllPackagesHash      []byte                  `yaml:"all_packages_hash,omitempty"`
+	Packages             map[string]packageState `yaml:"packages,omitempty"`
+	LastReportedStatuses []byte                  `yaml:"last_reported_statuses,omitempty"`
+	// BadContentHashes are the content hashes of the Collector executables
+	// that failed to be installed and must not be installed again.
+	BadContentHashes [][]byte `yaml:"bad_content_hashes,omitempty"`
+	// PendingUpdate is set while the Collector executable is being updated,
+	// so that the update can be rolled back if the Supervisor stops meanwhile.
+	PendingUpdate *pendingUpdate `yaml:"pending_update,omitempty"`
+}
+
+type packageState struct {
+	Type    int32  `yaml:"type"`
+	Hash    []byte `yaml:"hash"`
+	Version string `yaml:"version"`
+}
+
+type pendingUpdate struct {
+	// BackupPath is the path to the copy of the previous Collector executable.
+	BackupPath string `yaml:"backup_path"`
 }
 
 func (p *persistentState) SetInstanceID(id uuid.UUID) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	p.InstanceID = id
 	return p.writeState()
 }
 
+// packages returns a copy of the packages state.
+func (p *persistentState) packages() packagesState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state := p.Packages
+	state.Packages = make(map[string]packageState, len(p.Packages.Packages))
+	for name, pkg := range p.Packages.Packages {
+		state.Packages[name] = pkg
+	}
+	return state
+}
+
+// updatePackages applies the update to the packages state and persists it.
+func (p *persistentState) updatePackages(update func(state *packagesState)) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	update(&p.Packages)
+	return p.writeState()
+}
+
+// isBadContentHash returns true if the Collector executable with the content hash failed to be installed before.
+func (s packagesState) isBadContentHash(contentHash []byte) bool {
+	for _, hash := range s.BadContentHashes {
+		if bytes.Equal(hash, contentHash) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeState writes the state to disk, the caller must hold the mutex.
 func (p *persistentState) writeState() error {
 	by, err := yaml.Marshal(p)
 	if err != nil {
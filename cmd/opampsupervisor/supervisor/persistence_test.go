@@ -59,3 +59,31 @@ func TestPersistentState_SetInstanceID(t *testing.T) {
 
 	require.Equal(t, newUUID, loadedState.InstanceID)
 }
+
+func TestPersistentState_UpdatePackages(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "state.yaml")
+	state, err := createNewPersistentState(f)
+	require.NoError(t, err)
+
+	expected := packagesState{
+		AllPackagesHash: []byte{0xff, 0x00, 0x01},
+		Packages: map[string]packageState{
+			"": {Type: 0, Hash: []byte{0xde, 0xad}, Version: "1.0.0"},
+		},
+		LastReportedStatuses: []byte{0x0a, 0x80},
+		BadContentHashes:     [][]byte{{0xbe, 0xef}},
+		PendingUpdate:        &pendingUpdate{BackupPath: "/tmp/collector.previous"},
+	}
+	err = state.updatePackages(func(s *packagesState) {
+		*s = expected
+	})
+	require.NoError(t, err)
+
+	loadedState, err := loadPersistentState(f)
+	require.NoError(t, err)
+
+	require.Equal(t, state.InstanceID, loadedState.InstanceID)
+	require.Equal(t, expected, loadedState.packages())
+	require.True(t, loadedState.packages().isBadContentHash([]byte{0xbe, 0xef}))
+	require.False(t, loadedState.packages().isBadContentHash([]byte{0xde, 0xad}))
+}
@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sort"
 	"sync"
@@ -56,13 +57,16 @@ var (
 	//go:embed templates/owntelemetry.yaml
 	ownTelemetryTpl string
 
-	lastRecvRemoteConfigFile     = "last_recv_remote_config.dat"
-	lastRecvOwnMetricsConfigFile = "last_recv_own_metrics_config.dat"
+	lastRecvRemoteConfigFile      = "last_recv_remote_config.dat"
+	lastRecvOwnMetricsConfigFile  = "last_recv_own_metrics_config.dat"
+	lastKnownGoodRemoteConfigFile = "last_known_good_remote_config.dat"
 )
 
 const (
-	persistentStateFileName = "persistent_state.yaml"
-	agentConfigFileName     = "effective.yaml"
+	persistentStateFileName    = "persistent_state.yaml"
+	agentConfigFileName        = "effective.yaml"
+	validationConfigFileName   = "validate.yaml"
+	agentConfigValidateTimeout = 30 * time.Second
 )
 
 const maxBufferedCustomMessages = 10
@@ -80,6 +84,12 @@ func (c *configState) equal(other *configState) bool {
 
 type agentStartStatus string
 
+// agentUpdate is a verified Collector executable to be installed by the agent process loop.
+type agentUpdate struct {
+	stagedPath string
+	result     chan error
+}
+
 var (
 	agentStarting    agentStartStatus = "starting"
 	agentNotStarting agentStartStatus = "notStarting"
@@ -131,11 +141,29 @@ type Supervisor struct {
 	// Final effective config of the Collector.
 	effectiveConfig *atomic.Value
 
-	// Last received remote config.
+	// configMu serializes the composition of the merged config, which happens both on the
+	// messages of the OpAMP server and in the agent process loop when reverting a config.
+	// It guards remoteConfig, effectiveRemoteConfig and lastKnownGoodRemoteConfig.
+	configMu sync.Mutex
+	// Last received remote config, whose status is reported to the OpAMP server.
 	remoteConfig *protobufs.AgentRemoteConfig
+	// Remote config the merged config is composed with: the last received one, or the last
+	// known good one once the last received one has been reverted.
+	effectiveRemoteConfig *protobufs.AgentRemoteConfig
+	// Last remote config the agent was healthy with, used to revert bad remote configs.
+	lastKnownGoodRemoteConfig *protobufs.AgentRemoteConfig
+	// Config state the agent was last started with, only used by the agent process loop.
+	agentCfgState *configState
 
 	// A channel to indicate there is a new config to apply.
 	hasNewConfig chan struct{}
+	// A channel to hand over a new Collector executable to install.
+	agentUpdates chan *agentUpdate
+	// packageManager syncs the Collector executable with the packages offered by the OpAMP server.
+	packageManager *packageManager
+	// agentDescriptionOutdated is true if the Collector executable has changed since the
+	// agent description was last reported to the OpAMP server.
+	agentDescriptionOutdated atomic.Bool
 	// configApplyTimeout is the maximum time to wait for the agent to apply a new config.
 	// After this time passes without the agent reporting health as OK, the agent is considered unhealthy.
 	configApplyTimeout time.Duration
@@ -170,6 +198,7 @@ func NewSupervisor(logger *zap.Logger, cfg config.Supervisor) (*Supervisor, erro
 		logger:                       logger,
 		pidProvider:                  defaultPIDProvider{},
 		hasNewConfig:                 make(chan struct{}, 1),
+		agentUpdates:                 make(chan *agentUpdate),
 		agentConfigOwnMetricsSection: &atomic.Value{},
 		cfgState:                     &atomic.Value{},
 		effectiveConfig:              &atomic.Value{},
@@ -203,6 +232,24 @@ func (s *Supervisor) Start() error {
 		return err
 	}
 
+	if err = s.recoverPendingAgentUpdate(); err != nil {
+		return fmt.Errorf("could not roll back the interrupted Collector executable update: %w", err)
+	}
+
+	if s.config.Capabilities.AcceptsPackages {
+		s.packageManager, err = newPackageManager(
+			s.logger,
+			s.persistentState,
+			s.config.Agent.Executable,
+			packagesDir(s.config.Storage.Directory),
+			s.config.Packages,
+			s.installAgentExecutable,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
 	if err = s.getBootstrapInfo(); err != nil {
 		return fmt.Errorf("could not get bootstrap info from the Collector: %w", err)
 	}
@@ -447,6 +494,9 @@ func (s *Supervisor) startOpAMPClient() error {
 		},
 		Capabilities: s.config.Capabilities.SupportedCapabilities(),
 	}
+	if s.packageManager != nil {
+		settings.PackagesStateProvider = s.packageManager
+	}
 	ad := s.agentDescription.Load().(*protobufs.AgentDescription)
 	if err = s.opampClient.SetAgentDescription(ad); err != nil {
 		return err
@@ -511,6 +561,12 @@ func (s *Supervisor) handleAgentOpAMPMessage(conn serverTypes.Connection, messag
 	s.logger.Debug("Received OpAMP message from the agent")
 	if message.AgentDescription != nil {
 		s.setAgentDescription(message.AgentDescription)
+		if s.agentDescriptionOutdated.Swap(false) {
+			// The Collector executable has changed, report its new description.
+			if err := s.opampClient.SetAgentDescription(s.agentDescription.Load().(*protobufs.AgentDescription)); err != nil {
+				s.logger.Error("Failed to send agent description to OpAMP server", zap.Error(err))
+			}
+		}
 	}
 
 	if message.EffectiveConfig != nil {
@@ -797,12 +853,17 @@ func (s *Supervisor) loadAndWriteInitialMergedConfig() error {
 				s.logger.Error("Cannot parse last received remote config", zap.Error(err))
 			} else {
 				s.remoteConfig = config
+				s.effectiveRemoteConfig = config
 			}
 		case errors.Is(err, os.ErrNotExist):
 			s.logger.Info("No last received remote config found")
 		default:
 			s.logger.Error("error while reading last received config", zap.Error(err))
 		}
+
+		if s.config.Agent.RevertConfigOnFailure {
+			s.loadLastKnownGoodConfig()
+		}
 	} else {
 		s.logger.Debug("Remote config is not supported, will not attempt to load config from fil")
 	}
@@ -823,7 +884,7 @@ func (s *Supervisor) loadAndWriteInitialMergedConfig() error {
 		s.logger.Debug("Own metrics is not supported, will not attempt to load config from file")
 	}
 
-	_, err = s.composeMergedConfig(s.remoteConfig)
+	_, err = s.recomposeMergedConfig()
 	if err != nil {
 		return fmt.Errorf("could not compose initial merged config: %w", err)
 	}
@@ -890,7 +951,7 @@ func (s *Supervisor) setupOwnMetrics(_ context.Context, settings *protobufs.Tele
 	s.agentConfigOwnMetricsSection.Store(cfg.String())
 
 	// Need to recalculate the Agent config so that the metric config is included in it.
-	configChanged, err := s.composeMergedConfig(s.remoteConfig)
+	configChanged, err := s.recomposeMergedConfig()
 	if err != nil {
 		s.logger.Error("Error composing merged config for own metrics. Ignoring agent self metrics config", zap.Error(err))
 		return
@@ -899,10 +960,19 @@ func (s *Supervisor) setupOwnMetrics(_ context.Context, settings *protobufs.Tele
 	return configChanged
 }
 
+// recomposeMergedConfig composes the merged config with the effective remote config, so that
+// a reverted remote config is not applied again.
+func (s *Supervisor) recomposeMergedConfig() (configChanged bool, err error) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	return s.composeMergedConfig(s.effectiveRemoteConfig)
+}
+
 // composeMergedConfig composes the merged config from multiple sources:
 // 1) the remote config from OpAMP Server
 // 2) the own metrics config section
 // 3) the local override config that is hard-coded in the Supervisor.
+// The caller must hold configMu.
 func (s *Supervisor) composeMergedConfig(config *protobufs.AgentRemoteConfig) (configChanged bool, err error) {
 	k := koanf.New("::")
 
@@ -1103,6 +1173,14 @@ func (s *Supervisor) runAgentProcess() {
 	if _, err := os.Stat(s.agentConfigFilePath()); err == nil {
 		// We have an effective config file saved previously. Use it to start the agent.
 		s.logger.Debug("Effective config found, starting agent initial time")
+		s.agentCfgState = s.cfgState.Load().(*configState)
+		if err := s.validateAgentConfig(s.agentCfgState); err != nil {
+			s.logger.Error("Effective config is invalid", zap.Error(err))
+			s.reportConfigStatus(protobufs.RemoteConfigStatuses_RemoteConfigStatuses_FAILED, fmt.Sprintf("Config validation failed: %s", err))
+			if s.revertToLastKnownGoodConfig(s.agentCfgState) {
+				s.writeAgentConfig()
+			}
+		}
 		_, err := s.startAgent()
 		if err != nil {
 			s.logger.Error("starting agent failed", zap.Error(err))
@@ -1126,6 +1204,17 @@ func (s *Supervisor) runAgentProcess() {
 				default:
 				}
 			}
+
+			newCfgState := s.cfgState.Load().(*configState)
+			if err := s.validateAgentConfig(newCfgState); err != nil {
+				s.logger.Error("New agent config is invalid, not applying it", zap.Error(err))
+				s.reportConfigStatus(protobufs.RemoteConfigStatuses_RemoteConfigStatuses_FAILED, fmt.Sprintf("Config validation failed: %s", err))
+				if s.revertToLastKnownGoodConfig(newCfgState) && s.agentConfigChanged() {
+					s.restartAgentWithRevertedConfig()
+				}
+				continue
+			}
+
 			configApplyTimeoutTimer.Reset(s.config.Agent.ConfigApplyTimeout)
 
 			s.logger.Debug("Restarting agent due to new config")
@@ -1141,6 +1230,7 @@ func (s *Supervisor) runAgentProcess() {
 				// not starting agent because of nop config, clear timer
 				configApplyTimeoutTimer.Stop()
 				s.reportConfigStatus(protobufs.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED, "")
+				s.saveLastKnownGoodConfig()
 			}
 
 		case <-s.commander.Exited():
@@ -1181,11 +1271,21 @@ func (s *Supervisor) runAgentProcess() {
 
 		case <-configApplyTimeoutTimer.C:
 			if s.lastHealthFromClient == nil || !s.lastHealthFromClient.Healthy {
-				s.reportConfigStatus(protobufs.RemoteConfigStatuses_RemoteConfigStatuses_FAILED, "Config apply timeout exceeded")
+				if s.revertToLastKnownGoodConfig(s.agentCfgState) {
+					s.reportConfigStatus(protobufs.RemoteConfigStatuses_RemoteConfigStatuses_FAILED, "Config apply timeout exceeded, reverted to the last known good config")
+					s.restartAgentWithRevertedConfig()
+				} else {
+					s.reportConfigStatus(protobufs.RemoteConfigStatuses_RemoteConfigStatuses_FAILED, "Config apply timeout exceeded")
+				}
 			} else {
 				s.reportConfigStatus(protobufs.RemoteConfigStatuses_RemoteConfigStatuses_APPLIED, "")
+				s.saveLastKnownGoodConfig()
 			}
 
+		case update := <-s.agentUpdates:
+			restartTimer.Stop()
+			update.result <- s.updateAgentExecutable(update.stagedPath)
+
 		case <-s.healthCheckTicker.C:
 			s.healthCheck()
 
@@ -1199,19 +1299,310 @@ func (s *Supervisor) runAgentProcess() {
 	}
 }
 
-func (s *Supervisor) stopAgentApplyConfig() {
-	s.logger.Debug("Stopping the agent to apply new config")
-	cfgState := s.cfgState.Load().(*configState)
-	err := s.commander.Stop(context.Background())
+// installAgentExecutable hands over the Collector executable to the agent process loop
+// and waits for it to be installed.
+func (s *Supervisor) installAgentExecutable(ctx context.Context, stagedPath string) error {
+	update := &agentUpdate{
+		stagedPath: stagedPath,
+		result:     make(chan error, 1),
+	}
+
+	select {
+	case s.agentUpdates <- update:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.doneChan:
+		return errShuttingDown
+	}
+
+	return <-update.result
+}
+
+// updateAgentExecutable replaces the Collector executable with the staged one and restarts
+// the agent. The previous executable is restored if the agent does not become healthy.
+func (s *Supervisor) updateAgentExecutable(stagedPath string) error {
+	s.logger.Info("Updating the Collector executable")
+	backupPath := filepath.Join(packagesDir(s.config.Storage.Directory), previousAgentFileName)
+
+	if err := s.commander.Stop(context.Background()); err != nil {
+		s.logger.Error("Could not stop agent process", zap.Error(err))
+	}
+
+	if err := s.backupAgentExecutable(backupPath); err != nil {
+		s.restartAgentAfterUpdate()
+		return err
+	}
+
+	s.agentDescriptionOutdated.Store(true)
+	err := replaceFile(stagedPath, s.config.Agent.Executable)
+	if err == nil {
+		err = s.startAgentAndWaitHealthy()
+	}
+	if err != nil {
+		s.logger.Error("The Collector executable update failed, rolling back", zap.Error(err))
+		if rollbackErr := s.rollbackAgentExecutable(backupPath); rollbackErr != nil {
+			s.logger.Error("Could not roll back the Collector executable", zap.Error(rollbackErr))
+			return errors.Join(err, rollbackErr)
+		}
+		if errors.Is(err, errShuttingDown) {
+			// The executable was not proven to be bad.
+			return err
+		}
+		return fmt.Errorf("%w: %w", errUpdateRolledBack, err)
+	}
+
+	if err := s.clearPendingAgentUpdate(backupPath); err != nil {
+		s.logger.Error("Could not clear the Collector executable update state", zap.Error(err))
+	}
+	s.logger.Info("The Collector executable was updated")
+	return nil
+}
+
+// backupAgentExecutable saves the Collector executable and records the pending update, so that
+// the update can be rolled back even if the Supervisor stops before it completes.
+func (s *Supervisor) backupAgentExecutable(backupPath string) error {
+	info, err := os.Stat(s.config.Agent.Executable)
 	if err != nil {
+		return err
+	}
+
+	if err := copyFile(s.config.Agent.Executable, backupPath, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("could not back up the Collector executable: %w", err)
+	}
+
+	return s.persistentState.updatePackages(func(state *packagesState) {
+		state.PendingUpdate = &pendingUpdate{BackupPath: backupPath}
+	})
+}
+
+// startAgentAndWaitHealthy starts the agent and waits for it to report being healthy.
+func (s *Supervisor) startAgentAndWaitHealthy() error {
+	status, err := s.startAgent()
+	if err != nil {
+		return err
+	}
+	if status == agentNotStarting {
+		// There is no config to run the agent with, so the new executable cannot be checked beyond
+		// having printed its version.
+		s.logger.Info("The agent is not running, skipping the health check of the new Collector executable")
+		return nil
+	}
+
+	timeout := time.NewTimer(s.config.Packages.HealthCheckTimeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			lastErr = s.healthChecker.Check(ctx)
+			cancel()
+			if lastErr == nil {
+				return nil
+			}
+		case <-s.commander.Exited():
+			return fmt.Errorf("the agent exited with exit code %d", s.commander.ExitCode())
+		case <-timeout.C:
+			return fmt.Errorf("the agent did not become healthy within %s: %w", s.config.Packages.HealthCheckTimeout, lastErr)
+		case <-s.doneChan:
+			return errShuttingDown
+		}
+	}
+}
+
+// rollbackAgentExecutable restores the previous Collector executable and restarts the agent.
+func (s *Supervisor) rollbackAgentExecutable(backupPath string) error {
+	if err := s.commander.Stop(context.Background()); err != nil {
 		s.logger.Error("Could not stop agent process", zap.Error(err))
 	}
 
+	if err := replaceFile(backupPath, s.config.Agent.Executable); err != nil {
+		return err
+	}
+
+	if err := s.clearPendingAgentUpdate(backupPath); err != nil {
+		s.logger.Error("Could not clear the Collector executable update state", zap.Error(err))
+	}
+
+	s.restartAgentAfterUpdate()
+	return nil
+}
+
+func (s *Supervisor) restartAgentAfterUpdate() {
+	select {
+	case <-s.doneChan:
+		return
+	default:
+	}
+
+	if _, err := s.startAgent(); err != nil {
+		s.logger.Error("Could not start agent after updating the Collector executable", zap.Error(err))
+	}
+}
+
+func (s *Supervisor) clearPendingAgentUpdate(backupPath string) error {
+	if err := s.persistentState.updatePackages(func(state *packagesState) {
+		state.PendingUpdate = nil
+	}); err != nil {
+		return err
+	}
+
+	if err := os.Remove(backupPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// recoverPendingAgentUpdate restores the previous Collector executable if the Supervisor stopped
+// while the executable was being updated.
+func (s *Supervisor) recoverPendingAgentUpdate() error {
+	pending := s.persistentState.packages().PendingUpdate
+	if pending == nil {
+		return nil
+	}
+
+	s.logger.Warn("The Collector executable update was interrupted, restoring the previous executable")
+	if err := replaceFile(pending.BackupPath, s.config.Agent.Executable); err != nil {
+		return err
+	}
+
+	return s.clearPendingAgentUpdate(pending.BackupPath)
+}
+
+// validateAgentConfig runs the Collector's validate command with a merged config,
+// so that invalid configs are not applied.
+func (s *Supervisor) validateAgentConfig(cfgState *configState) error {
+	if !s.config.Agent.ValidateConfig || cfgState.configMapIsEmpty {
+		return nil
+	}
+
+	path := filepath.Join(s.config.Storage.Directory, validationConfigFileName)
+	if err := os.WriteFile(path, []byte(cfgState.mergedConfig), 0o600); err != nil {
+		return fmt.Errorf("failed to write config to validate: %w", err)
+	}
+	defer os.Remove(path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), agentConfigValidateTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, s.config.Agent.Executable, "validate", "--config", path).CombinedOutput() // #nosec G204
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// saveLastKnownGoodConfig records the effective remote config as the one to revert to,
+// once the agent has been healthy with it.
+func (s *Supervisor) saveLastKnownGoodConfig() {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	if !s.config.Agent.RevertConfigOnFailure || s.effectiveRemoteConfig == nil {
+		return
+	}
+
+	cfg, err := proto.Marshal(s.effectiveRemoteConfig)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(s.config.Storage.Directory, lastKnownGoodRemoteConfigFile), cfg, 0o600)
+	}
+	if err != nil {
+		s.logger.Error("Could not save last known good remote config", zap.Error(err))
+		return
+	}
+	s.lastKnownGoodRemoteConfig = s.effectiveRemoteConfig
+}
+
+func (s *Supervisor) loadLastKnownGoodConfig() {
+	cfg, err := os.ReadFile(filepath.Join(s.config.Storage.Directory, lastKnownGoodRemoteConfigFile))
+	switch {
+	case err == nil:
+		lastKnownGood := &protobufs.AgentRemoteConfig{}
+		if err = proto.Unmarshal(cfg, lastKnownGood); err != nil {
+			s.logger.Error("Cannot parse last known good remote config", zap.Error(err))
+			return
+		}
+		s.lastKnownGoodRemoteConfig = lastKnownGood
+	case errors.Is(err, os.ErrNotExist):
+		s.logger.Debug("No last known good remote config found")
+	default:
+		s.logger.Error("error while reading last known good remote config", zap.Error(err))
+	}
+}
+
+// revertToLastKnownGoodConfig composes the agent config from the last known good remote config
+// in place of the failed config state, returning false if there is no config to revert to or if
+// a new config has been composed since the failed one. The remote config that failed is kept
+// for reporting its status, while the last known good one becomes the effective remote config,
+// which the merged config is composed with from then on, and is saved as the last received
+// config, so that it is used when the Supervisor restarts.
+func (s *Supervisor) revertToLastKnownGoodConfig(failed *configState) bool {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	lastKnownGood := s.lastKnownGoodRemoteConfig
+	if !s.config.Agent.RevertConfigOnFailure || lastKnownGood == nil {
+		return false
+	}
+	if failed == nil || !failed.equal(s.cfgState.Load().(*configState)) {
+		// A new config was received in the meantime, it is applied instead.
+		s.logger.Debug("Not reverting to the last known good remote config, the config has changed since it failed")
+		return false
+	}
+	if bytes.Equal(lastKnownGood.GetConfigHash(), s.effectiveRemoteConfig.GetConfigHash()) {
+		// The failing config is the last known good one, there is nothing better to revert to.
+		return false
+	}
+
+	s.logger.Info("Reverting to the last known good remote config", zap.String("hash", fmt.Sprintf("%x", lastKnownGood.GetConfigHash())))
+	if err := s.saveLastReceivedConfig(lastKnownGood); err != nil {
+		s.logger.Error("Could not save last received remote config", zap.Error(err))
+	}
+
+	if _, err := s.composeMergedConfig(lastKnownGood); err != nil {
+		s.logger.Error("Error composing merged config with the last known good remote config", zap.Error(err))
+		return false
+	}
+	s.effectiveRemoteConfig = lastKnownGood
+	return true
+}
+
+// agentConfigChanged returns true if the merged config differs from the config the agent runs with.
+func (s *Supervisor) agentConfigChanged() bool {
+	cfg, err := os.ReadFile(s.agentConfigFilePath())
+	if err != nil {
+		return true
+	}
+	return string(cfg) != s.cfgState.Load().(*configState).mergedConfig
+}
+
+func (s *Supervisor) restartAgentWithRevertedConfig() {
+	s.stopAgentApplyConfig()
+	if _, err := s.startAgent(); err != nil {
+		s.logger.Error("starting agent with the last known good config failed", zap.Error(err))
+	}
+}
+
+func (s *Supervisor) writeAgentConfig() {
+	cfgState := s.cfgState.Load().(*configState)
+	s.agentCfgState = cfgState
 	if err := os.WriteFile(s.agentConfigFilePath(), []byte(cfgState.mergedConfig), 0o600); err != nil {
 		s.logger.Error("Failed to write agent config.", zap.Error(err))
 	}
 }
 
+func (s *Supervisor) stopAgentApplyConfig() {
+	s.logger.Debug("Stopping the agent to apply new config")
+	err := s.commander.Stop(context.Background())
+	if err != nil {
+		s.logger.Error("Could not stop agent process", zap.Error(err))
+	}
+
+	s.writeAgentConfig()
+}
+
 func (s *Supervisor) Shutdown() {
 	s.logger.Debug("Supervisor shutting down...")
 	close(s.doneChan)
@@ -1272,12 +1663,18 @@ func (s *Supervisor) saveLastReceivedOwnTelemetrySettings(set *protobufs.Telemet
 	return os.WriteFile(filepath.Join(s.config.Storage.Directory, filePath), cfg, 0o600)
 }
 
+func (s *Supervisor) remoteConfigHash() []byte {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	return s.remoteConfig.GetConfigHash()
+}
+
 func (s *Supervisor) reportConfigStatus(status protobufs.RemoteConfigStatuses, errorMessage string) {
 	if !s.config.Capabilities.ReportsRemoteConfig {
 		s.logger.Debug("supervisor is not configured to report remote config status")
 	}
 	err := s.opampClient.SetRemoteConfigStatus(&protobufs.RemoteConfigStatus{
-		LastRemoteConfigHash: s.remoteConfig.GetConfigHash(),
+		LastRemoteConfigHash: s.remoteConfigHash(),
 		Status:               status,
 		ErrorMessage:         errorMessage,
 	})
@@ -1332,6 +1729,12 @@ func (s *Supervisor) onMessage(ctx context.Context, msg *types.MessageData) {
 		haveMessageForAgent = true
 	}
 
+	if msg.PackageSyncer != nil {
+		if err := msg.PackageSyncer.Sync(ctx); err != nil {
+			s.logger.Error("Could not sync the packages offered by the server", zap.Error(err))
+		}
+	}
+
 	// Send any messages that need proxying to the agent.
 	if haveMessageForAgent {
 		conn, ok := s.agentConn.Load().(serverTypes.Connection)
@@ -1350,11 +1753,13 @@ func (s *Supervisor) processRemoteConfigMessage(msg *protobufs.AgentRemoteConfig
 		s.logger.Error("Could not save last received remote config", zap.Error(err))
 	}
 
-	s.remoteConfig = msg
-	s.logger.Debug("Received remote config from server", zap.String("hash", fmt.Sprintf("%x", s.remoteConfig.ConfigHash)))
+	s.logger.Debug("Received remote config from server", zap.String("hash", fmt.Sprintf("%x", msg.ConfigHash)))
 
-	var err error
-	configChanged, err := s.composeMergedConfig(s.remoteConfig)
+	s.configMu.Lock()
+	s.remoteConfig = msg
+	s.effectiveRemoteConfig = msg
+	configChanged, err := s.composeMergedConfig(s.effectiveRemoteConfig)
+	s.configMu.Unlock()
 	if err != nil {
 		s.logger.Error("Error composing merged config. Reporting failed remote config status.", zap.Error(err))
 		s.reportConfigStatus(protobufs.RemoteConfigStatuses_RemoteConfigStatuses_FAILED, err.Error())
@@ -1396,7 +1801,7 @@ func (s *Supervisor) processAgentIdentificationMessage(msg *protobufs.AgentIdent
 	}
 
 	// Need to recalculate the Agent config so that the new agent identification is included in it.
-	configChanged, err := s.composeMergedConfig(s.remoteConfig)
+	configChanged, err := s.recomposeMergedConfig()
 	if err != nil {
 		s.logger.Error("Error composing merged config with new instance ID", zap.Error(err))
 		return false
@@ -6,6 +6,7 @@ package supervisor
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"net"
 	"os"
@@ -13,6 +14,7 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -1395,3 +1397,201 @@ capabilities:
 		require.NoError(t, os.RemoveAll(tmpDir))
 	})
 }
+
+func newConfigTestSupervisor(t *testing.T, agentCfg config.Agent) *Supervisor {
+	t.Helper()
+
+	s := &Supervisor{
+		logger:          zap.NewNop(),
+		persistentState: &persistentState{},
+		config: config.Supervisor{
+			Capabilities: config.Capabilities{AcceptsRemoteConfig: true},
+			Storage:      config.Storage{Directory: t.TempDir()},
+			Agent:        agentCfg,
+		},
+		pidProvider:                  staticPIDProvider(1234),
+		hasNewConfig:                 make(chan struct{}, 1),
+		agentConfigOwnMetricsSection: &atomic.Value{},
+		cfgState:                     &atomic.Value{},
+		agentHealthCheckEndpoint:     "localhost:8000",
+		agentDescription:             &atomic.Value{},
+	}
+	s.agentDescription.Store(&protobufs.AgentDescription{})
+	require.NoError(t, s.createTemplates())
+	return s
+}
+
+func remoteConfigWithBody(body string) *protobufs.AgentRemoteConfig {
+	hash := sha256.Sum256([]byte(body))
+	return &protobufs.AgentRemoteConfig{
+		Config: &protobufs.AgentConfigMap{
+			ConfigMap: map[string]*protobufs.AgentConfigFile{
+				"": {Body: []byte(body)},
+			},
+		},
+		ConfigHash: hash[:],
+	}
+}
+
+func TestSupervisor_validateAgentConfig(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows because the test executable is a shell script.")
+	}
+
+	executable := filepath.Join(t.TempDir(), "otelcol")
+	require.NoError(t, os.WriteFile(executable, []byte(`#!/bin/sh
+if [ "$1" != "validate" ]; then exit 2; fi
+if grep -q doesntexist "$3"; then echo "unknown type: doesntexist"; exit 1; fi
+`), 0o700))
+
+	t.Run("Valid config", func(t *testing.T) {
+		s := newConfigTestSupervisor(t, config.Agent{Executable: executable, ValidateConfig: true})
+		_, err := s.composeMergedConfig(remoteConfigWithBody("receivers:\n  nop:\n"))
+		require.NoError(t, err)
+		require.NoError(t, s.validateAgentConfig(s.cfgState.Load().(*configState)))
+		require.NoFileExists(t, filepath.Join(s.config.Storage.Directory, validationConfigFileName))
+	})
+
+	t.Run("Invalid config", func(t *testing.T) {
+		s := newConfigTestSupervisor(t, config.Agent{Executable: executable, ValidateConfig: true})
+		_, err := s.composeMergedConfig(remoteConfigWithBody("receivers:\n  doesntexist:\n"))
+		require.NoError(t, err)
+		require.ErrorContains(t, s.validateAgentConfig(s.cfgState.Load().(*configState)), "unknown type: doesntexist")
+	})
+
+	t.Run("Validation disabled", func(t *testing.T) {
+		s := newConfigTestSupervisor(t, config.Agent{Executable: executable})
+		_, err := s.composeMergedConfig(remoteConfigWithBody("receivers:\n  doesntexist:\n"))
+		require.NoError(t, err)
+		require.NoError(t, s.validateAgentConfig(s.cfgState.Load().(*configState)))
+	})
+
+	t.Run("Empty config is not validated", func(t *testing.T) {
+		s := newConfigTestSupervisor(t, config.Agent{Executable: filepath.Join(t.TempDir(), "missing"), ValidateConfig: true})
+		_, err := s.composeMergedConfig(&protobufs.AgentRemoteConfig{})
+		require.NoError(t, err)
+		require.NoError(t, s.validateAgentConfig(s.cfgState.Load().(*configState)))
+	})
+}
+
+func TestSupervisor_revertToLastKnownGoodConfig(t *testing.T) {
+	goodConfig := remoteConfigWithBody("receivers:\n  nop/good:\n")
+	badConfig := remoteConfigWithBody("receivers:\n  nop/bad:\n")
+
+	s := newConfigTestSupervisor(t, config.Agent{RevertConfigOnFailure: true})
+
+	// Nothing to revert to yet
+	s.remoteConfig = goodConfig
+	s.effectiveRemoteConfig = goodConfig
+	_, err := s.composeMergedConfig(goodConfig)
+	require.NoError(t, err)
+	require.False(t, s.revertToLastKnownGoodConfig(s.cfgState.Load().(*configState)))
+
+	s.saveLastKnownGoodConfig()
+	require.FileExists(t, filepath.Join(s.config.Storage.Directory, lastKnownGoodRemoteConfigFile))
+
+	// The last known good config is not reverted to when it is the failing one
+	require.False(t, s.revertToLastKnownGoodConfig(s.cfgState.Load().(*configState)))
+
+	s.remoteConfig = badConfig
+	s.effectiveRemoteConfig = badConfig
+	require.NoError(t, s.saveLastReceivedConfig(badConfig))
+	_, err = s.composeMergedConfig(badConfig)
+	require.NoError(t, err)
+	require.Contains(t, s.cfgState.Load().(*configState).mergedConfig, "nop/bad")
+
+	failed := s.cfgState.Load().(*configState)
+	require.True(t, s.revertToLastKnownGoodConfig(failed))
+	mergedConfig := s.cfgState.Load().(*configState).mergedConfig
+	require.Contains(t, mergedConfig, "nop/good")
+	require.NotContains(t, mergedConfig, "nop/bad")
+	// The failed config is still the one reported to the server
+	require.Equal(t, badConfig, s.remoteConfig)
+	require.Equal(t, goodConfig, s.effectiveRemoteConfig)
+
+	require.True(t, s.agentConfigChanged())
+	s.writeAgentConfig()
+	require.False(t, s.agentConfigChanged())
+
+	// The last known good config is used after a restart
+	lastRecv, err := os.ReadFile(filepath.Join(s.config.Storage.Directory, lastRecvRemoteConfigFile))
+	require.NoError(t, err)
+	lastRecvConfig := &protobufs.AgentRemoteConfig{}
+	require.NoError(t, proto.Unmarshal(lastRecv, lastRecvConfig))
+	require.True(t, proto.Equal(goodConfig, lastRecvConfig))
+
+	restarted := newConfigTestSupervisor(t, config.Agent{RevertConfigOnFailure: true})
+	restarted.config.Storage = s.config.Storage
+	restarted.loadLastKnownGoodConfig()
+	require.True(t, proto.Equal(goodConfig, restarted.lastKnownGoodRemoteConfig))
+
+	// Reverting is disabled
+	s.config.Agent.RevertConfigOnFailure = false
+	require.False(t, s.revertToLastKnownGoodConfig(s.cfgState.Load().(*configState)))
+}
+
+func TestSupervisor_recomposeMergedConfigAfterRevert(t *testing.T) {
+	goodConfig := remoteConfigWithBody("receivers:\n  nop/good:\n")
+	badConfig := remoteConfigWithBody("receivers:\n  nop/bad:\n")
+
+	s := newConfigTestSupervisor(t, config.Agent{RevertConfigOnFailure: true})
+	_, err := s.composeMergedConfig(goodConfig)
+	require.NoError(t, err)
+	s.remoteConfig = goodConfig
+	s.effectiveRemoteConfig = goodConfig
+	s.saveLastKnownGoodConfig()
+	s.remoteConfig = badConfig
+	s.effectiveRemoteConfig = badConfig
+	_, err = s.composeMergedConfig(badConfig)
+	require.NoError(t, err)
+	require.True(t, s.revertToLastKnownGoodConfig(s.cfgState.Load().(*configState)))
+
+	// Setting up the own metrics recomposes the merged config, which must not bring the reverted config back.
+	s.setupOwnMetrics(context.Background(), &protobufs.TelemetryConnectionSettings{})
+	mergedConfig := s.cfgState.Load().(*configState).mergedConfig
+	require.Contains(t, mergedConfig, "nop/good")
+	require.NotContains(t, mergedConfig, "nop/bad")
+
+	// The same goes for a new instance ID.
+	_, err = s.recomposeMergedConfig()
+	require.NoError(t, err)
+	require.Contains(t, s.cfgState.Load().(*configState).mergedConfig, "nop/good")
+	require.Equal(t, badConfig, s.remoteConfig)
+}
+
+func TestSupervisor_revertToLastKnownGoodConfigNewConfig(t *testing.T) {
+	goodConfig := remoteConfigWithBody("receivers:\n  nop/good:\n")
+	badConfig := remoteConfigWithBody("receivers:\n  nop/bad:\n")
+	newConfig := remoteConfigWithBody("receivers:\n  nop/new:\n")
+
+	for i := 0; i < 20; i++ {
+		s := newConfigTestSupervisor(t, config.Agent{RevertConfigOnFailure: true})
+		s.opampClient = client.NewHTTP(newLoggerFromZap(zap.NewNop(), "opamp-client"))
+		s.remoteConfig = goodConfig
+		s.effectiveRemoteConfig = goodConfig
+		_, err := s.composeMergedConfig(goodConfig)
+		require.NoError(t, err)
+		s.saveLastKnownGoodConfig()
+		s.remoteConfig = badConfig
+		s.effectiveRemoteConfig = badConfig
+		_, err = s.composeMergedConfig(badConfig)
+		require.NoError(t, err)
+		failed := s.cfgState.Load().(*configState)
+
+		// A new config received while the failed one is reverted is never overwritten by the revert.
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			s.revertToLastKnownGoodConfig(failed)
+		}()
+		go func() {
+			defer wg.Done()
+			s.processRemoteConfigMessage(newConfig)
+		}()
+		wg.Wait()
+
+		require.Contains(t, s.cfgState.Load().(*configState).mergedConfig, "nop/new")
+		require.False(t, s.revertToLastKnownGoodConfig(failed))
+	}
+}
@@ -474,6 +474,8 @@ func TestLogsConnectorDetailed(t *testing.T) {
 	idSinkD := pipeline.NewIDWithName(pipeline.SignalLogs, "default")
 
 	isAcme := `request["X-Tenant"] == "acme"`
+	isAcmeTenant := `auth["tenant.id"] == "acme"`
+	isAcmeAuthHeader := `request["auth.tenant.id"] == "acme"`
 
 	isResourceA := `attributes["resourceName"] == "resourceA"`
 	isResourceB := `attributes["resourceName"] == "resourceB"`
@@ -589,6 +591,48 @@ func TestLogsConnectorDetailed(t *testing.T) {
 			expectSink1: plog.Logs{},
 			expectSinkD: plogutiltest.NewLogs("AB", "CD", "EF"),
 		},
+		{
+			name: "request/match_auth_value",
+			cfg: testConfig(
+				withRoute("request", isAcmeTenant, idSink0),
+				withDefault(idSinkD),
+			),
+			ctx: withAuthData(
+				withHTTPMetadata(context.Background(), map[string][]string{"auth.tenant.id": {"notacme"}}),
+				map[string]any{"tenant.id": "acme"},
+			),
+			input:       plogutiltest.NewLogs("AB", "CD", "EF"),
+			expectSink0: plogutiltest.NewLogs("AB", "CD", "EF"),
+			expectSink1: plog.Logs{},
+			expectSinkD: plog.Logs{},
+		},
+		{
+			name: "request/match_no_auth_value",
+			cfg: testConfig(
+				withRoute("request", isAcmeTenant, idSink0),
+				withDefault(idSinkD),
+			),
+			ctx:         withHTTPMetadata(context.Background(), map[string][]string{"auth.tenant.id": {"acme"}}),
+			input:       plogutiltest.NewLogs("AB", "CD", "EF"),
+			expectSink0: plog.Logs{},
+			expectSink1: plog.Logs{},
+			expectSinkD: plogutiltest.NewLogs("AB", "CD", "EF"),
+		},
+		{
+			name: "request/match_auth_prefixed_metadata",
+			cfg: testConfig(
+				withRoute("request", isAcmeAuthHeader, idSink0),
+				withDefault(idSinkD),
+			),
+			ctx: withAuthData(
+				withHTTPMetadata(context.Background(), map[string][]string{"auth.tenant.id": {"acme"}}),
+				map[string]any{"tenant.id": "notacme"},
+			),
+			input:       plogutiltest.NewLogs("AB", "CD", "EF"),
+			expectSink0: plogutiltest.NewLogs("AB", "CD", "EF"),
+			expectSink1: plog.Logs{},
+			expectSinkD: plog.Logs{},
+		},
 		{
 			name: "resource/all_match_first_only",
 			cfg: testConfig(
@@ -17,16 +17,21 @@ import (
 // but it's not clear that anything more than a simple comparison is needed.  We can expand this grammar in the
 // future if needed. For now, it expects the condition to be in exactly the format:
 // 'request["<name>"] <comparator> <value>' where <comparator> is either '==' or '!='.
+// The attributes of the auth data of the request are compared instead of its metadata with
+// 'auth["<name>"] <comparator> <value>', such as 'auth["tenant.id"] == "acme"'.
 
 var (
 	requestFieldRegex = regexp.MustCompile(`request\[".*"\]`)
+	authFieldRegex    = regexp.MustCompile(`auth\[".*"\]`)
 	valueFieldRegex   = regexp.MustCompile(`".*"`)
 	comparatorRegex   = regexp.MustCompile(`==|!=`)
 )
 
 type requestCondition struct {
 	attributeName string
-	compareFunc   func(string) bool
+	// auth is true when the condition is on the auth data of the request rather than its metadata.
+	auth        bool
+	compareFunc func(string) bool
 }
 
 func parseRequestCondition(condition string) (*requestCondition, error) {
@@ -52,7 +57,15 @@ func parseRequestCondition(condition string) (*requestCondition, error) {
 	parts[0] = strings.TrimSpace(parts[0])
 	parts[1] = strings.TrimSpace(parts[1])
 
-	if !requestFieldRegex.MatchString(parts[0]) {
+	var attributeName string
+	var auth bool
+	switch {
+	case requestFieldRegex.MatchString(parts[0]):
+		attributeName = strings.TrimSuffix(strings.TrimPrefix(parts[0], `request["`), `"]`)
+	case authFieldRegex.MatchString(parts[0]):
+		attributeName = strings.TrimSuffix(strings.TrimPrefix(parts[0], `auth["`), `"]`)
+		auth = true
+	default:
 		return nil, errors.New(`condition must have format 'request["<name>"] <comparator> <value>'`)
 	}
 	if !valueFieldRegex.MatchString(parts[1]) {
@@ -70,12 +83,16 @@ func parseRequestCondition(condition string) (*requestCondition, error) {
 	}
 
 	return &requestCondition{
-		attributeName: strings.TrimSuffix(strings.TrimPrefix(parts[0], `request["`), `"]`),
+		attributeName: attributeName,
+		auth:          auth,
 		compareFunc:   compareFunc,
 	}, nil
 }
 
 func (rc *requestCondition) matchRequest(ctx context.Context) bool {
+	if rc.auth {
+		return rc.matchAuth(ctx)
+	}
 	return rc.matchGRPC(ctx) || rc.matchHTTP(ctx)
 }
 
@@ -105,3 +122,23 @@ func (rc *requestCondition) matchHTTP(ctx context.Context) bool {
 	}
 	return false
 }
+
+func (rc *requestCondition) matchAuth(ctx context.Context) bool {
+	authData := client.FromContext(ctx).Auth
+	if authData == nil {
+		return false
+	}
+	var values []string
+	switch attr := authData.GetAttribute(rc.attributeName).(type) {
+	case string:
+		values = []string{attr}
+	case []string:
+		values = attr
+	}
+	for _, value := range values {
+		if rc.compareFunc(value) {
+			return true
+		}
+	}
+	return false
+}
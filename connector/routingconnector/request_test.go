@@ -17,3 +17,23 @@ func withGRPCMetadata(ctx context.Context, md map[string]string) context.Context
 func withHTTPMetadata(ctx context.Context, md map[string][]string) context.Context {
 	return client.NewContext(ctx, client.Info{Metadata: client.NewMetadata(md)})
 }
+
+type testAuthData map[string]any
+
+func (a testAuthData) GetAttribute(name string) any {
+	return a[name]
+}
+
+func (a testAuthData) GetAttributeNames() []string {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	return names
+}
+
+func withAuthData(ctx context.Context, attributes map[string]any) context.Context {
+	cl := client.FromContext(ctx)
+	cl.Auth = testAuthData(attributes)
+	return client.NewContext(ctx, cl)
+}
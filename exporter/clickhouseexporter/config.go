@@ -14,6 +14,7 @@ import (
 	"go.opentelemetry.io/collector/config/configopaque"
 	"go.opentelemetry.io/collector/config/configretry"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/clickhouseexporter/internal"
 )
@@ -60,6 +61,12 @@ type Config struct {
 	AsyncInsert bool `mapstructure:"async_insert"`
 	// MetricsTables defines the table names for metric types.
 	MetricsTables MetricTablesConfig `mapstructure:"metrics_tables"`
+	// MigrationsTableName is the table recording the schema migrations applied to the tables. default is `otel_schema_migrations`.
+	MigrationsTableName string `mapstructure:"migrations_table_name"`
+	// LogsPromotedColumns defines typed columns materialized from the attributes of the logs table.
+	LogsPromotedColumns []internal.PromotedColumn `mapstructure:"logs_promoted_columns"`
+	// TracesPromotedColumns defines typed columns materialized from the attributes of the traces table.
+	TracesPromotedColumns []internal.PromotedColumn `mapstructure:"traces_promoted_columns"`
 }
 
 type MetricTablesConfig struct {
@@ -85,6 +92,7 @@ const (
 	defaultDatabase           = "default"
 	defaultTableEngineName    = "MergeTree"
 	defaultMetricTableName    = "otel_metrics"
+	defaultMigrationsTable    = "otel_schema_migrations"
 	defaultGaugeSuffix        = "_gauge"
 	defaultSumSuffix          = "_sum"
 	defaultSummarySuffix      = "_summary"
@@ -95,6 +103,7 @@ const (
 var (
 	errConfigNoEndpoint      = errors.New("endpoint must be specified")
 	errConfigInvalidEndpoint = errors.New("endpoint must be url format")
+	errConfigNoMigrations    = errors.New("migrations_table_name must be specified")
 )
 
 // Validate the ClickHouse server configuration.
@@ -109,6 +118,11 @@ func (cfg *Config) Validate() (err error) {
 
 	cfg.buildMetricTableNames()
 
+	if cfg.CreateSchema && cfg.MigrationsTableName == "" {
+		err = errors.Join(err, errConfigNoMigrations)
+	}
+	err = errors.Join(err, cfg.validatePromotedColumns())
+
 	// Validate DSN with clickhouse driver.
 	// Last chance to catch invalid config.
 	if _, e := clickhouse.ParseDSN(dsn); e != nil {
@@ -184,6 +198,31 @@ func (cfg *Config) shouldCreateSchema() bool {
 	return cfg.CreateSchema
 }
 
+func (cfg *Config) validatePromotedColumns() (err error) {
+	if e := internal.ValidatePromotedColumns(cfg.LogsPromotedColumns, logsAttributeSources); e != nil {
+		err = errors.Join(err, fmt.Errorf("logs_promoted_columns: %w", e))
+	}
+	if e := internal.ValidatePromotedColumns(cfg.TracesPromotedColumns, tracesAttributeSources); e != nil {
+		err = errors.Join(err, fmt.Errorf("traces_promoted_columns: %w", e))
+	}
+	metricsTables := []struct {
+		name   string
+		config internal.MetricTypeConfig
+	}{
+		{"gauge", cfg.MetricsTables.Gauge},
+		{"sum", cfg.MetricsTables.Sum},
+		{"summary", cfg.MetricsTables.Summary},
+		{"histogram", cfg.MetricsTables.Histogram},
+		{"exponential_histogram", cfg.MetricsTables.ExponentialHistogram},
+	}
+	for _, table := range metricsTables {
+		if e := internal.ValidatePromotedColumns(table.config.PromotedColumns, internal.MetricsAttributeSources); e != nil {
+			err = errors.Join(err, fmt.Errorf("metrics_tables::%s::promoted_columns: %w", table.name, e))
+		}
+	}
+	return err
+}
+
 func (cfg *Config) buildMetricTableNames() {
 	tableName := defaultMetricTableName
 
@@ -237,3 +276,13 @@ func (cfg *Config) clusterString() string {
 
 	return fmt.Sprintf("ON CLUSTER %s", cfg.ClusterName)
 }
+
+// schema returns the schema manager creating and upgrading the tables.
+func (cfg *Config) schema(logger *zap.Logger) internal.Schema {
+	return internal.Schema{
+		MigrationsTable: cfg.MigrationsTableName,
+		Cluster:         cfg.clusterString(),
+		Engine:          cfg.tableEngineString(),
+		Logger:          logger,
+	}
+}
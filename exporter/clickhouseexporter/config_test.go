@@ -67,12 +67,35 @@ func TestLoadConfig(t *testing.T) {
 					Multiplier:          backoff.DefaultMultiplier,
 				},
 				MetricsTables: MetricTablesConfig{
-					Gauge:                internal.MetricTypeConfig{Name: "otel_metrics_custom_gauge"},
+					Gauge: internal.MetricTypeConfig{
+						Name: "otel_metrics_custom_gauge",
+						PromotedColumns: []internal.PromotedColumn{
+							{Name: "HostName", Type: "LowCardinality(String)", Source: "resource", Attribute: "host.name"},
+						},
+					},
 					Sum:                  internal.MetricTypeConfig{Name: "otel_metrics_custom_sum"},
 					Summary:              internal.MetricTypeConfig{Name: "otel_metrics_custom_summary"},
 					Histogram:            internal.MetricTypeConfig{Name: "otel_metrics_custom_histogram"},
 					ExponentialHistogram: internal.MetricTypeConfig{Name: "otel_metrics_custom_exp_histogram"},
 				},
+				MigrationsTableName: "otel_custom_schema_migrations",
+				LogsPromotedColumns: []internal.PromotedColumn{
+					{Name: "HttpMethod", Type: "LowCardinality(String)", Attribute: "http.request.method"},
+				},
+				TracesPromotedColumns: []internal.PromotedColumn{
+					{
+						Name:      "HttpStatusCode",
+						Type:      "UInt16",
+						Attribute: "http.response.status_code",
+						Index:     &internal.PromotedColumnIndex{Type: "minmax", Granularity: 4},
+					},
+					{
+						Name:       "UserEmail",
+						Type:       "String",
+						Expression: "lower(SpanAttributes['user.email'])",
+						Index:      &internal.PromotedColumnIndex{Type: "bloom_filter(0.01)"},
+					},
+				},
 				ConnectionParams: map[string]string{},
 				QueueSettings: exporterhelper.QueueConfig{
 					Enabled:      true,
@@ -622,3 +645,102 @@ func TestClusterString(t *testing.T) {
 		})
 	}
 }
+
+func TestSchemaConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		fn      func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name: "valid promoted columns",
+			fn: func(cfg *Config) {
+				cfg.LogsPromotedColumns = []internal.PromotedColumn{
+					{Name: "K8sNamespace", Type: "LowCardinality(String)", Source: "resource", Attribute: "k8s.namespace.name"},
+					{Name: "ScopeTeam", Type: "String", Source: "scope", Attribute: "team"},
+				}
+				cfg.MetricsTables.Histogram.PromotedColumns = []internal.PromotedColumn{
+					{Name: "HttpRoute", Type: "String", Attribute: "http.route", Index: &internal.PromotedColumnIndex{Type: "set(100)"}},
+				}
+			},
+		},
+		{
+			name:    "no migrations table",
+			fn:      func(cfg *Config) { cfg.MigrationsTableName = "" },
+			wantErr: "migrations_table_name must be specified",
+		},
+		{
+			name: "no migrations table without creating the schema",
+			fn: func(cfg *Config) {
+				cfg.MigrationsTableName = ""
+				cfg.CreateSchema = false
+			},
+		},
+		{
+			name: "invalid column name",
+			fn: func(cfg *Config) {
+				cfg.LogsPromotedColumns = []internal.PromotedColumn{{Name: "http.method", Type: "String", Attribute: "http.method"}}
+			},
+			wantErr: `logs_promoted_columns: invalid promoted column name "http.method"`,
+		},
+		{
+			name: "duplicate column",
+			fn: func(cfg *Config) {
+				cfg.TracesPromotedColumns = []internal.PromotedColumn{
+					{Name: "Method", Type: "String", Attribute: "http.method"},
+					{Name: "Method", Type: "String", Attribute: "http.request.method"},
+				}
+			},
+			wantErr: `traces_promoted_columns: duplicate promoted column "Method"`,
+		},
+		{
+			name: "no type",
+			fn: func(cfg *Config) {
+				cfg.MetricsTables.Sum.PromotedColumns = []internal.PromotedColumn{{Name: "Method", Attribute: "http.method"}}
+			},
+			wantErr: `metrics_tables::sum::promoted_columns: promoted column "Method": type must be specified`,
+		},
+		{
+			name: "attribute and expression",
+			fn: func(cfg *Config) {
+				cfg.LogsPromotedColumns = []internal.PromotedColumn{
+					{Name: "Method", Type: "String", Attribute: "http.method", Expression: "LogAttributes['http.method']"},
+				}
+			},
+			wantErr: `logs_promoted_columns: promoted column "Method": exactly one of attribute or expression must be specified`,
+		},
+		{
+			name: "no scope attributes in traces",
+			fn: func(cfg *Config) {
+				cfg.TracesPromotedColumns = []internal.PromotedColumn{{Name: "Team", Type: "String", Source: "scope", Attribute: "team"}}
+			},
+			wantErr: `traces_promoted_columns: promoted column "Team": invalid source "scope"`,
+		},
+		{
+			name: "no index type",
+			fn: func(cfg *Config) {
+				cfg.MetricsTables.ExponentialHistogram.PromotedColumns = []internal.PromotedColumn{
+					{Name: "Method", Type: "String", Attribute: "http.method", Index: &internal.PromotedColumnIndex{Granularity: 2}},
+				}
+			},
+			wantErr: `metrics_tables::exponential_histogram::promoted_columns: promoted column "Method": index type must be specified`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := withDefaultConfig(func(cfg *Config) {
+				cfg.Endpoint = defaultEndpoint
+			}, tt.fn)
+
+			err := component.ValidateConfig(cfg)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
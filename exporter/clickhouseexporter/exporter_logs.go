@@ -50,7 +50,7 @@ func (e *logsExporter) start(ctx context.Context, _ component.Host) error {
 		return err
 	}
 
-	return createLogsTable(ctx, e.cfg, e.client)
+	return createLogsTable(ctx, e.cfg, e.client, e.logger)
 }
 
 // shutdown will shut down the exporter.
@@ -233,11 +233,30 @@ func createDatabase(ctx context.Context, cfg *Config) error {
 	return nil
 }
 
-func createLogsTable(ctx context.Context, cfg *Config, db *sql.DB) error {
-	if _, err := db.ExecContext(ctx, renderCreateLogsTableSQL(cfg)); err != nil {
+// logsAttributeSources maps the attribute sources to the Map columns of the logs table.
+var logsAttributeSources = map[string]string{
+	internal.AttributeSourceResource:   "ResourceAttributes",
+	internal.AttributeSourceScope:      "ScopeAttributes",
+	internal.AttributeSourceAttributes: "LogAttributes",
+}
+
+// logsMigrations returns the migrations of the logs table. Changes to the table must be added as a new migration.
+func logsMigrations(cfg *Config) []internal.Migration {
+	return []internal.Migration{
+		{
+			Version:     1,
+			Description: "create table",
+			Statements:  []string{renderCreateLogsTableSQL(cfg)},
+		},
+	}
+}
+
+func createLogsTable(ctx context.Context, cfg *Config, db *sql.DB, logger *zap.Logger) error {
+	schema := cfg.schema(logger)
+	if err := schema.Migrate(ctx, db, cfg.LogsTableName, logsMigrations(cfg)); err != nil {
 		return fmt.Errorf("exec create logs table sql: %w", err)
 	}
-	return nil
+	return schema.PromoteColumns(ctx, db, cfg.LogsTableName, cfg.LogsPromotedColumns, logsAttributeSources)
 }
 
 func renderCreateLogsTableSQL(cfg *Config) string {
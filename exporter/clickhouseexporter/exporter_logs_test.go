@@ -8,6 +8,7 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"errors"
+	"io"
 	"strings"
 	"testing"
 	"time"
@@ -19,6 +20,8 @@ import (
 	conventions "go.opentelemetry.io/collector/semconv/v1.27.0"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/clickhouseexporter/internal"
 )
 
 func TestLogsExporter_New(t *testing.T) {
@@ -48,7 +51,7 @@ func TestLogsExporter_New(t *testing.T) {
 	}{
 		"no dsn": {
 			config: withDefaultConfig(),
-			want:   failWithMsg("exec create logs table sql: parse dsn address failed"),
+			want:   failWithMsg("exec create logs table sql: exec create schema migrations table sql: parse dsn address failed"),
 		},
 	}
 
@@ -75,7 +78,7 @@ func TestExporter_pushLogsData(t *testing.T) {
 		var items int
 		initClickhouseTestServer(t, func(query string, values []driver.Value) error {
 			t.Logf("%d, values:%+v", items, values)
-			if strings.HasPrefix(query, "INSERT") {
+			if strings.HasPrefix(query, "INSERT INTO otel_logs") {
 				items++
 			}
 			return nil
@@ -89,7 +92,7 @@ func TestExporter_pushLogsData(t *testing.T) {
 	})
 	t.Run("test check resource metadata", func(t *testing.T) {
 		initClickhouseTestServer(t, func(query string, values []driver.Value) error {
-			if strings.HasPrefix(query, "INSERT") {
+			if strings.HasPrefix(query, "INSERT INTO otel_logs") {
 				require.Equal(t, "https://opentelemetry.io/schemas/1.4.0", values[8])
 				require.Equal(t, orderedmap.FromMap(map[string]string{
 					"service.name": "test-service",
@@ -102,7 +105,7 @@ func TestExporter_pushLogsData(t *testing.T) {
 	})
 	t.Run("test check scope metadata", func(t *testing.T) {
 		initClickhouseTestServer(t, func(query string, values []driver.Value) error {
-			if strings.HasPrefix(query, "INSERT") {
+			if strings.HasPrefix(query, "INSERT INTO otel_logs") {
 				require.Equal(t, "https://opentelemetry.io/schemas/1.7.0", values[10])
 				require.Equal(t, "io.opentelemetry.contrib.clickhouse", values[11])
 				require.Equal(t, "1.0.0", values[12])
@@ -117,7 +120,7 @@ func TestExporter_pushLogsData(t *testing.T) {
 	})
 	t.Run("test with only observed timestamp", func(t *testing.T) {
 		initClickhouseTestServer(t, func(query string, values []driver.Value) error {
-			if strings.HasPrefix(query, "INSERT") {
+			if strings.HasPrefix(query, "INSERT INTO otel_logs") {
 				require.NotEqual(t, "0", values[0])
 			}
 			return nil
@@ -128,7 +131,7 @@ func TestExporter_pushLogsData(t *testing.T) {
 	})
 	t.Run("test with 2 log records with different service.name", func(t *testing.T) {
 		initClickhouseTestServer(t, func(query string, values []driver.Value) error {
-			if strings.HasPrefix(query, "INSERT") {
+			if strings.HasPrefix(query, "INSERT INTO otel_logs") {
 				body, _ := values[7].(string)
 				if body == "empty ServiceName" {
 					require.Equal(t, "", values[6])
@@ -158,6 +161,39 @@ func TestLogsTableEngineConfig(t *testing.T) {
 	})
 }
 
+func TestLogsSchema(t *testing.T) {
+	var queries []string
+	var migration []driver.Value
+	initClickhouseTestServer(t, func(query string, values []driver.Value) error {
+		queries = append(queries, getQueryFirstLine(query))
+		if strings.HasPrefix(query, "INSERT INTO otel_schema_migrations") {
+			migration = values
+		}
+		return nil
+	})
+
+	newTestLogsExporter(t, defaultEndpoint, func(cfg *Config) {
+		cfg.LogsPromotedColumns = []internal.PromotedColumn{
+			{
+				Name:      "HttpMethod",
+				Type:      "LowCardinality(String)",
+				Attribute: "http.request.method",
+				Index:     &internal.PromotedColumnIndex{Type: "set(10)"},
+			},
+		}
+	})
+
+	require.Equal(t, []string{
+		"CREATE TABLE IF NOT EXISTS otel_schema_migrations",
+		"SELECT max(Version) FROM otel_schema_migrations WHERE TableName = ?",
+		"CREATE TABLE IF NOT EXISTS otel_logs",
+		"INSERT INTO otel_schema_migrations (TableName, Version, Description) VALUES (?, ?, ?)",
+		"ALTER TABLE otel_logs  ADD COLUMN IF NOT EXISTS HttpMethod LowCardinality(String) MATERIALIZED accurateCastOrDefault(LogAttributes['http.request.method'], 'LowCardinality(String)')",
+		"ALTER TABLE otel_logs  ADD INDEX IF NOT EXISTS idx_promoted_HttpMethod HttpMethod TYPE set(10) GRANULARITY 1",
+	}, queries)
+	require.Equal(t, []driver.Value{"otel_logs", uint32(1), "create table"}, migration)
+}
+
 func newTestLogsExporter(t *testing.T, dsn string, fns ...func(*Config)) *logsExporter {
 	exporter, err := newLogsExporter(zaptest.NewLogger(t), withTestExporterConfig(fns...)(dsn))
 	require.NoError(t, err)
@@ -317,8 +353,23 @@ func (t *testClickhouseDriverStmt) Exec(args []driver.Value) (driver.Result, err
 	return nil, t.recorder(t.query, args)
 }
 
-func (t *testClickhouseDriverStmt) Query(_ []driver.Value) (driver.Rows, error) {
-	return nil, nil
+func (t *testClickhouseDriverStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &testClickhouseDriverRows{}, t.recorder(t.query, args)
+}
+
+// testClickhouseDriverRows is an empty result.
+type testClickhouseDriverRows struct{}
+
+func (*testClickhouseDriverRows) Columns() []string {
+	return nil
+}
+
+func (*testClickhouseDriverRows) Close() error {
+	return nil
+}
+
+func (*testClickhouseDriverRows) Next(_ []driver.Value) error {
+	return io.EOF
 }
 
 type testClickhouseDriverTx struct{}
@@ -69,6 +69,10 @@ func testClusterConfig(t *testing.T, completion clusterTestCompletion) {
 	for _, tt := range tests {
 		t.Run("test cluster config "+tt.name, func(t *testing.T) {
 			initClickhouseTestServer(t, func(query string, _ []driver.Value) error {
+				// Only DDL queries have an ON CLUSTER clause, not the ones reading or recording the schema migrations.
+				if strings.HasPrefix(query, "SELECT") || strings.HasPrefix(query, "INSERT") {
+					return nil
+				}
 				if tt.shouldPass {
 					require.NoError(t, checkClusterQueryDefinition(query, tt.cluster))
 				} else {
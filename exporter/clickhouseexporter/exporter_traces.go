@@ -52,7 +52,7 @@ func (e *tracesExporter) start(ctx context.Context, _ component.Host) error {
 		return err
 	}
 
-	return createTracesTable(ctx, e.cfg, e.client)
+	return createTracesTable(ctx, e.cfg, e.client, e.logger)
 }
 
 // shutdown will shut down the exporter.
@@ -266,17 +266,34 @@ GROUP BY TraceId;
 `
 )
 
-func createTracesTable(ctx context.Context, cfg *Config, db *sql.DB) error {
-	if _, err := db.ExecContext(ctx, renderCreateTracesTableSQL(cfg)); err != nil {
-		return fmt.Errorf("exec create traces table sql: %w", err)
-	}
-	if _, err := db.ExecContext(ctx, renderCreateTraceIDTsTableSQL(cfg)); err != nil {
-		return fmt.Errorf("exec create traceID timestamp table sql: %w", err)
+// tracesAttributeSources maps the attribute sources to the Map columns of the traces table.
+var tracesAttributeSources = map[string]string{
+	internal.AttributeSourceResource:   "ResourceAttributes",
+	internal.AttributeSourceAttributes: "SpanAttributes",
+}
+
+// tracesMigrations returns the migrations of the traces table, and of the trace ID timestamp table and view
+// derived from it. Changes to the tables must be added as a new migration.
+func tracesMigrations(cfg *Config) []internal.Migration {
+	return []internal.Migration{
+		{
+			Version:     1,
+			Description: "create tables",
+			Statements: []string{
+				renderCreateTracesTableSQL(cfg),
+				renderCreateTraceIDTsTableSQL(cfg),
+				renderTraceIDTsMaterializedViewSQL(cfg),
+			},
+		},
 	}
-	if _, err := db.ExecContext(ctx, renderTraceIDTsMaterializedViewSQL(cfg)); err != nil {
-		return fmt.Errorf("exec create traceID timestamp view sql: %w", err)
+}
+
+func createTracesTable(ctx context.Context, cfg *Config, db *sql.DB, logger *zap.Logger) error {
+	schema := cfg.schema(logger)
+	if err := schema.Migrate(ctx, db, cfg.TracesTableName, tracesMigrations(cfg)); err != nil {
+		return fmt.Errorf("exec create traces table sql: %w", err)
 	}
-	return nil
+	return schema.PromoteColumns(ctx, db, cfg.TracesTableName, cfg.TracesPromotedColumns, tracesAttributeSources)
 }
 
 func renderInsertTracesSQL(cfg *Config) string {
@@ -34,6 +34,8 @@ type MetricTablesConfigMapper map[pmetric.MetricType]MetricTypeConfig
 
 type MetricTypeConfig struct {
 	Name string `mapstructure:"name"`
+	// PromotedColumns defines typed columns materialized from the attributes of the table.
+	PromotedColumns []PromotedColumn `mapstructure:"promoted_columns"`
 }
 
 // MetricsModel is used to group metric data and insert into clickhouse
@@ -58,13 +60,24 @@ func SetLogger(l *zap.Logger) {
 	logger = l
 }
 
-// NewMetricsTable create metric tables with an expiry time to storage metric telemetry data
-func NewMetricsTable(ctx context.Context, tablesConfig MetricTablesConfigMapper, cluster, engine, ttlExpr string, db *sql.DB) error {
+// NewMetricsTable create metric tables with an expiry time to storage metric telemetry data,
+// and upgrade them to the latest version of their schema
+func NewMetricsTable(ctx context.Context, tablesConfig MetricTablesConfigMapper, schema Schema, ttlExpr string, db *sql.DB) error {
 	for key, queryTemplate := range supportedMetricTypes {
-		query := fmt.Sprintf(queryTemplate, tablesConfig[key].Name, cluster, engine, ttlExpr)
-		if _, err := db.ExecContext(ctx, query); err != nil {
+		tableConfig := tablesConfig[key]
+		migrations := []Migration{
+			{
+				Version:     1,
+				Description: "create table",
+				Statements:  []string{fmt.Sprintf(queryTemplate, tableConfig.Name, schema.Cluster, schema.Engine, ttlExpr)},
+			},
+		}
+		if err := schema.Migrate(ctx, db, tableConfig.Name, migrations); err != nil {
 			return fmt.Errorf("exec create metrics table sql: %w", err)
 		}
+		if err := schema.PromoteColumns(ctx, db, tableConfig.Name, tableConfig.PromotedColumns, MetricsAttributeSources); err != nil {
+			return err
+		}
 	}
 	return nil
 }
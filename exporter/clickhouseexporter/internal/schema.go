@@ -0,0 +1,212 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/clickhouseexporter/internal"
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// language=ClickHouse SQL
+	createMigrationsTableSQL = `
+CREATE TABLE IF NOT EXISTS %s %s (
+	TableName LowCardinality(String),
+	Version UInt32,
+	Description String,
+	AppliedAt DateTime DEFAULT now()
+) ENGINE = %s
+ORDER BY (TableName, Version);
+`
+	// language=ClickHouse SQL
+	selectSchemaVersionSQL = `SELECT max(Version) FROM %s WHERE TableName = ?`
+	// language=ClickHouse SQL
+	insertMigrationSQL = `INSERT INTO %s (TableName, Version, Description) VALUES (?, ?, ?)`
+	// language=ClickHouse SQL
+	addPromotedColumnSQL = `ALTER TABLE %s %s ADD COLUMN IF NOT EXISTS %s %s MATERIALIZED %s`
+	// language=ClickHouse SQL
+	addPromotedColumnIndexSQL = `ALTER TABLE %s %s ADD INDEX IF NOT EXISTS %s %s TYPE %s GRANULARITY %d`
+)
+
+const (
+	// AttributeSourceResource promotes a resource attribute.
+	AttributeSourceResource = "resource"
+	// AttributeSourceScope promotes an instrumentation scope attribute.
+	AttributeSourceScope = "scope"
+	// AttributeSourceAttributes promotes an attribute of the log record, span or data point.
+	AttributeSourceAttributes = "attributes"
+)
+
+// MetricsAttributeSources maps the attribute sources to the Map columns of the metrics tables.
+var MetricsAttributeSources = map[string]string{
+	AttributeSourceResource:   "ResourceAttributes",
+	AttributeSourceScope:      "ScopeAttributes",
+	AttributeSourceAttributes: "Attributes",
+}
+
+var columnNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Migration is a versioned change of the schema of a table.
+// A migration is recorded once all its statements succeeded, so the statements are run again
+// when one of them failed and must be idempotent.
+type Migration struct {
+	Version     uint32
+	Description string
+	Statements  []string
+}
+
+// PromotedColumn copies an attribute stored in a Map column to a typed column, computed by
+// ClickHouse when the rows are inserted, so that filtering on it doesn't read the Map column.
+type PromotedColumn struct {
+	// Name is the name of the column.
+	Name string `mapstructure:"name"`
+	// Type is the ClickHouse type of the column, for example `LowCardinality(String)` or `UInt16`.
+	Type string `mapstructure:"type"`
+	// Source is the Map column of the attribute: `resource`, `scope` or `attributes`. default is `attributes`.
+	Source string `mapstructure:"source"`
+	// Attribute is the key of the promoted attribute.
+	Attribute string `mapstructure:"attribute"`
+	// Expression replaces the expression computing the column from the attribute.
+	Expression string `mapstructure:"expression"`
+	// Index adds a data skipping index on the column when set.
+	Index *PromotedColumnIndex `mapstructure:"index"`
+}
+
+// PromotedColumnIndex defines the data skipping index of a promoted column.
+type PromotedColumnIndex struct {
+	// Type is the index type, for example `bloom_filter(0.01)`, `set(100)` or `minmax`.
+	Type string `mapstructure:"type"`
+	// Granularity is the index granularity. default is 1.
+	Granularity int `mapstructure:"granularity"`
+}
+
+// ValidatePromotedColumns checks the promoted columns of a table having the given attribute sources.
+func ValidatePromotedColumns(columns []PromotedColumn, sources map[string]string) (err error) {
+	names := make(map[string]struct{}, len(columns))
+	for _, c := range columns {
+		if !columnNameRegexp.MatchString(c.Name) {
+			err = errors.Join(err, fmt.Errorf("invalid promoted column name %q", c.Name))
+			continue
+		}
+		if _, ok := names[c.Name]; ok {
+			err = errors.Join(err, fmt.Errorf("duplicate promoted column %q", c.Name))
+		}
+		names[c.Name] = struct{}{}
+
+		if c.Type == "" {
+			err = errors.Join(err, fmt.Errorf("promoted column %q: type must be specified", c.Name))
+		}
+		if (c.Attribute == "") == (c.Expression == "") {
+			err = errors.Join(err, fmt.Errorf("promoted column %q: exactly one of attribute or expression must be specified", c.Name))
+		}
+		if _, ok := sources[c.source()]; !ok {
+			err = errors.Join(err, fmt.Errorf("promoted column %q: invalid source %q", c.Name, c.Source))
+		}
+		if c.Index != nil {
+			if c.Index.Type == "" {
+				err = errors.Join(err, fmt.Errorf("promoted column %q: index type must be specified", c.Name))
+			}
+			if c.Index.Granularity < 0 {
+				err = errors.Join(err, fmt.Errorf("promoted column %q: index granularity can't be negative", c.Name))
+			}
+		}
+	}
+	return err
+}
+
+func (c PromotedColumn) source() string {
+	if c.Source == "" {
+		return AttributeSourceAttributes
+	}
+	return c.Source
+}
+
+// expression returns the expression computing the column. Attributes which can't be converted to the
+// type of the column get its default value, instead of failing the inserts.
+func (c PromotedColumn) expression(sources map[string]string) string {
+	if c.Expression != "" {
+		return c.Expression
+	}
+	key := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(c.Attribute)
+	typ := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(c.Type)
+	return fmt.Sprintf("accurateCastOrDefault(%s['%s'], '%s')", sources[c.source()], key, typ)
+}
+
+func (c PromotedColumn) indexName() string {
+	return "idx_promoted_" + c.Name
+}
+
+func (c PromotedColumn) indexGranularity() int {
+	if c.Index.Granularity > 0 {
+		return c.Index.Granularity
+	}
+	return 1
+}
+
+// Schema creates and upgrades the tables of the exporter.
+type Schema struct {
+	// MigrationsTable is the table recording the migrations applied to each table.
+	MigrationsTable string
+	// Cluster is the ON CLUSTER clause, empty when not running on a cluster.
+	Cluster string
+	// Engine is the table engine of the migrations table.
+	Engine string
+	Logger *zap.Logger
+}
+
+// Migrate applies the migrations of a table which weren't applied yet, in order of version.
+func (s Schema) Migrate(ctx context.Context, db *sql.DB, table string, migrations []Migration) error {
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(createMigrationsTableSQL, s.MigrationsTable, s.Cluster, s.Engine)); err != nil {
+		return fmt.Errorf("exec create schema migrations table sql: %w", err)
+	}
+
+	var version uint32
+	err := db.QueryRowContext(ctx, fmt.Sprintf(selectSchemaVersionSQL, s.MigrationsTable), table).Scan(&version)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("select schema version of table %s: %w", table, err)
+	}
+
+	for _, m := range migrations {
+		if m.Version <= version {
+			continue
+		}
+		for _, statement := range m.Statements {
+			if _, err := db.ExecContext(ctx, statement); err != nil {
+				return fmt.Errorf("exec migration %d of table %s (%s): %w", m.Version, table, m.Description, err)
+			}
+		}
+		if _, err := db.ExecContext(ctx, fmt.Sprintf(insertMigrationSQL, s.MigrationsTable), table, m.Version, m.Description); err != nil {
+			return fmt.Errorf("record migration %d of table %s: %w", m.Version, table, err)
+		}
+		s.Logger.Info("Applied schema migration",
+			zap.String("table", table), zap.Uint32("version", m.Version), zap.String("description", m.Description))
+		version = m.Version
+	}
+	return nil
+}
+
+// PromoteColumns adds the promoted columns and their indexes missing from a table. Existing columns aren't
+// modified, and only the rows inserted after a column was added are materialized.
+func (s Schema) PromoteColumns(ctx context.Context, db *sql.DB, table string, columns []PromotedColumn, sources map[string]string) error {
+	for _, c := range columns {
+		query := fmt.Sprintf(addPromotedColumnSQL, table, s.Cluster, c.Name, c.Type, c.expression(sources))
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("exec add promoted column %s to table %s: %w", c.Name, table, err)
+		}
+		if c.Index == nil {
+			continue
+		}
+		query = fmt.Sprintf(addPromotedColumnIndexSQL, table, s.Cluster, c.indexName(), c.Name, c.Index.Type, c.indexGranularity())
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("exec add index of promoted column %s to table %s: %w", c.Name, table, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSchemaMigrate(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Description: "create table", Statements: []string{"CREATE TABLE t"}},
+		{Version: 2, Description: "add column", Statements: []string{"ALTER TABLE t ADD COLUMN a String", "ALTER TABLE t ADD INDEX idx_a a TYPE set(10)"}},
+		{Version: 3, Description: "add other column", Statements: []string{"ALTER TABLE t ADD COLUMN b String"}},
+	}
+
+	tests := []struct {
+		name     string
+		version  int64
+		failing  string
+		expected []string
+		wantErr  string
+	}{
+		{
+			name:    "new table",
+			version: 0,
+			expected: []string{
+				"CREATE TABLE t",
+				"INSERT INTO migrations t 1 create table",
+				"ALTER TABLE t ADD COLUMN a String",
+				"ALTER TABLE t ADD INDEX idx_a a TYPE set(10)",
+				"INSERT INTO migrations t 2 add column",
+				"ALTER TABLE t ADD COLUMN b String",
+				"INSERT INTO migrations t 3 add other column",
+			},
+		},
+		{
+			name:    "upgraded table",
+			version: 2,
+			expected: []string{
+				"ALTER TABLE t ADD COLUMN b String",
+				"INSERT INTO migrations t 3 add other column",
+			},
+		},
+		{
+			name:     "latest table",
+			version:  3,
+			expected: []string{},
+		},
+		{
+			name:    "failed migration",
+			version: 1,
+			failing: "ALTER TABLE t ADD INDEX",
+			expected: []string{
+				"ALTER TABLE t ADD COLUMN a String",
+			},
+			wantErr: "exec migration 2 of table t (add column): failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, statements := newTestDB(t, tt.version, tt.failing)
+			schema := Schema{MigrationsTable: "migrations", Engine: "MergeTree()", Logger: zaptest.NewLogger(t)}
+
+			err := schema.Migrate(context.Background(), db, "t", migrations)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.expected, (*statements)[1:])
+		})
+	}
+}
+
+func TestSchemaPromoteColumns(t *testing.T) {
+	db, statements := newTestDB(t, 0, "")
+	schema := Schema{MigrationsTable: "migrations", Cluster: "ON CLUSTER c", Engine: "MergeTree()", Logger: zaptest.NewLogger(t)}
+
+	err := schema.PromoteColumns(context.Background(), db, "otel_metrics_sum", []PromotedColumn{
+		{Name: "HostName", Type: "LowCardinality(String)", Source: "resource", Attribute: "host.name"},
+		{Name: "Quoted", Type: "String", Source: "scope", Attribute: `it's a \ key`},
+		{Name: "StatusCode", Type: "UInt16", Attribute: "http.response.status_code", Index: &PromotedColumnIndex{Type: "minmax", Granularity: 4}},
+		{Name: "Route", Type: "String", Expression: "lower(Attributes['http.route'])", Index: &PromotedColumnIndex{Type: "bloom_filter(0.01)"}},
+	}, MetricsAttributeSources)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"ALTER TABLE otel_metrics_sum ON CLUSTER c ADD COLUMN IF NOT EXISTS HostName LowCardinality(String) MATERIALIZED accurateCastOrDefault(ResourceAttributes['host.name'], 'LowCardinality(String)')",
+		`ALTER TABLE otel_metrics_sum ON CLUSTER c ADD COLUMN IF NOT EXISTS Quoted String MATERIALIZED accurateCastOrDefault(ScopeAttributes['it\'s a \\ key'], 'String')`,
+		"ALTER TABLE otel_metrics_sum ON CLUSTER c ADD COLUMN IF NOT EXISTS StatusCode UInt16 MATERIALIZED accurateCastOrDefault(Attributes['http.response.status_code'], 'UInt16')",
+		"ALTER TABLE otel_metrics_sum ON CLUSTER c ADD INDEX IF NOT EXISTS idx_promoted_StatusCode StatusCode TYPE minmax GRANULARITY 4",
+		"ALTER TABLE otel_metrics_sum ON CLUSTER c ADD COLUMN IF NOT EXISTS Route String MATERIALIZED lower(Attributes['http.route'])",
+		"ALTER TABLE otel_metrics_sum ON CLUSTER c ADD INDEX IF NOT EXISTS idx_promoted_Route Route TYPE bloom_filter(0.01) GRANULARITY 1",
+	}, *statements)
+}
+
+// newTestDB returns a database answering the schema version with the given version, and failing the
+// statements starting with failing. It records the executed statements, except the schema version query.
+func newTestDB(t *testing.T, version int64, failing string) (*sql.DB, *[]string) {
+	var statements []string
+	sql.Register(t.Name(), &testDriver{version: version, failing: failing, statements: &statements})
+	db, err := sql.Open(t.Name(), "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db, &statements
+}
+
+type testDriver struct {
+	version    int64
+	failing    string
+	statements *[]string
+}
+
+func (d *testDriver) Open(_ string) (driver.Conn, error) {
+	return d, nil
+}
+
+func (d *testDriver) Prepare(query string) (driver.Stmt, error) {
+	return &testStmt{driver: d, query: strings.TrimSpace(query)}, nil
+}
+
+func (*testDriver) Close() error {
+	return nil
+}
+
+func (*testDriver) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+type testStmt struct {
+	driver *testDriver
+	query  string
+}
+
+func (*testStmt) Close() error {
+	return nil
+}
+
+func (s *testStmt) NumInput() int {
+	return strings.Count(s.query, "?")
+}
+
+func (s *testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	statement := s.query
+	switch {
+	case strings.HasPrefix(statement, "INSERT INTO migrations"):
+		statement = fmt.Sprintf("INSERT INTO migrations %v %v %v", args[0], args[1], args[2])
+	case strings.HasPrefix(statement, "CREATE TABLE IF NOT EXISTS migrations"):
+		statement = "CREATE TABLE IF NOT EXISTS migrations"
+	}
+	if s.driver.failing != "" && strings.HasPrefix(statement, s.driver.failing) {
+		return nil, errors.New("failed")
+	}
+	*s.driver.statements = append(*s.driver.statements, statement)
+	return driver.RowsAffected(0), nil
+}
+
+func (s *testStmt) Query(_ []driver.Value) (driver.Rows, error) {
+	return &testRows{version: s.driver.version}, nil
+}
+
+type testRows struct {
+	version int64
+	done    bool
+}
+
+func (*testRows) Columns() []string {
+	return []string{"max(Version)"}
+}
+
+func (*testRows) Close() error {
+	return nil
+}
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.version
+	return nil
+}
@@ -4,9 +4,15 @@
 package fileexporter
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,6 +28,7 @@ import (
 	"go.opentelemetry.io/collector/pdata/pprofile"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding/otlpencodingextension"
 )
 
@@ -128,3 +135,105 @@ func generateTraces() ptrace.Traces {
 	span.SetEndTimestamp(pcommon.NewTimestampFromTime(time.Now()))
 	return traces
 }
+
+// numberedEncoding is a stream encoding that numbers the batches of each stream, one per line.
+type numberedEncoding struct {
+	component.StartFunc
+	component.ShutdownFunc
+	closed int
+}
+
+func (e *numberedEncoding) NewMarshalerStream() encoding.MarshalerStream {
+	return &numberedStream{encoding: e}
+}
+
+type numberedStream struct {
+	encoding *numberedEncoding
+	count    int
+}
+
+func (s *numberedStream) MarshalLogs(ld plog.Logs) ([]byte, error) {
+	buf, err := (&plog.JSONMarshaler{}).MarshalLogs(ld)
+	if err != nil {
+		return nil, err
+	}
+	s.count++
+	return append(append([]byte(fmt.Sprintf("%d:", s.count)), buf...), '\n'), nil
+}
+
+func (s *numberedStream) Close() error {
+	s.encoding.closed++
+	return nil
+}
+
+func TestStreamEncoding(t *testing.T) {
+	dir := t.TempDir()
+	f := NewFactory()
+	cfg := f.CreateDefaultConfig().(*Config)
+	cfg.Path = filepath.Join(dir, "stream.bin")
+	cfg.Rotation = &Rotation{MaxMegabytes: 1}
+	id := component.MustNewID("numbered")
+	cfg.Encoding = &id
+	ext := &numberedEncoding{}
+	host := hostWithEncoding{
+		map[component.ID]component.Component{id: ext},
+	}
+
+	// a stream must start at the beginning of a file, so the existing file is rotated.
+	require.NoError(t, os.WriteFile(cfg.Path, []byte("previous"), 0o600))
+
+	le, err := f.CreateLogs(context.Background(), exportertest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, le.Start(context.Background(), host))
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr(strings.Repeat("a", 300*1024))
+	for i := 0; i < 7; i++ {
+		require.NoError(t, le.ConsumeLogs(context.Background(), ld))
+	}
+	require.NoError(t, le.Shutdown(context.Background()))
+	require.Equal(t, 3, ext.closed)
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	require.Len(t, paths, 4)
+	var counts []int
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		if string(b) == "previous" {
+			continue
+		}
+		// each file holds a whole stream, numbered from its first batch, and written without framing.
+		br := bufio.NewReader(bytes.NewReader(b))
+		count := 0
+		for {
+			buf, err := br.ReadBytes('\n')
+			if errors.Is(err, io.EOF) {
+				require.Empty(t, buf)
+				break
+			}
+			require.NoError(t, err)
+			count++
+			require.True(t, bytes.HasPrefix(buf, []byte(fmt.Sprintf("%d:{", count))))
+		}
+		counts = append(counts, count)
+	}
+	require.ElementsMatch(t, []int{3, 3, 1}, counts)
+}
+
+func TestStreamEncodingAppend(t *testing.T) {
+	f := NewFactory()
+	cfg := f.CreateDefaultConfig().(*Config)
+	cfg.Path = filepath.Join(t.TempDir(), "stream.bin")
+	cfg.Append = true
+	id := component.MustNewID("numbered")
+	cfg.Encoding = &id
+	host := hostWithEncoding{
+		map[component.ID]component.Component{id: &numberedEncoding{}},
+	}
+
+	le, err := f.CreateLogs(context.Background(), exportertest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	require.EqualError(t, le.Start(context.Background(), host), `append is not supported with the stream encoding "numbered"`)
+	require.NoError(t, le.Shutdown(context.Background()))
+}
@@ -21,35 +21,27 @@ type fileExporter struct {
 }
 
 func (e *fileExporter) consumeTraces(_ context.Context, td ptrace.Traces) error {
-	buf, err := e.marshaller.marshalTraces(td)
-	if err != nil {
-		return err
-	}
-	return e.writer.export(buf)
+	return e.writer.marshalAndExport(e.marshaller, func(m *marshaller) ([]byte, error) {
+		return m.marshalTraces(td)
+	})
 }
 
 func (e *fileExporter) consumeMetrics(_ context.Context, md pmetric.Metrics) error {
-	buf, err := e.marshaller.marshalMetrics(md)
-	if err != nil {
-		return err
-	}
-	return e.writer.export(buf)
+	return e.writer.marshalAndExport(e.marshaller, func(m *marshaller) ([]byte, error) {
+		return m.marshalMetrics(md)
+	})
 }
 
 func (e *fileExporter) consumeLogs(_ context.Context, ld plog.Logs) error {
-	buf, err := e.marshaller.marshalLogs(ld)
-	if err != nil {
-		return err
-	}
-	return e.writer.export(buf)
+	return e.writer.marshalAndExport(e.marshaller, func(m *marshaller) ([]byte, error) {
+		return m.marshalLogs(ld)
+	})
 }
 
 func (e *fileExporter) consumeProfiles(_ context.Context, pd pprofile.Profiles) error {
-	buf, err := e.marshaller.marshalProfiles(pd)
-	if err != nil {
-		return err
-	}
-	return e.writer.export(buf)
+	return e.writer.marshalAndExport(e.marshaller, func(m *marshaller) ([]byte, error) {
+		return m.marshalProfiles(pd)
+	})
 }
 
 // Start starts the flush timer if set.
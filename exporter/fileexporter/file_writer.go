@@ -5,11 +5,20 @@ package fileexporter // import "github.com/open-telemetry/opentelemetry-collecto
 
 import (
 	"encoding/binary"
+	"errors"
 	"io"
+	"os"
 	"sync"
 	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding"
 )
 
+// defaultMaxMegabytes is the size of rotated files when max_megabytes is not set, as set by lumberjack.
+const defaultMaxMegabytes = 100
+
 // exportFunc defines how to export encoded telemetry data.
 type exportFunc func(e *fileWriter, buf []byte) error
 
@@ -23,6 +32,12 @@ type fileWriter struct {
 	flushInterval time.Duration
 	flushTicker   *time.Ticker
 	stopTicker    chan struct{}
+
+	// streamMutex keeps the batches of a stream encoding in the order in which they are marshaled.
+	streamMutex      sync.Mutex
+	stream           encoding.MarshalerStream
+	streamMarshaller *marshaller
+	streamSize       int64
 }
 
 func exportMessageAsLine(w *fileWriter, buf []byte) error {
@@ -50,10 +65,99 @@ func exportMessageAsBuffer(w *fileWriter, buf []byte) error {
 	return binary.Write(w.file, binary.BigEndian, append(data, buf...))
 }
 
+// exportMessageAsIs writes the self-delimiting batches of stream encodings without framing, so that
+// a file is in the format of the encoding.
+func exportMessageAsIs(w *fileWriter, buf []byte) error {
+	// Ensure only one write operation happens at a time.
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	_, err := w.file.Write(buf)
+	return err
+}
+
 func (w *fileWriter) export(buf []byte) error {
 	return w.exporter(w, buf)
 }
 
+// marshalAndExport marshals telemetry data with m and writes it. With stream encodings, the data is
+// marshaled with the stream of the file instead, in the order in which it is written, and written
+// without framing.
+func (w *fileWriter) marshalAndExport(m *marshaller, marshal marshalFunc) error {
+	if m.newStream == nil {
+		buf, err := marshal(m)
+		if err != nil {
+			return err
+		}
+		return w.export(buf)
+	}
+
+	w.streamMutex.Lock()
+	defer w.streamMutex.Unlock()
+	if w.stream == nil {
+		if err := w.startStream(m); err != nil {
+			return err
+		}
+	}
+	buf, err := marshal(w.streamMarshaller)
+	if err != nil {
+		return err
+	}
+	// a stream cannot be split across files: when the batch does not fit in the file, the file is
+	// rotated and the batch is marshaled again as the first batch of a new stream.
+	if logger, ok := w.file.(*lumberjack.Logger); ok && w.streamSize > 0 && w.streamSize+int64(len(buf)) > maxSize(logger) {
+		if err = w.startStream(m); err != nil {
+			return err
+		}
+		if buf, err = marshal(w.streamMarshaller); err != nil {
+			return err
+		}
+	}
+	if err = exportMessageAsIs(w, buf); err != nil {
+		return err
+	}
+	w.streamSize += int64(len(buf))
+	return nil
+}
+
+// startStream closes the current stream and starts a new one. As a stream must start at the beginning
+// of a file, rotated files are rotated first unless they are empty.
+func (w *fileWriter) startStream(m *marshaller) error {
+	if err := w.closeStream(); err != nil {
+		return err
+	}
+	if logger, ok := w.file.(*lumberjack.Logger); ok {
+		if info, err := os.Stat(w.path); err == nil && info.Size() > 0 {
+			w.mutex.Lock()
+			err = logger.Rotate()
+			w.mutex.Unlock()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	w.streamMarshaller, w.stream = m.startStream()
+	w.streamSize = 0
+	return nil
+}
+
+func (w *fileWriter) closeStream() error {
+	if w.stream == nil {
+		return nil
+	}
+	err := w.stream.Close()
+	w.stream = nil
+	w.streamMarshaller = nil
+	return err
+}
+
+// maxSize returns the size in bytes after which lumberjack rotates a file.
+func maxSize(logger *lumberjack.Logger) int64 {
+	if logger.MaxSize == 0 {
+		return defaultMaxMegabytes * 1024 * 1024
+	}
+	return int64(logger.MaxSize) * 1024 * 1024
+}
+
 // startFlusher starts the flusher.
 // It does not check the flushInterval
 func (w *fileWriter) startFlusher() {
@@ -102,7 +206,10 @@ func (w *fileWriter) shutdown() error {
 		close(w.stopTicker)
 		w.mutex.Unlock()
 	}
-	return w.file.Close()
+	w.streamMutex.Lock()
+	err := w.closeStream()
+	w.streamMutex.Unlock()
+	return errors.Join(err, w.file.Close())
 }
 
 func buildExportFunc(cfg *Config) func(w *fileWriter, buf []byte) error {
@@ -56,13 +56,9 @@ func (e *groupingFileExporter) consumeTraces(ctx context.Context, td ptrace.Trac
 			rSpans.CopyTo(traces.ResourceSpans().AppendEmpty())
 		}
 
-		buf, err := e.marshaller.marshalTraces(traces)
-		if err != nil {
-			errs = errors.Join(errs, err)
-			continue
-		}
-
-		err = e.write(ctx, pathSegment, buf)
+		err := e.write(ctx, pathSegment, func(m *marshaller) ([]byte, error) {
+			return m.marshalTraces(traces)
+		})
 		if err != nil {
 			errs = errors.Join(errs, err)
 		}
@@ -94,13 +90,9 @@ func (e *groupingFileExporter) consumeMetrics(ctx context.Context, md pmetric.Me
 			rMetrics.CopyTo(metrics.ResourceMetrics().AppendEmpty())
 		}
 
-		buf, err := e.marshaller.marshalMetrics(metrics)
-		if err != nil {
-			errs = errors.Join(errs, err)
-			continue
-		}
-
-		err = e.write(ctx, pathSegment, buf)
+		err := e.write(ctx, pathSegment, func(m *marshaller) ([]byte, error) {
+			return m.marshalMetrics(metrics)
+		})
 		if err != nil {
 			errs = errors.Join(errs, err)
 		}
@@ -132,13 +124,9 @@ func (e *groupingFileExporter) consumeLogs(ctx context.Context, ld plog.Logs) er
 			rlogs.CopyTo(logs.ResourceLogs().AppendEmpty())
 		}
 
-		buf, err := e.marshaller.marshalLogs(logs)
-		if err != nil {
-			errs = errors.Join(errs, err)
-			continue
-		}
-
-		err = e.write(ctx, pathSegment, buf)
+		err := e.write(ctx, pathSegment, func(m *marshaller) ([]byte, error) {
+			return m.marshalLogs(logs)
+		})
 		if err != nil {
 			errs = errors.Join(errs, err)
 		}
@@ -170,13 +158,9 @@ func (e *groupingFileExporter) consumeProfiles(ctx context.Context, pd pprofile.
 			rProfiles.CopyTo(profiles.ResourceProfiles().AppendEmpty())
 		}
 
-		buf, err := e.marshaller.marshalProfiles(profiles)
-		if err != nil {
-			errs = errors.Join(errs, err)
-			continue
-		}
-
-		err = e.write(ctx, pathSegment, buf)
+		err := e.write(ctx, pathSegment, func(m *marshaller) ([]byte, error) {
+			return m.marshalProfiles(profiles)
+		})
 		if err != nil {
 			errs = errors.Join(errs, err)
 		}
@@ -189,13 +173,13 @@ func (e *groupingFileExporter) consumeProfiles(ctx context.Context, pd pprofile.
 	return nil
 }
 
-func (e *groupingFileExporter) write(_ context.Context, pathSegment string, buf []byte) error {
+func (e *groupingFileExporter) write(_ context.Context, pathSegment string, marshal marshalFunc) error {
 	writer, err := e.getWriter(pathSegment)
 	if err != nil {
 		return err
 	}
 
-	err = writer.export(buf)
+	err = writer.marshalAndExport(e.marshaller, marshal)
 	if err != nil {
 		return err
 	}
@@ -12,6 +12,8 @@ import (
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/pprofile"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding"
 )
 
 // Marshaler configuration used for marhsaling Protobuf
@@ -45,19 +47,36 @@ type marshaller struct {
 	compressor  compressFunc
 
 	formatType string
+
+	// newStream starts a stream of a stream encoding, whose batches depend on the previous batches
+	// of the same stream. The data of each file is then marshaled with a stream of its own.
+	newStream func() encoding.MarshalerStream
 }
 
+// marshalFunc marshals telemetry data with the given marshaller.
+type marshalFunc func(m *marshaller) ([]byte, error)
+
 func newMarshaller(conf *Config, host component.Host) (*marshaller, error) {
 	if conf.Encoding != nil {
-		encoding := host.GetExtensions()[*conf.Encoding]
-		if encoding == nil {
+		ext := host.GetExtensions()[*conf.Encoding]
+		if ext == nil {
 			return nil, fmt.Errorf("unknown encoding %q", conf.Encoding)
 		}
+		if streamExt, ok := ext.(encoding.StreamMarshalerExtension); ok {
+			if conf.Append {
+				return nil, fmt.Errorf("append is not supported with the stream encoding %q", conf.Encoding)
+			}
+			return &marshaller{
+				newStream:   streamExt.NewMarshalerStream,
+				compression: conf.Compression,
+				compressor:  buildCompressor(conf.Compression),
+			}, nil
+		}
 		// cast with ok to avoid panics.
-		tm, _ := encoding.(ptrace.Marshaler)
-		mm, _ := encoding.(pmetric.Marshaler)
-		lm, _ := encoding.(plog.Marshaler)
-		pm, _ := encoding.(pprofile.Marshaler)
+		tm, _ := ext.(ptrace.Marshaler)
+		mm, _ := ext.(pmetric.Marshaler)
+		lm, _ := ext.(plog.Marshaler)
+		pm, _ := ext.(pprofile.Marshaler)
 		return &marshaller{
 			tracesMarshaler:   tm,
 			metricsMarshaler:  mm,
@@ -78,6 +97,25 @@ func newMarshaller(conf *Config, host component.Host) (*marshaller, error) {
 	}, nil
 }
 
+// startStream returns the marshaller of a new stream of a stream encoding, and the stream to
+// close once all its data is written.
+func (m *marshaller) startStream() (*marshaller, encoding.MarshalerStream) {
+	stream := m.newStream()
+	// cast with ok to avoid panics.
+	tm, _ := stream.(ptrace.Marshaler)
+	mm, _ := stream.(pmetric.Marshaler)
+	lm, _ := stream.(plog.Marshaler)
+	pm, _ := stream.(pprofile.Marshaler)
+	return &marshaller{
+		tracesMarshaler:   tm,
+		metricsMarshaler:  mm,
+		logsMarshaler:     lm,
+		profilesMarshaler: pm,
+		compression:       m.compression,
+		compressor:        m.compressor,
+	}, stream
+}
+
 func (m *marshaller) marshalTraces(td ptrace.Traces) ([]byte, error) {
 	if m.tracesMarshaler == nil {
 		return nil, errors.New("traces are not supported by encoding")
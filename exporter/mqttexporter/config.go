@@ -0,0 +1,75 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqttexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/mqttexporter"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+
+	internalmqtt "github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt"
+)
+
+// Config defines configuration for the MQTT exporter.
+type Config struct {
+	TimeoutSettings           exporterhelper.TimeoutConfig `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+	QueueSettings             exporterhelper.QueueConfig   `mapstructure:"sending_queue"`
+	configretry.BackOffConfig `mapstructure:"retry_on_failure"`
+
+	internalmqtt.ClientConfig `mapstructure:",squash"`
+
+	// QoS is the quality of service of the published messages: 0, 1 or 2.
+	QoS byte `mapstructure:"qos"`
+	// Retain asks the broker to retain the last message published to each topic, and to deliver it to new subscribers.
+	Retain bool `mapstructure:"retain"`
+
+	Traces  SignalConfig `mapstructure:"traces"`
+	Metrics SignalConfig `mapstructure:"metrics"`
+	Logs    SignalConfig `mapstructure:"logs"`
+}
+
+// SignalConfig defines how the data of a signal is published.
+type SignalConfig struct {
+	// Topic the data is published to.
+	Topic string `mapstructure:"topic"`
+	// Encoding of the messages: otlp_proto, otlp_json, or the ID of an encoding extension.
+	Encoding string `mapstructure:"encoding"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks the exporter configuration is valid.
+func (cfg *Config) Validate() error {
+	var errs []error
+	if cfg.QoS > 2 {
+		errs = append(errs, fmt.Errorf("invalid qos %d, it must be 0, 1 or 2", cfg.QoS))
+	}
+	if err := cfg.Traces.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("traces: %w", err))
+	}
+	if err := cfg.Metrics.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("metrics: %w", err))
+	}
+	if err := cfg.Logs.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("logs: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func (cfg SignalConfig) validate() error {
+	if cfg.Topic == "" {
+		return errors.New("topic must be specified")
+	}
+	if strings.ContainsAny(cfg.Topic, "+#") {
+		return fmt.Errorf("invalid topic %q: it must not contain the '+' or '#' wildcards", cfg.Topic)
+	}
+	if cfg.Encoding == "" {
+		return errors.New("encoding must be specified")
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqttexporter
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/mqttexporter/internal/metadata"
+	internalmqtt "github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	defaultConfig := createDefaultConfig().(*Config)
+
+	tests := []struct {
+		id          component.ID
+		expected    component.Config
+		expectedErr string
+	}{
+		{
+			id:       component.NewID(metadata.Type),
+			expected: defaultConfig,
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "all_fields"),
+			expected: &Config{
+				TimeoutSettings: exporterhelper.TimeoutConfig{Timeout: 10 * time.Second},
+				QueueSettings: func() exporterhelper.QueueConfig {
+					queue := exporterhelper.NewDefaultQueueConfig()
+					queue.Enabled = false
+					return queue
+				}(),
+				BackOffConfig: func() configretry.BackOffConfig {
+					retry := configretry.NewDefaultBackOffConfig()
+					retry.Enabled = false
+					return retry
+				}(),
+				ClientConfig: func() internalmqtt.ClientConfig {
+					client := internalmqtt.NewDefaultClientConfig()
+					client.Endpoint = "ssl://broker.example.com:8883"
+					client.ClientID = "gateway-1"
+					client.Username = "collector"
+					client.Password = "secret"
+					client.CleanSession = false
+					return client
+				}(),
+				QoS:    2,
+				Retain: true,
+				Traces: SignalConfig{
+					Topic:    "telemetry/traces",
+					Encoding: "otlp_json",
+				},
+				Metrics: defaultConfig.Metrics,
+				Logs: SignalConfig{
+					Topic:    "telemetry/logs",
+					Encoding: "text_encoding",
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "invalid"),
+			expectedErr: "invalid qos 3, it must be 0, 1 or 2\n" +
+				`traces: invalid topic "otlp/+/traces": it must not contain the '+' or '#' wildcards` + "\n" +
+				"metrics: topic must be specified\n" +
+				"logs: encoding must be specified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, component.ValidateConfig(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, component.ValidateConfig(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
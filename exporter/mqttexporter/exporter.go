@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqttexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/mqttexporter"
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/encoding"
+	internalmqtt "github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt"
+)
+
+// disconnectQuiesce is the time given to the client to complete the work in progress when disconnecting.
+const disconnectQuiesce = 250 * time.Millisecond
+
+// mqttExporter publishes the signals of its pipelines with a single client.
+type mqttExporter struct {
+	config   *Config
+	settings exporter.Settings
+	// starts load the marshalers of the signals the exporter has been created for.
+	starts []func(host component.Host) error
+
+	tracesMarshaler  ptrace.Marshaler
+	metricsMarshaler pmetric.Marshaler
+	logsMarshaler    plog.Marshaler
+
+	client internalmqtt.Client
+}
+
+func newMQTTExporter(config *Config, set exporter.Settings) *mqttExporter {
+	return &mqttExporter{
+		config:   config,
+		settings: set,
+	}
+}
+
+func (e *mqttExporter) registerTraces() {
+	e.starts = append(e.starts, func(host component.Host) (err error) {
+		e.tracesMarshaler, err = encoding.NewTracesMarshaler(e.config.Traces.Encoding, host)
+		return err
+	})
+}
+
+func (e *mqttExporter) registerMetrics() {
+	e.starts = append(e.starts, func(host component.Host) (err error) {
+		e.metricsMarshaler, err = encoding.NewMetricsMarshaler(e.config.Metrics.Encoding, host)
+		return err
+	})
+}
+
+func (e *mqttExporter) registerLogs() {
+	e.starts = append(e.starts, func(host component.Host) (err error) {
+		e.logsMarshaler, err = encoding.NewLogsMarshaler(e.config.Logs.Encoding, host)
+		return err
+	})
+}
+
+// Start loads the marshalers and connects to the broker.
+func (e *mqttExporter) Start(ctx context.Context, host component.Host) error {
+	for _, start := range e.starts {
+		if err := start(host); err != nil {
+			return err
+		}
+	}
+	var err error
+	e.client, err = internalmqtt.Connect(ctx, &e.config.ClientConfig, "otelcol-exporter-", e.settings.Logger)
+	return err
+}
+
+// Shutdown disconnects from the broker.
+func (e *mqttExporter) Shutdown(context.Context) error {
+	if e.client != nil {
+		e.client.Disconnect(disconnectQuiesce)
+	}
+	return nil
+}
+
+func (e *mqttExporter) publishTraces(ctx context.Context, td ptrace.Traces) error {
+	data, err := e.tracesMarshaler.MarshalTraces(td)
+	if err != nil {
+		return consumererror.NewPermanent(fmt.Errorf("failed to marshal traces: %w", err))
+	}
+	return e.publish(ctx, e.config.Traces.Topic, data)
+}
+
+func (e *mqttExporter) publishMetrics(ctx context.Context, md pmetric.Metrics) error {
+	data, err := e.metricsMarshaler.MarshalMetrics(md)
+	if err != nil {
+		return consumererror.NewPermanent(fmt.Errorf("failed to marshal metrics: %w", err))
+	}
+	return e.publish(ctx, e.config.Metrics.Topic, data)
+}
+
+func (e *mqttExporter) publishLogs(ctx context.Context, ld plog.Logs) error {
+	data, err := e.logsMarshaler.MarshalLogs(ld)
+	if err != nil {
+		return consumererror.NewPermanent(fmt.Errorf("failed to marshal logs: %w", err))
+	}
+	return e.publish(ctx, e.config.Logs.Topic, data)
+}
+
+// publish publishes a message and waits for the broker to acknowledge it, unless the QoS is 0.
+func (e *mqttExporter) publish(ctx context.Context, topic string, data []byte) error {
+	if err := e.client.Publish(ctx, topic, e.config.QoS, e.config.Retain, data); err != nil {
+		return fmt.Errorf("failed to publish to %q: %w", topic, err)
+	}
+	return nil
+}
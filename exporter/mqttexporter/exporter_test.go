@@ -0,0 +1,218 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqttexporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/encoding"
+	internalmqtt "github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt/mqtttest"
+)
+
+type testHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h *testHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+// failingMarshaler fails to marshal log records.
+type failingMarshaler struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+func (failingMarshaler) MarshalLogs(plog.Logs) ([]byte, error) {
+	return nil, errors.New("unsupported log record")
+}
+
+func newTestConfig(broker *mqtttest.Broker) *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = broker.Endpoint()
+	cfg.QueueSettings.Enabled = false
+	cfg.BackOffConfig.Enabled = false
+	return cfg
+}
+
+func newTestLogs() plog.Logs {
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("log")
+	return logs
+}
+
+func TestPublish(t *testing.T) {
+	broker := mqtttest.NewBroker(t)
+	cfg := newTestConfig(broker)
+	cfg.Metrics.Encoding = encoding.OTLPJSON
+	set := exportertest.NewNopSettings()
+	factory := NewFactory()
+
+	tracesExporter, err := factory.CreateTraces(context.Background(), set, cfg)
+	require.NoError(t, err)
+	metricsExporter, err := factory.CreateMetrics(context.Background(), set, cfg)
+	require.NoError(t, err)
+	logsExporter, err := factory.CreateLogs(context.Background(), set, cfg)
+	require.NoError(t, err)
+
+	host := componenttest.NewNopHost()
+	require.NoError(t, tracesExporter.Start(context.Background(), host))
+	require.NoError(t, metricsExporter.Start(context.Background(), host))
+	require.NoError(t, logsExporter.Start(context.Background(), host))
+	assert.Len(t, broker.ClientIDs(), 1)
+	assert.Regexp(t, "^otelcol-exporter-", broker.ClientIDs()[0])
+
+	traces := ptrace.NewTraces()
+	traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("span")
+	require.NoError(t, tracesExporter.ConsumeTraces(context.Background(), traces))
+	metrics := pmetric.NewMetrics()
+	metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(1)
+	require.NoError(t, metricsExporter.ConsumeMetrics(context.Background(), metrics))
+	require.NoError(t, logsExporter.ConsumeLogs(context.Background(), newTestLogs()))
+
+	require.NoError(t, tracesExporter.Shutdown(context.Background()))
+	require.NoError(t, metricsExporter.Shutdown(context.Background()))
+	require.NoError(t, logsExporter.Shutdown(context.Background()))
+
+	messages := broker.Messages()
+	require.Len(t, messages, 3)
+
+	assert.Equal(t, "otlp/traces", messages[0].Topic)
+	assert.Equal(t, byte(1), messages[0].QoS)
+	assert.False(t, messages[0].Retain)
+	gotTraces, err := (&ptrace.ProtoUnmarshaler{}).UnmarshalTraces(messages[0].Payload)
+	require.NoError(t, err)
+	assert.Equal(t, traces, gotTraces)
+
+	assert.Equal(t, "otlp/metrics", messages[1].Topic)
+	gotMetrics, err := (&pmetric.JSONUnmarshaler{}).UnmarshalMetrics(messages[1].Payload)
+	require.NoError(t, err)
+	assert.Equal(t, metrics, gotMetrics)
+
+	assert.Equal(t, "otlp/logs", messages[2].Topic)
+	gotLogs, err := (&plog.ProtoUnmarshaler{}).UnmarshalLogs(messages[2].Payload)
+	require.NoError(t, err)
+	assert.Equal(t, newTestLogs(), gotLogs)
+}
+
+func TestPublishQoSAndRetain(t *testing.T) {
+	for _, version := range []byte{internalmqtt.ProtocolVersion311, internalmqtt.ProtocolVersion5} {
+		for _, qos := range []byte{0, 1, 2} {
+			t.Run(fmt.Sprintf("mqtt_%d/qos_%d", version, qos), func(t *testing.T) {
+				broker := mqtttest.NewBroker(t)
+				cfg := newTestConfig(broker)
+				cfg.ProtocolVersion = uint(version)
+				cfg.QoS = qos
+				cfg.Retain = true
+				cfg.Logs.Topic = "devices/42/logs"
+
+				exp, err := NewFactory().CreateLogs(context.Background(), exportertest.NewNopSettings(), cfg)
+				require.NoError(t, err)
+				require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+				require.NoError(t, exp.ConsumeLogs(context.Background(), newTestLogs()))
+				require.NoError(t, exp.Shutdown(context.Background()))
+
+				require.Eventually(t, func() bool {
+					return len(broker.Messages()) == 1
+				}, 5*time.Second, 10*time.Millisecond)
+				assert.Equal(t, []byte{version}, broker.ProtocolVersions())
+				msg := broker.Messages()[0]
+				assert.Equal(t, qos, msg.QoS)
+				assert.True(t, msg.Retain)
+				retained, ok := broker.Retained("devices/42/logs")
+				require.True(t, ok)
+				assert.Equal(t, msg.Payload, retained.Payload)
+			})
+		}
+	}
+}
+
+func TestPublishErrors(t *testing.T) {
+	broker := mqtttest.NewBroker(t)
+	cfg := newTestConfig(broker)
+	cfg.Logs.Encoding = "failing_encoding"
+	host := &testHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{component.MustNewID("failing_encoding"): failingMarshaler{}},
+	}
+
+	exp, err := NewFactory().CreateLogs(context.Background(), exportertest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), host))
+	defer func() {
+		assert.NoError(t, exp.Shutdown(context.Background()))
+	}()
+
+	err = exp.ConsumeLogs(context.Background(), newTestLogs())
+	assert.ErrorContains(t, err, "failed to marshal logs: unsupported log record")
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.Empty(t, broker.Messages())
+}
+
+func TestPublishAfterDisconnecting(t *testing.T) {
+	broker := mqtttest.NewBroker(t)
+	exp := newMQTTExporter(newTestConfig(broker), exportertest.NewNopSettings())
+	exp.registerLogs()
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, exp.Shutdown(context.Background()))
+
+	assert.ErrorContains(t, exp.publishLogs(context.Background(), newTestLogs()), `failed to publish to "otlp/logs"`)
+}
+
+func TestEncodingErrors(t *testing.T) {
+	broker := mqtttest.NewBroker(t)
+	host := &testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{
+			component.MustNewID("failing_encoding"): failingMarshaler{},
+		},
+	}
+	tests := []struct {
+		name        string
+		encoding    string
+		expectedErr string
+	}{
+		{
+			name:        "missing extension",
+			encoding:    "json_log_encoding",
+			expectedErr: `unknown encoding extension "json_log_encoding"`,
+		},
+		{
+			name:        "not a marshaler",
+			encoding:    "failing_encoding",
+			expectedErr: `extension "failing_encoding" is not a marshaler`,
+		},
+		{
+			name:        "invalid extension ID",
+			encoding:    "1nvalid",
+			expectedErr: `invalid encoding "1nvalid"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(broker)
+			cfg.Traces.Encoding = tt.encoding
+			exp, err := NewFactory().CreateTraces(context.Background(), exportertest.NewNopSettings(), cfg)
+			require.NoError(t, err)
+			assert.ErrorContains(t, exp.Start(context.Background(), host), tt.expectedErr)
+			assert.NoError(t, exp.Shutdown(context.Background()))
+		})
+	}
+}
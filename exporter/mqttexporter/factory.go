@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqttexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/mqttexporter"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/mqttexporter/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/encoding"
+	internalmqtt "github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/sharedcomponent"
+)
+
+const defaultQoS = 1
+
+// NewFactory creates a factory for the MQTT exporter.
+func NewFactory() exporter.Factory {
+	return exporter.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		exporter.WithTraces(createTracesExporter, metadata.TracesStability),
+		exporter.WithMetrics(createMetricsExporter, metadata.MetricsStability),
+		exporter.WithLogs(createLogsExporter, metadata.LogsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		TimeoutSettings: exporterhelper.NewDefaultTimeoutConfig(),
+		QueueSettings:   exporterhelper.NewDefaultQueueConfig(),
+		BackOffConfig:   configretry.NewDefaultBackOffConfig(),
+		ClientConfig:    internalmqtt.NewDefaultClientConfig(),
+		QoS:             defaultQoS,
+		Traces: SignalConfig{
+			Topic:    "otlp/traces",
+			Encoding: encoding.OTLPProto,
+		},
+		Metrics: SignalConfig{
+			Topic:    "otlp/metrics",
+			Encoding: encoding.OTLPProto,
+		},
+		Logs: SignalConfig{
+			Topic:    "otlp/logs",
+			Encoding: encoding.OTLPProto,
+		},
+	}
+}
+
+func createTracesExporter(ctx context.Context, set exporter.Settings, cfg component.Config) (exporter.Traces, error) {
+	config := cfg.(*Config)
+	shared := getOrAddExporter(config, set)
+	exp := shared.Unwrap().(*mqttExporter)
+	exp.registerTraces()
+	return exporterhelper.NewTraces(
+		ctx,
+		set,
+		cfg,
+		exp.publishTraces,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		exporterhelper.WithTimeout(config.TimeoutSettings),
+		exporterhelper.WithRetry(config.BackOffConfig),
+		exporterhelper.WithQueue(config.QueueSettings),
+		exporterhelper.WithStart(shared.Start),
+		exporterhelper.WithShutdown(shared.Shutdown),
+	)
+}
+
+func createMetricsExporter(ctx context.Context, set exporter.Settings, cfg component.Config) (exporter.Metrics, error) {
+	config := cfg.(*Config)
+	shared := getOrAddExporter(config, set)
+	exp := shared.Unwrap().(*mqttExporter)
+	exp.registerMetrics()
+	return exporterhelper.NewMetrics(
+		ctx,
+		set,
+		cfg,
+		exp.publishMetrics,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		exporterhelper.WithTimeout(config.TimeoutSettings),
+		exporterhelper.WithRetry(config.BackOffConfig),
+		exporterhelper.WithQueue(config.QueueSettings),
+		exporterhelper.WithStart(shared.Start),
+		exporterhelper.WithShutdown(shared.Shutdown),
+	)
+}
+
+func createLogsExporter(ctx context.Context, set exporter.Settings, cfg component.Config) (exporter.Logs, error) {
+	config := cfg.(*Config)
+	shared := getOrAddExporter(config, set)
+	exp := shared.Unwrap().(*mqttExporter)
+	exp.registerLogs()
+	return exporterhelper.NewLogs(
+		ctx,
+		set,
+		cfg,
+		exp.publishLogs,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		exporterhelper.WithTimeout(config.TimeoutSettings),
+		exporterhelper.WithRetry(config.BackOffConfig),
+		exporterhelper.WithQueue(config.QueueSettings),
+		exporterhelper.WithStart(shared.Start),
+		exporterhelper.WithShutdown(shared.Shutdown),
+	)
+}
+
+// getOrAddExporter returns the exporter of a configuration, so that the signals share a single client.
+func getOrAddExporter(cfg *Config, set exporter.Settings) *sharedcomponent.SharedComponent {
+	return exporters.GetOrAdd(cfg, func() component.Component {
+		return newMQTTExporter(cfg, set)
+	})
+}
+
+var exporters = sharedcomponent.NewSharedComponents()
@@ -0,0 +1,37 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqttexporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/encoding"
+	internalmqtt "github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.Equal(t, internalmqtt.NewDefaultClientConfig(), cfg.ClientConfig)
+	assert.Equal(t, byte(defaultQoS), cfg.QoS)
+	assert.False(t, cfg.Retain)
+	assert.Equal(t, SignalConfig{Topic: "otlp/traces", Encoding: encoding.OTLPProto}, cfg.Traces)
+	assert.Equal(t, SignalConfig{Topic: "otlp/metrics", Encoding: encoding.OTLPProto}, cfg.Metrics)
+	assert.Equal(t, SignalConfig{Topic: "otlp/logs", Encoding: encoding.OTLPProto}, cfg.Logs)
+}
+
+func TestStartFailsWithoutBroker(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "tcp://127.0.0.1:1"
+
+	exp, err := NewFactory().CreateLogs(context.Background(), exportertest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	assert.ErrorContains(t, exp.Start(context.Background(), componenttest.NewNopHost()), "failed to connect to the MQTT broker")
+	assert.NoError(t, exp.Shutdown(context.Background()))
+}
@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package natsjetstreamexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/natsjetstreamexporter"
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+
+	internalnats "github.com/open-telemetry/opentelemetry-collector-contrib/internal/nats"
+)
+
+// Config defines configuration for the NATS JetStream exporter.
+type Config struct {
+	TimeoutSettings           exporterhelper.TimeoutConfig `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+	QueueSettings             exporterhelper.QueueConfig   `mapstructure:"sending_queue"`
+	configretry.BackOffConfig `mapstructure:"retry_on_failure"`
+
+	internalnats.ClientConfig `mapstructure:",squash"`
+
+	Traces  SignalConfig `mapstructure:"traces"`
+	Metrics SignalConfig `mapstructure:"metrics"`
+	Logs    SignalConfig `mapstructure:"logs"`
+}
+
+// SignalConfig defines how the data of a signal is published.
+type SignalConfig struct {
+	// Subject the data is published to. It can reference resource attributes between braces,
+	// e.g. otlp.logs.{service.name}, to publish the data of each resource to its own subject.
+	Subject string `mapstructure:"subject"`
+	// Encoding of the messages: otlp_proto, otlp_json, or the ID of an encoding extension.
+	Encoding string `mapstructure:"encoding"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks the exporter configuration is valid.
+func (cfg *Config) Validate() error {
+	var errs []error
+	if err := cfg.Traces.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("traces: %w", err))
+	}
+	if err := cfg.Metrics.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("metrics: %w", err))
+	}
+	if err := cfg.Logs.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("logs: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func (cfg SignalConfig) validate() error {
+	if _, err := parseSubjectTemplate(cfg.Subject); err != nil {
+		return err
+	}
+	if cfg.Encoding == "" {
+		return errors.New("encoding must be specified")
+	}
+	return nil
+}
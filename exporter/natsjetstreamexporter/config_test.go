@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package natsjetstreamexporter
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/natsjetstreamexporter/internal/metadata"
+	internalnats "github.com/open-telemetry/opentelemetry-collector-contrib/internal/nats"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	defaultConfig := createDefaultConfig().(*Config)
+
+	tests := []struct {
+		id          component.ID
+		expected    component.Config
+		expectedErr string
+	}{
+		{
+			id:       component.NewID(metadata.Type),
+			expected: defaultConfig,
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "all_fields"),
+			expected: &Config{
+				TimeoutSettings: exporterhelper.TimeoutConfig{Timeout: 10 * time.Second},
+				QueueSettings: func() exporterhelper.QueueConfig {
+					queue := exporterhelper.NewDefaultQueueConfig()
+					queue.Enabled = false
+					return queue
+				}(),
+				BackOffConfig: func() configretry.BackOffConfig {
+					retry := configretry.NewDefaultBackOffConfig()
+					retry.Enabled = false
+					return retry
+				}(),
+				ClientConfig: internalnats.ClientConfig{
+					Endpoint: "nats://nats-1.example.com:4222,nats://nats-2.example.com:4222",
+					Auth:     internalnats.AuthConfig{Username: "collector", Password: "secret"},
+				},
+				Traces: SignalConfig{
+					Subject:  "otlp.traces.{service.name}",
+					Encoding: "otlp_json",
+				},
+				Metrics: defaultConfig.Metrics,
+				Logs: SignalConfig{
+					Subject:  "logs.{k8s.namespace.name}.{service.name}",
+					Encoding: "text_encoding",
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "invalid_subjects"),
+			expectedErr: `traces: invalid subject "otlp.traces.>": it must not contain whitespaces or wildcards` + "\n" +
+				`metrics: invalid subject "otlp.metrics.{service.name": unclosed '{'` + "\n" +
+				"logs: subject must be specified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, component.ValidateConfig(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, component.ValidateConfig(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
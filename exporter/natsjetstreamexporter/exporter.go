@@ -0,0 +1,234 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package natsjetstreamexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/natsjetstreamexporter"
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/encoding"
+	internalnats "github.com/open-telemetry/opentelemetry-collector-contrib/internal/nats"
+)
+
+// publisher publishes messages to JetStream and waits for their acknowledgment.
+type publisher interface {
+	PublishMsg(ctx context.Context, msg *nats.Msg, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error)
+}
+
+type jetstreamExporter struct {
+	config   *Config
+	signal   SignalConfig
+	subject  *subjectTemplate
+	settings exporter.Settings
+
+	tracesMarshaler  ptrace.Marshaler
+	metricsMarshaler pmetric.Marshaler
+	logsMarshaler    plog.Marshaler
+
+	conn      *nats.Conn
+	publisher publisher
+}
+
+func newJetstreamExporter(config *Config, signal SignalConfig, set exporter.Settings) (*jetstreamExporter, error) {
+	subject, err := parseSubjectTemplate(signal.Subject)
+	if err != nil {
+		return nil, err
+	}
+	return &jetstreamExporter{
+		config:   config,
+		signal:   signal,
+		subject:  subject,
+		settings: set,
+	}, nil
+}
+
+func (e *jetstreamExporter) connect(ctx context.Context) error {
+	conn, err := internalnats.Connect(ctx, &e.config.ClientConfig, e.settings.ID.String(), e.settings.Logger)
+	if err != nil {
+		return err
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to create the JetStream context: %w", err)
+	}
+	e.conn = conn
+	e.publisher = js
+	return nil
+}
+
+func (e *jetstreamExporter) startTraces(ctx context.Context, host component.Host) error {
+	m, err := encoding.NewTracesMarshaler(e.signal.Encoding, host)
+	if err != nil {
+		return err
+	}
+	e.tracesMarshaler = m
+	return e.connect(ctx)
+}
+
+func (e *jetstreamExporter) startMetrics(ctx context.Context, host component.Host) error {
+	m, err := encoding.NewMetricsMarshaler(e.signal.Encoding, host)
+	if err != nil {
+		return err
+	}
+	e.metricsMarshaler = m
+	return e.connect(ctx)
+}
+
+func (e *jetstreamExporter) startLogs(ctx context.Context, host component.Host) error {
+	m, err := encoding.NewLogsMarshaler(e.signal.Encoding, host)
+	if err != nil {
+		return err
+	}
+	e.logsMarshaler = m
+	return e.connect(ctx)
+}
+
+func (e *jetstreamExporter) shutdown(context.Context) error {
+	if e.conn != nil {
+		e.conn.Close()
+	}
+	return nil
+}
+
+// publishTraces publishes the spans of each resource to the subject rendered from its attributes.
+func (e *jetstreamExporter) publishTraces(ctx context.Context, td ptrace.Traces) error {
+	if e.subject.isStatic() {
+		return e.marshalAndPublishTraces(ctx, e.subject.render(pcommon.NewMap()), td)
+	}
+
+	subjects := []string{}
+	groups := map[string]ptrace.Traces{}
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		subject := e.subject.render(rs.Resource().Attributes())
+		group, ok := groups[subject]
+		if !ok {
+			group = ptrace.NewTraces()
+			groups[subject] = group
+			subjects = append(subjects, subject)
+		}
+		rs.CopyTo(group.ResourceSpans().AppendEmpty())
+	}
+
+	var errs []error
+	for _, subject := range subjects {
+		errs = append(errs, e.marshalAndPublishTraces(ctx, subject, groups[subject]))
+	}
+	return errors.Join(errs...)
+}
+
+func (e *jetstreamExporter) marshalAndPublishTraces(ctx context.Context, subject string, td ptrace.Traces) error {
+	data, err := e.tracesMarshaler.MarshalTraces(td)
+	if err != nil {
+		return consumererror.NewPermanent(fmt.Errorf("failed to marshal traces: %w", err))
+	}
+	return e.publish(ctx, subject, data)
+}
+
+// publishMetrics publishes the metrics of each resource to the subject rendered from its attributes.
+func (e *jetstreamExporter) publishMetrics(ctx context.Context, md pmetric.Metrics) error {
+	if e.subject.isStatic() {
+		return e.marshalAndPublishMetrics(ctx, e.subject.render(pcommon.NewMap()), md)
+	}
+
+	subjects := []string{}
+	groups := map[string]pmetric.Metrics{}
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		subject := e.subject.render(rm.Resource().Attributes())
+		group, ok := groups[subject]
+		if !ok {
+			group = pmetric.NewMetrics()
+			groups[subject] = group
+			subjects = append(subjects, subject)
+		}
+		rm.CopyTo(group.ResourceMetrics().AppendEmpty())
+	}
+
+	var errs []error
+	for _, subject := range subjects {
+		errs = append(errs, e.marshalAndPublishMetrics(ctx, subject, groups[subject]))
+	}
+	return errors.Join(errs...)
+}
+
+func (e *jetstreamExporter) marshalAndPublishMetrics(ctx context.Context, subject string, md pmetric.Metrics) error {
+	data, err := e.metricsMarshaler.MarshalMetrics(md)
+	if err != nil {
+		return consumererror.NewPermanent(fmt.Errorf("failed to marshal metrics: %w", err))
+	}
+	return e.publish(ctx, subject, data)
+}
+
+// publishLogs publishes the log records of each resource to the subject rendered from its attributes.
+func (e *jetstreamExporter) publishLogs(ctx context.Context, ld plog.Logs) error {
+	if e.subject.isStatic() {
+		return e.marshalAndPublishLogs(ctx, e.subject.render(pcommon.NewMap()), ld)
+	}
+
+	subjects := []string{}
+	groups := map[string]plog.Logs{}
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		subject := e.subject.render(rl.Resource().Attributes())
+		group, ok := groups[subject]
+		if !ok {
+			group = plog.NewLogs()
+			groups[subject] = group
+			subjects = append(subjects, subject)
+		}
+		rl.CopyTo(group.ResourceLogs().AppendEmpty())
+	}
+
+	var errs []error
+	for _, subject := range subjects {
+		errs = append(errs, e.marshalAndPublishLogs(ctx, subject, groups[subject]))
+	}
+	return errors.Join(errs...)
+}
+
+func (e *jetstreamExporter) marshalAndPublishLogs(ctx context.Context, subject string, ld plog.Logs) error {
+	data, err := e.logsMarshaler.MarshalLogs(ld)
+	if err != nil {
+		return consumererror.NewPermanent(fmt.Errorf("failed to marshal logs: %w", err))
+	}
+	return e.publish(ctx, subject, data)
+}
+
+// publish publishes a message and waits for the stream to acknowledge it. The message ID is derived from the
+// subject and the data, so that the stream discards the messages published again when a request is retried.
+func (e *jetstreamExporter) publish(ctx context.Context, subject string, data []byte) error {
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	msg.Header.Set(jetstream.MsgIDHeader, messageID(subject, data))
+	if _, err := e.publisher.PublishMsg(ctx, msg); err != nil {
+		return fmt.Errorf("failed to publish to %q: %w", subject, err)
+	}
+	return nil
+}
+
+func messageID(subject string, data []byte) string {
+	h := sha256.New()
+	h.Write([]byte(subject))
+	h.Write([]byte{0})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
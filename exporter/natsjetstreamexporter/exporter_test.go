@@ -0,0 +1,197 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package natsjetstreamexporter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/encoding"
+)
+
+// fakePublisher records the published messages, failing the subjects of failSubjects.
+type fakePublisher struct {
+	msgs         []*nats.Msg
+	failSubjects map[string]bool
+}
+
+func (p *fakePublisher) PublishMsg(_ context.Context, msg *nats.Msg, _ ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	if p.failSubjects[msg.Subject] {
+		return nil, jetstream.ErrNoStreamResponse
+	}
+	p.msgs = append(p.msgs, msg)
+	return &jetstream.PubAck{Stream: "OTEL"}, nil
+}
+
+func newTestExporter(t *testing.T, signal SignalConfig) (*jetstreamExporter, *fakePublisher) {
+	exp, err := newJetstreamExporter(createDefaultConfig().(*Config), signal, exportertest.NewNopSettings())
+	require.NoError(t, err)
+	exp.tracesMarshaler = &ptrace.ProtoMarshaler{}
+	exp.metricsMarshaler = &pmetric.ProtoMarshaler{}
+	exp.logsMarshaler = &plog.ProtoMarshaler{}
+	pub := &fakePublisher{}
+	exp.publisher = pub
+	return exp, pub
+}
+
+func newTestLogs(services ...string) plog.Logs {
+	logs := plog.NewLogs()
+	for _, service := range services {
+		rl := logs.ResourceLogs().AppendEmpty()
+		if service != "" {
+			rl.Resource().Attributes().PutStr("service.name", service)
+		}
+		rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr(service)
+	}
+	return logs
+}
+
+func TestPublishStaticSubject(t *testing.T) {
+	exp, pub := newTestExporter(t, SignalConfig{Subject: "otlp.logs", Encoding: encoding.OTLPProto})
+
+	logs := newTestLogs("checkout", "cart")
+	require.NoError(t, exp.publishLogs(context.Background(), logs))
+	require.Len(t, pub.msgs, 1)
+	assert.Equal(t, "otlp.logs", pub.msgs[0].Subject)
+
+	received, err := (&plog.ProtoUnmarshaler{}).UnmarshalLogs(pub.msgs[0].Data)
+	require.NoError(t, err)
+	assert.Equal(t, logs, received)
+}
+
+func TestPublishTemplatedSubject(t *testing.T) {
+	exp, pub := newTestExporter(t, SignalConfig{Subject: "otlp.logs.{service.name}", Encoding: encoding.OTLPProto})
+
+	require.NoError(t, exp.publishLogs(context.Background(), newTestLogs("checkout", "cart", "checkout", "")))
+	require.Len(t, pub.msgs, 3)
+
+	subjects := map[string]int{}
+	for _, msg := range pub.msgs {
+		logs, err := (&plog.ProtoUnmarshaler{}).UnmarshalLogs(msg.Data)
+		require.NoError(t, err)
+		subjects[msg.Subject] = logs.ResourceLogs().Len()
+	}
+	assert.Equal(t, map[string]int{
+		"otlp.logs.checkout": 2,
+		"otlp.logs.cart":     1,
+		"otlp.logs.unknown":  1,
+	}, subjects)
+	assert.Equal(t, "otlp.logs.checkout", pub.msgs[0].Subject)
+}
+
+func TestPublishTracesAndMetrics(t *testing.T) {
+	exp, pub := newTestExporter(t, SignalConfig{Subject: "otlp.{service.name}", Encoding: encoding.OTLPProto})
+
+	traces := ptrace.NewTraces()
+	rs := traces.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", "checkout")
+	rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("span")
+	require.NoError(t, exp.publishTraces(context.Background(), traces))
+
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "cart")
+	rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(1)
+	require.NoError(t, exp.publishMetrics(context.Background(), metrics))
+
+	require.Len(t, pub.msgs, 2)
+	assert.Equal(t, "otlp.checkout", pub.msgs[0].Subject)
+	receivedTraces, err := (&ptrace.ProtoUnmarshaler{}).UnmarshalTraces(pub.msgs[0].Data)
+	require.NoError(t, err)
+	assert.Equal(t, traces, receivedTraces)
+
+	assert.Equal(t, "otlp.cart", pub.msgs[1].Subject)
+	receivedMetrics, err := (&pmetric.ProtoUnmarshaler{}).UnmarshalMetrics(pub.msgs[1].Data)
+	require.NoError(t, err)
+	assert.Equal(t, metrics, receivedMetrics)
+}
+
+func TestPublishMessageID(t *testing.T) {
+	exp, pub := newTestExporter(t, SignalConfig{Subject: "otlp.logs.{service.name}", Encoding: encoding.OTLPProto})
+
+	// Publishing the same data again, e.g. when a request is retried, uses the same message ID.
+	require.NoError(t, exp.publishLogs(context.Background(), newTestLogs("checkout", "cart")))
+	require.NoError(t, exp.publishLogs(context.Background(), newTestLogs("checkout", "cart")))
+	require.Len(t, pub.msgs, 4)
+
+	ids := []string{}
+	for _, msg := range pub.msgs {
+		ids = append(ids, msg.Header.Get(jetstream.MsgIDHeader))
+	}
+	assert.Equal(t, messageID("otlp.logs.checkout", pub.msgs[0].Data), ids[0])
+	assert.Len(t, ids[0], 64)
+	assert.Equal(t, ids[0], ids[2])
+	assert.Equal(t, ids[1], ids[3])
+	assert.NotEqual(t, ids[0], ids[1])
+}
+
+func TestPublishErrors(t *testing.T) {
+	exp, pub := newTestExporter(t, SignalConfig{Subject: "otlp.logs.{service.name}", Encoding: encoding.OTLPProto})
+	pub.failSubjects = map[string]bool{"otlp.logs.cart": true}
+
+	err := exp.publishLogs(context.Background(), newTestLogs("checkout", "cart"))
+	assert.ErrorIs(t, err, jetstream.ErrNoStreamResponse)
+	assert.ErrorContains(t, err, `failed to publish to "otlp.logs.cart"`)
+	assert.False(t, consumererror.IsPermanent(err))
+	// The other subjects are still published.
+	require.Len(t, pub.msgs, 1)
+	assert.Equal(t, "otlp.logs.checkout", pub.msgs[0].Subject)
+
+	exp.logsMarshaler = failingMarshaler{}
+	err = exp.publishLogs(context.Background(), newTestLogs("checkout"))
+	assert.True(t, consumererror.IsPermanent(err))
+}
+
+type failingMarshaler struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+func (failingMarshaler) MarshalLogs(plog.Logs) ([]byte, error) {
+	return nil, errors.New("marshal failed")
+}
+
+type testHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h *testHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+func TestEncodingExtension(t *testing.T) {
+	host := &testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{
+			component.MustNewID("failing_encoding"): failingMarshaler{},
+		},
+	}
+
+	m, err := encoding.NewLogsMarshaler("failing_encoding", host)
+	require.NoError(t, err)
+	assert.Equal(t, failingMarshaler{}, m)
+
+	_, err = encoding.NewTracesMarshaler("failing_encoding", host)
+	assert.EqualError(t, err, `extension "failing_encoding" is not a marshaler`)
+
+	_, err = encoding.NewMetricsMarshaler("otlp_avro", host)
+	assert.EqualError(t, err, `unknown encoding extension "otlp_avro"`)
+
+	_, err = encoding.NewMetricsMarshaler("1nvalid", host)
+	assert.ErrorContains(t, err, `invalid encoding "1nvalid"`)
+}
@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package natsjetstreamexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/natsjetstreamexporter"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/natsjetstreamexporter/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/encoding"
+	internalnats "github.com/open-telemetry/opentelemetry-collector-contrib/internal/nats"
+)
+
+const defaultEndpoint = "nats://localhost:4222"
+
+// NewFactory creates a factory for the NATS JetStream exporter.
+func NewFactory() exporter.Factory {
+	return exporter.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		exporter.WithTraces(createTracesExporter, metadata.TracesStability),
+		exporter.WithMetrics(createMetricsExporter, metadata.MetricsStability),
+		exporter.WithLogs(createLogsExporter, metadata.LogsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		TimeoutSettings: exporterhelper.NewDefaultTimeoutConfig(),
+		QueueSettings:   exporterhelper.NewDefaultQueueConfig(),
+		BackOffConfig:   configretry.NewDefaultBackOffConfig(),
+		ClientConfig: internalnats.ClientConfig{
+			Endpoint: defaultEndpoint,
+		},
+		Traces: SignalConfig{
+			Subject:  "otlp.traces",
+			Encoding: encoding.OTLPProto,
+		},
+		Metrics: SignalConfig{
+			Subject:  "otlp.metrics",
+			Encoding: encoding.OTLPProto,
+		},
+		Logs: SignalConfig{
+			Subject:  "otlp.logs",
+			Encoding: encoding.OTLPProto,
+		},
+	}
+}
+
+func createTracesExporter(ctx context.Context, set exporter.Settings, cfg component.Config) (exporter.Traces, error) {
+	config := cfg.(*Config)
+	exp, err := newJetstreamExporter(config, config.Traces, set)
+	if err != nil {
+		return nil, err
+	}
+	return exporterhelper.NewTraces(
+		ctx,
+		set,
+		cfg,
+		exp.publishTraces,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		exporterhelper.WithTimeout(config.TimeoutSettings),
+		exporterhelper.WithRetry(config.BackOffConfig),
+		exporterhelper.WithQueue(config.QueueSettings),
+		exporterhelper.WithStart(exp.startTraces),
+		exporterhelper.WithShutdown(exp.shutdown),
+	)
+}
+
+func createMetricsExporter(ctx context.Context, set exporter.Settings, cfg component.Config) (exporter.Metrics, error) {
+	config := cfg.(*Config)
+	exp, err := newJetstreamExporter(config, config.Metrics, set)
+	if err != nil {
+		return nil, err
+	}
+	return exporterhelper.NewMetrics(
+		ctx,
+		set,
+		cfg,
+		exp.publishMetrics,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		exporterhelper.WithTimeout(config.TimeoutSettings),
+		exporterhelper.WithRetry(config.BackOffConfig),
+		exporterhelper.WithQueue(config.QueueSettings),
+		exporterhelper.WithStart(exp.startMetrics),
+		exporterhelper.WithShutdown(exp.shutdown),
+	)
+}
+
+func createLogsExporter(ctx context.Context, set exporter.Settings, cfg component.Config) (exporter.Logs, error) {
+	config := cfg.(*Config)
+	exp, err := newJetstreamExporter(config, config.Logs, set)
+	if err != nil {
+		return nil, err
+	}
+	return exporterhelper.NewLogs(
+		ctx,
+		set,
+		cfg,
+		exp.publishLogs,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		exporterhelper.WithTimeout(config.TimeoutSettings),
+		exporterhelper.WithRetry(config.BackOffConfig),
+		exporterhelper.WithQueue(config.QueueSettings),
+		exporterhelper.WithStart(exp.startLogs),
+		exporterhelper.WithShutdown(exp.shutdown),
+	)
+}
@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package natsjetstreamexporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+)
+
+func TestCreateExporters(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	set := exportertest.NewNopSettings()
+
+	traces, err := factory.CreateTraces(context.Background(), set, cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, traces)
+
+	metrics, err := factory.CreateMetrics(context.Background(), set, cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, metrics)
+
+	logs, err := factory.CreateLogs(context.Background(), set, cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, logs)
+}
+
+func TestCreateExporterInvalidSubject(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Logs.Subject = "otlp.>"
+
+	_, err := NewFactory().CreateLogs(context.Background(), exportertest.NewNopSettings(), cfg)
+	assert.EqualError(t, err, `invalid subject "otlp.>": it must not contain whitespaces or wildcards`)
+}
+
+func TestStartFailsWithoutServer(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "nats://127.0.0.1:1"
+
+	exp, err := NewFactory().CreateLogs(context.Background(), exportertest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	assert.ErrorContains(t, exp.Start(context.Background(), componenttest.NewNopHost()), "failed to connect to the NATS server")
+	assert.NoError(t, exp.Shutdown(context.Background()))
+}
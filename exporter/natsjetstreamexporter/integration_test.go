@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package natsjetstreamexporter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestPublishLogs(t *testing.T) {
+	endpoint := startNATSContainer(t)
+
+	nc, err := nats.Connect(endpoint)
+	require.NoError(t, err)
+	defer nc.Close()
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+	stream, err := js.CreateStream(context.Background(), jetstream.StreamConfig{
+		Name:     "OTEL",
+		Subjects: []string{"otlp.>"},
+	})
+	require.NoError(t, err)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = endpoint
+	cfg.QueueSettings.Enabled = false
+	cfg.Logs.Subject = "otlp.logs.{service.name}"
+	exp, err := NewFactory().CreateLogs(context.Background(), exportertest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, exp.Shutdown(context.Background()))
+	}()
+
+	// The second export is discarded by the stream because the messages have the same IDs.
+	require.NoError(t, exp.ConsumeLogs(context.Background(), newTestLogs("checkout", "cart")))
+	require.NoError(t, exp.ConsumeLogs(context.Background(), newTestLogs("checkout", "cart")))
+
+	info, err := stream.Info(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), info.State.Msgs)
+
+	msg, err := stream.GetLastMsgForSubject(context.Background(), "otlp.logs.checkout")
+	require.NoError(t, err)
+	logs, err := (&plog.ProtoUnmarshaler{}).UnmarshalLogs(msg.Data)
+	require.NoError(t, err)
+	assert.Equal(t, newTestLogs("checkout"), logs)
+}
+
+func startNATSContainer(t *testing.T) string {
+	req := testcontainers.ContainerRequest{
+		Image:        "nats:latest",
+		Cmd:          []string{"-js"},
+		ExposedPorts: []string{"4222/tcp"},
+		WaitingFor:   wait.ForLog("Server is ready").WithStartupTimeout(time.Minute),
+	}
+	container, err := testcontainers.GenericContainer(context.Background(), testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, container.Terminate(context.Background()))
+	})
+
+	host, err := container.Host(context.Background())
+	require.NoError(t, err)
+	port, err := container.MappedPort(context.Background(), "4222")
+	require.NoError(t, err)
+	return fmt.Sprintf("nats://%s:%s", host, port.Port())
+}
@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package natsjetstreamexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/natsjetstreamexporter"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// unknownAttributeValue replaces the attributes referenced by a subject that a resource doesn't have.
+const unknownAttributeValue = "unknown"
+
+// subjectTemplate is a subject whose placeholders, e.g. {service.name}, are replaced by the values of resource attributes.
+type subjectTemplate struct {
+	// literals and attributes alternate: the subject is literals[0] + attributes[0] + literals[1] + ...
+	literals   []string
+	attributes []string
+}
+
+func parseSubjectTemplate(subject string) (*subjectTemplate, error) {
+	if subject == "" {
+		return nil, errors.New("subject must be specified")
+	}
+
+	t := &subjectTemplate{}
+	rest := subject
+	for {
+		start := strings.IndexAny(rest, "{}")
+		if start < 0 {
+			t.literals = append(t.literals, rest)
+			break
+		}
+		if rest[start] == '}' {
+			return nil, fmt.Errorf("invalid subject %q: unexpected '}'", subject)
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid subject %q: unclosed '{'", subject)
+		}
+		attribute := rest[start+1 : start+end]
+		if attribute == "" || strings.ContainsAny(attribute, "{ \t") {
+			return nil, fmt.Errorf("invalid subject %q: invalid attribute placeholder %q", subject, rest[start:start+end+1])
+		}
+		t.literals = append(t.literals, rest[:start])
+		t.attributes = append(t.attributes, attribute)
+		rest = rest[start+end+1:]
+	}
+
+	for _, literal := range t.literals {
+		if strings.ContainsAny(literal, " \t\r\n*>") {
+			return nil, fmt.Errorf("invalid subject %q: it must not contain whitespaces or wildcards", subject)
+		}
+	}
+	for _, token := range strings.Split(t.render(pcommon.NewMap()), ".") {
+		if token == "" {
+			return nil, fmt.Errorf("invalid subject %q: it must not contain empty tokens", subject)
+		}
+	}
+	return t, nil
+}
+
+// isStatic returns whether the subject doesn't reference any attribute.
+func (t *subjectTemplate) isStatic() bool {
+	return len(t.attributes) == 0
+}
+
+// render returns the subject for a resource. Attribute values are sanitized so that each of them is a single token.
+func (t *subjectTemplate) render(attrs pcommon.Map) string {
+	if t.isStatic() {
+		return t.literals[0]
+	}
+	var sb strings.Builder
+	for i, literal := range t.literals {
+		sb.WriteString(literal)
+		if i == len(t.attributes) {
+			break
+		}
+		value := unknownAttributeValue
+		if v, ok := attrs.Get(t.attributes[i]); ok && v.AsString() != "" {
+			value = v.AsString()
+		}
+		sb.WriteString(sanitizeToken(value))
+	}
+	return sb.String()
+}
+
+func sanitizeToken(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n', '.', '*', '>':
+			return '_'
+		}
+		return r
+	}, value)
+}
@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package natsjetstreamexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestParseSubjectTemplateErrors(t *testing.T) {
+	tests := []struct {
+		subject     string
+		expectedErr string
+	}{
+		{
+			subject:     "",
+			expectedErr: "subject must be specified",
+		},
+		{
+			subject:     "otlp.*",
+			expectedErr: `invalid subject "otlp.*": it must not contain whitespaces or wildcards`,
+		},
+		{
+			subject:     "otlp logs",
+			expectedErr: `invalid subject "otlp logs": it must not contain whitespaces or wildcards`,
+		},
+		{
+			subject:     "otlp.{service.name",
+			expectedErr: `invalid subject "otlp.{service.name": unclosed '{'`,
+		},
+		{
+			subject:     "otlp.service.name}",
+			expectedErr: `invalid subject "otlp.service.name}": unexpected '}'`,
+		},
+		{
+			subject:     "otlp.{}",
+			expectedErr: `invalid subject "otlp.{}": invalid attribute placeholder "{}"`,
+		},
+		{
+			subject:     "otlp.{service.{name}",
+			expectedErr: `invalid subject "otlp.{service.{name}": invalid attribute placeholder "{service.{name}"`,
+		},
+		{
+			subject:     "otlp..logs",
+			expectedErr: `invalid subject "otlp..logs": it must not contain empty tokens`,
+		},
+		{
+			subject:     "otlp.logs.",
+			expectedErr: `invalid subject "otlp.logs.": it must not contain empty tokens`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			_, err := parseSubjectTemplate(tt.subject)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestRenderSubject(t *testing.T) {
+	attrs := pcommon.NewMap()
+	attrs.PutStr("service.name", "checkout")
+	attrs.PutStr("k8s.namespace.name", "shop.eu west")
+	attrs.PutStr("host.name", "")
+	attrs.PutInt("shard", 3)
+
+	tests := []struct {
+		subject  string
+		expected string
+	}{
+		{
+			subject:  "otlp.logs",
+			expected: "otlp.logs",
+		},
+		{
+			subject:  "otlp.logs.{service.name}",
+			expected: "otlp.logs.checkout",
+		},
+		{
+			subject:  "{k8s.namespace.name}.{service.name}.logs",
+			expected: "shop_eu_west.checkout.logs",
+		},
+		{
+			subject:  "logs.{service.name}-{shard}",
+			expected: "logs.checkout-3",
+		},
+		{
+			subject:  "logs.{host.name}.{deployment.environment}",
+			expected: "logs.unknown.unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			tmpl, err := parseSubjectTemplate(tt.subject)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tmpl.render(attrs))
+		})
+	}
+}
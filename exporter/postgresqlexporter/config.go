@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresqlexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/postgresqlexporter"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+// Config defines configuration for the PostgreSQL exporter.
+type Config struct {
+	TimeoutSettings           exporterhelper.TimeoutConfig `mapstructure:",squash"`
+	configretry.BackOffConfig `mapstructure:"retry_on_failure"`
+	QueueSettings             exporterhelper.QueueConfig `mapstructure:"sending_queue"`
+
+	// Endpoint is the host:port of the PostgreSQL server.
+	Endpoint string `mapstructure:"endpoint"`
+	// Username is the authentication username.
+	Username string `mapstructure:"username"`
+	// Password is the authentication password.
+	Password configopaque.String `mapstructure:"password"`
+	// Database is the database name.
+	Database string `mapstructure:"database"`
+	// TLS configures the connection to the server. The connection isn't encrypted when `insecure` is true.
+	TLS configtls.ClientConfig `mapstructure:"tls"`
+	// MaxConnections is the maximum number of connections to the server. default is the greater of 4 and the number of CPUs.
+	MaxConnections int32 `mapstructure:"max_connections"`
+
+	// Schema is the schema of the tables. default is `public`.
+	Schema string `mapstructure:"schema"`
+	// Table is the table name for logs, traces and metrics.
+	Table Table `mapstructure:"table"`
+	// CreateSchema if set to true will create the schema and the tables. default is true.
+	CreateSchema bool `mapstructure:"create_schema"`
+	// Timescale converts the tables to TimescaleDB hypertables; ignored if create_schema is false.
+	Timescale TimescaleConfig `mapstructure:"timescale"`
+}
+
+type Table struct {
+	// Logs is the table name for logs. default is `otel_logs`.
+	Logs string `mapstructure:"logs"`
+	// Traces is the table name for traces. default is `otel_traces`.
+	Traces string `mapstructure:"traces"`
+	// Metrics is the prefix of the table names for each metric type. default is `otel_metrics`.
+	Metrics string `mapstructure:"metrics"`
+}
+
+type TimescaleConfig struct {
+	// Enabled creates the tables as hypertables partitioned by time. The timescaledb extension must be available.
+	Enabled bool `mapstructure:"enabled"`
+	// ChunkTimeInterval is the time interval covered by each chunk of the hypertables. default is 24h.
+	ChunkTimeInterval time.Duration `mapstructure:"chunk_time_interval"`
+	// Retention drops the chunks older than this duration. 0 means the chunks are never dropped.
+	Retention time.Duration `mapstructure:"retention"`
+}
+
+var identifierRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Validate the PostgreSQL exporter configuration.
+func (cfg *Config) Validate() (err error) {
+	if cfg.Endpoint == "" {
+		err = errors.Join(err, errors.New("endpoint must be specified"))
+	} else if _, _, e := net.SplitHostPort(cfg.Endpoint); e != nil {
+		err = errors.Join(err, errors.New("endpoint must be in the host:port format"))
+	}
+	if cfg.Database == "" {
+		err = errors.Join(err, errors.New("database must be specified"))
+	}
+	if cfg.MaxConnections < 0 {
+		err = errors.Join(err, errors.New("max_connections can't be negative"))
+	}
+
+	// Preventing SQL Injection Attacks
+	if !identifierRegexp.MatchString(cfg.Schema) {
+		err = errors.Join(err, errors.New("schema name must be alphanumeric and underscore"))
+	}
+	if !identifierRegexp.MatchString(cfg.Table.Logs) {
+		err = errors.Join(err, errors.New("logs table name must be alphanumeric and underscore"))
+	}
+	if !identifierRegexp.MatchString(cfg.Table.Traces) {
+		err = errors.Join(err, errors.New("traces table name must be alphanumeric and underscore"))
+	}
+	if !identifierRegexp.MatchString(cfg.Table.Metrics) {
+		err = errors.Join(err, errors.New("metrics table name must be alphanumeric and underscore"))
+	}
+
+	if cfg.Timescale.Enabled && cfg.Timescale.ChunkTimeInterval <= 0 {
+		err = errors.Join(err, errors.New("timescale chunk_time_interval must be positive"))
+	}
+	if cfg.Timescale.Retention < 0 {
+		err = errors.Join(err, errors.New("timescale retention can't be negative"))
+	}
+	if cfg.Timescale.Retention > 0 && !cfg.Timescale.Enabled {
+		err = errors.Join(err, errors.New("timescale retention requires timescale to be enabled"))
+	}
+
+	return err
+}
+
+// poolConfig builds the configuration of the connection pool.
+func (cfg *Config) poolConfig(ctx context.Context) (*pgxpool.Config, error) {
+	connURL := url.URL{
+		Scheme: "postgres",
+		Host:   cfg.Endpoint,
+		Path:   cfg.Database,
+	}
+	if cfg.TLS.Insecure {
+		connURL.RawQuery = "sslmode=disable"
+	}
+	poolConfig, err := pgxpool.ParseConfig(connURL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	poolConfig.ConnConfig.User = cfg.Username
+	poolConfig.ConnConfig.Password = string(cfg.Password)
+	if !cfg.TLS.Insecure {
+		tlsConfig, err := cfg.TLS.LoadTLSConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS config: %w", err)
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = poolConfig.ConnConfig.Host
+		}
+		poolConfig.ConnConfig.TLSConfig = tlsConfig
+		// Don't fall back to an unencrypted connection.
+		poolConfig.ConnConfig.Fallbacks = nil
+	}
+	if cfg.MaxConnections > 0 {
+		poolConfig.MaxConns = cfg.MaxConnections
+	}
+	return poolConfig, nil
+}
+
+// tableName returns the quoted name of a table in the schema.
+func (cfg *Config) tableName(table string) string {
+	return pgx.Identifier{cfg.Schema, table}.Sanitize()
+}
@@ -0,0 +1,207 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresqlexporter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/postgresqlexporter/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		id       component.ID
+		expected component.Config
+	}{
+		{
+			id:       component.NewIDWithName(metadata.Type, ""),
+			expected: createDefaultConfig(),
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "full"),
+			expected: &Config{
+				TimeoutSettings: exporterhelper.TimeoutConfig{
+					Timeout: 10 * time.Second,
+				},
+				BackOffConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     5 * time.Second,
+					MaxInterval:         30 * time.Second,
+					MaxElapsedTime:      300 * time.Second,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+					Multiplier:          backoff.DefaultMultiplier,
+				},
+				QueueSettings: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: 10,
+					QueueSize:    1000,
+				},
+				Endpoint: "postgres.example.com:6432",
+				Username: "otel",
+				Password: configopaque.String("secret"),
+				Database: "telemetry",
+				TLS: configtls.ClientConfig{
+					Insecure: true,
+				},
+				MaxConnections: 8,
+				Schema:         "observability",
+				Table: Table{
+					Logs:    "logs",
+					Traces:  "spans",
+					Metrics: "metrics",
+				},
+				CreateSchema: true,
+				Timescale: TimescaleConfig{
+					Enabled:           true,
+					ChunkTimeInterval: 6 * time.Hour,
+					Retention:         30 * 24 * time.Hour,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cfg := createDefaultConfig()
+
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			assert.NoError(t, component.ValidateConfig(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:    "missing endpoint",
+			modify:  func(cfg *Config) { cfg.Endpoint = "" },
+			wantErr: "endpoint must be specified",
+		},
+		{
+			name:    "endpoint without port",
+			modify:  func(cfg *Config) { cfg.Endpoint = "localhost" },
+			wantErr: "endpoint must be in the host:port format",
+		},
+		{
+			name:    "missing database",
+			modify:  func(cfg *Config) { cfg.Database = "" },
+			wantErr: "database must be specified",
+		},
+		{
+			name:    "negative max connections",
+			modify:  func(cfg *Config) { cfg.MaxConnections = -1 },
+			wantErr: "max_connections can't be negative",
+		},
+		{
+			name:    "invalid schema",
+			modify:  func(cfg *Config) { cfg.Schema = "public; DROP TABLE users" },
+			wantErr: "schema name must be alphanumeric and underscore",
+		},
+		{
+			name: "invalid table names",
+			modify: func(cfg *Config) {
+				cfg.Table.Logs = "otel-logs"
+				cfg.Table.Traces = ""
+				cfg.Table.Metrics = "1metrics"
+			},
+			wantErr: "logs table name must be alphanumeric and underscore\n" +
+				"traces table name must be alphanumeric and underscore\n" +
+				"metrics table name must be alphanumeric and underscore",
+		},
+		{
+			name: "timescale without chunk time interval",
+			modify: func(cfg *Config) {
+				cfg.Timescale.Enabled = true
+				cfg.Timescale.ChunkTimeInterval = 0
+			},
+			wantErr: "timescale chunk_time_interval must be positive",
+		},
+		{
+			name: "negative retention",
+			modify: func(cfg *Config) {
+				cfg.Timescale.Enabled = true
+				cfg.Timescale.Retention = -time.Hour
+			},
+			wantErr: "timescale retention can't be negative",
+		},
+		{
+			name:    "retention without timescale",
+			modify:  func(cfg *Config) { cfg.Timescale.Retention = time.Hour },
+			wantErr: "timescale retention requires timescale to be enabled",
+		},
+		{
+			name:   "chunk time interval ignored without timescale",
+			modify: func(cfg *Config) { cfg.Timescale.ChunkTimeInterval = 0 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPoolConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "db.example.com:6432"
+	cfg.Username = "otel"
+	cfg.Password = "secret"
+	cfg.MaxConnections = 8
+
+	poolConfig, err := cfg.poolConfig(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "db.example.com", poolConfig.ConnConfig.Host)
+	assert.Equal(t, uint16(6432), poolConfig.ConnConfig.Port)
+	assert.Equal(t, "otel", poolConfig.ConnConfig.Database)
+	assert.Equal(t, "otel", poolConfig.ConnConfig.User)
+	assert.Equal(t, "secret", poolConfig.ConnConfig.Password)
+	assert.Equal(t, int32(8), poolConfig.MaxConns)
+	require.NotNil(t, poolConfig.ConnConfig.TLSConfig)
+	assert.Equal(t, "db.example.com", poolConfig.ConnConfig.TLSConfig.ServerName)
+	assert.Empty(t, poolConfig.ConnConfig.Fallbacks)
+
+	cfg.TLS.Insecure = true
+	poolConfig, err = cfg.poolConfig(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, poolConfig.ConnConfig.TLSConfig)
+}
+
+func TestTableName(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.Equal(t, `"public"."otel_logs"`, cfg.tableName(cfg.Table.Logs))
+}
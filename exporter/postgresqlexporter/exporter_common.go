@@ -0,0 +1,247 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresqlexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/postgresqlexporter"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/collector/semconv/v1.27.0"
+	"go.uber.org/zap"
+)
+
+// database is the subset of the connection pool used by the exporters.
+type database interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+	Close()
+}
+
+// connect creates the connection pool, replaced in the tests.
+var connect = func(ctx context.Context, cfg *Config) (database, error) {
+	poolConfig, err := cfg.poolConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pgxpool.NewWithConfig(ctx, poolConfig)
+}
+
+// tableSchema is a table created by an exporter.
+type tableSchema struct {
+	name string
+	// ddl is the template of the statements creating the table, formatted with the quoted name of the
+	// table in the schema and the name of the table.
+	ddl string
+	// timeColumn is the column partitioning the table when it is a hypertable.
+	timeColumn string
+}
+
+// copyBatch is the rows copied to a table.
+type copyBatch struct {
+	table   string
+	columns []string
+	rows    [][]any
+}
+
+type commonExporter struct {
+	logger *zap.Logger
+	cfg    *Config
+	tables []tableSchema
+
+	db database
+}
+
+func newExporter(logger *zap.Logger, cfg *Config, tables ...tableSchema) *commonExporter {
+	return &commonExporter{
+		logger: logger,
+		cfg:    cfg,
+		tables: tables,
+	}
+}
+
+func (e *commonExporter) start(ctx context.Context, _ component.Host) error {
+	db, err := connect(ctx, e.cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create the connection pool: %w", err)
+	}
+	e.db = db
+
+	if !e.cfg.CreateSchema {
+		return nil
+	}
+	return e.createSchema(ctx)
+}
+
+func (e *commonExporter) shutdown(_ context.Context) error {
+	if e.db != nil {
+		e.db.Close()
+	}
+	return nil
+}
+
+// createSchema creates the schema and the tables, and converts the tables to hypertables when timescale is enabled.
+func (e *commonExporter) createSchema(ctx context.Context) error {
+	if _, err := e.db.Exec(ctx, "CREATE SCHEMA IF NOT EXISTS "+pgx.Identifier{e.cfg.Schema}.Sanitize()); err != nil {
+		return fmt.Errorf("create schema: %w", err)
+	}
+	if e.cfg.Timescale.Enabled {
+		if _, err := e.db.Exec(ctx, "CREATE EXTENSION IF NOT EXISTS timescaledb"); err != nil {
+			return fmt.Errorf("create timescaledb extension: %w", err)
+		}
+	}
+
+	for _, table := range e.tables {
+		tableName := e.cfg.tableName(table.name)
+		if _, err := e.db.Exec(ctx, fmt.Sprintf(table.ddl, tableName, table.name)); err != nil {
+			return fmt.Errorf("create table %s: %w", table.name, err)
+		}
+		if !e.cfg.Timescale.Enabled {
+			continue
+		}
+		if err := e.createHypertable(ctx, tableName, table.timeColumn); err != nil {
+			return fmt.Errorf("create hypertable %s: %w", table.name, err)
+		}
+	}
+	return nil
+}
+
+const (
+	// The indexes are created with the tables, and the existing rows are moved to chunks
+	// when timescale is enabled after the table was created.
+	createHypertableSQL = `SELECT create_hypertable($1::regclass, $2::name, chunk_time_interval => $3::interval,
+	create_default_indexes => FALSE, if_not_exists => TRUE, migrate_data => TRUE)`
+	removeRetentionPolicySQL = `SELECT remove_retention_policy($1::regclass, if_exists => TRUE)`
+	addRetentionPolicySQL    = `SELECT add_retention_policy($1::regclass, drop_after => $2::interval)`
+)
+
+// createHypertable converts a table to a hypertable, and replaces its retention policy so that it follows
+// the configuration.
+func (e *commonExporter) createHypertable(ctx context.Context, tableName string, timeColumn string) error {
+	if _, err := e.db.Exec(ctx, createHypertableSQL, tableName, timeColumn, interval(e.cfg.Timescale.ChunkTimeInterval)); err != nil {
+		return err
+	}
+	if _, err := e.db.Exec(ctx, removeRetentionPolicySQL, tableName); err != nil {
+		return fmt.Errorf("remove retention policy: %w", err)
+	}
+	if e.cfg.Timescale.Retention == 0 {
+		return nil
+	}
+	if _, err := e.db.Exec(ctx, addRetentionPolicySQL, tableName, interval(e.cfg.Timescale.Retention)); err != nil {
+		return fmt.Errorf("add retention policy: %w", err)
+	}
+	return nil
+}
+
+// interval formats a duration as a PostgreSQL interval.
+func interval(d time.Duration) string {
+	return fmt.Sprintf("%d microseconds", d.Microseconds())
+}
+
+// copyRows copies the rows of the batches with COPY in a single transaction, so that either all the rows
+// or none of them are inserted.
+func (e *commonExporter) copyRows(ctx context.Context, batches ...copyBatch) error {
+	tx, err := e.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return wrapError(fmt.Errorf("begin transaction: %w", err))
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	for _, batch := range batches {
+		if len(batch.rows) == 0 {
+			continue
+		}
+		for _, row := range batch.rows {
+			for i, value := range row {
+				row[i] = removeNUL(value)
+			}
+		}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{e.cfg.Schema, batch.table}, batch.columns, pgx.CopyFromRows(batch.rows)); err != nil {
+			return wrapError(fmt.Errorf("copy to table %s: %w", batch.table, err))
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return wrapError(fmt.Errorf("commit transaction: %w", err))
+	}
+	return nil
+}
+
+// removeNUL removes the NUL characters of the strings of a value, including the keys and the values of
+// the maps and the attributes stored in JSONB columns. PostgreSQL rejects them both in text columns and
+// in JSONB, where they are encoded as \u0000, which would fail the whole batch with a permanent error.
+// The maps and the slices are updated in place.
+func removeNUL(value any) any {
+	switch v := value.(type) {
+	case string:
+		if strings.IndexByte(v, 0) >= 0 {
+			return strings.ReplaceAll(v, "\x00", "")
+		}
+	case map[string]any:
+		var keys []string
+		for key, item := range v {
+			if strings.IndexByte(key, 0) >= 0 {
+				keys = append(keys, key)
+			}
+			v[key] = removeNUL(item)
+		}
+		for _, key := range keys {
+			item := v[key]
+			delete(v, key)
+			v[removeNUL(key).(string)] = item
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = removeNUL(item)
+		}
+	case []pgEvent:
+		for i := range v {
+			v[i].Name = removeNUL(v[i].Name).(string)
+			removeNUL(v[i].Attributes)
+		}
+	case []pgLink:
+		for i := range v {
+			v[i].TraceState = removeNUL(v[i].TraceState).(string)
+			removeNUL(v[i].Attributes)
+		}
+	case []pgExemplar:
+		for i := range v {
+			removeNUL(v[i].FilteredAttributes)
+		}
+	}
+	return value
+}
+
+// wrapError marks the errors which won't succeed when retried as permanent: invalid data, violated
+// constraints, and statements which don't match the schema of the tables.
+func wrapError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || len(pgErr.Code) < 2 {
+		return err
+	}
+	switch pgErr.Code[:2] {
+	case "22", "23", "42":
+		return consumererror.NewPermanent(err)
+	default:
+		return err
+	}
+}
+
+// getServiceName returns the service name of a resource.
+func getServiceName(attributes pcommon.Map) string {
+	if v, ok := attributes.Get(conventions.AttributeServiceName); ok {
+		return v.AsString()
+	}
+	return ""
+}
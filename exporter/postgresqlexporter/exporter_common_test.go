@@ -0,0 +1,325 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresqlexporter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeDatabase records the statements and the copies instead of sending them to a server. The statements
+// starting with failExec fail with execErr.
+type fakeDatabase struct {
+	statements []string
+	arguments  [][]any
+	failExec   string
+	execErr    error
+
+	beginErr error
+	copyErr  error
+	txs      []*fakeTx
+	closed   bool
+}
+
+type fakeCopy struct {
+	table   pgx.Identifier
+	columns []string
+	rows    [][]any
+}
+
+type fakeTx struct {
+	pgx.Tx
+	db         *fakeDatabase
+	copies     []fakeCopy
+	committed  bool
+	rolledBack bool
+}
+
+func (db *fakeDatabase) Exec(_ context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	if db.failExec != "" && strings.HasPrefix(sql, db.failExec) {
+		return pgconn.CommandTag{}, db.execErr
+	}
+	db.statements = append(db.statements, sql)
+	db.arguments = append(db.arguments, arguments)
+	return pgconn.CommandTag{}, nil
+}
+
+func (db *fakeDatabase) BeginTx(_ context.Context, _ pgx.TxOptions) (pgx.Tx, error) {
+	if db.beginErr != nil {
+		return nil, db.beginErr
+	}
+	tx := &fakeTx{db: db}
+	db.txs = append(db.txs, tx)
+	return tx, nil
+}
+
+func (db *fakeDatabase) Close() {
+	db.closed = true
+}
+
+func (tx *fakeTx) CopyFrom(_ context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	if tx.db.copyErr != nil {
+		return 0, tx.db.copyErr
+	}
+	var rows [][]any
+	for rowSrc.Next() {
+		values, err := rowSrc.Values()
+		if err != nil {
+			return 0, err
+		}
+		rows = append(rows, values)
+	}
+	tx.copies = append(tx.copies, fakeCopy{table: tableName, columns: columnNames, rows: rows})
+	return int64(len(rows)), nil
+}
+
+func (tx *fakeTx) Commit(_ context.Context) error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(_ context.Context) error {
+	if tx.committed {
+		return pgx.ErrTxClosed
+	}
+	tx.rolledBack = true
+	return nil
+}
+
+// withFakeDatabase replaces the connection to the server by db for the duration of the test.
+func withFakeDatabase(t *testing.T, db *fakeDatabase) {
+	previous := connect
+	connect = func(context.Context, *Config) (database, error) {
+		return db, nil
+	}
+	t.Cleanup(func() { connect = previous })
+}
+
+func TestStart(t *testing.T) {
+	db := &fakeDatabase{}
+	withFakeDatabase(t, db)
+
+	cfg := createDefaultConfig().(*Config)
+	exporter := newLogsExporter(zaptest.NewLogger(t), cfg)
+	require.NoError(t, exporter.start(context.Background(), componenttest.NewNopHost()))
+	require.Len(t, db.statements, 2)
+	assert.Equal(t, `CREATE SCHEMA IF NOT EXISTS "public"`, db.statements[0])
+	assert.True(t, strings.HasPrefix(db.statements[1], `CREATE TABLE IF NOT EXISTS "public"."otel_logs"`))
+	assert.Contains(t, db.statements[1], `CREATE INDEX IF NOT EXISTS otel_logs_timestamp_idx ON "public"."otel_logs" (timestamp DESC);`)
+
+	require.NoError(t, exporter.shutdown(context.Background()))
+	assert.True(t, db.closed)
+}
+
+func TestStartWithoutCreateSchema(t *testing.T) {
+	db := &fakeDatabase{}
+	withFakeDatabase(t, db)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.CreateSchema = false
+	exporter := newTracesExporter(zaptest.NewLogger(t), cfg)
+	require.NoError(t, exporter.start(context.Background(), componenttest.NewNopHost()))
+	assert.Empty(t, db.statements)
+}
+
+func TestStartConnectionError(t *testing.T) {
+	previous := connect
+	connect = func(context.Context, *Config) (database, error) {
+		return nil, errors.New("connection refused")
+	}
+	t.Cleanup(func() { connect = previous })
+
+	exporter := newLogsExporter(zaptest.NewLogger(t), createDefaultConfig().(*Config))
+	require.EqualError(t, exporter.start(context.Background(), componenttest.NewNopHost()), "failed to create the connection pool: connection refused")
+	require.NoError(t, exporter.shutdown(context.Background()))
+}
+
+func TestCreateSchemaTimescale(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention time.Duration
+		expected  [][]any
+	}{
+		{
+			name: "without retention",
+			expected: [][]any{
+				{`"public"."otel_traces"`, "start_time", "21600000000 microseconds"},
+				{`"public"."otel_traces"`},
+			},
+		},
+		{
+			name:      "with retention",
+			retention: 7 * 24 * time.Hour,
+			expected: [][]any{
+				{`"public"."otel_traces"`, "start_time", "21600000000 microseconds"},
+				{`"public"."otel_traces"`},
+				{`"public"."otel_traces"`, "604800000000 microseconds"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDatabase{}
+			cfg := createDefaultConfig().(*Config)
+			cfg.Timescale.Enabled = true
+			cfg.Timescale.ChunkTimeInterval = 6 * time.Hour
+			cfg.Timescale.Retention = tt.retention
+			exporter := newTracesExporter(zaptest.NewLogger(t), cfg)
+			exporter.db = db
+
+			require.NoError(t, exporter.createSchema(context.Background()))
+			require.Len(t, db.statements, 3+len(tt.expected))
+			assert.Equal(t, "CREATE EXTENSION IF NOT EXISTS timescaledb", db.statements[1])
+			assert.Equal(t, createHypertableSQL, db.statements[3])
+			assert.Equal(t, removeRetentionPolicySQL, db.statements[4])
+			if tt.retention > 0 {
+				assert.Equal(t, addRetentionPolicySQL, db.statements[5])
+			}
+			assert.Equal(t, tt.expected, db.arguments[3:])
+		})
+	}
+}
+
+func TestCreateSchemaMetricTables(t *testing.T) {
+	db := &fakeDatabase{}
+	cfg := createDefaultConfig().(*Config)
+	cfg.Timescale.Enabled = true
+	exporter := newMetricsExporter(zaptest.NewLogger(t), cfg)
+	exporter.db = db
+
+	require.NoError(t, exporter.createSchema(context.Background()))
+
+	var hypertables []any
+	for i, statement := range db.statements {
+		if statement == createHypertableSQL {
+			hypertables = append(hypertables, db.arguments[i][0])
+		}
+	}
+	assert.Equal(t, []any{
+		`"public"."otel_metrics_gauge"`,
+		`"public"."otel_metrics_sum"`,
+		`"public"."otel_metrics_histogram"`,
+		`"public"."otel_metrics_exponential_histogram"`,
+		`"public"."otel_metrics_summary"`,
+	}, hypertables)
+}
+
+func TestCreateSchemaError(t *testing.T) {
+	db := &fakeDatabase{failExec: "SELECT create_hypertable", execErr: errors.New("extension not available")}
+	cfg := createDefaultConfig().(*Config)
+	cfg.Timescale.Enabled = true
+	exporter := newLogsExporter(zaptest.NewLogger(t), cfg)
+	exporter.db = db
+
+	require.EqualError(t, exporter.createSchema(context.Background()), "create hypertable otel_logs: extension not available")
+}
+
+func TestCopyRows(t *testing.T) {
+	db := &fakeDatabase{}
+	exporter := newLogsExporter(zaptest.NewLogger(t), createDefaultConfig().(*Config))
+	exporter.db = db
+
+	err := exporter.copyRows(context.Background(),
+		copyBatch{table: "a", columns: []string{"x"}, rows: [][]any{{1}, {2}}},
+		copyBatch{table: "b", columns: []string{"y"}},
+		copyBatch{table: "c", columns: []string{"z"}, rows: [][]any{{3}}},
+	)
+	require.NoError(t, err)
+	require.Len(t, db.txs, 1)
+	tx := db.txs[0]
+	assert.True(t, tx.committed)
+	assert.False(t, tx.rolledBack)
+	assert.Equal(t, []fakeCopy{
+		{table: pgx.Identifier{"public", "a"}, columns: []string{"x"}, rows: [][]any{{1}, {2}}},
+		{table: pgx.Identifier{"public", "c"}, columns: []string{"z"}, rows: [][]any{{3}}},
+	}, tx.copies)
+}
+
+func TestCopyRowsRemovesNUL(t *testing.T) {
+	db := &fakeDatabase{}
+	exporter := newMetricsExporter(zaptest.NewLogger(t), createDefaultConfig().(*Config))
+	exporter.db = db
+
+	err := exporter.copyRows(context.Background(), copyBatch{table: "a", columns: []string{"x", "y", "z"}, rows: [][]any{{
+		"request\x00s",
+		int64(1),
+		[]pgExemplar{{FilteredAttributes: map[string]any{"user\x00": "\x00alice"}, TraceID: "0102"}},
+	}}})
+	require.NoError(t, err)
+	require.Len(t, db.txs, 1)
+	assert.Equal(t, [][]any{{
+		"requests",
+		int64(1),
+		[]pgExemplar{{FilteredAttributes: map[string]any{"user": "alice"}, TraceID: "0102"}},
+	}}, db.txs[0].copies[0].rows)
+}
+
+func TestCopyRowsError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		permanent bool
+	}{
+		{
+			name: "connection error",
+			err:  errors.New("connection reset by peer"),
+		},
+		{
+			name: "serialization failure",
+			err:  &pgconn.PgError{Code: "40001", Message: "could not serialize access"},
+		},
+		{
+			name:      "invalid data",
+			err:       &pgconn.PgError{Code: "22P02", Message: "invalid input syntax"},
+			permanent: true,
+		},
+		{
+			name:      "constraint violation",
+			err:       &pgconn.PgError{Code: "23502", Message: "null value violates not-null constraint"},
+			permanent: true,
+		},
+		{
+			name:      "undefined column",
+			err:       &pgconn.PgError{Code: "42703", Message: "column does not exist"},
+			permanent: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDatabase{copyErr: tt.err}
+			exporter := newLogsExporter(zaptest.NewLogger(t), createDefaultConfig().(*Config))
+			exporter.db = db
+
+			err := exporter.copyRows(context.Background(), copyBatch{table: "a", columns: []string{"x"}, rows: [][]any{{1}}})
+			require.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.permanent, consumererror.IsPermanent(err))
+			require.Len(t, db.txs, 1)
+			assert.False(t, db.txs[0].committed)
+			assert.True(t, db.txs[0].rolledBack)
+		})
+	}
+}
+
+func TestCopyRowsBeginError(t *testing.T) {
+	db := &fakeDatabase{beginErr: errors.New("too many connections")}
+	exporter := newLogsExporter(zaptest.NewLogger(t), createDefaultConfig().(*Config))
+	exporter.db = db
+
+	err := exporter.copyRows(context.Background(), copyBatch{table: "a", columns: []string{"x"}, rows: [][]any{{1}}})
+	require.EqualError(t, err, "begin transaction: too many connections")
+	assert.False(t, consumererror.IsPermanent(err))
+}
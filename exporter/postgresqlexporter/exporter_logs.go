@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresqlexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/postgresqlexporter"
+
+import (
+	"context"
+	_ "embed" // for SQL file embedding
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/traceutil"
+)
+
+//go:embed sql/logs_ddl.sql
+var logsDDL string
+
+var logsColumns = []string{
+	"timestamp",
+	"observed_timestamp",
+	"trace_id",
+	"span_id",
+	"trace_flags",
+	"severity_text",
+	"severity_number",
+	"service_name",
+	"body",
+	"resource_schema_url",
+	"resource_attributes",
+	"scope_schema_url",
+	"scope_name",
+	"scope_version",
+	"scope_attributes",
+	"log_attributes",
+}
+
+type logsExporter struct {
+	*commonExporter
+}
+
+func newLogsExporter(logger *zap.Logger, cfg *Config) *logsExporter {
+	return &logsExporter{
+		commonExporter: newExporter(logger, cfg, tableSchema{name: cfg.Table.Logs, ddl: logsDDL, timeColumn: "timestamp"}),
+	}
+}
+
+func (e *logsExporter) pushLogData(ctx context.Context, ld plog.Logs) error {
+	rows := make([][]any, 0, ld.LogRecordCount())
+
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		resourceLogs := ld.ResourceLogs().At(i)
+		resource := resourceLogs.Resource()
+		resourceAttributes := resource.Attributes().AsRaw()
+		serviceName := getServiceName(resource.Attributes())
+
+		for j := 0; j < resourceLogs.ScopeLogs().Len(); j++ {
+			scopeLogs := resourceLogs.ScopeLogs().At(j)
+			scope := scopeLogs.Scope()
+			scopeAttributes := scope.Attributes().AsRaw()
+
+			for k := 0; k < scopeLogs.LogRecords().Len(); k++ {
+				logRecord := scopeLogs.LogRecords().At(k)
+
+				timestamp := logRecord.Timestamp()
+				if timestamp == 0 {
+					timestamp = logRecord.ObservedTimestamp()
+				}
+
+				rows = append(rows, []any{
+					timestamp.AsTime(),
+					logRecord.ObservedTimestamp().AsTime(),
+					traceutil.TraceIDToHexOrEmptyString(logRecord.TraceID()),
+					traceutil.SpanIDToHexOrEmptyString(logRecord.SpanID()),
+					int32(logRecord.Flags()),
+					logRecord.SeverityText(),
+					int32(logRecord.SeverityNumber()),
+					serviceName,
+					logRecord.Body().AsString(),
+					resourceLogs.SchemaUrl(),
+					resourceAttributes,
+					scopeLogs.SchemaUrl(),
+					scope.Name(),
+					scope.Version(),
+					scopeAttributes,
+					logRecord.Attributes().AsRaw(),
+				})
+			}
+		}
+	}
+
+	return e.copyRows(ctx, copyBatch{table: e.cfg.Table.Logs, columns: logsColumns, rows: rows})
+}
@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresqlexporter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPushLogData(t *testing.T) {
+	db := &fakeDatabase{}
+	exporter := newLogsExporter(zaptest.NewLogger(t), createDefaultConfig().(*Config))
+	exporter.db = db
+
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.SetSchemaUrl("https://opentelemetry.io/schemas/1.27.0")
+	rl.Resource().Attributes().PutStr("service.name", "checkout")
+	rl.Resource().Attributes().PutInt("process.pid", 42)
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName("io.opentelemetry.contrib.postgresql")
+	sl.Scope().SetVersion("1.0.0")
+	r := sl.LogRecords().AppendEmpty()
+	r.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	r.SetObservedTimestamp(pcommon.NewTimestampFromTime(timestamp.Add(time.Second)))
+	r.SetTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	r.SetSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	r.SetFlags(plog.DefaultLogRecordFlags.WithIsSampled(true))
+	r.SetSeverityText("ERROR")
+	r.SetSeverityNumber(plog.SeverityNumberError)
+	r.Body().SetStr("payment failed")
+	r.Attributes().PutStr("http.route", "/pay")
+	// Without a timestamp, the log record is stored with the observed timestamp.
+	observed := sl.LogRecords().AppendEmpty()
+	observed.SetObservedTimestamp(pcommon.NewTimestampFromTime(timestamp))
+
+	require.NoError(t, exporter.pushLogData(context.Background(), logs))
+	require.Len(t, db.txs, 1)
+	require.True(t, db.txs[0].committed)
+	require.Len(t, db.txs[0].copies, 1)
+
+	copied := db.txs[0].copies[0]
+	assert.Equal(t, pgx.Identifier{"public", "otel_logs"}, copied.table)
+	assert.Equal(t, logsColumns, copied.columns)
+	require.Len(t, copied.rows, 2)
+	assert.Equal(t, []any{
+		timestamp,
+		timestamp.Add(time.Second),
+		"0102030405060708090a0b0c0d0e0f10",
+		"0102030405060708",
+		int32(1),
+		"ERROR",
+		int32(plog.SeverityNumberError),
+		"checkout",
+		"payment failed",
+		"https://opentelemetry.io/schemas/1.27.0",
+		map[string]any{"service.name": "checkout", "process.pid": int64(42)},
+		"",
+		"io.opentelemetry.contrib.postgresql",
+		"1.0.0",
+		map[string]any{},
+		map[string]any{"http.route": "/pay"},
+	}, copied.rows[0])
+	assert.Equal(t, timestamp, copied.rows[1][0])
+	assert.Equal(t, "", copied.rows[1][2])
+}
+
+func TestPushLogDataNUL(t *testing.T) {
+	db := &fakeDatabase{}
+	exporter := newLogsExporter(zaptest.NewLogger(t), createDefaultConfig().(*Config))
+	exporter.db = db
+
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "check\x00out")
+	r := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	r.Body().SetStr("payment\x00 failed")
+	r.Attributes().PutStr("payment.card", "visa\x00")
+	r.Attributes().PutStr("payment\x00.id", "42")
+	r.Attributes().PutEmptySlice("payment.errors").AppendEmpty().SetStr("\x00declined")
+	r.Attributes().PutEmptyMap("payment.details").PutStr("issuer", "ba\x00nk")
+
+	require.NoError(t, exporter.pushLogData(context.Background(), logs))
+	require.Len(t, db.txs, 1)
+	require.Len(t, db.txs[0].copies, 1)
+	row := db.txs[0].copies[0].rows[0]
+	assert.Equal(t, "checkout", row[7])
+	assert.Equal(t, "payment failed", row[8])
+	assert.Equal(t, map[string]any{"service.name": "checkout"}, row[10])
+	assert.Equal(t, map[string]any{
+		"payment.card":    "visa",
+		"payment.id":      "42",
+		"payment.errors":  []any{"declined"},
+		"payment.details": map[string]any{"issuer": "bank"},
+	}, row[15])
+}
+
+func TestPushLogDataEmpty(t *testing.T) {
+	db := &fakeDatabase{}
+	exporter := newLogsExporter(zaptest.NewLogger(t), createDefaultConfig().(*Config))
+	exporter.db = db
+
+	require.NoError(t, exporter.pushLogData(context.Background(), plog.NewLogs()))
+	require.Len(t, db.txs, 1)
+	assert.Empty(t, db.txs[0].copies)
+}
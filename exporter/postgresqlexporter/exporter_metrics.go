@@ -0,0 +1,300 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresqlexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/postgresqlexporter"
+
+import (
+	"context"
+	_ "embed" // for SQL file embedding
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/traceutil"
+)
+
+var (
+	//go:embed sql/metrics_gauge_ddl.sql
+	metricsGaugeDDL string
+	//go:embed sql/metrics_sum_ddl.sql
+	metricsSumDDL string
+	//go:embed sql/metrics_histogram_ddl.sql
+	metricsHistogramDDL string
+	//go:embed sql/metrics_exponential_histogram_ddl.sql
+	metricsExponentialHistogramDDL string
+	//go:embed sql/metrics_summary_ddl.sql
+	metricsSummaryDDL string
+)
+
+// metricsColumns are the columns shared by the tables of all the metric types.
+var metricsColumns = []string{
+	"resource_schema_url",
+	"resource_attributes",
+	"scope_schema_url",
+	"scope_name",
+	"scope_version",
+	"scope_attributes",
+	"service_name",
+	"metric_name",
+	"metric_description",
+	"metric_unit",
+	"attributes",
+	"start_time",
+	"time",
+	"flags",
+	"exemplars",
+}
+
+// metricTable is the table of a metric type.
+type metricTable struct {
+	suffix  string
+	ddl     string
+	columns []string
+}
+
+var metricTables = map[pmetric.MetricType]metricTable{
+	pmetric.MetricTypeGauge: {
+		suffix:  "_gauge",
+		ddl:     metricsGaugeDDL,
+		columns: []string{"value"},
+	},
+	pmetric.MetricTypeSum: {
+		suffix:  "_sum",
+		ddl:     metricsSumDDL,
+		columns: []string{"value", "aggregation_temporality", "is_monotonic"},
+	},
+	pmetric.MetricTypeHistogram: {
+		suffix:  "_histogram",
+		ddl:     metricsHistogramDDL,
+		columns: []string{"count", "sum", "min", "max", "bucket_counts", "explicit_bounds", "aggregation_temporality"},
+	},
+	pmetric.MetricTypeExponentialHistogram: {
+		suffix: "_exponential_histogram",
+		ddl:    metricsExponentialHistogramDDL,
+		columns: []string{
+			"count", "sum", "min", "max", "scale", "zero_count", "zero_threshold",
+			"positive_offset", "positive_bucket_counts", "negative_offset", "negative_bucket_counts", "aggregation_temporality",
+		},
+	},
+	pmetric.MetricTypeSummary: {
+		suffix:  "_summary",
+		ddl:     metricsSummaryDDL,
+		columns: []string{"count", "sum", "quantiles", "quantile_values"},
+	},
+}
+
+// metricTypes orders the tables of the metric types.
+var metricTypes = []pmetric.MetricType{
+	pmetric.MetricTypeGauge,
+	pmetric.MetricTypeSum,
+	pmetric.MetricTypeHistogram,
+	pmetric.MetricTypeExponentialHistogram,
+	pmetric.MetricTypeSummary,
+}
+
+// pgExemplar is an exemplar stored in the exemplars JSONB column.
+type pgExemplar struct {
+	FilteredAttributes map[string]any `json:"filtered_attributes"`
+	Timestamp          time.Time      `json:"timestamp"`
+	Value              float64        `json:"value"`
+	SpanID             string         `json:"span_id"`
+	TraceID            string         `json:"trace_id"`
+}
+
+type metricsExporter struct {
+	*commonExporter
+}
+
+func newMetricsExporter(logger *zap.Logger, cfg *Config) *metricsExporter {
+	tables := make([]tableSchema, 0, len(metricTypes))
+	for _, metricType := range metricTypes {
+		table := metricTables[metricType]
+		tables = append(tables, tableSchema{name: cfg.Table.Metrics + table.suffix, ddl: table.ddl, timeColumn: "time"})
+	}
+	return &metricsExporter{
+		commonExporter: newExporter(logger, cfg, tables...),
+	}
+}
+
+func (e *metricsExporter) pushMetricData(ctx context.Context, md pmetric.Metrics) error {
+	rows := make(map[pmetric.MetricType][][]any, len(metricTypes))
+
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		resourceMetrics := md.ResourceMetrics().At(i)
+		resource := resourceMetrics.Resource()
+		resourceAttributes := resource.Attributes().AsRaw()
+		serviceName := getServiceName(resource.Attributes())
+
+		for j := 0; j < resourceMetrics.ScopeMetrics().Len(); j++ {
+			scopeMetrics := resourceMetrics.ScopeMetrics().At(j)
+			scope := scopeMetrics.Scope()
+			scopeAttributes := scope.Attributes().AsRaw()
+
+			for k := 0; k < scopeMetrics.Metrics().Len(); k++ {
+				metric := scopeMetrics.Metrics().At(k)
+
+				// common returns the values of the columns shared by all the metric types for a data point.
+				common := func(attributes pcommon.Map, startTime, timestamp pcommon.Timestamp, flags pmetric.DataPointFlags, exemplars pmetric.ExemplarSlice) []any {
+					return []any{
+						resourceMetrics.SchemaUrl(),
+						resourceAttributes,
+						scopeMetrics.SchemaUrl(),
+						scope.Name(),
+						scope.Version(),
+						scopeAttributes,
+						serviceName,
+						metric.Name(),
+						metric.Description(),
+						metric.Unit(),
+						attributes.AsRaw(),
+						startTime.AsTime(),
+						timestamp.AsTime(),
+						int32(flags),
+						convertExemplars(exemplars),
+					}
+				}
+
+				//exhaustive:enforce
+				switch metric.Type() {
+				case pmetric.MetricTypeGauge:
+					dps := metric.Gauge().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						dp := dps.At(l)
+						rows[pmetric.MetricTypeGauge] = append(rows[pmetric.MetricTypeGauge], append(
+							common(dp.Attributes(), dp.StartTimestamp(), dp.Timestamp(), dp.Flags(), dp.Exemplars()),
+							numberValue(dp),
+						))
+					}
+				case pmetric.MetricTypeSum:
+					sum := metric.Sum()
+					dps := sum.DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						dp := dps.At(l)
+						rows[pmetric.MetricTypeSum] = append(rows[pmetric.MetricTypeSum], append(
+							common(dp.Attributes(), dp.StartTimestamp(), dp.Timestamp(), dp.Flags(), dp.Exemplars()),
+							numberValue(dp),
+							int32(sum.AggregationTemporality()),
+							sum.IsMonotonic(),
+						))
+					}
+				case pmetric.MetricTypeHistogram:
+					histogram := metric.Histogram()
+					dps := histogram.DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						dp := dps.At(l)
+						rows[pmetric.MetricTypeHistogram] = append(rows[pmetric.MetricTypeHistogram], append(
+							common(dp.Attributes(), dp.StartTimestamp(), dp.Timestamp(), dp.Flags(), dp.Exemplars()),
+							int64(dp.Count()),
+							optionalValue(dp.HasSum(), dp.Sum()),
+							optionalValue(dp.HasMin(), dp.Min()),
+							optionalValue(dp.HasMax(), dp.Max()),
+							convertCounts(dp.BucketCounts()),
+							dp.ExplicitBounds().AsRaw(),
+							int32(histogram.AggregationTemporality()),
+						))
+					}
+				case pmetric.MetricTypeExponentialHistogram:
+					histogram := metric.ExponentialHistogram()
+					dps := histogram.DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						dp := dps.At(l)
+						rows[pmetric.MetricTypeExponentialHistogram] = append(rows[pmetric.MetricTypeExponentialHistogram], append(
+							common(dp.Attributes(), dp.StartTimestamp(), dp.Timestamp(), dp.Flags(), dp.Exemplars()),
+							int64(dp.Count()),
+							optionalValue(dp.HasSum(), dp.Sum()),
+							optionalValue(dp.HasMin(), dp.Min()),
+							optionalValue(dp.HasMax(), dp.Max()),
+							dp.Scale(),
+							int64(dp.ZeroCount()),
+							dp.ZeroThreshold(),
+							dp.Positive().Offset(),
+							convertCounts(dp.Positive().BucketCounts()),
+							dp.Negative().Offset(),
+							convertCounts(dp.Negative().BucketCounts()),
+							int32(histogram.AggregationTemporality()),
+						))
+					}
+				case pmetric.MetricTypeSummary:
+					dps := metric.Summary().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						dp := dps.At(l)
+						quantiles := make([]float64, 0, dp.QuantileValues().Len())
+						values := make([]float64, 0, dp.QuantileValues().Len())
+						for m := 0; m < dp.QuantileValues().Len(); m++ {
+							quantiles = append(quantiles, dp.QuantileValues().At(m).Quantile())
+							values = append(values, dp.QuantileValues().At(m).Value())
+						}
+						rows[pmetric.MetricTypeSummary] = append(rows[pmetric.MetricTypeSummary], append(
+							common(dp.Attributes(), dp.StartTimestamp(), dp.Timestamp(), dp.Flags(), pmetric.NewExemplarSlice()),
+							int64(dp.Count()),
+							dp.Sum(),
+							quantiles,
+							values,
+						))
+					}
+				case pmetric.MetricTypeEmpty:
+					return errors.New("metric type is unset")
+				default:
+					return errors.New("unsupported metric type")
+				}
+			}
+		}
+	}
+
+	batches := make([]copyBatch, 0, len(metricTypes))
+	for _, metricType := range metricTypes {
+		table := metricTables[metricType]
+		batches = append(batches, copyBatch{
+			table:   e.cfg.Table.Metrics + table.suffix,
+			columns: append(append([]string{}, metricsColumns...), table.columns...),
+			rows:    rows[metricType],
+		})
+	}
+	return e.copyRows(ctx, batches...)
+}
+
+// numberValue returns the value of a number data point as a double.
+func numberValue(dp pmetric.NumberDataPoint) float64 {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		return float64(dp.IntValue())
+	}
+	return dp.DoubleValue()
+}
+
+// optionalValue returns nil, stored as NULL, when the value isn't set.
+func optionalValue(ok bool, value float64) *float64 {
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+func convertCounts(counts pcommon.UInt64Slice) []int64 {
+	converted := make([]int64, counts.Len())
+	for i := 0; i < counts.Len(); i++ {
+		converted[i] = int64(counts.At(i))
+	}
+	return converted
+}
+
+func convertExemplars(exemplars pmetric.ExemplarSlice) []pgExemplar {
+	pgExemplars := make([]pgExemplar, 0, exemplars.Len())
+	for i := 0; i < exemplars.Len(); i++ {
+		exemplar := exemplars.At(i)
+		value := exemplar.DoubleValue()
+		if exemplar.ValueType() == pmetric.ExemplarValueTypeInt {
+			value = float64(exemplar.IntValue())
+		}
+		pgExemplars = append(pgExemplars, pgExemplar{
+			FilteredAttributes: exemplar.FilteredAttributes().AsRaw(),
+			Timestamp:          exemplar.Timestamp().AsTime(),
+			Value:              value,
+			SpanID:             traceutil.SpanIDToHexOrEmptyString(exemplar.SpanID()),
+			TraceID:            traceutil.TraceIDToHexOrEmptyString(exemplar.TraceID()),
+		})
+	}
+	return pgExemplars
+}
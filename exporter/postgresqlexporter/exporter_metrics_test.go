@@ -0,0 +1,204 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresqlexporter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPushMetricData(t *testing.T) {
+	db := &fakeDatabase{}
+	exporter := newMetricsExporter(zaptest.NewLogger(t), createDefaultConfig().(*Config))
+	exporter.db = db
+
+	start := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	timestamp := start.Add(time.Minute)
+	setTimes := func(dp interface {
+		SetStartTimestamp(pcommon.Timestamp)
+		SetTimestamp(pcommon.Timestamp)
+	},
+	) {
+		dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	}
+
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "checkout")
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName("io.opentelemetry.contrib.postgresql")
+
+	gauge := sm.Metrics().AppendEmpty()
+	gauge.SetName("queue.size")
+	gauge.SetUnit("{item}")
+	gaugeDP := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	setTimes(gaugeDP)
+	gaugeDP.SetIntValue(12)
+	gaugeDP.Attributes().PutStr("queue", "payments")
+	exemplar := gaugeDP.Exemplars().AppendEmpty()
+	exemplar.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	exemplar.SetDoubleValue(13.5)
+	exemplar.SetTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	exemplar.SetSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+
+	sum := sm.Metrics().AppendEmpty()
+	sum.SetName("requests")
+	sum.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	sum.Sum().SetIsMonotonic(true)
+	sumDP := sum.Sum().DataPoints().AppendEmpty()
+	setTimes(sumDP)
+	sumDP.SetDoubleValue(3.5)
+
+	histogram := sm.Metrics().AppendEmpty()
+	histogram.SetName("latency")
+	histogram.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	histogramDP := histogram.Histogram().DataPoints().AppendEmpty()
+	setTimes(histogramDP)
+	histogramDP.SetCount(3)
+	histogramDP.SetSum(6)
+	histogramDP.SetMax(4)
+	histogramDP.BucketCounts().FromRaw([]uint64{1, 2, 0})
+	histogramDP.ExplicitBounds().FromRaw([]float64{1, 5})
+
+	exponentialHistogram := sm.Metrics().AppendEmpty()
+	exponentialHistogram.SetName("size")
+	exponentialHistogram.SetEmptyExponentialHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	exponentialHistogramDP := exponentialHistogram.ExponentialHistogram().DataPoints().AppendEmpty()
+	setTimes(exponentialHistogramDP)
+	exponentialHistogramDP.SetCount(4)
+	exponentialHistogramDP.SetScale(2)
+	exponentialHistogramDP.SetZeroCount(1)
+	exponentialHistogramDP.SetZeroThreshold(0.5)
+	exponentialHistogramDP.Positive().SetOffset(1)
+	exponentialHistogramDP.Positive().BucketCounts().FromRaw([]uint64{1, 1})
+	exponentialHistogramDP.Negative().SetOffset(-1)
+	exponentialHistogramDP.Negative().BucketCounts().FromRaw([]uint64{1})
+
+	summary := sm.Metrics().AppendEmpty()
+	summary.SetName("duration")
+	summaryDP := summary.SetEmptySummary().DataPoints().AppendEmpty()
+	setTimes(summaryDP)
+	summaryDP.SetCount(10)
+	summaryDP.SetSum(25)
+	quantile := summaryDP.QuantileValues().AppendEmpty()
+	quantile.SetQuantile(0.99)
+	quantile.SetValue(7)
+
+	require.NoError(t, exporter.pushMetricData(context.Background(), metrics))
+	require.Len(t, db.txs, 1)
+	require.True(t, db.txs[0].committed)
+	copies := db.txs[0].copies
+	require.Len(t, copies, 5)
+
+	common := func(name, unit string, attributes map[string]any, exemplars []pgExemplar) []any {
+		return []any{
+			"",
+			map[string]any{"service.name": "checkout"},
+			"",
+			"io.opentelemetry.contrib.postgresql",
+			"",
+			map[string]any{},
+			"checkout",
+			name,
+			"",
+			unit,
+			attributes,
+			start,
+			timestamp,
+			int32(0),
+			exemplars,
+		}
+	}
+	float := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		table    string
+		columns  []string
+		expected []any
+	}{
+		{
+			table:   "otel_metrics_gauge",
+			columns: []string{"value"},
+			expected: append(common("queue.size", "{item}", map[string]any{"queue": "payments"}, []pgExemplar{{
+				FilteredAttributes: map[string]any{},
+				Timestamp:          timestamp,
+				Value:              13.5,
+				SpanID:             "0102030405060708",
+				TraceID:            "0102030405060708090a0b0c0d0e0f10",
+			}}), float64(12)),
+		},
+		{
+			table:    "otel_metrics_sum",
+			columns:  []string{"value", "aggregation_temporality", "is_monotonic"},
+			expected: append(common("requests", "", map[string]any{}, []pgExemplar{}), 3.5, int32(pmetric.AggregationTemporalityCumulative), true),
+		},
+		{
+			table:   "otel_metrics_histogram",
+			columns: []string{"count", "sum", "min", "max", "bucket_counts", "explicit_bounds", "aggregation_temporality"},
+			expected: append(common("latency", "", map[string]any{}, []pgExemplar{}),
+				int64(3), float(6), (*float64)(nil), float(4), []int64{1, 2, 0}, []float64{1, 5}, int32(pmetric.AggregationTemporalityDelta)),
+		},
+		{
+			table: "otel_metrics_exponential_histogram",
+			columns: []string{
+				"count", "sum", "min", "max", "scale", "zero_count", "zero_threshold",
+				"positive_offset", "positive_bucket_counts", "negative_offset", "negative_bucket_counts", "aggregation_temporality",
+			},
+			expected: append(common("size", "", map[string]any{}, []pgExemplar{}),
+				int64(4), (*float64)(nil), (*float64)(nil), (*float64)(nil), int32(2), int64(1), 0.5,
+				int32(1), []int64{1, 1}, int32(-1), []int64{1}, int32(pmetric.AggregationTemporalityCumulative)),
+		},
+		{
+			table:    "otel_metrics_summary",
+			columns:  []string{"count", "sum", "quantiles", "quantile_values"},
+			expected: append(common("duration", "", map[string]any{}, []pgExemplar{}), int64(10), float64(25), []float64{0.99}, []float64{7}),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			copied := copies[i]
+			assert.Equal(t, pgx.Identifier{"public", tt.table}, copied.table)
+			assert.Equal(t, append(append([]string{}, metricsColumns...), tt.columns...), copied.columns)
+			require.Len(t, copied.rows, 1)
+			assert.Equal(t, tt.expected, copied.rows[0])
+		})
+	}
+}
+
+func TestPushMetricDataSkipsEmptyTables(t *testing.T) {
+	db := &fakeDatabase{}
+	exporter := newMetricsExporter(zaptest.NewLogger(t), createDefaultConfig().(*Config))
+	exporter.db = db
+
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("queue.size")
+	metric.SetEmptyGauge().DataPoints().AppendEmpty().SetDoubleValue(1)
+
+	require.NoError(t, exporter.pushMetricData(context.Background(), metrics))
+	require.Len(t, db.txs[0].copies, 1)
+	assert.Equal(t, pgx.Identifier{"public", "otel_metrics_gauge"}, db.txs[0].copies[0].table)
+}
+
+func TestPushMetricDataEmptyMetricType(t *testing.T) {
+	db := &fakeDatabase{}
+	exporter := newMetricsExporter(zaptest.NewLogger(t), createDefaultConfig().(*Config))
+	exporter.db = db
+
+	metrics := pmetric.NewMetrics()
+	metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetName("unset")
+
+	require.EqualError(t, exporter.pushMetricData(context.Background(), metrics), "metric type is unset")
+	assert.Empty(t, db.txs)
+}
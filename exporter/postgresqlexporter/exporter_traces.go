@@ -0,0 +1,141 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresqlexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/postgresqlexporter"
+
+import (
+	"context"
+	_ "embed" // for SQL file embedding
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/traceutil"
+)
+
+//go:embed sql/traces_ddl.sql
+var tracesDDL string
+
+var tracesColumns = []string{
+	"start_time",
+	"end_time",
+	"duration",
+	"trace_id",
+	"span_id",
+	"parent_span_id",
+	"trace_state",
+	"span_name",
+	"span_kind",
+	"service_name",
+	"resource_schema_url",
+	"resource_attributes",
+	"scope_schema_url",
+	"scope_name",
+	"scope_version",
+	"scope_attributes",
+	"span_attributes",
+	"status_code",
+	"status_message",
+	"events",
+	"links",
+}
+
+// pgEvent is a span event stored in the events JSONB column.
+type pgEvent struct {
+	Timestamp  time.Time      `json:"timestamp"`
+	Name       string         `json:"name"`
+	Attributes map[string]any `json:"attributes"`
+}
+
+// pgLink is a span link stored in the links JSONB column.
+type pgLink struct {
+	TraceID    string         `json:"trace_id"`
+	SpanID     string         `json:"span_id"`
+	TraceState string         `json:"trace_state"`
+	Attributes map[string]any `json:"attributes"`
+}
+
+type tracesExporter struct {
+	*commonExporter
+}
+
+func newTracesExporter(logger *zap.Logger, cfg *Config) *tracesExporter {
+	return &tracesExporter{
+		commonExporter: newExporter(logger, cfg, tableSchema{name: cfg.Table.Traces, ddl: tracesDDL, timeColumn: "start_time"}),
+	}
+}
+
+func (e *tracesExporter) pushTraceData(ctx context.Context, td ptrace.Traces) error {
+	rows := make([][]any, 0, td.SpanCount())
+
+	for i := 0; i < td.ResourceSpans().Len(); i++ {
+		resourceSpans := td.ResourceSpans().At(i)
+		resource := resourceSpans.Resource()
+		resourceAttributes := resource.Attributes().AsRaw()
+		serviceName := getServiceName(resource.Attributes())
+
+		for j := 0; j < resourceSpans.ScopeSpans().Len(); j++ {
+			scopeSpans := resourceSpans.ScopeSpans().At(j)
+			scope := scopeSpans.Scope()
+			scopeAttributes := scope.Attributes().AsRaw()
+
+			for k := 0; k < scopeSpans.Spans().Len(); k++ {
+				span := scopeSpans.Spans().At(k)
+
+				rows = append(rows, []any{
+					span.StartTimestamp().AsTime(),
+					span.EndTimestamp().AsTime(),
+					int64(span.EndTimestamp() - span.StartTimestamp()),
+					traceutil.TraceIDToHexOrEmptyString(span.TraceID()),
+					traceutil.SpanIDToHexOrEmptyString(span.SpanID()),
+					traceutil.SpanIDToHexOrEmptyString(span.ParentSpanID()),
+					span.TraceState().AsRaw(),
+					span.Name(),
+					span.Kind().String(),
+					serviceName,
+					resourceSpans.SchemaUrl(),
+					resourceAttributes,
+					scopeSpans.SchemaUrl(),
+					scope.Name(),
+					scope.Version(),
+					scopeAttributes,
+					span.Attributes().AsRaw(),
+					span.Status().Code().String(),
+					span.Status().Message(),
+					convertEvents(span.Events()),
+					convertLinks(span.Links()),
+				})
+			}
+		}
+	}
+
+	return e.copyRows(ctx, copyBatch{table: e.cfg.Table.Traces, columns: tracesColumns, rows: rows})
+}
+
+func convertEvents(events ptrace.SpanEventSlice) []pgEvent {
+	pgEvents := make([]pgEvent, 0, events.Len())
+	for i := 0; i < events.Len(); i++ {
+		event := events.At(i)
+		pgEvents = append(pgEvents, pgEvent{
+			Timestamp:  event.Timestamp().AsTime(),
+			Name:       event.Name(),
+			Attributes: event.Attributes().AsRaw(),
+		})
+	}
+	return pgEvents
+}
+
+func convertLinks(links ptrace.SpanLinkSlice) []pgLink {
+	pgLinks := make([]pgLink, 0, links.Len())
+	for i := 0; i < links.Len(); i++ {
+		link := links.At(i)
+		pgLinks = append(pgLinks, pgLink{
+			TraceID:    traceutil.TraceIDToHexOrEmptyString(link.TraceID()),
+			SpanID:     traceutil.SpanIDToHexOrEmptyString(link.SpanID()),
+			TraceState: link.TraceState().AsRaw(),
+			Attributes: link.Attributes().AsRaw(),
+		})
+	}
+	return pgLinks
+}
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package postgresqlexporter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPushTraceData(t *testing.T) {
+	db := &fakeDatabase{}
+	cfg := createDefaultConfig().(*Config)
+	cfg.Schema = "observability"
+	cfg.Table.Traces = "spans"
+	exporter := newTracesExporter(zaptest.NewLogger(t), cfg)
+	exporter.db = db
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	traces := ptrace.NewTraces()
+	rs := traces.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", "checkout")
+	ss := rs.ScopeSpans().AppendEmpty()
+	ss.Scope().SetName("io.opentelemetry.contrib.postgresql")
+	span := ss.Spans().AppendEmpty()
+	span.SetTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	span.SetSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	span.SetParentSpanID([8]byte{8, 7, 6, 5, 4, 3, 2, 1})
+	span.TraceState().FromRaw("vendor=value")
+	span.SetName("POST /pay")
+	span.SetKind(ptrace.SpanKindServer)
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	span.SetEndTimestamp(pcommon.NewTimestampFromTime(start.Add(1500 * time.Millisecond)))
+	span.Attributes().PutInt("http.response.status_code", 500)
+	span.Status().SetCode(ptrace.StatusCodeError)
+	span.Status().SetMessage("payment failed")
+	event := span.Events().AppendEmpty()
+	event.SetName("exception")
+	event.SetTimestamp(pcommon.NewTimestampFromTime(start.Add(time.Second)))
+	event.Attributes().PutStr("exception.type", "TimeoutError")
+	link := span.Links().AppendEmpty()
+	link.SetTraceID([16]byte{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1})
+	link.SetSpanID([8]byte{1, 1, 1, 1, 1, 1, 1, 1})
+
+	require.NoError(t, exporter.pushTraceData(context.Background(), traces))
+	require.Len(t, db.txs, 1)
+	require.True(t, db.txs[0].committed)
+	require.Len(t, db.txs[0].copies, 1)
+
+	copied := db.txs[0].copies[0]
+	assert.Equal(t, pgx.Identifier{"observability", "spans"}, copied.table)
+	assert.Equal(t, tracesColumns, copied.columns)
+	require.Len(t, copied.rows, 1)
+	assert.Equal(t, []any{
+		start,
+		start.Add(1500 * time.Millisecond),
+		int64(1500 * time.Millisecond),
+		"0102030405060708090a0b0c0d0e0f10",
+		"0102030405060708",
+		"0807060504030201",
+		"vendor=value",
+		"POST /pay",
+		"Server",
+		"checkout",
+		"",
+		map[string]any{"service.name": "checkout"},
+		"",
+		"io.opentelemetry.contrib.postgresql",
+		"",
+		map[string]any{},
+		map[string]any{"http.response.status_code": int64(500)},
+		"Error",
+		"payment failed",
+		[]pgEvent{{Timestamp: start.Add(time.Second), Name: "exception", Attributes: map[string]any{"exception.type": "TimeoutError"}}},
+		[]pgLink{{TraceID: "100f0e0d0c0b0a090807060504030201", SpanID: "0101010101010101", Attributes: map[string]any{}}},
+	}, copied.rows[0])
+}
+
+func TestPushTraceDataNUL(t *testing.T) {
+	db := &fakeDatabase{}
+	exporter := newTracesExporter(zaptest.NewLogger(t), createDefaultConfig().(*Config))
+	exporter.db = db
+
+	traces := ptrace.NewTraces()
+	span := traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName("POST /pay\x00")
+	span.Attributes().PutStr("http.route", "/pay\x00")
+	span.Status().SetMessage("payment\x00 failed")
+	event := span.Events().AppendEmpty()
+	event.SetName("exception\x00")
+	event.Attributes().PutStr("exception.message", "invalid\x00 card")
+	span.Links().AppendEmpty().Attributes().PutStr("link.kind", "\x00retry")
+
+	require.NoError(t, exporter.pushTraceData(context.Background(), traces))
+	require.Len(t, db.txs, 1)
+	require.Len(t, db.txs[0].copies, 1)
+	row := db.txs[0].copies[0].rows[0]
+	assert.Equal(t, "POST /pay", row[7])
+	assert.Equal(t, map[string]any{"http.route": "/pay"}, row[16])
+	assert.Equal(t, "payment failed", row[18])
+	assert.Equal(t, []pgEvent{{Timestamp: time.Unix(0, 0).UTC(), Name: "exception", Attributes: map[string]any{"exception.message": "invalid card"}}}, row[19])
+	assert.Equal(t, []pgLink{{Attributes: map[string]any{"link.kind": "retry"}}}, row[20])
+}
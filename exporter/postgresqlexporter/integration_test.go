@@ -0,0 +1,222 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package postgresqlexporter
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestIntegration(t *testing.T) {
+	tests := []struct {
+		name      string
+		image     string
+		timescale bool
+	}{
+		{
+			name:  "postgres 17",
+			image: "postgres:17-alpine",
+		},
+		{
+			name:      "timescaledb 2.17",
+			image:     "timescale/timescaledb:2.17.2-pg17",
+			timescale: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+				ContainerRequest: testcontainers.ContainerRequest{
+					Image:        tt.image,
+					ExposedPorts: []string{"5432/tcp"},
+					Env: map[string]string{
+						"POSTGRES_USER":     "otel",
+						"POSTGRES_PASSWORD": "otel",
+						"POSTGRES_DB":       "otel",
+					},
+					// The server restarts once after running the initialization scripts.
+					WaitingFor: wait.ForLog("database system is ready to accept connections").
+						WithOccurrence(2).
+						WithStartupTimeout(2 * time.Minute),
+				},
+				Started: true,
+			})
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, container.Terminate(ctx))
+			}()
+
+			host, err := container.Host(ctx)
+			require.NoError(t, err)
+			port, err := container.MappedPort(ctx, "5432")
+			require.NoError(t, err)
+
+			cfg := createDefaultConfig().(*Config)
+			cfg.Endpoint = net.JoinHostPort(host, port.Port())
+			cfg.Username = "otel"
+			cfg.Password = "otel"
+			cfg.TLS.Insecure = true
+			cfg.Schema = "telemetry"
+			cfg.Timescale.Enabled = tt.timescale
+			if tt.timescale {
+				cfg.Timescale.Retention = 30 * 24 * time.Hour
+			}
+
+			poolConfig, err := cfg.poolConfig(ctx)
+			require.NoError(t, err)
+			pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+			require.NoError(t, err)
+			defer pool.Close()
+
+			verifyExportLogs(t, cfg, pool)
+			verifyExportTraces(t, cfg, pool)
+			verifyExportMetrics(t, cfg, pool)
+
+			if tt.timescale {
+				var hypertables int
+				require.NoError(t, pool.QueryRow(ctx,
+					"SELECT count(*) FROM timescaledb_information.hypertables WHERE hypertable_schema = 'telemetry'").Scan(&hypertables))
+				require.Equal(t, 7, hypertables)
+
+				var policies int
+				require.NoError(t, pool.QueryRow(ctx,
+					"SELECT count(*) FROM timescaledb_information.jobs WHERE proc_name = 'policy_retention' AND hypertable_schema = 'telemetry'").Scan(&policies))
+				require.Equal(t, 7, policies)
+			}
+		})
+	}
+}
+
+func verifyExportLogs(t *testing.T, cfg *Config, pool *pgxpool.Pool) {
+	ctx := context.Background()
+	exporter := newLogsExporter(zaptest.NewLogger(t), cfg)
+	require.NoError(t, exporter.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, exporter.shutdown(ctx))
+	}()
+
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "checkout")
+	r := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	r.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	r.SetSeverityText("ERROR")
+	// PostgreSQL rejects NUL characters in text and in JSONB.
+	r.Body().SetStr("payment\x00 failed")
+	r.Attributes().PutInt("http.response.status_code", 500)
+	r.Attributes().PutStr("payment.card", "visa\x00")
+	require.NoError(t, exporter.pushLogData(ctx, logs))
+
+	var serviceName, body string
+	var statusCode int
+	require.NoError(t, pool.QueryRow(ctx,
+		`SELECT service_name, body, (log_attributes->>'http.response.status_code')::int FROM telemetry.otel_logs
+		WHERE log_attributes @> '{"http.response.status_code": 500}'`).Scan(&serviceName, &body, &statusCode))
+	require.Equal(t, "checkout", serviceName)
+	require.Equal(t, "payment failed", body)
+	require.Equal(t, 500, statusCode)
+}
+
+func verifyExportTraces(t *testing.T, cfg *Config, pool *pgxpool.Pool) {
+	ctx := context.Background()
+	exporter := newTracesExporter(zaptest.NewLogger(t), cfg)
+	require.NoError(t, exporter.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, exporter.shutdown(ctx))
+	}()
+
+	start := time.Now()
+	traces := ptrace.NewTraces()
+	rs := traces.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", "checkout")
+	span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetTraceID([16]byte{1, 2, 3})
+	span.SetSpanID([8]byte{1, 2, 3})
+	span.SetName("POST /pay")
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	span.SetEndTimestamp(pcommon.NewTimestampFromTime(start.Add(time.Second)))
+	span.Events().AppendEmpty().SetName("exception")
+	require.NoError(t, exporter.pushTraceData(ctx, traces))
+
+	var name, event string
+	var duration int64
+	require.NoError(t, pool.QueryRow(ctx,
+		`SELECT span_name, duration, events->0->>'name' FROM telemetry.otel_traces WHERE trace_id = $1`,
+		"01020300000000000000000000000000").Scan(&name, &duration, &event))
+	require.Equal(t, "POST /pay", name)
+	require.Equal(t, int64(time.Second), duration)
+	require.Equal(t, "exception", event)
+}
+
+func verifyExportMetrics(t *testing.T, cfg *Config, pool *pgxpool.Pool) {
+	ctx := context.Background()
+	exporter := newMetricsExporter(zaptest.NewLogger(t), cfg)
+	require.NoError(t, exporter.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, exporter.shutdown(ctx))
+	}()
+
+	now := pcommon.NewTimestampFromTime(time.Now())
+	metrics := pmetric.NewMetrics()
+	sm := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	gauge := sm.Metrics().AppendEmpty()
+	gauge.SetName("queue.size")
+	gaugeDP := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	gaugeDP.SetTimestamp(now)
+	gaugeDP.SetIntValue(12)
+	sum := sm.Metrics().AppendEmpty()
+	sum.SetName("requests")
+	sumDP := sum.SetEmptySum().DataPoints().AppendEmpty()
+	sumDP.SetTimestamp(now)
+	sumDP.SetDoubleValue(3)
+	histogram := sm.Metrics().AppendEmpty()
+	histogram.SetName("latency")
+	histogramDP := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+	histogramDP.SetTimestamp(now)
+	histogramDP.SetCount(3)
+	histogramDP.BucketCounts().FromRaw([]uint64{1, 2})
+	histogramDP.ExplicitBounds().FromRaw([]float64{5})
+	exponentialHistogram := sm.Metrics().AppendEmpty()
+	exponentialHistogram.SetName("size")
+	exponentialHistogramDP := exponentialHistogram.SetEmptyExponentialHistogram().DataPoints().AppendEmpty()
+	exponentialHistogramDP.SetTimestamp(now)
+	exponentialHistogramDP.Positive().BucketCounts().FromRaw([]uint64{1})
+	summary := sm.Metrics().AppendEmpty()
+	summary.SetName("duration")
+	summaryDP := summary.SetEmptySummary().DataPoints().AppendEmpty()
+	summaryDP.SetTimestamp(now)
+	quantile := summaryDP.QuantileValues().AppendEmpty()
+	quantile.SetQuantile(0.5)
+	quantile.SetValue(2)
+	require.NoError(t, exporter.pushMetricData(ctx, metrics))
+
+	for _, table := range []string{"gauge", "sum", "histogram", "exponential_histogram", "summary"} {
+		var count int
+		require.NoError(t, pool.QueryRow(ctx, "SELECT count(*) FROM telemetry.otel_metrics_"+table).Scan(&count))
+		require.Equal(t, 1, count, table)
+	}
+
+	var bucketCounts []int64
+	var sumValue *float64
+	require.NoError(t, pool.QueryRow(ctx, "SELECT bucket_counts, sum FROM telemetry.otel_metrics_histogram").Scan(&bucketCounts, &sumValue))
+	require.Equal(t, []int64{1, 2}, bucketCounts)
+	require.Nil(t, sumValue)
+}
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS %[1]s
+(
+    timestamp               TIMESTAMPTZ NOT NULL,
+    observed_timestamp      TIMESTAMPTZ NOT NULL,
+    trace_id                TEXT NOT NULL,
+    span_id                 TEXT NOT NULL,
+    trace_flags             INTEGER NOT NULL,
+    severity_text           TEXT NOT NULL,
+    severity_number         INTEGER NOT NULL,
+    service_name            TEXT NOT NULL,
+    body                    TEXT NOT NULL,
+    resource_schema_url     TEXT NOT NULL,
+    resource_attributes     JSONB NOT NULL,
+    scope_schema_url        TEXT NOT NULL,
+    scope_name              TEXT NOT NULL,
+    scope_version           TEXT NOT NULL,
+    scope_attributes        JSONB NOT NULL,
+    log_attributes          JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS %[2]s_timestamp_idx ON %[1]s (timestamp DESC);
+CREATE INDEX IF NOT EXISTS %[2]s_service_name_timestamp_idx ON %[1]s (service_name, timestamp DESC);
+CREATE INDEX IF NOT EXISTS %[2]s_trace_id_idx ON %[1]s (trace_id) WHERE trace_id <> '';
+CREATE INDEX IF NOT EXISTS %[2]s_resource_attributes_idx ON %[1]s USING GIN (resource_attributes jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS %[2]s_log_attributes_idx ON %[1]s USING GIN (log_attributes jsonb_path_ops);
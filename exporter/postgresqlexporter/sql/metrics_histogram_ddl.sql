@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS %[1]s
+(
+    resource_schema_url     TEXT NOT NULL,
+    resource_attributes     JSONB NOT NULL,
+    scope_schema_url        TEXT NOT NULL,
+    scope_name              TEXT NOT NULL,
+    scope_version           TEXT NOT NULL,
+    scope_attributes        JSONB NOT NULL,
+    service_name            TEXT NOT NULL,
+    metric_name             TEXT NOT NULL,
+    metric_description      TEXT NOT NULL,
+    metric_unit             TEXT NOT NULL,
+    attributes              JSONB NOT NULL,
+    start_time              TIMESTAMPTZ NOT NULL,
+    time                    TIMESTAMPTZ NOT NULL,
+    flags                   INTEGER NOT NULL,
+    exemplars               JSONB NOT NULL,
+    count                   BIGINT NOT NULL,
+    sum                     DOUBLE PRECISION,
+    min                     DOUBLE PRECISION,
+    max                     DOUBLE PRECISION,
+    bucket_counts           BIGINT[] NOT NULL,
+    explicit_bounds         DOUBLE PRECISION[] NOT NULL,
+    aggregation_temporality INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS %[2]s_time_idx ON %[1]s (time DESC);
+CREATE INDEX IF NOT EXISTS %[2]s_metric_name_time_idx ON %[1]s (service_name, metric_name, time DESC);
+CREATE INDEX IF NOT EXISTS %[2]s_resource_attributes_idx ON %[1]s USING GIN (resource_attributes jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS %[2]s_attributes_idx ON %[1]s USING GIN (attributes jsonb_path_ops);
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS %[1]s
+(
+    start_time              TIMESTAMPTZ NOT NULL,
+    end_time                TIMESTAMPTZ NOT NULL,
+    duration                BIGINT NOT NULL,
+    trace_id                TEXT NOT NULL,
+    span_id                 TEXT NOT NULL,
+    parent_span_id          TEXT NOT NULL,
+    trace_state             TEXT NOT NULL,
+    span_name               TEXT NOT NULL,
+    span_kind               TEXT NOT NULL,
+    service_name            TEXT NOT NULL,
+    resource_schema_url     TEXT NOT NULL,
+    resource_attributes     JSONB NOT NULL,
+    scope_schema_url        TEXT NOT NULL,
+    scope_name              TEXT NOT NULL,
+    scope_version           TEXT NOT NULL,
+    scope_attributes        JSONB NOT NULL,
+    span_attributes         JSONB NOT NULL,
+    status_code             TEXT NOT NULL,
+    status_message          TEXT NOT NULL,
+    events                  JSONB NOT NULL,
+    links                   JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS %[2]s_start_time_idx ON %[1]s (start_time DESC);
+CREATE INDEX IF NOT EXISTS %[2]s_service_name_start_time_idx ON %[1]s (service_name, span_name, start_time DESC);
+CREATE INDEX IF NOT EXISTS %[2]s_trace_id_idx ON %[1]s (trace_id);
+CREATE INDEX IF NOT EXISTS %[2]s_resource_attributes_idx ON %[1]s USING GIN (resource_attributes jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS %[2]s_span_attributes_idx ON %[1]s USING GIN (span_attributes jsonb_path_ops);
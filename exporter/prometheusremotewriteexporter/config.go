@@ -5,6 +5,7 @@ package prometheusremotewriteexporter // import "github.com/open-telemetry/opent
 
 import (
 	"fmt"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/confighttp"
@@ -79,6 +80,24 @@ type RemoteWriteQueue struct {
 	// NumWorkers configures the number of workers used by
 	// the collector to fan out remote write requests.
 	NumConsumers int `mapstructure:"num_consumers"`
+
+	// DynamicSharding resizes the number of workers based on the time spent sending
+	// the remote write requests. The workers are fixed if not set.
+	DynamicSharding *DynamicShardingConfig `mapstructure:"dynamic_sharding"`
+}
+
+// DynamicShardingConfig configures the dynamic resizing of the remote write shards.
+// The time series are split between the shards by hashing their labels, so that the
+// samples of a series are always sent in order by the same shard.
+type DynamicShardingConfig struct {
+	// MinShards is the minimum number of shards. Defaults to 1.
+	MinShards int `mapstructure:"min_shards"`
+
+	// MaxShards is the maximum number of shards. Defaults to 50.
+	MaxShards int `mapstructure:"max_shards"`
+
+	// UpdateInterval is how often the number of shards is computed again. Defaults to 10s.
+	UpdateInterval time.Duration `mapstructure:"update_interval"`
 }
 
 // TODO(jbd): Add capacity, max_samples_per_send to QueueConfig.
@@ -99,6 +118,18 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("remote write consumer number can't be negative")
 	}
 
+	if sharding := cfg.RemoteWriteQueue.DynamicSharding; sharding != nil {
+		if sharding.MinShards < 0 || sharding.MaxShards < 0 {
+			return fmt.Errorf("remote write min and max shards can't be negative")
+		}
+		if sharding.maxShards() < sharding.minShards() {
+			return fmt.Errorf("remote write max shards can't be lower than min shards")
+		}
+		if sharding.UpdateInterval < 0 {
+			return fmt.Errorf("remote write shards update interval can't be negative")
+		}
+	}
+
 	if cfg.TargetInfo == nil {
 		cfg.TargetInfo = &TargetInfo{
 			Enabled: true,
@@ -124,6 +124,53 @@ func TestDisabledQueue(t *testing.T) {
 	assert.False(t, cfg.(*Config).RemoteWriteQueue.Enabled)
 }
 
+func TestDynamicSharding(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		id           component.ID
+		expected     *DynamicShardingConfig
+		errorMessage string
+	}{
+		{
+			id: component.NewIDWithName(metadata.Type, "dynamic_sharding"),
+			expected: &DynamicShardingConfig{
+				MinShards:      2,
+				MaxShards:      100,
+				UpdateInterval: 30 * time.Second,
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "negative_min_shards"),
+			errorMessage: "remote write min and max shards can't be negative",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "max_shards_lower_than_min_shards"),
+			errorMessage: "remote write max shards can't be lower than min shards",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+			assert.Nil(t, cfg.(*Config).RemoteWriteQueue.DynamicSharding)
+
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expected == nil {
+				assert.EqualError(t, component.ValidateConfig(cfg), tt.errorMessage)
+				return
+			}
+			assert.NoError(t, component.ValidateConfig(cfg))
+			assert.Equal(t, tt.expected, cfg.(*Config).RemoteWriteQueue.DynamicSharding)
+		})
+	}
+}
+
 func TestDisabledTargetInfo(t *testing.T) {
 	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
 	require.NoError(t, err)
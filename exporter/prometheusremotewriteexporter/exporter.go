@@ -38,6 +38,8 @@ import (
 type prwTelemetry interface {
 	recordTranslationFailure(ctx context.Context)
 	recordTranslatedTimeSeries(ctx context.Context, numTS int)
+	recordShards(ctx context.Context, numShards int)
+	recordDesiredShards(ctx context.Context, desiredShards float64)
 }
 
 type prwTelemetryOtel struct {
@@ -53,6 +55,14 @@ func (p *prwTelemetryOtel) recordTranslatedTimeSeries(ctx context.Context, numTS
 	p.telemetryBuilder.ExporterPrometheusremotewriteTranslatedTimeSeries.Add(ctx, int64(numTS), metric.WithAttributes(p.otelAttrs...))
 }
 
+func (p *prwTelemetryOtel) recordShards(ctx context.Context, numShards int) {
+	p.telemetryBuilder.ExporterPrometheusremotewriteShards.Record(ctx, int64(numShards), metric.WithAttributes(p.otelAttrs...))
+}
+
+func (p *prwTelemetryOtel) recordDesiredShards(ctx context.Context, desiredShards float64) {
+	p.telemetryBuilder.ExporterPrometheusremotewriteShardsDesired.Record(ctx, desiredShards, metric.WithAttributes(p.otelAttrs...))
+}
+
 type buffer struct {
 	protobuf *proto.Buffer
 	snappy   []byte
@@ -82,6 +92,7 @@ type prwExporter struct {
 	retrySettings     configretry.BackOffConfig
 	retryOnHTTP429    bool
 	wal               *prweWAL
+	shards            *shardManager
 	exporterSettings  prometheusremotewrite.Settings
 	telemetry         prwTelemetry
 
@@ -152,6 +163,9 @@ func newPRWExporter(cfg *Config, set exporter.Settings) (*prwExporter, error) {
 	}
 
 	prwe.wal = newWAL(cfg.WAL, prwe.export)
+	if sharding := cfg.RemoteWriteQueue.DynamicSharding; sharding != nil {
+		prwe.shards = newShardManager(sharding, cfg.RemoteWriteQueue.NumConsumers, cfg.MaxBatchSizeBytes, prwTelemetry, set.Logger)
+	}
 	return prwe, nil
 }
 
@@ -161,6 +175,13 @@ func (prwe *prwExporter) Start(ctx context.Context, host component.Host) (err er
 	if err != nil {
 		return err
 	}
+	if prwe.shards != nil {
+		prwe.wg.Add(1)
+		go func() {
+			defer prwe.wg.Done()
+			prwe.shards.run(prwe.closeChan)
+		}()
+	}
 	return prwe.turnOnWALIfEnabled(contextWithLogger(ctx, prwe.settings.Logger.Named("prw.wal")))
 }
 
@@ -254,6 +275,10 @@ func (prwe *prwExporter) handleExport(ctx context.Context, tsMap map[string]*pro
 
 // export sends a Snappy-compressed WriteRequest containing TimeSeries to a remote write endpoint in order
 func (prwe *prwExporter) export(ctx context.Context, requests []*prompb.WriteRequest) error {
+	if prwe.shards != nil {
+		return prwe.shards.send(ctx, requests, prwe.execute)
+	}
+
 	input := make(chan *prompb.WriteRequest, len(requests))
 	for _, request := range requests {
 		input <- request
@@ -26,6 +26,8 @@ func Tracer(settings component.TelemetrySettings) trace.Tracer {
 type TelemetryBuilder struct {
 	meter                                             metric.Meter
 	ExporterPrometheusremotewriteFailedTranslations   metric.Int64Counter
+	ExporterPrometheusremotewriteShards               metric.Int64Gauge
+	ExporterPrometheusremotewriteShardsDesired        metric.Float64Gauge
 	ExporterPrometheusremotewriteTranslatedTimeSeries metric.Int64Counter
 }
 
@@ -55,6 +57,18 @@ func NewTelemetryBuilder(settings component.TelemetrySettings, options ...Teleme
 		metric.WithUnit("1"),
 	)
 	errs = errors.Join(errs, err)
+	builder.ExporterPrometheusremotewriteShards, err = getLeveledMeter(builder.meter, configtelemetry.LevelBasic, settings.MetricsLevel).Int64Gauge(
+		"otelcol_exporter_prometheusremotewrite_shards",
+		metric.WithDescription("Number of shards sending remote write requests concurrently"),
+		metric.WithUnit("{shard}"),
+	)
+	errs = errors.Join(errs, err)
+	builder.ExporterPrometheusremotewriteShardsDesired, err = getLeveledMeter(builder.meter, configtelemetry.LevelBasic, settings.MetricsLevel).Float64Gauge(
+		"otelcol_exporter_prometheusremotewrite_shards_desired",
+		metric.WithDescription("Number of shards the dynamic sharding computed to keep up with the send rate, before applying the tolerance and the min and max shards"),
+		metric.WithUnit("{shard}"),
+	)
+	errs = errors.Join(errs, err)
 	builder.ExporterPrometheusremotewriteTranslatedTimeSeries, err = getLeveledMeter(builder.meter, configtelemetry.LevelBasic, settings.MetricsLevel).Int64Counter(
 		"otelcol_exporter_prometheusremotewrite_translated_time_series",
 		metric.WithDescription("Number of Prometheus time series that were translated from OTel metrics"),
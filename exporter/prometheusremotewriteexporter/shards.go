@@ -0,0 +1,224 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/prometheusremotewriteexporter"
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultMinShards           = 1
+	defaultMaxShards           = 50
+	defaultShardUpdateInterval = 10 * time.Second
+
+	// shardTargetUtilization is the fraction of the time the shards should spend sending requests. The
+	// headroom absorbs bursts, and lets the number of shards grow when the shards can't keep up anymore.
+	shardTargetUtilization = 0.5
+	// shardToleranceFraction avoids resharding when the desired number of shards changes slightly.
+	shardToleranceFraction = 0.3
+	// shardEWMAWeight is the weight of the last update interval in the moving average of the busy shards.
+	shardEWMAWeight = 0.2
+)
+
+func (sc *DynamicShardingConfig) minShards() int {
+	if sc.MinShards > 0 {
+		return sc.MinShards
+	}
+	return defaultMinShards
+}
+
+func (sc *DynamicShardingConfig) maxShards() int {
+	if sc.MaxShards > 0 {
+		return sc.MaxShards
+	}
+	return max(defaultMaxShards, sc.minShards())
+}
+
+func (sc *DynamicShardingConfig) updateInterval() time.Duration {
+	if sc.UpdateInterval > 0 {
+		return sc.UpdateInterval
+	}
+	return defaultShardUpdateInterval
+}
+
+// shardManager splits the time series of the remote write requests between shards sending them
+// concurrently, and resizes the number of shards based on the time the shards spend sending requests.
+type shardManager struct {
+	config            *DynamicShardingConfig
+	maxBatchSizeBytes int
+	telemetry         prwTelemetry
+	logger            *zap.Logger
+
+	// mu is held for reading while sending requests, and for writing while resharding, so that the
+	// series moved to another shard are only sent by the new shard once the old one sent them.
+	mu        sync.RWMutex
+	numShards int
+
+	// sendTime is the time spent sending requests by all the shards since the last update, in nanoseconds.
+	sendTime   atomic.Int64
+	lastUpdate time.Time
+	// busyShards is the moving average of the number of shards busy sending requests.
+	busyShards  float64
+	initialized bool
+}
+
+func newShardManager(config *DynamicShardingConfig, initialShards, maxBatchSizeBytes int, telemetry prwTelemetry, logger *zap.Logger) *shardManager {
+	return &shardManager{
+		config:            config,
+		maxBatchSizeBytes: maxBatchSizeBytes,
+		telemetry:         telemetry,
+		logger:            logger,
+		numShards:         min(max(initialShards, config.minShards()), config.maxShards()),
+	}
+}
+
+// run updates the number of shards periodically until stop is closed.
+func (m *shardManager) run(stop <-chan struct{}) {
+	ctx := context.Background()
+	m.lastUpdate = time.Now()
+	m.telemetry.recordShards(ctx, m.numShards)
+
+	ticker := time.NewTicker(m.config.updateInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			m.update(ctx, now)
+		}
+	}
+}
+
+// update computes the number of shards needed to keep up with the time spent sending requests, and
+// reshards when it differs from the current number of shards by more than the tolerance.
+func (m *shardManager) update(ctx context.Context, now time.Time) {
+	elapsed := now.Sub(m.lastUpdate)
+	if elapsed <= 0 {
+		return
+	}
+	m.lastUpdate = now
+
+	busyShards := float64(m.sendTime.Swap(0)) / float64(elapsed)
+	if m.initialized {
+		m.busyShards = shardEWMAWeight*busyShards + (1-shardEWMAWeight)*m.busyShards
+	} else {
+		m.busyShards = busyShards
+		m.initialized = true
+	}
+	desiredShards := m.busyShards / shardTargetUtilization
+	m.telemetry.recordDesiredShards(ctx, desiredShards)
+
+	// numShards is only written by this goroutine, reading it doesn't require the lock.
+	current := float64(m.numShards)
+	if desiredShards >= current*(1-shardToleranceFraction) && desiredShards <= current*(1+shardToleranceFraction) {
+		return
+	}
+	numShards := min(max(int(math.Ceil(desiredShards)), m.config.minShards()), m.config.maxShards())
+	if numShards == m.numShards {
+		return
+	}
+
+	m.logger.Debug("Resharding the remote write requests",
+		zap.Int("from", m.numShards), zap.Int("to", numShards), zap.Float64("desired", desiredShards))
+	m.mu.Lock()
+	m.numShards = numShards
+	m.mu.Unlock()
+	m.telemetry.recordShards(ctx, numShards)
+}
+
+// send splits the time series of the requests between the shards, and sends the requests of each shard
+// in order. The shards send their requests concurrently.
+func (m *shardManager) send(ctx context.Context, requests []*prompb.WriteRequest, execute func(context.Context, *prompb.WriteRequest) error) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs error
+	for _, shard := range m.shardRequests(requests) {
+		if len(shard) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, request := range shard {
+				if ctx.Err() != nil {
+					return
+				}
+				start := time.Now()
+				err := execute(ctx, request)
+				m.sendTime.Add(int64(time.Since(start)))
+				if err != nil {
+					mu.Lock()
+					errs = multierr.Append(errs, consumererror.NewPermanent(err))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// shardRequests batches again the time series of the requests per shard. The metadata is sent by the first shard.
+func (m *shardManager) shardRequests(requests []*prompb.WriteRequest) [][]*prompb.WriteRequest {
+	shards := make([][]*prompb.WriteRequest, m.numShards)
+	series := make([][]prompb.TimeSeries, m.numShards)
+	sizes := make([]int, m.numShards)
+	for _, request := range requests {
+		if len(request.Metadata) > 0 {
+			shards[0] = append(shards[0], &prompb.WriteRequest{Metadata: request.Metadata})
+		}
+		for _, ts := range request.Timeseries {
+			shard := seriesShard(ts.Labels, m.numShards)
+			size := ts.Size()
+			if len(series[shard]) > 0 && sizes[shard]+size >= m.maxBatchSizeBytes {
+				shards[shard] = append(shards[shard], &prompb.WriteRequest{Timeseries: series[shard]})
+				series[shard] = nil
+				sizes[shard] = 0
+			}
+			series[shard] = append(series[shard], ts)
+			sizes[shard] += size
+		}
+	}
+	for shard := range series {
+		if len(series[shard]) > 0 {
+			shards[shard] = append(shards[shard], &prompb.WriteRequest{Timeseries: series[shard]})
+		}
+	}
+	return shards
+}
+
+// seriesShard returns the shard of a time series, computed with the FNV-1a hash of its labels.
+func seriesShard(labels []prompb.Label, numShards int) int {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+		sep      = 0xff
+	)
+	hash := uint64(offset64)
+	for _, label := range labels {
+		for i := 0; i < len(label.Name); i++ {
+			hash = (hash ^ uint64(label.Name[i])) * prime64
+		}
+		hash = (hash ^ sep) * prime64
+		for i := 0; i < len(label.Value); i++ {
+			hash = (hash ^ uint64(label.Value[i])) * prime64
+		}
+		hash = (hash ^ sep) * prime64
+	}
+	return int(hash % uint64(numShards))
+}
@@ -0,0 +1,322 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheusremotewriteexporter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+)
+
+// shardsTelemetry records the number of shards.
+type shardsTelemetry struct {
+	prwTelemetry
+	shards        []int
+	desiredShards []float64
+}
+
+func (s *shardsTelemetry) recordShards(_ context.Context, numShards int) {
+	s.shards = append(s.shards, numShards)
+}
+
+func (s *shardsTelemetry) recordDesiredShards(_ context.Context, desiredShards float64) {
+	s.desiredShards = append(s.desiredShards, desiredShards)
+}
+
+func newTestSeries(n int) []prompb.TimeSeries {
+	series := make([]prompb.TimeSeries, n)
+	for i := range series {
+		series[i] = prompb.TimeSeries{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "test_metric"}, {Name: "id", Value: strconv.Itoa(i)}},
+			Samples: []prompb.Sample{{Value: float64(i), Timestamp: int64(i)}},
+		}
+	}
+	return series
+}
+
+func TestDynamicShardingConfigDefaults(t *testing.T) {
+	cfg := &DynamicShardingConfig{}
+	assert.Equal(t, defaultMinShards, cfg.minShards())
+	assert.Equal(t, defaultMaxShards, cfg.maxShards())
+	assert.Equal(t, defaultShardUpdateInterval, cfg.updateInterval())
+
+	cfg = &DynamicShardingConfig{MinShards: 60}
+	assert.Equal(t, 60, cfg.maxShards())
+
+	cfg = &DynamicShardingConfig{MinShards: 2, MaxShards: 8, UpdateInterval: time.Second}
+	assert.Equal(t, 2, cfg.minShards())
+	assert.Equal(t, 8, cfg.maxShards())
+	assert.Equal(t, time.Second, cfg.updateInterval())
+}
+
+func TestNewShardManagerInitialShards(t *testing.T) {
+	cfg := &DynamicShardingConfig{MinShards: 2, MaxShards: 8}
+	assert.Equal(t, 2, newShardManager(cfg, 1, 1000, &shardsTelemetry{}, zap.NewNop()).numShards)
+	assert.Equal(t, 5, newShardManager(cfg, 5, 1000, &shardsTelemetry{}, zap.NewNop()).numShards)
+	assert.Equal(t, 8, newShardManager(cfg, 10, 1000, &shardsTelemetry{}, zap.NewNop()).numShards)
+}
+
+func TestSeriesShard(t *testing.T) {
+	counts := make([]int, 10)
+	for _, ts := range newTestSeries(1000) {
+		shard := seriesShard(ts.Labels, 10)
+		// The same series is always assigned to the same shard.
+		assert.Equal(t, shard, seriesShard(ts.Labels, 10))
+		counts[shard]++
+	}
+	for shard, count := range counts {
+		assert.Positive(t, count, "shard %d has no series", shard)
+	}
+
+	// The separators prevent series whose concatenated labels are equal from colliding.
+	assert.NotEqual(t,
+		seriesShard([]prompb.Label{{Name: "a", Value: "bc"}}, 1<<30),
+		seriesShard([]prompb.Label{{Name: "ab", Value: "c"}}, 1<<30))
+}
+
+func TestShardRequests(t *testing.T) {
+	series := newTestSeries(100)
+	m := newShardManager(&DynamicShardingConfig{}, 4, series[0].Size()*10, &shardsTelemetry{}, zap.NewNop())
+	metadata := []prompb.MetricMetadata{{MetricFamilyName: "test_metric", Type: prompb.MetricMetadata_GAUGE}}
+	requests := []*prompb.WriteRequest{
+		{Timeseries: series[:50]},
+		{Timeseries: series[50:]},
+		{Metadata: metadata},
+	}
+
+	shards := m.shardRequests(requests)
+	require.Len(t, shards, 4)
+
+	seen := 0
+	for shard, shardRequests := range shards {
+		for _, request := range shardRequests {
+			if len(request.Metadata) > 0 {
+				assert.Equal(t, 0, shard)
+				assert.Equal(t, metadata, request.Metadata)
+				assert.Empty(t, request.Timeseries)
+				continue
+			}
+			size := 0
+			for _, ts := range request.Timeseries {
+				assert.Equal(t, shard, seriesShard(ts.Labels, 4))
+				size += ts.Size()
+				seen++
+			}
+			assert.Less(t, size, m.maxBatchSizeBytes)
+		}
+	}
+	assert.Equal(t, 100, seen)
+}
+
+func TestShardManagerSend(t *testing.T) {
+	m := newShardManager(&DynamicShardingConfig{}, 4, 3000000, &shardsTelemetry{}, zap.NewNop())
+	series := newTestSeries(100)
+
+	var mu sync.Mutex
+	received := map[string]int{}
+	execute := func(_ context.Context, request *prompb.WriteRequest) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, ts := range request.Timeseries {
+			received[ts.Labels[1].Value]++
+		}
+		if len(received) == len(series) {
+			return errors.New("remote write returned HTTP status 400")
+		}
+		return nil
+	}
+
+	err := m.send(context.Background(), []*prompb.WriteRequest{{Timeseries: series}}, execute)
+	assert.ErrorContains(t, err, "remote write returned HTTP status 400")
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.Len(t, received, 100)
+	for id, count := range received {
+		assert.Equal(t, 1, count, "series %s", id)
+	}
+	assert.Positive(t, m.sendTime.Load())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, m.send(ctx, []*prompb.WriteRequest{{Timeseries: series}}, func(context.Context, *prompb.WriteRequest) error {
+		return errors.New("should not be called")
+	}))
+}
+
+func TestShardManagerUpdate(t *testing.T) {
+	telemetry := &shardsTelemetry{}
+	m := newShardManager(&DynamicShardingConfig{MinShards: 1, MaxShards: 10}, 2, 3000000, telemetry, zap.NewNop())
+	now := time.Now()
+	m.lastUpdate = now
+
+	// The shards were busy 3 seconds out of 1: 6 shards keep them busy half of the time.
+	now = now.Add(time.Second)
+	m.sendTime.Store(int64(3 * time.Second))
+	m.update(context.Background(), now)
+	assert.Equal(t, 6, m.numShards)
+	assert.Equal(t, []float64{6}, telemetry.desiredShards)
+	assert.Equal(t, []int{6}, telemetry.shards)
+	assert.Zero(t, m.sendTime.Load())
+
+	// The desired shards change within the tolerance: the moving average is 6.4.
+	now = now.Add(time.Second)
+	m.sendTime.Store(int64(4 * time.Second))
+	m.update(context.Background(), now)
+	assert.Equal(t, 6, m.numShards)
+	assert.InDelta(t, 6.4, telemetry.desiredShards[1], 1e-9)
+	assert.Len(t, telemetry.shards, 1)
+
+	// The shards can't keep up anymore, the number of shards is capped by max_shards.
+	for i := 0; i < 10; i++ {
+		now = now.Add(time.Second)
+		m.sendTime.Store(int64(20 * time.Second))
+		m.update(context.Background(), now)
+	}
+	assert.Equal(t, 10, m.numShards)
+	assert.Equal(t, 10, telemetry.shards[len(telemetry.shards)-1])
+
+	// The shards are idle, the number of shards decreases to min_shards.
+	for i := 0; i < 30; i++ {
+		now = now.Add(time.Second)
+		m.update(context.Background(), now)
+	}
+	assert.Equal(t, 1, m.numShards)
+	assert.Equal(t, 1, telemetry.shards[len(telemetry.shards)-1])
+}
+
+func TestShardManagerResharding(t *testing.T) {
+	m := newShardManager(&DynamicShardingConfig{MaxShards: 10}, 1, 3000000, &shardsTelemetry{}, zap.NewNop())
+	m.lastUpdate = time.Now()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- m.send(context.Background(), []*prompb.WriteRequest{{Timeseries: newTestSeries(1)}}, func(context.Context, *prompb.WriteRequest) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	// Resharding waits for the requests in flight to be sent.
+	resharded := make(chan struct{})
+	go func() {
+		m.sendTime.Store(int64(time.Hour))
+		m.update(context.Background(), m.lastUpdate.Add(time.Second))
+		close(resharded)
+	}()
+	select {
+	case <-resharded:
+		t.Fatal("resharded while sending requests")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	require.NoError(t, <-done)
+	<-resharded
+	assert.Equal(t, 10, m.numShards)
+}
+
+func TestPushMetricsDynamicSharding(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		data, err := snappy.Decode(nil, body)
+		assert.NoError(t, err)
+		var request prompb.WriteRequest
+		assert.NoError(t, proto.Unmarshal(data, &request))
+		mu.Lock()
+		for _, ts := range request.Timeseries {
+			received[ts.String()]++
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.ClientConfig = confighttp.NewDefaultClientConfig()
+	cfg.ClientConfig.Endpoint = server.URL
+	cfg.RemoteWriteQueue.NumConsumers = 4
+	cfg.RemoteWriteQueue.DynamicSharding = &DynamicShardingConfig{MinShards: 2, MaxShards: 8}
+	require.NoError(t, cfg.Validate())
+
+	tel := setupTestTelemetry()
+	set := tel.NewSettings()
+	set.MetricsLevel = configtelemetry.LevelBasic
+	prwe, err := newPRWExporter(cfg, set)
+	require.NoError(t, err)
+	require.NoError(t, prwe.Start(context.Background(), componenttest.NewNopHost()))
+
+	md := pmetric.NewMetrics()
+	dps := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	dps.SetName("test_gauge")
+	points := dps.SetEmptyGauge().DataPoints()
+	for i := 0; i < 100; i++ {
+		dp := points.AppendEmpty()
+		dp.Attributes().PutInt("id", int64(i))
+		dp.SetDoubleValue(float64(i))
+	}
+	require.NoError(t, prwe.PushMetrics(context.Background(), md))
+	require.NoError(t, prwe.Shutdown(context.Background()))
+
+	// target_info is not generated since the resource has no attributes.
+	assert.Len(t, received, 100)
+	for ts, count := range received {
+		assert.Equal(t, 1, count, ts)
+	}
+
+	tel.assertMetrics(t, []metricdata.Metrics{
+		{
+			Name:        "otelcol_exporter_prometheusremotewrite_shards",
+			Description: "Number of shards sending remote write requests concurrently",
+			Unit:        "{shard}",
+			Data: metricdata.Gauge[int64]{
+				DataPoints: []metricdata.DataPoint[int64]{
+					{
+						Value:      4,
+						Attributes: attribute.NewSet(attribute.String("exporter", "prometheusremotewrite")),
+					},
+				},
+			},
+		},
+		{
+			Name:        "otelcol_exporter_prometheusremotewrite_translated_time_series",
+			Description: "Number of Prometheus time series that were translated from OTel metrics",
+			Unit:        "1",
+			Data: metricdata.Sum[int64]{
+				Temporality: metricdata.CumulativeTemporality,
+				IsMonotonic: true,
+				DataPoints: []metricdata.DataPoint[int64]{
+					{
+						Value:      100,
+						Attributes: attribute.NewSet(attribute.String("exporter", "prometheusremotewrite")),
+					},
+				},
+			},
+		},
+	})
+}
@@ -17,6 +17,9 @@ type Config struct {
 	Environment string `mapstructure:"environment"`
 	// InsecureSkipVerify controls whether the client verifies the Sentry server certificate chain
 	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"`
+	// RequestSessions sends the requests handled by the services, i.e. their server spans, as sessions for the
+	// release health of Sentry, as Sentry SDKs do in server mode.
+	RequestSessions bool `mapstructure:"request_sessions"`
 }
 
 // Validate checks if the exporter configuration is valid
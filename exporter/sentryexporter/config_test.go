@@ -32,8 +32,9 @@ func TestLoadConfig(t *testing.T) {
 		{
 			id: component.NewIDWithName(metadata.Type, "2"),
 			expected: &Config{
-				DSN:         "https://key@host/path/42",
-				Environment: "prod",
+				DSN:             "https://key@host/path/42",
+				Environment:     "prod",
+				RequestSessions: true,
 			},
 		},
 	}
@@ -0,0 +1,103 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sentryexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/sentryexporter"
+
+import (
+	"github.com/getsentry/sentry-go"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	conventions "go.opentelemetry.io/collector/semconv/v1.18.0"
+)
+
+// maxBreadcrumbs is the maximum number of breadcrumbs of an event, the default of the Sentry SDKs.
+const maxBreadcrumbs = 100
+
+// exception is an exception recorded with the exception attributes of the semantic conventions, on a span
+// event or a log record.
+type exception struct {
+	Type       string
+	Message    string
+	Stacktrace string
+}
+
+// exceptionFromAttributes returns the exception described by attributes, and false if they have neither
+// an exception type nor an exception message.
+func exceptionFromAttributes(attrs pcommon.Map) (exception, bool) {
+	var exc exception
+	if v, ok := attrs.Get(conventions.AttributeExceptionType); ok {
+		exc.Type = v.AsString()
+	}
+	if v, ok := attrs.Get(conventions.AttributeExceptionMessage); ok {
+		exc.Message = v.AsString()
+	}
+	if v, ok := attrs.Get(conventions.AttributeExceptionStacktrace); ok {
+		exc.Stacktrace = v.AsString()
+	}
+	// `At least one of the following sets of attributes is required:
+	// - exception.type
+	// - exception.message`
+	return exc, exc.Type != "" || exc.Message != ""
+}
+
+// newExceptionEvent creates the Sentry error event of an exception. The events are grouped by the type of
+// their exception, so that the different messages of an exception are the same issue.
+func newExceptionEvent(exc exception) *sentry.Event {
+	event := sentry.NewEvent()
+	event.EventID = generateEventID()
+
+	event.Type = exc.Type
+	event.Message = exc.Message
+	event.Level = sentry.LevelError
+
+	sentryException := sentry.Exception{
+		Value: exc.Message,
+		Type:  exc.Type,
+	}
+	if exc.Stacktrace != "" {
+		sentryException.Stacktrace = parseStacktrace(exc.Stacktrace)
+		if sentryException.Stacktrace == nil {
+			// Keep the stack traces which can't be parsed so that they can still be read in Sentry.
+			event.Extra[conventions.AttributeExceptionStacktrace] = exc.Stacktrace
+		}
+	}
+	event.Exception = []sentry.Exception{sentryException}
+	if exc.Type != "" {
+		event.Fingerprint = []string{exc.Type}
+	}
+
+	event.Sdk.Name = otelSentryExporterName
+	event.Sdk.Version = otelSentryExporterVersion
+
+	return event
+}
+
+// breadcrumbsFromSpanEvents creates the breadcrumbs of the span events preceding an exception, keeping the
+// most recent ones when there are more than maxBreadcrumbs.
+func breadcrumbsFromSpanEvents(events ptrace.SpanEventSlice, end int) []*sentry.Breadcrumb {
+	start := max(0, end-maxBreadcrumbs)
+	if start >= end {
+		return nil
+	}
+
+	breadcrumbs := make([]*sentry.Breadcrumb, 0, end-start)
+	for i := start; i < end; i++ {
+		event := events.At(i)
+		breadcrumb := &sentry.Breadcrumb{
+			Type:      "default",
+			Category:  event.Name(),
+			Level:     sentry.LevelInfo,
+			Timestamp: unixNanoToTime(event.Timestamp()),
+		}
+		if event.Attributes().Len() > 0 {
+			breadcrumb.Data = event.Attributes().AsRaw()
+		}
+		if exc, ok := exceptionFromAttributes(event.Attributes()); ok && event.Name() == "exception" {
+			breadcrumb.Type = "error"
+			breadcrumb.Level = sentry.LevelError
+			breadcrumb.Message = exc.Message
+		}
+		breadcrumbs = append(breadcrumbs, breadcrumb)
+	}
+	return breadcrumbs
+}
@@ -0,0 +1,107 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sentryexporter
+
+import (
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	conventions "go.opentelemetry.io/collector/semconv/v1.18.0"
+)
+
+func TestExceptionFromAttributes(t *testing.T) {
+	attrs := pcommon.NewMap()
+	_, ok := exceptionFromAttributes(attrs)
+	assert.False(t, ok)
+
+	attrs.PutStr(conventions.AttributeExceptionStacktrace, "stacktrace")
+	_, ok = exceptionFromAttributes(attrs)
+	assert.False(t, ok, "an exception requires a type or a message")
+
+	attrs.PutStr(conventions.AttributeExceptionType, "ValueError")
+	exc, ok := exceptionFromAttributes(attrs)
+	assert.True(t, ok)
+	assert.Equal(t, exception{Type: "ValueError", Stacktrace: "stacktrace"}, exc)
+
+	attrs.PutStr(conventions.AttributeExceptionMessage, "invalid quantity")
+	exc, ok = exceptionFromAttributes(attrs)
+	assert.True(t, ok)
+	assert.Equal(t, exception{Type: "ValueError", Message: "invalid quantity", Stacktrace: "stacktrace"}, exc)
+}
+
+func TestNewExceptionEvent(t *testing.T) {
+	event := newExceptionEvent(exception{
+		Type:       "ValueError",
+		Message:    "invalid quantity",
+		Stacktrace: "Traceback (most recent call last):\n  File \"/app/orders.py\", line 42, in create_order\nValueError: invalid quantity",
+	})
+	assert.NotEmpty(t, event.EventID)
+	assert.Equal(t, sentry.LevelError, event.Level)
+	assert.Equal(t, "invalid quantity", event.Message)
+	assert.Equal(t, []string{"ValueError"}, event.Fingerprint)
+	require.Len(t, event.Exception, 1)
+	assert.Equal(t, "ValueError", event.Exception[0].Type)
+	assert.Equal(t, "invalid quantity", event.Exception[0].Value)
+	assert.Equal(t, &sentry.Stacktrace{Frames: []sentry.Frame{
+		{Function: "create_order", Filename: "/app/orders.py", Lineno: 42, InApp: true},
+	}}, event.Exception[0].Stacktrace)
+	assert.Empty(t, event.Extra)
+
+	event = newExceptionEvent(exception{Message: "something went wrong", Stacktrace: "somewhere"})
+	assert.Empty(t, event.Fingerprint, "the events without exception type use the grouping of Sentry")
+	assert.Nil(t, event.Exception[0].Stacktrace)
+	assert.Equal(t, map[string]any{conventions.AttributeExceptionStacktrace: "somewhere"}, event.Extra)
+}
+
+func TestBreadcrumbsFromSpanEvents(t *testing.T) {
+	events := ptrace.NewSpanEventSlice()
+	cacheMiss := events.AppendEmpty()
+	cacheMiss.SetName("cache miss")
+	cacheMiss.SetTimestamp(10)
+	cacheMiss.Attributes().PutStr("key", "order:42")
+	retry := events.AppendEmpty()
+	retry.SetName("exception")
+	retry.SetTimestamp(20)
+	retry.Attributes().PutStr(conventions.AttributeExceptionType, "TimeoutError")
+	retry.Attributes().PutStr(conventions.AttributeExceptionMessage, "query timed out")
+	events.AppendEmpty().SetName("exception")
+
+	assert.Empty(t, breadcrumbsFromSpanEvents(events, 0))
+	assert.Equal(t, []*sentry.Breadcrumb{
+		{
+			Type:      "default",
+			Category:  "cache miss",
+			Level:     sentry.LevelInfo,
+			Data:      map[string]any{"key": "order:42"},
+			Timestamp: unixNanoToTime(10),
+		},
+		{
+			Type:     "error",
+			Category: "exception",
+			Message:  "query timed out",
+			Level:    sentry.LevelError,
+			Data: map[string]any{
+				conventions.AttributeExceptionType:    "TimeoutError",
+				conventions.AttributeExceptionMessage: "query timed out",
+			},
+			Timestamp: unixNanoToTime(20),
+		},
+	}, breadcrumbsFromSpanEvents(events, 2))
+}
+
+func TestBreadcrumbsFromSpanEventsLimit(t *testing.T) {
+	events := ptrace.NewSpanEventSlice()
+	for i := 0; i < maxBreadcrumbs+10; i++ {
+		events.AppendEmpty().SetTimestamp(pcommon.Timestamp(i))
+	}
+
+	breadcrumbs := breadcrumbsFromSpanEvents(events, events.Len())
+	require.Len(t, breadcrumbs, maxBreadcrumbs)
+	assert.Equal(t, unixNanoToTime(10), breadcrumbs[0].Timestamp, "the oldest span events are dropped")
+	assert.Equal(t, unixNanoToTime(pcommon.Timestamp(maxBreadcrumbs+9)), breadcrumbs[maxBreadcrumbs-1].Timestamp)
+}
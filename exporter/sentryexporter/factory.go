@@ -11,6 +11,7 @@ import (
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/sentryexporter/internal/metadata"
 )
@@ -21,6 +22,8 @@ func NewFactory() exporter.Factory {
 		metadata.Type,
 		createDefaultConfig,
 		exporter.WithTraces(createTracesExporter, metadata.TracesStability),
+		exporter.WithLogs(createLogsExporter, metadata.LogsStability),
+		exporter.WithMetrics(createMetricsExporter, metadata.MetricsStability),
 	)
 }
 
@@ -29,7 +32,7 @@ func createDefaultConfig() component.Config {
 }
 
 func createTracesExporter(
-	_ context.Context,
+	ctx context.Context,
 	params exporter.Settings,
 	config component.Config,
 ) (exporter.Traces, error) {
@@ -39,6 +42,52 @@ func createTracesExporter(
 	}
 
 	// Create exporter based on sentry config.
-	exp, err := createSentryExporter(sentryConfig, params)
-	return exp, err
+	s := createSentryExporter(sentryConfig, params)
+	return exporterhelper.NewTraces(
+		ctx,
+		params,
+		config,
+		s.pushTraceData,
+		exporterhelper.WithShutdown(s.shutdown),
+	)
+}
+
+func createLogsExporter(
+	ctx context.Context,
+	params exporter.Settings,
+	config component.Config,
+) (exporter.Logs, error) {
+	sentryConfig, ok := config.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("unexpected config type: %T", config)
+	}
+
+	s := createSentryExporter(sentryConfig, params)
+	return exporterhelper.NewLogs(
+		ctx,
+		params,
+		config,
+		s.pushLogData,
+		exporterhelper.WithShutdown(s.shutdown),
+	)
+}
+
+func createMetricsExporter(
+	ctx context.Context,
+	params exporter.Settings,
+	config component.Config,
+) (exporter.Metrics, error) {
+	sentryConfig, ok := config.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("unexpected config type: %T", config)
+	}
+
+	s := createSentryExporter(sentryConfig, params)
+	return exporterhelper.NewMetrics(
+		ctx,
+		params,
+		config,
+		s.pushMetricData,
+		exporterhelper.WithShutdown(s.shutdown),
+	)
 }
@@ -33,7 +33,11 @@ func TestCreateExporter(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, te, "failed to create trace exporter")
 
+	le, err := factory.CreateLogs(context.Background(), params, eCfg)
+	assert.NoError(t, err)
+	assert.NotNil(t, le, "failed to create logs exporter")
+
 	me, err := factory.CreateMetrics(context.Background(), params, eCfg)
-	assert.Error(t, err)
-	assert.Nil(t, me)
+	assert.NoError(t, err)
+	assert.NotNil(t, me, "failed to create metrics exporter")
 }
@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sentryexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/sentryexporter"
+
+import (
+	"context"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.18.0"
+)
+
+// pushLogData takes incoming OpenTelemetry logs, converts the log records of exceptions into Sentry error
+// events and the log records of sessions into session updates, and sends them using Sentry's transport.
+// The other log records are dropped.
+func (s *SentryExporter) pushLogData(ctx context.Context, ld plog.Logs) error {
+	var events []*sentry.Event
+	var sessionUpdates []sessionUpdate
+
+	resourceLogs := ld.ResourceLogs()
+	for i := 0; i < resourceLogs.Len(); i++ {
+		rl := resourceLogs.At(i)
+		resourceTags := generateTagsFromResource(rl.Resource())
+		release := releaseFromResource(rl.Resource())
+		sessionAttrs := sessionAttributes{Release: release, Environment: s.environment}
+		serverName := ""
+		if hostName, ok := rl.Resource().Attributes().Get(conventions.AttributeHostName); ok {
+			serverName = hostName.AsString()
+		}
+
+		scopeLogs := rl.ScopeLogs()
+		for j := 0; j < scopeLogs.Len(); j++ {
+			sl := scopeLogs.At(j)
+
+			logRecords := sl.LogRecords()
+			for k := 0; k < logRecords.Len(); k++ {
+				logRecord := logRecords.At(k)
+				if update, ok := sessionUpdateFromLogRecord(logRecord, rl.Resource(), sessionAttrs); ok {
+					sessionUpdates = append(sessionUpdates, update)
+				}
+				exc, ok := exceptionFromAttributes(logRecord.Attributes())
+				if !ok {
+					continue
+				}
+
+				event := sentryEventFromLogRecord(logRecord, exc, resourceTags)
+				event.Logger = sl.Scope().Name()
+				event.ServerName = serverName
+				event.Environment = s.environment
+				event.Release = release
+				events = append(events, event)
+			}
+		}
+	}
+
+	// The sessions are sent first, as only their envelopes can fail and be retried.
+	if err := s.sendSessions(ctx, sessionUpdates, nil); err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	s.transport.SendEvents(events)
+
+	return nil
+}
+
+// sentryEventFromLogRecord creates a sentry event from the log record of an exception.
+func sentryEventFromLogRecord(logRecord plog.LogRecord, exc exception, resourceTags map[string]string) *sentry.Event {
+	event := newExceptionEvent(exc)
+	event.Level = levelFromSeverity(logRecord.SeverityNumber())
+	if body := logRecord.Body().AsString(); body != "" {
+		event.Message = body
+	}
+
+	timestamp := logRecord.Timestamp()
+	if timestamp == 0 {
+		timestamp = logRecord.ObservedTimestamp()
+	}
+	event.Timestamp = unixNanoToTime(timestamp)
+
+	if traceID := logRecord.TraceID(); !traceID.IsEmpty() {
+		traceContext := sentry.TraceContext{TraceID: sentry.TraceID(traceID)}
+		if spanID := logRecord.SpanID(); !spanID.IsEmpty() {
+			traceContext.SpanID = sentry.SpanID(spanID)
+		}
+		event.Contexts["trace"] = traceContext.Map()
+	}
+
+	// The exception attributes are already in the exception of the event, and the stack traces are too
+	// long for tags.
+	tags := generateTagsFromAttributes(logRecord.Attributes())
+	for k := range tags {
+		if strings.HasPrefix(k, "exception.") {
+			delete(tags, k)
+		}
+	}
+	for k, v := range resourceTags {
+		tags[k] = v
+	}
+	event.Tags = tags
+
+	return event
+}
+
+// levelFromSeverity maps the severity of a log record to a Sentry level, error when it isn't set.
+func levelFromSeverity(severity plog.SeverityNumber) sentry.Level {
+	switch {
+	case severity == plog.SeverityNumberUnspecified:
+		return sentry.LevelError
+	case severity < plog.SeverityNumberInfo:
+		return sentry.LevelDebug
+	case severity < plog.SeverityNumberWarn:
+		return sentry.LevelInfo
+	case severity < plog.SeverityNumberError:
+		return sentry.LevelWarning
+	case severity < plog.SeverityNumberFatal:
+		return sentry.LevelError
+	default:
+		return sentry.LevelFatal
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sentryexporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.18.0"
+)
+
+func TestPushLogData(t *testing.T) {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr(conventions.AttributeServiceName, "orders")
+	rl.Resource().Attributes().PutStr(conventions.AttributeServiceVersion, "1.4.2")
+	rl.Resource().Attributes().PutStr(conventions.AttributeHostName, "orders-7d9f")
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName("shop.orders")
+
+	info := sl.LogRecords().AppendEmpty()
+	info.Body().SetStr("order created")
+	info.SetSeverityNumber(plog.SeverityNumberInfo)
+
+	exceptionLog := sl.LogRecords().AppendEmpty()
+	exceptionLog.Body().SetStr("failed to create order")
+	exceptionLog.SetSeverityNumber(plog.SeverityNumberError)
+	exceptionLog.SetTimestamp(pcommon.Timestamp(1_700_000_000_000_000_000))
+	exceptionLog.SetTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 8, 7, 6, 5, 4, 3, 2, 1})
+	exceptionLog.SetSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	exceptionLog.Attributes().PutStr(conventions.AttributeExceptionType, "ValueError")
+	exceptionLog.Attributes().PutStr(conventions.AttributeExceptionMessage, "invalid quantity")
+	exceptionLog.Attributes().PutStr(conventions.AttributeExceptionStacktrace, "Traceback (most recent call last):\n  File \"/app/orders.py\", line 42, in create_order\nValueError: invalid quantity")
+	exceptionLog.Attributes().PutStr("order.id", "42")
+
+	transport := &mockTransport{}
+	s := &SentryExporter{transport: transport, environment: "production"}
+	require.NoError(t, s.pushLogData(context.Background(), ld))
+
+	require.Len(t, transport.transactions, 1)
+	event := transport.transactions[0]
+	assert.Equal(t, "failed to create order", event.Message)
+	assert.Equal(t, sentry.LevelError, event.Level)
+	assert.Equal(t, []string{"ValueError"}, event.Fingerprint)
+	require.Len(t, event.Exception, 1)
+	assert.Equal(t, "invalid quantity", event.Exception[0].Value)
+	require.NotNil(t, event.Exception[0].Stacktrace)
+	assert.Equal(t, "create_order", event.Exception[0].Stacktrace.Frames[0].Function)
+	assert.Equal(t, unixNanoToTime(exceptionLog.Timestamp()), event.Timestamp)
+	assert.Equal(t, "shop.orders", event.Logger)
+	assert.Equal(t, "orders-7d9f", event.ServerName)
+	assert.Equal(t, "production", event.Environment)
+	assert.Equal(t, "orders@1.4.2", event.Release)
+	assert.Equal(t, map[string]string{
+		"order.id":                          "42",
+		conventions.AttributeServiceName:    "orders",
+		conventions.AttributeServiceVersion: "1.4.2",
+		conventions.AttributeHostName:       "orders-7d9f",
+	}, event.Tags)
+	assert.Equal(t, sentry.TraceContext{
+		TraceID: traceIDFromHex("01020304050607080807060504030201"),
+		SpanID:  spanIDFromHex("0102030405060708"),
+	}.Map(), event.Contexts["trace"])
+}
+
+func TestPushLogDataWithoutExceptions(t *testing.T) {
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("order created")
+
+	transport := &mockTransport{}
+	s := &SentryExporter{transport: transport}
+	require.NoError(t, s.pushLogData(context.Background(), ld))
+	assert.False(t, transport.called)
+}
+
+func TestSentryEventFromLogRecordDefaults(t *testing.T) {
+	logRecord := plog.NewLogRecord()
+	logRecord.SetObservedTimestamp(pcommon.Timestamp(1_700_000_000_000_000_000))
+
+	event := sentryEventFromLogRecord(logRecord, exception{Type: "ValueError", Message: "invalid quantity"}, map[string]string{})
+	assert.Equal(t, "invalid quantity", event.Message, "the exception message is the message of the logs without body")
+	assert.Equal(t, sentry.LevelError, event.Level)
+	assert.Equal(t, unixNanoToTime(logRecord.ObservedTimestamp()), event.Timestamp)
+	assert.NotContains(t, event.Contexts, "trace")
+}
+
+func TestLevelFromSeverity(t *testing.T) {
+	tests := []struct {
+		severity plog.SeverityNumber
+		expected sentry.Level
+	}{
+		{severity: plog.SeverityNumberUnspecified, expected: sentry.LevelError},
+		{severity: plog.SeverityNumberTrace, expected: sentry.LevelDebug},
+		{severity: plog.SeverityNumberDebug4, expected: sentry.LevelDebug},
+		{severity: plog.SeverityNumberInfo, expected: sentry.LevelInfo},
+		{severity: plog.SeverityNumberWarn2, expected: sentry.LevelWarning},
+		{severity: plog.SeverityNumberError, expected: sentry.LevelError},
+		{severity: plog.SeverityNumberError4, expected: sentry.LevelError},
+		{severity: plog.SeverityNumberFatal, expected: sentry.LevelFatal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.severity.String(), func(t *testing.T) {
+			assert.Equal(t, tt.expected, levelFromSeverity(tt.severity))
+		})
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sentryexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/sentryexporter"
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+// metricType is the type of the events of the Sentry SDK holding metrics.
+const metricType = "statsd"
+
+// metricKeyRegex matches the characters of the metric names which aren't allowed in the keys of Sentry metrics.
+var metricKeyRegex = regexp.MustCompile(`[^a-zA-Z0-9_/.-]+`)
+
+// metricUnits maps the UCUM units of OpenTelemetry to the units of Sentry metrics.
+var metricUnits = map[string]sentry.MetricUnit{
+	"ns":   sentry.NanoSecond(),
+	"us":   sentry.MicroSecond(),
+	"ms":   sentry.MilliSecond(),
+	"s":    sentry.Second(),
+	"min":  sentry.Minute(),
+	"h":    sentry.Hour(),
+	"d":    sentry.Day(),
+	"bit":  sentry.Bit(),
+	"By":   sentry.Byte(),
+	"kBy":  sentry.KiloByte(),
+	"KiBy": sentry.KibiByte(),
+	"MBy":  sentry.MegaByte(),
+	"MiBy": sentry.MebiByte(),
+	"GBy":  sentry.GigaByte(),
+	"GiBy": sentry.GibiByte(),
+	"TBy":  sentry.TeraByte(),
+	"TiBy": sentry.TebiByte(),
+	"%":    sentry.Percent(),
+	"1":    sentry.CustomUnit(""),
+}
+
+// pushMetricData takes incoming OpenTelemetry metrics, converts them into Sentry metrics and sends them
+// using Sentry's transport. The metrics which can't be converted are dropped.
+func (s *SentryExporter) pushMetricData(_ context.Context, md pmetric.Metrics) error {
+	var metrics []sentry.Metric
+	dropped := 0
+
+	resourceMetrics := md.ResourceMetrics()
+	for i := 0; i < resourceMetrics.Len(); i++ {
+		rm := resourceMetrics.At(i)
+		resourceTags := generateTagsFromResource(rm.Resource())
+		if release := releaseFromResource(rm.Resource()); release != "" {
+			resourceTags["release"] = release
+		}
+		if s.environment != "" {
+			resourceTags["environment"] = s.environment
+		}
+
+		scopeMetrics := rm.ScopeMetrics()
+		for j := 0; j < scopeMetrics.Len(); j++ {
+			ms := scopeMetrics.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				converted, ok := sentryMetrics(ms.At(k), resourceTags)
+				if !ok {
+					dropped++
+					continue
+				}
+				metrics = append(metrics, converted...)
+			}
+		}
+	}
+
+	if dropped > 0 {
+		s.logger.Debug("Dropped metrics which have no Sentry metric type", zap.Int("dropped", dropped))
+	}
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	event := sentry.NewEvent()
+	event.EventID = generateEventID()
+	event.Type = metricType
+	event.Sdk.Name = otelSentryExporterName
+	event.Sdk.Version = otelSentryExporterVersion
+	event.Metrics = metrics
+	s.transport.SendEvents([]*sentry.Event{event})
+
+	return nil
+}
+
+// sentryMetrics converts the data points of a metric into Sentry metrics:
+//   - the data points of gauges and of cumulative sums which aren't monotonic into gauges,
+//   - the data points of delta sums into counters,
+//   - the data points of delta histograms and exponential histograms into gauges of their minimum, maximum,
+//     sum and count, with their mean as last value.
+//
+// It returns false for the other metrics, which can't be converted, such as cumulative monotonic sums and
+// summaries.
+func sentryMetrics(metric pmetric.Metric, resourceTags map[string]string) ([]sentry.Metric, bool) {
+	key := metricKeyRegex.ReplaceAllString(metric.Name(), "_")
+	unit := metricUnit(metric.Unit())
+	tagsOf := func(attrs pcommon.Map) map[string]string {
+		tags := generateTagsFromAttributes(attrs)
+		for k, v := range resourceTags {
+			tags[k] = v
+		}
+		return tags
+	}
+
+	var metrics []sentry.Metric
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			value := numberValue(dp)
+			metrics = append(metrics, newGaugeMetric(key, unit, tagsOf(dp.Attributes()), dp.Timestamp(), value, value, value, value, 1))
+		}
+	case pmetric.MetricTypeSum:
+		sum := metric.Sum()
+		delta := sum.AggregationTemporality() == pmetric.AggregationTemporalityDelta
+		if !delta && sum.IsMonotonic() {
+			return nil, false
+		}
+		dps := sum.DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			value := numberValue(dp)
+			if delta {
+				metrics = append(metrics, sentry.NewCounterMetric(key, unit, tagsOf(dp.Attributes()), timestampSeconds(dp.Timestamp()), value))
+			} else {
+				metrics = append(metrics, newGaugeMetric(key, unit, tagsOf(dp.Attributes()), dp.Timestamp(), value, value, value, value, 1))
+			}
+		}
+	case pmetric.MetricTypeHistogram:
+		if metric.Histogram().AggregationTemporality() != pmetric.AggregationTemporalityDelta {
+			return nil, false
+		}
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			if dp.Count() > 0 {
+				metrics = append(metrics, newHistogramGauge(key, unit, tagsOf(dp.Attributes()), dp))
+			}
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		if metric.ExponentialHistogram().AggregationTemporality() != pmetric.AggregationTemporalityDelta {
+			return nil, false
+		}
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			if dp.Count() > 0 {
+				metrics = append(metrics, newHistogramGauge(key, unit, tagsOf(dp.Attributes()), dp))
+			}
+		}
+	default:
+		return nil, false
+	}
+	return metrics, true
+}
+
+// gaugeMetric is a Sentry gauge summarizing values by their last value, minimum, maximum, sum and count.
+// The gauges of the SDK can't be created with a count, as their count is set to their value.
+type gaugeMetric struct {
+	sentry.GaugeMetric
+	last, minimum, maximum, sum float64
+	count                       uint64
+}
+
+func newGaugeMetric(key string, unit sentry.MetricUnit, tags map[string]string, timestamp pcommon.Timestamp, last, minimum, maximum, sum float64, count uint64) gaugeMetric {
+	return gaugeMetric{
+		GaugeMetric: sentry.NewGaugeMetric(key, unit, tags, timestampSeconds(timestamp), last),
+		last:        last,
+		minimum:     minimum,
+		maximum:     maximum,
+		sum:         sum,
+		count:       count,
+	}
+}
+
+func (g gaugeMetric) SerializeValue() string {
+	return fmt.Sprintf(":%v:%v:%v:%v:%v", g.last, g.minimum, g.maximum, g.sum, g.count)
+}
+
+// histogramDataPoint is a data point of a histogram or of an exponential histogram.
+type histogramDataPoint interface {
+	Timestamp() pcommon.Timestamp
+	Count() uint64
+	Sum() float64
+	HasMin() bool
+	Min() float64
+	HasMax() bool
+	Max() float64
+}
+
+// newHistogramGauge returns the gauge of a histogram data point, whose minimum and maximum default to its mean.
+func newHistogramGauge(key string, unit sentry.MetricUnit, tags map[string]string, dp histogramDataPoint) gaugeMetric {
+	mean := dp.Sum() / float64(dp.Count())
+	minimum, maximum := mean, mean
+	if dp.HasMin() {
+		minimum = dp.Min()
+	}
+	if dp.HasMax() {
+		maximum = dp.Max()
+	}
+	return newGaugeMetric(key, unit, tags, dp.Timestamp(), mean, minimum, maximum, dp.Sum(), dp.Count())
+}
+
+func numberValue(dp pmetric.NumberDataPoint) float64 {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		return float64(dp.IntValue())
+	}
+	return dp.DoubleValue()
+}
+
+// metricUnit returns the Sentry unit of a UCUM unit. The other units are kept as custom units, without the
+// braces of their annotations such as `{request}`.
+func metricUnit(unit string) sentry.MetricUnit {
+	if u, ok := metricUnits[unit]; ok {
+		return u
+	}
+	return sentry.CustomUnit(strings.ToLower(unit))
+}
+
+func timestampSeconds(timestamp pcommon.Timestamp) int64 {
+	return unixNanoToTime(timestamp).Unix()
+}
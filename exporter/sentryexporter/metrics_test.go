@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sentryexporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	conventions "go.opentelemetry.io/collector/semconv/v1.18.0"
+	"go.uber.org/zap"
+)
+
+// statsdLine serializes a Sentry metric as the Sentry SDK does in the `statsd` items.
+func statsdLine(m sentry.Metric) string {
+	line := m.GetKey()
+	if unit := m.GetUnit(); unit != "" {
+		line += "@" + unit
+	}
+	line += m.SerializeValue() + "|" + m.GetType()
+	if tags := m.SerializeTags(); tags != "" {
+		line += "|#" + tags
+	}
+	return line
+}
+
+func TestSentryMetrics(t *testing.T) {
+	tests := []struct {
+		name     string
+		metric   func(m pmetric.Metric)
+		expected []string
+	}{
+		{
+			name: "gauge",
+			metric: func(m pmetric.Metric) {
+				m.SetName("process.memory.usage")
+				m.SetUnit("By")
+				dp := m.SetEmptyGauge().DataPoints().AppendEmpty()
+				dp.SetIntValue(2048)
+				dp.Attributes().PutStr("pool", "heap")
+			},
+			expected: []string{"process.memory.usage@byte:2048:2048:2048:2048:1|g|#pool:heap,service.name:orders"},
+		},
+		{
+			name: "delta sum",
+			metric: func(m pmetric.Metric) {
+				m.SetName("http.server.requests")
+				m.SetUnit("{request}")
+				sum := m.SetEmptySum()
+				sum.SetIsMonotonic(true)
+				sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+				sum.DataPoints().AppendEmpty().SetIntValue(3)
+				sum.DataPoints().AppendEmpty().SetIntValue(4)
+			},
+			expected: []string{
+				"http.server.requests@request:3|c|#service.name:orders",
+				"http.server.requests@request:4|c|#service.name:orders",
+			},
+		},
+		{
+			name: "cumulative sum which isn't monotonic",
+			metric: func(m pmetric.Metric) {
+				m.SetName("queue size")
+				m.SetUnit("1")
+				sum := m.SetEmptySum()
+				sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+				sum.DataPoints().AppendEmpty().SetDoubleValue(1.5)
+			},
+			expected: []string{"queue_size:1.5:1.5:1.5:1.5:1|g|#service.name:orders"},
+		},
+		{
+			name: "delta histogram",
+			metric: func(m pmetric.Metric) {
+				m.SetName("http.server.duration")
+				m.SetUnit("ms")
+				histogram := m.SetEmptyHistogram()
+				histogram.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+				dp := histogram.DataPoints().AppendEmpty()
+				dp.SetCount(4)
+				dp.SetSum(100)
+				dp.SetMin(10)
+				dp.SetMax(40)
+				// Empty data points are skipped.
+				histogram.DataPoints().AppendEmpty()
+			},
+			expected: []string{"http.server.duration@millisecond:25:10:40:100:4|g|#service.name:orders"},
+		},
+		{
+			name: "delta exponential histogram without minimum and maximum",
+			metric: func(m pmetric.Metric) {
+				m.SetName("http.server.duration")
+				m.SetUnit("s")
+				histogram := m.SetEmptyExponentialHistogram()
+				histogram.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+				dp := histogram.DataPoints().AppendEmpty()
+				dp.SetCount(2)
+				dp.SetSum(3)
+			},
+			expected: []string{"http.server.duration@second:1.5:1.5:1.5:3:2|g|#service.name:orders"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := pmetric.NewMetric()
+			tt.metric(m)
+			metrics, ok := sentryMetrics(m, map[string]string{conventions.AttributeServiceName: "orders"})
+			require.True(t, ok)
+			lines := make([]string, 0, len(metrics))
+			for _, metric := range metrics {
+				lines = append(lines, statsdLine(metric))
+			}
+			assert.Equal(t, tt.expected, lines)
+		})
+	}
+}
+
+func TestSentryMetricsUnsupported(t *testing.T) {
+	cumulative := pmetric.NewMetric()
+	sum := cumulative.SetEmptySum()
+	sum.SetIsMonotonic(true)
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	sum.DataPoints().AppendEmpty().SetIntValue(3)
+
+	cumulativeHistogram := pmetric.NewMetric()
+	cumulativeHistogram.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+
+	summary := pmetric.NewMetric()
+	summary.SetEmptySummary().DataPoints().AppendEmpty()
+
+	for _, m := range []pmetric.Metric{cumulative, cumulativeHistogram, summary} {
+		_, ok := sentryMetrics(m, map[string]string{})
+		assert.False(t, ok)
+	}
+}
+
+func TestPushMetricData(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr(conventions.AttributeServiceName, "orders")
+	rm.Resource().Attributes().PutStr(conventions.AttributeServiceVersion, "1.4.2")
+	ms := rm.ScopeMetrics().AppendEmpty().Metrics()
+	gauge := ms.AppendEmpty()
+	gauge.SetName("queue.size")
+	gauge.SetEmptyGauge().DataPoints().AppendEmpty().SetTimestamp(1_700_000_000_000_000_000)
+	ms.AppendEmpty().SetEmptySummary()
+
+	transport := &mockTransport{}
+	s := &SentryExporter{transport: transport, environment: "production", logger: zap.NewNop()}
+	require.NoError(t, s.pushMetricData(context.Background(), md))
+
+	require.Len(t, transport.transactions, 1)
+	event := transport.transactions[0]
+	assert.Equal(t, "statsd", event.Type)
+	assert.Equal(t, otelSentryExporterName, event.Sdk.Name)
+	require.Len(t, event.Metrics, 1)
+	assert.Equal(t, "queue.size:0:0:0:0:1|g|#environment:production,release:orders@1.4.2,service.name:orders,service.version:1.4.2", statsdLine(event.Metrics[0]))
+	assert.Equal(t, int64(1_700_000_000), event.Metrics[0].GetTimestamp())
+}
+
+func TestPushMetricDataWithoutMetrics(t *testing.T) {
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptySummary()
+
+	transport := &mockTransport{}
+	s := &SentryExporter{transport: transport, logger: zap.NewNop()}
+	require.NoError(t, s.pushMetricData(context.Background(), md))
+	assert.False(t, transport.called)
+}
+
+func TestMetricUnit(t *testing.T) {
+	assert.Equal(t, sentry.MilliSecond(), metricUnit("ms"))
+	assert.Equal(t, sentry.CustomUnit("request"), metricUnit("{request}"))
+	assert.Equal(t, sentry.CustomUnit(""), metricUnit("1"))
+}
@@ -16,10 +16,10 @@ import (
 
 	"github.com/getsentry/sentry-go"
 	"go.opentelemetry.io/collector/exporter"
-	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	conventions "go.opentelemetry.io/collector/semconv/v1.18.0"
+	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/traceutil"
 )
@@ -70,14 +70,18 @@ var canonicalCodesGrpcMap = map[string]sentry.SpanStatus{
 
 // SentryExporter defines the Sentry Exporter.
 type SentryExporter struct {
-	transport   transport
-	environment string
+	transport       transport
+	environment     string
+	requestSessions bool
+	logger          *zap.Logger
 }
 
 // pushTraceData takes an incoming OpenTelemetry trace, converts them into Sentry spans and transactions
 // and sends them using Sentry's transport.
-func (s *SentryExporter) pushTraceData(_ context.Context, td ptrace.Traces) error {
+func (s *SentryExporter) pushTraceData(ctx context.Context, td ptrace.Traces) error {
 	var exceptionEvents []*sentry.Event
+	var sessionUpdates []sessionUpdate
+	requests := requestSessions{}
 	resourceSpans := td.ResourceSpans()
 	if resourceSpans.Len() == 0 {
 		return nil
@@ -93,6 +97,8 @@ func (s *SentryExporter) pushTraceData(_ context.Context, td ptrace.Traces) erro
 	for i := 0; i < resourceSpans.Len(); i++ {
 		rs := resourceSpans.At(i)
 		resourceTags := generateTagsFromResource(rs.Resource())
+		release := releaseFromResource(rs.Resource())
+		sessionAttrs := sessionAttributes{Release: release, Environment: s.environment}
 
 		ilss := rs.ScopeSpans()
 		for j := 0; j < ilss.Len(); j++ {
@@ -103,7 +109,11 @@ func (s *SentryExporter) pushTraceData(_ context.Context, td ptrace.Traces) erro
 			for k := 0; k < spans.Len(); k++ {
 				otelSpan := spans.At(k)
 				sentrySpan := convertToSentrySpan(otelSpan, library, resourceTags)
-				convertEventsToSentryExceptions(&exceptionEvents, otelSpan.Events(), sentrySpan)
+				convertEventsToSentryExceptions(&exceptionEvents, otelSpan.Events(), sentrySpan, s.environment, release)
+				sessionUpdates = append(sessionUpdates, sessionUpdatesFromSpan(otelSpan, rs.Resource(), sessionAttrs)...)
+				if s.requestSessions && otelSpan.Kind() == ptrace.SpanKindServer {
+					requests.add(otelSpan, sessionAttrs)
+				}
 
 				// If a span is a root span, we consider it the start of a Sentry transaction.
 				// We should then create a new transaction for that root span, and keep track of it.
@@ -125,17 +135,25 @@ func (s *SentryExporter) pushTraceData(_ context.Context, td ptrace.Traces) erro
 		}
 	}
 
-	if len(transactionMap) == 0 {
-		return nil
-	}
+	var transactions []*sentry.Event
+	if len(transactionMap) != 0 {
+		// After the first pass through, we can't necessarily make the assumption we have not associated all
+		// the spans with a transaction. As such, we must classify the remaining spans as orphans or not.
+		orphanSpans := classifyAsOrphanSpans(maybeOrphanSpans, len(maybeOrphanSpans)+1, idMap, transactionMap)
 
-	// After the first pass through, we can't necessarily make the assumption we have not associated all
-	// the spans with a transaction. As such, we must classify the remaining spans as orphans or not.
-	orphanSpans := classifyAsOrphanSpans(maybeOrphanSpans, len(maybeOrphanSpans)+1, idMap, transactionMap)
+		transactions = generateTransactions(transactionMap, orphanSpans, s.environment)
+	}
 
-	transactions := generateTransactions(transactionMap, orphanSpans, s.environment)
+	// The sessions are sent first, as only their envelopes can fail and be retried.
+	if err := s.sendSessions(ctx, sessionUpdates, requests); err != nil {
+		return err
+	}
 
+	// The exceptions are sent even when their spans aren't, as they are errors rather than performance data.
 	transactions = append(transactions, exceptionEvents...)
+	if len(transactions) == 0 {
+		return nil
+	}
 
 	s.transport.SendEvents(transactions)
 
@@ -158,43 +176,34 @@ func generateTransactions(transactionMap map[sentry.SpanID]*sentry.Event, orphan
 	return transactions
 }
 
-// convertEventsToSentryExceptions creates a set of sentry events from exception events present in spans.
+// convertEventsToSentryExceptions creates a set of sentry events from exception events present in spans,
+// with the span events preceding each exception as breadcrumbs.
 // These events are stored in a mutated eventList
-func convertEventsToSentryExceptions(eventList *[]*sentry.Event, events ptrace.SpanEventSlice, sentrySpan *sentry.Span) {
+func convertEventsToSentryExceptions(eventList *[]*sentry.Event, events ptrace.SpanEventSlice, sentrySpan *sentry.Span, environment, release string) {
 	for i := 0; i < events.Len(); i++ {
 		event := events.At(i)
 		if event.Name() != "exception" {
 			continue
 		}
-		var exceptionMessage, exceptionType string
-		event.Attributes().Range(func(k string, v pcommon.Value) bool {
-			switch k {
-			case conventions.AttributeExceptionMessage:
-				exceptionMessage = v.Str()
-			case conventions.AttributeExceptionType:
-				exceptionType = v.Str()
-			}
-			return true
-		})
-		if exceptionMessage == "" && exceptionType == "" {
-			// `At least one of the following sets of attributes is required:
-			// - exception.type
-			// - exception.message`
+		exc, ok := exceptionFromAttributes(event.Attributes())
+		if !ok {
 			continue
 		}
-		sentryEvent, _ := sentryEventFromError(exceptionMessage, exceptionType, sentrySpan)
+		sentryEvent, _ := sentryEventFromError(exc, sentrySpan)
+		sentryEvent.Breadcrumbs = breadcrumbsFromSpanEvents(events, i)
+		sentryEvent.Environment = environment
+		sentryEvent.Release = release
 		*eventList = append(*eventList, sentryEvent)
 	}
 }
 
 // sentryEventFromError creates a sentry event from error event in a span
-func sentryEventFromError(errorMessage, errorType string, span *sentry.Span) (*sentry.Event, error) {
-	if errorMessage == "" && errorType == "" {
+func sentryEventFromError(exc exception, span *sentry.Span) (*sentry.Event, error) {
+	if exc.Message == "" && exc.Type == "" {
 		err := errors.New("error type and error message were both empty")
 		return nil, err
 	}
-	event := sentry.NewEvent()
-	event.EventID = generateEventID()
+	event := newExceptionEvent(exc)
 
 	event.Contexts["trace"] = sentry.TraceContext{
 		TraceID:      span.TraceID,
@@ -205,17 +214,6 @@ func sentryEventFromError(errorMessage, errorType string, span *sentry.Span) (*s
 		Status:       span.Status,
 	}.Map()
 
-	event.Type = errorType
-	event.Message = errorMessage
-	event.Level = "error"
-	event.Exception = []sentry.Exception{{
-		Value: errorMessage,
-		Type:  errorType,
-	}}
-
-	event.Sdk.Name = otelSentryExporterName
-	event.Sdk.Version = otelSentryExporterVersion
-
 	event.StartTime = span.StartTime
 	event.Tags = span.Tags
 	event.Timestamp = span.EndTime
@@ -370,6 +368,19 @@ func generateTagsFromResource(resource pcommon.Resource) map[string]string {
 	return generateTagsFromAttributes(resource.Attributes())
 }
 
+// releaseFromResource returns the Sentry release of the service of a resource, `service.name@service.version`
+// following the Sentry naming of the releases, or an empty string when the version of the service is unknown.
+func releaseFromResource(resource pcommon.Resource) string {
+	version, ok := resource.Attributes().Get(conventions.AttributeServiceVersion)
+	if !ok || version.AsString() == "" {
+		return ""
+	}
+	if name, ok := resource.Attributes().Get(conventions.AttributeServiceName); ok && name.AsString() != "" {
+		return name.AsString() + "@" + version.AsString()
+	}
+	return version.AsString()
+}
+
 func generateTagsFromAttributes(attrs pcommon.Map) map[string]string {
 	tags := make(map[string]string)
 
@@ -480,7 +491,7 @@ func generateEventID() sentry.EventID {
 }
 
 // createSentryExporter returns a new Sentry Exporter.
-func createSentryExporter(config *Config, set exporter.Settings) (exporter.Traces, error) {
+func createSentryExporter(config *Config, set exporter.Settings) *SentryExporter {
 	transport := newSentryTransport()
 
 	clientOptions := sentry.ClientOptions{
@@ -494,24 +505,21 @@ func createSentryExporter(config *Config, set exporter.Settings) (exporter.Trace
 
 	transport.Configure(clientOptions)
 
-	s := &SentryExporter{
-		transport:   transport,
-		environment: config.Environment,
+	return &SentryExporter{
+		transport:       transport,
+		environment:     config.Environment,
+		requestSessions: config.RequestSessions,
+		logger:          set.Logger,
 	}
+}
 
-	return exporterhelper.NewTraces(
-		context.TODO(),
-		set,
-		config,
-		s.pushTraceData,
-		exporterhelper.WithShutdown(func(ctx context.Context) error {
-			allEventsFlushed := transport.Flush(ctx)
+// shutdown flushes the events which weren't sent yet.
+func (s *SentryExporter) shutdown(ctx context.Context) error {
+	allEventsFlushed := s.transport.Flush(ctx)
 
-			if !allEventsFlushed {
-				set.Logger.Warn("Could not flush all events, reached timeout")
-			}
+	if !allEventsFlushed {
+		s.logger.Warn("Could not flush all events, reached timeout")
+	}
 
-			return nil
-		}),
-	)
+	return nil
 }
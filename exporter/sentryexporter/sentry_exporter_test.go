@@ -235,6 +235,7 @@ func TestSpanEventToSentryEvent(t *testing.T) {
 					Value: errorMessage,
 					Type:  errorType,
 				}}
+				expectedSentryEventWithTypeAndMessage.Fingerprint = []string{errorType}
 				return &expectedSentryEventWithTypeAndMessage
 			}(),
 			expectedError: nil,
@@ -251,6 +252,7 @@ func TestSpanEventToSentryEvent(t *testing.T) {
 					Value: "",
 					Type:  errorType,
 				}}
+				expectedSentryEventWithType.Fingerprint = []string{errorType}
 				return &expectedSentryEventWithType
 			}(),
 			expectedError: nil,
@@ -267,7 +269,7 @@ func TestSpanEventToSentryEvent(t *testing.T) {
 
 	for _, test := range testCases {
 		t.Run(test.testName, func(t *testing.T) {
-			sentryEvent, err := sentryEventFromError(test.errorMessage, test.errorType, test.sampleSentrySpan)
+			sentryEvent, err := sentryEventFromError(exception{Type: test.errorType, Message: test.errorMessage}, test.sampleSentrySpan)
 			if sentryEvent != nil {
 				sentryEvent.EventID = test.expectedSentryEvent.EventID
 			}
@@ -654,6 +656,8 @@ func TestGenerateTransactions(t *testing.T) {
 type mockTransport struct {
 	called       bool
 	transactions []*sentry.Event
+	envelopes    [][]envelopeItem
+	err          error
 }
 
 func (t *mockTransport) SendEvents(transactions []*sentry.Event) {
@@ -661,6 +665,11 @@ func (t *mockTransport) SendEvents(transactions []*sentry.Event) {
 	t.called = true
 }
 
+func (t *mockTransport) SendEnvelope(_ context.Context, items []envelopeItem) error {
+	t.envelopes = append(t.envelopes, items)
+	return t.err
+}
+
 func (t *mockTransport) Configure(_ sentry.ClientOptions) {}
 func (t *mockTransport) Flush(_ context.Context) bool {
 	return true
@@ -712,6 +721,19 @@ func TestPushTraceData(t *testing.T) {
 			}(),
 			called: true,
 		},
+		{
+			testName: "with exception in span without transaction",
+			td: func() ptrace.Traces {
+				traces := ptrace.NewTraces()
+				span := traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+				span.SetParentSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+				event := span.Events().AppendEmpty()
+				event.SetName("exception")
+				event.Attributes().PutStr(conventions.AttributeExceptionType, "ValueError")
+				return traces
+			}(),
+			called: true,
+		},
 	}
 
 	for _, test := range testCases {
@@ -0,0 +1,245 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sentryexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/sentryexporter"
+
+import (
+	"context"
+	"crypto/sha1" // #nosec G505 -- only used to derive session IDs, as done by UUID version 5
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	conventions "go.opentelemetry.io/collector/semconv/v1.18.0"
+)
+
+// The attributes and events of the session semantic conventions, see
+// https://opentelemetry.io/docs/specs/semconv/general/session/.
+const (
+	attributeSessionID = "session.id"
+	attributeEventName = "event.name"
+	sessionStartEvent  = "session.start"
+	sessionEndEvent    = "session.end"
+)
+
+// The statuses of the Sentry sessions, see https://develop.sentry.dev/sdk/sessions/.
+const (
+	sessionStatusOK      = "ok"
+	sessionStatusExited  = "exited"
+	sessionStatusCrashed = "crashed"
+)
+
+// maxSessionItems is the maximum number of session items of an envelope accepted by Sentry.
+const maxSessionItems = 100
+
+// sessionAttributes are the attributes of the sessions of a release.
+type sessionAttributes struct {
+	Release     string `json:"release"`
+	Environment string `json:"environment,omitempty"`
+}
+
+// sessionUpdate is the payload of a `session` item, an update of a user session.
+type sessionUpdate struct {
+	SessionID  string            `json:"sid"`
+	DistinctID string            `json:"did,omitempty"`
+	Init       bool              `json:"init,omitempty"`
+	Started    time.Time         `json:"started"`
+	Timestamp  time.Time         `json:"timestamp"`
+	Status     string            `json:"status"`
+	Errors     int               `json:"errors"`
+	Attributes sessionAttributes `json:"attrs"`
+}
+
+// sessionAggregates is the payload of a `sessions` item, the number of requests handled by a release
+// in each minute, by outcome.
+type sessionAggregates struct {
+	Aggregates []sessionAggregate `json:"aggregates"`
+	Attributes sessionAttributes  `json:"attrs"`
+}
+
+type sessionAggregate struct {
+	Started time.Time `json:"started"`
+	Exited  int       `json:"exited,omitempty"`
+	Errored int       `json:"errored,omitempty"`
+}
+
+// sessionUpdateFromLogRecord returns the session update of a `session.start` or `session.end` event, or of
+// the exception of a session. The session of a log record is the `session.id` of its attributes or of its
+// resource. The exceptions of log records with a fatal severity crash their session.
+func sessionUpdateFromLogRecord(logRecord plog.LogRecord, resource pcommon.Resource, attrs sessionAttributes) (sessionUpdate, bool) {
+	id, ok := sessionID(logRecord.Attributes(), resource.Attributes())
+	if !ok || attrs.Release == "" {
+		return sessionUpdate{}, false
+	}
+	timestamp := logRecord.Timestamp()
+	if timestamp == 0 {
+		timestamp = logRecord.ObservedTimestamp()
+	}
+	update := newSessionUpdate(id, unixNanoToTime(timestamp), attrs, logRecord.Attributes(), resource.Attributes())
+
+	eventName, _ := logRecord.Attributes().Get(attributeEventName)
+	switch {
+	case eventName.Str() == sessionStartEvent:
+		update.Init = true
+	case eventName.Str() == sessionEndEvent:
+		update.Status = sessionStatusExited
+	case isException(logRecord.Attributes()):
+		update.Errors = 1
+		if logRecord.SeverityNumber() >= plog.SeverityNumberFatal {
+			update.Status = sessionStatusCrashed
+		}
+	default:
+		return sessionUpdate{}, false
+	}
+	return update, true
+}
+
+// sessionUpdatesFromSpan returns the session updates of the exceptions of a span, whose session is the
+// `session.id` of its attributes or of its resource.
+func sessionUpdatesFromSpan(span ptrace.Span, resource pcommon.Resource, attrs sessionAttributes) []sessionUpdate {
+	id, ok := sessionID(span.Attributes(), resource.Attributes())
+	if !ok || attrs.Release == "" {
+		return nil
+	}
+	var updates []sessionUpdate
+	events := span.Events()
+	for i := 0; i < events.Len(); i++ {
+		event := events.At(i)
+		if event.Name() != "exception" || !isException(event.Attributes()) {
+			continue
+		}
+		update := newSessionUpdate(id, unixNanoToTime(event.Timestamp()), attrs, span.Attributes(), resource.Attributes())
+		update.Errors = 1
+		updates = append(updates, update)
+	}
+	return updates
+}
+
+// newSessionUpdate returns an update of a session, which is started at the time of the update as the
+// exporter doesn't keep the state of the sessions. Sentry counts the sessions from their updates.
+func newSessionUpdate(id string, timestamp time.Time, attrs sessionAttributes, maps ...pcommon.Map) sessionUpdate {
+	update := sessionUpdate{
+		SessionID:  sessionUUID(id),
+		Started:    timestamp,
+		Timestamp:  timestamp,
+		Status:     sessionStatusOK,
+		Attributes: attrs,
+	}
+	for _, m := range maps {
+		if userID, ok := m.Get(conventions.AttributeEnduserID); ok && userID.AsString() != "" {
+			update.DistinctID = userID.AsString()
+			break
+		}
+	}
+	return update
+}
+
+func sessionID(maps ...pcommon.Map) (string, bool) {
+	for _, m := range maps {
+		if id, ok := m.Get(attributeSessionID); ok && id.AsString() != "" {
+			return id.AsString(), true
+		}
+	}
+	return "", false
+}
+
+func isException(attrs pcommon.Map) bool {
+	_, ok := exceptionFromAttributes(attrs)
+	return ok
+}
+
+// sessionUUID returns the ID of a session as a UUID, as required by Sentry. IDs which are not UUIDs are
+// hashed into one.
+func sessionUUID(id string) string {
+	if b, err := hex.DecodeString(strings.ReplaceAll(id, "-", "")); err == nil && len(b) == 16 {
+		return hex.EncodeToString(b)
+	}
+	sum := sha1.Sum([]byte(id)) // #nosec G401
+	b := sum[:16]
+	b[6] = b[6]&0x0F | 0x50 // set version to 5 (name-based uuid)
+	b[8] = b[8]&0x3F | 0x80 // set to IETF variant
+	return hex.EncodeToString(b)
+}
+
+// requestSessions aggregates the requests handled by the services, as Sentry SDKs do in server mode: each
+// request is a session, which is errored if the request failed.
+type requestSessions map[sessionAttributes]map[time.Time]*sessionAggregate
+
+// add records the request of a server span, which failed if its status is an error or if it recorded
+// an exception.
+func (r requestSessions) add(span ptrace.Span, attrs sessionAttributes) {
+	if attrs.Release == "" {
+		return
+	}
+	aggregates, ok := r[attrs]
+	if !ok {
+		aggregates = map[time.Time]*sessionAggregate{}
+		r[attrs] = aggregates
+	}
+	started := unixNanoToTime(span.StartTimestamp()).Truncate(time.Minute)
+	aggregate, ok := aggregates[started]
+	if !ok {
+		aggregate = &sessionAggregate{Started: started}
+		aggregates[started] = aggregate
+	}
+	if span.Status().Code() == ptrace.StatusCodeError || hasException(span.Events()) {
+		aggregate.Errored++
+	} else {
+		aggregate.Exited++
+	}
+}
+
+func hasException(events ptrace.SpanEventSlice) bool {
+	for i := 0; i < events.Len(); i++ {
+		if events.At(i).Name() == "exception" {
+			return true
+		}
+	}
+	return false
+}
+
+// envelopeItems returns a `sessions` item per release, with its aggregates sorted by time.
+func (r requestSessions) envelopeItems() []envelopeItem {
+	releases := make([]sessionAttributes, 0, len(r))
+	for attrs := range r {
+		releases = append(releases, attrs)
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].Release < releases[j].Release
+	})
+
+	items := make([]envelopeItem, 0, len(r))
+	for _, attrs := range releases {
+		payload := sessionAggregates{Attributes: attrs}
+		for _, aggregate := range r[attrs] {
+			payload.Aggregates = append(payload.Aggregates, *aggregate)
+		}
+		sort.Slice(payload.Aggregates, func(i, j int) bool {
+			return payload.Aggregates[i].Started.Before(payload.Aggregates[j].Started)
+		})
+		items = append(items, envelopeItem{Type: "sessions", Payload: payload})
+	}
+	return items
+}
+
+// sendSessions sends the session updates and the request sessions, in envelopes of at most maxSessionItems
+// session updates.
+func (s *SentryExporter) sendSessions(ctx context.Context, updates []sessionUpdate, requests requestSessions) error {
+	items := requests.envelopeItems()
+	for len(updates) > 0 || len(items) > 0 {
+		n := min(len(updates), maxSessionItems)
+		for _, update := range updates[:n] {
+			items = append(items, envelopeItem{Type: "session", Payload: update})
+		}
+		updates = updates[n:]
+		if err := s.transport.SendEnvelope(ctx, items); err != nil {
+			return err
+		}
+		items = nil
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sentryexporter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	conventions "go.opentelemetry.io/collector/semconv/v1.18.0"
+)
+
+const testSessionID = "0b8e2f6c-4d5a-4b7e-9c1f-3a2d6e8f9a0b"
+
+var testSessionAttributes = sessionAttributes{Release: "orders@1.4.2", Environment: "production"}
+
+func TestSessionUpdateFromLogRecord(t *testing.T) {
+	timestamp := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	tests := []struct {
+		name     string
+		record   func(lr plog.LogRecord)
+		attrs    sessionAttributes
+		expected *sessionUpdate
+	}{
+		{
+			name: "session start",
+			record: func(lr plog.LogRecord) {
+				lr.Attributes().PutStr(attributeEventName, sessionStartEvent)
+				lr.Attributes().PutStr(conventions.AttributeEnduserID, "user-1")
+			},
+			attrs: testSessionAttributes,
+			expected: &sessionUpdate{
+				SessionID:  "0b8e2f6c4d5a4b7e9c1f3a2d6e8f9a0b",
+				DistinctID: "user-1",
+				Init:       true,
+				Started:    timestamp,
+				Timestamp:  timestamp,
+				Status:     sessionStatusOK,
+				Attributes: testSessionAttributes,
+			},
+		},
+		{
+			name: "session end",
+			record: func(lr plog.LogRecord) {
+				lr.Attributes().PutStr(attributeEventName, sessionEndEvent)
+			},
+			attrs: testSessionAttributes,
+			expected: &sessionUpdate{
+				SessionID:  "0b8e2f6c4d5a4b7e9c1f3a2d6e8f9a0b",
+				Started:    timestamp,
+				Timestamp:  timestamp,
+				Status:     sessionStatusExited,
+				Attributes: testSessionAttributes,
+			},
+		},
+		{
+			name: "exception",
+			record: func(lr plog.LogRecord) {
+				lr.SetSeverityNumber(plog.SeverityNumberError)
+				lr.Attributes().PutStr(conventions.AttributeExceptionType, "ValueError")
+			},
+			attrs: testSessionAttributes,
+			expected: &sessionUpdate{
+				SessionID:  "0b8e2f6c4d5a4b7e9c1f3a2d6e8f9a0b",
+				Started:    timestamp,
+				Timestamp:  timestamp,
+				Status:     sessionStatusOK,
+				Errors:     1,
+				Attributes: testSessionAttributes,
+			},
+		},
+		{
+			name: "fatal exception",
+			record: func(lr plog.LogRecord) {
+				lr.SetSeverityNumber(plog.SeverityNumberFatal)
+				lr.Attributes().PutStr(conventions.AttributeExceptionMessage, "out of memory")
+			},
+			attrs: testSessionAttributes,
+			expected: &sessionUpdate{
+				SessionID:  "0b8e2f6c4d5a4b7e9c1f3a2d6e8f9a0b",
+				Started:    timestamp,
+				Timestamp:  timestamp,
+				Status:     sessionStatusCrashed,
+				Errors:     1,
+				Attributes: testSessionAttributes,
+			},
+		},
+		{
+			name: "other log record",
+			record: func(lr plog.LogRecord) {
+				lr.Body().SetStr("order created")
+			},
+			attrs: testSessionAttributes,
+		},
+		{
+			name: "without release",
+			record: func(lr plog.LogRecord) {
+				lr.Attributes().PutStr(attributeEventName, sessionStartEvent)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := pcommon.NewResource()
+			resource.Attributes().PutStr(attributeSessionID, testSessionID)
+			lr := plog.NewLogRecord()
+			lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(timestamp))
+			tt.record(lr)
+
+			update, ok := sessionUpdateFromLogRecord(lr, resource, tt.attrs)
+			if tt.expected == nil {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, *tt.expected, update)
+		})
+	}
+}
+
+func TestSessionUpdateFromLogRecordWithoutSession(t *testing.T) {
+	lr := plog.NewLogRecord()
+	lr.Attributes().PutStr(attributeEventName, sessionStartEvent)
+	_, ok := sessionUpdateFromLogRecord(lr, pcommon.NewResource(), testSessionAttributes)
+	assert.False(t, ok)
+}
+
+func TestSessionUpdatesFromSpan(t *testing.T) {
+	timestamp := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	span := ptrace.NewSpan()
+	span.Attributes().PutStr(attributeSessionID, testSessionID)
+	exception := span.Events().AppendEmpty()
+	exception.SetName("exception")
+	exception.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	exception.Attributes().PutStr(conventions.AttributeExceptionType, "ValueError")
+	span.Events().AppendEmpty().SetName("retry")
+
+	updates := sessionUpdatesFromSpan(span, pcommon.NewResource(), testSessionAttributes)
+	assert.Equal(t, []sessionUpdate{{
+		SessionID:  "0b8e2f6c4d5a4b7e9c1f3a2d6e8f9a0b",
+		Started:    timestamp,
+		Timestamp:  timestamp,
+		Status:     sessionStatusOK,
+		Errors:     1,
+		Attributes: testSessionAttributes,
+	}}, updates)
+
+	assert.Empty(t, sessionUpdatesFromSpan(span, pcommon.NewResource(), sessionAttributes{}))
+	assert.Empty(t, sessionUpdatesFromSpan(ptrace.NewSpan(), pcommon.NewResource(), testSessionAttributes))
+}
+
+func TestSessionUUID(t *testing.T) {
+	assert.Equal(t, "0b8e2f6c4d5a4b7e9c1f3a2d6e8f9a0b", sessionUUID(testSessionID))
+	assert.Equal(t, "0b8e2f6c4d5a4b7e9c1f3a2d6e8f9a0b", sessionUUID("0b8e2f6c4d5a4b7e9c1f3a2d6e8f9a0b"))
+
+	id := sessionUUID("browser-session-1")
+	assert.Len(t, id, 32)
+	assert.Equal(t, byte('5'), id[12])
+	assert.Equal(t, id, sessionUUID("browser-session-1"))
+	assert.NotEqual(t, id, sessionUUID("browser-session-2"))
+}
+
+func TestRequestSessions(t *testing.T) {
+	minute := time.Date(2023, 11, 14, 22, 13, 0, 0, time.UTC)
+	newSpan := func(start time.Time, status ptrace.StatusCode, exception bool) ptrace.Span {
+		span := ptrace.NewSpan()
+		span.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+		span.Status().SetCode(status)
+		if exception {
+			span.Events().AppendEmpty().SetName("exception")
+		}
+		return span
+	}
+	other := sessionAttributes{Release: "checkout@2.0.0"}
+
+	requests := requestSessions{}
+	requests.add(newSpan(minute.Add(time.Minute+time.Second), ptrace.StatusCodeOk, false), testSessionAttributes)
+	requests.add(newSpan(minute.Add(10*time.Second), ptrace.StatusCodeUnset, false), testSessionAttributes)
+	requests.add(newSpan(minute.Add(20*time.Second), ptrace.StatusCodeError, false), testSessionAttributes)
+	requests.add(newSpan(minute.Add(30*time.Second), ptrace.StatusCodeUnset, true), testSessionAttributes)
+	requests.add(newSpan(minute, ptrace.StatusCodeUnset, false), other)
+	requests.add(newSpan(minute, ptrace.StatusCodeUnset, false), sessionAttributes{})
+
+	assert.Equal(t, []envelopeItem{
+		{Type: "sessions", Payload: sessionAggregates{
+			Aggregates: []sessionAggregate{{Started: minute, Exited: 1}},
+			Attributes: other,
+		}},
+		{Type: "sessions", Payload: sessionAggregates{
+			Aggregates: []sessionAggregate{
+				{Started: minute, Exited: 1, Errored: 2},
+				{Started: minute.Add(time.Minute), Exited: 1},
+			},
+			Attributes: testSessionAttributes,
+		}},
+	}, requests.envelopeItems())
+}
+
+func TestSendSessions(t *testing.T) {
+	updates := make([]sessionUpdate, maxSessionItems+1)
+	requests := requestSessions{}
+	requests.add(ptrace.NewSpan(), testSessionAttributes)
+
+	transport := &mockTransport{}
+	s := &SentryExporter{transport: transport}
+	require.NoError(t, s.sendSessions(context.Background(), updates, requests))
+
+	require.Len(t, transport.envelopes, 2)
+	assert.Len(t, transport.envelopes[0], maxSessionItems+1)
+	assert.Equal(t, "sessions", transport.envelopes[0][0].Type)
+	assert.Equal(t, "session", transport.envelopes[0][1].Type)
+	assert.Len(t, transport.envelopes[1], 1)
+	assert.Equal(t, "session", transport.envelopes[1][0].Type)
+
+	transport = &mockTransport{}
+	s = &SentryExporter{transport: transport}
+	require.NoError(t, s.sendSessions(context.Background(), nil, requestSessions{}))
+	assert.Empty(t, transport.envelopes)
+}
+
+func TestSendSessionsError(t *testing.T) {
+	transport := &mockTransport{err: errors.New("unavailable")}
+	s := &SentryExporter{transport: transport}
+	err := s.sendSessions(context.Background(), make([]sessionUpdate, 2*maxSessionItems), requestSessions{})
+	assert.EqualError(t, err, "unavailable")
+	assert.Len(t, transport.envelopes, 1)
+}
@@ -0,0 +1,240 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sentryexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/sentryexporter"
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// stacktraceParser parses the frames of the stack trace of a language, ordered from the oldest call to
+// the most recent one as expected by Sentry.
+type stacktraceParser func(lines []string) []sentry.Frame
+
+// stacktraceParsers are the parsers of the stack traces recorded in the `exception.stacktrace` attribute,
+// whose format is the natural representation of the stack traces in each language.
+var stacktraceParsers = []stacktraceParser{
+	parsePythonStacktrace,
+	parseGoStacktrace,
+	parseJavaStacktrace,
+	parseDotNetStacktrace,
+	parseJavaScriptStacktrace,
+}
+
+// parseStacktrace parses a stack trace with the parser recognizing the most frames, and returns nil when
+// no parser recognizes it.
+func parseStacktrace(stacktrace string) *sentry.Stacktrace {
+	lines := strings.Split(strings.ReplaceAll(stacktrace, "\r\n", "\n"), "\n")
+
+	var frames []sentry.Frame
+	for _, parser := range stacktraceParsers {
+		if parsed := parser(lines); len(parsed) > len(frames) {
+			frames = parsed
+		}
+	}
+	if len(frames) == 0 {
+		return nil
+	}
+	return &sentry.Stacktrace{Frames: frames}
+}
+
+func reverseFrames(frames []sentry.Frame) []sentry.Frame {
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return frames
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+var (
+	pythonFrameRegexp = regexp.MustCompile(`^\s*File "(.+)", line (\d+), in (.+)$`)
+	// pythonLibraryRegexp matches the paths of the standard library and the installed packages.
+	pythonLibraryRegexp = regexp.MustCompile(`[/\\](site-packages|dist-packages|lib[/\\]python[\d.]*)[/\\]`)
+)
+
+// parsePythonStacktrace parses the frames of a traceback:
+//
+//	Traceback (most recent call last):
+//	  File "/app/main.py", line 10, in handler
+//	    process(order)
+func parsePythonStacktrace(lines []string) []sentry.Frame {
+	var frames []sentry.Frame
+	for i := 0; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "Traceback (most recent call last):") {
+			// Only the last traceback of chained exceptions is the one of the exception.
+			frames = nil
+			continue
+		}
+		match := pythonFrameRegexp.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+		frame := sentry.Frame{
+			Filename: match[1],
+			Lineno:   atoi(match[2]),
+			Function: match[3],
+			InApp:    !pythonLibraryRegexp.MatchString(match[1]),
+		}
+		// The source line of the frame follows it, with a deeper indentation.
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "    ") && !pythonFrameRegexp.MatchString(lines[i+1]) {
+			frame.ContextLine = strings.TrimSpace(lines[i+1])
+			i++
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+var (
+	goFunctionRegexp = regexp.MustCompile(`^(?:created by )?(\S+?)(?:\([^()]*\))?(?: in goroutine \d+)?$`)
+	goLocationRegexp = regexp.MustCompile(`^\t(.+\.go):(\d+)(?: \+0x[0-9a-f]+)?$`)
+)
+
+// parseGoStacktrace parses the frames of the first goroutine of a panic or of debug.Stack:
+//
+//	goroutine 1 [running]:
+//	main.handler(0x1)
+//		/app/main.go:10 +0x1d
+func parseGoStacktrace(lines []string) []sentry.Frame {
+	var frames []sentry.Frame
+	for i := 0; i+1 < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "goroutine ") && len(frames) > 0 {
+			break
+		}
+		location := goLocationRegexp.FindStringSubmatch(lines[i+1])
+		if location == nil {
+			continue
+		}
+		function := goFunctionRegexp.FindStringSubmatch(lines[i])
+		if function == nil {
+			continue
+		}
+		module, name := splitGoFunction(function[1])
+		frames = append(frames, sentry.Frame{
+			Module:   module,
+			Function: name,
+			Filename: location[1],
+			Lineno:   atoi(location[2]),
+			InApp:    !isGoLibrary(module, location[1]),
+		})
+		i++
+	}
+	return reverseFrames(frames)
+}
+
+// splitGoFunction splits a qualified function name, such as `github.com/org/pkg.(*T).Method`, into
+// its package path and its name.
+func splitGoFunction(qualified string) (string, string) {
+	lastSlash := strings.LastIndex(qualified, "/")
+	dot := strings.Index(qualified[lastSlash+1:], ".")
+	if dot < 0 {
+		return "", qualified
+	}
+	dot += lastSlash + 1
+	return qualified[:dot], qualified[dot+1:]
+}
+
+// isGoLibrary reports whether a frame is in the standard library, whose packages have no dot in their
+// first path element, or in a dependency.
+func isGoLibrary(module, filename string) bool {
+	firstElement, _, _ := strings.Cut(module, "/")
+	if !strings.Contains(firstElement, ".") && module != "main" {
+		return true
+	}
+	return strings.Contains(filename, "/pkg/mod/") || strings.Contains(filename, "/vendor/")
+}
+
+var (
+	javaFrameRegexp = regexp.MustCompile(`^\s*at (?:\S+/)?([\w$.<>]+)\.([\w$<>-]+)\((?:([\w$.-]+)(?::(\d+))?|Native Method|Unknown Source)\)$`)
+	// javaLibraryRegexp matches the classes of the Java and Kotlin runtimes.
+	javaLibraryRegexp = regexp.MustCompile(`^(java|javax|jdk|sun|com\.sun|kotlin|kotlinx)\.`)
+)
+
+// parseJavaStacktrace parses the frames of the exception of a Java or Kotlin stack trace, ignoring the
+// ones of its causes:
+//
+//	java.lang.IllegalStateException: invalid order
+//		at com.example.OrderService.process(OrderService.java:42)
+func parseJavaStacktrace(lines []string) []sentry.Frame {
+	var frames []sentry.Frame
+	for _, line := range lines {
+		if strings.HasPrefix(line, "Caused by:") {
+			break
+		}
+		match := javaFrameRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		frames = append(frames, sentry.Frame{
+			Module:   match[1],
+			Function: match[2],
+			Filename: match[3],
+			Lineno:   atoi(match[4]),
+			InApp:    !javaLibraryRegexp.MatchString(match[1]),
+		})
+	}
+	return reverseFrames(frames)
+}
+
+var (
+	// dotNetFrameRegexp matches the frames with the parameters of the methods, such as `(Order order, Int32 quantity)`,
+	// which tell them apart from the frames of Java.
+	dotNetFrameRegexp = regexp.MustCompile(`^\s*at ([^\s(]+)\.([^\s.(]+)\((?:[^\s,()]+ [^\s,()]+(?:, [^\s,()]+ [^\s,()]+)*)?\)(?: in (.+):line (\d+))?$`)
+	// dotNetLibraryRegexp matches the namespaces of the .NET runtime.
+	dotNetLibraryRegexp = regexp.MustCompile(`^(System|Microsoft)\.`)
+)
+
+// parseDotNetStacktrace parses the frames of a .NET stack trace:
+//
+//	System.InvalidOperationException: invalid order
+//	   at Shop.OrderService.Process(Order order) in /src/OrderService.cs:line 42
+func parseDotNetStacktrace(lines []string) []sentry.Frame {
+	var frames []sentry.Frame
+	for _, line := range lines {
+		match := dotNetFrameRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		frames = append(frames, sentry.Frame{
+			Module:   match[1],
+			Function: match[2],
+			Filename: match[3],
+			Lineno:   atoi(match[4]),
+			InApp:    !dotNetLibraryRegexp.MatchString(match[1]),
+		})
+	}
+	return reverseFrames(frames)
+}
+
+var javaScriptFrameRegexp = regexp.MustCompile(`^\s*at (?:(.+?) \()?((?:node:|file://|/|[A-Za-z]:\\|\.).*?):(\d+):(\d+)\)?$`)
+
+// parseJavaScriptStacktrace parses the frames of a V8 stack trace:
+//
+//	Error: invalid order
+//	    at processOrder (/app/orders.js:42:11)
+//	    at /app/server.js:10:5
+func parseJavaScriptStacktrace(lines []string) []sentry.Frame {
+	var frames []sentry.Frame
+	for _, line := range lines {
+		match := javaScriptFrameRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		frames = append(frames, sentry.Frame{
+			Function: strings.TrimPrefix(match[1], "async "),
+			Filename: match[2],
+			Lineno:   atoi(match[3]),
+			Colno:    atoi(match[4]),
+			InApp:    !strings.HasPrefix(match[2], "node:") && !strings.Contains(match[2], "node_modules"),
+		})
+	}
+	return reverseFrames(frames)
+}
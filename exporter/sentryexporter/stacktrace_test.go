@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sentryexporter
+
+import (
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStacktrace(t *testing.T) {
+	tests := []struct {
+		name       string
+		stacktrace string
+		expected   []sentry.Frame
+	}{
+		{
+			name: "python",
+			stacktrace: `Traceback (most recent call last):
+  File "/usr/lib/python3.12/site-packages/flask/app.py", line 880, in full_dispatch_request
+    rv = self.dispatch_request()
+  File "/app/orders.py", line 42, in create_order
+    raise ValueError("invalid quantity")
+ValueError: invalid quantity`,
+			expected: []sentry.Frame{
+				{Function: "full_dispatch_request", Filename: "/usr/lib/python3.12/site-packages/flask/app.py", Lineno: 880, ContextLine: "rv = self.dispatch_request()"},
+				{Function: "create_order", Filename: "/app/orders.py", Lineno: 42, ContextLine: `raise ValueError("invalid quantity")`, InApp: true},
+			},
+		},
+		{
+			name: "python chained exceptions",
+			stacktrace: `Traceback (most recent call last):
+  File "/app/db.py", line 10, in query
+    cursor.execute(sql)
+sqlite3.OperationalError: database is locked
+
+During handling of the above exception, another exception occurred:
+
+Traceback (most recent call last):
+  File "/app/orders.py", line 20, in load_order
+    return query(sql)
+RuntimeError: order unavailable`,
+			expected: []sentry.Frame{
+				{Function: "load_order", Filename: "/app/orders.py", Lineno: 20, ContextLine: "return query(sql)", InApp: true},
+			},
+		},
+		{
+			name: "go",
+			stacktrace: `goroutine 7 [running]:
+runtime/debug.Stack()
+	/usr/local/go/src/runtime/debug/stack.go:26 +0x5e
+github.com/example/shop/orders.(*Service).Create(0xc000010000, {0x0, 0x0})
+	/src/orders/service.go:42 +0x1d
+main.main()
+	/src/main.go:10 +0x25
+
+goroutine 1 [chan receive]:
+main.wait()
+	/src/main.go:20 +0x10`,
+			expected: []sentry.Frame{
+				{Module: "main", Function: "main", Filename: "/src/main.go", Lineno: 10, InApp: true},
+				{Module: "github.com/example/shop/orders", Function: "(*Service).Create", Filename: "/src/orders/service.go", Lineno: 42, InApp: true},
+				{Module: "runtime/debug", Function: "Stack", Filename: "/usr/local/go/src/runtime/debug/stack.go", Lineno: 26},
+			},
+		},
+		{
+			name: "java",
+			stacktrace: `java.lang.IllegalStateException: invalid order
+	at com.example.shop.OrderService.create(OrderService.java:42)
+	at com.example.shop.OrderController$Handler.lambda$handle$0(OrderController.kt)
+	at java.base/java.lang.Thread.run(Thread.java:829)
+Caused by: java.sql.SQLException: connection closed
+	at com.example.shop.Database.query(Database.java:10)
+	... 3 more`,
+			expected: []sentry.Frame{
+				{Module: "java.lang.Thread", Function: "run", Filename: "Thread.java", Lineno: 829},
+				{Module: "com.example.shop.OrderController$Handler", Function: "lambda$handle$0", Filename: "OrderController.kt", InApp: true},
+				{Module: "com.example.shop.OrderService", Function: "create", Filename: "OrderService.java", Lineno: 42, InApp: true},
+			},
+		},
+		{
+			name: "dotnet",
+			stacktrace: `System.InvalidOperationException: invalid order
+   at Shop.Orders.OrderService.Create(Order order, Int32 quantity) in /src/Orders/OrderService.cs:line 42
+   at System.Threading.Tasks.Task.Execute()`,
+			expected: []sentry.Frame{
+				{Module: "System.Threading.Tasks.Task", Function: "Execute"},
+				{Module: "Shop.Orders.OrderService", Function: "Create", Filename: "/src/Orders/OrderService.cs", Lineno: 42, InApp: true},
+			},
+		},
+		{
+			name: "javascript",
+			stacktrace: `Error: invalid order
+    at OrderService.create (/app/src/orders.js:42:11)
+    at /app/node_modules/express/lib/router/layer.js:95:5
+    at async Promise.all (index 0)
+    at process.processTicksAndRejections (node:internal/process/task_queues:95:5)`,
+			expected: []sentry.Frame{
+				{Function: "process.processTicksAndRejections", Filename: "node:internal/process/task_queues", Lineno: 95, Colno: 5},
+				{Filename: "/app/node_modules/express/lib/router/layer.js", Lineno: 95, Colno: 5},
+				{Function: "OrderService.create", Filename: "/app/src/orders.js", Lineno: 42, Colno: 11, InApp: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stacktrace := parseStacktrace(tt.stacktrace)
+			if assert.NotNil(t, stacktrace) {
+				assert.Equal(t, tt.expected, stacktrace.Frames)
+			}
+		})
+	}
+}
+
+func TestParseStacktraceUnknownFormat(t *testing.T) {
+	assert.Nil(t, parseStacktrace(""))
+	assert.Nil(t, parseStacktrace("something went wrong\nsomewhere"))
+}
@@ -4,21 +4,40 @@
 package sentryexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/sentryexporter"
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
 	"time"
 
 	"github.com/getsentry/sentry-go"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 )
 
+// sentryAPIVersion is the version of the Sentry protocol of the envelopes.
+const sentryAPIVersion = "7"
+
 // transport is used by exporter to send events to Sentry
 type transport interface {
 	SendEvents(events []*sentry.Event)
+	// SendEnvelope sends the items the Sentry SDK has no events for, such as sessions, in an envelope.
+	SendEnvelope(ctx context.Context, items []envelopeItem) error
 	Configure(options sentry.ClientOptions)
 	Flush(ctx context.Context) bool
 }
 
+// envelopeItem is an item of a Sentry envelope, whose payload is encoded as JSON.
+type envelopeItem struct {
+	Type    string
+	Payload any
+}
+
 type sentryTransport struct {
 	httpTransport *sentry.HTTPTransport
+	dsn           *sentry.Dsn
+	client        *http.Client
 }
 
 // newSentryTransport returns a new pre-configured instance of sentryTransport.
@@ -31,6 +50,12 @@ func newSentryTransport() *sentryTransport {
 
 func (t *sentryTransport) Configure(options sentry.ClientOptions) {
 	t.httpTransport.Configure(options)
+
+	// As for the events, nothing is sent without a valid DSN.
+	if dsn, err := sentry.NewDsn(options.Dsn); err == nil {
+		t.dsn = dsn
+	}
+	t.client = &http.Client{Transport: options.HTTPTransport, Timeout: t.httpTransport.Timeout}
 }
 
 func (t *sentryTransport) Flush(ctx context.Context) bool {
@@ -56,3 +81,86 @@ func (t *sentryTransport) SendEvents(transactions []*sentry.Event) {
 		bufferCounter++
 	}
 }
+
+// SendEnvelope posts the items in an envelope to the envelope endpoint of the Sentry project. Unlike the
+// events, the envelope is sent synchronously, so that the exporter can retry it.
+func (t *sentryTransport) SendEnvelope(ctx context.Context, items []envelopeItem) error {
+	if t.dsn == nil || len(items) == 0 {
+		return nil
+	}
+	body, err := encodeEnvelope(t.dsn, time.Now(), items)
+	if err != nil {
+		return consumererror.NewPermanent(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.dsn.GetAPIURL().String(), bytes.NewReader(body))
+	if err != nil {
+		return consumererror.NewPermanent(err)
+	}
+	auth := fmt.Sprintf("Sentry sentry_version=%s, sentry_client=%s/%s, sentry_key=%s",
+		sentryAPIVersion, otelSentryExporterName, otelSentryExporterVersion, t.dsn.GetPublicKey())
+	if secret := t.dsn.GetSecretKey(); secret != "" {
+		auth += ", sentry_secret=" + secret
+	}
+	req.Header.Set("X-Sentry-Auth", auth)
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("User-Agent", otelSentryExporterName+"/"+otelSentryExporterVersion)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode < http.StatusBadRequest:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("failed to send envelope to Sentry: %s", resp.Status)
+	default:
+		return consumererror.NewPermanent(fmt.Errorf("failed to send envelope to Sentry: %s", resp.Status))
+	}
+}
+
+// encodeEnvelope encodes the items in an envelope: a header followed by the items, each of them a header
+// and its payload, on separate lines.
+func encodeEnvelope(dsn *sentry.Dsn, sentAt time.Time, items []envelopeItem) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	err := enc.Encode(struct {
+		SentAt time.Time         `json:"sent_at"`
+		Dsn    string            `json:"dsn"`
+		Sdk    map[string]string `json:"sdk"`
+	}{
+		SentAt: sentAt,
+		Dsn:    dsn.String(),
+		Sdk: map[string]string{
+			"name":    otelSentryExporterName,
+			"version": otelSentryExporterVersion,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		payload, err := json.Marshal(item.Payload)
+		if err != nil {
+			return nil, err
+		}
+		err = enc.Encode(struct {
+			Type   string `json:"type"`
+			Length int    `json:"length"`
+		}{
+			Type:   item.Type,
+			Length: len(payload),
+		})
+		if err != nil {
+			return nil, err
+		}
+		b.Write(payload)
+		b.WriteByte('\n')
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,335 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sentryexporter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	conventions "go.opentelemetry.io/collector/semconv/v1.18.0"
+)
+
+// envelopeServer mocks the envelope endpoint of a Sentry project, recording the payloads of the items
+// of the envelopes by item type.
+type envelopeServer struct {
+	*httptest.Server
+	t *testing.T
+
+	mu    sync.Mutex
+	items map[string][][]byte
+}
+
+func newEnvelopeServer(t *testing.T) *envelopeServer {
+	s := &envelopeServer{t: t, items: map[string][][]byte{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// dsn returns the DSN of the project 42 of the server.
+func (s *envelopeServer) dsn() string {
+	return strings.Replace(s.URL, "http://", "http://public@", 1) + "/42"
+}
+
+func (s *envelopeServer) handle(w http.ResponseWriter, r *http.Request) {
+	assert.Equal(s.t, "/api/42/envelope/", r.URL.Path)
+	assert.Equal(s.t, "application/x-sentry-envelope", r.Header.Get("Content-Type"))
+	assert.Contains(s.t, r.Header.Get("X-Sentry-Auth"), "sentry_key=public")
+
+	// An envelope is a header followed by items, each of them a header and a payload, on separate lines.
+	// The payloads span several lines when their header has a length.
+	reader := bufio.NewReader(r.Body)
+	_, err := reader.ReadBytes('\n')
+	require.NoError(s.t, err, "missing envelope header")
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(line) == 0 {
+			break
+		}
+		require.NoError(s.t, err)
+		var header struct {
+			Type   string `json:"type"`
+			Length int    `json:"length"`
+		}
+		require.NoError(s.t, json.Unmarshal(line, &header))
+
+		var payload []byte
+		if header.Length > 0 {
+			payload = make([]byte, header.Length)
+			_, err = io.ReadFull(reader, payload)
+			require.NoError(s.t, err, "truncated item payload")
+			_, _ = reader.ReadByte()
+		} else {
+			payload, err = reader.ReadBytes('\n')
+			require.NoError(s.t, err, "missing item payload")
+		}
+
+		s.mu.Lock()
+		s.items[header.Type] = append(s.items[header.Type], bytes.TrimSuffix(payload, []byte("\n")))
+		s.mu.Unlock()
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *envelopeServer) payloads(itemType string) [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.items[itemType]
+}
+
+// received returns the events of the items of a type.
+func (s *envelopeServer) received(itemType string) []*sentry.Event {
+	var events []*sentry.Event
+	for _, payload := range s.payloads(itemType) {
+		var event sentry.Event
+		require.NoError(s.t, json.Unmarshal(payload, &event))
+		events = append(events, &event)
+	}
+	return events
+}
+
+func TestEnvelopeEndpointTraces(t *testing.T) {
+	server := newEnvelopeServer(t)
+
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr(conventions.AttributeServiceName, "orders")
+	rs.Resource().Attributes().PutStr(conventions.AttributeServiceVersion, "1.4.2")
+	span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName("POST /orders")
+	span.SetTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 8, 7, 6, 5, 4, 3, 2, 1})
+	span.SetSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	span.SetStartTimestamp(1_700_000_000_000_000_000)
+	span.SetEndTimestamp(1_700_000_001_000_000_000)
+	cacheMiss := span.Events().AppendEmpty()
+	cacheMiss.SetName("cache miss")
+	cacheMiss.SetTimestamp(1_700_000_000_100_000_000)
+	exceptionEvent := span.Events().AppendEmpty()
+	exceptionEvent.SetName("exception")
+	exceptionEvent.SetTimestamp(1_700_000_000_200_000_000)
+	exceptionEvent.Attributes().PutStr(conventions.AttributeExceptionType, "*errors.errorString")
+	exceptionEvent.Attributes().PutStr(conventions.AttributeExceptionMessage, "invalid quantity")
+	exceptionEvent.Attributes().PutStr(conventions.AttributeExceptionStacktrace, `goroutine 7 [running]:
+github.com/example/shop/orders.(*Service).Create(0xc000010000)
+	/src/orders/service.go:42 +0x1d
+main.main()
+	/src/main.go:10 +0x25`)
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DSN = server.dsn()
+	cfg.Environment = "staging"
+	exp, err := factory.CreateTraces(context.Background(), exportertest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, exp.ConsumeTraces(context.Background(), td))
+	require.NoError(t, exp.Shutdown(context.Background()))
+
+	transactions := server.received("transaction")
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "POST /orders", transactions[0].Transaction)
+
+	events := server.received("event")
+	require.Len(t, events, 1)
+	event := events[0]
+	assert.Equal(t, sentry.LevelError, event.Level)
+	assert.Equal(t, "staging", event.Environment)
+	assert.Equal(t, "orders@1.4.2", event.Release)
+	assert.Equal(t, []string{"*errors.errorString"}, event.Fingerprint)
+	require.Len(t, event.Exception, 1)
+	assert.Equal(t, "invalid quantity", event.Exception[0].Value)
+	require.NotNil(t, event.Exception[0].Stacktrace)
+	assert.Equal(t, []sentry.Frame{
+		{Module: "main", Function: "main", Filename: "/src/main.go", Lineno: 10, InApp: true},
+		{Module: "github.com/example/shop/orders", Function: "(*Service).Create", Filename: "/src/orders/service.go", Lineno: 42, InApp: true},
+	}, event.Exception[0].Stacktrace.Frames)
+	require.Len(t, event.Breadcrumbs, 1)
+	assert.Equal(t, "cache miss", event.Breadcrumbs[0].Category)
+	assert.Equal(t, "0102030405060708", event.Contexts["trace"]["span_id"])
+}
+
+func TestEnvelopeEndpointLogs(t *testing.T) {
+	server := newEnvelopeServer(t)
+
+	ld := plog.NewLogs()
+	logRecords := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	logRecords.AppendEmpty().Body().SetStr("order created")
+	exceptionLog := logRecords.AppendEmpty()
+	exceptionLog.Body().SetStr("failed to create order")
+	exceptionLog.SetSeverityNumber(plog.SeverityNumberFatal)
+	exceptionLog.SetTimestamp(1_700_000_000_000_000_000)
+	exceptionLog.Attributes().PutStr(conventions.AttributeExceptionType, "java.lang.IllegalStateException")
+	exceptionLog.Attributes().PutStr(conventions.AttributeExceptionMessage, "invalid quantity")
+	exceptionLog.Attributes().PutStr(conventions.AttributeExceptionStacktrace, `java.lang.IllegalStateException: invalid quantity
+	at com.example.shop.OrderService.create(OrderService.java:42)
+	at java.base/java.lang.Thread.run(Thread.java:829)`)
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DSN = server.dsn()
+	exp, err := factory.CreateLogs(context.Background(), exportertest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, exp.ConsumeLogs(context.Background(), ld))
+	require.NoError(t, exp.Shutdown(context.Background()))
+
+	events := server.received("event")
+	require.Len(t, events, 1)
+	event := events[0]
+	assert.Equal(t, "failed to create order", event.Message)
+	assert.Equal(t, sentry.LevelFatal, event.Level)
+	assert.Equal(t, []string{"java.lang.IllegalStateException"}, event.Fingerprint)
+	require.Len(t, event.Exception, 1)
+	require.NotNil(t, event.Exception[0].Stacktrace)
+	assert.Equal(t, []sentry.Frame{
+		{Module: "java.lang.Thread", Function: "run", Filename: "Thread.java", Lineno: 829},
+		{Module: "com.example.shop.OrderService", Function: "create", Filename: "OrderService.java", Lineno: 42, InApp: true},
+	}, event.Exception[0].Stacktrace.Frames)
+	assert.Empty(t, server.received("transaction"))
+}
+
+func TestEnvelopeEndpointSessions(t *testing.T) {
+	server := newEnvelopeServer(t)
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DSN = server.dsn()
+	cfg.Environment = "staging"
+	cfg.RequestSessions = true
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr(conventions.AttributeServiceName, "shop-app")
+	rl.Resource().Attributes().PutStr(conventions.AttributeServiceVersion, "2.0.1")
+	sessionStart := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	sessionStart.SetTimestamp(1_700_000_000_000_000_000)
+	sessionStart.Attributes().PutStr("event.name", "session.start")
+	sessionStart.Attributes().PutStr("session.id", "0b4f2f6c-5a3e-4bb5-9a0e-2f1f5e8f3c11")
+
+	logs, err := factory.CreateLogs(context.Background(), exportertest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, logs.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, logs.ConsumeLogs(context.Background(), ld))
+	require.NoError(t, logs.Shutdown(context.Background()))
+
+	updates := server.payloads("session")
+	require.Len(t, updates, 1)
+	assert.JSONEq(t, `{
+		"sid": "0b4f2f6c5a3e4bb59a0e2f1f5e8f3c11",
+		"init": true,
+		"started": "2023-11-14T22:13:20Z",
+		"timestamp": "2023-11-14T22:13:20Z",
+		"status": "ok",
+		"errors": 0,
+		"attrs": {"release": "shop-app@2.0.1", "environment": "staging"}
+	}`, string(updates[0]))
+
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr(conventions.AttributeServiceName, "orders")
+	rs.Resource().Attributes().PutStr(conventions.AttributeServiceVersion, "1.4.2")
+	spans := rs.ScopeSpans().AppendEmpty().Spans()
+	for i, code := range []ptrace.StatusCode{ptrace.StatusCodeOk, ptrace.StatusCodeUnset, ptrace.StatusCodeError} {
+		span := spans.AppendEmpty()
+		span.SetName("POST /orders")
+		span.SetKind(ptrace.SpanKindServer)
+		span.SetTraceID([16]byte{1, byte(i)})
+		span.SetSpanID([8]byte{1, byte(i)})
+		span.SetStartTimestamp(1_700_000_000_000_000_000)
+		span.SetEndTimestamp(1_700_000_001_000_000_000)
+		span.Status().SetCode(code)
+	}
+
+	traces, err := factory.CreateTraces(context.Background(), exportertest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, traces.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, traces.ConsumeTraces(context.Background(), td))
+	require.NoError(t, traces.Shutdown(context.Background()))
+
+	aggregates := server.payloads("sessions")
+	require.Len(t, aggregates, 1)
+	assert.JSONEq(t, `{
+		"aggregates": [{"started": "2023-11-14T22:13:00Z", "exited": 2, "errored": 1}],
+		"attrs": {"release": "orders@1.4.2", "environment": "staging"}
+	}`, string(aggregates[0]))
+	assert.Len(t, server.received("transaction"), 3)
+}
+
+func TestEnvelopeEndpointMetrics(t *testing.T) {
+	server := newEnvelopeServer(t)
+
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr(conventions.AttributeServiceName, "orders")
+	ms := rm.ScopeMetrics().AppendEmpty().Metrics()
+	requests := ms.AppendEmpty()
+	requests.SetName("http.server.requests")
+	requests.SetUnit("{request}")
+	requests.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	dp := requests.Sum().DataPoints().AppendEmpty()
+	dp.SetTimestamp(1_700_000_000_000_000_000)
+	dp.SetIntValue(12)
+	dp.Attributes().PutStr("http.route", "/orders")
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.DSN = server.dsn()
+	exp, err := factory.CreateMetrics(context.Background(), exportertest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, exp.ConsumeMetrics(context.Background(), md))
+	require.NoError(t, exp.Shutdown(context.Background()))
+
+	payloads := server.payloads("statsd")
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "http.server.requests@request:12|c|#http.route:/orders,service.name:orders|T1700000000", string(payloads[0]))
+}
+
+func TestSendEnvelopeErrors(t *testing.T) {
+	tests := []struct {
+		status    int
+		permanent bool
+	}{
+		{status: http.StatusTooManyRequests},
+		{status: http.StatusServiceUnavailable},
+		{status: http.StatusBadRequest, permanent: true},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			transport := newSentryTransport()
+			transport.Configure(sentry.ClientOptions{Dsn: strings.Replace(server.URL, "http://", "http://public@", 1) + "/42"})
+			err := transport.SendEnvelope(context.Background(), []envelopeItem{{Type: "session", Payload: sessionUpdate{}}})
+			require.Error(t, err)
+			assert.Equal(t, tt.permanent, consumererror.IsPermanent(err))
+		})
+	}
+}
+
+func TestSendEnvelopeWithoutDSN(t *testing.T) {
+	transport := newSentryTransport()
+	transport.Configure(sentry.ClientOptions{})
+	assert.NoError(t, transport.SendEnvelope(context.Background(), []envelopeItem{{Type: "session", Payload: sessionUpdate{}}}))
+}
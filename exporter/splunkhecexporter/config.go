@@ -10,6 +10,7 @@ import (
 	"path"
 	"time"
 
+	"github.com/google/uuid"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/config/configopaque"
 	"go.opentelemetry.io/collector/config/configretry"
@@ -63,6 +64,25 @@ type HecTelemetry struct {
 	ExtraAttributes map[string]string `mapstructure:"extra_attributes"`
 }
 
+// HecIndexerAck defines the indexer acknowledgment configuration for the exporter
+type HecIndexerAck struct {
+	// Enabled makes the exporter wait for the events to be indexed before reporting a batch as sent.
+	// The HEC token must have indexer acknowledgment enabled.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Channel is the GUID of the channel the events are sent on. A random channel is used if not set.
+	Channel string `mapstructure:"channel"`
+
+	// Path for the ack API, default is '/services/collector/ack'
+	Path string `mapstructure:"path"`
+
+	// PollInterval is the interval at which the ack API is queried. Defaults to 1s.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+
+	// Timeout is the time to wait for a batch to be acknowledged before sending it again. Defaults to 1m.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
 // Config defines configuration for Splunk exporter.
 type Config struct {
 	confighttp.ClientConfig   `mapstructure:",squash"`
@@ -143,6 +163,9 @@ type Config struct {
 
 	// Telemetry is the configuration for splunk hec exporter telemetry
 	Telemetry HecTelemetry `mapstructure:"telemetry"`
+
+	// IndexerAck is the configuration to wait for the indexer acknowledgment of the sent events
+	IndexerAck HecIndexerAck `mapstructure:"indexer_ack"`
 }
 
 func (cfg *Config) getURL() (out *url.URL, err error) {
@@ -189,5 +212,19 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf(`requires "max_event_size" <= %d`, maxMaxEventSize)
 	}
 
+	if cfg.IndexerAck.Enabled {
+		if cfg.IndexerAck.Channel != "" {
+			if _, err := uuid.Parse(cfg.IndexerAck.Channel); err != nil {
+				return fmt.Errorf(`invalid "indexer_ack.channel": %w`, err)
+			}
+		}
+		if cfg.IndexerAck.PollInterval <= 0 {
+			return errors.New(`requires "indexer_ack.poll_interval" > 0`)
+		}
+		if cfg.IndexerAck.Timeout <= 0 {
+			return errors.New(`requires "indexer_ack.timeout" > 0`)
+		}
+	}
+
 	return nil
 }
@@ -133,6 +133,13 @@ func TestLoadConfig(t *testing.T) {
 						"customKey": "customVal",
 					},
 				},
+				IndexerAck: HecIndexerAck{
+					Enabled:      true,
+					Channel:      "e1e7ae1c-3c4a-4b2f-b7a5-6c6d1d6d8a0f",
+					Path:         "/services/collector/ack",
+					PollInterval: 5 * time.Second,
+					Timeout:      time.Minute,
+				},
 			},
 		},
 	}
@@ -238,6 +245,30 @@ func TestConfig_Validate(t *testing.T) {
 			}(),
 			wantErr: "queue size must be positive",
 		},
+		{
+			name: "invalid indexer ack channel",
+			cfg: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.ClientConfig.Endpoint = "http://foo_bar.com"
+				cfg.Token = "foo"
+				cfg.IndexerAck.Enabled = true
+				cfg.IndexerAck.Channel = "foo"
+				return cfg
+			}(),
+			wantErr: "invalid \"indexer_ack.channel\": invalid UUID length: 3",
+		},
+		{
+			name: "invalid indexer ack timeout",
+			cfg: func() *Config {
+				cfg := createDefaultConfig().(*Config)
+				cfg.ClientConfig.Endpoint = "http://foo_bar.com"
+				cfg.Token = "foo"
+				cfg.IndexerAck.Enabled = true
+				cfg.IndexerAck.Timeout = 0
+				return cfg
+			}(),
+			wantErr: "requires \"indexer_ack.timeout\" > 0",
+		},
 	}
 
 	for _, tt := range tests {
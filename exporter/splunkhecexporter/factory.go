@@ -28,6 +28,8 @@ const (
 	defaultHTTP2PingTimeout     = time.Second * 10
 	defaultIdleConnTimeout      = 10 * time.Second
 	defaultSplunkAppName        = "OpenTelemetry Collector Contrib"
+	defaultAckPollInterval      = time.Second
+	defaultAckTimeout           = time.Minute
 )
 
 // TODO: Find a place for this to be shared.
@@ -110,6 +112,12 @@ func createDefaultConfig() component.Config {
 			OverrideMetricsNames: map[string]string{},
 			ExtraAttributes:      map[string]string{},
 		},
+		IndexerAck: HecIndexerAck{
+			Enabled:      false,
+			Path:         splunk.DefaultAckPath,
+			PollInterval: defaultAckPollInterval,
+			Timeout:      defaultAckTimeout,
+		},
 	}
 }
 
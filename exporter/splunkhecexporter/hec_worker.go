@@ -25,6 +25,8 @@ type defaultHecWorker struct {
 	client  *http.Client
 	headers map[string]string
 	logger  *zap.Logger
+	// acker waits for the indexer acknowledgment of the sent batches, if enabled.
+	acker *indexerAcker
 }
 
 func (hec *defaultHecWorker) send(ctx context.Context, buf buffer, headers map[string]string) error {
@@ -48,6 +50,10 @@ func (hec *defaultHecWorker) send(ctx context.Context, buf buffer, headers map[s
 		req.Header.Set(k, v)
 	}
 
+	if hec.acker != nil {
+		req.Header.Set(splunk.HTTPSplunkChannelHeader, hec.acker.channel)
+	}
+
 	if _, ok := buf.(*cancellableGzipWriter); ok {
 		req.Header.Set("Content-Encoding", "gzip")
 	}
@@ -67,6 +73,17 @@ func (hec *defaultHecWorker) send(ctx context.Context, buf buffer, headers map[s
 		return err
 	}
 
+	if hec.acker != nil {
+		ackID, errAck := readAckID(resp.Body)
+		if errAck != nil {
+			return errAck
+		}
+		if _, errCopy := io.Copy(io.Discard, resp.Body); errCopy != nil {
+			return errCopy
+		}
+		return hec.acker.waitForAck(ctx, ackID, hec.ackHeaders(headers))
+	}
+
 	// Do not drain the response when 429 or 502 status code is returned.
 	// HTTP client will not reuse the same connection unless it is drained.
 	// See https://github.com/open-telemetry/opentelemetry-collector-contrib/issues/18281 for more details.
@@ -78,4 +95,16 @@ func (hec *defaultHecWorker) send(ctx context.Context, buf buffer, headers map[s
 	return nil
 }
 
+// ackHeaders returns the headers of the ack API requests, so that they use the same token as the sent batch.
+func (hec *defaultHecWorker) ackHeaders(headers map[string]string) map[string]string {
+	ackHeaders := make(map[string]string, len(hec.headers)+len(headers))
+	for k, v := range hec.headers {
+		ackHeaders[k] = v
+	}
+	for k, v := range headers {
+		ackHeaders[k] = v
+	}
+	return ackHeaders
+}
+
 var _ hecWorker = &defaultHecWorker{}
@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package splunkhecexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/splunkhecexporter"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+var errMissingAckID = errors.New("response has no ackId, indexer acknowledgment must be enabled on the HEC token")
+
+// sendResponse is the response of HEC to a batch of events.
+type sendResponse struct {
+	AckID *uint64 `json:"ackId"`
+}
+
+// ackResponse is the response of the HEC ack API, keyed by ackId.
+type ackResponse struct {
+	Acks map[string]bool `json:"acks"`
+}
+
+// indexerAcker waits for the batches sent on a HEC channel to be indexed, by polling the ack API.
+type indexerAcker struct {
+	url          *url.URL
+	client       *http.Client
+	channel      string
+	pollInterval time.Duration
+	timeout      time.Duration
+	logger       *zap.Logger
+}
+
+func newIndexerAcker(cfg *Config, client *http.Client, logger *zap.Logger) *indexerAcker {
+	ackURL, _ := cfg.getURL()
+	ackURL.Path = cfg.IndexerAck.Path
+	channel := cfg.IndexerAck.Channel
+	if channel == "" {
+		channel = uuid.NewString()
+	}
+	return &indexerAcker{
+		url:          ackURL,
+		client:       client,
+		channel:      channel,
+		pollInterval: cfg.IndexerAck.PollInterval,
+		timeout:      cfg.IndexerAck.Timeout,
+		logger:       logger,
+	}
+}
+
+// readAckID reads the ackId HEC assigned to a batch of events from its response.
+func readAckID(body io.Reader) (uint64, error) {
+	var resp sendResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return 0, fmt.Errorf("failed to read the ackId: %w", err)
+	}
+	if resp.AckID == nil {
+		return 0, errMissingAckID
+	}
+	return *resp.AckID, nil
+}
+
+// waitForAck blocks until the batch is indexed. An error is returned if it is not acknowledged
+// before the timeout, so that the batch is sent again.
+func (a *indexerAcker) waitForAck(ctx context.Context, ackID uint64, headers map[string]string) error {
+	timer := time.NewTimer(a.timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(a.pollInterval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			if lastErr != nil {
+				return fmt.Errorf("ackId %d was not acknowledged within %s: %w", ackID, a.timeout, lastErr)
+			}
+			return fmt.Errorf("ackId %d was not acknowledged within %s", ackID, a.timeout)
+		case <-ticker.C:
+		}
+
+		acked, err := a.queryAck(ctx, ackID, headers)
+		if err != nil {
+			// The ack API is queried again until the timeout, as sending the batch again
+			// would duplicate the events if they were indexed.
+			a.logger.Debug("Failed to query the indexer acknowledgment", zap.Uint64("ackId", ackID), zap.Error(err))
+			lastErr = err
+			continue
+		}
+		if acked {
+			return nil
+		}
+	}
+}
+
+func (a *indexerAcker) queryAck(ctx context.Context, ackID uint64, headers map[string]string) (bool, error) {
+	body, err := json.Marshal(splunk.AckRequest{Acks: []uint64{ackID}})
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url.String(), bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set(splunk.HTTPSplunkChannelHeader, a.channel)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if err = splunk.HandleHTTPCode(resp); err != nil {
+		return false, err
+	}
+
+	var acks ackResponse
+	if err = json.NewDecoder(resp.Body).Decode(&acks); err != nil {
+		return false, fmt.Errorf("failed to read the acknowledgments: %w", err)
+	}
+	return acks.Acks[strconv.FormatUint(ackID, 10)], nil
+}
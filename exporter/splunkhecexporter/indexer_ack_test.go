@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package splunkhecexporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/splunk"
+)
+
+// ackServer is a HEC endpoint that acknowledges each batch after it has been queried ackAfter times.
+type ackServer struct {
+	t         *testing.T
+	ackAfter  int
+	omitAckID bool
+
+	mu       sync.Mutex
+	nextID   uint64
+	queries  map[uint64]int
+	channels []string
+}
+
+func newAckServer(t *testing.T, ackAfter int) *ackServer {
+	return &ackServer{t: t, ackAfter: ackAfter, queries: map[uint64]int{}}
+}
+
+func (s *ackServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.channels = append(s.channels, r.Header.Get(splunk.HTTPSplunkChannelHeader))
+	assert.Equal(s.t, "Splunk 1234", r.Header.Get("Authorization"))
+
+	if r.URL.Path != splunk.DefaultAckPath {
+		_, _ = io.Copy(io.Discard, r.Body)
+		if s.omitAckID {
+			_, _ = w.Write([]byte(`{"text": "Success", "code": 0}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"text": "Success", "code": 0, "ackId": %d}`, s.nextID)
+		s.nextID++
+		return
+	}
+
+	var req splunk.AckRequest
+	assert.NoError(s.t, json.NewDecoder(r.Body).Decode(&req))
+	acks := map[uint64]bool{}
+	for _, id := range req.Acks {
+		s.queries[id]++
+		acks[id] = s.ackAfter > 0 && s.queries[id] >= s.ackAfter
+	}
+	assert.NoError(s.t, json.NewEncoder(w).Encode(map[string]any{"acks": acks}))
+}
+
+func (s *ackServer) receivedChannels() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.channels
+}
+
+func newAckClient(t *testing.T, endpoint string, timeout time.Duration) *client {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = endpoint
+	cfg.Token = "1234"
+	cfg.DisableCompression = true
+	// A single record fits in a batch, so that each record has its own ackId.
+	cfg.MaxContentLengthLogs = 300
+	cfg.IndexerAck.Enabled = true
+	cfg.IndexerAck.PollInterval = 10 * time.Millisecond
+	cfg.IndexerAck.Timeout = timeout
+
+	c := newLogsClient(exportertest.NewNopSettings(), cfg)
+	require.NoError(t, c.start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, c.stop(context.Background()))
+	})
+	return c
+}
+
+func TestIndexerAck(t *testing.T) {
+	srv := newAckServer(t, 2)
+	httpSrv := httptest.NewServer(srv)
+	defer httpSrv.Close()
+
+	c := newAckClient(t, httpSrv.URL, time.Minute)
+	require.NoError(t, c.pushLogData(context.Background(), createLogData(1, 1, 2)))
+
+	// Each batch is sent then polled twice, on the same channel.
+	channels := srv.receivedChannels()
+	require.Len(t, channels, 6)
+	assert.NotEmpty(t, channels[0])
+	for _, channel := range channels {
+		assert.Equal(t, channels[0], channel)
+	}
+	assert.Equal(t, map[uint64]int{0: 2, 1: 2}, srv.queries)
+}
+
+func TestIndexerAckTimeout(t *testing.T) {
+	srv := newAckServer(t, 0)
+	httpSrv := httptest.NewServer(srv)
+	defer httpSrv.Close()
+
+	c := newAckClient(t, httpSrv.URL, 50*time.Millisecond)
+	logs := createLogData(1, 1, 2)
+	err := c.pushLogData(context.Background(), logs)
+	require.ErrorContains(t, err, "ackId 0 was not acknowledged within 50ms")
+	assert.False(t, consumererror.IsPermanent(err))
+
+	// The unacknowledged batch and the following ones are sent again.
+	var logsErr consumererror.Logs
+	require.ErrorAs(t, err, &logsErr)
+	assert.Equal(t, logs, logsErr.Data())
+}
+
+func TestIndexerAckMissingAckID(t *testing.T) {
+	srv := newAckServer(t, 1)
+	srv.omitAckID = true
+	httpSrv := httptest.NewServer(srv)
+	defer httpSrv.Close()
+
+	c := newAckClient(t, httpSrv.URL, time.Minute)
+	err := c.pushLogData(context.Background(), createLogData(1, 1, 1))
+	assert.ErrorIs(t, err, errMissingAckID)
+}
+
+func TestIndexerAckChannel(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "https://splunk:8088"
+	cfg.IndexerAck.Channel = "e1e7ae1c-3c4a-4b2f-b7a5-6c6d1d6d8a0f"
+
+	acker := newIndexerAcker(cfg, http.DefaultClient, nil)
+	assert.Equal(t, "e1e7ae1c-3c4a-4b2f-b7a5-6c6d1d6d8a0f", acker.channel)
+	assert.Equal(t, "https://splunk:8088/services/collector/ack", acker.url.String())
+
+	cfg.IndexerAck.Channel = ""
+	acker = newIndexerAcker(cfg, http.DefaultClient, nil)
+	_, err := uuid.Parse(acker.channel)
+	assert.NoError(t, err)
+}
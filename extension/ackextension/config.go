@@ -8,7 +8,8 @@ import (
 
 // Config defines configuration for ack extension
 type Config struct {
-	// StorageID defines the storage type of the extension. In-memory type is set by default (if not provided). Future consideration is disk type.
+	// StorageID defines the storage extension used to persist the acks, so that they survive restarts.
+	// The acks are only kept in memory if not provided.
 	StorageID *component.ID `mapstructure:"storage"`
 	// MaxNumPartition Specifies the maximum number of partitions that clients can acquire for this extension instance.
 	// Implementation defines how limit exceeding should be handled.
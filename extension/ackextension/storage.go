@@ -0,0 +1,377 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package ackextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/ackextension"
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+)
+
+const (
+	// partitionsKey is the storage key of the IDs of the partitions, from the least to the most recently used.
+	partitionsKey = "partitions"
+	// partitionKeyPrefix prefixes the storage keys of the partitions.
+	partitionKeyPrefix = "partition_"
+	// ackIDBlockSize is the number of ack IDs reserved at once in a partition, so that the storage is not
+	// written each time an ack ID is generated.
+	ackIDBlockSize = 1000
+	// defaultFlushInterval is the interval at which the changed partitions are written to the storage.
+	defaultFlushInterval = time.Second
+)
+
+var errInvalidPartitionRecord = errors.New("invalid partition record")
+
+// storageAckExtension is the storage-backed implementation of the AckExtension.
+// Ack IDs are tracked in memory as by the in-memory implementation, and the acknowledged ack IDs of each partition
+// are written to the storage, so that they can be queried after a restart. Ack IDs are reserved by blocks in the
+// storage, so that they are not generated again after a restart.
+// The changed partitions are written periodically in a batch, outside of the lock guarding the partitions, so that
+// acknowledging and querying ack IDs does not wait for the storage. Only the reservation of a new block of ack IDs
+// waits for the partition to be written, as its ack IDs must not be handed out before they are reserved.
+// The retention is bounded by MaxNumPartition and MaxNumPendingAcksPerPartition: the partitions and the acks
+// evicted from memory are removed from the storage.
+type storageAckExtension struct {
+	id                            component.ID
+	storageID                     component.ID
+	logger                        *zap.Logger
+	maxNumPartition               uint64
+	maxNumPendingAcksPerPartition uint64
+	flushInterval                 time.Duration
+
+	mu           sync.Mutex
+	client       storage.Client
+	partitionMap *lru.Cache[string, *storedAckPartition]
+	// changed and removed hold the IDs of the partitions to write to and to remove from the storage.
+	changed map[string]struct{}
+	removed map[string]struct{}
+	// partitionsChanged is whether the partition IDs must be written to the storage.
+	partitionsChanged bool
+
+	// flushMu serializes the flushes, so that the storage is updated in the order of the changes.
+	flushMu  sync.Mutex
+	shutdown chan struct{}
+	wg       sync.WaitGroup
+}
+
+func newStorageAckExtension(conf *Config, set component.TelemetrySettings, id component.ID) *storageAckExtension {
+	return &storageAckExtension{
+		id:                            id,
+		storageID:                     *conf.StorageID,
+		logger:                        set.Logger,
+		maxNumPartition:               conf.MaxNumPartition,
+		maxNumPendingAcksPerPartition: conf.MaxNumPendingAcksPerPartition,
+		flushInterval:                 defaultFlushInterval,
+		changed:                       map[string]struct{}{},
+		removed:                       map[string]struct{}{},
+	}
+}
+
+// storedAckPartition is a partition whose acknowledged ack IDs are written to the storage.
+type storedAckPartition struct {
+	// lastID is the last generated ack ID.
+	lastID uint64
+	// reservedID is the last reserved ack ID.
+	reservedID uint64
+	// writtenID is the last reserved ack ID written to the storage.
+	writtenID uint64
+	ackMap    *lru.Cache[uint64, bool]
+	// acked holds the acknowledged ack IDs of ackMap, which are written to the storage.
+	acked map[uint64]struct{}
+}
+
+func newStoredAckPartition(maxPendingAcks uint64) *storedAckPartition {
+	p := &storedAckPartition{acked: map[uint64]struct{}{}}
+	p.ackMap, _ = lru.NewWithEvict[uint64, bool](int(maxPendingAcks), func(ackID uint64, _ bool) {
+		delete(p.acked, ackID)
+	})
+	return p
+}
+
+// marshal encodes the reserved ack ID followed by the acknowledged ack IDs, as deltas in ascending order.
+func (p *storedAckPartition) marshal() []byte {
+	ackIDs := make([]uint64, 0, len(p.acked))
+	for ackID := range p.acked {
+		ackIDs = append(ackIDs, ackID)
+	}
+	slices.Sort(ackIDs)
+
+	buf := binary.AppendUvarint(nil, p.reservedID)
+	buf = binary.AppendUvarint(buf, uint64(len(ackIDs)))
+	previous := uint64(0)
+	for _, ackID := range ackIDs {
+		buf = binary.AppendUvarint(buf, ackID-previous)
+		previous = ackID
+	}
+	return buf
+}
+
+func (p *storedAckPartition) unmarshal(buf []byte) error {
+	values := make([]uint64, 0, 2)
+	for len(buf) > 0 {
+		value, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return errInvalidPartitionRecord
+		}
+		values = append(values, value)
+		buf = buf[n:]
+	}
+	if len(values) < 2 || uint64(len(values)-2) != values[1] {
+		return errInvalidPartitionRecord
+	}
+
+	// Ack IDs generated before the restart are never generated again.
+	p.reservedID = values[0]
+	p.writtenID = values[0]
+	p.lastID = values[0]
+	ackID := uint64(0)
+	for _, delta := range values[2:] {
+		ackID += delta
+		p.ackMap.Add(ackID, true)
+		p.acked[ackID] = struct{}{}
+	}
+	return nil
+}
+
+// Start gets the storage client, recovers the partitions from the storage and starts writing the changed partitions
+// periodically.
+func (s *storageAckExtension) Start(ctx context.Context, host component.Host) error {
+	ext, ok := host.GetExtensions()[s.storageID]
+	if !ok {
+		return fmt.Errorf("storage extension %s not found", s.storageID)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return fmt.Errorf("extension %s is not a storage extension", s.storageID)
+	}
+	client, err := storageExt.GetClient(ctx, component.KindExtension, s.id, "")
+	if err != nil {
+		return fmt.Errorf("failed to get the storage client: %w", err)
+	}
+
+	s.mu.Lock()
+	s.client = client
+	s.partitionMap, _ = lru.NewWithEvict[string, *storedAckPartition](int(s.maxNumPartition), func(partitionID string, _ *storedAckPartition) {
+		delete(s.changed, partitionID)
+		s.removed[partitionID] = struct{}{}
+		s.partitionsChanged = true
+	})
+	err = s.recover(ctx)
+	s.mu.Unlock()
+	// Partitions may have been evicted if the maximum number of partitions has been lowered.
+	if err == nil {
+		err = s.flush(ctx)
+	}
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed to recover the acks from the storage: %w", err), client.Close(ctx))
+	}
+
+	s.shutdown = make(chan struct{})
+	s.wg.Add(1)
+	go s.flushPeriodically()
+	return nil
+}
+
+func (s *storageAckExtension) recover(ctx context.Context) error {
+	buf, err := s.client.Get(ctx, partitionsKey)
+	if err != nil || buf == nil {
+		return err
+	}
+	var partitionIDs []string
+	if err = json.Unmarshal(buf, &partitionIDs); err != nil {
+		return err
+	}
+
+	for _, partitionID := range partitionIDs {
+		buf, err = s.client.Get(ctx, partitionKeyPrefix+partitionID)
+		if err != nil {
+			return err
+		}
+		if buf == nil {
+			continue
+		}
+		p := newStoredAckPartition(s.maxNumPendingAcksPerPartition)
+		if err = p.unmarshal(buf); err != nil {
+			return fmt.Errorf("partition %q: %w", partitionID, err)
+		}
+		s.partitionMap.Add(partitionID, p)
+	}
+	return nil
+}
+
+// Shutdown stops writing the changed partitions periodically, writes them with the partition IDs in their current
+// order of use, then closes the storage client.
+func (s *storageAckExtension) Shutdown(ctx context.Context) error {
+	if s.shutdown == nil {
+		return nil
+	}
+	close(s.shutdown)
+	s.wg.Wait()
+
+	s.mu.Lock()
+	s.partitionsChanged = true
+	s.mu.Unlock()
+	err := s.flush(ctx)
+	return errors.Join(err, s.client.Close(ctx))
+}
+
+func (s *storageAckExtension) flushPeriodically() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.shutdown:
+			return
+		case <-ticker.C:
+			if err := s.flush(context.Background()); err != nil {
+				s.logger.Warn("Failed to write the acks to the storage", zap.Error(err))
+			}
+		}
+	}
+}
+
+// flush writes the changed partitions to the storage, and removes the evicted ones. The changes are collected under
+// the lock guarding the partitions, and written in a batch without holding it. They are collected again if the
+// batch fails, so that they are written by the next flush.
+func (s *storageAckExtension) flush(ctx context.Context) error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	changed, removed, partitionsChanged := s.changed, s.removed, s.partitionsChanged
+	s.changed, s.removed, s.partitionsChanged = map[string]struct{}{}, map[string]struct{}{}, false
+	var ops []storage.Operation
+	reservedIDs := make(map[*storedAckPartition]uint64, len(changed))
+	for partitionID := range removed {
+		// The partition may have been evicted then used again.
+		if _, ok := s.partitionMap.Peek(partitionID); !ok {
+			ops = append(ops, storage.DeleteOperation(partitionKeyPrefix+partitionID))
+		}
+	}
+	for partitionID := range changed {
+		if p, ok := s.partitionMap.Peek(partitionID); ok {
+			ops = append(ops, storage.SetOperation(partitionKeyPrefix+partitionID, p.marshal()))
+			reservedIDs[p] = p.reservedID
+		}
+	}
+	if partitionsChanged {
+		buf, err := json.Marshal(s.partitionMap.Keys())
+		if err != nil {
+			s.mu.Unlock()
+			return err
+		}
+		ops = append(ops, storage.SetOperation(partitionsKey, buf))
+	}
+	s.mu.Unlock()
+
+	if len(ops) == 0 {
+		return nil
+	}
+	err := s.client.Batch(ctx, ops...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		for partitionID := range changed {
+			s.changed[partitionID] = struct{}{}
+		}
+		for partitionID := range removed {
+			s.removed[partitionID] = struct{}{}
+		}
+		s.partitionsChanged = s.partitionsChanged || partitionsChanged
+		return err
+	}
+	for p, reservedID := range reservedIDs {
+		p.writtenID = max(p.writtenID, reservedID)
+	}
+	return nil
+}
+
+// ProcessEvent marks the beginning of processing an event. It generates an ack ID for the associated partition ID.
+func (s *storageAckExtension) ProcessEvent(partitionID string) (ackID uint64) {
+	s.mu.Lock()
+	p, ok := s.partitionMap.Get(partitionID)
+	if !ok {
+		p = newStoredAckPartition(s.maxNumPendingAcksPerPartition)
+		s.partitionMap.Add(partitionID, p)
+		s.partitionsChanged = true
+	}
+
+	p.lastID++
+	ackID = p.lastID
+	numAcked := len(p.acked)
+	p.ackMap.Add(ackID, false)
+	if ackID > p.reservedID {
+		p.reservedID = ackID + ackIDBlockSize - 1
+		s.changed[partitionID] = struct{}{}
+	}
+	// An acknowledged ack ID has been evicted.
+	if len(p.acked) != numAcked {
+		s.changed[partitionID] = struct{}{}
+	}
+	reserved := ackID <= p.writtenID
+	s.mu.Unlock()
+
+	// The ack ID is only handed out once it is reserved in the storage.
+	if !reserved {
+		if err := s.flush(context.Background()); err != nil {
+			s.logger.Warn("Failed to reserve ack IDs in the storage", zap.String("partition", partitionID), zap.Error(err))
+		}
+	}
+	return ackID
+}
+
+// Ack acknowledges an event has been processed.
+func (s *storageAckExtension) Ack(partitionID string, ackID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.partitionMap.Get(partitionID)
+	if !ok {
+		return
+	}
+	if isAcked, ok := p.ackMap.Get(ackID); ok && !isAcked {
+		p.ackMap.Add(ackID, true)
+		p.acked[ackID] = struct{}{}
+		s.changed[partitionID] = struct{}{}
+	}
+}
+
+// QueryAcks checks the statuses of given ackIDs for a partition.
+// ackIDs that are not generated from ProcessEvent or have been removed as a result of previous calls to QueryAcks will return false.
+func (s *storageAckExtension) QueryAcks(partitionID string, ackIDs []uint64) map[uint64]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[uint64]bool, len(ackIDs))
+	p, ok := s.partitionMap.Get(partitionID)
+	if !ok {
+		for _, ackID := range ackIDs {
+			result[ackID] = false
+		}
+		return result
+	}
+
+	for _, ackID := range ackIDs {
+		if isAcked, ok := p.ackMap.Get(ackID); ok && isAcked {
+			result[ackID] = true
+			p.ackMap.Remove(ackID)
+			s.changed[partitionID] = struct{}{}
+		} else {
+			result[ackID] = false
+		}
+	}
+	return result
+}
@@ -0,0 +1,173 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package ackextension
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+)
+
+func newTestStorageAckExtension(t *testing.T, host component.Host, maxNumPartition, maxNumPendingAcks uint64) *storageAckExtension {
+	storageID := storagetest.NewStorageID("test")
+	ext := newStorageAckExtension(&Config{
+		StorageID:                     &storageID,
+		MaxNumPartition:               maxNumPartition,
+		MaxNumPendingAcksPerPartition: maxNumPendingAcks,
+	}, componenttest.NewNopTelemetrySettings(), component.MustNewID("ack"))
+	require.NoError(t, ext.Start(context.Background(), host))
+	return ext
+}
+
+func TestStorageAckExtensionRecovery(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+
+	ext := newTestStorageAckExtension(t, host, 10, 10)
+	for i := 0; i < 3; i++ {
+		ext.ProcessEvent("partition1")
+	}
+	ext.ProcessEvent("partition2")
+	ext.Ack("partition1", 1)
+	ext.Ack("partition1", 3)
+	ext.Ack("partition2", 1)
+	assert.Equal(t, map[uint64]bool{1: true}, ext.QueryAcks("partition2", []uint64{1}))
+	require.NoError(t, ext.Shutdown(context.Background()))
+
+	ext = newTestStorageAckExtension(t, host, 10, 10)
+	// Ack IDs generated before the restart are not generated again.
+	assert.Equal(t, uint64(ackIDBlockSize+1), ext.ProcessEvent("partition1"))
+	assert.Equal(t, map[uint64]bool{1: true, 2: false, 3: true}, ext.QueryAcks("partition1", []uint64{1, 2, 3}))
+	assert.Equal(t, map[uint64]bool{1: false}, ext.QueryAcks("partition2", []uint64{1}))
+	require.NoError(t, ext.Shutdown(context.Background()))
+
+	// Queried acks are removed from the storage.
+	ext = newTestStorageAckExtension(t, host, 10, 10)
+	assert.Equal(t, map[uint64]bool{1: false, 3: false}, ext.QueryAcks("partition1", []uint64{1, 3}))
+	require.NoError(t, ext.Shutdown(context.Background()))
+}
+
+func TestStorageAckExtensionRetention(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+
+	ext := newTestStorageAckExtension(t, host, 2, 2)
+	for i := uint64(1); i <= 3; i++ {
+		ext.ProcessEvent("partition1")
+		ext.Ack("partition1", i)
+	}
+	ext.ProcessEvent("partition2")
+	ext.Ack("partition2", 1)
+	ext.ProcessEvent("partition3")
+	ext.Ack("partition3", 1)
+	require.NoError(t, ext.Shutdown(context.Background()))
+
+	// The least recently used partition and acks have been evicted from the storage.
+	ext = newTestStorageAckExtension(t, host, 2, 2)
+	assert.Equal(t, map[uint64]bool{1: false}, ext.QueryAcks("partition1", []uint64{1}))
+	assert.Equal(t, map[uint64]bool{1: true}, ext.QueryAcks("partition2", []uint64{1}))
+	assert.Equal(t, map[uint64]bool{1: true}, ext.QueryAcks("partition3", []uint64{1}))
+	require.NoError(t, ext.Shutdown(context.Background()))
+
+	storageID := storagetest.NewStorageID("test")
+	ext = newStorageAckExtension(&Config{StorageID: &storageID, MaxNumPartition: 10, MaxNumPendingAcksPerPartition: 2},
+		componenttest.NewNopTelemetrySettings(), component.MustNewID("ack"))
+	require.NoError(t, ext.Start(context.Background(), host))
+	for i := uint64(1); i <= 3; i++ {
+		ext.ProcessEvent("partition4")
+		ext.Ack("partition4", i)
+	}
+	require.NoError(t, ext.Shutdown(context.Background()))
+
+	ext = newTestStorageAckExtension(t, host, 10, 2)
+	assert.Equal(t, map[uint64]bool{1: false, 2: true, 3: true}, ext.QueryAcks("partition4", []uint64{1, 2, 3}))
+	require.NoError(t, ext.Shutdown(context.Background()))
+}
+
+func TestStorageAckExtensionFlush(t *testing.T) {
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("test", t.TempDir())
+	storageID := storagetest.NewStorageID("test")
+	ext := newStorageAckExtension(&Config{StorageID: &storageID, MaxNumPartition: 10, MaxNumPendingAcksPerPartition: 10},
+		componenttest.NewNopTelemetrySettings(), component.MustNewID("ack"))
+	ext.flushInterval = 10 * time.Millisecond
+	require.NoError(t, ext.Start(context.Background(), host))
+	defer func() {
+		require.NoError(t, ext.Shutdown(context.Background()))
+	}()
+
+	readPartition := func() *storedAckPartition {
+		buf, err := ext.client.Get(context.Background(), partitionKeyPrefix+"partition1")
+		require.NoError(t, err)
+		p := newStoredAckPartition(10)
+		if buf != nil {
+			require.NoError(t, p.unmarshal(buf))
+		}
+		return p
+	}
+
+	// The ack IDs are reserved in the storage before being handed out.
+	assert.Equal(t, uint64(1), ext.ProcessEvent("partition1"))
+	assert.Equal(t, uint64(ackIDBlockSize), readPartition().reservedID)
+
+	// The acks are written by the next flush.
+	ext.Ack("partition1", 1)
+	assert.Eventually(t, func() bool {
+		_, ok := readPartition().acked[1]
+		return ok
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestStorageAckExtensionStartErrors(t *testing.T) {
+	storageID := storagetest.NewStorageID("test")
+	nonStorageID := storagetest.NewNonStorageID("test")
+	tests := []struct {
+		name        string
+		storageID   component.ID
+		host        component.Host
+		expectedErr string
+	}{
+		{
+			name:        "missing storage",
+			storageID:   storageID,
+			host:        storagetest.NewStorageHost(),
+			expectedErr: "storage extension test_storage/test not found",
+		},
+		{
+			name:        "not a storage",
+			storageID:   nonStorageID,
+			host:        storagetest.NewStorageHost().WithNonStorageExtension("test"),
+			expectedErr: "extension non_storage/test is not a storage extension",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ext := newStorageAckExtension(&Config{StorageID: &tt.storageID, MaxNumPartition: 10, MaxNumPendingAcksPerPartition: 10},
+				componenttest.NewNopTelemetrySettings(), component.MustNewID("ack"))
+			assert.EqualError(t, ext.Start(context.Background(), tt.host), tt.expectedErr)
+			assert.NoError(t, ext.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestStoredAckPartitionMarshal(t *testing.T) {
+	p := newStoredAckPartition(10)
+	p.reservedID = 1000
+	p.acked = map[uint64]struct{}{3: {}, 1: {}, 300: {}}
+
+	recovered := newStoredAckPartition(10)
+	require.NoError(t, recovered.unmarshal(p.marshal()))
+	assert.Equal(t, uint64(1000), recovered.reservedID)
+	assert.Equal(t, uint64(1000), recovered.lastID)
+	assert.Equal(t, p.acked, recovered.acked)
+	assert.Equal(t, []uint64{1, 3, 300}, recovered.ackMap.Keys())
+
+	assert.ErrorIs(t, newStoredAckPartition(10).unmarshal([]byte{0x80}), errInvalidPartitionRecord)
+	assert.ErrorIs(t, newStoredAckPartition(10).unmarshal([]byte{1, 2, 1}), errInvalidPartitionRecord)
+}
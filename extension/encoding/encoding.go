@@ -4,6 +4,8 @@
 package encoding // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding"
 
 import (
+	"bufio"
+
 	"go.opentelemetry.io/collector/extension"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
@@ -58,3 +60,39 @@ type ProfilesUnmarshalerExtension interface {
 	extension.Extension
 	pprofile.Unmarshaler
 }
+
+// StreamMarshalerExtension is an extension whose encoded batches form a stream, such as a file:
+// the batches are self-delimiting and written one after the other without any framing, so that
+// the stream is in the format of the encoding, and may depend on the previous batches of the stream.
+type StreamMarshalerExtension interface {
+	extension.Extension
+	// NewMarshalerStream starts a new stream. The returned marshaler implements the
+	// marshaler interfaces of the signals that the extension supports, and must be
+	// used to encode the batches in the order in which they are written.
+	NewMarshalerStream() MarshalerStream
+}
+
+// MarshalerStream marshals the batches of a single stream.
+type MarshalerStream interface {
+	// Close releases the resources of the stream.
+	Close() error
+}
+
+// StreamUnmarshalerExtension is an extension that unmarshals the batches encoded by a
+// StreamMarshalerExtension.
+type StreamUnmarshalerExtension interface {
+	extension.Extension
+	// NewUnmarshalerStream starts a new stream. The returned unmarshaler implements the
+	// unmarshaler interfaces of the signals that the extension supports, and must be
+	// used to decode the batches of a stream in the order in which they were encoded.
+	NewUnmarshalerStream() UnmarshalerStream
+}
+
+// UnmarshalerStream unmarshals the batches of a single stream.
+type UnmarshalerStream interface {
+	// ReadBatch reads the next encoded batch of the stream from r, to be unmarshaled. It
+	// returns io.EOF when the stream has no more batches.
+	ReadBatch(r *bufio.Reader) ([]byte, error)
+	// Close releases the resources of the stream.
+	Close() error
+}
@@ -0,0 +1,28 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowencodingextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding/otelarrowencodingextension"
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configcompression"
+)
+
+var _ component.ConfigValidator = (*Config)(nil)
+
+type Config struct {
+	// PayloadCompression is the compression of the Arrow IPC streams of each encoded
+	// batch, either "zstd" or "none".
+	PayloadCompression configcompression.Type `mapstructure:"payload_compression"`
+}
+
+func (c *Config) Validate() error {
+	switch c.PayloadCompression {
+	case configcompression.TypeZstd, "none", "":
+		return nil
+	default:
+		return fmt.Errorf("unsupported payload compression: %q", c.PayloadCompression)
+	}
+}
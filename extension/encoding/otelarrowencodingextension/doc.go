@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+// Package otelarrowencodingextension implements an encoding extension marshaling and unmarshaling
+// telemetry as OTel-Arrow record batches.
+package otelarrowencodingextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding/otelarrowencodingextension"
@@ -0,0 +1,222 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowencodingextension
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/apache/arrow/go/v17/arrow/ipc"
+	otelAssert "github.com/open-telemetry/otel-arrow/pkg/otel/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configcompression"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/otelarrow/testdata"
+)
+
+type compareJSONTraces struct{ ptrace.Traces }
+type compareJSONMetrics struct{ pmetric.Metrics }
+type compareJSONLogs struct{ plog.Logs }
+
+func (c compareJSONTraces) MarshalJSON() ([]byte, error) {
+	var m ptrace.JSONMarshaler
+	return m.MarshalTraces(c.Traces)
+}
+
+func (c compareJSONMetrics) MarshalJSON() ([]byte, error) {
+	var m pmetric.JSONMarshaler
+	return m.MarshalMetrics(c.Metrics)
+}
+
+func (c compareJSONLogs) MarshalJSON() ([]byte, error) {
+	var m plog.JSONMarshaler
+	return m.MarshalLogs(c.Logs)
+}
+
+func TestExtension_Start(t *testing.T) {
+	factory := NewFactory()
+	ext, err := factory.Create(context.Background(), extensiontest.NewNopSettings(), factory.CreateDefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, ext.Shutdown(context.Background()))
+}
+
+func TestConfigValidate(t *testing.T) {
+	require.NoError(t, (&Config{PayloadCompression: "none"}).Validate())
+	require.NoError(t, (&Config{PayloadCompression: configcompression.TypeZstd}).Validate())
+	require.EqualError(t, (&Config{PayloadCompression: configcompression.TypeGzip}).Validate(), `unsupported payload compression: "gzip"`)
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, compression := range []configcompression.Type{"none", configcompression.TypeZstd} {
+		t.Run(string(compression), func(t *testing.T) {
+			stdTesting := otelAssert.NewStdUnitTest(t)
+			ex := newExtension(&Config{PayloadCompression: compression})
+
+			traces := testdata.GenerateTraces(10)
+			buf, err := ex.MarshalTraces(traces)
+			require.NoError(t, err)
+			traces0, err := ex.UnmarshalTraces(buf)
+			require.NoError(t, err)
+			otelAssert.Equiv(stdTesting, []json.Marshaler{compareJSONTraces{traces}}, []json.Marshaler{compareJSONTraces{traces0}})
+
+			logs := testdata.GenerateLogs(10)
+			buf, err = ex.MarshalLogs(logs)
+			require.NoError(t, err)
+			logs0, err := ex.UnmarshalLogs(buf)
+			require.NoError(t, err)
+			otelAssert.Equiv(stdTesting, []json.Marshaler{compareJSONLogs{logs}}, []json.Marshaler{compareJSONLogs{logs0}})
+
+			metrics := testdata.GenerateMetrics(2)
+			buf, err = ex.MarshalMetrics(metrics)
+			require.NoError(t, err)
+			metrics0, err := ex.UnmarshalMetrics(buf)
+			require.NoError(t, err)
+			otelAssert.Equiv(stdTesting, []json.Marshaler{compareJSONMetrics{metrics}}, []json.Marshaler{compareJSONMetrics{metrics0}})
+		})
+	}
+}
+
+// TestIndependentBatches verifies that each batch encoded by the marshalers of the extension can be
+// decoded on its own, as the batches sent as messages are.
+func TestIndependentBatches(t *testing.T) {
+	ex := newExtension(&Config{PayloadCompression: "none"})
+
+	var bufs [][]byte
+	for i := 0; i < 3; i++ {
+		buf, err := ex.MarshalTraces(testdata.GenerateTraces(10))
+		require.NoError(t, err)
+		bufs = append(bufs, buf)
+	}
+
+	for i := len(bufs) - 1; i >= 0; i-- {
+		traces, err := ex.UnmarshalTraces(bufs[i])
+		require.NoError(t, err)
+		require.Equal(t, 10, traces.SpanCount())
+	}
+}
+
+// TestStream verifies that the batches of a stream, which may hold several signals, are written one
+// after the other and read back in order by an unmarshaler stream.
+func TestStream(t *testing.T) {
+	stdTesting := otelAssert.NewStdUnitTest(t)
+	ex := newExtension(&Config{PayloadCompression: "none"})
+	marshaler := ex.newMarshalerStream()
+	defer func() {
+		require.NoError(t, marshaler.Close())
+	}()
+
+	var file bytes.Buffer
+	for i := 0; i < 3; i++ {
+		buf, err := marshaler.MarshalTraces(testdata.GenerateTraces(10))
+		require.NoError(t, err)
+		file.Write(buf)
+		buf, err = marshaler.MarshalLogs(testdata.GenerateLogs(10))
+		require.NoError(t, err)
+		file.Write(buf)
+	}
+
+	unmarshaler := ex.newUnmarshalerStream()
+	defer func() {
+		require.NoError(t, unmarshaler.Close())
+	}()
+	r := bufio.NewReader(&file)
+	for i := 0; ; i++ {
+		buf, err := unmarshaler.ReadBatch(r)
+		if errors.Is(err, io.EOF) {
+			require.Equal(t, 6, i)
+			break
+		}
+		require.NoError(t, err)
+		// read the batches as a reader that does not know their signal would: the batches of the
+		// other signals are decoded as empty batches.
+		traces, err := unmarshaler.UnmarshalTraces(buf)
+		require.NoError(t, err)
+		if i%2 == 1 {
+			require.Equal(t, 0, traces.SpanCount())
+			logs, err := unmarshaler.UnmarshalLogs(buf)
+			require.NoError(t, err)
+			otelAssert.Equiv(stdTesting, []json.Marshaler{compareJSONLogs{testdata.GenerateLogs(10)}}, []json.Marshaler{compareJSONLogs{logs}})
+			continue
+		}
+		otelAssert.Equiv(stdTesting, []json.Marshaler{compareJSONTraces{testdata.GenerateTraces(10)}}, []json.Marshaler{compareJSONTraces{traces}})
+	}
+}
+
+// TestArrowIPCStreams verifies that the encoded batches are Arrow IPC streams that generic Arrow
+// readers can read, one per payload type, with the metadata of the payload in their schema.
+func TestArrowIPCStreams(t *testing.T) {
+	for _, compression := range []configcompression.Type{"none", configcompression.TypeZstd} {
+		t.Run(string(compression), func(t *testing.T) {
+			marshaler := newExtension(&Config{PayloadCompression: compression}).newMarshalerStream()
+			var file bytes.Buffer
+			for i := 0; i < 2; i++ {
+				buf, err := marshaler.MarshalTraces(testdata.GenerateTraces(10))
+				require.NoError(t, err)
+				file.Write(buf)
+			}
+
+			r := bytes.NewReader(file.Bytes())
+			streams := map[string][]string{}
+			counts := map[string]string{}
+			for r.Len() > 0 {
+				reader, err := ipc.NewReader(r)
+				require.NoError(t, err)
+				metadata := reader.Schema().Metadata()
+				payloadType, _ := metadata.GetValue(payloadTypeKey)
+				batch, _ := metadata.GetValue(batchKey)
+				count, _ := metadata.GetValue(payloadCountKey)
+				rows := int64(0)
+				for reader.Next() {
+					rows += reader.Record().NumRows()
+				}
+				require.NoError(t, reader.Err())
+				require.Positive(t, rows)
+				reader.Release()
+				streams[batch] = append(streams[batch], payloadType)
+				counts[batch] = count
+			}
+			require.Len(t, streams, 2)
+			for batch, payloadTypes := range streams {
+				require.Equal(t, counts[batch], strconv.Itoa(len(payloadTypes)))
+			}
+			require.Equal(t, streams["0"], streams["1"])
+			require.Equal(t, "SPANS", streams["0"][0])
+			require.Contains(t, streams["0"], "RESOURCE_ATTRS")
+		})
+	}
+}
+
+func TestReadBatchInvalid(t *testing.T) {
+	ex := newExtension(&Config{PayloadCompression: "none"})
+	buf, err := ex.MarshalTraces(testdata.GenerateTraces(10))
+	require.NoError(t, err)
+
+	_, err = readBatch(bufio.NewReader(bytes.NewReader(buf[:len(buf)-4])))
+	require.ErrorContains(t, err, "failed to read arrow stream")
+	_, err = readBatch(bufio.NewReader(bytes.NewReader(nil)))
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	ex := newExtension(&Config{PayloadCompression: "none"})
+
+	_, err := ex.UnmarshalTraces([]byte{0xff, 0xff})
+	require.ErrorContains(t, err, "failed to unmarshal arrow records")
+	_, err = ex.UnmarshalLogs([]byte{0xff, 0xff})
+	require.ErrorContains(t, err, "failed to unmarshal arrow records")
+	_, err = ex.UnmarshalMetrics([]byte{0xff, 0xff})
+	require.ErrorContains(t, err, "failed to unmarshal arrow records")
+}
@@ -0,0 +1,153 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowencodingextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding/otelarrowencodingextension"
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/ipc"
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+)
+
+// The metadata of the schema of each Arrow IPC stream of an encoded batch, which tells what the
+// stream holds to the readers of the batches, including generic Arrow tools.
+const (
+	// payloadTypeKey is the OTel-Arrow payload type of the record batch of the stream, e.g. SPANS.
+	payloadTypeKey = "otel_arrow.payload_type"
+	// batchKey is the index of the encoded batch in its marshaler stream. The IDs relating the
+	// record batches of the payload types of a batch only hold within the batch.
+	batchKey = "otel_arrow.batch"
+	// payloadCountKey is the number of payloads of the encoded batch, i.e. its number of streams.
+	payloadCountKey = "otel_arrow.payload_count"
+)
+
+// encodeBatch encodes the payloads of a batch one after the other, each as a complete Arrow IPC
+// stream holding the schema, with the metadata of the payload, the dictionaries and the record
+// batch of the payload, followed by the end-of-stream marker.
+func encodeBatch(records *arrowpb.BatchArrowRecords, batch int64, options []ipc.Option) ([]byte, error) {
+	var buf bytes.Buffer
+	payloads := records.GetArrowPayloads()
+	for _, payload := range payloads {
+		metadata := arrow.NewMetadata(
+			[]string{payloadTypeKey, batchKey, payloadCountKey},
+			[]string{payload.GetType().String(), strconv.FormatInt(batch, 10), strconv.Itoa(len(payloads))},
+		)
+		if err := encodePayload(&buf, payload, metadata, options); err != nil {
+			return nil, fmt.Errorf("failed to write the %s arrow stream: %w", payload.GetType(), err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func encodePayload(w io.Writer, payload *arrowpb.ArrowPayload, metadata arrow.Metadata, options []ipc.Option) error {
+	reader, err := ipc.NewReader(bytes.NewReader(payload.GetRecord()), ipc.WithDictionaryDeltas(true))
+	if err != nil {
+		return err
+	}
+	defer reader.Release()
+
+	schemaMetadata := reader.Schema().Metadata()
+	metadata = arrow.NewMetadata(
+		slices.Concat(schemaMetadata.Keys(), metadata.Keys()),
+		slices.Concat(schemaMetadata.Values(), metadata.Values()),
+	)
+	schema := arrow.NewSchema(reader.Schema().Fields(), &metadata)
+
+	writer := ipc.NewWriter(w, append([]ipc.Option{ipc.WithSchema(schema)}, options...)...)
+	for reader.Next() {
+		rec := array.NewRecord(schema, reader.Record().Columns(), reader.Record().NumRows())
+		err = writer.Write(rec)
+		rec.Release()
+		if err != nil {
+			return errors.Join(err, writer.Close())
+		}
+	}
+	if err = reader.Err(); err != nil {
+		return errors.Join(err, writer.Close())
+	}
+	return writer.Close()
+}
+
+// decodeBatch returns the payloads of an encoded batch, whose records are the Arrow IPC streams
+// of the payloads.
+func decodeBatch(buf []byte) (*arrowpb.BatchArrowRecords, error) {
+	records := &arrowpb.BatchArrowRecords{}
+	r := bytes.NewReader(buf)
+	for r.Len() > 0 {
+		stream, schema, err := readStream(r)
+		if err != nil {
+			return nil, err
+		}
+		name, _ := schema.Metadata().GetValue(payloadTypeKey)
+		payloadType, ok := arrowpb.ArrowPayloadType_value[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown arrow payload type %q", name)
+		}
+		records.ArrowPayloads = append(records.ArrowPayloads, &arrowpb.ArrowPayload{
+			// Each payload has a stream of its own, with its schema.
+			SchemaId: strconv.Itoa(len(records.ArrowPayloads)),
+			Type:     arrowpb.ArrowPayloadType(payloadType),
+			Record:   stream,
+		})
+	}
+	return records, nil
+}
+
+// readBatch reads the next encoded batch of r: the number of its streams is read from the
+// metadata of its first stream. It returns io.EOF when r has no more batches.
+func readBatch(r *bufio.Reader) ([]byte, error) {
+	if _, err := r.Peek(1); err != nil {
+		return nil, err
+	}
+	var buf []byte
+	for count := 1; count > 0; count-- {
+		stream, schema, err := readStream(r)
+		if err != nil {
+			return nil, err
+		}
+		if buf == nil {
+			value, _ := schema.Metadata().GetValue(payloadCountKey)
+			if count, err = strconv.Atoi(value); err != nil || count < 1 {
+				return nil, fmt.Errorf("invalid %s metadata %q", payloadCountKey, value)
+			}
+		}
+		buf = append(buf, stream...)
+	}
+	return buf, nil
+}
+
+// readStream reads an Arrow IPC stream up to its end-of-stream marker, and returns the stream
+// and its schema.
+func readStream(r io.Reader) ([]byte, *arrow.Schema, error) {
+	var buf bytes.Buffer
+	messages := ipc.NewMessageReader(io.TeeReader(r, &buf))
+	defer messages.Release()
+	for {
+		// The message reader also returns io.EOF when the stream is truncated.
+		_, err := messages.Message()
+		if errors.Is(err, io.EOF) && bytes.HasSuffix(buf.Bytes(), endOfStream) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read arrow stream: %w", err)
+		}
+	}
+	reader, err := ipc.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read arrow stream: %w", err)
+	}
+	defer reader.Release()
+	return buf.Bytes(), reader.Schema(), nil
+}
+
+// endOfStream is the end-of-stream marker of the Arrow IPC streams.
+var endOfStream = []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}
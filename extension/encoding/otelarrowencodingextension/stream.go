@@ -0,0 +1,188 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package otelarrowencodingextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding/otelarrowencodingextension"
+
+import (
+	"bufio"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/apache/arrow/go/v17/arrow/ipc"
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	arrowconfig "github.com/open-telemetry/otel-arrow/pkg/config"
+	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/multierr"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding"
+)
+
+var (
+	_ encoding.MarshalerStream   = (*marshalerStream)(nil)
+	_ ptrace.Marshaler           = (*marshalerStream)(nil)
+	_ pmetric.Marshaler          = (*marshalerStream)(nil)
+	_ plog.Marshaler             = (*marshalerStream)(nil)
+	_ encoding.UnmarshalerStream = (*unmarshalerStream)(nil)
+	_ ptrace.Unmarshaler         = (*unmarshalerStream)(nil)
+	_ pmetric.Unmarshaler        = (*unmarshalerStream)(nil)
+	_ plog.Unmarshaler           = (*unmarshalerStream)(nil)
+)
+
+// The payload types of the root records of each signal, which tell the signal of an encoded batch.
+var (
+	tracesPayloadTypes  = []arrowpb.ArrowPayloadType{arrowpb.ArrowPayloadType_SPANS}
+	metricsPayloadTypes = []arrowpb.ArrowPayloadType{arrowpb.ArrowPayloadType_UNIVARIATE_METRICS, arrowpb.ArrowPayloadType_MULTIVARIATE_METRICS}
+	logsPayloadTypes    = []arrowpb.ArrowPayloadType{arrowpb.ArrowPayloadType_LOGS}
+)
+
+// marshalerStream encodes the batches of a stream, e.g. a file, numbering them so that the readers
+// of the stream can tell the batch of each Arrow IPC stream. Each batch is encoded on its own, with the
+// schemas and the dictionaries of its payloads, so that generic Arrow tools can read its streams.
+type marshalerStream struct {
+	mu      sync.Mutex
+	options []ipc.Option
+	batch   int64
+}
+
+func (s *marshalerStream) MarshalTraces(traces ptrace.Traces) ([]byte, error) {
+	return s.marshal(func(producer *arrowRecord.Producer) (*arrowpb.BatchArrowRecords, error) {
+		return producer.BatchArrowRecordsFromTraces(traces)
+	})
+}
+
+func (s *marshalerStream) MarshalMetrics(metrics pmetric.Metrics) ([]byte, error) {
+	return s.marshal(func(producer *arrowRecord.Producer) (*arrowpb.BatchArrowRecords, error) {
+		return producer.BatchArrowRecordsFromMetrics(metrics)
+	})
+}
+
+func (s *marshalerStream) MarshalLogs(logs plog.Logs) ([]byte, error) {
+	return s.marshal(func(producer *arrowRecord.Producer) (*arrowpb.BatchArrowRecords, error) {
+		return producer.BatchArrowRecordsFromLogs(logs)
+	})
+}
+
+// marshal produces the OTel-Arrow records of a batch with a new producer, whose first records hold
+// the schemas and the dictionaries, and encodes them as Arrow IPC streams.
+func (s *marshalerStream) marshal(produce func(*arrowRecord.Producer) (*arrowpb.BatchArrowRecords, error)) (buf []byte, err error) {
+	producer := arrowRecord.NewProducerWithOptions(arrowconfig.WithNoZstd())
+	defer func() {
+		err = multierr.Append(err, producer.Close())
+	}()
+	records, err := produce(producer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create arrow records: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if buf, err = encodeBatch(records, s.batch, s.options); err != nil {
+		return nil, err
+	}
+	s.batch++
+	return buf, nil
+}
+
+func (s *marshalerStream) Close() error {
+	return nil
+}
+
+// unmarshalerStream decodes the batches of a stream. As each batch holds the schemas of its
+// payloads, each batch is decoded with a new consumer.
+type unmarshalerStream struct{}
+
+func (s *unmarshalerStream) ReadBatch(r *bufio.Reader) ([]byte, error) {
+	return readBatch(r)
+}
+
+func (s *unmarshalerStream) UnmarshalTraces(buf []byte) (traces ptrace.Traces, err error) {
+	records, err := unmarshalRecords(buf, tracesPayloadTypes)
+	if err != nil {
+		return ptrace.Traces{}, err
+	}
+	if records == nil {
+		return ptrace.NewTraces(), nil
+	}
+	consumer := arrowRecord.NewConsumer()
+	defer func() {
+		err = multierr.Append(err, consumer.Close())
+	}()
+	batches, err := consumer.TracesFrom(records)
+	if err != nil {
+		return ptrace.Traces{}, fmt.Errorf("failed to read arrow records: %w", err)
+	}
+	traces = ptrace.NewTraces()
+	for _, batch := range batches {
+		batch.ResourceSpans().MoveAndAppendTo(traces.ResourceSpans())
+	}
+	return traces, nil
+}
+
+func (s *unmarshalerStream) UnmarshalMetrics(buf []byte) (metrics pmetric.Metrics, err error) {
+	records, err := unmarshalRecords(buf, metricsPayloadTypes)
+	if err != nil {
+		return pmetric.Metrics{}, err
+	}
+	if records == nil {
+		return pmetric.NewMetrics(), nil
+	}
+	consumer := arrowRecord.NewConsumer()
+	defer func() {
+		err = multierr.Append(err, consumer.Close())
+	}()
+	batches, err := consumer.MetricsFrom(records)
+	if err != nil {
+		return pmetric.Metrics{}, fmt.Errorf("failed to read arrow records: %w", err)
+	}
+	metrics = pmetric.NewMetrics()
+	for _, batch := range batches {
+		batch.ResourceMetrics().MoveAndAppendTo(metrics.ResourceMetrics())
+	}
+	return metrics, nil
+}
+
+func (s *unmarshalerStream) UnmarshalLogs(buf []byte) (logs plog.Logs, err error) {
+	records, err := unmarshalRecords(buf, logsPayloadTypes)
+	if err != nil {
+		return plog.Logs{}, err
+	}
+	if records == nil {
+		return plog.NewLogs(), nil
+	}
+	consumer := arrowRecord.NewConsumer()
+	defer func() {
+		err = multierr.Append(err, consumer.Close())
+	}()
+	batches, err := consumer.LogsFrom(records)
+	if err != nil {
+		return plog.Logs{}, fmt.Errorf("failed to read arrow records: %w", err)
+	}
+	logs = plog.NewLogs()
+	for _, batch := range batches {
+		batch.ResourceLogs().MoveAndAppendTo(logs.ResourceLogs())
+	}
+	return logs, nil
+}
+
+func (s *unmarshalerStream) Close() error {
+	return nil
+}
+
+// unmarshalRecords decodes an encoded batch, and returns nil if it holds another signal than the one
+// of payloadTypes. Such batches are decoded as empty batches, as with the OTLP protobuf encoding.
+func unmarshalRecords(buf []byte, payloadTypes []arrowpb.ArrowPayloadType) (*arrowpb.BatchArrowRecords, error) {
+	records, err := decodeBatch(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arrow records: %w", err)
+	}
+	for _, payload := range records.GetArrowPayloads() {
+		if slices.Contains(payloadTypes, payload.GetType()) {
+			return records, nil
+		}
+	}
+	return nil, nil
+}
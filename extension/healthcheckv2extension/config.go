@@ -13,11 +13,13 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/common"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/grpc"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/http"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/rules"
 )
 
 const (
-	httpConfigKey = "http"
-	grpcConfigKey = "grpc"
+	httpConfigKey        = "http"
+	grpcConfigKey        = "grpc"
+	healthRulesConfigKey = "health_rules"
 )
 
 var (
@@ -41,6 +43,9 @@ type Config struct {
 
 	// ComponentHealthConfig is v2 config shared between http and grpc services
 	ComponentHealthConfig *common.ComponentHealthConfig `mapstructure:"component_health"`
+
+	// HealthRulesConfig is v2 config for the health rules reported alongside component status
+	HealthRulesConfig *rules.Config `mapstructure:"health_rules"`
 }
 
 var _ component.Config = (*Config)(nil)
@@ -95,5 +100,9 @@ func (c *Config) Unmarshal(conf *confmap.Conf) error {
 		c.GRPCConfig = nil
 	}
 
+	if !conf.IsSet(healthRulesConfigKey) {
+		c.HealthRulesConfig = nil
+	}
+
 	return nil
 }
@@ -21,6 +21,7 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/grpc"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/http"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/rules"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/testutil"
 )
 
@@ -28,9 +29,10 @@ func TestLoadConfig(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		id          component.ID
-		expected    component.Config
-		expectedErr error
+		id             component.ID
+		expected       component.Config
+		expectedErr    error
+		expectedErrMsg string
 	}{
 		{
 			id: component.NewID(metadata.Type),
@@ -170,6 +172,67 @@ func TestLoadConfig(t *testing.T) {
 			id:          component.NewIDWithName(metadata.Type, "v2noprotocols"),
 			expectedErr: errMissingProtocol,
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "v2healthrules"),
+			expected: &Config{
+				LegacyConfig: http.LegacyConfig{
+					UseV2: true,
+					ServerConfig: confighttp.ServerConfig{
+						Endpoint: testutil.EndpointForPort(defaultHTTPPort),
+					},
+					Path: "/",
+				},
+				HTTPConfig: &http.Config{
+					ServerConfig: confighttp.ServerConfig{
+						Endpoint: testutil.EndpointForPort(defaultHTTPPort),
+					},
+					Status: http.PathConfig{
+						Enabled: true,
+						Path:    "/status",
+					},
+					Config: http.PathConfig{
+						Enabled: false,
+						Path:    "/config",
+					},
+				},
+				HealthRulesConfig: &rules.Config{
+					Interval: 30 * time.Second,
+					Metrics:  healthRulesMetricsConfig(),
+					Rules: []rules.Rule{
+						{
+							Name:   "loadbalancing_backends",
+							Status: rules.StatusPermanent,
+							Metric: &rules.MetricCondition{
+								Name:   "otelcol_loadbalancer_backend_outcome_total",
+								Labels: map[string]string{"success": "true"},
+								Rate:   true,
+								Above:  ptr(0.0),
+							},
+						},
+						{
+							Name: "sending_queue",
+							Metric: &rules.MetricCondition{
+								Name:        "otelcol_exporter_queue_size",
+								DividedBy:   "otelcol_exporter_queue_capacity",
+								Aggregation: rules.AggregationMax,
+								Below:       ptr(0.8),
+							},
+						},
+						{
+							Name: "exporters",
+							Components: &rules.ComponentsCondition{
+								IDs:        []string{"exporter:otlp/1", "exporter:otlp/2"},
+								MinHealthy: 1,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			id:             component.NewIDWithName(metadata.Type, "v2healthrulesinvalid"),
+			expectedErrMsg: `rule "exporters": exactly one of components or metric must be set`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -185,8 +248,16 @@ func TestLoadConfig(t *testing.T) {
 				assert.ErrorIs(t, component.ValidateConfig(cfg), tt.expectedErr)
 				return
 			}
+			if tt.expectedErrMsg != "" {
+				assert.ErrorContains(t, component.ValidateConfig(cfg), tt.expectedErrMsg)
+				return
+			}
 			assert.NoError(t, component.ValidateConfig(cfg))
 			assert.Equal(t, tt.expected, cfg)
 		})
 	}
 }
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/grpc"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/http"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/rules"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/status"
 )
 
@@ -29,6 +30,7 @@ type healthCheckExtension struct {
 	telemetry     component.TelemetrySettings
 	aggregator    *status.Aggregator
 	subcomponents []component.Component
+	rules         *rules.Evaluator
 	eventCh       chan *eventSourcePair
 	readyCh       chan struct{}
 	host          component.Host
@@ -86,6 +88,15 @@ func newExtension(
 		readyCh:       make(chan struct{}),
 	}
 
+	if config.UseV2 && config.HealthRulesConfig != nil && len(config.HealthRulesConfig.Rules) > 0 {
+		hc.rules = rules.NewEvaluator(
+			config.HealthRulesConfig,
+			set.TelemetrySettings,
+			aggregator,
+			hc.ComponentStatusChanged,
+		)
+	}
+
 	// Start processing events in the background so that our status watcher doesn't
 	// block others before the extension starts.
 	go hc.eventLoop(ctx)
@@ -105,18 +116,27 @@ func (hc *healthCheckExtension) Start(ctx context.Context, host component.Host)
 		}
 	}
 
+	if hc.rules != nil {
+		return hc.rules.Start(ctx, host)
+	}
+
 	return nil
 }
 
 // Shutdown implements the component.Component interface.
 func (hc *healthCheckExtension) Shutdown(ctx context.Context) error {
+	// Stop evaluating rules before the event channel is closed
+	var err error
+	if hc.rules != nil {
+		err = hc.rules.Shutdown(ctx)
+	}
+
 	// Preemptively send the stopped event, so it can be exported before shutdown
 	componentstatus.ReportStatus(hc.host, componentstatus.NewEvent(componentstatus.StatusStopped))
 
 	close(hc.eventCh)
 	hc.aggregator.Close()
 
-	var err error
 	for _, comp := range hc.subcomponents {
 		err = multierr.Append(err, comp.Shutdown(ctx))
 	}
@@ -20,6 +20,7 @@ import (
 	"go.opentelemetry.io/collector/confmap/confmaptest"
 	"go.opentelemetry.io/collector/extension/extensiontest"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/rules"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/testutil"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/status"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/status/testhelpers"
@@ -91,6 +92,47 @@ func TestComponentStatus(t *testing.T) {
 	assert.Equal(t, componentstatus.StatusStopping, st.Status())
 }
 
+func TestHealthRules(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.HTTPConfig.Endpoint = testutil.GetAvailableLocalAddress(t)
+	cfg.GRPCConfig.NetAddr.Endpoint = testutil.GetAvailableLocalAddress(t)
+	cfg.UseV2 = true
+	cfg.HealthRulesConfig.Interval = 10 * time.Millisecond
+	cfg.HealthRulesConfig.Rules = []rules.Rule{
+		{
+			Name:       "exporters",
+			Components: &rules.ComponentsCondition{IDs: []string{"exporter:traces/out"}},
+		},
+	}
+	ext := newExtension(context.Background(), *cfg, extensiontest.NewNopSettings())
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, ext.Shutdown(context.Background())) })
+
+	traces := testhelpers.NewPipelineMetadata("traces")
+	for _, id := range traces.InstanceIDs() {
+		ext.ComponentStatusChanged(id, componentstatus.NewEvent(componentstatus.StatusStarting))
+	}
+	require.NoError(t, ext.Ready())
+	ext.ComponentStatusChanged(traces.ReceiverID, componentstatus.NewEvent(componentstatus.StatusOK))
+	ext.ComponentStatusChanged(traces.ProcessorID, componentstatus.NewEvent(componentstatus.StatusOK))
+
+	// The rule is failing while the exporter is starting
+	assert.Eventually(t, func() bool {
+		st, ok := ext.aggregator.AggregateStatus(status.ScopeExtensions, status.Verbose)
+		require.True(t, ok)
+		rule, ok := st.ComponentStatusMap["extension:healthrule/exporters"]
+		return ok && rule.Status() == componentstatus.StatusRecoverableError
+	}, time.Second, 10*time.Millisecond)
+
+	ext.ComponentStatusChanged(traces.ExporterID, componentstatus.NewEvent(componentstatus.StatusOK))
+
+	assert.Eventually(t, func() bool {
+		st, ok := ext.aggregator.AggregateStatus(status.ScopeAll, status.Concise)
+		require.True(t, ok)
+		return st.Status() == componentstatus.StatusOK
+	}, time.Second, 10*time.Millisecond)
+}
+
 func TestNotifyConfig(t *testing.T) {
 	confMap, err := confmaptest.LoadConf(
 		filepath.Join("internal", "http", "testdata", "config.yaml"),
@@ -7,6 +7,7 @@ package healthcheckv2extension // import "github.com/open-telemetry/opentelemetr
 
 import (
 	"context"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configgrpc"
@@ -17,12 +18,17 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/grpc"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/http"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/rules"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/testutil"
 )
 
 const (
 	defaultGRPCPort = 13132
 	defaultHTTPPort = 13133
+
+	defaultHealthRulesInterval = 15 * time.Second
+	// defaultMetricsEndpoint is the default Prometheus endpoint of the collector's internal telemetry.
+	defaultMetricsEndpoint = "http://localhost:8888/metrics"
 )
 
 // NewFactory creates a factory for HealthCheck extension.
@@ -64,9 +70,19 @@ func createDefaultConfig() component.Config {
 				},
 			},
 		},
+		HealthRulesConfig: &rules.Config{
+			Interval: defaultHealthRulesInterval,
+			Metrics:  healthRulesMetricsConfig(),
+		},
 	}
 }
 
+func healthRulesMetricsConfig() confighttp.ClientConfig {
+	cfg := confighttp.NewDefaultClientConfig()
+	cfg.Endpoint = defaultMetricsEndpoint
+	return cfg
+}
+
 func createExtension(ctx context.Context, set extension.Settings, cfg component.Config) (extension.Extension, error) {
 	config := cfg.(*Config)
 	return newExtension(ctx, *config, set), nil
@@ -6,6 +6,7 @@ package healthcheckv2extension
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,6 +18,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/grpc"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/http"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/rules"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/testutil"
 )
 
@@ -50,6 +52,10 @@ func TestCreateDefaultConfig(t *testing.T) {
 				},
 			},
 		},
+		HealthRulesConfig: &rules.Config{
+			Interval: 15 * time.Second,
+			Metrics:  healthRulesMetricsConfig(),
+		},
 	}, cfg)
 
 	assert.NoError(t, componenttest.CheckConfigStruct(cfg))
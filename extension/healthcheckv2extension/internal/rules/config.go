@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package rules // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/rules"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+// Statuses reported by rules that are not met.
+const (
+	StatusRecoverable = "recoverable"
+	StatusPermanent   = "permanent"
+)
+
+// Aggregations of the series selected by a metric rule.
+const (
+	AggregationSum = "sum"
+	AggregationMax = "max"
+	AggregationMin = "min"
+)
+
+// RuleType is the component type of the rules in the aggregate status, e.g. "extension:healthrule/exporters".
+var RuleType = component.MustNewType("healthrule")
+
+var (
+	errInvalidInterval    = errors.New("interval must be greater than 0")
+	errMissingMetricsURL  = errors.New("metrics endpoint required by metric rules")
+	errMissingCondition   = errors.New("exactly one of components or metric must be set")
+	errMissingComponents  = errors.New("components.ids must not be empty")
+	errMissingMetricName  = errors.New("metric.name is required")
+	errMissingMetricBound = errors.New("at least one of metric.above or metric.below must be set")
+)
+
+// Config contains the config of the health rules, which are evaluated periodically and reported
+// in the aggregate status alongside the components.
+type Config struct {
+	// Interval is how often the rules are evaluated.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// Metrics is the client for the Prometheus endpoint of the collector's internal telemetry,
+	// which metric rules are evaluated against.
+	Metrics confighttp.ClientConfig `mapstructure:"metrics"`
+
+	// Rules are the health rules.
+	Rules []Rule `mapstructure:"rules"`
+}
+
+// Rule is a health rule. It is reported as StatusOK when its condition is met, and as an error
+// with its Status otherwise.
+type Rule struct {
+	// Name identifies the rule in the aggregate status.
+	Name string `mapstructure:"name"`
+
+	// Status is the error status reported when the condition is not met, either "recoverable"
+	// (the default) or "permanent". It is subject to the component_health config like the
+	// statuses reported by components.
+	Status string `mapstructure:"status"`
+
+	// Components is a condition over the statuses of components.
+	Components *ComponentsCondition `mapstructure:"components"`
+
+	// Metric is a condition over the internal telemetry of the collector.
+	Metric *MetricCondition `mapstructure:"metric"`
+}
+
+// ComponentsCondition is met when enough of the components are healthy, that is reporting
+// StatusOK in every pipeline they are part of.
+type ComponentsCondition struct {
+	// IDs are the components as they appear in the aggregate status, e.g. "exporter:otlp/backend1".
+	IDs []string `mapstructure:"ids"`
+
+	// MinHealthy is the number of components that must be healthy. All of them by default.
+	MinHealthy int `mapstructure:"min_healthy"`
+}
+
+// MetricCondition is met when the value of a metric of the internal telemetry is within bounds.
+type MetricCondition struct {
+	// Name is the name of the metric as it is exposed by the Prometheus endpoint, e.g.
+	// "otelcol_exporter_queue_size".
+	Name string `mapstructure:"name"`
+
+	// Labels select the series of the metric having these label values.
+	Labels map[string]string `mapstructure:"labels"`
+
+	// Rate uses the per-second rate of the series, for counters, instead of their values.
+	Rate bool `mapstructure:"rate"`
+
+	// DividedBy is the name of a metric every series is divided by, matching series by labels,
+	// e.g. "otelcol_exporter_queue_capacity".
+	DividedBy string `mapstructure:"divided_by"`
+
+	// Aggregation of the selected series: "sum" (the default), "max" or "min". The value is 0 when
+	// no series are selected.
+	Aggregation string `mapstructure:"aggregation"`
+
+	// Above is the value the aggregation must be greater than.
+	Above *float64 `mapstructure:"above"`
+
+	// Below is the value the aggregation must be less than.
+	Below *float64 `mapstructure:"below"`
+}
+
+// Validate checks if the rules configuration is valid.
+func (c *Config) Validate() error {
+	if c.Interval <= 0 {
+		return errInvalidInterval
+	}
+
+	names := map[string]struct{}{}
+	hasMetricRules := false
+	for _, rule := range c.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		if _, ok := names[rule.Name]; ok {
+			return fmt.Errorf("rule %q is defined more than once", rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+		hasMetricRules = hasMetricRules || rule.Metric != nil
+	}
+
+	if hasMetricRules && c.Metrics.Endpoint == "" {
+		return errMissingMetricsURL
+	}
+	return nil
+}
+
+func (r *Rule) validate() error {
+	var id component.ID
+	if err := id.UnmarshalText([]byte(RuleType.String() + "/" + r.Name)); err != nil || r.Name == "" {
+		return errors.New("invalid name, it must be a valid component name")
+	}
+
+	switch r.Status {
+	case "", StatusRecoverable, StatusPermanent:
+	default:
+		return fmt.Errorf("unknown status %q", r.Status)
+	}
+
+	if (r.Components == nil) == (r.Metric == nil) {
+		return errMissingCondition
+	}
+	if r.Components != nil {
+		return r.Components.validate()
+	}
+	return r.Metric.validate()
+}
+
+func (c *ComponentsCondition) validate() error {
+	if len(c.IDs) == 0 {
+		return errMissingComponents
+	}
+	if c.MinHealthy < 0 || c.MinHealthy > len(c.IDs) {
+		return fmt.Errorf("components.min_healthy must be between 0 and %d", len(c.IDs))
+	}
+	return nil
+}
+
+func (c *MetricCondition) validate() error {
+	if c.Name == "" {
+		return errMissingMetricName
+	}
+	if c.Above == nil && c.Below == nil {
+		return errMissingMetricBound
+	}
+	switch c.Aggregation {
+	case "", AggregationSum, AggregationMax, AggregationMin:
+	default:
+		return fmt.Errorf("unknown metric.aggregation %q", c.Aggregation)
+	}
+	return nil
+}
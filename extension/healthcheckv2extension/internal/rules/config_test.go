@@ -0,0 +1,145 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestValidate(t *testing.T) {
+	metricsConfig := confighttp.ClientConfig{Endpoint: "http://localhost:8888/metrics"}
+
+	tests := []struct {
+		name        string
+		config      Config
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			config: Config{
+				Interval: time.Second,
+				Metrics:  metricsConfig,
+				Rules: []Rule{
+					{Name: "queue", Status: StatusPermanent, Metric: &MetricCondition{Name: "otelcol_exporter_queue_size", Below: ptr(1000.0)}},
+					{Name: "exporters", Components: &ComponentsCondition{IDs: []string{"exporter:otlp"}}},
+				},
+			},
+		},
+		{
+			name:        "invalid interval",
+			config:      Config{},
+			expectedErr: "interval must be greater than 0",
+		},
+		{
+			name: "missing metrics endpoint",
+			config: Config{
+				Interval: time.Second,
+				Rules:    []Rule{{Name: "queue", Metric: &MetricCondition{Name: "otelcol_exporter_queue_size", Below: ptr(1000.0)}}},
+			},
+			expectedErr: "metrics endpoint required by metric rules",
+		},
+		{
+			name: "invalid name",
+			config: Config{
+				Interval: time.Second,
+				Rules:    []Rule{{Name: "a b", Components: &ComponentsCondition{IDs: []string{"exporter:otlp"}}}},
+			},
+			expectedErr: `rule "a b": invalid name, it must be a valid component name`,
+		},
+		{
+			name: "duplicate name",
+			config: Config{
+				Interval: time.Second,
+				Rules: []Rule{
+					{Name: "exporters", Components: &ComponentsCondition{IDs: []string{"exporter:otlp"}}},
+					{Name: "exporters", Components: &ComponentsCondition{IDs: []string{"exporter:otlp"}}},
+				},
+			},
+			expectedErr: `rule "exporters" is defined more than once`,
+		},
+		{
+			name: "unknown status",
+			config: Config{
+				Interval: time.Second,
+				Rules:    []Rule{{Name: "exporters", Status: "fatal", Components: &ComponentsCondition{IDs: []string{"exporter:otlp"}}}},
+			},
+			expectedErr: `rule "exporters": unknown status "fatal"`,
+		},
+		{
+			name: "both conditions",
+			config: Config{
+				Interval: time.Second,
+				Metrics:  metricsConfig,
+				Rules: []Rule{{
+					Name:       "exporters",
+					Components: &ComponentsCondition{IDs: []string{"exporter:otlp"}},
+					Metric:     &MetricCondition{Name: "otelcol_exporter_queue_size", Below: ptr(1000.0)},
+				}},
+			},
+			expectedErr: `rule "exporters": exactly one of components or metric must be set`,
+		},
+		{
+			name: "missing components",
+			config: Config{
+				Interval: time.Second,
+				Rules:    []Rule{{Name: "exporters", Components: &ComponentsCondition{}}},
+			},
+			expectedErr: `rule "exporters": components.ids must not be empty`,
+		},
+		{
+			name: "invalid min healthy",
+			config: Config{
+				Interval: time.Second,
+				Rules:    []Rule{{Name: "exporters", Components: &ComponentsCondition{IDs: []string{"exporter:otlp"}, MinHealthy: 2}}},
+			},
+			expectedErr: `rule "exporters": components.min_healthy must be between 0 and 1`,
+		},
+		{
+			name: "missing metric name",
+			config: Config{
+				Interval: time.Second,
+				Metrics:  metricsConfig,
+				Rules:    []Rule{{Name: "queue", Metric: &MetricCondition{Below: ptr(1000.0)}}},
+			},
+			expectedErr: `rule "queue": metric.name is required`,
+		},
+		{
+			name: "missing metric bounds",
+			config: Config{
+				Interval: time.Second,
+				Metrics:  metricsConfig,
+				Rules:    []Rule{{Name: "queue", Metric: &MetricCondition{Name: "otelcol_exporter_queue_size"}}},
+			},
+			expectedErr: `rule "queue": at least one of metric.above or metric.below must be set`,
+		},
+		{
+			name: "unknown aggregation",
+			config: Config{
+				Interval: time.Second,
+				Metrics:  metricsConfig,
+				Rules:    []Rule{{Name: "queue", Metric: &MetricCondition{Name: "otelcol_exporter_queue_size", Aggregation: "avg", Below: ptr(1000.0)}}},
+			},
+			expectedErr: `rule "queue": unknown metric.aggregation "avg"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package rules // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/rules"
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/status"
+)
+
+// ReportFunc reports the status of a rule, as component status is reported.
+type ReportFunc func(*componentstatus.InstanceID, *componentstatus.Event)
+
+// Evaluator periodically evaluates the health rules and reports their statuses. Each rule is
+// reported as an extension named after it, so that it is part of the overall collector status.
+type Evaluator struct {
+	config     *Config
+	telemetry  component.TelemetrySettings
+	aggregator *status.Aggregator
+	report     ReportFunc
+	client     *http.Client
+	rules      []*rule
+	previous   *snapshot
+	doneCh     chan struct{}
+	wg         sync.WaitGroup
+}
+
+type rule struct {
+	Rule
+	source *componentstatus.InstanceID
+	status componentstatus.Status
+}
+
+var _ component.Component = (*Evaluator)(nil)
+
+func NewEvaluator(
+	config *Config,
+	telemetry component.TelemetrySettings,
+	aggregator *status.Aggregator,
+	report ReportFunc,
+) *Evaluator {
+	e := &Evaluator{
+		config:     config,
+		telemetry:  telemetry,
+		aggregator: aggregator,
+		report:     report,
+		doneCh:     make(chan struct{}),
+	}
+	for _, r := range config.Rules {
+		e.rules = append(e.rules, &rule{
+			Rule: r,
+			source: componentstatus.NewInstanceID(
+				component.NewIDWithName(RuleType, r.Name),
+				component.KindExtension,
+			),
+		})
+	}
+	return e
+}
+
+// Start implements the component.Component interface.
+func (e *Evaluator) Start(ctx context.Context, host component.Host) error {
+	for _, r := range e.rules {
+		if r.Metric == nil {
+			continue
+		}
+		client, err := e.config.Metrics.ToClient(ctx, host, e.telemetry)
+		if err != nil {
+			return fmt.Errorf("failed to create the internal telemetry client: %w", err)
+		}
+		e.client = client
+		break
+	}
+
+	// Rules are starting until they are evaluated for the first time.
+	for _, r := range e.rules {
+		r.status = componentstatus.StatusStarting
+		e.report(r.source, componentstatus.NewEvent(r.status))
+	}
+
+	e.wg.Add(1)
+	go e.evaluationLoop()
+	return nil
+}
+
+// Shutdown implements the component.Component interface.
+func (e *Evaluator) Shutdown(context.Context) error {
+	close(e.doneCh)
+	e.wg.Wait()
+	return nil
+}
+
+func (e *Evaluator) evaluationLoop() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		e.evaluate()
+		select {
+		case <-ticker.C:
+		case <-e.doneCh:
+			return
+		}
+	}
+}
+
+func (e *Evaluator) evaluate() {
+	var current *snapshot
+	var scrapeErr error
+	if e.client != nil {
+		current, scrapeErr = e.scrape()
+		if scrapeErr != nil {
+			e.telemetry.Logger.Debug("Failed to read the internal telemetry", zap.Error(scrapeErr))
+		}
+	}
+
+	for _, r := range e.rules {
+		var err error
+		switch {
+		case r.Components != nil:
+			err = r.Components.check(e.aggregator)
+		case scrapeErr != nil:
+			err = scrapeErr
+		default:
+			value, ok := r.Metric.value(current, e.previous)
+			if !ok {
+				continue
+			}
+			err = r.Metric.check(value)
+		}
+		e.record(r, err)
+	}
+
+	if scrapeErr == nil {
+		e.previous = current
+	}
+}
+
+// record reports the status of the rule when it has changed. Errors are not reported again while
+// the rule is failing, so that the recovery duration applies from the first failure.
+func (e *Evaluator) record(r *rule, err error) {
+	var ev *componentstatus.Event
+	switch {
+	case err == nil:
+		ev = componentstatus.NewEvent(componentstatus.StatusOK)
+	case r.Status == StatusPermanent:
+		ev = componentstatus.NewPermanentErrorEvent(err)
+	default:
+		ev = componentstatus.NewRecoverableErrorEvent(err)
+	}
+
+	if ev.Status() == r.status {
+		return
+	}
+	r.status = ev.Status()
+	e.report(r.source, ev)
+}
+
+func (e *Evaluator) scrape() (*snapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.config.Interval)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.config.Metrics.Endpoint, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the internal telemetry: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read the internal telemetry: %s", resp.Status)
+	}
+
+	snap, err := parseMetrics(resp.Body, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the internal telemetry: %w", err)
+	}
+	return snap, nil
+}
+
+// check returns an error when less than MinHealthy of the components are healthy.
+func (c *ComponentsCondition) check(aggregator *status.Aggregator) error {
+	st, _ := aggregator.AggregateStatus(status.ScopeAll, status.Verbose)
+
+	// A component is healthy when it is OK in every pipeline it is part of.
+	healthy := map[string]bool{}
+	for _, pipelineStatus := range st.ComponentStatusMap {
+		for key, componentStatus := range pipelineStatus.ComponentStatusMap {
+			ok, seen := healthy[key]
+			healthy[key] = componentStatus.Status() == componentstatus.StatusOK && (ok || !seen)
+		}
+	}
+
+	count := 0
+	for _, id := range c.IDs {
+		if healthy[id] {
+			count++
+		}
+	}
+
+	minHealthy := c.MinHealthy
+	if minHealthy == 0 {
+		minHealthy = len(c.IDs)
+	}
+	if count < minHealthy {
+		return fmt.Errorf("%d of %d components are healthy, expected at least %d", count, len(c.IDs), minHealthy)
+	}
+	return nil
+}
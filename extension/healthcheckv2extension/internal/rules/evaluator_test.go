@@ -0,0 +1,201 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/confighttp"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/status"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/status/testhelpers"
+)
+
+type recordedEvent struct {
+	rule   string
+	status componentstatus.Status
+	err    string
+}
+
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []recordedEvent
+}
+
+func (r *eventRecorder) report(source *componentstatus.InstanceID, ev *componentstatus.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	recorded := recordedEvent{rule: source.ComponentID().Name(), status: ev.Status()}
+	if ev.Err() != nil {
+		recorded.err = ev.Err().Error()
+	}
+	r.events = append(r.events, recorded)
+}
+
+func (r *eventRecorder) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.events)
+}
+
+func (r *eventRecorder) takeEvents() []recordedEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.events
+	r.events = nil
+	return events
+}
+
+func newMetricsServer(t *testing.T, metrics *atomic.Value) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		body := metrics.Load().(string)
+		if body == "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestEvaluatorMetricRules(t *testing.T) {
+	var metrics atomic.Value
+	metrics.Store(testMetrics)
+	srv := newMetricsServer(t, &metrics)
+
+	config := &Config{
+		Interval: time.Hour,
+		Metrics:  confighttp.ClientConfig{Endpoint: srv.URL},
+		Rules: []Rule{
+			{
+				Name: "sending_queue",
+				Metric: &MetricCondition{
+					Name:        "otelcol_exporter_queue_size",
+					DividedBy:   "otelcol_exporter_queue_capacity",
+					Aggregation: AggregationMax,
+					Below:       ptr(0.8),
+				},
+			},
+			{
+				Name:   "sent_spans",
+				Status: StatusPermanent,
+				Metric: &MetricCondition{
+					Name:  "otelcol_exporter_sent_spans_total",
+					Rate:  true,
+					Above: ptr(0.0),
+				},
+			},
+		},
+	}
+	recorder := &eventRecorder{}
+	evaluator := NewEvaluator(config, componenttest.NewNopTelemetrySettings(), status.NewAggregator(status.PriorityPermanent), recorder.report)
+
+	// Rules are starting until evaluated, and rate rules need two evaluations.
+	require.NoError(t, evaluator.Start(context.Background(), componenttest.NewNopHost()))
+	require.Eventually(t, func() bool {
+		return recorder.len() >= 3
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, evaluator.Shutdown(context.Background()))
+	assert.Equal(t, []recordedEvent{
+		{rule: "sending_queue", status: componentstatus.StatusStarting},
+		{rule: "sent_spans", status: componentstatus.StatusStarting},
+		{
+			rule:   "sending_queue",
+			status: componentstatus.StatusRecoverableError,
+			err:    "max(otelcol_exporter_queue_size / otelcol_exporter_queue_capacity) is 0.9, expected below 0.8",
+		},
+	}, recorder.takeEvents())
+
+	// No spans were sent since the previous evaluation
+	evaluator.evaluate()
+	assert.Equal(t, []recordedEvent{
+		{rule: "sent_spans", status: componentstatus.StatusPermanentError, err: "sum(rate(otelcol_exporter_sent_spans_total)) is 0, expected above 0"},
+	}, recorder.takeEvents())
+
+	// Errors are only reported when the status changes
+	evaluator.evaluate()
+	assert.Empty(t, recorder.takeEvents())
+
+	metrics.Store(`# TYPE otelcol_exporter_queue_capacity gauge
+otelcol_exporter_queue_capacity{exporter="otlp/2"} 100
+# TYPE otelcol_exporter_queue_size gauge
+otelcol_exporter_queue_size{exporter="otlp/2"} 10
+# TYPE otelcol_exporter_sent_spans_total counter
+otelcol_exporter_sent_spans_total{exporter="otlp/1"} 1000
+`)
+	evaluator.evaluate()
+	assert.Equal(t, []recordedEvent{
+		{rule: "sending_queue", status: componentstatus.StatusOK},
+		{rule: "sent_spans", status: componentstatus.StatusOK},
+	}, recorder.takeEvents())
+
+	// The internal telemetry can't be read
+	metrics.Store("")
+	evaluator.evaluate()
+	assert.Equal(t, []recordedEvent{
+		{rule: "sending_queue", status: componentstatus.StatusRecoverableError, err: "failed to read the internal telemetry: 503 Service Unavailable"},
+		{rule: "sent_spans", status: componentstatus.StatusPermanentError, err: "failed to read the internal telemetry: 503 Service Unavailable"},
+	}, recorder.takeEvents())
+}
+
+func TestEvaluatorComponentsRule(t *testing.T) {
+	aggregator := status.NewAggregator(status.PriorityPermanent)
+	traces := testhelpers.NewPipelineMetadata("traces")
+	metrics := testhelpers.NewPipelineMetadata("metrics")
+	// The receivers are part of both pipelines
+	receiverID := componentstatus.NewInstanceID(component.MustNewID("otlp"), component.KindReceiver).
+		WithPipelines(traces.PipelineID, metrics.PipelineID)
+
+	config := &Config{
+		Interval: time.Hour,
+		Rules: []Rule{
+			{
+				Name:       "exporters",
+				Components: &ComponentsCondition{IDs: []string{"exporter:traces/out", "exporter:metrics/out"}, MinHealthy: 1},
+			},
+			{
+				Name:       "receivers",
+				Components: &ComponentsCondition{IDs: []string{"receiver:otlp"}},
+			},
+		},
+	}
+	recorder := &eventRecorder{}
+	evaluator := NewEvaluator(config, componenttest.NewNopTelemetrySettings(), aggregator, recorder.report)
+
+	// Components that have not reported are not healthy
+	evaluator.evaluate()
+	assert.Equal(t, []recordedEvent{
+		{rule: "exporters", status: componentstatus.StatusRecoverableError, err: "0 of 2 components are healthy, expected at least 1"},
+		{rule: "receivers", status: componentstatus.StatusRecoverableError, err: "0 of 1 components are healthy, expected at least 1"},
+	}, recorder.takeEvents())
+
+	aggregator.RecordStatus(traces.ExporterID, componentstatus.NewEvent(componentstatus.StatusOK))
+	aggregator.RecordStatus(metrics.ExporterID, componentstatus.NewRecoverableErrorEvent(assert.AnError))
+	aggregator.RecordStatus(receiverID, componentstatus.NewEvent(componentstatus.StatusOK))
+	evaluator.evaluate()
+	assert.Equal(t, []recordedEvent{
+		{rule: "exporters", status: componentstatus.StatusOK},
+		{rule: "receivers", status: componentstatus.StatusOK},
+	}, recorder.takeEvents())
+
+	aggregator.RecordStatus(traces.ExporterID, componentstatus.NewRecoverableErrorEvent(assert.AnError))
+	evaluator.evaluate()
+	assert.Equal(t, []recordedEvent{
+		{rule: "exporters", status: componentstatus.StatusRecoverableError, err: "0 of 2 components are healthy, expected at least 1"},
+	}, recorder.takeEvents())
+}
@@ -0,0 +1,190 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package rules // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckv2extension/internal/rules"
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// series is a series of a metric of the internal telemetry.
+type series struct {
+	labels map[string]string
+	value  float64
+}
+
+// snapshot holds the series of every metric read from the Prometheus endpoint at a point in time,
+// keyed by metric name then by label set.
+type snapshot struct {
+	time    time.Time
+	metrics map[string]map[string]series
+}
+
+func parseMetrics(r io.Reader, t time.Time) (*snapshot, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &snapshot{time: t, metrics: make(map[string]map[string]series, len(families))}
+	for name, family := range families {
+		metric := make(map[string]series, len(family.GetMetric()))
+		for _, m := range family.GetMetric() {
+			value, ok := metricValue(family.GetType(), m)
+			if !ok {
+				continue
+			}
+			labels := make(map[string]string, len(m.GetLabel()))
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			metric[seriesKey(labels)] = series{labels: labels, value: value}
+		}
+		snap.metrics[name] = metric
+	}
+	return snap, nil
+}
+
+// metricValue returns the value of counters, gauges and untyped metrics. Other types are ignored.
+func metricValue(metricType dto.MetricType, m *dto.Metric) (float64, bool) {
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		return m.GetCounter().GetValue(), true
+	case dto.MetricType_GAUGE:
+		return m.GetGauge().GetValue(), true
+	case dto.MetricType_UNTYPED:
+		return m.GetUntyped().GetValue(), true
+	default:
+		return 0, false
+	}
+}
+
+func seriesKey(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for name, value := range labels {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// value returns the aggregated value of the condition. The boolean return value is false when the
+// value can't be computed yet, i.e. rates before a previous snapshot is available.
+func (c *MetricCondition) value(current, previous *snapshot) (float64, bool) {
+	if c.Rate && previous == nil {
+		return 0, false
+	}
+
+	numerators := c.seriesValues(c.Name, current, previous)
+	var denominators map[string]float64
+	if c.DividedBy != "" {
+		denominators = c.seriesValues(c.DividedBy, current, previous)
+	}
+
+	var values []float64
+	for key, value := range numerators {
+		if denominators != nil {
+			denominator, ok := denominators[key]
+			if !ok || denominator == 0 {
+				continue
+			}
+			value /= denominator
+		}
+		values = append(values, value)
+	}
+	return aggregate(c.Aggregation, values), true
+}
+
+// seriesValues returns the values, or the rates, of the series of the metric selected by the labels
+// of the condition.
+func (c *MetricCondition) seriesValues(name string, current, previous *snapshot) map[string]float64 {
+	values := map[string]float64{}
+	for key, s := range current.metrics[name] {
+		if !matchLabels(s.labels, c.Labels) {
+			continue
+		}
+		if !c.Rate {
+			values[key] = s.value
+			continue
+		}
+
+		prev, ok := previous.metrics[name][key]
+		elapsed := current.time.Sub(previous.time).Seconds()
+		if !ok || elapsed <= 0 {
+			continue
+		}
+		increase := s.value - prev.value
+		if increase < 0 {
+			// The counter has been reset.
+			increase = s.value
+		}
+		values[key] = increase / elapsed
+	}
+	return values
+}
+
+func matchLabels(labels, selector map[string]string) bool {
+	for name, value := range selector {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func aggregate(aggregation string, values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	result := values[0]
+	for _, value := range values[1:] {
+		switch aggregation {
+		case AggregationMax:
+			result = max(result, value)
+		case AggregationMin:
+			result = min(result, value)
+		default:
+			result += value
+		}
+	}
+	return result
+}
+
+// check returns an error when the value is out of the bounds of the condition.
+func (c *MetricCondition) check(value float64) error {
+	if c.Above != nil && value <= *c.Above {
+		return fmt.Errorf("%s is %g, expected above %g", c.description(), value, *c.Above)
+	}
+	if c.Below != nil && value >= *c.Below {
+		return fmt.Errorf("%s is %g, expected below %g", c.description(), value, *c.Below)
+	}
+	return nil
+}
+
+// description describes the value of the condition in errors, e.g. "max(rate(otelcol_exporter_sent_spans_total))".
+func (c *MetricCondition) description() string {
+	desc := c.Name
+	if c.Rate {
+		desc = "rate(" + desc + ")"
+	}
+	if c.DividedBy != "" {
+		divisor := c.DividedBy
+		if c.Rate {
+			divisor = "rate(" + divisor + ")"
+		}
+		desc += " / " + divisor
+	}
+	aggregation := c.Aggregation
+	if aggregation == "" {
+		aggregation = AggregationSum
+	}
+	return aggregation + "(" + desc + ")"
+}
@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMetrics = `# TYPE otelcol_exporter_queue_capacity gauge
+otelcol_exporter_queue_capacity{exporter="otlp/1"} 1000
+otelcol_exporter_queue_capacity{exporter="otlp/2"} 100
+# TYPE otelcol_exporter_queue_size gauge
+otelcol_exporter_queue_size{exporter="otlp/1"} 100
+otelcol_exporter_queue_size{exporter="otlp/2"} 90
+otelcol_exporter_queue_size{exporter="otlp/3"} 10
+# TYPE otelcol_exporter_sent_spans_total counter
+otelcol_exporter_sent_spans_total{exporter="otlp/1"} 100
+otelcol_exporter_sent_spans_total{exporter="otlp/2"} 50
+# TYPE otelcol_exporter_send_latency histogram
+otelcol_exporter_send_latency_bucket{le="+Inf"} 1
+otelcol_exporter_send_latency_sum 1
+otelcol_exporter_send_latency_count 1
+`
+
+func parseTestMetrics(t *testing.T, metrics string, ts time.Time) *snapshot {
+	t.Helper()
+
+	snap, err := parseMetrics(strings.NewReader(metrics), ts)
+	require.NoError(t, err)
+	return snap
+}
+
+func TestParseMetrics(t *testing.T) {
+	snap := parseTestMetrics(t, testMetrics, time.Now())
+
+	assert.Equal(t, map[string]series{
+		"exporter=otlp/1": {labels: map[string]string{"exporter": "otlp/1"}, value: 100},
+		"exporter=otlp/2": {labels: map[string]string{"exporter": "otlp/2"}, value: 50},
+	}, snap.metrics["otelcol_exporter_sent_spans_total"])
+	// histograms are ignored
+	assert.Empty(t, snap.metrics["otelcol_exporter_send_latency"])
+
+	_, err := parseMetrics(strings.NewReader("invalid metrics"), time.Now())
+	assert.Error(t, err)
+}
+
+func TestMetricConditionValue(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	previous := parseTestMetrics(t, testMetrics, start)
+	current := parseTestMetrics(t, strings.NewReplacer(
+		`otelcol_exporter_sent_spans_total{exporter="otlp/1"} 100`, `otelcol_exporter_sent_spans_total{exporter="otlp/1"} 200`,
+		`otelcol_exporter_sent_spans_total{exporter="otlp/2"} 50`, `otelcol_exporter_sent_spans_total{exporter="otlp/2"} 10`,
+	).Replace(testMetrics), start.Add(10*time.Second))
+
+	tests := []struct {
+		name      string
+		condition MetricCondition
+		previous  *snapshot
+		expected  float64
+		ok        bool
+	}{
+		{
+			name:      "sum",
+			condition: MetricCondition{Name: "otelcol_exporter_queue_size"},
+			expected:  200,
+			ok:        true,
+		},
+		{
+			name:      "max",
+			condition: MetricCondition{Name: "otelcol_exporter_queue_size", Aggregation: AggregationMax},
+			expected:  100,
+			ok:        true,
+		},
+		{
+			name:      "min",
+			condition: MetricCondition{Name: "otelcol_exporter_queue_size", Aggregation: AggregationMin},
+			expected:  10,
+			ok:        true,
+		},
+		{
+			name:      "labels",
+			condition: MetricCondition{Name: "otelcol_exporter_queue_size", Labels: map[string]string{"exporter": "otlp/2"}},
+			expected:  90,
+			ok:        true,
+		},
+		{
+			name:      "no series",
+			condition: MetricCondition{Name: "otelcol_exporter_queue_size", Labels: map[string]string{"exporter": "debug"}},
+			expected:  0,
+			ok:        true,
+		},
+		{
+			name:      "ratio",
+			condition: MetricCondition{Name: "otelcol_exporter_queue_size", DividedBy: "otelcol_exporter_queue_capacity", Aggregation: AggregationMax},
+			expected:  0.9,
+			ok:        true,
+		},
+		{
+			name:      "rate without previous snapshot",
+			condition: MetricCondition{Name: "otelcol_exporter_sent_spans_total", Rate: true},
+		},
+		{
+			// the counter of otlp/2 has been reset
+			name:      "rate",
+			condition: MetricCondition{Name: "otelcol_exporter_sent_spans_total", Rate: true},
+			previous:  previous,
+			expected:  11,
+			ok:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := tt.condition.value(current, tt.previous)
+			assert.Equal(t, tt.ok, ok)
+			assert.InDelta(t, tt.expected, value, 1e-9)
+		})
+	}
+}
+
+func TestMetricConditionCheck(t *testing.T) {
+	condition := MetricCondition{
+		Name:        "otelcol_exporter_queue_size",
+		DividedBy:   "otelcol_exporter_queue_capacity",
+		Aggregation: AggregationMax,
+		Above:       ptr(0.0),
+		Below:       ptr(0.8),
+	}
+
+	assert.NoError(t, condition.check(0.5))
+	assert.EqualError(t, condition.check(0), "max(otelcol_exporter_queue_size / otelcol_exporter_queue_capacity) is 0, expected above 0")
+	assert.EqualError(t, condition.check(0.9), "max(otelcol_exporter_queue_size / otelcol_exporter_queue_capacity) is 0.9, expected below 0.8")
+
+	condition = MetricCondition{Name: "otelcol_exporter_sent_spans_total", Rate: true, Above: ptr(0.0)}
+	assert.EqualError(t, condition.check(0), "sum(rate(otelcol_exporter_sent_spans_total)) is 0, expected above 0")
+}
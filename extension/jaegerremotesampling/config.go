@@ -7,14 +7,16 @@ import (
 	"errors"
 	"time"
 
+	"github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider/adaptive"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configgrpc"
 	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/confmap"
 )
 
 var (
-	errTooManySources     = errors.New("too many sources specified, has to be either 'file' or 'remote'")
-	errNoSources          = errors.New("no sources specified, has to be either 'file' or 'remote'")
+	errTooManySources     = errors.New("too many sources specified, has to be either 'file', 'remote' or 'adaptive'")
+	errNoSources          = errors.New("no sources specified, has to be either 'file', 'remote' or 'adaptive'")
 	errAtLeastOneProtocol = errors.New("no protocols selected to serve the strategies, use 'grpc', 'http', or both")
 )
 
@@ -24,7 +26,7 @@ type Config struct {
 	HTTPServerConfig *confighttp.ServerConfig `mapstructure:"http"`
 	GRPCServerConfig *configgrpc.ServerConfig `mapstructure:"grpc"`
 
-	// Source configures the source for the strategies file. One of `remote` or `file` has to be specified.
+	// Source configures the source for the strategies file. One of `remote`, `file` or `adaptive` has to be specified.
 	Source Source `mapstructure:"source"`
 }
 
@@ -37,9 +39,37 @@ type Source struct {
 
 	// ReloadInterval determines the periodicity to refresh the strategies
 	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+
+	// Adaptive calculates the strategies from the throughput of the root spans recorded by the
+	// adaptive_sampling processor
+	Adaptive *AdaptiveConfig `mapstructure:"adaptive"`
+}
+
+// AdaptiveConfig configures the calculation of per-operation sampling probabilities from the
+// observed throughput, as done by Jaeger's adaptive sampling.
+type AdaptiveConfig struct {
+	// StorageID is the storage extension used to share the throughput and the probabilities between replicas.
+	// They are kept in memory if it is not set, in which case every replica calculates its own probabilities.
+	StorageID *component.ID `mapstructure:"storage"`
+
+	adaptive.Options `mapstructure:",squash"`
 }
 
-var _ component.Config = (*Config)(nil)
+var (
+	_ component.Config    = (*Config)(nil)
+	_ confmap.Unmarshaler = (*Config)(nil)
+)
+
+const adaptiveKey = "source::adaptive"
+
+// Unmarshal a confmap.Conf into the config struct, using the default adaptive sampling settings
+// for the ones that are not set.
+func (cfg *Config) Unmarshal(conf *confmap.Conf) error {
+	if conf.IsSet(adaptiveKey) && cfg.Source.Adaptive == nil {
+		cfg.Source.Adaptive = &AdaptiveConfig{Options: adaptive.DefaultOptions()}
+	}
+	return conf.Unmarshal(cfg)
+}
 
 // Validate checks if the extension configuration is valid
 func (cfg *Config) Validate() error {
@@ -47,13 +77,49 @@ func (cfg *Config) Validate() error {
 		return errAtLeastOneProtocol
 	}
 
-	if cfg.Source.File != "" && cfg.Source.Remote != nil {
+	sources := 0
+	if cfg.Source.File != "" {
+		sources++
+	}
+	if cfg.Source.Remote != nil {
+		sources++
+	}
+	if cfg.Source.Adaptive != nil {
+		sources++
+	}
+
+	if sources > 1 {
 		return errTooManySources
 	}
 
-	if cfg.Source.File == "" && cfg.Source.Remote == nil {
+	if sources == 0 {
 		return errNoSources
 	}
 
 	return nil
 }
+
+// Validate checks if the adaptive sampling configuration is valid
+func (cfg *AdaptiveConfig) Validate() error {
+	if cfg.TargetSamplesPerSecond <= 0 {
+		return errors.New("'target_samples_per_second' must be positive")
+	}
+	if cfg.CalculationInterval <= 0 {
+		return errors.New("'calculation_interval' must be positive")
+	}
+	if cfg.AggregationBuckets <= 0 || cfg.BucketsForCalculation <= 0 {
+		return errors.New("'aggregation_buckets' and 'calculation_buckets' must be positive")
+	}
+	if cfg.BucketsForCalculation > cfg.AggregationBuckets {
+		return errors.New("'calculation_buckets' cannot be larger than 'aggregation_buckets'")
+	}
+	for _, probability := range []float64{cfg.InitialSamplingProbability, cfg.MinSamplingProbability} {
+		if probability <= 0 || probability > 1 {
+			return errors.New("'initial_sampling_probability' and 'min_sampling_probability' must be in (0, 1]")
+		}
+	}
+	if cfg.LeaderLeaseRefreshInterval <= 0 || cfg.FollowerLeaseRefreshInterval <= 0 {
+		return errors.New("'leader_lease_refresh_interval' and 'follower_lease_refresh_interval' must be positive")
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider/adaptive"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
@@ -55,6 +56,26 @@ func TestLoadConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "adaptive"),
+			expected: &Config{
+				HTTPServerConfig: &confighttp.ServerConfig{Endpoint: "localhost:5778"},
+				GRPCServerConfig: &configgrpc.ServerConfig{NetAddr: confignet.AddrConfig{
+					Endpoint:  "localhost:14250",
+					Transport: confignet.TransportTypeTCP,
+				}},
+				Source: Source{
+					Adaptive: func() *AdaptiveConfig {
+						cfg := &AdaptiveConfig{Options: adaptive.DefaultOptions()}
+						storageID := component.MustNewIDWithName("redis_storage", "sampling")
+						cfg.StorageID = &storageID
+						cfg.TargetSamplesPerSecond = 10
+						cfg.CalculationInterval = 30 * time.Second
+						return cfg
+					}(),
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.id.String(), func(t *testing.T) {
@@ -100,6 +121,17 @@ func TestValidate(t *testing.T) {
 			},
 			expected: errTooManySources,
 		},
+		{
+			desc: "too many sources with adaptive",
+			cfg: Config{
+				GRPCServerConfig: &configgrpc.ServerConfig{},
+				Source: Source{
+					File:     "/tmp/some-file",
+					Adaptive: &AdaptiveConfig{Options: adaptive.DefaultOptions()},
+				},
+			},
+			expected: errTooManySources,
+		},
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
@@ -108,3 +140,53 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateAdaptive(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		modify   func(*AdaptiveConfig)
+		expected string
+	}{
+		{
+			desc:   "defaults",
+			modify: func(*AdaptiveConfig) {},
+		},
+		{
+			desc:     "no target samples",
+			modify:   func(cfg *AdaptiveConfig) { cfg.TargetSamplesPerSecond = 0 },
+			expected: "'target_samples_per_second' must be positive",
+		},
+		{
+			desc:     "no calculation interval",
+			modify:   func(cfg *AdaptiveConfig) { cfg.CalculationInterval = 0 },
+			expected: "'calculation_interval' must be positive",
+		},
+		{
+			desc:     "more calculation buckets than aggregation buckets",
+			modify:   func(cfg *AdaptiveConfig) { cfg.BucketsForCalculation = cfg.AggregationBuckets + 1 },
+			expected: "'calculation_buckets' cannot be larger than 'aggregation_buckets'",
+		},
+		{
+			desc:     "probability out of range",
+			modify:   func(cfg *AdaptiveConfig) { cfg.MinSamplingProbability = 2 },
+			expected: "'initial_sampling_probability' and 'min_sampling_probability' must be in (0, 1]",
+		},
+		{
+			desc:     "no lease refresh interval",
+			modify:   func(cfg *AdaptiveConfig) { cfg.LeaderLeaseRefreshInterval = 0 },
+			expected: "'leader_lease_refresh_interval' and 'follower_lease_refresh_interval' must be positive",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			cfg := &AdaptiveConfig{Options: adaptive.DefaultOptions()}
+			tC.modify(cfg)
+			err := cfg.Validate()
+			if tC.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tC.expected)
+			}
+		})
+	}
+}
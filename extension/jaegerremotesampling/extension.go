@@ -5,32 +5,47 @@ package jaegerremotesampling // import "github.com/open-telemetry/opentelemetry-
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
+	"github.com/jaegertracing/jaeger/pkg/distributedlock"
+	"github.com/jaegertracing/jaeger/pkg/hostname"
+	"github.com/jaegertracing/jaeger/pkg/metrics"
+	"github.com/jaegertracing/jaeger/plugin/sampling/leaderelection"
+	"github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider/adaptive"
 	"github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider/static"
+	"github.com/jaegertracing/jaeger/plugin/storage/memory"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/jaegerremotesampling/internal"
 )
 
+// samplingLock is the resource of the lock held by the replica calculating the adaptive sampling probabilities.
+const samplingLock = "sampling_lock"
+
 var _ extension.Extension = (*jrsExtension)(nil)
 
 type jrsExtension struct {
+	id        component.ID
 	cfg       *Config
 	telemetry component.TelemetrySettings
 
 	httpServer    component.Component
 	grpcServer    component.Component
 	samplingStore samplingstrategy.Provider
+	aggregator    samplingstrategy.Aggregator
 
 	closers []func() error
 }
 
-func newExtension(cfg *Config, telemetry component.TelemetrySettings) *jrsExtension {
+func newExtension(id component.ID, cfg *Config, telemetry component.TelemetrySettings) *jrsExtension {
 	jrse := &jrsExtension{
+		id:        id,
 		cfg:       cfg,
 		telemetry: telemetry,
 	}
@@ -42,6 +57,7 @@ func (jrse *jrsExtension) Start(ctx context.Context, host component.Host) error
 	// source of the sampling config:
 	// - remote (gRPC)
 	// - local file
+	// - adaptive
 	// we can then use a simplified logic here to assign the appropriate store
 	if jrse.cfg.Source.File != "" {
 		opts := static.Options{
@@ -73,6 +89,12 @@ func (jrse *jrsExtension) Start(ctx context.Context, host component.Host) error
 		jrse.samplingStore = remoteStore
 	}
 
+	if jrse.cfg.Source.Adaptive != nil {
+		if err := jrse.startAdaptive(ctx, host); err != nil {
+			return fmt.Errorf("failed to create the adaptive strategy store: %w", err)
+		}
+	}
+
 	if jrse.cfg.HTTPServerConfig != nil {
 		httpServer, err := internal.NewHTTP(jrse.telemetry, *jrse.cfg.HTTPServerConfig, jrse.samplingStore)
 		if err != nil {
@@ -100,6 +122,70 @@ func (jrse *jrsExtension) Start(ctx context.Context, host component.Host) error
 	return nil
 }
 
+// startAdaptive starts calculating the sampling probabilities from the throughput recorded by the adaptive
+// sampling processors. The throughput and the probabilities are kept in memory, unless a storage extension is
+// configured to share them between the replicas of the collector, which then elect the one calculating them.
+func (jrse *jrsExtension) startAdaptive(ctx context.Context, host component.Host) error {
+	cfg := jrse.cfg.Source.Adaptive
+
+	var store samplingstore.Store
+	var lock distributedlock.Lock
+	if cfg.StorageID != nil {
+		client, err := getStorageClient(ctx, host, *cfg.StorageID, jrse.id)
+		if err != nil {
+			return err
+		}
+		jrse.closers = append(jrse.closers, func() error {
+			return client.Close(context.Background())
+		})
+		participantName, err := hostname.AsIdentifier()
+		if err != nil {
+			return err
+		}
+		store, lock = internal.NewStorageSamplingStore(client, participantName, cfg.AggregationBuckets)
+	} else {
+		store, lock = memory.NewSamplingStore(cfg.AggregationBuckets), internal.NewLocalLock()
+	}
+
+	participant := leaderelection.NewElectionParticipant(lock, samplingLock, leaderelection.ElectionParticipantOptions{
+		LeaderLeaseRefreshInterval:   cfg.LeaderLeaseRefreshInterval,
+		FollowerLeaseRefreshInterval: cfg.FollowerLeaseRefreshInterval,
+		Logger:                       jrse.telemetry.Logger,
+	})
+	if err := participant.Start(); err != nil {
+		return err
+	}
+
+	provider := adaptive.NewProvider(cfg.Options, jrse.telemetry.Logger, participant, store)
+	if err := provider.Start(); err != nil {
+		return errors.Join(err, participant.Close())
+	}
+
+	aggregator, err := adaptive.NewAggregator(cfg.Options, jrse.telemetry.Logger, metrics.NullFactory, participant, store)
+	if err != nil {
+		return errors.Join(err, provider.Close(), participant.Close())
+	}
+	aggregator.Start()
+
+	// the storage client, if any, is closed last, as the others write to it until they are closed
+	jrse.closers = append([]func() error{aggregator.Close, provider.Close, participant.Close}, jrse.closers...)
+	jrse.samplingStore = provider
+	jrse.aggregator = aggregator
+	return nil
+}
+
+func getStorageClient(ctx context.Context, host component.Host, storageID component.ID, id component.ID) (storage.Client, error) {
+	ext, ok := host.GetExtensions()[storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %s not found", storageID)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %s is not a storage extension", storageID)
+	}
+	return storageExt.GetClient(ctx, component.KindExtension, id, "")
+}
+
 func (jrse *jrsExtension) Shutdown(ctx context.Context) error {
 	// we probably don't want to break whenever an error occurs, we want to continue and close the other resources
 	if jrse.httpServer != nil {
@@ -6,19 +6,24 @@ package jaegerremotesampling
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider/adaptive"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config/configgrpc"
 	"go.opentelemetry.io/collector/config/configopaque"
 	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
@@ -27,7 +32,7 @@ func TestNewExtension(t *testing.T) {
 	// test
 	cfg := testConfig()
 	cfg.Source.File = filepath.Join("testdata", "strategy.json")
-	e := newExtension(cfg, componenttest.NewNopTelemetrySettings())
+	e := newExtension(component.MustNewID("jaegerremotesampling"), cfg, componenttest.NewNopTelemetrySettings())
 
 	// verify
 	assert.NotNil(t, e)
@@ -38,7 +43,7 @@ func TestStartAndShutdownLocalFile(t *testing.T) {
 	cfg := testConfig()
 	cfg.Source.File = filepath.Join("testdata", "strategy.json")
 
-	e := newExtension(cfg, componenttest.NewNopTelemetrySettings())
+	e := newExtension(component.MustNewID("jaegerremotesampling"), cfg, componenttest.NewNopTelemetrySettings())
 	require.NotNil(t, e)
 	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
 
@@ -110,7 +115,7 @@ func TestRemote(t *testing.T) {
 			}
 
 			// create the extension
-			e := newExtension(cfg, componenttest.NewNopTelemetrySettings())
+			e := newExtension(component.MustNewID("jaegerremotesampling"), cfg, componenttest.NewNopTelemetrySettings())
 			require.NotNil(t, e)
 
 			// start the server
@@ -143,6 +148,122 @@ func TestRemote(t *testing.T) {
 	}
 }
 
+func TestStartAndShutdownAdaptive(t *testing.T) {
+	storageID := component.MustNewID("test_storage")
+	for _, tc := range []struct {
+		name      string
+		storageID *component.ID
+		err       string
+	}{
+		{
+			name: "in memory",
+		},
+		{
+			name:      "shared storage",
+			storageID: &storageID,
+		},
+		{
+			name:      "missing storage",
+			storageID: func() *component.ID { id := component.MustNewID("missing"); return &id }(),
+			err:       "failed to create the adaptive strategy store: storage extension missing not found",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.GRPCServerConfig = nil
+			cfg.Source.Adaptive = &AdaptiveConfig{StorageID: tc.storageID, Options: adaptive.DefaultOptions()}
+			cfg.Source.Adaptive.InitialSamplingProbability = 0.25
+			storageExt := &testStorageExtension{values: map[string][]byte{}}
+			host := &testHost{extensions: map[component.ID]component.Component{storageID: storageExt}}
+
+			id := component.MustNewID("jaegerremotesampling")
+			e := newExtension(id, cfg, componenttest.NewNopTelemetrySettings())
+			host.extensions[id] = e
+			err := e.Start(context.Background(), host)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				assert.NoError(t, e.Shutdown(context.Background()))
+				return
+			}
+			require.NoError(t, err)
+
+			// the strategies use the initial probability until probabilities are calculated
+			resp, err := http.Get("http://127.0.0.1:5778/sampling?service=foo")
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.NoError(t, resp.Body.Close())
+			assert.Contains(t, string(body), `"defaultSamplingProbability":0.25`)
+
+			recorder, err := GetThroughputRecorder(host, id)
+			require.NoError(t, err)
+			assert.NotNil(t, recorder)
+
+			assert.NoError(t, e.Shutdown(context.Background()))
+			if tc.storageID != nil {
+				assert.True(t, storageExt.closed)
+			}
+		})
+	}
+}
+
+type testHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h *testHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+// testStorageExtension is a storage extension keeping its values in a map.
+type testStorageExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+	mu     sync.Mutex
+	values map[string][]byte
+	closed bool
+}
+
+func (s *testStorageExtension) GetClient(context.Context, component.Kind, component.ID, string) (storage.Client, error) {
+	return s, nil
+}
+
+func (s *testStorageExtension) Get(ctx context.Context, key string) ([]byte, error) {
+	op := storage.GetOperation(key)
+	err := s.Batch(ctx, op)
+	return op.Value, err
+}
+
+func (s *testStorageExtension) Set(ctx context.Context, key string, value []byte) error {
+	return s.Batch(ctx, storage.SetOperation(key, value))
+}
+
+func (s *testStorageExtension) Delete(ctx context.Context, key string) error {
+	return s.Batch(ctx, storage.DeleteOperation(key))
+}
+
+func (s *testStorageExtension) Batch(_ context.Context, ops ...storage.Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value = s.values[op.Key]
+		case storage.Set:
+			s.values[op.Key] = op.Value
+		case storage.Delete:
+			delete(s.values, op.Key)
+		}
+	}
+	return nil
+}
+
+func (s *testStorageExtension) Close(context.Context) error {
+	s.closed = true
+	return nil
+}
+
 type samplingServer struct {
 	api_v2.UnimplementedSamplingManagerServer
 	observedCalls []observedCall
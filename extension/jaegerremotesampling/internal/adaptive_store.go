@@ -0,0 +1,365 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/jaegerremotesampling/internal"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/model"
+	"github.com/jaegertracing/jaeger/pkg/distributedlock"
+	"github.com/jaegertracing/jaeger/storage/samplingstore"
+	"github.com/jonboulle/clockwork"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+const (
+	participantKeyPrefix = "participant/"
+	probabilitiesKey     = "probabilities"
+	throughputKeyPrefix  = "throughput/"
+	lockKeyPrefix        = "lock/"
+
+	// maxParticipants is the number of participant slots, that is the maximum number of replicas sharing a storage.
+	maxParticipants = 32
+)
+
+var (
+	_ samplingstore.Store  = (*storageSamplingStore)(nil)
+	_ distributedlock.Lock = (*storageLock)(nil)
+)
+
+type storedThroughput struct {
+	Time       time.Time           `json:"time"`
+	Throughput []*model.Throughput `json:"throughput"`
+}
+
+type storedParticipant struct {
+	Hostname string    `json:"hostname"`
+	Seen     time.Time `json:"seen"`
+}
+
+type storedProbabilities struct {
+	Hostname      string                              `json:"hostname"`
+	Probabilities model.ServiceOperationProbabilities `json:"probabilities"`
+	QPS           model.ServiceOperationQPS           `json:"qps"`
+	Time          time.Time                           `json:"time"`
+}
+
+// storageSamplingStore is a samplingstore.Store keeping the state of adaptive sampling in a storage client, which
+// is shared between the replicas of the collector when the storage extension is backed by a shared database.
+// Every replica writes the throughput it observes under its own key, and announces itself by claiming one of
+// maxParticipants participant slots which it then is the only one to write. As storage clients have no
+// compare-and-swap operation, two replicas claiming the same slot at the same time are only told apart when
+// reading it back, so the throughput of a replica that has just started may be missed for an interval.
+type storageSamplingStore struct {
+	client     storage.Client
+	hostname   string
+	maxBuckets int
+	clock      clockwork.Clock
+	// slot is the participant slot claimed by the replica, -1 until it is claimed.
+	slot int
+}
+
+// NewStorageSamplingStore returns a samplingstore.Store and a distributedlock.Lock backed by a storage client.
+// Up to maxBuckets buckets of throughput are kept for every replica, identified by their hostname.
+func NewStorageSamplingStore(client storage.Client, hostname string, maxBuckets int) (samplingstore.Store, distributedlock.Lock) {
+	clock := clockwork.NewRealClock()
+	return newStorageSamplingStore(client, hostname, maxBuckets, clock), newStorageLock(client, hostname, clock)
+}
+
+func newStorageSamplingStore(client storage.Client, hostname string, maxBuckets int, clock clockwork.Clock) *storageSamplingStore {
+	return &storageSamplingStore{
+		client:     client,
+		hostname:   hostname,
+		maxBuckets: maxBuckets,
+		clock:      clock,
+		slot:       -1,
+	}
+}
+
+// InsertThroughput implements samplingstore.Store#InsertThroughput.
+func (s *storageSamplingStore) InsertThroughput(throughput []*model.Throughput) error {
+	ctx := context.Background()
+	if err := s.registerParticipant(ctx); err != nil {
+		return err
+	}
+
+	var buckets []storedThroughput
+	if err := s.get(ctx, throughputKeyPrefix+s.hostname, &buckets); err != nil {
+		return err
+	}
+	buckets = append([]storedThroughput{{Time: s.clock.Now(), Throughput: throughput}}, buckets...)
+	if len(buckets) > s.maxBuckets {
+		buckets = buckets[:s.maxBuckets]
+	}
+	return s.set(ctx, throughputKeyPrefix+s.hostname, buckets)
+}
+
+// GetThroughput implements samplingstore.Store#GetThroughput, returning the throughput observed by every replica.
+func (s *storageSamplingStore) GetThroughput(start, end time.Time) ([]*model.Throughput, error) {
+	ctx := context.Background()
+	participants, err := s.participants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var throughput []*model.Throughput
+	for _, participant := range participants {
+		var buckets []storedThroughput
+		if err := s.get(ctx, throughputKeyPrefix+participant, &buckets); err != nil {
+			return nil, err
+		}
+		for _, bucket := range buckets {
+			if bucket.Time.After(start) && !bucket.Time.After(end) {
+				throughput = append(throughput, bucket.Throughput...)
+			}
+		}
+	}
+	return throughput, nil
+}
+
+// InsertProbabilitiesAndQPS implements samplingstore.Store#InsertProbabilitiesAndQPS.
+func (s *storageSamplingStore) InsertProbabilitiesAndQPS(
+	hostname string,
+	probabilities model.ServiceOperationProbabilities,
+	qps model.ServiceOperationQPS,
+) error {
+	return s.set(context.Background(), probabilitiesKey, storedProbabilities{
+		Hostname:      hostname,
+		Probabilities: probabilities,
+		QPS:           qps,
+		Time:          s.clock.Now(),
+	})
+}
+
+// GetLatestProbabilities implements samplingstore.Store#GetLatestProbabilities.
+func (s *storageSamplingStore) GetLatestProbabilities() (model.ServiceOperationProbabilities, error) {
+	var stored storedProbabilities
+	if err := s.get(context.Background(), probabilitiesKey, &stored); err != nil {
+		return nil, err
+	}
+	if stored.Probabilities == nil {
+		return model.ServiceOperationProbabilities{}, nil
+	}
+	return stored.Probabilities, nil
+}
+
+// registerParticipant records that this replica writes throughput in its participant slot, claiming one if
+// needed, and forgets the replicas whose throughput is too old to be used by any calculation.
+func (s *storageSamplingStore) registerParticipant(ctx context.Context) error {
+	slots, err := s.readSlots(ctx)
+	if err != nil {
+		return err
+	}
+	now := s.clock.Now()
+
+	var ops []storage.Operation
+	for i, slot := range slots {
+		if slot.Hostname != "" && slot.Hostname != s.hostname && s.expired(slot, now) {
+			ops = append(ops, storage.DeleteOperation(participantKey(i)), storage.DeleteOperation(throughputKeyPrefix+slot.Hostname))
+			slots[i] = storedParticipant{}
+		}
+	}
+	if len(ops) > 0 {
+		if err = s.client.Batch(ctx, ops...); err != nil {
+			return err
+		}
+	}
+
+	if s.slot < 0 || slots[s.slot].Hostname != s.hostname {
+		// The slot was never claimed, or was claimed by another replica at the same time.
+		s.slot = -1
+		for i, slot := range slots {
+			if slot.Hostname == s.hostname {
+				s.slot = i
+				break
+			}
+		}
+	}
+	if s.slot >= 0 {
+		return setJSON(ctx, s.client, participantKey(s.slot), storedParticipant{Hostname: s.hostname, Seen: now})
+	}
+	return s.claimSlot(ctx, slots, now)
+}
+
+// claimSlot claims the first free participant slot. Slots are read back after being written, so that a replica
+// claiming a slot at the same time as another tries the next free one.
+func (s *storageSamplingStore) claimSlot(ctx context.Context, slots []storedParticipant, now time.Time) error {
+	for i, slot := range slots {
+		if slot.Hostname != "" {
+			continue
+		}
+		if err := setJSON(ctx, s.client, participantKey(i), storedParticipant{Hostname: s.hostname, Seen: now}); err != nil {
+			return err
+		}
+		var claimed storedParticipant
+		if err := getJSON(ctx, s.client, participantKey(i), &claimed); err != nil {
+			return err
+		}
+		if claimed.Hostname == s.hostname {
+			s.slot = i
+			return nil
+		}
+	}
+	return fmt.Errorf("all participant slots are claimed, at most %d replicas can share a storage", maxParticipants)
+}
+
+// expired returns true if the throughput of a participant is too old to be used. Buckets are written every
+// calculation interval, so the throughput of a replica that has not written for as many intervals as there are
+// buckets is not used anymore. The interval is not known here, hence the conservative limit of one hour per bucket.
+func (s *storageSamplingStore) expired(participant storedParticipant, now time.Time) bool {
+	return now.Sub(participant.Seen) > time.Duration(s.maxBuckets)*time.Hour
+}
+
+func (s *storageSamplingStore) readSlots(ctx context.Context) ([]storedParticipant, error) {
+	ops := make([]storage.Operation, maxParticipants)
+	for i := range ops {
+		ops[i] = storage.GetOperation(participantKey(i))
+	}
+	if err := s.client.Batch(ctx, ops...); err != nil {
+		return nil, fmt.Errorf("failed to read participants from storage: %w", err)
+	}
+	slots := make([]storedParticipant, maxParticipants)
+	for i, op := range ops {
+		if op.Value == nil {
+			continue
+		}
+		if err := json.Unmarshal(op.Value, &slots[i]); err != nil {
+			return nil, fmt.Errorf("failed to decode %q from storage: %w", op.Key, err)
+		}
+	}
+	return slots, nil
+}
+
+func (s *storageSamplingStore) participants(ctx context.Context) ([]string, error) {
+	slots, err := s.readSlots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	var participants []string
+	for _, slot := range slots {
+		if slot.Hostname != "" && !s.expired(slot, now) {
+			participants = append(participants, slot.Hostname)
+		}
+	}
+	sort.Strings(participants)
+	return participants, nil
+}
+
+func participantKey(slot int) string {
+	return participantKeyPrefix + strconv.Itoa(slot)
+}
+
+func (s *storageSamplingStore) get(ctx context.Context, key string, v any) error {
+	return getJSON(ctx, s.client, key, v)
+}
+
+func (s *storageSamplingStore) set(ctx context.Context, key string, v any) error {
+	return setJSON(ctx, s.client, key, v)
+}
+
+type storedLease struct {
+	Owner   string    `json:"owner"`
+	Expires time.Time `json:"expires"`
+}
+
+// storageLock is a distributedlock.Lock keeping leases in a storage client. As storage clients have no
+// compare-and-swap operation, the lease is read back after being written, which is not mutual exclusion: two
+// replicas acquiring an expired lease at the same time may both see their own lease. This is only acceptable
+// because two leaders calculate the same probabilities from the same throughput, the last one written wins.
+type storageLock struct {
+	client storage.Client
+	owner  string
+	clock  clockwork.Clock
+}
+
+func newStorageLock(client storage.Client, owner string, clock clockwork.Clock) *storageLock {
+	return &storageLock{client: client, owner: owner, clock: clock}
+}
+
+// Acquire implements distributedlock.Lock#Acquire.
+func (l *storageLock) Acquire(resource string, ttl time.Duration) (bool, error) {
+	ctx := context.Background()
+	var lease storedLease
+	if err := getJSON(ctx, l.client, lockKeyPrefix+resource, &lease); err != nil {
+		return false, err
+	}
+	now := l.clock.Now()
+	if lease.Owner != "" && lease.Owner != l.owner && now.Before(lease.Expires) {
+		return false, nil
+	}
+
+	if err := setJSON(ctx, l.client, lockKeyPrefix+resource, storedLease{Owner: l.owner, Expires: now.Add(ttl)}); err != nil {
+		return false, err
+	}
+	if err := getJSON(ctx, l.client, lockKeyPrefix+resource, &lease); err != nil {
+		return false, err
+	}
+	return lease.Owner == l.owner, nil
+}
+
+// Forfeit implements distributedlock.Lock#Forfeit.
+func (l *storageLock) Forfeit(resource string) (bool, error) {
+	ctx := context.Background()
+	var lease storedLease
+	if err := getJSON(ctx, l.client, lockKeyPrefix+resource, &lease); err != nil {
+		return false, err
+	}
+	if lease.Owner != l.owner {
+		return false, nil
+	}
+	if err := l.client.Delete(ctx, lockKeyPrefix+resource); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func getJSON(ctx context.Context, client storage.Client, key string, v any) error {
+	value, err := client.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to read %q from storage: %w", key, err)
+	}
+	if value == nil {
+		return nil
+	}
+	if err := json.Unmarshal(value, v); err != nil {
+		return fmt.Errorf("failed to decode %q from storage: %w", key, err)
+	}
+	return nil
+}
+
+func setJSON(ctx context.Context, client storage.Client, key string, v any) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := client.Set(ctx, key, value); err != nil {
+		return fmt.Errorf("failed to write %q to storage: %w", key, err)
+	}
+	return nil
+}
+
+// localLock is a distributedlock.Lock that is always acquired, used when the adaptive sampling probabilities are
+// not shared between replicas.
+type localLock struct{}
+
+// NewLocalLock returns a distributedlock.Lock that is always acquired.
+func NewLocalLock() distributedlock.Lock {
+	return localLock{}
+}
+
+// Acquire implements distributedlock.Lock#Acquire.
+func (localLock) Acquire(string, time.Duration) (bool, error) {
+	return true, nil
+}
+
+// Forfeit implements distributedlock.Lock#Forfeit.
+func (localLock) Forfeit(string) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,244 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/model"
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+// mapClient is a storage.Client keeping its values in a map, shared by the replicas of the tests.
+type mapClient struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func newMapClient() *mapClient {
+	return &mapClient{values: map[string][]byte{}}
+}
+
+func (c *mapClient) Get(ctx context.Context, key string) ([]byte, error) {
+	op := storage.GetOperation(key)
+	err := c.Batch(ctx, op)
+	return op.Value, err
+}
+
+func (c *mapClient) Set(ctx context.Context, key string, value []byte) error {
+	return c.Batch(ctx, storage.SetOperation(key, value))
+}
+
+func (c *mapClient) Delete(ctx context.Context, key string) error {
+	return c.Batch(ctx, storage.DeleteOperation(key))
+}
+
+func (c *mapClient) Batch(_ context.Context, ops ...storage.Operation) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value = c.values[op.Key]
+		case storage.Set:
+			c.values[op.Key] = op.Value
+		case storage.Delete:
+			delete(c.values, op.Key)
+		default:
+			return errors.New("wrong operation type")
+		}
+	}
+	return nil
+}
+
+func (c *mapClient) Close(context.Context) error {
+	return nil
+}
+
+func throughput(service, operation string, count int64) *model.Throughput {
+	return &model.Throughput{
+		Service:       service,
+		Operation:     operation,
+		Count:         count,
+		Probabilities: map[string]struct{}{"0.001000": {}},
+	}
+}
+
+func TestStorageSamplingStoreThroughput(t *testing.T) {
+	client := newMapClient()
+	clock := clockwork.NewFakeClockAt(time.Unix(1700000000, 0))
+	start := clock.Now()
+	replica1 := newStorageSamplingStore(client, "replica-1", 2, clock)
+	replica2 := newStorageSamplingStore(client, "replica-2", 2, clock)
+
+	// no throughput has been recorded yet
+	got, err := replica1.GetThroughput(start, start.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	clock.Advance(time.Minute)
+	require.NoError(t, replica1.InsertThroughput([]*model.Throughput{throughput("foo", "GET /", 1)}))
+	require.NoError(t, replica2.InsertThroughput([]*model.Throughput{throughput("foo", "GET /", 2)}))
+	clock.Advance(time.Minute)
+	require.NoError(t, replica1.InsertThroughput([]*model.Throughput{throughput("foo", "GET /", 3)}))
+	clock.Advance(time.Minute)
+	require.NoError(t, replica1.InsertThroughput([]*model.Throughput{throughput("bar", "POST /", 4)}))
+
+	// every replica gets the throughput of all of them, while only the latest buckets of each are kept
+	for _, store := range []*storageSamplingStore{replica1, replica2} {
+		got, err = store.GetThroughput(start, clock.Now())
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Throughput{
+			throughput("bar", "POST /", 4),
+			throughput("foo", "GET /", 3),
+			throughput("foo", "GET /", 2),
+		}, got)
+	}
+
+	got, err = replica2.GetThroughput(start.Add(time.Minute), clock.Now())
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Throughput{
+		throughput("bar", "POST /", 4),
+		throughput("foo", "GET /", 3),
+	}, got)
+
+	// the throughput of replicas that have stopped is eventually removed
+	clock.Advance(3 * time.Hour)
+	require.NoError(t, replica1.InsertThroughput(nil))
+	participants, err := replica1.participants(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"replica-1"}, participants)
+	assert.NotContains(t, client.values, throughputKeyPrefix+"replica-2")
+}
+
+func TestStorageSamplingStoreParticipants(t *testing.T) {
+	ctx := context.Background()
+	client := newMapClient()
+	clock := clockwork.NewFakeClockAt(time.Unix(1700000000, 0))
+
+	replicas := make([]*storageSamplingStore, 10)
+	expected := make([]string, len(replicas))
+	for i := range replicas {
+		expected[i] = "replica-" + strconv.Itoa(i)
+		replicas[i] = newStorageSamplingStore(client, expected[i], 2, clock)
+	}
+	sort.Strings(expected)
+
+	// Replicas registering at the same time may claim the same slot, which is detected on their next write.
+	var wg sync.WaitGroup
+	for _, replica := range replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, replica.InsertThroughput(nil))
+		}()
+	}
+	wg.Wait()
+	for _, replica := range replicas {
+		require.NoError(t, replica.InsertThroughput(nil))
+	}
+	participants, err := replicas[0].participants(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, expected, participants)
+
+	// Once claimed, a slot is only written by its replica.
+	before := map[string][]byte{}
+	for i := 0; i < maxParticipants; i++ {
+		before[participantKey(i)] = client.values[participantKey(i)]
+	}
+	clock.Advance(time.Minute)
+	require.NoError(t, replicas[3].InsertThroughput(nil))
+	for i := 0; i < maxParticipants; i++ {
+		if i != replicas[3].slot {
+			assert.Equal(t, before[participantKey(i)], client.values[participantKey(i)])
+		}
+	}
+
+	// A replica whose slot was lost claims another one.
+	lost := replicas[3].slot
+	require.NoError(t, setJSON(ctx, client, participantKey(lost), storedParticipant{Hostname: "replica-new", Seen: clock.Now()}))
+	require.NoError(t, replicas[3].InsertThroughput(nil))
+	assert.NotEqual(t, lost, replicas[3].slot)
+	participants, err = replicas[0].participants(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, participants, "replica-3")
+	assert.Contains(t, participants, "replica-new")
+}
+
+func TestStorageSamplingStoreParticipantsFull(t *testing.T) {
+	client := newMapClient()
+	clock := clockwork.NewFakeClock()
+	for i := 0; i < maxParticipants; i++ {
+		require.NoError(t, newStorageSamplingStore(client, "replica-"+strconv.Itoa(i), 2, clock).InsertThroughput(nil))
+	}
+	err := newStorageSamplingStore(client, "replica-extra", 2, clock).InsertThroughput(nil)
+	assert.EqualError(t, err, "all participant slots are claimed, at most 32 replicas can share a storage")
+}
+
+func TestStorageSamplingStoreProbabilities(t *testing.T) {
+	client := newMapClient()
+	clock := clockwork.NewFakeClock()
+	leader := newStorageSamplingStore(client, "replica-1", 10, clock)
+	follower := newStorageSamplingStore(client, "replica-2", 10, clock)
+
+	probabilities, err := follower.GetLatestProbabilities()
+	require.NoError(t, err)
+	assert.Equal(t, model.ServiceOperationProbabilities{}, probabilities)
+
+	expected := model.ServiceOperationProbabilities{"foo": {"GET /": 0.5}}
+	require.NoError(t, leader.InsertProbabilitiesAndQPS("replica-1", expected, model.ServiceOperationQPS{"foo": {"GET /": 2}}))
+	probabilities, err = follower.GetLatestProbabilities()
+	require.NoError(t, err)
+	assert.Equal(t, expected, probabilities)
+
+	client.values[probabilitiesKey] = []byte("invalid")
+	_, err = follower.GetLatestProbabilities()
+	assert.ErrorContains(t, err, `failed to decode "probabilities" from storage`)
+}
+
+func TestStorageLock(t *testing.T) {
+	client := newMapClient()
+	clock := clockwork.NewFakeClock()
+	lock1 := newStorageLock(client, "replica-1", clock)
+	lock2 := newStorageLock(client, "replica-2", clock)
+
+	acquired, err := lock1.Acquire("sampling_lock", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// the lease can be renewed by its owner only
+	acquired, err = lock2.Acquire("sampling_lock", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	clock.Advance(30 * time.Second)
+	acquired, err = lock1.Acquire("sampling_lock", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// an expired lease can be acquired by anyone
+	clock.Advance(2 * time.Minute)
+	acquired, err = lock2.Acquire("sampling_lock", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	forfeited, err := lock1.Forfeit("sampling_lock")
+	require.NoError(t, err)
+	assert.False(t, forfeited)
+	forfeited, err = lock2.Forfeit("sampling_lock")
+	require.NoError(t, err)
+	assert.True(t, forfeited)
+
+	acquired, err = lock1.Acquire("sampling_lock", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
@@ -0,0 +1,127 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package jaegerremotesampling // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/jaegerremotesampling"
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/samplingstrategy"
+	"github.com/jaegertracing/jaeger/model"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const (
+	samplerTypeAttribute  = "sampler.type"
+	samplerParamAttribute = "sampler.param"
+	serviceNameAttribute  = "service.name"
+)
+
+var (
+	errNotJaegerRemoteSampling = errors.New("extension is not a jaegerremotesampling extension")
+	errNotAdaptive             = errors.New("jaegerremotesampling extension does not use the adaptive source")
+)
+
+// ThroughputRecorder records the throughput of the root spans of every service and operation, from which the
+// adaptive sampling probabilities are calculated.
+type ThroughputRecorder interface {
+	// RecordTraces records the root spans of the traces. Only root spans with the `sampler.type` and
+	// `sampler.param` attributes reported by Jaeger SDKs are taken into account.
+	RecordTraces(td ptrace.Traces)
+}
+
+// GetThroughputRecorder returns the ThroughputRecorder of the jaegerremotesampling extension with the given ID,
+// which must use the adaptive source and must have been started.
+func GetThroughputRecorder(host component.Host, id component.ID) (ThroughputRecorder, error) {
+	ext, ok := host.GetExtensions()[id]
+	if !ok {
+		return nil, fmt.Errorf("extension %s not found", id)
+	}
+	jrse, ok := ext.(*jrsExtension)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", id, errNotJaegerRemoteSampling)
+	}
+	if jrse.aggregator == nil {
+		return nil, fmt.Errorf("%s: %w", id, errNotAdaptive)
+	}
+	return &throughputRecorder{aggregator: jrse.aggregator}, nil
+}
+
+type throughputRecorder struct {
+	aggregator samplingstrategy.Aggregator
+}
+
+func (r *throughputRecorder) RecordTraces(td ptrace.Traces) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		serviceName, ok := rs.Resource().Attributes().Get(serviceNameAttribute)
+		if !ok || serviceName.Str() == "" {
+			continue
+		}
+		sss := rs.ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				r.recordSpan(serviceName.Str(), spans.At(k))
+			}
+		}
+	}
+}
+
+func (r *throughputRecorder) recordSpan(serviceName string, span ptrace.Span) {
+	if !span.ParentSpanID().IsEmpty() || span.Name() == "" {
+		return
+	}
+	samplerType, samplerParam := samplerParams(span.Attributes())
+	if samplerType == model.SamplerTypeUnrecognized {
+		return
+	}
+	r.aggregator.RecordThroughput(serviceName, span.Name(), samplerType, samplerParam)
+}
+
+// samplerParams returns the sampler of a root span, as it is returned for Jaeger spans by model.Span#GetSamplerParams.
+func samplerParams(attrs pcommon.Map) (model.SamplerType, float64) {
+	typeValue, ok := attrs.Get(samplerTypeAttribute)
+	if !ok {
+		return model.SamplerTypeUnrecognized, 0
+	}
+	var samplerType model.SamplerType
+	switch typeValue.Str() {
+	case "probabilistic":
+		samplerType = model.SamplerTypeProbabilistic
+	case "lowerbound":
+		samplerType = model.SamplerTypeLowerBound
+	case "ratelimiting":
+		samplerType = model.SamplerTypeRateLimiting
+	case "const":
+		samplerType = model.SamplerTypeConst
+	default:
+		return model.SamplerTypeUnrecognized, 0
+	}
+
+	paramValue, ok := attrs.Get(samplerParamAttribute)
+	if !ok {
+		return model.SamplerTypeUnrecognized, 0
+	}
+	switch paramValue.Type() {
+	case pcommon.ValueTypeDouble:
+		return samplerType, paramValue.Double()
+	case pcommon.ValueTypeInt:
+		return samplerType, float64(paramValue.Int())
+	case pcommon.ValueTypeBool:
+		if paramValue.Bool() {
+			return samplerType, 1
+		}
+		return samplerType, 0
+	case pcommon.ValueTypeStr:
+		if param, err := strconv.ParseFloat(paramValue.Str(), 64); err == nil {
+			return samplerType, param
+		}
+	}
+	return model.SamplerTypeUnrecognized, 0
+}
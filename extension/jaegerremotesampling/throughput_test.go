@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package jaegerremotesampling
+
+import (
+	"testing"
+
+	"github.com/jaegertracing/jaeger/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+type recordedThroughput struct {
+	service     string
+	operation   string
+	samplerType model.SamplerType
+	probability float64
+}
+
+type testAggregator struct {
+	recorded []recordedThroughput
+}
+
+func (a *testAggregator) RecordThroughput(service, operation string, samplerType model.SamplerType, probability float64) {
+	a.recorded = append(a.recorded, recordedThroughput{service, operation, samplerType, probability})
+}
+
+func (*testAggregator) HandleRootSpan(*model.Span, *zap.Logger) {}
+
+func (*testAggregator) Start() {}
+
+func (*testAggregator) Close() error {
+	return nil
+}
+
+func TestRecordTraces(t *testing.T) {
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", "foo")
+	spans := rs.ScopeSpans().AppendEmpty().Spans()
+
+	addSpan := func(name string, parent pcommon.SpanID, samplerType string, samplerParam any) {
+		span := spans.AppendEmpty()
+		span.SetName(name)
+		span.SetParentSpanID(parent)
+		if samplerType != "" {
+			span.Attributes().PutStr("sampler.type", samplerType)
+		}
+		switch param := samplerParam.(type) {
+		case float64:
+			span.Attributes().PutDouble("sampler.param", param)
+		case string:
+			span.Attributes().PutStr("sampler.param", param)
+		case bool:
+			span.Attributes().PutBool("sampler.param", param)
+		}
+	}
+	addSpan("GET /", pcommon.NewSpanIDEmpty(), "probabilistic", 0.1)
+	addSpan("GET /", pcommon.NewSpanIDEmpty(), "lowerbound", "0.5")
+	addSpan("POST /", pcommon.NewSpanIDEmpty(), "const", true)
+	// child spans, spans without sampler and spans with unknown samplers are ignored
+	addSpan("child", pcommon.SpanID([8]byte{1}), "probabilistic", 0.1)
+	addSpan("no sampler", pcommon.NewSpanIDEmpty(), "", nil)
+	addSpan("no param", pcommon.NewSpanIDEmpty(), "probabilistic", nil)
+	addSpan("unknown", pcommon.NewSpanIDEmpty(), "remote", 0.1)
+
+	// resources without service name are ignored
+	td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("GET /")
+
+	aggregator := &testAggregator{}
+	recorder := &throughputRecorder{aggregator: aggregator}
+	recorder.RecordTraces(td)
+
+	assert.Equal(t, []recordedThroughput{
+		{"foo", "GET /", model.SamplerTypeProbabilistic, 0.1},
+		{"foo", "GET /", model.SamplerTypeLowerBound, 0.5},
+		{"foo", "POST /", model.SamplerTypeConst, 1},
+	}, aggregator.recorded)
+}
+
+func TestGetThroughputRecorder(t *testing.T) {
+	id := component.MustNewID("jaegerremotesampling")
+	notAdaptive := newExtension(id, testConfig(), componenttest.NewNopTelemetrySettings())
+	adaptive := newExtension(id, testConfig(), componenttest.NewNopTelemetrySettings())
+	adaptive.aggregator = &testAggregator{}
+
+	host := &testHost{extensions: map[component.ID]component.Component{}}
+	_, err := GetThroughputRecorder(host, id)
+	assert.EqualError(t, err, "extension jaegerremotesampling not found")
+
+	host.extensions[id] = &testStorageExtension{}
+	_, err = GetThroughputRecorder(host, id)
+	assert.ErrorIs(t, err, errNotJaegerRemoteSampling)
+
+	host.extensions[id] = notAdaptive
+	_, err = GetThroughputRecorder(host, id)
+	assert.ErrorIs(t, err, errNotAdaptive)
+
+	host.extensions[id] = adaptive
+	recorder, err := GetThroughputRecorder(host, id)
+	require.NoError(t, err)
+	assert.NotNil(t, recorder)
+}
@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mtlsauthextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/mtlsauthextension"
+
+import (
+	"crypto/x509"
+
+	"go.opentelemetry.io/collector/client"
+)
+
+var _ client.AuthData = (*authData)(nil)
+
+// authData holds the identities of a verified client certificate.
+type authData struct {
+	spiffeID       string
+	subject        string
+	commonName     string
+	issuer         string
+	serialNumber   string
+	dnsNames       []string
+	uris           []string
+	emailAddresses []string
+	ipAddresses    []string
+}
+
+func newAuthData(cert *x509.Certificate) *authData {
+	data := &authData{
+		subject:        cert.Subject.String(),
+		commonName:     cert.Subject.CommonName,
+		issuer:         cert.Issuer.String(),
+		dnsNames:       cert.DNSNames,
+		emailAddresses: cert.EmailAddresses,
+	}
+	if cert.SerialNumber != nil {
+		data.serialNumber = cert.SerialNumber.String()
+	}
+
+	var spiffeIDs []string
+	for _, uri := range cert.URIs {
+		data.uris = append(data.uris, uri.String())
+		if uri.Scheme == spiffeScheme {
+			spiffeIDs = append(spiffeIDs, uri.String())
+		}
+	}
+	// An X.509 SVID has exactly one SPIFFE ID, certificates with more are not SVIDs.
+	if len(spiffeIDs) == 1 {
+		data.spiffeID = spiffeIDs[0]
+	}
+	for _, ip := range cert.IPAddresses {
+		data.ipAddresses = append(data.ipAddresses, ip.String())
+	}
+	return data
+}
+
+func (a *authData) GetAttribute(name string) any {
+	switch name {
+	case "spiffe_id":
+		return a.spiffeID
+	case "subject":
+		return a.subject
+	case "common_name":
+		return a.commonName
+	case "issuer":
+		return a.issuer
+	case "serial_number":
+		return a.serialNumber
+	case "dns_names":
+		return a.dnsNames
+	case "uris":
+		return a.uris
+	case "email_addresses":
+		return a.emailAddresses
+	case "ip_addresses":
+		return a.ipAddresses
+	default:
+		return nil
+	}
+}
+
+func (*authData) GetAttributeNames() []string {
+	return []string{"spiffe_id", "subject", "common_name", "issuer", "serial_number", "dns_names", "uris", "email_addresses", "ip_addresses"}
+}
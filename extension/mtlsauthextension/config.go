@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mtlsauthextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/mtlsauthextension"
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var errEmptyValue = errors.New("values cannot be empty")
+
+// MatchSettings defines the allowed values of an identity of the certificate.
+type MatchSettings struct {
+	// Values are the allowed values.
+	Values []string `mapstructure:"values"`
+	// Regexes are regular expressions that have to match the whole value.
+	Regexes []string `mapstructure:"regexes"`
+}
+
+type Config struct {
+	// SPIFFEIDs are the allowed SPIFFE IDs, e.g. `spiffe://example.org/ns/prod/sa/collector`.
+	SPIFFEIDs MatchSettings `mapstructure:"spiffe_ids"`
+	// Subjects are the allowed distinguished names of the subject, e.g. `CN=collector,O=example`.
+	Subjects MatchSettings `mapstructure:"subjects"`
+	// CommonNames are the allowed common names of the subject.
+	CommonNames MatchSettings `mapstructure:"common_names"`
+	// DNSNames are the allowed DNS names of the subject alternative names.
+	DNSNames MatchSettings `mapstructure:"dns_names"`
+	// URIs are the allowed URIs of the subject alternative names, including SPIFFE IDs.
+	URIs MatchSettings `mapstructure:"uris"`
+	// EmailAddresses are the allowed email addresses of the subject alternative names.
+	EmailAddresses MatchSettings `mapstructure:"email_addresses"`
+}
+
+func (cfg *Config) Validate() error {
+	for _, settings := range []struct {
+		name string
+		MatchSettings
+	}{
+		{"spiffe_ids", cfg.SPIFFEIDs},
+		{"subjects", cfg.Subjects},
+		{"common_names", cfg.CommonNames},
+		{"dns_names", cfg.DNSNames},
+		{"uris", cfg.URIs},
+		{"email_addresses", cfg.EmailAddresses},
+	} {
+		if err := settings.validate(); err != nil {
+			return fmt.Errorf("%s: %w", settings.name, err)
+		}
+	}
+	for _, id := range cfg.SPIFFEIDs.Values {
+		if !strings.HasPrefix(id, spiffeScheme+"://") {
+			return fmt.Errorf("spiffe_ids: %q is not a SPIFFE ID", id)
+		}
+	}
+	return nil
+}
+
+func (ms *MatchSettings) validate() error {
+	for _, value := range ms.Values {
+		if value == "" {
+			return errEmptyValue
+		}
+	}
+	for _, expr := range ms.Regexes {
+		if _, err := compileRegex(expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ms *MatchSettings) isEmpty() bool {
+	return len(ms.Values) == 0 && len(ms.Regexes) == 0
+}
+
+// compileRegex compiles a regular expression that has to match the whole value, so that a pattern like
+// `collector\.example\.org` does not match `collector.example.org.attacker.com`.
+func compileRegex(expr string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", expr, err)
+	}
+	return re, nil
+}
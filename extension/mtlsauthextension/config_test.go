@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mtlsauthextension
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/mtlsauthextension/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id          component.ID
+		expected    component.Config
+		expectedErr string
+	}{
+		{
+			id:       component.NewID(metadata.Type),
+			expected: &Config{},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "all_settings"),
+			expected: &Config{
+				SPIFFEIDs: MatchSettings{
+					Values:  []string{"spiffe://example.org/ns/prod/sa/collector"},
+					Regexes: []string{`spiffe://example\.org/ns/staging/.*`},
+				},
+				Subjects:       MatchSettings{Values: []string{"CN=collector,O=example"}},
+				CommonNames:    MatchSettings{Regexes: []string{`collector-\d+`}},
+				DNSNames:       MatchSettings{Values: []string{"collector.example.org"}},
+				URIs:           MatchSettings{Values: []string{"https://example.org/collector"}},
+				EmailAddresses: MatchSettings{Values: []string{"collector@example.org"}},
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_regex"),
+			expectedErr: `dns_names: invalid regex "collector-("`,
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_spiffe_id"),
+			expectedErr: `spiffe_ids: "https://example.org/collector" is not a SPIFFE ID`,
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "empty_value"),
+			expectedErr: "subjects: values cannot be empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, component.ValidateConfig(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, component.ValidateConfig(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mtlsauthextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/mtlsauthextension"
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"regexp"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/extension/auth"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+const spiffeScheme = "spiffe"
+
+var (
+	errNoCertificate = errors.New("no verified client certificate")
+	errNotAllowed    = errors.New("client certificate identity is not allowed")
+)
+
+// matcher matches a single identity of the certificate against the allowed values and regexes.
+type matcher struct {
+	values  map[string]struct{}
+	regexes []*regexp.Regexp
+}
+
+func newMatcher(settings MatchSettings) *matcher {
+	if settings.isEmpty() {
+		return nil
+	}
+	m := &matcher{values: map[string]struct{}{}}
+	for _, value := range settings.Values {
+		m.values[value] = struct{}{}
+	}
+	for _, expr := range settings.Regexes {
+		// validated by Config.Validate
+		re, _ := compileRegex(expr)
+		m.regexes = append(m.regexes, re)
+	}
+	return m
+}
+
+func (m *matcher) matches(values ...string) bool {
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		if _, ok := m.values[value]; ok {
+			return true
+		}
+		for _, re := range m.regexes {
+			if re.MatchString(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type mtlsAuth struct {
+	logger *zap.Logger
+
+	spiffeIDs      *matcher
+	subjects       *matcher
+	commonNames    *matcher
+	dnsNames       *matcher
+	uris           *matcher
+	emailAddresses *matcher
+}
+
+func newServerAuthExtension(cfg *Config, logger *zap.Logger) auth.Server {
+	ma := &mtlsAuth{
+		logger:         logger,
+		spiffeIDs:      newMatcher(cfg.SPIFFEIDs),
+		subjects:       newMatcher(cfg.Subjects),
+		commonNames:    newMatcher(cfg.CommonNames),
+		dnsNames:       newMatcher(cfg.DNSNames),
+		uris:           newMatcher(cfg.URIs),
+		emailAddresses: newMatcher(cfg.EmailAddresses),
+	}
+	return auth.NewServer(
+		auth.WithServerAuthenticate(ma.authenticate),
+	)
+}
+
+// authenticate authorizes the verified client certificate of a gRPC request if any of its identities is allowed,
+// or any certificate if no identity is configured.
+func (ma *mtlsAuth) authenticate(ctx context.Context, _ map[string][]string) (context.Context, error) {
+	cert := peerCertificate(ctx)
+	if cert == nil {
+		return ctx, errNoCertificate
+	}
+
+	data := newAuthData(cert)
+	if !ma.allowed(data) {
+		ma.logger.Debug("Client certificate identity is not allowed",
+			zap.String("subject", data.subject), zap.String("spiffe_id", data.spiffeID))
+		return ctx, errNotAllowed
+	}
+
+	cl := client.FromContext(ctx)
+	cl.Auth = data
+	return client.NewContext(ctx, cl), nil
+}
+
+func (ma *mtlsAuth) allowed(data *authData) bool {
+	configured := false
+	for _, rule := range []struct {
+		m      *matcher
+		values []string
+	}{
+		{ma.spiffeIDs, []string{data.spiffeID}},
+		{ma.subjects, []string{data.subject}},
+		{ma.commonNames, []string{data.commonName}},
+		{ma.dnsNames, data.dnsNames},
+		{ma.uris, data.uris},
+		{ma.emailAddresses, data.emailAddresses},
+	} {
+		if rule.m == nil {
+			continue
+		}
+		configured = true
+		if rule.m.matches(rule.values...) {
+			return true
+		}
+	}
+	return !configured
+}
+
+// peerCertificate returns the leaf of the first verified chain of the client of a gRPC request.
+func peerCertificate(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return tlsInfo.State.VerifiedChains[0][0]
+}
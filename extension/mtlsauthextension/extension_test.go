@@ -0,0 +1,180 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mtlsauthextension
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/auth"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func newTestExtension(t *testing.T, cfg *Config) auth.Server {
+	require.NoError(t, cfg.Validate())
+	ext := newServerAuthExtension(cfg, zap.NewNop())
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		require.NoError(t, ext.Shutdown(context.Background()))
+	})
+	return ext
+}
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	return u
+}
+
+func newTestCertificate(t *testing.T, cn string, uris ...string) *x509.Certificate {
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: cn, Organization: []string{"example"}},
+		Issuer:         pkix.Name{CommonName: "ca"},
+		SerialNumber:   big.NewInt(42),
+		DNSNames:       []string{cn + ".example.org"},
+		EmailAddresses: []string{cn + "@example.org"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+	}
+	for _, uri := range uris {
+		cert.URIs = append(cert.URIs, mustParseURL(t, uri))
+	}
+	return cert
+}
+
+// peerContext returns the context of a gRPC request whose client certificate was verified.
+func peerContext(cert *x509.Certificate) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{cert},
+				VerifiedChains:   [][]*x509.Certificate{{cert}},
+			},
+		},
+	})
+}
+
+func TestAuthenticateAttributes(t *testing.T) {
+	ext := newTestExtension(t, &Config{})
+
+	cert := newTestCertificate(t, "collector", "spiffe://example.org/ns/prod/sa/collector", "https://example.org/collector")
+	ctx, err := ext.Authenticate(peerContext(cert), map[string][]string{})
+	require.NoError(t, err)
+
+	data := client.FromContext(ctx).Auth
+	require.NotNil(t, data)
+	assert.Equal(t, "spiffe://example.org/ns/prod/sa/collector", data.GetAttribute("spiffe_id"))
+	assert.Equal(t, "CN=collector,O=example", data.GetAttribute("subject"))
+	assert.Equal(t, "collector", data.GetAttribute("common_name"))
+	assert.Equal(t, "CN=ca", data.GetAttribute("issuer"))
+	assert.Equal(t, "42", data.GetAttribute("serial_number"))
+	assert.Equal(t, []string{"collector.example.org"}, data.GetAttribute("dns_names"))
+	assert.Equal(t, []string{"spiffe://example.org/ns/prod/sa/collector", "https://example.org/collector"}, data.GetAttribute("uris"))
+	assert.Equal(t, []string{"collector@example.org"}, data.GetAttribute("email_addresses"))
+	assert.Equal(t, []string{"10.0.0.1"}, data.GetAttribute("ip_addresses"))
+	assert.Nil(t, data.GetAttribute("missing"))
+	for _, name := range data.GetAttributeNames() {
+		assert.NotNil(t, data.GetAttribute(name), name)
+	}
+}
+
+func TestAuthenticateSPIFFEID(t *testing.T) {
+	ext := newTestExtension(t, &Config{})
+
+	// Certificates with multiple SPIFFE IDs are not SVIDs.
+	cert := newTestCertificate(t, "collector", "spiffe://example.org/a", "spiffe://example.org/b")
+	ctx, err := ext.Authenticate(peerContext(cert), map[string][]string{})
+	require.NoError(t, err)
+	assert.Equal(t, "", client.FromContext(ctx).Auth.GetAttribute("spiffe_id"))
+}
+
+func TestAuthenticateAllowed(t *testing.T) {
+	ext := newTestExtension(t, &Config{
+		SPIFFEIDs: MatchSettings{
+			Values:  []string{"spiffe://example.org/ns/prod/sa/collector"},
+			Regexes: []string{`spiffe://example\.org/ns/staging/.*`},
+		},
+		CommonNames: MatchSettings{Regexes: []string{`gateway-\d+`}},
+		DNSNames:    MatchSettings{Values: []string{"agent.example.org"}},
+	})
+
+	tests := []struct {
+		name    string
+		cert    *x509.Certificate
+		allowed bool
+	}{
+		{
+			name:    "spiffe_id",
+			cert:    newTestCertificate(t, "collector", "spiffe://example.org/ns/prod/sa/collector"),
+			allowed: true,
+		},
+		{
+			name:    "spiffe_id_regex",
+			cert:    newTestCertificate(t, "collector", "spiffe://example.org/ns/staging/sa/collector"),
+			allowed: true,
+		},
+		{
+			name: "other_spiffe_id",
+			cert: newTestCertificate(t, "collector", "spiffe://example.org/ns/dev/sa/collector"),
+		},
+		{
+			name: "spiffe_id_regex_is_anchored",
+			cert: newTestCertificate(t, "collector", "spiffe://attacker.org/spiffe://example.org/ns/staging/sa/collector"),
+		},
+		{
+			name:    "common_name_regex",
+			cert:    newTestCertificate(t, "gateway-1"),
+			allowed: true,
+		},
+		{
+			name: "common_name_regex_is_anchored",
+			cert: newTestCertificate(t, "gateway-1-attacker"),
+		},
+		{
+			name:    "dns_name",
+			cert:    newTestCertificate(t, "agent"),
+			allowed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := ext.Authenticate(peerContext(tt.cert), map[string][]string{})
+			if !tt.allowed {
+				assert.ErrorIs(t, err, errNotAllowed)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, client.FromContext(ctx).Auth)
+		})
+	}
+}
+
+func TestAuthenticateWithoutCertificate(t *testing.T) {
+	ext := newTestExtension(t, &Config{})
+
+	_, err := ext.Authenticate(context.Background(), map[string][]string{})
+	assert.ErrorIs(t, err, errNoCertificate)
+
+	// Certificates that were presented but not verified are not trusted.
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{newTestCertificate(t, "collector")},
+			},
+		},
+	})
+	_, err = ext.Authenticate(ctx, map[string][]string{})
+	assert.ErrorIs(t, err, errNoCertificate)
+}
@@ -0,0 +1,38 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mtlsauthextension
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/mtlsauthextension/internal/metadata"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	expected := &Config{}
+	actual := createDefaultConfig()
+	assert.Equal(t, expected, actual)
+	assert.NoError(t, componenttest.CheckConfigStruct(actual))
+}
+
+func TestCreateExtension_ValidConfig(t *testing.T) {
+	cfg := &Config{
+		SPIFFEIDs: MatchSettings{Values: []string{"spiffe://example.org/collector"}},
+	}
+
+	ext, err := createExtension(context.Background(), extensiontest.NewNopSettings(), cfg)
+	assert.NoError(t, err)
+	assert.NotNil(t, ext)
+}
+
+func TestNewFactory(t *testing.T) {
+	f := NewFactory()
+	assert.NotNil(t, f)
+	assert.Equal(t, metadata.Type, f.Type())
+}
@@ -8,6 +8,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sync"
 
 	// Postgres driver
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -17,28 +18,37 @@ import (
 )
 
 const (
-	createTableSqlite = "create table if not exists %s (key text primary key, value blob)"
-	createTable       = "create table if not exists %s (key text primary key, value text)"
-	getQueryText      = "select value from %s where key=$1"
-	setQueryText      = "insert into %s(key, value) values($1,$2) on conflict(key) do update set value=$3"
-	deleteQueryText   = "delete from %s where key=$1"
+	getQueryText    = "select value from %s where key=$1"
+	setQueryText    = "insert into %s(key, value) values($1,$2) on conflict(key) do update set value=excluded.value"
+	deleteQueryText = "delete from %s where key=$1"
 )
 
+// querier is implemented by both sql.DB and sql.Tx.
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// txBeginner is implemented by both sql.DB and sql.Conn.
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// transactor runs fn in a transaction, which is committed if fn succeeds and rolled back otherwise.
+type transactor func(ctx context.Context, fn func(tx *sql.Tx) error) error
+
 type dbStorageClient struct {
 	db          *sql.DB
 	getQuery    *sql.Stmt
 	setQuery    *sql.Stmt
 	deleteQuery *sql.Stmt
+	limits      *clientLimits
+	lock        *clientLock
+	cancel      context.CancelFunc
+	loops       sync.WaitGroup
 }
 
-func newClient(ctx context.Context, driverName string, db *sql.DB, tableName string) (*dbStorageClient, error) {
-	createTableSQL := createTable
-	if driverName == "sqlite" {
-		createTableSQL = createTableSqlite
-	}
-	var err error
-	_, err = db.ExecContext(ctx, fmt.Sprintf(createTableSQL, tableName))
-	if err != nil {
+func newClient(ctx context.Context, driverName string, db *sql.DB, tableName string, limits *clientLimits, lock *clientLock) (*dbStorageClient, error) {
+	if err := migrate(ctx, db, driverName, tableName); err != nil {
 		return nil, err
 	}
 
@@ -54,68 +64,160 @@ func newClient(ctx context.Context, driverName string, db *sql.DB, tableName str
 	if err != nil {
 		return nil, err
 	}
-	return &dbStorageClient{db, selectQuery, setQuery, deleteQuery}, nil
+	client := &dbStorageClient{db: db, getQuery: selectQuery, setQuery: setQuery, deleteQuery: deleteQuery, limits: limits, lock: lock}
+	if lock != nil && limits != nil {
+		lock.acquiredAgain = limits.refreshSize
+	}
+	if limits == nil {
+		// drop write times that would otherwise be stale once limits are enforced again
+		if _, err = db.ExecContext(ctx, fmt.Sprintf(dropWriteTimesTable, tableName+writeTimesTableSuffix)); err != nil {
+			return nil, err
+		}
+		return client, nil
+	}
+
+	if err = limits.init(ctx, db, tableName); err != nil {
+		return nil, err
+	}
+	limits.add(ctx, 0)
+	if limits.ttl > 0 {
+		var loopCtx context.Context
+		loopCtx, client.cancel = context.WithCancel(context.Background())
+		limits.startExpiryLoop(loopCtx, client.inTransaction, &client.loops)
+	}
+	return client, nil
 }
 
 // Get will retrieve data from storage that corresponds to the specified key
 func (c *dbStorageClient) Get(ctx context.Context, key string) ([]byte, error) {
-	rows, err := c.getQuery.QueryContext(ctx, key)
-	if err != nil {
-		return nil, err
-	}
-	if !rows.Next() {
-		return nil, nil
+	return c.get(ctx, c.db, c.getQuery, key)
+}
+
+func (c *dbStorageClient) get(ctx context.Context, q querier, getQuery *sql.Stmt, key string) ([]byte, error) {
+	if c.limits != nil {
+		// expired values are removed periodically
+		expired, err := c.limits.expired(ctx, q, key)
+		if err != nil || expired {
+			return nil, err
+		}
 	}
 	var result []byte
-	err = rows.Scan(&result)
-	if err != nil {
-		return result, err
+	err := getQuery.QueryRowContext(ctx, key).Scan(&result)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
 	}
-	err = rows.Close()
 	return result, err
 }
 
 // Set will store data. The data can be retrieved using the same key
 func (c *dbStorageClient) Set(ctx context.Context, key string, value []byte) error {
-	_, err := c.setQuery.ExecContext(ctx, key, value, value)
-	return err
+	return c.Batch(ctx, storage.SetOperation(key, value))
 }
 
 // Delete will delete data associated with the specified key
 func (c *dbStorageClient) Delete(ctx context.Context, key string) error {
-	_, err := c.deleteQuery.ExecContext(ctx, key)
-	return err
+	return c.Batch(ctx, storage.DeleteOperation(key))
 }
 
-// Batch executes the specified operations in order. Get operation results are updated in place
+// Batch executes the specified operations in order, in a single transaction.
+// Either all or none of the operations are applied. Get operation results are updated in place
 func (c *dbStorageClient) Batch(ctx context.Context, ops ...storage.Operation) error {
-	var err error
-	for _, op := range ops {
-		switch op.Type {
-		case storage.Get:
-			op.Value, err = c.Get(ctx, op.Key)
-		case storage.Set:
-			err = c.Set(ctx, op.Key, op.Value)
-		case storage.Delete:
-			err = c.Delete(ctx, op.Key)
-		default:
-			return errors.New("wrong operation type")
-		}
+	if c.limits != nil {
+		c.limits.mu.Lock()
+		defer c.limits.mu.Unlock()
+	}
+
+	var sizeDelta, evicted int64
+	err := c.inTransaction(ctx, func(tx *sql.Tx) error {
+		var getQuery, setQuery, deleteQuery *sql.Stmt
+		for _, op := range ops {
+			var err error
+			switch op.Type {
+			case storage.Get:
+				if getQuery == nil {
+					getQuery = tx.StmtContext(ctx, c.getQuery)
+				}
+				op.Value, err = c.get(ctx, tx, getQuery, op.Key)
+			case storage.Set:
+				if setQuery == nil {
+					setQuery = tx.StmtContext(ctx, c.setQuery)
+				}
+				if c.limits == nil {
+					_, err = setQuery.ExecContext(ctx, op.Key, op.Value)
+					break
+				}
+				var delta, n int64
+				delta, n, err = c.limits.set(ctx, tx, setQuery, op.Key, op.Value, sizeDelta)
+				sizeDelta += delta
+				evicted += n
+			case storage.Delete:
+				if deleteQuery == nil {
+					deleteQuery = tx.StmtContext(ctx, c.deleteQuery)
+				}
+				if c.limits == nil {
+					_, err = deleteQuery.ExecContext(ctx, op.Key)
+					break
+				}
+				var delta int64
+				delta, err = c.limits.delete(ctx, tx, deleteQuery, op.Key)
+				sizeDelta += delta
+			default:
+				return errors.New("wrong operation type")
+			}
 
-		if err != nil {
-			return err
+			if err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if c.limits != nil {
+		c.limits.committed(ctx, sizeDelta, evicted)
 	}
-	return err
+	return nil
 }
 
 // Close will close the database
-func (c *dbStorageClient) Close(_ context.Context) error {
+func (c *dbStorageClient) Close(ctx context.Context) error {
+	if c.cancel != nil {
+		c.cancel()
+		c.loops.Wait()
+	}
 	if err := c.setQuery.Close(); err != nil {
 		return err
 	}
 	if err := c.deleteQuery.Close(); err != nil {
 		return err
 	}
-	return c.getQuery.Close()
+	if err := c.getQuery.Close(); err != nil {
+		return err
+	}
+	if c.lock != nil {
+		return c.lock.release(ctx)
+	}
+	return nil
+}
+
+// inTransaction runs the writes of the client in a transaction, on the connection holding the lock of
+// the client if it is locked.
+func (c *dbStorageClient) inTransaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if c.lock != nil {
+		return c.lock.inTransaction(ctx, fn)
+	}
+	return inTransaction(ctx, c.db, fn)
+}
+
+// inTransaction runs fn in a transaction, which is committed if fn succeeds and rolled back otherwise.
+func inTransaction(ctx context.Context, db txBeginner, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err = fn(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
 }
@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dbstorage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+
+	// Tables created before migrations were introduced are upgraded.
+	_, err := db.Exec(fmt.Sprintf("create table %s (key text primary key, value blob)", testTable))
+	require.NoError(t, err)
+	_, err = db.Exec(fmt.Sprintf("insert into %s(key, value) values('key', 'value')", testTable))
+	require.NoError(t, err)
+
+	client, err := newClient(ctx, "sqlite3", db, testTable, nil, nil)
+	require.NoError(t, err)
+	value, err := client.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+	require.NoError(t, client.Close(ctx))
+
+	var version int
+	require.NoError(t, db.QueryRow(selectVersionQueryText, testTable).Scan(&version))
+	assert.Equal(t, len(migrations), version)
+
+	// Migrating again is a no-op.
+	require.NoError(t, migrate(ctx, db, "sqlite3", testTable))
+
+	// Tables written by a newer collector are not used.
+	_, err = db.Exec(setVersionQueryText, testTable, len(migrations)+1)
+	require.NoError(t, err)
+	_, err = newClient(ctx, "sqlite3", db, testTable, nil, nil)
+	assert.EqualError(t, err, fmt.Sprintf("failed to migrate table %s: schema version %d is newer than the latest supported version %d",
+		testTable, len(migrations)+1, len(migrations)))
+}
+
+func TestBatchIsTransactional(t *testing.T) {
+	ctx := context.Background()
+	client, err := newClient(ctx, "sqlite3", newTestDB(t), testTable, nil, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, client.Close(ctx))
+	})
+
+	require.NoError(t, client.Set(ctx, "key1", []byte("value1")))
+
+	// Operations see the changes of the previous operations of the batch.
+	get := storage.GetOperation("key2")
+	require.NoError(t, client.Batch(ctx,
+		storage.SetOperation("key2", []byte("value2")),
+		get,
+		storage.DeleteOperation("key1"),
+	))
+	assert.Equal(t, []byte("value2"), get.Value)
+
+	// None of the operations are applied if one fails.
+	invalid := storage.GetOperation("key3")
+	invalid.Type = 42
+	assert.EqualError(t, client.Batch(ctx,
+		storage.SetOperation("key1", []byte("value1")),
+		storage.DeleteOperation("key2"),
+		invalid,
+	), "wrong operation type")
+	value, err := client.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+	value, err = client.Get(ctx, "key2")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value2"), value)
+}
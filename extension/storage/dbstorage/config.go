@@ -5,12 +5,30 @@ package dbstorage // import "github.com/open-telemetry/opentelemetry-collector-c
 
 import (
 	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota"
 )
 
 // Config defines configuration for dbstorage extension.
 type Config struct {
 	DriverName string `mapstructure:"driver,omitempty"`
 	DataSource string `mapstructure:"datasource,omitempty"`
+
+	// SQLite configures connections to SQLite databases
+	SQLite SQLiteConfig `mapstructure:"sqlite,omitempty"`
+
+	// Locking configures the ownership of clients by collectors sharing a PostgreSQL database
+	Locking LockingConfig `mapstructure:"locking,omitempty"`
+
+	// Quota limits the size of the data stored by each client
+	Quota quota.Config `mapstructure:"quota,omitempty"`
+
+	// TTL specifies the time after which keys that were not written again are removed. Keys never expire if it is 0.
+	TTL time.Duration `mapstructure:"ttl,omitempty"`
 }
 
 func (cfg *Config) Validate() error {
@@ -20,6 +38,70 @@ func (cfg *Config) Validate() error {
 	if cfg.DriverName == "" {
 		return errors.New("missing driver name")
 	}
+	if cfg.TTL < 0 {
+		return errors.New("ttl cannot be negative")
+	}
+	if cfg.Locking.Enabled && isSQLite(cfg.DriverName) {
+		return errors.New("locking is not supported by SQLite")
+	}
 
 	return nil
 }
+
+// SQLiteConfig defines configuration for connections to SQLite databases. Parameters
+// set in the datasource take precedence.
+type SQLiteConfig struct {
+	// WAL enables write-ahead logging, which lets reads run concurrently with writes and
+	// reduces the number of fsyncs, at the cost of not being usable on network file systems.
+	WAL bool `mapstructure:"wal"`
+	// BusyTimeout is how long to wait for the database to be unlocked by other connections.
+	BusyTimeout time.Duration `mapstructure:"busy_timeout"`
+	// ImmediateTransactions makes transactions take the write lock when they begin, so that concurrent
+	// transactions wait for each other instead of failing when they start writing.
+	ImmediateTransactions bool `mapstructure:"immediate_transactions"`
+}
+
+// sqliteParameters are the names of the parameters set by SQLiteConfig, with their aliases.
+var sqliteParameters = map[string][]string{
+	"_txlock":       {"_txlock"},
+	"_busy_timeout": {"_busy_timeout", "_timeout"},
+	"_journal_mode": {"_journal_mode", "_journal"},
+	"_synchronous":  {"_synchronous", "_sync"},
+}
+
+// dataSource returns the datasource, with the parameters of the SQLite configuration added for SQLite drivers.
+func (cfg *Config) dataSource() string {
+	if !isSQLite(cfg.DriverName) {
+		return cfg.DataSource
+	}
+
+	_, query, hasQuery := strings.Cut(cfg.DataSource, "?")
+	existing, _ := url.ParseQuery(query)
+	params := url.Values{}
+	set := func(name, value string) {
+		for _, alias := range sqliteParameters[name] {
+			if existing.Has(alias) {
+				return
+			}
+		}
+		params.Set(name, value)
+	}
+
+	if cfg.SQLite.ImmediateTransactions {
+		set("_txlock", "immediate")
+	}
+	if cfg.SQLite.BusyTimeout > 0 {
+		set("_busy_timeout", strconv.FormatInt(cfg.SQLite.BusyTimeout.Milliseconds(), 10))
+	}
+	if cfg.SQLite.WAL {
+		set("_journal_mode", "WAL")
+		set("_synchronous", "NORMAL")
+	}
+	if len(params) == 0 {
+		return cfg.DataSource
+	}
+	if hasQuery {
+		return cfg.DataSource + "&" + params.Encode()
+	}
+	return cfg.DataSource + "?" + params.Encode()
+}
@@ -6,6 +6,7 @@ package dbstorage // import "github.com/open-telemetry/opentelemetry-collector-c
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -26,6 +27,16 @@ func TestConfigValidate(t *testing.T) {
 			Config{DriverName: "foo"},
 			errors.New("missing datasource"),
 		},
+		{
+			"Negative ttl",
+			Config{DriverName: "foo", DataSource: "bar", TTL: -time.Second},
+			errors.New("ttl cannot be negative"),
+		},
+		{
+			"Locking with SQLite",
+			Config{DriverName: "sqlite3", DataSource: "bar", Locking: LockingConfig{Enabled: true}},
+			errors.New("locking is not supported by SQLite"),
+		},
 		{
 			"valid",
 			Config{DriverName: "foo", DataSource: "bar"},
@@ -42,3 +53,50 @@ func TestConfigValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestLockingConfigValidate(t *testing.T) {
+	assert.NoError(t, (&LockingConfig{Enabled: true}).Validate())
+	assert.NoError(t, (&LockingConfig{Enabled: true, Timeout: time.Minute, RetryInterval: time.Second}).Validate())
+	assert.EqualError(t, (&LockingConfig{Timeout: -time.Second}).Validate(), "locking timeout cannot be negative")
+	assert.EqualError(t, (&LockingConfig{Timeout: time.Minute}).Validate(), "locking retry_interval must be positive when timeout is set")
+}
+
+func TestDataSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		expected string
+	}{
+		{
+			"postgres",
+			Config{DriverName: "pgx", DataSource: "host=localhost", SQLite: SQLiteConfig{WAL: true}},
+			"host=localhost",
+		},
+		{
+			"sqlite",
+			Config{DriverName: "sqlite3", DataSource: "foo.db"},
+			"foo.db",
+		},
+		{
+			"sqlite immediate transactions",
+			Config{DriverName: "sqlite3", DataSource: "foo.db", SQLite: SQLiteConfig{ImmediateTransactions: true}},
+			"foo.db?_txlock=immediate",
+		},
+		{
+			"sqlite wal",
+			Config{DriverName: "sqlite3", DataSource: "file:foo.db?cache=shared", SQLite: SQLiteConfig{WAL: true, BusyTimeout: 5 * time.Second, ImmediateTransactions: true}},
+			"file:foo.db?cache=shared&_busy_timeout=5000&_journal_mode=WAL&_synchronous=NORMAL&_txlock=immediate",
+		},
+		{
+			"sqlite parameters in datasource take precedence",
+			Config{DriverName: "sqlite3", DataSource: "foo.db?_journal=DELETE&_timeout=100&_txlock=deferred", SQLite: SQLiteConfig{WAL: true, BusyTimeout: 5 * time.Second, ImmediateTransactions: true}},
+			"foo.db?_journal=DELETE&_timeout=100&_txlock=deferred&_synchronous=NORMAL",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.config.dataSource())
+		})
+	}
+}
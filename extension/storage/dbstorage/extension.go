@@ -6,6 +6,7 @@ package dbstorage // import "github.com/open-telemetry/opentelemetry-collector-c
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -13,23 +14,35 @@ import (
 	"go.opentelemetry.io/collector/extension"
 	"go.opentelemetry.io/collector/extension/experimental/storage"
 	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/dbstorage/internal/metadata"
 )
 
 type databaseStorage struct {
 	driverName     string
 	datasourceName string
+	locking        LockingConfig
 	logger         *zap.Logger
 	db             *sql.DB
+	cfg            *Config
+	telemetry      *metadata.TelemetryBuilder
 }
 
 // Ensure this storage extension implements the appropriate interface
 var _ storage.Extension = (*databaseStorage)(nil)
 
-func newDBStorage(logger *zap.Logger, config *Config) (extension.Extension, error) {
+func newDBStorage(set component.TelemetrySettings, config *Config) (extension.Extension, error) {
+	telemetry, err := metadata.NewTelemetryBuilder(set)
+	if err != nil {
+		return nil, err
+	}
 	return &databaseStorage{
 		driverName:     config.DriverName,
-		datasourceName: config.DataSource,
-		logger:         logger,
+		datasourceName: config.dataSource(),
+		locking:        config.Locking,
+		logger:         set.Logger,
+		cfg:            config,
+		telemetry:      telemetry,
 	}, nil
 }
 
@@ -64,7 +77,19 @@ func (ds *databaseStorage) GetClient(ctx context.Context, kind component.Kind, e
 		fullName = fmt.Sprintf("%s_%s_%s_%s", kindString(kind), ent.Type(), ent.Name(), name)
 	}
 	fullName = strings.ReplaceAll(fullName, " ", "")
-	return newClient(ctx, ds.driverName, ds.db, fullName)
+
+	var lock *clientLock
+	if ds.locking.Enabled {
+		var err error
+		if lock, err = acquireClientLock(ctx, ds.db, fullName, ds.locking); err != nil {
+			return nil, err
+		}
+	}
+	client, err := newClient(ctx, ds.driverName, ds.db, fullName, newClientLimits(ds.cfg, ds.telemetry, ds.logger, fullName), lock)
+	if err != nil && lock != nil {
+		return nil, errors.Join(err, lock.release(ctx))
+	}
+	return client, err
 }
 
 func kindString(k component.Kind) string {
@@ -10,6 +10,7 @@ import (
 	"runtime"
 	"sync"
 	"testing"
+	"time"
 
 	ctypes "github.com/docker/docker/api/types/container"
 	"github.com/docker/go-connections/nat"
@@ -117,6 +118,70 @@ func testExtensionIntegrity(t *testing.T, se storage.Extension) {
 	wg.Wait()
 }
 
+func TestClientLockingWithPostgres(t *testing.T) {
+	if runtime.GOOS == "windows" && os.Getenv("GITHUB_ACTIONS") == "true" {
+		t.Skip("Skipping test on Windows GH runners: test requires Docker to be running Linux containers")
+	}
+
+	ctx := context.Background()
+	dataSource := startPostgres(t)
+	newExtension := func() storage.Extension {
+		f := NewFactory()
+		cfg := f.CreateDefaultConfig().(*Config)
+		cfg.DriverName = "pgx"
+		cfg.DataSource = dataSource
+		cfg.Locking = LockingConfig{Enabled: true, Timeout: 2 * time.Second, RetryInterval: 100 * time.Millisecond}
+		ext, err := f.Create(ctx, extensiontest.NewNopSettings(), cfg)
+		require.NoError(t, err)
+		require.NoError(t, ext.Start(ctx, componenttest.NewNopHost()))
+		t.Cleanup(func() {
+			require.NoError(t, ext.Shutdown(ctx))
+		})
+		return ext.(storage.Extension)
+	}
+	active, standby := newExtension(), newExtension()
+
+	client, err := active.GetClient(ctx, component.KindReceiver, newTestEntity("locked"), "")
+	require.NoError(t, err)
+	require.NoError(t, client.Set(ctx, "checkpoint", []byte("1")))
+
+	// Clients owned by another collector cannot be used until they are released.
+	_, err = standby.GetClient(ctx, component.KindReceiver, newTestEntity("locked"), "")
+	require.ErrorIs(t, err, ErrClientLocked)
+	other, err := standby.GetClient(ctx, component.KindReceiver, newTestEntity("other"), "")
+	require.NoError(t, err)
+	require.NoError(t, other.Close(ctx))
+
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		assert.NoError(t, client.Close(ctx))
+	}()
+	client, err = standby.GetClient(ctx, component.KindReceiver, newTestEntity("locked"), "")
+	require.NoError(t, err)
+	value, err := client.Get(ctx, "checkpoint")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+
+	// A collector which lost the connection holding its lock cannot write once another collector owns the client.
+	lock := client.(*dbStorageClient).lock
+	var pid int
+	require.NoError(t, lock.conn.QueryRowContext(ctx, "select pg_backend_pid()").Scan(&pid))
+	_, err = lock.db.ExecContext(ctx, "select pg_terminate_backend($1)", pid)
+	require.NoError(t, err)
+	taken, err := active.GetClient(ctx, component.KindReceiver, newTestEntity("locked"), "")
+	require.NoError(t, err)
+	require.ErrorIs(t, client.Set(ctx, "checkpoint", []byte("2")), ErrClientLocked)
+	require.NoError(t, taken.Set(ctx, "checkpoint", []byte("3")))
+	require.NoError(t, taken.Close(ctx))
+
+	// The lock is acquired again once it is free.
+	require.NoError(t, client.Set(ctx, "checkpoint", []byte("4")))
+	value, err = client.Get(ctx, "checkpoint")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("4"), value)
+	require.NoError(t, client.Close(ctx))
+}
+
 func newSqliteTestExtension(t *testing.T) storage.Extension {
 	f := NewFactory()
 	cfg := f.CreateDefaultConfig().(*Config)
@@ -133,6 +198,22 @@ func newSqliteTestExtension(t *testing.T) storage.Extension {
 }
 
 func newPostgresTestExtension(t *testing.T) storage.Extension {
+	f := NewFactory()
+	cfg := f.CreateDefaultConfig().(*Config)
+	cfg.DriverName = "pgx"
+	cfg.DataSource = startPostgres(t)
+
+	extension, err := f.Create(context.Background(), extensiontest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+
+	se, ok := extension.(storage.Extension)
+	require.True(t, ok)
+
+	return se
+}
+
+// startPostgres starts a PostgreSQL container and returns its datasource.
+func startPostgres(t *testing.T) string {
 	req := testcontainers.GenericContainerRequest{
 		ContainerRequest: testcontainers.ContainerRequest{
 			Image: "postgres:14",
@@ -160,18 +241,7 @@ func newPostgresTestExtension(t *testing.T) storage.Extension {
 	t.Cleanup(func() {
 		require.NoError(t, ctr.Terminate(context.Background()))
 	})
-	f := NewFactory()
-	cfg := f.CreateDefaultConfig().(*Config)
-	cfg.DriverName = "pgx"
-	cfg.DataSource = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", "127.0.0.1", port.Port(), "root", "passwd", "db")
-
-	extension, err := f.Create(context.Background(), extensiontest.NewNopSettings(), cfg)
-	require.NoError(t, err)
-
-	se, ok := extension.(storage.Extension)
-	require.True(t, ok)
-
-	return se
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", "127.0.0.1", port.Port(), "root", "passwd", "db")
 }
 
 func newTestEntity(name string) component.ID {
@@ -7,11 +7,18 @@ package dbstorage // import "github.com/open-telemetry/opentelemetry-collector-c
 
 import (
 	"context"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/extension"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/dbstorage/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota"
+)
+
+const (
+	defaultBusyTimeout       = 10 * time.Second
+	defaultLockRetryInterval = time.Second
 )
 
 // NewFactory creates a factory for DBStorage extension.
@@ -25,7 +32,17 @@ func NewFactory() extension.Factory {
 }
 
 func createDefaultConfig() component.Config {
-	return &Config{}
+	return &Config{
+		SQLite: SQLiteConfig{
+			BusyTimeout: defaultBusyTimeout,
+		},
+		Locking: LockingConfig{
+			RetryInterval: defaultLockRetryInterval,
+		},
+		Quota: quota.Config{
+			OnExceeded: quota.Reject,
+		},
+	}
 }
 
 func createExtension(
@@ -33,5 +50,5 @@ func createExtension(
 	params extension.Settings,
 	cfg component.Config,
 ) (extension.Extension, error) {
-	return newDBStorage(params.Logger, cfg.(*Config))
+	return newDBStorage(params.TelemetrySettings, cfg.(*Config))
 }
@@ -0,0 +1,334 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dbstorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/dbstorage"
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/dbstorage/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota"
+)
+
+const (
+	// maxTTLCheckInterval is the maximum time between checks for expired keys.
+	maxTTLCheckInterval = time.Minute
+	// maxExpiredPerTransaction is the maximum number of expired keys removed in a single transaction.
+	maxExpiredPerTransaction = 1000
+
+	// writeTimesTableSuffix is appended to the table of a client to name the table holding the write times of its keys.
+	writeTimesTableSuffix = "_write_times"
+
+	createWriteTimesTable    = "create table if not exists %s (key text primary key, size bigint, written_at bigint)"
+	dropWriteTimesTable      = "drop table if exists %s"
+	deleteStaleWriteTimes    = "delete from %s where key not in (select key from %s)"
+	selectMissingWriteTimes  = "select key, value from %s where key not in (select key from %s)"
+	selectTotalSize          = "select coalesce(sum(size), 0) from %s"
+	selectSizeQueryText      = "select size from %s where key=$1"
+	selectWrittenAtQueryText = "select written_at from %s where key=$1"
+	setWriteTimeQueryText    = "insert into %s(key, size, written_at) values($1,$2,$3) on conflict(key) do update set size=excluded.size, written_at=excluded.written_at"
+	selectOldestQueryText    = "select key, size from %s where key<>$1 order by written_at, key"
+	selectExpiredQueryText   = "select key, size from %s where written_at<=$1 order by written_at, key limit $2"
+)
+
+// clientLimits enforces the quota and TTL of a client. The size of every key and the time
+// it was last written are stored in a separate table, so that the oldest and expired keys can be found.
+type clientLimits struct {
+	maxBytes   int64
+	evict      bool
+	ttl        time.Duration
+	telemetry  *metadata.TelemetryBuilder
+	attributes metric.MeasurementOption
+	logger     *zap.Logger
+	now        func() time.Time
+
+	table      string
+	timesTable string
+
+	// mu serializes the writes of the client, so that size is accurate
+	mu   sync.Mutex
+	size int64
+}
+
+func newClientLimits(cfg *Config, telemetry *metadata.TelemetryBuilder, logger *zap.Logger, clientName string) *clientLimits {
+	if cfg.Quota.MaxBytes == 0 && cfg.TTL == 0 {
+		return nil
+	}
+	return &clientLimits{
+		maxBytes:   cfg.Quota.MaxBytes,
+		evict:      cfg.Quota.OnExceeded == quota.EvictOldest,
+		ttl:        cfg.TTL,
+		telemetry:  telemetry,
+		attributes: metric.WithAttributeSet(attribute.NewSet(attribute.String("client", clientName))),
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// init creates the table holding the write times and computes the size of the stored data.
+// Keys that were written while limits were not enforced are considered written now.
+func (l *clientLimits) init(ctx context.Context, db *sql.DB, table string) error {
+	l.table = table
+	l.timesTable = table + writeTimesTableSuffix
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(createWriteTimesTable, l.timesTable)); err != nil {
+		return err
+	}
+
+	return inTransaction(ctx, db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(deleteStaleWriteTimes, l.timesTable, l.table)); err != nil {
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(selectMissingWriteTimes, l.table, l.timesTable))
+		if err != nil {
+			return err
+		}
+		sizes := map[string]int64{}
+		for rows.Next() {
+			var key string
+			var value []byte
+			if err = rows.Scan(&key, &value); err != nil {
+				_ = rows.Close()
+				return err
+			}
+			sizes[key] = int64(len(key) + len(value))
+		}
+		if err = errors.Join(rows.Err(), rows.Close()); err != nil {
+			return err
+		}
+		now := l.now().UnixNano()
+		for key, size := range sizes {
+			if err = l.setWriteTime(ctx, tx, key, size, now); err != nil {
+				return err
+			}
+		}
+
+		return tx.QueryRowContext(ctx, fmt.Sprintf(selectTotalSize, l.timesTable)).Scan(&l.size)
+	})
+}
+
+// set stores a value with the given statement of the transaction, after making room for it if needed.
+// pending is the change of the stored size by the previous operations of the transaction.
+// It returns the change of the stored size and the number of evicted keys. It must be called with mu held.
+func (l *clientLimits) set(ctx context.Context, tx *sql.Tx, setQuery *sql.Stmt, key string, value []byte, pending int64) (int64, int64, error) {
+	size := int64(len(key) + len(value))
+	old, err := l.storedSize(ctx, tx, key)
+	if err != nil {
+		return 0, 0, err
+	}
+	delta := size - old
+
+	var evicted int64
+	if l.maxBytes > 0 && l.size+pending+delta > l.maxBytes {
+		if !l.evict || size > l.maxBytes {
+			l.telemetry.DbStorageClientRejectedWrites.Add(ctx, 1, l.attributes)
+			return 0, 0, quota.ErrExceeded
+		}
+		var freed int64
+		if evicted, freed, err = l.evictOldest(ctx, tx, key, l.size+pending+delta-l.maxBytes); err != nil {
+			return 0, 0, err
+		}
+		delta -= freed
+		if l.size+pending+delta > l.maxBytes {
+			l.telemetry.DbStorageClientRejectedWrites.Add(ctx, 1, l.attributes)
+			return 0, 0, quota.ErrExceeded
+		}
+	}
+
+	if _, err = setQuery.ExecContext(ctx, key, value); err != nil {
+		return 0, 0, err
+	}
+	return delta, evicted, l.setWriteTime(ctx, tx, key, size, l.now().UnixNano())
+}
+
+// delete removes a value with the given statement of the transaction, together with its write time.
+// It returns the change of the stored size. It must be called with mu held.
+func (l *clientLimits) delete(ctx context.Context, tx *sql.Tx, deleteQuery *sql.Stmt, key string) (int64, error) {
+	old, err := l.storedSize(ctx, tx, key)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = deleteQuery.ExecContext(ctx, key); err != nil {
+		return 0, err
+	}
+	return -old, l.deleteWriteTime(ctx, tx, key)
+}
+
+// committed applies the changes of a committed transaction. It must be called with mu held.
+func (l *clientLimits) committed(ctx context.Context, delta, evicted int64) {
+	if evicted > 0 {
+		l.telemetry.DbStorageClientEvictedKeys.Add(ctx, evicted, l.attributes)
+	}
+	if delta != 0 {
+		l.add(ctx, delta)
+	}
+}
+
+// expired returns true if the key was written longer than the TTL ago.
+func (l *clientLimits) expired(ctx context.Context, q querier, key string) (bool, error) {
+	if l.ttl == 0 {
+		return false, nil
+	}
+	var writtenAt int64
+	err := q.QueryRowContext(ctx, fmt.Sprintf(selectWrittenAtQueryText, l.timesTable), key).Scan(&writtenAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return l.now().Sub(time.Unix(0, writtenAt)) >= l.ttl, nil
+}
+
+// evictOldest removes the least recently written keys, except for the given one,
+// until at least needed bytes are freed. It returns the number of removed keys and freed bytes.
+func (l *clientLimits) evictOldest(ctx context.Context, tx *sql.Tx, except string, needed int64) (int64, int64, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(selectOldestQueryText, l.timesTable), except)
+	if err != nil {
+		return 0, 0, err
+	}
+	var freed int64
+	var keys []string
+	for freed < needed && rows.Next() {
+		var key string
+		var size int64
+		if err = rows.Scan(&key, &size); err != nil {
+			_ = rows.Close()
+			return 0, 0, err
+		}
+		keys = append(keys, key)
+		freed += size
+	}
+	if err = errors.Join(rows.Err(), rows.Close()); err != nil {
+		return 0, 0, err
+	}
+	return int64(len(keys)), freed, l.deleteKeys(ctx, tx, keys)
+}
+
+// removeExpired removes keys that were written longer than the TTL ago, in transactions
+// of at most maxExpiredPerTransaction keys.
+func (l *clientLimits) removeExpired(ctx context.Context, inTx transactor) error {
+	for {
+		removed, err := l.removeExpiredBatch(ctx, inTx)
+		if err != nil || removed < maxExpiredPerTransaction {
+			return err
+		}
+	}
+}
+
+func (l *clientLimits) removeExpiredBatch(ctx context.Context, inTx transactor) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var keys []string
+	var freed int64
+	err := inTx(ctx, func(tx *sql.Tx) error {
+		cutoff := l.now().Add(-l.ttl).UnixNano()
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(selectExpiredQueryText, l.timesTable), cutoff, maxExpiredPerTransaction)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var key string
+			var size int64
+			if err = rows.Scan(&key, &size); err != nil {
+				_ = rows.Close()
+				return err
+			}
+			keys = append(keys, key)
+			freed += size
+		}
+		if err = errors.Join(rows.Err(), rows.Close()); err != nil {
+			return err
+		}
+		return l.deleteKeys(ctx, tx, keys)
+	})
+	if err != nil || len(keys) == 0 {
+		return 0, err
+	}
+	l.telemetry.DbStorageClientExpiredKeys.Add(ctx, int64(len(keys)), l.attributes)
+	l.add(ctx, -freed)
+	return int64(len(keys)), nil
+}
+
+func (l *clientLimits) deleteKeys(ctx context.Context, tx *sql.Tx, keys []string) error {
+	for _, key := range keys {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(deleteQueryText, l.table), key); err != nil {
+			return err
+		}
+		if err := l.deleteWriteTime(ctx, tx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *clientLimits) storedSize(ctx context.Context, tx *sql.Tx, key string) (int64, error) {
+	var size int64
+	err := tx.QueryRowContext(ctx, fmt.Sprintf(selectSizeQueryText, l.timesTable), key).Scan(&size)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return size, err
+}
+
+func (l *clientLimits) setWriteTime(ctx context.Context, tx *sql.Tx, key string, size, writtenAt int64) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(setWriteTimeQueryText, l.timesTable), key, size, writtenAt)
+	return err
+}
+
+func (l *clientLimits) deleteWriteTime(ctx context.Context, tx *sql.Tx, key string) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(deleteQueryText, l.timesTable), key)
+	return err
+}
+
+// refreshSize reads the size of the stored data again, after other collectors may have changed it.
+// It must be called with mu held.
+func (l *clientLimits) refreshSize(ctx context.Context, tx *sql.Tx) error {
+	var size int64
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf(selectTotalSize, l.timesTable)).Scan(&size); err != nil {
+		return err
+	}
+	l.add(ctx, size-l.size)
+	return nil
+}
+
+// add applies a change of the stored size and reports the new size. It must be called with mu held.
+func (l *clientLimits) add(ctx context.Context, delta int64) {
+	l.size += delta
+	l.telemetry.DbStorageClientSize.Record(ctx, l.size, l.attributes)
+}
+
+// ttlCheckInterval returns how often expired keys are removed.
+func (l *clientLimits) ttlCheckInterval() time.Duration {
+	return min(l.ttl, maxTTLCheckInterval)
+}
+
+// startExpiryLoop periodically removes expired keys until the context is canceled.
+func (l *clientLimits) startExpiryLoop(ctx context.Context, inTx transactor, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(l.ttlCheckInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := l.removeExpired(ctx, inTx); err != nil && !errors.Is(err, context.Canceled) {
+					l.logger.Error("failed to remove expired keys", zap.Error(err))
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
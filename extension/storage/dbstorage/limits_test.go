@@ -0,0 +1,191 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dbstorage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/dbstorage/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota/quotatest"
+)
+
+const testTable = "receiver_test_"
+
+func newTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s/foo.db?_busy_timeout=10000&_journal=WAL&_sync=NORMAL", t.TempDir()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+	return db
+}
+
+// newTestLimits returns limits whose clock is only advanced by the returned function.
+func newTestLimits(t *testing.T, tel *componentTestTelemetry, quotaCfg quota.Config, ttl time.Duration) (*clientLimits, func(time.Duration)) {
+	telemetry, err := metadata.NewTelemetryBuilder(tel.newTelemetrySettings())
+	require.NoError(t, err)
+	limits := newClientLimits(&Config{Quota: quotaCfg, TTL: ttl}, telemetry, zap.NewNop(), testTable)
+	require.NotNil(t, limits)
+	now := time.Unix(1700000000, 0)
+	limits.now = func() time.Time { return now }
+	return limits, func(d time.Duration) { now = now.Add(d) }
+}
+
+func newTestLimitsClient(t *testing.T, db *sql.DB, limits *clientLimits) *dbStorageClient {
+	client, err := newClient(context.Background(), "sqlite3", db, testTable, limits, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, client.Close(context.Background()))
+	})
+	return client
+}
+
+func sizeMetric(size int64) metricdata.Metrics {
+	return metricdata.Metrics{
+		Name:        "otelcol_db_storage_client_size",
+		Description: "Total size of the keys and values stored by a client",
+		Unit:        "By",
+		Data: metricdata.Gauge[int64]{
+			DataPoints: []metricdata.DataPoint[int64]{
+				{Value: size, Attributes: attribute.NewSet(attribute.String("client", testTable))},
+			},
+		},
+	}
+}
+
+func counterMetric(name, description, unit string, value int64) metricdata.Metrics {
+	return metricdata.Metrics{
+		Name:        name,
+		Description: description,
+		Unit:        unit,
+		Data: metricdata.Sum[int64]{
+			Temporality: metricdata.CumulativeTemporality,
+			IsMonotonic: true,
+			DataPoints: []metricdata.DataPoint[int64]{
+				{Value: value, Attributes: attribute.NewSet(attribute.String("client", testTable))},
+			},
+		},
+	}
+}
+
+func TestQuota(t *testing.T) {
+	quotatest.TestClient(t, func(t *testing.T, cfg quota.Config) (storage.Client, func(time.Duration)) {
+		tel := setupTestTelemetry()
+		limits, advance := newTestLimits(t, &tel, cfg, 0)
+		return newTestLimitsClient(t, newTestDB(t), limits), advance
+	})
+}
+
+func TestQuotaRejectBatch(t *testing.T) {
+	ctx := context.Background()
+	tel := setupTestTelemetry()
+	limits, _ := newTestLimits(t, &tel, quota.Config{MaxBytes: 20, OnExceeded: quota.Reject}, 0)
+	client := newTestLimitsClient(t, newTestDB(t), limits)
+
+	require.NoError(t, client.Set(ctx, "key1", []byte("value1")))
+	require.NoError(t, client.Set(ctx, "key2", []byte("value2")))
+
+	// Rejected batches are rolled back.
+	assert.ErrorIs(t, client.Batch(ctx,
+		storage.DeleteOperation("key1"),
+		storage.SetOperation("key3", []byte("value3")),
+		storage.SetOperation("key4", []byte("value4")),
+	), quota.ErrExceeded)
+	value, err := client.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+	assert.Equal(t, int64(20), limits.size)
+}
+
+func TestQuotaTelemetry(t *testing.T) {
+	ctx := context.Background()
+	tel := setupTestTelemetry()
+	limits, advance := newTestLimits(t, &tel, quota.Config{MaxBytes: 20, OnExceeded: quota.EvictOldest}, 0)
+	client := newTestLimitsClient(t, newTestDB(t), limits)
+
+	require.NoError(t, client.Set(ctx, "key1", []byte("value1")))
+	advance(time.Second)
+	require.NoError(t, client.Set(ctx, "key2", []byte("value2")))
+	advance(time.Second)
+	require.NoError(t, client.Set(ctx, "key3", []byte("value3")))
+	assert.ErrorIs(t, client.Set(ctx, "large", make([]byte, 20)), quota.ErrExceeded)
+
+	tel.assertMetrics(t, []metricdata.Metrics{
+		sizeMetric(20),
+		counterMetric("otelcol_db_storage_client_evicted_keys",
+			"Number of keys evicted to stay within the client quota", "{keys}", 1),
+		counterMetric("otelcol_db_storage_client_rejected_writes",
+			"Number of writes rejected because they would exceed the client quota", "{writes}", 1),
+	})
+}
+
+func TestTTL(t *testing.T) {
+	ctx := context.Background()
+	tel := setupTestTelemetry()
+	db := newTestDB(t)
+	limits, advance := newTestLimits(t, &tel, quota.Config{OnExceeded: quota.Reject}, time.Hour)
+	client := newTestLimitsClient(t, db, limits)
+
+	require.NoError(t, client.Set(ctx, "old", []byte("value")))
+	advance(30 * time.Minute)
+	require.NoError(t, client.Set(ctx, "new", []byte("value")))
+	advance(30 * time.Minute)
+
+	// Expired keys are not returned, even before they are removed.
+	value, err := client.Get(ctx, "old")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+	value, err = client.Get(ctx, "new")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	require.NoError(t, limits.removeExpired(ctx, client.inTransaction))
+	var count int
+	require.NoError(t, db.QueryRow("select count(*) from "+testTable).Scan(&count))
+	assert.Equal(t, 1, count)
+	require.NoError(t, db.QueryRow("select count(*) from "+testTable+writeTimesTableSuffix).Scan(&count))
+	assert.Equal(t, 1, count)
+
+	tel.assertMetrics(t, []metricdata.Metrics{
+		sizeMetric(8),
+		counterMetric("otelcol_db_storage_client_expired_keys",
+			"Number of keys removed because their TTL expired", "{keys}", 1),
+	})
+}
+
+func TestLimitsInit(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	tel := setupTestTelemetry()
+
+	// Keys written without limits are considered written when limits are enforced.
+	client, err := newClient(ctx, "sqlite3", db, testTable, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, client.Set(ctx, "key1", []byte("value")))
+	require.NoError(t, client.Set(ctx, "key2", []byte("value")))
+	require.NoError(t, client.Close(ctx))
+
+	limits, advance := newTestLimits(t, &tel, quota.Config{OnExceeded: quota.Reject}, time.Hour)
+	client = newTestLimitsClient(t, db, limits)
+	assert.Equal(t, int64(18), limits.size)
+
+	advance(time.Hour)
+	require.NoError(t, limits.removeExpired(ctx, client.inTransaction))
+	value, err := client.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+	assert.Equal(t, int64(0), limits.size)
+}
@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dbstorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/dbstorage"
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// ErrClientLocked is returned when the client is owned by another collector.
+var ErrClientLocked = errors.New("client is owned by another collector")
+
+// LockingConfig defines configuration for the ownership of clients by collectors sharing a PostgreSQL database.
+type LockingConfig struct {
+	// Enabled specifies that a client can only be used by one collector at a time,
+	// using a PostgreSQL session level advisory lock named after the client.
+	Enabled bool `mapstructure:"enabled"`
+	// Timeout is how long to wait for a client owned by another collector. It fails immediately if 0.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// RetryInterval is the time between attempts to acquire a client owned by another collector.
+	RetryInterval time.Duration `mapstructure:"retry_interval"`
+}
+
+func (cfg *LockingConfig) Validate() error {
+	if cfg.Timeout < 0 {
+		return errors.New("locking timeout cannot be negative")
+	}
+	if cfg.Timeout > 0 && cfg.RetryInterval <= 0 {
+		return errors.New("locking retry_interval must be positive when timeout is set")
+	}
+	return nil
+}
+
+// clientLock is an advisory lock owning a client. Session level advisory locks are held
+// by a database connection, which is reserved until the lock is released. The lock is
+// released by the database if the connection is lost, e.g. when the collector crashes.
+//
+// The writes of the client are fenced by the lock: they run in transactions on the connection
+// holding it, so that a collector which lost its lock cannot commit writes over the ones of the
+// collector which acquired it since.
+type clientLock struct {
+	db    *sql.DB
+	table string
+	id    int64
+	// acquiredAgain is called in the first transaction after the lock was acquired again,
+	// as the data may have been changed by other collectors in the meantime.
+	acquiredAgain func(ctx context.Context, tx *sql.Tx) error
+
+	// mu serializes the transactions on conn
+	mu    sync.Mutex
+	conn  *sql.Conn
+	stale bool
+}
+
+// acquireClientLock acquires the advisory lock of the table of a client, retrying until the timeout expires.
+func acquireClientLock(ctx context.Context, db *sql.DB, table string, cfg LockingConfig) (*clientLock, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lock := &clientLock{db: db, table: table, id: clientLockID(table), conn: conn}
+
+	deadline := time.Now().Add(cfg.Timeout)
+	for {
+		var acquired bool
+		if acquired, err = lock.tryLock(ctx, conn); err != nil {
+			return nil, errors.Join(err, conn.Close())
+		}
+		if acquired {
+			return lock, nil
+		}
+		if !time.Now().Add(cfg.RetryInterval).Before(deadline) {
+			return nil, errors.Join(fmt.Errorf("failed to acquire %s: %w", table, ErrClientLocked), conn.Close())
+		}
+		select {
+		case <-time.After(cfg.RetryInterval):
+		case <-ctx.Done():
+			return nil, errors.Join(ctx.Err(), conn.Close())
+		}
+	}
+}
+
+// clientLockID returns the ID of the advisory lock of the table of a client.
+func clientLockID(table string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("otelcol_dbstorage:" + table))
+	return int64(h.Sum64())
+}
+
+func (l *clientLock) tryLock(ctx context.Context, conn *sql.Conn) (bool, error) {
+	var acquired bool
+	err := conn.QueryRowContext(ctx, "select pg_try_advisory_lock($1)", l.id).Scan(&acquired)
+	return acquired, err
+}
+
+// inTransaction runs fn in a transaction on the connection holding the lock. If the connection
+// was lost, and the lock with it, the lock is acquired again on a new connection first, which
+// fails if another collector owns the client.
+func (l *clientLock) inTransaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.conn.PingContext(ctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err = l.acquireAgain(ctx); err != nil {
+			return err
+		}
+	}
+	err := inTransaction(ctx, l.conn, func(tx *sql.Tx) error {
+		if l.stale && l.acquiredAgain != nil {
+			if err := l.acquiredAgain(ctx, tx); err != nil {
+				return err
+			}
+		}
+		return fn(tx)
+	})
+	if err == nil {
+		l.stale = false
+	}
+	return err
+}
+
+// acquireAgain acquires the lock on a new connection after its connection was lost. It must be called with mu held.
+func (l *clientLock) acquireAgain(ctx context.Context) error {
+	// the connection is already unusable
+	_ = l.conn.Close()
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	acquired, err := l.tryLock(ctx, conn)
+	if err != nil {
+		return errors.Join(err, conn.Close())
+	}
+	if !acquired {
+		return errors.Join(fmt.Errorf("lost %s: %w", l.table, ErrClientLocked), conn.Close())
+	}
+	l.conn = conn
+	l.stale = true
+	return nil
+}
+
+// release releases the lock and returns the connection holding it to the pool.
+func (l *clientLock) release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, err := l.conn.ExecContext(ctx, "select pg_advisory_unlock($1)", l.id)
+	if errors.Is(err, sql.ErrConnDone) {
+		// the lock was lost with its connection
+		return nil
+	}
+	return errors.Join(err, l.conn.Close())
+}
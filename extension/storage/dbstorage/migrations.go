@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package dbstorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/dbstorage"
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// migrationsTable records the schema version of the table of every client.
+	migrationsTable = "otelcol_dbstorage_migrations"
+
+	createMigrationsTable  = "create table if not exists " + migrationsTable + " (table_name text primary key, version integer not null)"
+	selectVersionQueryText = "select version from " + migrationsTable + " where table_name=$1"
+	setVersionQueryText    = "insert into " + migrationsTable + "(table_name, version) values($1,$2) on conflict(table_name) do update set version=excluded.version"
+
+	// migrationLockID is the PostgreSQL advisory lock that serializes the migrations of collectors sharing a database.
+	migrationLockID = 0x6f74656c6d696772
+)
+
+// migration upgrades the table of a client to the next schema version. Statements are
+// formatted with the name of the table.
+type migration struct {
+	sqlite   []string
+	postgres []string
+}
+
+// migrations are applied in order to the table of a client. The schema version of a table is
+// the number of migrations applied to it. Migrations must never be changed once released,
+// new ones are appended instead.
+var migrations = []migration{
+	// 1: the table of a client, as created before migrations were introduced
+	{
+		sqlite:   []string{"create table if not exists %s (key text primary key, value blob)"},
+		postgres: []string{"create table if not exists %s (key text primary key, value text)"},
+	},
+	// 2: store values as binary data, as they are not necessarily valid text
+	{
+		postgres: []string{"alter table %s alter column value type bytea using convert_to(value, 'UTF8')"},
+	},
+}
+
+func (m migration) statements(driverName string) []string {
+	if isSQLite(driverName) {
+		return m.sqlite
+	}
+	return m.postgres
+}
+
+// isSQLite returns true for the drivers of SQLite, any other driver is expected to be compatible with PostgreSQL.
+func isSQLite(driverName string) bool {
+	return strings.HasPrefix(driverName, "sqlite")
+}
+
+// migrate upgrades the table of a client to the latest schema version in a single transaction.
+// It fails for tables with a newer schema version, written by a newer collector.
+func migrate(ctx context.Context, db *sql.DB, driverName, table string) error {
+	err := inTransaction(ctx, db, func(tx *sql.Tx) error {
+		return migrateTx(ctx, tx, driverName, table)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate table %s: %w", table, err)
+	}
+	return nil
+}
+
+func migrateTx(ctx context.Context, tx *sql.Tx, driverName, table string) error {
+	if !isSQLite(driverName) {
+		// released when the transaction ends
+		if _, err := tx.ExecContext(ctx, "select pg_advisory_xact_lock($1)", int64(migrationLockID)); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, createMigrationsTable); err != nil {
+		return err
+	}
+
+	var version int
+	err := tx.QueryRowContext(ctx, selectVersionQueryText, table).Scan(&version)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("schema version %d is newer than the latest supported version %d", version, len(migrations))
+	}
+	if version == len(migrations) {
+		return nil
+	}
+
+	for _, m := range migrations[version:] {
+		for _, statement := range m.statements(driverName) {
+			if _, err = tx.ExecContext(ctx, fmt.Sprintf(statement, table)); err != nil {
+				return err
+			}
+		}
+	}
+	_, err = tx.ExecContext(ctx, setVersionQueryText, table, len(migrations))
+	return err
+}
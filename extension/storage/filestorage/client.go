@@ -35,6 +35,8 @@ type fileStorageClient struct {
 	db              *bbolt.DB
 	compactionCfg   *CompactionConfig
 	openTimeout     time.Duration
+	encryptor       *encryptor
+	limits          *clientLimits
 	cancel          context.CancelFunc
 	closed          bool
 }
@@ -48,7 +50,7 @@ func bboltOptions(timeout time.Duration, noSync bool) *bbolt.Options {
 	}
 }
 
-func newClient(logger *zap.Logger, filePath string, timeout time.Duration, compactionCfg *CompactionConfig, noSync bool) (*fileStorageClient, error) {
+func newClient(logger *zap.Logger, filePath string, timeout time.Duration, compactionCfg *CompactionConfig, noSync bool, enc *encryptor, limits *clientLimits) (*fileStorageClient, error) {
 	options := bboltOptions(timeout, noSync)
 	db, err := bbolt.Open(filePath, 0o600, options)
 	if err != nil {
@@ -56,17 +58,29 @@ func newClient(logger *zap.Logger, filePath string, timeout time.Duration, compa
 	}
 
 	initBucket := func(tx *bbolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists(defaultBucket)
-		return err
+		if _, err := tx.CreateBucketIfNotExists(defaultBucket); err != nil {
+			return err
+		}
+		return limits.init(tx)
 	}
 	if err := db.Update(initBucket); err != nil {
 		_ = db.Close()
 		return nil, err
 	}
 
-	client := &fileStorageClient{logger: logger, db: db, compactionCfg: compactionCfg, openTimeout: timeout}
-	if compactionCfg.OnRebound {
-		client.startCompactionLoop(context.Background())
+	client := &fileStorageClient{logger: logger, db: db, compactionCfg: compactionCfg, openTimeout: timeout, encryptor: enc, limits: limits}
+	if limits != nil {
+		limits.add(context.Background(), 0)
+	}
+	if compactionCfg.OnRebound || (limits != nil && limits.ttl > 0) {
+		var ctx context.Context
+		ctx, client.cancel = context.WithCancel(context.Background())
+		if compactionCfg.OnRebound {
+			client.startCompactionLoop(ctx)
+		}
+		if limits != nil && limits.ttl > 0 {
+			client.startExpiryLoop(ctx)
+		}
 	}
 
 	return client, nil
@@ -94,7 +108,11 @@ func (c *fileStorageClient) Delete(ctx context.Context, key string) error {
 }
 
 // Batch executes the specified operations in order. Get operation results are updated in place
-func (c *fileStorageClient) Batch(_ context.Context, ops ...storage.Operation) error {
+func (c *fileStorageClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	// sizeDelta is the change of the stored size, which is applied before the transaction is committed
+	// so that it is taken into account by the next one
+	var sizeDelta int64
+	var sizeApplied bool
 	batch := func(tx *bbolt.Tx) error {
 		bucket := tx.Bucket(defaultBucket)
 		if bucket == nil {
@@ -106,17 +124,48 @@ func (c *fileStorageClient) Batch(_ context.Context, ops ...storage.Operation) e
 			switch op.Type {
 			case storage.Get:
 				value := bucket.Get([]byte(op.Key))
-				if value != nil {
+				if value != nil && c.limits != nil && c.limits.expired(tx, []byte(op.Key)) {
+					// expired values are removed periodically
+					value = nil
+				}
+				switch {
+				case value == nil:
+					op.Value = nil
+				case c.encryptor != nil:
+					// decryption returns a new slice unless the value was stored before encryption was enabled
+					if value, err = c.encryptor.decrypt([]byte(op.Key), value); err != nil {
+						return fmt.Errorf("failed to get %q: %w", op.Key, err)
+					}
+					fallthrough
+				default:
 					// the output of Bucket.Get is only valid within a transaction, so we need to make a copy
 					// to be able to return the value
 					op.Value = make([]byte, len(value))
 					copy(op.Value, value)
-				} else {
-					op.Value = nil
 				}
 			case storage.Set:
-				err = bucket.Put([]byte(op.Key), op.Value)
+				value := op.Value
+				if c.encryptor != nil {
+					if value, err = c.encryptor.encrypt([]byte(op.Key), value); err != nil {
+						return err
+					}
+				}
+				if c.limits != nil {
+					var delta int64
+					if delta, err = c.limits.set(ctx, tx, []byte(op.Key), value, sizeDelta); err != nil {
+						return err
+					}
+					sizeDelta += delta
+				}
+				err = bucket.Put([]byte(op.Key), value)
 			case storage.Delete:
+				if c.limits != nil {
+					var delta int64
+					if delta, err = c.limits.delete(tx, []byte(op.Key)); err != nil {
+						return err
+					}
+					sizeDelta += delta
+				}
 				err = bucket.Delete([]byte(op.Key))
 			default:
 				return errors.New("wrong operation type")
@@ -127,12 +176,21 @@ func (c *fileStorageClient) Batch(_ context.Context, ops ...storage.Operation) e
 			}
 		}
 
+		if c.limits != nil && sizeDelta != 0 {
+			c.limits.add(ctx, sizeDelta)
+			sizeApplied = true
+		}
 		return nil
 	}
 
 	c.compactionMutex.RLock()
 	defer c.compactionMutex.RUnlock()
-	return c.db.Update(batch)
+	err := c.db.Update(batch)
+	if err != nil && sizeApplied {
+		// the transaction failed after the size was updated, when committing it
+		c.limits.add(ctx, -sizeDelta)
+	}
+	return err
 }
 
 // Close will close the database
@@ -199,6 +257,21 @@ func (c *fileStorageClient) Compact(compactionDirectory string, timeout time.Dur
 		return err
 	}
 
+	// values that are not encrypted with the current key, because it was rotated or encryption
+	// was enabled after they were stored, are encrypted with the current key
+	if c.encryptor != nil {
+		var reencrypted int
+		if reencrypted, err = c.encryptor.reencrypt(compactedDb, maxTransactionSize); err != nil {
+			compactedDb.Close()
+			return err
+		}
+		if reencrypted > 0 {
+			c.logger.Info("re-encrypted values during compaction",
+				zap.String(directoryKey, c.db.Path()),
+				zap.Int("count", reencrypted))
+		}
+	}
+
 	dbPath := c.db.Path()
 	compactedDbPath := compactedDb.Path()
 
@@ -241,8 +314,6 @@ func (c *fileStorageClient) Compact(compactionDirectory string, timeout time.Dur
 
 // startCompactionLoop provides asynchronous compaction function
 func (c *fileStorageClient) startCompactionLoop(ctx context.Context) {
-	ctx, c.cancel = context.WithCancel(ctx)
-
 	go func() {
 		c.logger.Debug("starting compaction loop",
 			zap.Duration("compaction_check_interval", c.compactionCfg.CheckInterval))
@@ -10,6 +10,8 @@ import (
 	"os"
 	"strconv"
 	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota"
 )
 
 var (
@@ -24,6 +26,15 @@ type Config struct {
 
 	Compaction *CompactionConfig `mapstructure:"compaction,omitempty"`
 
+	// Encryption specifies that the stored values are encrypted with AES-GCM
+	Encryption *EncryptionConfig `mapstructure:"encryption,omitempty"`
+
+	// Quota limits the size of the data stored by each client
+	Quota quota.Config `mapstructure:"quota,omitempty"`
+
+	// TTL specifies the time after which keys that were not written again are removed. Keys never expire if it is 0.
+	TTL time.Duration `mapstructure:"ttl,omitempty"`
+
 	// FSync specifies that fsync should be called after each database write
 	FSync bool `mapstructure:"fsync,omitempty"`
 
@@ -92,6 +103,10 @@ func (cfg *Config) Validate() error {
 		return errors.New("compaction check interval must be positive when rebound compaction is set")
 	}
 
+	if cfg.TTL < 0 {
+		return errors.New("ttl cannot be negative")
+	}
+
 	if cfg.CreateDirectory {
 		permissions, err := strconv.ParseInt(cfg.DirectoryPermissions, 8, 32)
 		if err != nil {
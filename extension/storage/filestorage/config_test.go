@@ -18,6 +18,7 @@ import (
 	"go.opentelemetry.io/collector/extension"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/filestorage/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -49,12 +50,29 @@ func TestLoadConfig(t *testing.T) {
 					CheckInterval:              time.Second * 5,
 					CleanupOnStart:             true,
 				},
+				Quota: quota.Config{
+					MaxBytes:   104857600,
+					OnExceeded: quota.EvictOldest,
+				},
+				TTL:                  24 * time.Hour,
 				Timeout:              2 * time.Second,
 				FSync:                true,
 				CreateDirectory:      false,
 				DirectoryPermissions: "0750",
 			},
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "encryption"),
+			expected: func() component.Config {
+				ret := NewFactory().CreateDefaultConfig()
+				ret.(*Config).Directory = "."
+				ret.(*Config).Encryption = &EncryptionConfig{
+					Key:          KeySource{File: "/etc/otelcol/storage.key"},
+					PreviousKeys: []KeySource{{Env: "OTELCOL_STORAGE_PREVIOUS_KEY"}},
+				}
+				return ret
+			}(),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.id.String(), func(t *testing.T) {
@@ -271,3 +289,106 @@ func TestCompactionDirectory(t *testing.T) {
 		})
 	}
 }
+
+func TestEncryptionConfigValidate(t *testing.T) {
+	providerID := component.MustNewID("keyprovider")
+	tests := []struct {
+		name string
+		cfg  EncryptionConfig
+		err  string
+	}{
+		{
+			name: "key file",
+			cfg:  EncryptionConfig{Key: KeySource{File: "key"}},
+		},
+		{
+			name: "key provider",
+			cfg:  EncryptionConfig{KeyProvider: &providerID},
+		},
+		{
+			name: "no key",
+			cfg:  EncryptionConfig{},
+			err:  "encryption key: exactly one of file or env must be set",
+		},
+		{
+			name: "file and env",
+			cfg:  EncryptionConfig{Key: KeySource{File: "key", Env: "KEY"}},
+			err:  "encryption key: exactly one of file or env must be set",
+		},
+		{
+			name: "previous key",
+			cfg:  EncryptionConfig{Key: KeySource{Env: "KEY"}, PreviousKeys: []KeySource{{}}},
+			err:  "encryption previous_keys[0]: exactly one of file or env must be set",
+		},
+		{
+			name: "key and key provider",
+			cfg:  EncryptionConfig{Key: KeySource{Env: "KEY"}, KeyProvider: &providerID},
+			err:  "encryption key_provider cannot be used together with key or previous_keys",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestQuotaConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		err    string
+	}{
+		{
+			name: "default",
+		},
+		{
+			name: "evict oldest",
+			modify: func(cfg *Config) {
+				cfg.Quota = quota.Config{MaxBytes: 1024, OnExceeded: quota.EvictOldest}
+				cfg.TTL = time.Hour
+			},
+		},
+		{
+			name: "negative max bytes",
+			modify: func(cfg *Config) {
+				cfg.Quota.MaxBytes = -1
+			},
+			err: "quota max_bytes cannot be negative",
+		},
+		{
+			name: "invalid on exceeded",
+			modify: func(cfg *Config) {
+				cfg.Quota.OnExceeded = "drop"
+			},
+			err: `quota on_exceeded must be "reject" or "evict_oldest", got "drop"`,
+		},
+		{
+			name: "negative ttl",
+			modify: func(cfg *Config) {
+				cfg.TTL = -time.Second
+			},
+			err: "ttl cannot be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewFactory().CreateDefaultConfig().(*Config)
+			cfg.Directory = t.TempDir()
+			if tt.modify != nil {
+				tt.modify(cfg)
+			}
+			err := component.ValidateConfig(cfg)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
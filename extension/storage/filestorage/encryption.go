@@ -0,0 +1,289 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package filestorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/filestorage"
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.etcd.io/bbolt"
+	"go.opentelemetry.io/collector/component"
+)
+
+const (
+	// encryptedValueMagic prefixes encrypted values, so that they can be told apart from
+	// values that were stored before encryption was enabled.
+	encryptedValueMagic = "\x00OTELENC"
+	keyIDSize           = 8
+	headerSize          = len(encryptedValueMagic) + 1 + keyIDSize
+	valueFormat         = byte(1)
+)
+
+// KeyProvider is implemented by extensions that provide the keys used to encrypt the stored values.
+type KeyProvider interface {
+	// EncryptionKeys returns the key that values are encrypted with, followed by
+	// any previous keys that are still needed to decrypt existing values.
+	// Keys must be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+	EncryptionKeys(ctx context.Context) ([][]byte, error)
+}
+
+// EncryptionConfig defines configuration for encrypting the stored values.
+type EncryptionConfig struct {
+	// Key is the key that values are encrypted with.
+	Key KeySource `mapstructure:"key,omitempty"`
+	// PreviousKeys are only used to decrypt values that were stored before the key was rotated.
+	PreviousKeys []KeySource `mapstructure:"previous_keys,omitempty"`
+	// KeyProvider is the ID of an extension that implements KeyProvider, used instead of Key and PreviousKeys.
+	KeyProvider *component.ID `mapstructure:"key_provider,omitempty"`
+	// MigrateUnencrypted allows reading the values stored before encryption was enabled, until
+	// compaction encrypts them. Unencrypted values are rejected otherwise.
+	MigrateUnencrypted bool `mapstructure:"migrate_unencrypted,omitempty"`
+}
+
+// KeySource defines where a base64 encoded key is read from.
+type KeySource struct {
+	// File is the path of a file that holds the key.
+	File string `mapstructure:"file,omitempty"`
+	// Env is the name of an environment variable that holds the key.
+	Env string `mapstructure:"env,omitempty"`
+}
+
+func (cfg *EncryptionConfig) Validate() error {
+	if cfg.KeyProvider != nil {
+		if cfg.Key != (KeySource{}) || len(cfg.PreviousKeys) > 0 {
+			return errors.New("encryption key_provider cannot be used together with key or previous_keys")
+		}
+		return nil
+	}
+	if err := cfg.Key.validate(); err != nil {
+		return fmt.Errorf("encryption key: %w", err)
+	}
+	for i, key := range cfg.PreviousKeys {
+		if err := key.validate(); err != nil {
+			return fmt.Errorf("encryption previous_keys[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (s KeySource) validate() error {
+	if (s.File == "") == (s.Env == "") {
+		return errors.New("exactly one of file or env must be set")
+	}
+	return nil
+}
+
+func (s KeySource) read() ([]byte, error) {
+	var encoded string
+	if s.File != "" {
+		data, err := os.ReadFile(s.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+		encoded = string(data)
+	} else {
+		var ok bool
+		if encoded, ok = os.LookupEnv(s.Env); !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("key is not base64 encoded: %w", err)
+	}
+	return key, nil
+}
+
+// loadKeys returns the configured keys, the key that values are encrypted with first.
+func (cfg *EncryptionConfig) loadKeys(ctx context.Context, host component.Host) ([][]byte, error) {
+	if cfg.KeyProvider != nil {
+		ext, ok := host.GetExtensions()[*cfg.KeyProvider]
+		if !ok {
+			return nil, fmt.Errorf("key provider %s not found", cfg.KeyProvider)
+		}
+		provider, ok := ext.(KeyProvider)
+		if !ok {
+			return nil, fmt.Errorf("extension %s is not a key provider", cfg.KeyProvider)
+		}
+		keys, err := provider.EncryptionKeys(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get keys from %s: %w", cfg.KeyProvider, err)
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("key provider %s returned no keys", cfg.KeyProvider)
+		}
+		return keys, nil
+	}
+
+	keys := make([][]byte, 0, 1+len(cfg.PreviousKeys))
+	for _, source := range append([]KeySource{cfg.Key}, cfg.PreviousKeys...) {
+		key, err := source.read()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// encryptor encrypts values with AES-GCM. Every value is prefixed by a header
+// that identifies the key it was encrypted with, so that keys can be rotated.
+type encryptor struct {
+	currentID [keyIDSize]byte
+	aeads     map[[keyIDSize]byte]cipher.AEAD
+	// migrateUnencrypted allows reading the values that are not encrypted.
+	migrateUnencrypted bool
+}
+
+func newEncryptor(keys [][]byte, migrateUnencrypted bool) (*encryptor, error) {
+	e := &encryptor{
+		aeads:              make(map[[keyIDSize]byte]cipher.AEAD, len(keys)),
+		migrateUnencrypted: migrateUnencrypted,
+	}
+	for i, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key: %w", err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		id := keyID(key)
+		if i == 0 {
+			e.currentID = id
+		}
+		if _, ok := e.aeads[id]; !ok {
+			e.aeads[id] = aead
+		}
+	}
+	return e, nil
+}
+
+// keyID identifies a key without revealing it.
+func keyID(key []byte) [keyIDSize]byte {
+	var id [keyIDSize]byte
+	sum := sha256.Sum256(key)
+	copy(id[:], sum[:])
+	return id
+}
+
+// additionalData returns the data authenticated along with a value: its header and the storage key
+// it is stored under, so that neither the header can be altered nor values moved between storage keys.
+func additionalData(header, key []byte) []byte {
+	return append(bytes.Clone(header), key...)
+}
+
+func (e *encryptor) encrypt(key, value []byte) ([]byte, error) {
+	aead := e.aeads[e.currentID]
+	out := make([]byte, headerSize+aead.NonceSize(), headerSize+aead.NonceSize()+len(value)+aead.Overhead())
+	copy(out, encryptedValueMagic)
+	out[len(encryptedValueMagic)] = valueFormat
+	copy(out[len(encryptedValueMagic)+1:], e.currentID[:])
+	nonce := out[headerSize:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, nonce, value, additionalData(out[:headerSize], key)), nil
+}
+
+// decrypt returns the plaintext of the value stored under key. Values that are not encrypted are
+// returned as is when migrating unencrypted values, and rejected otherwise.
+func (e *encryptor) decrypt(key, value []byte) ([]byte, error) {
+	if !isEncrypted(value) {
+		if !e.migrateUnencrypted {
+			return nil, errors.New("value is not encrypted, set migrate_unencrypted to read values stored before encryption was enabled")
+		}
+		return value, nil
+	}
+	if value[len(encryptedValueMagic)] != valueFormat {
+		return nil, fmt.Errorf("unsupported encrypted value format %d", value[len(encryptedValueMagic)])
+	}
+	var id [keyIDSize]byte
+	copy(id[:], value[len(encryptedValueMagic)+1:headerSize])
+	aead, ok := e.aeads[id]
+	if !ok {
+		return nil, errors.New("value is encrypted with an unknown key")
+	}
+	if len(value) < headerSize+aead.NonceSize() {
+		return nil, errors.New("encrypted value is too short")
+	}
+	nonce := value[headerSize : headerSize+aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, value[headerSize+aead.NonceSize():], additionalData(value[:headerSize], key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return plaintext, nil
+}
+
+// needsReencryption returns true for values that are not encrypted with the current key. Values that
+// are not encrypted at all are only encrypted when migrating unencrypted values.
+func (e *encryptor) needsReencryption(value []byte) bool {
+	if !isEncrypted(value) {
+		return e.migrateUnencrypted
+	}
+	return !bytes.Equal(value[len(encryptedValueMagic)+1:headerSize], e.currentID[:])
+}
+
+// reencrypt encrypts all values of db that need to be encrypted with the current key,
+// in transactions of at most maxTransactionSize values.
+func (e *encryptor) reencrypt(db *bbolt.DB, maxTransactionSize int64) (int, error) {
+	var keys [][]byte
+	err := db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(defaultBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			if v != nil && e.needsReencryption(v) {
+				keys = append(keys, bytes.Clone(k))
+			}
+			return nil
+		})
+	})
+	if err != nil || len(keys) == 0 {
+		return 0, err
+	}
+
+	if maxTransactionSize <= 0 {
+		maxTransactionSize = int64(len(keys))
+	}
+	for start := 0; start < len(keys); start += int(maxTransactionSize) {
+		end := min(start+int(maxTransactionSize), len(keys))
+		err = db.Update(func(tx *bbolt.Tx) error {
+			bucket := tx.Bucket(defaultBucket)
+			for _, k := range keys[start:end] {
+				plaintext, err := e.decrypt(k, bucket.Get(k))
+				if err != nil {
+					return fmt.Errorf("failed to re-encrypt value: %w", err)
+				}
+				encrypted, err := e.encrypt(k, plaintext)
+				if err != nil {
+					return err
+				}
+				if err = bucket.Put(k, encrypted); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return start, err
+		}
+	}
+	return len(keys), nil
+}
+
+func isEncrypted(value []byte) bool {
+	return len(value) >= headerSize && bytes.HasPrefix(value, []byte(encryptedValueMagic))
+}
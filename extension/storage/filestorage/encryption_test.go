@@ -0,0 +1,228 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package filestorage
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.uber.org/zap"
+)
+
+var (
+	testKey1 = bytes.Repeat([]byte{1}, 32)
+	testKey2 = bytes.Repeat([]byte{2}, 16)
+)
+
+func newTestEncryptor(t *testing.T, keys ...[]byte) *encryptor {
+	enc, err := newEncryptor(keys, true)
+	require.NoError(t, err)
+	return enc
+}
+
+// rawValue returns the value of key as it is stored in the database file.
+func rawValue(t *testing.T, client *fileStorageClient, key string) []byte {
+	var value []byte
+	require.NoError(t, client.db.View(func(tx *bbolt.Tx) error {
+		value = bytes.Clone(tx.Bucket(defaultBucket).Get([]byte(key)))
+		return nil
+	}))
+	return value
+}
+
+func TestEncryptor(t *testing.T) {
+	enc := newTestEncryptor(t, testKey1)
+	key := []byte("key")
+
+	encrypted, err := enc.encrypt(key, []byte("secret"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(encrypted), "secret")
+	assert.False(t, enc.needsReencryption(encrypted))
+
+	decrypted, err := enc.decrypt(key, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), decrypted)
+
+	// Values stored before encryption was enabled are returned as is.
+	decrypted, err = enc.decrypt(key, []byte("plain"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("plain"), decrypted)
+	assert.True(t, enc.needsReencryption([]byte("plain")))
+
+	// Values cannot be moved to another storage key.
+	_, err = enc.decrypt([]byte("other"), encrypted)
+	assert.ErrorContains(t, err, "failed to decrypt value")
+
+	// Tampered values are detected.
+	encrypted[len(encrypted)-1] ^= 1
+	_, err = enc.decrypt(key, encrypted)
+	assert.ErrorContains(t, err, "failed to decrypt value")
+
+	// Values encrypted with keys that are no longer configured cannot be decrypted.
+	encrypted, err = newTestEncryptor(t, testKey2).encrypt(key, []byte("secret"))
+	require.NoError(t, err)
+	_, err = enc.decrypt(key, encrypted)
+	assert.EqualError(t, err, "value is encrypted with an unknown key")
+
+	_, err = newEncryptor([][]byte{[]byte("short")}, false)
+	assert.ErrorContains(t, err, "invalid encryption key")
+}
+
+func TestEncryptorUnencryptedValues(t *testing.T) {
+	enc, err := newEncryptor([][]byte{testKey1}, false)
+	require.NoError(t, err)
+
+	// Values that are not encrypted are rejected unless migrating them.
+	_, err = enc.decrypt([]byte("key"), []byte("plain"))
+	assert.ErrorContains(t, err, "value is not encrypted")
+	assert.False(t, enc.needsReencryption([]byte("plain")))
+}
+
+func TestClientEncryptionKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	tempDir := t.TempDir()
+	dbFile := filepath.Join(tempDir, "my_db")
+
+	// Values stored before encryption was enabled are kept in plaintext until compaction.
+	client, err := newClient(zap.NewNop(), dbFile, time.Second, &CompactionConfig{}, false, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, client.Set(ctx, "plain", []byte("plain value")))
+	require.NoError(t, client.Close(ctx))
+
+	client, err = newClient(zap.NewNop(), dbFile, time.Second, &CompactionConfig{}, false, newTestEncryptor(t, testKey1), nil)
+	require.NoError(t, err)
+	require.NoError(t, client.Set(ctx, "old", []byte("old value")))
+	assert.True(t, isEncrypted(rawValue(t, client, "old")))
+	require.NoError(t, client.Close(ctx))
+
+	// After rotating the key, values encrypted with the previous key can still be read.
+	rotated := newTestEncryptor(t, testKey2, testKey1)
+	client, err = newClient(zap.NewNop(), dbFile, time.Second, &CompactionConfig{}, false, rotated, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, client.Close(ctx))
+	})
+	require.NoError(t, client.Set(ctx, "new", []byte("new value")))
+	assert.True(t, rotated.needsReencryption(rawValue(t, client, "plain")))
+	assert.True(t, rotated.needsReencryption(rawValue(t, client, "old")))
+	assert.False(t, rotated.needsReencryption(rawValue(t, client, "new")))
+
+	// Compaction encrypts all values with the current key.
+	require.NoError(t, client.Compact(tempDir, time.Second, 1))
+	for key, expected := range map[string]string{"plain": "plain value", "old": "old value", "new": "new value"} {
+		assert.False(t, rotated.needsReencryption(rawValue(t, client, key)), key)
+		value, err := client.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(expected), value)
+	}
+	_, err = newTestEncryptor(t, testKey2).decrypt([]byte("old"), rawValue(t, client, "old"))
+	require.NoError(t, err)
+}
+
+func TestLoadKeys(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(testKey1)+"\n"), 0o600))
+	t.Setenv("TEST_FILE_STORAGE_KEY", base64.StdEncoding.EncodeToString(testKey2))
+
+	cfg := &EncryptionConfig{
+		Key:          KeySource{Env: "TEST_FILE_STORAGE_KEY"},
+		PreviousKeys: []KeySource{{File: keyFile}},
+	}
+	keys, err := cfg.loadKeys(context.Background(), componenttest.NewNopHost())
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{testKey2, testKey1}, keys)
+
+	_, err = (&EncryptionConfig{Key: KeySource{Env: "TEST_FILE_STORAGE_MISSING_KEY"}}).loadKeys(context.Background(), componenttest.NewNopHost())
+	assert.EqualError(t, err, "environment variable TEST_FILE_STORAGE_MISSING_KEY is not set")
+
+	require.NoError(t, os.WriteFile(keyFile, []byte("not base64!"), 0o600))
+	_, err = (&EncryptionConfig{Key: KeySource{File: keyFile}}).loadKeys(context.Background(), componenttest.NewNopHost())
+	assert.ErrorContains(t, err, "key is not base64 encoded")
+}
+
+type testKeyProvider struct {
+	component.StartFunc
+	component.ShutdownFunc
+	keys [][]byte
+	err  error
+}
+
+func (p *testKeyProvider) EncryptionKeys(context.Context) ([][]byte, error) {
+	return p.keys, p.err
+}
+
+type testHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h *testHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+func TestExtensionWithKeyProvider(t *testing.T) {
+	ctx := context.Background()
+	providerID := component.MustNewID("keyprovider")
+	host := &testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{
+			providerID:                      &testKeyProvider{keys: [][]byte{testKey1}},
+			component.MustNewID("failing"):  &testKeyProvider{err: errors.New("unavailable")},
+			component.MustNewID("otherext"): &localFileStorage{},
+		},
+	}
+
+	for id, expectedErr := range map[string]string{
+		"missing":  "failed to load encryption keys: key provider missing not found",
+		"failing":  "failed to load encryption keys: failed to get keys from failing: unavailable",
+		"otherext": "failed to load encryption keys: extension otherext is not a key provider",
+	} {
+		cfg := NewFactory().CreateDefaultConfig().(*Config)
+		cfg.Directory = t.TempDir()
+		providerID := component.MustNewID(id)
+		cfg.Encryption = &EncryptionConfig{KeyProvider: &providerID}
+		ext, err := NewFactory().Create(ctx, extensiontest.NewNopSettings(), cfg)
+		require.NoError(t, err)
+		assert.EqualError(t, ext.Start(ctx, host), expectedErr)
+	}
+
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	cfg.Directory = t.TempDir()
+	cfg.Encryption = &EncryptionConfig{KeyProvider: &providerID}
+	ext, err := NewFactory().Create(ctx, extensiontest.NewNopSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(ctx, host))
+	t.Cleanup(func() {
+		require.NoError(t, ext.Shutdown(ctx))
+	})
+
+	client, err := ext.(storage.Extension).GetClient(ctx, component.KindReceiver, newTestEntity("my_component"), "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, client.Close(ctx))
+	})
+	require.NoError(t, client.Set(ctx, "key", []byte("value")))
+	value, err := client.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	raw := rawValue(t, client.(*fileStorageClient), "key")
+	assert.True(t, isEncrypted(raw))
+	decrypted, err := newTestEncryptor(t, testKey1).decrypt([]byte("key"), raw)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), decrypted)
+}
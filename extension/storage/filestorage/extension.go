@@ -15,17 +15,21 @@ import (
 	"go.opentelemetry.io/collector/extension"
 	"go.opentelemetry.io/collector/extension/experimental/storage"
 	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/filestorage/internal/metadata"
 )
 
 type localFileStorage struct {
-	cfg    *Config
-	logger *zap.Logger
+	cfg       *Config
+	logger    *zap.Logger
+	encryptor *encryptor
+	telemetry *metadata.TelemetryBuilder
 }
 
 // Ensure this storage extension implements the appropriate interface
 var _ storage.Extension = (*localFileStorage)(nil)
 
-func newLocalFileStorage(logger *zap.Logger, config *Config) (extension.Extension, error) {
+func newLocalFileStorage(set component.TelemetrySettings, config *Config) (extension.Extension, error) {
 	if config.CreateDirectory {
 		var dirs []string
 		if config.Compaction.OnStart || config.Compaction.OnRebound {
@@ -39,14 +43,28 @@ func newLocalFileStorage(logger *zap.Logger, config *Config) (extension.Extensio
 			}
 		}
 	}
+	telemetry, err := metadata.NewTelemetryBuilder(set)
+	if err != nil {
+		return nil, err
+	}
 	return &localFileStorage{
-		cfg:    config,
-		logger: logger,
+		cfg:       config,
+		logger:    set.Logger,
+		telemetry: telemetry,
 	}, nil
 }
 
-// Start runs cleanup if configured
-func (lfs *localFileStorage) Start(context.Context, component.Host) error {
+// Start loads the encryption keys and runs cleanup if configured
+func (lfs *localFileStorage) Start(ctx context.Context, host component.Host) error {
+	if lfs.cfg.Encryption != nil {
+		keys, err := lfs.cfg.Encryption.loadKeys(ctx, host)
+		if err != nil {
+			return fmt.Errorf("failed to load encryption keys: %w", err)
+		}
+		if lfs.encryptor, err = newEncryptor(keys, lfs.cfg.Encryption.MigrateUnencrypted); err != nil {
+			return err
+		}
+	}
 	if lfs.cfg.Compaction.CleanupOnStart {
 		return lfs.cleanup(lfs.cfg.Compaction.Directory)
 	}
@@ -71,7 +89,8 @@ func (lfs *localFileStorage) GetClient(_ context.Context, kind component.Kind, e
 
 	rawName = sanitize(rawName)
 	absoluteName := filepath.Join(lfs.cfg.Directory, rawName)
-	client, err := newClient(lfs.logger, absoluteName, lfs.cfg.Timeout, lfs.cfg.Compaction, !lfs.cfg.FSync)
+	limits := newClientLimits(lfs.cfg, lfs.telemetry, rawName)
+	client, err := newClient(lfs.logger, absoluteName, lfs.cfg.Timeout, lfs.cfg.Compaction, !lfs.cfg.FSync, lfs.encryptor, limits)
 	if err != nil {
 		return nil, err
 	}
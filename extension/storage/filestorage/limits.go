@@ -0,0 +1,318 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package filestorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/filestorage"
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/filestorage/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota"
+)
+
+const (
+	// maxTTLCheckInterval is the maximum time between checks for expired keys.
+	maxTTLCheckInterval = time.Minute
+	// maxExpiredPerTransaction is the maximum number of expired keys removed in a single transaction.
+	maxExpiredPerTransaction = 1000
+)
+
+var (
+	// writeTimeBucket maps keys to the time they were last written.
+	writeTimeBucket = []byte(`write_time`)
+	// writeOrderBucket holds the keys ordered by the time they were last written, prefixed by that time.
+	writeOrderBucket = []byte(`write_order`)
+)
+
+// clientLimits enforces the quota and TTL of a client. The time every key was last
+// written is stored next to it, so that the oldest and expired keys can be found.
+type clientLimits struct {
+	maxBytes   int64
+	evict      bool
+	ttl        time.Duration
+	telemetry  *metadata.TelemetryBuilder
+	attributes metric.MeasurementOption
+	now        func() time.Time
+
+	mu   sync.Mutex
+	size int64
+}
+
+func newClientLimits(cfg *Config, telemetry *metadata.TelemetryBuilder, clientName string) *clientLimits {
+	if cfg.Quota.MaxBytes == 0 && cfg.TTL == 0 {
+		return nil
+	}
+	return &clientLimits{
+		maxBytes:   cfg.Quota.MaxBytes,
+		evict:      cfg.Quota.OnExceeded == quota.EvictOldest,
+		ttl:        cfg.TTL,
+		telemetry:  telemetry,
+		attributes: metric.WithAttributeSet(attribute.NewSet(attribute.String("client", clientName))),
+		now:        time.Now,
+	}
+}
+
+// init prepares the buckets holding the write times and computes the size of the stored data.
+// Keys that were written while limits were not enforced are considered written now.
+func (l *clientLimits) init(tx *bbolt.Tx) error {
+	if l == nil {
+		// drop write times that would otherwise be stale once limits are enforced again
+		for _, name := range [][]byte{writeTimeBucket, writeOrderBucket} {
+			if err := tx.DeleteBucket(name); err != nil && !errors.Is(err, bbolt.ErrBucketNotFound) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	data := tx.Bucket(defaultBucket)
+	times, err := tx.CreateBucketIfNotExists(writeTimeBucket)
+	if err != nil {
+		return err
+	}
+	order, err := tx.CreateBucketIfNotExists(writeOrderBucket)
+	if err != nil {
+		return err
+	}
+
+	var stale [][]byte
+	if err = times.ForEach(func(k, _ []byte) error {
+		if data.Get(k) == nil {
+			stale = append(stale, bytes.Clone(k))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, k := range stale {
+		if err = l.removeWriteTime(tx, k); err != nil {
+			return err
+		}
+	}
+
+	now := l.now()
+	var size int64
+	var missing [][]byte
+	if err = data.ForEach(func(k, v []byte) error {
+		size += int64(len(k) + len(v))
+		if times.Get(k) == nil {
+			missing = append(missing, bytes.Clone(k))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, k := range missing {
+		if err = times.Put(k, encodeTime(now)); err != nil {
+			return err
+		}
+		if err = order.Put(orderKey(now, k), nil); err != nil {
+			return err
+		}
+	}
+	l.size = size
+	return nil
+}
+
+// set makes room for a value, if needed, and records the time it is written.
+// It returns the change of the stored size.
+func (l *clientLimits) set(ctx context.Context, tx *bbolt.Tx, key, value []byte, pending int64) (int64, error) {
+	data := tx.Bucket(defaultBucket)
+	delta := int64(len(key) + len(value))
+	if old := data.Get(key); old != nil {
+		delta -= int64(len(key) + len(old))
+	}
+
+	if l.maxBytes > 0 && l.currentSize()+pending+delta > l.maxBytes {
+		if !l.evict || int64(len(key)+len(value)) > l.maxBytes {
+			l.telemetry.FileStorageClientRejectedWrites.Add(ctx, 1, l.attributes)
+			return 0, quota.ErrExceeded
+		}
+		evicted, freed, err := l.evictOldest(tx, key, l.currentSize()+pending+delta-l.maxBytes)
+		if err != nil {
+			return 0, err
+		}
+		l.telemetry.FileStorageClientEvictedKeys.Add(ctx, evicted, l.attributes)
+		delta -= freed
+		if l.currentSize()+pending+delta > l.maxBytes {
+			l.telemetry.FileStorageClientRejectedWrites.Add(ctx, 1, l.attributes)
+			return 0, quota.ErrExceeded
+		}
+	}
+
+	if err := l.removeWriteTime(tx, key); err != nil {
+		return 0, err
+	}
+	now := l.now()
+	if err := tx.Bucket(writeTimeBucket).Put(key, encodeTime(now)); err != nil {
+		return 0, err
+	}
+	return delta, tx.Bucket(writeOrderBucket).Put(orderKey(now, key), nil)
+}
+
+// delete forgets the write time of a key and returns the change of the stored size.
+func (l *clientLimits) delete(tx *bbolt.Tx, key []byte) (int64, error) {
+	old := tx.Bucket(defaultBucket).Get(key)
+	if old == nil {
+		return 0, nil
+	}
+	return -int64(len(key) + len(old)), l.removeWriteTime(tx, key)
+}
+
+// expired returns true if the key was written longer than the TTL ago.
+func (l *clientLimits) expired(tx *bbolt.Tx, key []byte) bool {
+	if l.ttl == 0 {
+		return false
+	}
+	written := tx.Bucket(writeTimeBucket).Get(key)
+	return written != nil && l.now().Sub(decodeTime(written)) >= l.ttl
+}
+
+// evictOldest removes the least recently written keys, except for the given one,
+// until at least needed bytes are freed. It returns the number of removed keys and freed bytes.
+func (l *clientLimits) evictOldest(tx *bbolt.Tx, except []byte, needed int64) (int64, int64, error) {
+	data := tx.Bucket(defaultBucket)
+	var evicted, freed int64
+	var keys [][]byte
+	c := tx.Bucket(writeOrderBucket).Cursor()
+	for k, _ := c.First(); k != nil && freed < needed; k, _ = c.Next() {
+		key := k[8:]
+		if bytes.Equal(key, except) {
+			continue
+		}
+		if v := data.Get(key); v != nil {
+			freed += int64(len(key) + len(v))
+		}
+		keys = append(keys, bytes.Clone(key))
+	}
+	for _, key := range keys {
+		if err := data.Delete(key); err != nil {
+			return 0, 0, err
+		}
+		if err := l.removeWriteTime(tx, key); err != nil {
+			return 0, 0, err
+		}
+		evicted++
+	}
+	return evicted, freed, nil
+}
+
+// removeExpired removes keys that were written longer than the TTL ago, in transactions
+// of at most maxExpiredPerTransaction keys.
+func (l *clientLimits) removeExpired(ctx context.Context, db *bbolt.DB) error {
+	for {
+		var removed, freed int64
+		err := db.Update(func(tx *bbolt.Tx) error {
+			data := tx.Bucket(defaultBucket)
+			cutoff := l.now().Add(-l.ttl)
+			var keys [][]byte
+			c := tx.Bucket(writeOrderBucket).Cursor()
+			for k, _ := c.First(); k != nil && len(keys) < maxExpiredPerTransaction; k, _ = c.Next() {
+				if decodeTime(k[:8]).After(cutoff) {
+					break
+				}
+				keys = append(keys, bytes.Clone(k[8:]))
+			}
+			for _, key := range keys {
+				if v := data.Get(key); v != nil {
+					freed += int64(len(key) + len(v))
+				}
+				if err := data.Delete(key); err != nil {
+					return err
+				}
+				if err := l.removeWriteTime(tx, key); err != nil {
+					return err
+				}
+				removed++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			return nil
+		}
+		l.telemetry.FileStorageClientExpiredKeys.Add(ctx, removed, l.attributes)
+		l.add(ctx, -freed)
+		if removed < maxExpiredPerTransaction {
+			return nil
+		}
+	}
+}
+
+func (l *clientLimits) removeWriteTime(tx *bbolt.Tx, key []byte) error {
+	times := tx.Bucket(writeTimeBucket)
+	written := times.Get(key)
+	if written == nil {
+		return nil
+	}
+	if err := tx.Bucket(writeOrderBucket).Delete(orderKey(decodeTime(written), key)); err != nil {
+		return err
+	}
+	return times.Delete(key)
+}
+
+func (l *clientLimits) currentSize() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.size
+}
+
+// add applies a change of the stored size and reports the new size.
+func (l *clientLimits) add(ctx context.Context, delta int64) {
+	l.mu.Lock()
+	l.size += delta
+	size := l.size
+	l.mu.Unlock()
+	l.telemetry.FileStorageClientSize.Record(ctx, size, l.attributes)
+}
+
+// ttlCheckInterval returns how often expired keys are removed.
+func (l *clientLimits) ttlCheckInterval() time.Duration {
+	return min(l.ttl, maxTTLCheckInterval)
+}
+
+// startExpiryLoop periodically removes expired keys until the context is canceled.
+func (c *fileStorageClient) startExpiryLoop(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(c.limits.ttlCheckInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.compactionMutex.RLock()
+				if !c.closed {
+					if err := c.limits.removeExpired(ctx, c.db); err != nil {
+						c.logger.Error("failed to remove expired keys", zap.Error(err))
+					}
+				}
+				c.compactionMutex.RUnlock()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func encodeTime(t time.Time) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(t.UnixNano()))
+}
+
+func decodeTime(b []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(b)))
+}
+
+func orderKey(t time.Time, key []byte) []byte {
+	return append(encodeTime(t), key...)
+}
@@ -0,0 +1,215 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package filestorage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/filestorage/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota/quotatest"
+)
+
+// newTestLimits returns limits whose clock is only advanced by the returned function.
+func newTestLimits(t *testing.T, tel *componentTestTelemetry, quotaCfg quota.Config, ttl time.Duration) (*clientLimits, func(time.Duration)) {
+	telemetry, err := metadata.NewTelemetryBuilder(tel.newTelemetrySettings())
+	require.NoError(t, err)
+	limits := newClientLimits(&Config{Quota: quotaCfg, TTL: ttl}, telemetry, "test")
+	require.NotNil(t, limits)
+	now := time.Unix(1700000000, 0)
+	limits.now = func() time.Time { return now }
+	return limits, func(d time.Duration) { now = now.Add(d) }
+}
+
+func newTestLimitsClient(t *testing.T, dbFile string, limits *clientLimits) *fileStorageClient {
+	client, err := newClient(zap.NewNop(), dbFile, time.Second, &CompactionConfig{}, false, nil, limits)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, client.Close(context.Background()))
+	})
+	return client
+}
+
+func sizeMetric(size int64) metricdata.Metrics {
+	return metricdata.Metrics{
+		Name:        "otelcol_file_storage_client_size",
+		Description: "Total size of the keys and values stored by a client",
+		Unit:        "By",
+		Data: metricdata.Gauge[int64]{
+			DataPoints: []metricdata.DataPoint[int64]{
+				{Value: size, Attributes: attribute.NewSet(attribute.String("client", "test"))},
+			},
+		},
+	}
+}
+
+func counterMetric(name, description, unit string, value int64) metricdata.Metrics {
+	return metricdata.Metrics{
+		Name:        name,
+		Description: description,
+		Unit:        unit,
+		Data: metricdata.Sum[int64]{
+			Temporality: metricdata.CumulativeTemporality,
+			IsMonotonic: true,
+			DataPoints: []metricdata.DataPoint[int64]{
+				{Value: value, Attributes: attribute.NewSet(attribute.String("client", "test"))},
+			},
+		},
+	}
+}
+
+func TestNewClientLimits(t *testing.T) {
+	assert.Nil(t, newClientLimits(&Config{Quota: quota.Config{OnExceeded: quota.Reject}}, nil, "test"))
+	assert.NotNil(t, newClientLimits(&Config{Quota: quota.Config{MaxBytes: 1}}, nil, "test"))
+	assert.NotNil(t, newClientLimits(&Config{TTL: time.Hour}, nil, "test"))
+}
+
+func TestQuota(t *testing.T) {
+	quotatest.TestClient(t, func(t *testing.T, cfg quota.Config) (storage.Client, func(time.Duration)) {
+		tel := setupTestTelemetry()
+		limits, advance := newTestLimits(t, &tel, cfg, 0)
+		return newTestLimitsClient(t, filepath.Join(t.TempDir(), "my_db"), limits), advance
+	})
+}
+
+func TestQuotaRejectBatch(t *testing.T) {
+	ctx := context.Background()
+	tel := setupTestTelemetry()
+	limits, _ := newTestLimits(t, &tel, quota.Config{MaxBytes: 20, OnExceeded: quota.Reject}, 0)
+	client := newTestLimitsClient(t, filepath.Join(t.TempDir(), "my_db"), limits)
+
+	require.NoError(t, client.Set(ctx, "key1", []byte("value1")))
+	require.NoError(t, client.Set(ctx, "key2", []byte("value2")))
+
+	// Rejected batches are not applied at all.
+	assert.ErrorIs(t, client.Batch(ctx,
+		storage.DeleteOperation("key1"),
+		storage.SetOperation("key3", []byte("value3")),
+		storage.SetOperation("key4", []byte("value4")),
+	), quota.ErrExceeded)
+	value, err := client.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+	assert.Equal(t, int64(20), limits.currentSize())
+}
+
+func TestQuotaTelemetry(t *testing.T) {
+	ctx := context.Background()
+	tel := setupTestTelemetry()
+	limits, advance := newTestLimits(t, &tel, quota.Config{MaxBytes: 20, OnExceeded: quota.EvictOldest}, 0)
+	client := newTestLimitsClient(t, filepath.Join(t.TempDir(), "my_db"), limits)
+
+	require.NoError(t, client.Set(ctx, "key1", []byte("value1")))
+	advance(time.Second)
+	require.NoError(t, client.Set(ctx, "key2", []byte("value2")))
+	advance(time.Second)
+	require.NoError(t, client.Set(ctx, "key3", []byte("value3")))
+	assert.ErrorIs(t, client.Set(ctx, "large", make([]byte, 20)), quota.ErrExceeded)
+
+	tel.assertMetrics(t, []metricdata.Metrics{
+		sizeMetric(20),
+		counterMetric("otelcol_file_storage_client_evicted_keys",
+			"Number of keys evicted to stay within the client quota", "{keys}", 1),
+		counterMetric("otelcol_file_storage_client_rejected_writes",
+			"Number of writes rejected because they would exceed the client quota", "{writes}", 1),
+	})
+}
+
+func TestQuotaFailedBatch(t *testing.T) {
+	ctx := context.Background()
+	tel := setupTestTelemetry()
+	limits, _ := newTestLimits(t, &tel, quota.Config{MaxBytes: 20, OnExceeded: quota.Reject}, 0)
+	client := newTestLimitsClient(t, filepath.Join(t.TempDir(), "my_db"), limits)
+
+	require.NoError(t, client.Set(ctx, "key1", []byte("value1")))
+	assert.Equal(t, int64(10), limits.currentSize())
+
+	// The batch fails after a set was accounted for, the size is left as it was.
+	invalid := storage.GetOperation("key1")
+	invalid.Type = -1
+	assert.EqualError(t, client.Batch(ctx, storage.SetOperation("key2", []byte("value2")), invalid), "wrong operation type")
+	assert.Equal(t, int64(10), limits.currentSize())
+
+	require.NoError(t, client.Set(ctx, "key2", []byte("value2")))
+	assert.ErrorIs(t, client.Set(ctx, "key3", []byte("value3")), quota.ErrExceeded)
+	assert.Equal(t, int64(20), limits.currentSize())
+}
+
+func TestTTL(t *testing.T) {
+	ctx := context.Background()
+	tel := setupTestTelemetry()
+	limits, advance := newTestLimits(t, &tel, quota.Config{OnExceeded: quota.Reject}, time.Hour)
+	client := newTestLimitsClient(t, filepath.Join(t.TempDir(), "my_db"), limits)
+
+	require.NoError(t, client.Set(ctx, "old", []byte("value")))
+	advance(30 * time.Minute)
+	require.NoError(t, client.Set(ctx, "new", []byte("value")))
+	advance(30 * time.Minute)
+
+	// Expired keys are not returned, even before they are removed.
+	value, err := client.Get(ctx, "old")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+	value, err = client.Get(ctx, "new")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	require.NoError(t, limits.removeExpired(ctx, client.db))
+	require.NoError(t, client.db.View(func(tx *bbolt.Tx) error {
+		assert.Nil(t, tx.Bucket(defaultBucket).Get([]byte("old")))
+		assert.Nil(t, tx.Bucket(writeTimeBucket).Get([]byte("old")))
+		assert.Equal(t, 1, tx.Bucket(writeOrderBucket).Stats().KeyN)
+		return nil
+	}))
+	assert.Equal(t, int64(8), limits.currentSize())
+
+	tel.assertMetrics(t, []metricdata.Metrics{
+		sizeMetric(8),
+		counterMetric("otelcol_file_storage_client_expired_keys",
+			"Number of keys removed because their TTL expired", "{keys}", 1),
+	})
+}
+
+func TestLimitsInit(t *testing.T) {
+	ctx := context.Background()
+	dbFile := filepath.Join(t.TempDir(), "my_db")
+	tel := setupTestTelemetry()
+
+	// Keys written while limits were enforced have a write time.
+	limits, advance := newTestLimits(t, &tel, quota.Config{OnExceeded: quota.Reject}, time.Hour)
+	client, err := newClient(zap.NewNop(), dbFile, time.Second, &CompactionConfig{}, false, nil, limits)
+	require.NoError(t, err)
+	require.NoError(t, client.Set(ctx, "key1", []byte("value")))
+	require.NoError(t, client.Set(ctx, "key2", []byte("value")))
+	require.NoError(t, client.Close(ctx))
+
+	// Keys written and deleted while limits were not enforced leave no stale write times.
+	client, err = newClient(zap.NewNop(), dbFile, time.Second, &CompactionConfig{}, false, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, client.Delete(ctx, "key1"))
+	require.NoError(t, client.Set(ctx, "key3", []byte("value")))
+	require.NoError(t, client.Close(ctx))
+
+	advance(30 * time.Minute)
+	client = newTestLimitsClient(t, dbFile, limits)
+	assert.Equal(t, int64(18), limits.currentSize())
+	require.NoError(t, client.db.View(func(tx *bbolt.Tx) error {
+		times := tx.Bucket(writeTimeBucket)
+		assert.Equal(t, 2, times.Stats().KeyN)
+		assert.Equal(t, limits.now(), decodeTime(times.Get([]byte("key2"))))
+		assert.Equal(t, limits.now(), decodeTime(times.Get([]byte("key3"))))
+		return nil
+	}))
+}
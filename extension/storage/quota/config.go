@@ -0,0 +1,41 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package quota defines the configuration limiting the size of the data stored by each client of
+// the storage extensions.
+package quota // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota"
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// Reject rejects writes that would exceed the quota.
+	Reject = "reject"
+	// EvictOldest removes the least recently written keys to make room for writes.
+	EvictOldest = "evict_oldest"
+)
+
+// ErrExceeded is returned for writes that would exceed the quota of a client.
+var ErrExceeded = errors.New("storage quota exceeded")
+
+// Config defines configuration for limiting the size of the data stored by each client.
+type Config struct {
+	// MaxBytes is the maximum total size of the keys and values stored by a client. No limit is enforced if it is 0.
+	MaxBytes int64 `mapstructure:"max_bytes,omitempty"`
+	// OnExceeded specifies what happens to writes that would exceed the quota, either reject or evict_oldest.
+	OnExceeded string `mapstructure:"on_exceeded,omitempty"`
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.MaxBytes < 0 {
+		return errors.New("quota max_bytes cannot be negative")
+	}
+	switch cfg.OnExceeded {
+	case Reject, EvictOldest:
+		return nil
+	default:
+		return fmt.Errorf("quota on_exceeded must be %q or %q, got %q", Reject, EvictOldest, cfg.OnExceeded)
+	}
+}
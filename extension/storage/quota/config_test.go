@@ -0,0 +1,47 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package quota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{
+			name: "reject",
+			cfg:  Config{MaxBytes: 1024, OnExceeded: Reject},
+		},
+		{
+			name: "evict oldest",
+			cfg:  Config{OnExceeded: EvictOldest},
+		},
+		{
+			name: "negative max bytes",
+			cfg:  Config{MaxBytes: -1, OnExceeded: Reject},
+			err:  "quota max_bytes cannot be negative",
+		},
+		{
+			name: "invalid on exceeded",
+			cfg:  Config{MaxBytes: 1024, OnExceeded: "drop"},
+			err:  `quota on_exceeded must be "reject" or "evict_oldest", got "drop"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
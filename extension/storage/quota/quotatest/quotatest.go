@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package quotatest provides the tests that the clients of every storage extension enforcing a quota
+// must pass.
+package quotatest // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota/quotatest"
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota"
+)
+
+// NewClientFunc creates a client enforcing the quota. The returned function advances the clock of the
+// client, so that the keys written one after the other have different write times.
+type NewClientFunc func(t *testing.T, cfg quota.Config) (storage.Client, func(time.Duration))
+
+// TestClient runs the quota tests against the clients created by newClient, a new client for each test.
+// The keys and values of the tests are 4 and 5 or 6 bytes long.
+func TestClient(t *testing.T, newClient NewClientFunc) {
+	t.Run("reject", func(t *testing.T) {
+		testReject(t, newClient)
+	})
+	t.Run("evict_oldest", func(t *testing.T) {
+		testEvictOldest(t, newClient)
+	})
+}
+
+func testReject(t *testing.T, newClient NewClientFunc) {
+	ctx := context.Background()
+	client, _ := newClient(t, quota.Config{MaxBytes: 20, OnExceeded: quota.Reject})
+
+	require.NoError(t, client.Set(ctx, "key1", []byte("value1")))
+	require.NoError(t, client.Set(ctx, "key2", []byte("value2")))
+	assert.ErrorIs(t, client.Set(ctx, "key3", []byte("value3")), quota.ErrExceeded)
+	value, err := client.Get(ctx, "key3")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	// Overwriting a key only takes the change of its size into account.
+	require.NoError(t, client.Set(ctx, "key2", []byte("value")))
+	require.NoError(t, client.Delete(ctx, "key1"))
+	require.NoError(t, client.Set(ctx, "key3", []byte("value3")))
+	assert.ErrorIs(t, client.Set(ctx, "key1", []byte("value1")), quota.ErrExceeded)
+
+	// Writes within the quota are accepted again once keys are deleted.
+	require.NoError(t, client.Batch(ctx, storage.DeleteOperation("key3"), storage.SetOperation("key1", []byte("value1"))))
+	for key, expected := range map[string][]byte{"key1": []byte("value1"), "key2": []byte("value"), "key3": nil} {
+		value, err = client.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, expected, value, key)
+	}
+}
+
+func testEvictOldest(t *testing.T, newClient NewClientFunc) {
+	ctx := context.Background()
+	client, advance := newClient(t, quota.Config{MaxBytes: 30, OnExceeded: quota.EvictOldest})
+
+	for _, key := range []string{"key1", "key2", "key3"} {
+		require.NoError(t, client.Set(ctx, key, []byte("value")))
+		advance(time.Second)
+	}
+	// Writing key1 again makes key2 the oldest key, which is evicted to make room for key4.
+	require.NoError(t, client.Set(ctx, "key1", []byte("value")))
+	advance(time.Second)
+	require.NoError(t, client.Set(ctx, "key4", []byte("value")))
+
+	for key, expected := range map[string][]byte{"key1": []byte("value"), "key2": nil, "key3": []byte("value"), "key4": []byte("value")} {
+		value, err := client.Get(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, expected, value, key)
+	}
+
+	// Values larger than the quota are rejected instead of evicting everything.
+	assert.ErrorIs(t, client.Set(ctx, "large", make([]byte, 30)), quota.ErrExceeded)
+	value, err := client.Get(ctx, "key3")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+}
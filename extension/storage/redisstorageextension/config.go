@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"go.opentelemetry.io/collector/config/configopaque"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota"
 )
 
 // Config defines configuration for the Redis storage extension.
@@ -15,4 +17,7 @@ type Config struct {
 	Password   configopaque.String `mapstructure:"password"`
 	DB         int                 `mapstructure:"db"`
 	Expiration time.Duration       `mapstructure:"expiration"`
+
+	// Quota limits the size of the data stored by each client
+	Quota quota.Config `mapstructure:"quota"`
 }
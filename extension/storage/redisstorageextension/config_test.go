@@ -13,6 +13,7 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/redisstorageextension/internal/metadata"
 )
 
@@ -38,6 +39,10 @@ func TestLoadConfig(t *testing.T) {
 				Password:   "passwd",
 				DB:         1,
 				Expiration: 3 * time.Hour,
+				Quota: quota.Config{
+					MaxBytes:   1048576,
+					OnExceeded: quota.EvictOldest,
+				},
 			},
 		},
 	}
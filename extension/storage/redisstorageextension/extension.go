@@ -14,21 +14,29 @@ import (
 	"go.opentelemetry.io/collector/extension"
 	"go.opentelemetry.io/collector/extension/experimental/storage"
 	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/redisstorageextension/internal/metadata"
 )
 
 type redisStorage struct {
-	cfg    *Config
-	logger *zap.Logger
-	client *redis.Client
+	cfg       *Config
+	logger    *zap.Logger
+	client    *redis.Client
+	telemetry *metadata.TelemetryBuilder
 }
 
 // Ensure this storage extension implements the appropriate interface
 var _ storage.Extension = (*redisStorage)(nil)
 
-func newRedisStorage(logger *zap.Logger, config *Config) (extension.Extension, error) {
+func newRedisStorage(set component.TelemetrySettings, config *Config) (extension.Extension, error) {
+	telemetry, err := metadata.NewTelemetryBuilder(set)
+	if err != nil {
+		return nil, err
+	}
 	return &redisStorage{
-		cfg:    config,
-		logger: logger,
+		cfg:       config,
+		logger:    set.Logger,
+		telemetry: telemetry,
 	}, nil
 }
 
@@ -55,6 +63,7 @@ type redisClient struct {
 	client     *redis.Client
 	prefix     string
 	expiration time.Duration
+	quota      *clientQuota
 }
 
 var _ storage.Client = redisClient{}
@@ -68,16 +77,25 @@ func (rc redisClient) Get(ctx context.Context, key string) ([]byte, error) {
 }
 
 func (rc redisClient) Set(ctx context.Context, key string, value []byte) error {
+	if rc.quota != nil {
+		return rc.quota.set(ctx, rc.client, key, value)
+	}
 	_, err := rc.client.Set(ctx, rc.prefix+key, value, rc.expiration).Result()
 	return err
 }
 
 func (rc redisClient) Delete(ctx context.Context, key string) error {
+	if rc.quota != nil {
+		return rc.quota.delete(ctx, rc.client, key)
+	}
 	_, err := rc.client.Del(ctx, rc.prefix+key).Result()
 	return err
 }
 
 func (rc redisClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	if rc.quota != nil {
+		return rc.batchWithQuota(ctx, ops...)
+	}
 	p := rc.client.Pipeline()
 	for _, op := range ops {
 		switch op.Type {
@@ -93,6 +111,28 @@ func (rc redisClient) Batch(ctx context.Context, ops ...storage.Operation) error
 	return err
 }
 
+// batchWithQuota executes the operations in order, as every write needs to check the quota.
+// Get operation results are updated in place
+func (rc redisClient) batchWithQuota(ctx context.Context, ops ...storage.Operation) error {
+	var err error
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value, err = rc.Get(ctx, op.Key)
+		case storage.Set:
+			err = rc.Set(ctx, op.Key, op.Value)
+		case storage.Delete:
+			err = rc.Delete(ctx, op.Key)
+		default:
+			return errors.New("wrong operation type")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (rc redisClient) Close(_ context.Context) error {
 	return nil
 }
@@ -110,6 +150,7 @@ func (rs *redisStorage) GetClient(_ context.Context, kind component.Kind, ent co
 		client:     rs.client,
 		prefix:     rawName,
 		expiration: rs.cfg.Expiration,
+		quota:      newClientQuota(rs.cfg, rs.telemetry, rawName),
 	}, nil
 }
 
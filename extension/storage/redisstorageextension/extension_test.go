@@ -7,12 +7,16 @@ import (
 	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/extension/experimental/storage"
 	"go.opentelemetry.io/collector/extension/extensiontest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota/quotatest"
 )
 
 func TestExtensionIntegrity(t *testing.T) {
@@ -184,6 +188,35 @@ func TestTwoClientsWithDifferentNames(t *testing.T) {
 	require.Equal(t, myBytes2, data)
 }
 
+func TestClientQuota(t *testing.T) {
+	t.Skip("Requires a Redis cluster to be present at localhost:6379")
+	quotatest.TestClient(t, func(t *testing.T, cfg quota.Config) (storage.Client, func(time.Duration)) {
+		ctx := context.Background()
+		f := NewFactory()
+		extCfg := f.CreateDefaultConfig().(*Config)
+		extCfg.Quota = cfg
+		ext, err := f.Create(ctx, extensiontest.NewNopSettings(), extCfg)
+		require.NoError(t, err)
+		require.NoError(t, ext.Start(ctx, componenttest.NewNopHost()))
+		t.Cleanup(func() {
+			require.NoError(t, ext.Shutdown(ctx))
+		})
+
+		client, err := ext.(storage.Extension).GetClient(ctx, component.KindReceiver, newTestEntity("quota"), t.Name())
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			for _, key := range []string{"key1", "key2", "key3", "key4"} {
+				require.NoError(t, client.Delete(ctx, key))
+			}
+			require.NoError(t, client.Close(ctx))
+		})
+
+		now := time.Now()
+		client.(redisClient).quota.now = func() time.Time { return now }
+		return client, func(d time.Duration) { now = now.Add(d) }
+	})
+}
+
 func newTestExtension(t *testing.T) storage.Extension {
 	f := NewFactory()
 	cfg := f.CreateDefaultConfig().(*Config)
@@ -9,6 +9,7 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/extension"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/redisstorageextension/internal/metadata"
 )
 
@@ -24,6 +25,9 @@ func NewFactory() extension.Factory {
 func createDefaultConfig() component.Config {
 	return &Config{
 		Endpoint: "localhost:6379",
+		Quota: quota.Config{
+			OnExceeded: quota.Reject,
+		},
 	}
 }
 
@@ -32,5 +36,5 @@ func createExtension(
 	params extension.Settings,
 	cfg component.Config,
 ) (extension.Extension, error) {
-	return newRedisStorage(params.Logger, cfg.(*Config))
+	return newRedisStorage(params.TelemetrySettings, cfg.(*Config))
 }
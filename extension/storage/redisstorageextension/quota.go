@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package redisstorageextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/redisstorageextension"
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/quota"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/redisstorageextension/internal/metadata"
+)
+
+const (
+	// quotaKeyPrefix prefixes the keys holding the quota index of a client, so that they cannot collide with its keys.
+	quotaKeyPrefix = "otelcol_quota:"
+)
+
+// setScript stores a value after making room for it, if needed. The size of every key is
+// stored in a hash and the time it was last written in a sorted set, so that the oldest keys
+// can be evicted. Keys that expired are removed from both before the quota is checked.
+//
+// KEYS: the key of the value, the sizes hash, the write times sorted set and the total size.
+// ARGV: the value, the key without prefix, the current time and expiration in milliseconds,
+// the quota, 1 to evict the oldest keys and the prefix of the keys of the client.
+// It returns the new total size, or -1 if the value was rejected, and the number of evicted keys.
+var setScript = redis.NewScript(`
+local now = tonumber(ARGV[3])
+local expiration = tonumber(ARGV[4])
+local maxBytes = tonumber(ARGV[5])
+local total = tonumber(redis.call('GET', KEYS[4]) or '0')
+
+local function forget(member)
+  total = total - tonumber(redis.call('HGET', KEYS[2], member) or '0')
+  redis.call('HDEL', KEYS[2], member)
+  redis.call('ZREM', KEYS[3], member)
+end
+
+if expiration > 0 then
+  for _, member in ipairs(redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', now - expiration)) do
+    forget(member)
+  end
+end
+
+local size = string.len(ARGV[2]) + string.len(ARGV[1])
+local delta = size - tonumber(redis.call('HGET', KEYS[2], ARGV[2]) or '0')
+local evicted = 0
+if total + delta > maxBytes then
+  if ARGV[6] ~= '1' or size > maxBytes then
+    redis.call('SET', KEYS[4], total)
+    return {-1, 0}
+  end
+  for _, member in ipairs(redis.call('ZRANGE', KEYS[3], 0, -1)) do
+    if total + delta <= maxBytes then
+      break
+    end
+    if member ~= ARGV[2] then
+      redis.call('DEL', ARGV[7] .. member)
+      forget(member)
+      evicted = evicted + 1
+    end
+  end
+end
+
+if expiration > 0 then
+  redis.call('SET', KEYS[1], ARGV[1], 'PX', expiration)
+else
+  redis.call('SET', KEYS[1], ARGV[1])
+end
+redis.call('HSET', KEYS[2], ARGV[2], size)
+redis.call('ZADD', KEYS[3], now, ARGV[2])
+total = total + delta
+redis.call('SET', KEYS[4], total)
+return {total, evicted}
+`)
+
+// deleteScript deletes a value and removes it from the quota index.
+//
+// KEYS: the key of the value, the sizes hash, the write times sorted set and the total size.
+// ARGV: the key without prefix.
+// It returns the new total size.
+var deleteScript = redis.NewScript(`
+local total = tonumber(redis.call('GET', KEYS[4]) or '0')
+local size = redis.call('HGET', KEYS[2], ARGV[1])
+if size then
+  total = total - tonumber(size)
+  redis.call('HDEL', KEYS[2], ARGV[1])
+  redis.call('ZREM', KEYS[3], ARGV[1])
+  redis.call('SET', KEYS[4], total)
+end
+redis.call('DEL', KEYS[1])
+return total
+`)
+
+// clientQuota enforces the quota of a client.
+type clientQuota struct {
+	maxBytes   int64
+	evict      bool
+	expiration time.Duration
+	prefix     string
+	telemetry  *metadata.TelemetryBuilder
+	attributes metric.MeasurementOption
+	now        func() time.Time
+}
+
+func newClientQuota(cfg *Config, telemetry *metadata.TelemetryBuilder, prefix string) *clientQuota {
+	if cfg.Quota.MaxBytes == 0 {
+		return nil
+	}
+	return &clientQuota{
+		maxBytes:   cfg.Quota.MaxBytes,
+		evict:      cfg.Quota.OnExceeded == quota.EvictOldest,
+		expiration: cfg.Expiration,
+		prefix:     prefix,
+		telemetry:  telemetry,
+		attributes: metric.WithAttributeSet(attribute.NewSet(attribute.String("client", prefix))),
+		now:        time.Now,
+	}
+}
+
+func (q *clientQuota) keys(key string) []string {
+	return []string{
+		q.prefix + key,
+		quotaKeyPrefix + q.prefix + ":sizes",
+		quotaKeyPrefix + q.prefix + ":write_times",
+		quotaKeyPrefix + q.prefix + ":size",
+	}
+}
+
+func (q *clientQuota) set(ctx context.Context, client redis.Scripter, key string, value []byte) error {
+	evict := 0
+	if q.evict {
+		evict = 1
+	}
+	result, err := setScript.Run(ctx, client, q.keys(key),
+		value, key, q.now().UnixMilli(), q.expiration.Milliseconds(), q.maxBytes, evict, q.prefix).Int64Slice()
+	if err != nil {
+		return err
+	}
+	if result[0] < 0 {
+		q.telemetry.RedisStorageClientRejectedWrites.Add(ctx, 1, q.attributes)
+		return quota.ErrExceeded
+	}
+	if result[1] > 0 {
+		q.telemetry.RedisStorageClientEvictedKeys.Add(ctx, result[1], q.attributes)
+	}
+	q.telemetry.RedisStorageClientSize.Record(ctx, result[0], q.attributes)
+	return nil
+}
+
+func (q *clientQuota) delete(ctx context.Context, client redis.Scripter, key string) error {
+	size, err := deleteScript.Run(ctx, client, q.keys(key), key).Int64()
+	if err != nil {
+		return err
+	}
+	q.telemetry.RedisStorageClientSize.Record(ctx, size, q.attributes)
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tenantauthextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/tenantauthextension"
+
+import (
+	"go.opentelemetry.io/collector/client"
+)
+
+const (
+	// TenantIDAttribute is the attribute holding the ID of the tenant of the request.
+	TenantIDAttribute = "tenant.id"
+	// SubjectAttribute is the attribute holding the user, certificate subject or authenticator claim
+	// that identified the tenant.
+	SubjectAttribute = "subject"
+	// MethodAttribute is the attribute holding how the request was authenticated: one of
+	// `htpasswd`, `token`, `mtls` or `authenticator`.
+	MethodAttribute = "auth.method"
+)
+
+var _ client.AuthData = (*authData)(nil)
+
+type authData struct {
+	tenantID string
+	subject  string
+	method   string
+	// delegated is the auth data of the authenticator, whose attributes are exposed as well.
+	delegated client.AuthData
+}
+
+func (a *authData) GetAttribute(name string) any {
+	switch name {
+	case TenantIDAttribute:
+		return a.tenantID
+	case SubjectAttribute:
+		return a.subject
+	case MethodAttribute:
+		return a.method
+	}
+	if a.delegated != nil {
+		return a.delegated.GetAttribute(name)
+	}
+	return nil
+}
+
+func (a *authData) GetAttributeNames() []string {
+	names := []string{TenantIDAttribute, SubjectAttribute, MethodAttribute}
+	if a.delegated == nil {
+		return names
+	}
+	for _, name := range a.delegated.GetAttributeNames() {
+		if name != TenantIDAttribute && name != SubjectAttribute && name != MethodAttribute {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,164 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tenantauthextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/tenantauthextension"
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+)
+
+var (
+	errNoTenants           = errors.New("at least one tenant must be configured")
+	errNoCredentialSource  = errors.New("no credential source provided, one of `htpasswd`, `tokens`, `subjects` or `authenticator` must be used")
+	errMissingAttribute    = errors.New("`attribute` must be set when `authenticator` is used")
+	errUnknownDefault      = errors.New("`default_tenant` must be one of the configured tenants")
+	errInvalidRateLimit    = errors.New("rate limit `requests_per_second` must be positive")
+	errEmptyCredentialItem = errors.New("users, tokens, subjects and claims cannot be empty")
+)
+
+// HtpasswdSettings defines where the htpasswd entries used to authenticate users are read from.
+type HtpasswdSettings struct {
+	// Path to the htpasswd file.
+	File string `mapstructure:"file"`
+	// Inline contents of the htpasswd file.
+	Inline string `mapstructure:"inline"`
+}
+
+// RateLimitSettings defines a token bucket limiting the requests of a tenant.
+type RateLimitSettings struct {
+	// RequestsPerSecond is the rate at which requests are allowed.
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	// Burst is the number of requests allowed at once. It defaults to RequestsPerSecond, rounded up.
+	Burst int `mapstructure:"burst"`
+}
+
+// TenantSettings defines the credentials that identify a tenant.
+type TenantSettings struct {
+	// Users are the names of the htpasswd users of the tenant.
+	Users []string `mapstructure:"users"`
+	// Tokens are the static bearer tokens of the tenant.
+	Tokens []configopaque.String `mapstructure:"tokens"`
+	// Subjects are the subjects of the verified client certificates of the tenant, either as a common name or as a
+	// distinguished name such as `CN=collector,O=team-a`. They are only available for gRPC.
+	Subjects []string `mapstructure:"subjects"`
+	// Claims are the values of the attribute of the authenticator that identify the tenant.
+	Claims []string `mapstructure:"claims"`
+	// RateLimit limits the requests of the tenant, replacing the default rate limit.
+	RateLimit *RateLimitSettings `mapstructure:"rate_limit"`
+}
+
+type Config struct {
+	// Htpasswd settings used to authenticate users with basic auth.
+	Htpasswd *HtpasswdSettings `mapstructure:"htpasswd,omitempty"`
+
+	// Authenticator is the ID of a server authenticator, such as oidc, used for credentials that are
+	// not a user or a static token. The tenant is identified by an attribute of the authenticated request.
+	Authenticator *component.ID `mapstructure:"authenticator,omitempty"`
+
+	// Attribute is the attribute of the authenticator that is matched with the claims of the tenants,
+	// e.g. `subject` or `membership` for oidc. Any value of attributes with multiple values can match.
+	Attribute string `mapstructure:"attribute,omitempty"`
+
+	// Header is the header holding the credentials.
+	Header string `mapstructure:"header,omitempty"`
+
+	// Tenants maps tenant IDs to their credentials.
+	Tenants map[string]TenantSettings `mapstructure:"tenants"`
+
+	// DefaultTenant is the tenant of requests that are authenticated but not mapped to any tenant.
+	// Such requests are rejected if it is not set.
+	DefaultTenant string `mapstructure:"default_tenant,omitempty"`
+
+	// RateLimit limits the requests of every tenant that has no rate limit of its own. No limit is enforced if it is not set.
+	RateLimit *RateLimitSettings `mapstructure:"rate_limit,omitempty"`
+}
+
+func (cfg *Config) Validate() error {
+	if len(cfg.Tenants) == 0 {
+		return errNoTenants
+	}
+	if cfg.Authenticator != nil && cfg.Attribute == "" {
+		return errMissingAttribute
+	}
+	if cfg.DefaultTenant != "" {
+		if _, ok := cfg.Tenants[cfg.DefaultTenant]; !ok {
+			return errUnknownDefault
+		}
+	}
+	if cfg.RateLimit != nil {
+		if err := cfg.RateLimit.validate(); err != nil {
+			return err
+		}
+	}
+
+	var hasUsers, hasTokens, hasSubjects, hasClaims bool
+	users := map[string]string{}
+	tokens := map[configopaque.String]string{}
+	subjects := map[string]string{}
+	claims := map[string]string{}
+	for id, tenant := range cfg.Tenants {
+		for _, items := range []struct {
+			kind   string
+			values []string
+			seen   map[string]string
+		}{
+			{"user", tenant.Users, users},
+			{"subject", tenant.Subjects, subjects},
+			{"claim", tenant.Claims, claims},
+		} {
+			for _, value := range items.values {
+				if value == "" {
+					return fmt.Errorf("tenant %q: %w", id, errEmptyCredentialItem)
+				}
+				if other, ok := items.seen[value]; ok && other != id {
+					return fmt.Errorf("%s %q is mapped to both tenant %q and %q", items.kind, value, other, id)
+				}
+				items.seen[value] = id
+			}
+		}
+		for _, token := range tenant.Tokens {
+			if token == "" {
+				return fmt.Errorf("tenant %q: %w", id, errEmptyCredentialItem)
+			}
+			if other, ok := tokens[token]; ok && other != id {
+				// tokens are not printed, as they are secrets
+				return fmt.Errorf("a token is mapped to both tenant %q and %q", other, id)
+			}
+			tokens[token] = id
+		}
+		if tenant.RateLimit != nil {
+			if err := tenant.RateLimit.validate(); err != nil {
+				return fmt.Errorf("tenant %q: %w", id, err)
+			}
+		}
+		hasUsers = hasUsers || len(tenant.Users) > 0
+		hasTokens = hasTokens || len(tenant.Tokens) > 0
+		hasSubjects = hasSubjects || len(tenant.Subjects) > 0
+		hasClaims = hasClaims || len(tenant.Claims) > 0
+	}
+
+	if hasUsers && cfg.Htpasswd == nil {
+		return errors.New("`htpasswd` must be set when tenants have users")
+	}
+	if hasClaims && cfg.Authenticator == nil {
+		return errors.New("`authenticator` must be set when tenants have claims")
+	}
+	if cfg.Htpasswd == nil && cfg.Authenticator == nil && !hasTokens && !hasSubjects {
+		return errNoCredentialSource
+	}
+	return nil
+}
+
+func (rl *RateLimitSettings) validate() error {
+	if rl.RequestsPerSecond <= 0 {
+		return errInvalidRateLimit
+	}
+	if rl.Burst < 0 {
+		return errors.New("rate limit `burst` cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tenantauthextension
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/tenantauthextension/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	oidc := component.MustNewID("oidc")
+	tests := []struct {
+		id          component.ID
+		expected    component.Config
+		expectedErr string
+	}{
+		{
+			id:          component.NewID(metadata.Type),
+			expectedErr: errNoTenants.Error(),
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "htpasswd"),
+			expected: &Config{
+				Htpasswd: &HtpasswdSettings{
+					Inline: "alice:secret\n",
+				},
+				Header: "Authorization",
+				Tenants: map[string]TenantSettings{
+					"team-a": {
+						Users:     []string{"alice"},
+						Tokens:    []configopaque.String{"token-a"},
+						RateLimit: &RateLimitSettings{RequestsPerSecond: 100},
+					},
+					"team-b": {
+						Subjects: []string{"CN=collector,O=team-b"},
+					},
+				},
+				DefaultTenant: "team-b",
+				RateLimit:     &RateLimitSettings{RequestsPerSecond: 10, Burst: 20},
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "authenticator"),
+			expected: &Config{
+				Authenticator: &oidc,
+				Attribute:     "membership",
+				Header:        "X-Scope-Token",
+				Tenants: map[string]TenantSettings{
+					"team-a": {Claims: []string{"group-a"}},
+				},
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "missing_attribute"),
+			expectedErr: errMissingAttribute.Error(),
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "duplicate_user"),
+			expectedErr: "is mapped to both tenant",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "users_without_htpasswd"),
+			expectedErr: "`htpasswd` must be set when tenants have users",
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "unknown_default"),
+			expectedErr: errUnknownDefault.Error(),
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "invalid_rate_limit"),
+			expectedErr: errInvalidRateLimit.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+			require.NoError(t, err)
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, component.ValidateConfig(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, component.ValidateConfig(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestValidateDuplicateToken(t *testing.T) {
+	cfg := &Config{
+		Tenants: map[string]TenantSettings{
+			"team-a": {Tokens: []configopaque.String{"secret-token"}},
+			"team-b": {Tokens: []configopaque.String{"secret-token"}},
+		},
+	}
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-token")
+}
+
+func TestValidateNoCredentialSource(t *testing.T) {
+	cfg := &Config{
+		Tenants: map[string]TenantSettings{"team-a": {}},
+	}
+	assert.ErrorIs(t, cfg.Validate(), errNoCredentialSource)
+}
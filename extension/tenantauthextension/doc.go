@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+// Package tenantauthextension implements a server authenticator that maps credentials to tenants,
+// with per-tenant rate limits.
+package tenantauthextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/tenantauthextension"
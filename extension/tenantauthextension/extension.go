@@ -0,0 +1,294 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tenantauthextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/tenantauthextension"
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/tg123/go-htpasswd"
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/auth"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	methodHtpasswd      = "htpasswd"
+	methodToken         = "token"
+	methodMTLS          = "mtls"
+	methodAuthenticator = "authenticator"
+)
+
+var (
+	errNoAuth              = errors.New("no credentials provided")
+	errInvalidCredentials  = errors.New("invalid credentials")
+	errInvalidFormat       = errors.New("invalid authorization format")
+	errUnknownTenant       = errors.New("credentials are not mapped to a tenant")
+	errNotServerAuthorizer = errors.New("extension is not a server authenticator")
+)
+
+// tenant is a tenant with the limiter of its requests, if any.
+type tenant struct {
+	id      string
+	limiter *rate.Limiter
+}
+
+type tenantAuth struct {
+	cfg    *Config
+	logger *zap.Logger
+
+	tenants  map[string]*tenant
+	users    map[string]*tenant
+	tokens   map[[sha256.Size]byte]*tenant
+	subjects map[string]*tenant
+	claims   map[string]*tenant
+	fallback *tenant
+
+	matchUser func(username, password string) bool
+	delegate  auth.Server
+}
+
+// tenantAuthExtension is the server authenticator, which also exposes the rate limits of the tenants
+// to the tenant_rate_limit processor.
+type tenantAuthExtension struct {
+	auth.Server
+	limiter *tenantAuth
+}
+
+func newServerAuthExtension(cfg *Config, logger *zap.Logger) *tenantAuthExtension {
+	ta := &tenantAuth{
+		cfg:      cfg,
+		logger:   logger,
+		tenants:  map[string]*tenant{},
+		users:    map[string]*tenant{},
+		tokens:   map[[sha256.Size]byte]*tenant{},
+		subjects: map[string]*tenant{},
+		claims:   map[string]*tenant{},
+	}
+	for id, settings := range cfg.Tenants {
+		t := &tenant{id: id}
+		ta.tenants[id] = t
+		if limit := settings.RateLimit; limit != nil {
+			t.limiter = newLimiter(limit)
+		} else if cfg.RateLimit != nil {
+			t.limiter = newLimiter(cfg.RateLimit)
+		}
+		for _, user := range settings.Users {
+			ta.users[user] = t
+		}
+		for _, token := range settings.Tokens {
+			ta.tokens[sha256.Sum256([]byte(token))] = t
+		}
+		for _, subject := range settings.Subjects {
+			ta.subjects[subject] = t
+		}
+		for _, claim := range settings.Claims {
+			ta.claims[claim] = t
+		}
+		if id == cfg.DefaultTenant {
+			ta.fallback = t
+		}
+	}
+
+	return &tenantAuthExtension{
+		Server: auth.NewServer(
+			auth.WithServerStart(ta.serverStart),
+			auth.WithServerAuthenticate(ta.authenticate),
+		),
+		limiter: ta,
+	}
+}
+
+func newLimiter(settings *RateLimitSettings) *rate.Limiter {
+	burst := settings.Burst
+	if burst == 0 {
+		burst = int(math.Ceil(settings.RequestsPerSecond))
+	}
+	return rate.NewLimiter(rate.Limit(settings.RequestsPerSecond), burst)
+}
+
+func (ta *tenantAuth) serverStart(_ context.Context, host component.Host) error {
+	if ta.cfg.Htpasswd != nil {
+		htp, err := loadHtpasswd(ta.cfg.Htpasswd)
+		if err != nil {
+			return err
+		}
+		ta.matchUser = htp.Match
+	}
+
+	if ta.cfg.Authenticator != nil {
+		ext, ok := host.GetExtensions()[*ta.cfg.Authenticator]
+		if !ok {
+			return fmt.Errorf("authenticator %s not found", ta.cfg.Authenticator)
+		}
+		if ta.delegate, ok = ext.(auth.Server); !ok {
+			return fmt.Errorf("%s: %w", ta.cfg.Authenticator, errNotServerAuthorizer)
+		}
+	}
+	return nil
+}
+
+func loadHtpasswd(settings *HtpasswdSettings) (*htpasswd.File, error) {
+	var rs []io.Reader
+	if settings.File != "" {
+		f, err := os.Open(settings.File)
+		if err != nil {
+			return nil, fmt.Errorf("open htpasswd file: %w", err)
+		}
+		defer f.Close()
+
+		rs = append(rs, f, strings.NewReader("\n"))
+	}
+	// Ensure that the inline content is read the last.
+	// This way the inline content will override the content from file.
+	rs = append(rs, strings.NewReader(settings.Inline))
+
+	htp, err := htpasswd.NewFromReader(io.MultiReader(rs...), htpasswd.DefaultSystems, nil)
+	if err != nil {
+		return nil, fmt.Errorf("read htpasswd content: %w", err)
+	}
+	return htp, nil
+}
+
+// authenticate identifies the tenant of a request, using the first credentials that apply:
+// the user of basic auth, a static bearer token, the authenticator or the client certificate.
+func (ta *tenantAuth) authenticate(ctx context.Context, headers map[string][]string) (context.Context, error) {
+	var t *tenant
+	var identity, method string
+	var delegated client.AuthData
+	var err error
+
+	credentials := getHeader(headers, ta.cfg.Header)
+	scheme, value, _ := strings.Cut(credentials, " ")
+	switch {
+	case credentials != "" && strings.EqualFold(scheme, "basic") && ta.matchUser != nil:
+		if identity, err = ta.authenticateUser(value); err != nil {
+			return ctx, err
+		}
+		t, method = ta.users[identity], methodHtpasswd
+	case credentials != "" && strings.EqualFold(scheme, "bearer") && ta.tokens[sha256.Sum256([]byte(value))] != nil:
+		t, method = ta.tokens[sha256.Sum256([]byte(value))], methodToken
+		identity = t.id
+	case credentials == "" && len(ta.subjects) > 0 && peerCertificateSubject(ctx) != nil:
+		subject := peerCertificateSubject(ctx)
+		identity, method = subject.dn, methodMTLS
+		if t = ta.subjects[subject.dn]; t == nil {
+			t = ta.subjects[subject.cn]
+		}
+	case ta.delegate != nil:
+		if ctx, err = ta.delegate.Authenticate(ctx, headers); err != nil {
+			return ctx, err
+		}
+		delegated = client.FromContext(ctx).Auth
+		identity, t = ta.matchClaims(delegated)
+		method = methodAuthenticator
+	case credentials == "":
+		return ctx, errNoAuth
+	default:
+		return ctx, errInvalidCredentials
+	}
+
+	if t == nil {
+		t = ta.fallback
+	}
+	if t == nil {
+		return ctx, errUnknownTenant
+	}
+
+	cl := client.FromContext(ctx)
+	cl.Auth = &authData{
+		tenantID:  t.id,
+		subject:   identity,
+		method:    method,
+		delegated: delegated,
+	}
+	return client.NewContext(ctx, cl), nil
+}
+
+// authenticateUser returns the user of basic auth credentials that match the htpasswd entries.
+func (ta *tenantAuth) authenticateUser(encoded string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", errInvalidFormat
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", errInvalidFormat
+	}
+	if !ta.matchUser(username, password) {
+		return "", errInvalidCredentials
+	}
+	return username, nil
+}
+
+// matchClaims returns the value of the attribute of the authenticator that is mapped to a tenant, if any.
+func (ta *tenantAuth) matchClaims(data client.AuthData) (string, *tenant) {
+	if data == nil {
+		return "", nil
+	}
+	var values []string
+	switch value := data.GetAttribute(ta.cfg.Attribute).(type) {
+	case string:
+		values = []string{value}
+	case []string:
+		values = value
+	case []any:
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	for _, value := range values {
+		if t, ok := ta.claims[value]; ok {
+			return value, t
+		}
+	}
+	if len(values) > 0 {
+		return values[0], nil
+	}
+	return "", nil
+}
+
+type certificateSubject struct {
+	dn string
+	cn string
+}
+
+// peerCertificateSubject returns the subject of the verified client certificate of a gRPC request.
+func peerCertificateSubject(ctx context.Context) *certificateSubject {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	cert := tlsInfo.State.VerifiedChains[0][0]
+	return &certificateSubject{dn: cert.Subject.String(), cn: cert.Subject.CommonName}
+}
+
+// getHeader returns the first value of a header, whose name is lower case for gRPC metadata
+// and canonical for HTTP headers.
+func getHeader(headers map[string][]string, name string) string {
+	for _, key := range []string{name, strings.ToLower(name), http.CanonicalHeaderKey(name)} {
+		if values := headers[key]; len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,301 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tenantauthextension
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/extension/auth"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var oidcID = component.MustNewID("oidc")
+
+type testHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h *testHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+// claimsAuthData is the auth data of the test authenticator.
+type claimsAuthData struct {
+	groups []string
+}
+
+func (a *claimsAuthData) GetAttribute(name string) any {
+	if name == "membership" {
+		return a.groups
+	}
+	return nil
+}
+
+func (*claimsAuthData) GetAttributeNames() []string {
+	return []string{"membership"}
+}
+
+// newTestAuthenticator authenticates the requests whose authorization header is a comma separated list of groups.
+func newTestAuthenticator() auth.Server {
+	return auth.NewServer(auth.WithServerAuthenticate(func(ctx context.Context, headers map[string][]string) (context.Context, error) {
+		groups := getHeader(headers, "Authorization")
+		if groups == "" || groups == "invalid" {
+			return ctx, errors.New("unauthenticated")
+		}
+		cl := client.FromContext(ctx)
+		cl.Auth = &claimsAuthData{groups: []string{"other", groups}}
+		return client.NewContext(ctx, cl), nil
+	}))
+}
+
+func newTestExtension(t *testing.T, cfg *Config) *tenantAuthExtension {
+	if cfg.Header == "" {
+		cfg.Header = defaultHeader
+	}
+	require.NoError(t, cfg.Validate())
+	ext := newServerAuthExtension(cfg, zap.NewNop())
+	host := &testHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{oidcID: newTestAuthenticator()},
+	}
+	require.NoError(t, ext.Start(context.Background(), host))
+	t.Cleanup(func() {
+		require.NoError(t, ext.Shutdown(context.Background()))
+	})
+	return ext
+}
+
+func basicAuth(username, password string) map[string][]string {
+	return map[string][]string{
+		"authorization": {"Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))},
+	}
+}
+
+func peerContext(subject pkix.Name) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{{{Subject: subject}}},
+			},
+		},
+	})
+}
+
+func assertAuth(t *testing.T, ctx context.Context, tenantID, subject, method string) {
+	data := client.FromContext(ctx).Auth
+	require.NotNil(t, data)
+	assert.Equal(t, tenantID, data.GetAttribute(TenantIDAttribute))
+	assert.Equal(t, subject, data.GetAttribute(SubjectAttribute))
+	assert.Equal(t, method, data.GetAttribute(MethodAttribute))
+}
+
+func TestAuthenticateHtpasswd(t *testing.T) {
+	ext := newTestExtension(t, &Config{
+		Htpasswd: &HtpasswdSettings{Inline: "alice:secret\nbob:secret\ncarol:secret"},
+		Tenants: map[string]TenantSettings{
+			"team-a": {Users: []string{"alice"}},
+			"team-b": {Users: []string{"bob"}},
+		},
+	})
+
+	ctx, err := ext.Authenticate(context.Background(), basicAuth("alice", "secret"))
+	require.NoError(t, err)
+	assertAuth(t, ctx, "team-a", "alice", "htpasswd")
+
+	ctx, err = ext.Authenticate(context.Background(), basicAuth("bob", "secret"))
+	require.NoError(t, err)
+	assertAuth(t, ctx, "team-b", "bob", "htpasswd")
+
+	_, err = ext.Authenticate(context.Background(), basicAuth("alice", "wrong"))
+	assert.ErrorIs(t, err, errInvalidCredentials)
+
+	// Users that are not mapped to a tenant are rejected without a default tenant.
+	_, err = ext.Authenticate(context.Background(), basicAuth("carol", "secret"))
+	assert.ErrorIs(t, err, errUnknownTenant)
+
+	_, err = ext.Authenticate(context.Background(), map[string][]string{"authorization": {"Basic invalid"}})
+	assert.ErrorIs(t, err, errInvalidFormat)
+
+	_, err = ext.Authenticate(context.Background(), map[string][]string{})
+	assert.ErrorIs(t, err, errNoAuth)
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	ext := newTestExtension(t, &Config{
+		Header: "X-Scope-Token",
+		Tenants: map[string]TenantSettings{
+			"team-a": {Tokens: []configopaque.String{"token-a"}},
+			"team-b": {Tokens: []configopaque.String{"token-b1", "token-b2"}},
+		},
+	})
+
+	ctx, err := ext.Authenticate(context.Background(), map[string][]string{"X-Scope-Token": {"Bearer token-a"}})
+	require.NoError(t, err)
+	assertAuth(t, ctx, "team-a", "team-a", "token")
+
+	ctx, err = ext.Authenticate(context.Background(), map[string][]string{"x-scope-token": {"bearer token-b2"}})
+	require.NoError(t, err)
+	assertAuth(t, ctx, "team-b", "team-b", "token")
+
+	_, err = ext.Authenticate(context.Background(), map[string][]string{"x-scope-token": {"Bearer token-c"}})
+	assert.ErrorIs(t, err, errInvalidCredentials)
+
+	// The credentials are only read from the configured header.
+	_, err = ext.Authenticate(context.Background(), map[string][]string{"authorization": {"Bearer token-a"}})
+	assert.ErrorIs(t, err, errNoAuth)
+}
+
+func TestAuthenticateMTLS(t *testing.T) {
+	ext := newTestExtension(t, &Config{
+		Tenants: map[string]TenantSettings{
+			"team-a": {Subjects: []string{"collector-a"}},
+			"team-b": {Subjects: []string{"CN=collector,O=team-b"}},
+		},
+	})
+
+	ctx, err := ext.Authenticate(peerContext(pkix.Name{CommonName: "collector-a", Organization: []string{"team-a"}}), map[string][]string{})
+	require.NoError(t, err)
+	assertAuth(t, ctx, "team-a", "CN=collector-a,O=team-a", "mtls")
+
+	ctx, err = ext.Authenticate(peerContext(pkix.Name{CommonName: "collector", Organization: []string{"team-b"}}), map[string][]string{})
+	require.NoError(t, err)
+	assertAuth(t, ctx, "team-b", "CN=collector,O=team-b", "mtls")
+
+	_, err = ext.Authenticate(peerContext(pkix.Name{CommonName: "collector", Organization: []string{"team-c"}}), map[string][]string{})
+	assert.ErrorIs(t, err, errUnknownTenant)
+
+	// Peers without a verified certificate are not authenticated.
+	_, err = ext.Authenticate(peer.NewContext(context.Background(), &peer.Peer{}), map[string][]string{})
+	assert.ErrorIs(t, err, errNoAuth)
+}
+
+func TestAuthenticateAuthenticator(t *testing.T) {
+	ext := newTestExtension(t, &Config{
+		Authenticator: &oidcID,
+		Attribute:     "membership",
+		Tenants: map[string]TenantSettings{
+			"team-a": {Claims: []string{"group-a"}},
+			"team-b": {Claims: []string{"group-b"}},
+		},
+		DefaultTenant: "team-b",
+	})
+
+	ctx, err := ext.Authenticate(context.Background(), map[string][]string{"authorization": {"group-a"}})
+	require.NoError(t, err)
+	assertAuth(t, ctx, "team-a", "group-a", "authenticator")
+	// The attributes of the authenticator are still available.
+	data := client.FromContext(ctx).Auth
+	assert.Equal(t, []string{"other", "group-a"}, data.GetAttribute("membership"))
+	assert.Equal(t, []string{TenantIDAttribute, SubjectAttribute, MethodAttribute, "membership"}, data.GetAttributeNames())
+
+	// Requests that are authenticated but not mapped to a tenant use the default tenant.
+	ctx, err = ext.Authenticate(context.Background(), map[string][]string{"authorization": {"group-c"}})
+	require.NoError(t, err)
+	assertAuth(t, ctx, "team-b", "other", "authenticator")
+
+	_, err = ext.Authenticate(context.Background(), map[string][]string{"authorization": {"invalid"}})
+	assert.EqualError(t, err, "unauthenticated")
+}
+
+func TestStartWithAuthenticator(t *testing.T) {
+	missing := component.MustNewID("missing")
+	ext := newServerAuthExtension(&Config{Authenticator: &missing}, zap.NewNop())
+	assert.EqualError(t, ext.Start(context.Background(), componenttest.NewNopHost()), "authenticator missing not found")
+
+	// the extension is not an authenticator
+	var nop struct {
+		component.StartFunc
+		component.ShutdownFunc
+	}
+	host := &testHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{oidcID: nop},
+	}
+	ext = newServerAuthExtension(&Config{Authenticator: &oidcID}, zap.NewNop())
+	assert.ErrorIs(t, ext.Start(context.Background(), host), errNotServerAuthorizer)
+}
+
+func TestStartWithMissingHtpasswdFile(t *testing.T) {
+	ext := newServerAuthExtension(&Config{Htpasswd: &HtpasswdSettings{File: "/non/existing/file"}}, zap.NewNop())
+	assert.ErrorContains(t, ext.Start(context.Background(), componenttest.NewNopHost()), "open htpasswd file")
+}
+
+func TestRateLimit(t *testing.T) {
+	ext := newTestExtension(t, &Config{
+		Tenants: map[string]TenantSettings{
+			"team-a": {Tokens: []configopaque.String{"token-a"}},
+			"team-b": {
+				Tokens:    []configopaque.String{"token-b"},
+				RateLimit: &RateLimitSettings{RequestsPerSecond: 0.001, Burst: 3},
+			},
+		},
+		RateLimit: &RateLimitSettings{RequestsPerSecond: 0.001},
+	})
+	host := &testHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{component.MustNewID("tenantauth"): ext},
+	}
+	limiter, err := GetRateLimiter(host, component.MustNewID("tenantauth"))
+	require.NoError(t, err)
+
+	// Requests exceeding the rate limit are still authenticated.
+	ctxA, err := ext.Authenticate(context.Background(), map[string][]string{"authorization": {"Bearer token-a"}})
+	require.NoError(t, err)
+	ctxB, err := ext.Authenticate(context.Background(), map[string][]string{"authorization": {"Bearer token-b"}})
+	require.NoError(t, err)
+
+	// The default burst is the rate rounded up.
+	require.NoError(t, limiter.Allow(ctxA))
+	err = limiter.Allow(ctxA)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.EqualError(t, err, "rpc error: code = ResourceExhausted desc = tenant rate limit exceeded for team-a")
+	details := status.Convert(err).Details()
+	require.Len(t, details, 1)
+	retryInfo, ok := details[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	// The next request is allowed in 1000s, as the rejected requests don't use any tokens.
+	assert.InDelta(t, 1000, retryInfo.RetryDelay.AsDuration().Seconds(), 1)
+
+	// Tenants are limited independently.
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Allow(ctxB))
+	}
+	assert.Equal(t, codes.ResourceExhausted, status.Code(limiter.Allow(ctxB)))
+
+	// Requests without a tenant of the extension are not limited.
+	require.NoError(t, limiter.Allow(context.Background()))
+	unknown := client.NewContext(context.Background(), client.Info{Auth: &authData{tenantID: "team-c"}})
+	require.NoError(t, limiter.Allow(unknown))
+}
+
+func TestGetRateLimiter(t *testing.T) {
+	id := component.MustNewID("tenantauth")
+	_, err := GetRateLimiter(componenttest.NewNopHost(), id)
+	assert.EqualError(t, err, "extension tenantauth not found")
+
+	host := &testHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{id: newTestAuthenticator()},
+	}
+	_, err = GetRateLimiter(host, id)
+	assert.ErrorIs(t, err, errNotTenantAuth)
+}
@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tenantauthextension // import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/tenantauthextension"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var errNotTenantAuth = errors.New("extension is not a tenantauth extension")
+
+// RateLimiter enforces the rate limits of the tenants of a tenantauth extension. The limits are not enforced
+// by the authenticator, as receivers report any authentication error as Unauthenticated, or 401 for HTTP.
+type RateLimiter interface {
+	// Allow returns an error with the ResourceExhausted gRPC status code if the tenant of the request exceeds
+	// its rate limit, which receivers report as ResourceExhausted, or 429 for HTTP. The error tells clients
+	// when to retry with a RetryInfo detail. Requests without a tenant of the extension are always allowed.
+	Allow(ctx context.Context) error
+}
+
+// GetRateLimiter returns the RateLimiter of the tenantauth extension with the given ID.
+func GetRateLimiter(host component.Host, id component.ID) (RateLimiter, error) {
+	ext, ok := host.GetExtensions()[id]
+	if !ok {
+		return nil, fmt.Errorf("extension %s not found", id)
+	}
+	tae, ok := ext.(*tenantAuthExtension)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", id, errNotTenantAuth)
+	}
+	return tae.limiter, nil
+}
+
+func (ta *tenantAuth) Allow(ctx context.Context) error {
+	data := client.FromContext(ctx).Auth
+	if data == nil {
+		return nil
+	}
+	id, _ := data.GetAttribute(TenantIDAttribute).(string)
+	t, ok := ta.tenants[id]
+	if !ok || t.limiter == nil {
+		return nil
+	}
+
+	r := t.limiter.Reserve()
+	delay := r.Delay()
+	if delay == 0 {
+		return nil
+	}
+	// The request is rejected, so it doesn't use the tokens of the following requests.
+	r.Cancel()
+	st := status.Newf(codes.ResourceExhausted, "tenant rate limit exceeded for %s", id)
+	if withRetry, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)}); err == nil {
+		st = withRetry
+	}
+	return st.Err()
+}
@@ -0,0 +1,183 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package parseutils // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/parseutils"
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	cefPrefix     = "CEF:"
+	cefHeaderSize = 7
+)
+
+// ParseCEF parses an ArcSight Common Event Format message. Anything before the
+// "CEF:" prefix, such as a syslog header, is ignored. The header fields are
+// returned by name and the extension under "extensions", where values of
+// custom fields are keyed by their label (e.g. cs1Label=Action cs1=block yields
+// Action=block).
+func ParseCEF(input string) (map[string]any, error) {
+	start := strings.Index(input, cefPrefix)
+	if start < 0 {
+		return nil, errors.New("missing CEF prefix")
+	}
+	header, rest, err := splitHeader(input[start+len(cefPrefix):], cefHeaderSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CEF header: %w", err)
+	}
+
+	extensions, err := parseExtension(rest, " ")
+	if err != nil {
+		return nil, fmt.Errorf("invalid CEF extension: %w", err)
+	}
+
+	return map[string]any{
+		"version":               header[0],
+		"device_vendor":         header[1],
+		"device_product":        header[2],
+		"device_version":        header[3],
+		"device_event_class_id": header[4],
+		"name":                  header[5],
+		"severity":              header[6],
+		"extensions":            extensions,
+	}, nil
+}
+
+// splitHeader splits the first n fields separated by unescaped pipes off the
+// input. The fields are unescaped and the remainder is returned as is.
+func splitHeader(input string, n int) ([]string, string, error) {
+	fields := make([]string, 0, n)
+	var sb strings.Builder
+	for i := 0; i < len(input); i++ {
+		switch c := input[i]; {
+		case c == '\\' && i+1 < len(input) && (input[i+1] == '|' || input[i+1] == '\\'):
+			sb.WriteByte(input[i+1])
+			i++
+		case c == '|':
+			fields = append(fields, strings.TrimSpace(sb.String()))
+			sb.Reset()
+			if len(fields) == n {
+				return fields, input[i+1:], nil
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return nil, "", fmt.Errorf("expected %d fields, got %d", n, len(fields))
+}
+
+// parseExtension parses key=value pairs separated by delimiter. As values may
+// contain the delimiter, a value only ends where the delimiter is followed by a
+// key and an unescaped equal sign.
+func parseExtension(input, delimiter string) (map[string]any, error) {
+	type pair struct{ keyStart, eq int }
+	var pairs []pair
+	for i := 0; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			i++
+		case '=':
+			keyStart := i
+			for keyStart > 0 && isExtensionKeyChar(input[keyStart-1]) {
+				keyStart--
+			}
+			if keyStart == i {
+				continue
+			}
+			if keyStart == 0 || strings.HasSuffix(input[:keyStart], delimiter) {
+				pairs = append(pairs, pair{keyStart: keyStart, eq: i})
+			}
+		}
+	}
+
+	result := map[string]any{}
+	if len(pairs) == 0 {
+		if strings.TrimSpace(input) != "" {
+			return nil, fmt.Errorf("no key value pairs found in %q", input)
+		}
+		return result, nil
+	}
+	if prefix := strings.TrimSpace(input[:pairs[0].keyStart]); prefix != "" {
+		return nil, fmt.Errorf("unexpected text %q before the first key", prefix)
+	}
+
+	values := make(map[string]string, len(pairs))
+	for n, p := range pairs {
+		end := len(input)
+		if n+1 < len(pairs) {
+			end = pairs[n+1].keyStart - len(delimiter)
+		}
+		value := input[p.eq+1 : end]
+		if delimiter == " " {
+			value = strings.TrimRight(value, " ")
+		}
+		values[input[p.keyStart:p.eq]] = unescapeExtensionValue(value)
+	}
+
+	for key, value := range pairCustomLabels(values) {
+		result[key] = value
+	}
+	return result, nil
+}
+
+func isExtensionKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '.' || c == '-' || c == '[' || c == ']'
+}
+
+func unescapeExtensionValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			sb.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case '=', '\\', '|':
+			sb.WriteByte(value[i])
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte(value[i])
+		}
+	}
+	return sb.String()
+}
+
+// pairCustomLabels replaces the keys of custom fields that have a label, such
+// as cs1 with cs1Label, by the value of the label.
+func pairCustomLabels(values map[string]string) map[string]string {
+	var labels []string
+	for key := range values {
+		if strings.HasSuffix(key, "Label") {
+			labels = append(labels, key)
+		}
+	}
+	sort.Strings(labels)
+
+	for _, labelKey := range labels {
+		key := strings.TrimSuffix(labelKey, "Label")
+		label := values[labelKey]
+		value, ok := values[key]
+		if !ok || label == "" {
+			continue
+		}
+		delete(values, key)
+		delete(values, labelKey)
+		values[label] = value
+	}
+	return values
+}
@@ -0,0 +1,123 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package parseutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseCEF(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       string
+		expected    map[string]any
+		expectedErr string
+	}{
+		{
+			name:  "full",
+			input: `CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|src=10.0.0.1 dst=2.1.2.2 spt=1232`,
+			expected: map[string]any{
+				"version":               "0",
+				"device_vendor":         "Security",
+				"device_product":        "threatmanager",
+				"device_version":        "1.0",
+				"device_event_class_id": "100",
+				"name":                  "worm successfully stopped",
+				"severity":              "10",
+				"extensions": map[string]any{
+					"src": "10.0.0.1",
+					"dst": "2.1.2.2",
+					"spt": "1232",
+				},
+			},
+		},
+		{
+			name:  "syslog prefix and escaped header",
+			input: `<134>Feb 14 19:04:54 fw01 CEF:1|Vendor\|Inc|Firewall|2.0\\1|deny|Denied \| blocked|High|`,
+			expected: map[string]any{
+				"version":               "1",
+				"device_vendor":         "Vendor|Inc",
+				"device_product":        "Firewall",
+				"device_version":        `2.0\1`,
+				"device_event_class_id": "deny",
+				"name":                  "Denied | blocked",
+				"severity":              "High",
+				"extensions":            map[string]any{},
+			},
+		},
+		{
+			name:  "extension values with spaces and escapes",
+			input: `CEF:0|V|P|1|id|n|5|msg=User login failed  for admin\=root\nretrying request=https://example.com/?a=b&c=d path=C:\\Windows act=blocked`,
+			expected: map[string]any{
+				"version":               "0",
+				"device_vendor":         "V",
+				"device_product":        "P",
+				"device_version":        "1",
+				"device_event_class_id": "id",
+				"name":                  "n",
+				"severity":              "5",
+				"extensions": map[string]any{
+					"msg":     "User login failed  for admin=root\nretrying",
+					"request": "https://example.com/?a=b&c=d",
+					"path":    `C:\Windows`,
+					"act":     "blocked",
+				},
+			},
+		},
+		{
+			name:  "custom labels",
+			input: `CEF:0|V|P|1|id|n|5|cs1Label=Rule Name cs1=Block all cn1=42 cn1Label=Count cs2=unlabeled cs3Label=Missing`,
+			expected: map[string]any{
+				"version":               "0",
+				"device_vendor":         "V",
+				"device_product":        "P",
+				"device_version":        "1",
+				"device_event_class_id": "id",
+				"name":                  "n",
+				"severity":              "5",
+				"extensions": map[string]any{
+					"Rule Name": "Block all",
+					"Count":     "42",
+					"cs2":       "unlabeled",
+					"cs3Label":  "Missing",
+				},
+			},
+		},
+		{
+			name:        "missing prefix",
+			input:       `0|V|P|1|id|n|5|`,
+			expectedErr: "missing CEF prefix",
+		},
+		{
+			name:        "short header",
+			input:       `CEF:0|V|P|1|id`,
+			expectedErr: "invalid CEF header: expected 7 fields, got 4",
+		},
+		{
+			name:        "text before first key",
+			input:       `CEF:0|V|P|1|id|n|5|garbage src=1.1.1.1`,
+			expectedErr: `invalid CEF extension: unexpected text "garbage" before the first key`,
+		},
+		{
+			name:        "no pairs",
+			input:       `CEF:0|V|P|1|id|n|5|garbage`,
+			expectedErr: `invalid CEF extension: no key value pairs found in "garbage"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := ParseCEF(tc.input)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
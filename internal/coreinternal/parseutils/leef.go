@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package parseutils // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/parseutils"
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	leefPrefix       = "LEEF:"
+	leefHeaderSize   = 5
+	leefDefaultDelim = "\t"
+)
+
+// ParseLEEF parses an IBM Log Event Extended Format message, in version 1.0 or
+// 2.0. Anything before the "LEEF:" prefix, such as a syslog header, is ignored.
+// The header fields are returned by name and the event attributes under
+// "attributes", with custom fields paired with their labels as in ParseCEF.
+func ParseLEEF(input string) (map[string]any, error) {
+	start := strings.Index(input, leefPrefix)
+	if start < 0 {
+		return nil, errors.New("missing LEEF prefix")
+	}
+	header, rest, err := splitHeader(input[start+len(leefPrefix):], leefHeaderSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEEF header: %w", err)
+	}
+
+	delimiter := leefDefaultDelim
+	if strings.HasPrefix(header[0], "2") {
+		// Version 2.0 has an additional header field with the attribute delimiter.
+		// It is optional, so it is only taken as delimiter if followed by a pipe.
+		if i := strings.IndexByte(rest, '|'); i >= 0 && i <= len("0x09") && !strings.Contains(rest[:i], "=") {
+			if delimiter, err = parseLEEFDelimiter(rest[:i]); err != nil {
+				return nil, err
+			}
+			rest = rest[i+1:]
+		}
+	}
+
+	attributes, err := parseExtension(rest, delimiter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEEF attributes: %w", err)
+	}
+
+	return map[string]any{
+		"version":         header[0],
+		"vendor":          header[1],
+		"product":         header[2],
+		"product_version": header[3],
+		"event_id":        header[4],
+		"attributes":      attributes,
+	}, nil
+}
+
+// parseLEEFDelimiter parses the delimiter header field, which is either a single
+// character or its hex code prefixed by x or 0x.
+func parseLEEFDelimiter(field string) (string, error) {
+	switch {
+	case field == "":
+		return leefDefaultDelim, nil
+	case len(field) == 1:
+		return field, nil
+	}
+	lower := strings.ToLower(field)
+	hex, ok := strings.CutPrefix(lower, "0x")
+	if !ok {
+		if hex, ok = strings.CutPrefix(lower, "x"); !ok {
+			return "", fmt.Errorf("invalid LEEF delimiter %q", field)
+		}
+	}
+	code, err := strconv.ParseUint(hex, 16, 8)
+	if err != nil {
+		return "", fmt.Errorf("invalid LEEF delimiter %q: %w", field, err)
+	}
+	return string(rune(code)), nil
+}
@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package parseutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseLEEF(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       string
+		expected    map[string]any
+		expectedErr string
+	}{
+		{
+			name:  "version 1.0",
+			input: "LEEF:1.0|Microsoft|MSExchange|4.0 SP1|15345|src=192.0.2.0\tdst=172.50.123.1\tsev=5\tmsg=this is a message",
+			expected: map[string]any{
+				"version":         "1.0",
+				"vendor":          "Microsoft",
+				"product":         "MSExchange",
+				"product_version": "4.0 SP1",
+				"event_id":        "15345",
+				"attributes": map[string]any{
+					"src": "192.0.2.0",
+					"dst": "172.50.123.1",
+					"sev": "5",
+					"msg": "this is a message",
+				},
+			},
+		},
+		{
+			name:  "version 2.0 with delimiter",
+			input: "<13>Jan 18 11:07:53 host LEEF:2.0|Lancope|StealthWatch|1.0|41|^|src=192.0.2.0^dst=172.50.123.1^devCustomLabel=Zone^devCustom=dmz^note=a\\=b",
+			expected: map[string]any{
+				"version":         "2.0",
+				"vendor":          "Lancope",
+				"product":         "StealthWatch",
+				"product_version": "1.0",
+				"event_id":        "41",
+				"attributes": map[string]any{
+					"src":  "192.0.2.0",
+					"dst":  "172.50.123.1",
+					"Zone": "dmz",
+					"note": "a=b",
+				},
+			},
+		},
+		{
+			name:  "version 2.0 with hex delimiter",
+			input: "LEEF:2.0|V|P|1|id|x7C|a=1|b=2",
+			expected: map[string]any{
+				"version":         "2.0",
+				"vendor":          "V",
+				"product":         "P",
+				"product_version": "1",
+				"event_id":        "id",
+				"attributes": map[string]any{
+					"a": "1",
+					"b": "2",
+				},
+			},
+		},
+		{
+			name:  "version 2.0 without delimiter",
+			input: "LEEF:2.0|V|P|1|id|a=1\tb=2",
+			expected: map[string]any{
+				"version":         "2.0",
+				"vendor":          "V",
+				"product":         "P",
+				"product_version": "1",
+				"event_id":        "id",
+				"attributes": map[string]any{
+					"a": "1",
+					"b": "2",
+				},
+			},
+		},
+		{
+			name:        "missing prefix",
+			input:       "CEF:0|V|P|1|id|n|5|",
+			expectedErr: "missing LEEF prefix",
+		},
+		{
+			name:        "short header",
+			input:       "LEEF:1.0|V|P",
+			expectedErr: "invalid LEEF header: expected 5 fields, got 2",
+		},
+		{
+			name:        "invalid delimiter",
+			input:       "LEEF:2.0|V|P|1|id|zz|a=1",
+			expectedErr: `invalid LEEF delimiter "zz"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := ParseLEEF(tc.input)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package encoding resolves the encoding setting of components, which is
+// either one of the built-in OTLP encodings or the ID of an encoding extension.
+package encoding // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/encoding"
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const (
+	// OTLPProto is the OTLP protobuf encoding.
+	OTLPProto = "otlp_proto"
+	// OTLPJSON is the OTLP JSON encoding.
+	OTLPJSON = "otlp_json"
+)
+
+// NewTracesMarshaler returns the traces marshaler of the encoding.
+func NewTracesMarshaler(encoding string, host component.Host) (ptrace.Marshaler, error) {
+	switch encoding {
+	case OTLPProto:
+		return &ptrace.ProtoMarshaler{}, nil
+	case OTLPJSON:
+		return &ptrace.JSONMarshaler{}, nil
+	}
+	return loadExtension[ptrace.Marshaler](host, encoding, "a marshaler")
+}
+
+// NewMetricsMarshaler returns the metrics marshaler of the encoding.
+func NewMetricsMarshaler(encoding string, host component.Host) (pmetric.Marshaler, error) {
+	switch encoding {
+	case OTLPProto:
+		return &pmetric.ProtoMarshaler{}, nil
+	case OTLPJSON:
+		return &pmetric.JSONMarshaler{}, nil
+	}
+	return loadExtension[pmetric.Marshaler](host, encoding, "a marshaler")
+}
+
+// NewLogsMarshaler returns the logs marshaler of the encoding.
+func NewLogsMarshaler(encoding string, host component.Host) (plog.Marshaler, error) {
+	switch encoding {
+	case OTLPProto:
+		return &plog.ProtoMarshaler{}, nil
+	case OTLPJSON:
+		return &plog.JSONMarshaler{}, nil
+	}
+	return loadExtension[plog.Marshaler](host, encoding, "a marshaler")
+}
+
+// NewTracesUnmarshaler returns the traces unmarshaler of the encoding.
+func NewTracesUnmarshaler(encoding string, host component.Host) (ptrace.Unmarshaler, error) {
+	switch encoding {
+	case OTLPProto:
+		return &ptrace.ProtoUnmarshaler{}, nil
+	case OTLPJSON:
+		return &ptrace.JSONUnmarshaler{}, nil
+	}
+	return loadExtension[ptrace.Unmarshaler](host, encoding, "an unmarshaler")
+}
+
+// NewMetricsUnmarshaler returns the metrics unmarshaler of the encoding.
+func NewMetricsUnmarshaler(encoding string, host component.Host) (pmetric.Unmarshaler, error) {
+	switch encoding {
+	case OTLPProto:
+		return &pmetric.ProtoUnmarshaler{}, nil
+	case OTLPJSON:
+		return &pmetric.JSONUnmarshaler{}, nil
+	}
+	return loadExtension[pmetric.Unmarshaler](host, encoding, "an unmarshaler")
+}
+
+// NewLogsUnmarshaler returns the logs unmarshaler of the encoding.
+func NewLogsUnmarshaler(encoding string, host component.Host) (plog.Unmarshaler, error) {
+	switch encoding {
+	case OTLPProto:
+		return &plog.ProtoUnmarshaler{}, nil
+	case OTLPJSON:
+		return &plog.JSONUnmarshaler{}, nil
+	}
+	return loadExtension[plog.Unmarshaler](host, encoding, "an unmarshaler")
+}
+
+// loadExtension returns the encoding extension whose ID is the encoding.
+func loadExtension[T any](host component.Host, encoding string, kind string) (T, error) {
+	var encoder T
+	var id component.ID
+	if err := id.UnmarshalText([]byte(encoding)); err != nil {
+		return encoder, fmt.Errorf("invalid encoding %q: %w", encoding, err)
+	}
+	ext, ok := host.GetExtensions()[id]
+	if !ok {
+		return encoder, fmt.Errorf("unknown encoding extension %q", encoding)
+	}
+	encoder, ok = ext.(T)
+	if !ok {
+		return encoder, fmt.Errorf("extension %q is not %s", encoding, kind)
+	}
+	return encoder, nil
+}
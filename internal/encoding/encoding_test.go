@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package encoding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+type logsEncodingExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+func (logsEncodingExtension) MarshalLogs(plog.Logs) ([]byte, error) {
+	return []byte("logs"), nil
+}
+
+func (logsEncodingExtension) UnmarshalLogs([]byte) (plog.Logs, error) {
+	return plog.NewLogs(), nil
+}
+
+type testHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h *testHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+func TestBuiltinEncodings(t *testing.T) {
+	host := componenttest.NewNopHost()
+
+	tm, err := NewTracesMarshaler(OTLPProto, host)
+	require.NoError(t, err)
+	assert.Equal(t, &ptrace.ProtoMarshaler{}, tm)
+	mm, err := NewMetricsMarshaler(OTLPJSON, host)
+	require.NoError(t, err)
+	assert.Equal(t, &pmetric.JSONMarshaler{}, mm)
+	lm, err := NewLogsMarshaler(OTLPProto, host)
+	require.NoError(t, err)
+	assert.Equal(t, &plog.ProtoMarshaler{}, lm)
+
+	tu, err := NewTracesUnmarshaler(OTLPJSON, host)
+	require.NoError(t, err)
+	assert.Equal(t, &ptrace.JSONUnmarshaler{}, tu)
+	mu, err := NewMetricsUnmarshaler(OTLPProto, host)
+	require.NoError(t, err)
+	assert.Equal(t, &pmetric.ProtoUnmarshaler{}, mu)
+	lu, err := NewLogsUnmarshaler(OTLPJSON, host)
+	require.NoError(t, err)
+	assert.Equal(t, &plog.JSONUnmarshaler{}, lu)
+}
+
+func TestEncodingExtension(t *testing.T) {
+	host := &testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{
+			component.MustNewIDWithName("logs_encoding", "custom"): logsEncodingExtension{},
+		},
+	}
+
+	m, err := NewLogsMarshaler("logs_encoding/custom", host)
+	require.NoError(t, err)
+	assert.Equal(t, logsEncodingExtension{}, m)
+	u, err := NewLogsUnmarshaler("logs_encoding/custom", host)
+	require.NoError(t, err)
+	assert.Equal(t, logsEncodingExtension{}, u)
+
+	_, err = NewTracesMarshaler("logs_encoding/custom", host)
+	assert.EqualError(t, err, `extension "logs_encoding/custom" is not a marshaler`)
+	_, err = NewMetricsUnmarshaler("logs_encoding/custom", host)
+	assert.EqualError(t, err, `extension "logs_encoding/custom" is not an unmarshaler`)
+
+	_, err = NewLogsMarshaler("logs_encoding", host)
+	assert.EqualError(t, err, `unknown encoding extension "logs_encoding"`)
+
+	_, err = NewLogsUnmarshaler("1nvalid", host)
+	assert.ErrorContains(t, err, `invalid encoding "1nvalid"`)
+}
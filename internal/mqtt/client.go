@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt"
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.uber.org/zap"
+)
+
+const (
+	// ProtocolVersion31 is the protocol version of MQTT 3.1.
+	ProtocolVersion31 = 3
+	// ProtocolVersion311 is the protocol version of MQTT 3.1.1.
+	ProtocolVersion311 = 4
+	// ProtocolVersion5 is the protocol version of MQTT 5.
+	ProtocolVersion5 = 5
+)
+
+// ClientConfig defines the connection to an MQTT broker.
+type ClientConfig struct {
+	// Endpoint is the URL of the broker, e.g. tcp://localhost:1883. The tcp, ssl, ws and wss schemes are supported.
+	Endpoint string `mapstructure:"endpoint"`
+	// ClientID identifies the client to the broker. A random client ID is used if not set.
+	ClientID string `mapstructure:"client_id"`
+	// TLS configures the connection to the broker when the scheme of the endpoint is ssl or wss.
+	TLS *configtls.ClientConfig `mapstructure:"tls"`
+	// Username and Password authenticate the client.
+	Username string              `mapstructure:"username"`
+	Password configopaque.String `mapstructure:"password"`
+	// ProtocolVersion is the protocol version: 3 for MQTT 3.1, 4 for MQTT 3.1.1, or 5 for MQTT 5.
+	ProtocolVersion uint `mapstructure:"protocol_version"`
+	// CleanSession discards the session state of the client when it connects. Persistent sessions require a client ID.
+	CleanSession bool `mapstructure:"clean_session"`
+	// KeepAlive is the interval of the pings sent to the broker.
+	KeepAlive time.Duration `mapstructure:"keep_alive"`
+	// ConnectTimeout is the time to wait for the broker to accept the connection.
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+}
+
+// NewDefaultClientConfig returns the default client configuration.
+func NewDefaultClientConfig() ClientConfig {
+	return ClientConfig{
+		Endpoint:        "tcp://localhost:1883",
+		ProtocolVersion: ProtocolVersion311,
+		CleanSession:    true,
+		KeepAlive:       30 * time.Second,
+		ConnectTimeout:  10 * time.Second,
+	}
+}
+
+// Validate checks the client configuration is valid.
+func (cfg *ClientConfig) Validate() error {
+	var errs []error
+	if cfg.Endpoint == "" {
+		errs = append(errs, errors.New("endpoint must be specified"))
+	}
+	switch cfg.ProtocolVersion {
+	case ProtocolVersion31, ProtocolVersion311, ProtocolVersion5:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported protocol_version %d, it must be 3 (MQTT 3.1), 4 (MQTT 3.1.1) or 5 (MQTT 5)", cfg.ProtocolVersion))
+	}
+	if !cfg.CleanSession && cfg.ClientID == "" {
+		errs = append(errs, errors.New("client_id must be specified when clean_session is false"))
+	}
+	if cfg.KeepAlive < 0 {
+		errs = append(errs, errors.New("keep_alive must not be negative"))
+	}
+	if cfg.ConnectTimeout <= 0 {
+		errs = append(errs, errors.New("connect_timeout must be greater than 0"))
+	}
+	return errors.Join(errs...)
+}
+
+// Subscription is the subscription of a handler to a topic filter.
+type Subscription struct {
+	Filter string
+	// Handler is called with the topic and payload of each message matching the filter.
+	Handler func(topic string, payload []byte)
+}
+
+// Client is a client connected to an MQTT broker. It reconnects automatically once connected.
+type Client interface {
+	// Publish publishes a message and waits for the broker to acknowledge it, unless the QoS is 0.
+	Publish(ctx context.Context, topic string, qos byte, retain bool, payload []byte) error
+	// Subscribe subscribes to the topic filters, and subscribes again each time the client reconnects.
+	// Messages are acknowledged once their handler returns, so that the broker doesn't deliver more
+	// messages than the handlers consume.
+	Subscribe(ctx context.Context, qos byte, subscriptions []Subscription) error
+	// Disconnect disconnects from the broker, giving the work in progress up to quiesce to complete.
+	Disconnect(quiesce time.Duration)
+}
+
+// Connect connects a client to the broker, with the protocol version of the configuration. Client IDs
+// generated when none is configured start with the prefix.
+func Connect(ctx context.Context, cfg *ClientConfig, clientIDPrefix string, logger *zap.Logger) (Client, error) {
+	clientID := cfg.ClientID
+	if clientID == "" {
+		suffix := make([]byte, 8)
+		if _, err := rand.Read(suffix); err != nil {
+			return nil, fmt.Errorf("failed to generate a client ID: %w", err)
+		}
+		clientID = clientIDPrefix + hex.EncodeToString(suffix)
+	}
+
+	var tlsConfig *tls.Config
+	if cfg.TLS != nil {
+		var err error
+		if tlsConfig, err = cfg.TLS.LoadTLSConfig(ctx); err != nil {
+			return nil, fmt.Errorf("failed to load the TLS config: %w", err)
+		}
+	}
+
+	// The clients are returned only on success, so that a failed connection is a nil Client.
+	if cfg.ProtocolVersion == ProtocolVersion5 {
+		c, err := connect5(cfg, clientID, tlsConfig, logger)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	c, err := connect3(cfg, clientID, tlsConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,119 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt"
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
+	"go.uber.org/zap"
+)
+
+// subscriptionFailure is the return code of the subscriptions refused by the broker.
+const subscriptionFailure = 0x80
+
+// client3 is the MQTT 3.1 and 3.1.1 client, built on paho.mqtt.golang.
+type client3 struct {
+	client pahomqtt.Client
+	logger *zap.Logger
+
+	mu            sync.Mutex
+	qos           byte
+	subscriptions []Subscription
+}
+
+func connect3(cfg *ClientConfig, clientID string, tlsConfig *tls.Config, logger *zap.Logger) (*client3, error) {
+	c := &client3{logger: logger}
+	opts := pahomqtt.NewClientOptions().
+		AddBroker(cfg.Endpoint).
+		SetClientID(clientID).
+		SetUsername(cfg.Username).
+		SetPassword(string(cfg.Password)).
+		SetProtocolVersion(cfg.ProtocolVersion).
+		SetCleanSession(cfg.CleanSession).
+		SetKeepAlive(cfg.KeepAlive).
+		SetConnectTimeout(cfg.ConnectTimeout).
+		SetAutoReconnect(true).
+		SetConnectionLostHandler(func(_ pahomqtt.Client, err error) {
+			logger.Warn("Lost the connection to the MQTT broker", zap.Error(err))
+		}).
+		SetOnConnectHandler(c.onConnect)
+	if tlsConfig != nil {
+		opts.SetTLSConfig(tlsConfig)
+	}
+
+	c.client = pahomqtt.NewClient(opts)
+	token := c.client.Connect()
+	if !token.WaitTimeout(cfg.ConnectTimeout) {
+		c.client.Disconnect(0)
+		return nil, errors.New("failed to connect to the MQTT broker: timed out")
+	}
+	if err := token.Error(); err != nil {
+		return nil, fmt.Errorf("failed to connect to the MQTT broker: %w", err)
+	}
+	return c, nil
+}
+
+// onConnect subscribes again when the client reconnects, as the broker may have discarded the session.
+func (c *client3) onConnect(client pahomqtt.Client) {
+	c.mu.Lock()
+	qos, subscriptions := c.qos, c.subscriptions
+	c.mu.Unlock()
+	if len(subscriptions) == 0 {
+		return
+	}
+	c.logger.Info("Reconnected to the MQTT broker")
+	if err := subscribe3(context.Background(), client, qos, subscriptions); err != nil {
+		c.logger.Error("Failed to subscribe again after reconnecting", zap.Error(err))
+	}
+}
+
+func (c *client3) Publish(ctx context.Context, topic string, qos byte, retain bool, payload []byte) error {
+	return waitForToken(ctx, c.client.Publish(topic, qos, retain, payload))
+}
+
+func (c *client3) Subscribe(ctx context.Context, qos byte, subscriptions []Subscription) error {
+	if err := subscribe3(ctx, c.client, qos, subscriptions); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.qos, c.subscriptions = qos, subscriptions
+	return nil
+}
+
+func subscribe3(ctx context.Context, client pahomqtt.Client, qos byte, subscriptions []Subscription) error {
+	for _, s := range subscriptions {
+		handler := s.Handler
+		token := client.Subscribe(s.Filter, qos, func(_ pahomqtt.Client, msg pahomqtt.Message) {
+			handler(msg.Topic(), msg.Payload())
+		})
+		if err := waitForToken(ctx, token); err != nil {
+			return fmt.Errorf("failed to subscribe to %q: %w", s.Filter, err)
+		}
+		if st, ok := token.(*pahomqtt.SubscribeToken); ok && st.Result()[s.Filter] == subscriptionFailure {
+			return fmt.Errorf("failed to subscribe to %q: the broker refused the subscription", s.Filter)
+		}
+	}
+	return nil
+}
+
+func (c *client3) Disconnect(quiesce time.Duration) {
+	c.client.Disconnect(uint(quiesce.Milliseconds()))
+}
+
+// waitForToken waits for the completion of an operation, or for the context to be done.
+func waitForToken(ctx context.Context, token pahomqtt.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
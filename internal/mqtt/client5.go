@@ -0,0 +1,160 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt"
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+	"go.uber.org/zap"
+)
+
+const (
+	// sessionNeverExpires is the session expiry interval of the persistent sessions, which are kept by
+	// the broker until the client connects again, as in MQTT 3.1.1.
+	sessionNeverExpires = math.MaxUint32
+
+	// The reconnection delays match the ones of the MQTT 3.1.1 client.
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 10 * time.Minute
+)
+
+// client5 is the MQTT 5 client, built on the autopaho connection manager of paho.golang.
+type client5 struct {
+	manager *autopaho.ConnectionManager
+	router  *paho.StandardRouter
+	logger  *zap.Logger
+
+	mu            sync.Mutex
+	qos           byte
+	subscriptions []Subscription
+}
+
+func connect5(cfg *ClientConfig, clientID string, tlsConfig *tls.Config, logger *zap.Logger) (*client5, error) {
+	serverURL, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %q: %w", cfg.Endpoint, err)
+	}
+
+	c := &client5{router: paho.NewStandardRouter(), logger: logger}
+	var connectErr atomic.Value
+	clientConfig := autopaho.ClientConfig{
+		ServerUrls:                    []*url.URL{serverURL},
+		TlsCfg:                        tlsConfig,
+		KeepAlive:                     uint16(cfg.KeepAlive.Seconds()),
+		CleanStartOnInitialConnection: cfg.CleanSession,
+		ReconnectBackoff:              autopaho.NewExponentialBackoff(minReconnectDelay, maxReconnectDelay, 2*minReconnectDelay, 2),
+		ConnectTimeout:                cfg.ConnectTimeout,
+		ConnectUsername:               cfg.Username,
+		ConnectPassword:               []byte(cfg.Password),
+		OnConnectionUp: func(*autopaho.ConnectionManager, *paho.Connack) {
+			c.onConnect()
+		},
+		OnConnectError: func(err error) {
+			connectErr.Store(err)
+			logger.Debug("Failed to connect to the MQTT broker", zap.Error(err))
+		},
+		ClientConfig: paho.ClientConfig{
+			ClientID: clientID,
+			OnPublishReceived: []func(paho.PublishReceived) (bool, error){
+				func(pr paho.PublishReceived) (bool, error) {
+					c.router.Route(pr.Packet.Packet())
+					return true, nil
+				},
+			},
+			OnClientError: func(err error) {
+				logger.Warn("Lost the connection to the MQTT broker", zap.Error(err))
+			},
+			OnServerDisconnect: func(d *paho.Disconnect) {
+				logger.Warn("The MQTT broker closed the connection", zap.Uint8("reason_code", d.ReasonCode))
+			},
+		},
+	}
+	if !cfg.CleanSession {
+		clientConfig.SessionExpiryInterval = sessionNeverExpires
+	}
+
+	// The connection manager keeps reconnecting until it is disconnected, so it doesn't depend on the
+	// context of the caller.
+	c.manager, err = autopaho.NewConnection(context.Background(), clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the MQTT broker: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
+	defer cancel()
+	if err = c.manager.AwaitConnection(ctx); err != nil {
+		_ = c.manager.Disconnect(ctx)
+		if lastErr, ok := connectErr.Load().(error); ok {
+			return nil, fmt.Errorf("failed to connect to the MQTT broker: %w", lastErr)
+		}
+		return nil, errors.New("failed to connect to the MQTT broker: timed out")
+	}
+	return c, nil
+}
+
+// onConnect subscribes again when the client reconnects, as the broker may have discarded the session.
+func (c *client5) onConnect() {
+	c.mu.Lock()
+	qos, subscriptions := c.qos, c.subscriptions
+	c.mu.Unlock()
+	if len(subscriptions) == 0 {
+		return
+	}
+	c.logger.Info("Reconnected to the MQTT broker")
+	if err := c.subscribe(context.Background(), qos, subscriptions); err != nil {
+		c.logger.Error("Failed to subscribe again after reconnecting", zap.Error(err))
+	}
+}
+
+func (c *client5) Publish(ctx context.Context, topic string, qos byte, retain bool, payload []byte) error {
+	_, err := c.manager.Publish(ctx, &paho.Publish{
+		Topic:   topic,
+		QoS:     qos,
+		Retain:  retain,
+		Payload: payload,
+	})
+	return err
+}
+
+func (c *client5) Subscribe(ctx context.Context, qos byte, subscriptions []Subscription) error {
+	for _, s := range subscriptions {
+		handler := s.Handler
+		c.router.RegisterHandler(s.Filter, func(p *paho.Publish) {
+			handler(p.Topic, p.Payload)
+		})
+	}
+	if err := c.subscribe(ctx, qos, subscriptions); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.qos, c.subscriptions = qos, subscriptions
+	return nil
+}
+
+func (c *client5) subscribe(ctx context.Context, qos byte, subscriptions []Subscription) error {
+	for _, s := range subscriptions {
+		if _, err := c.manager.Subscribe(ctx, &paho.Subscribe{
+			Subscriptions: []paho.SubscribeOptions{{Topic: s.Filter, QoS: qos}},
+		}); err != nil {
+			return fmt.Errorf("failed to subscribe to %q: %w", s.Filter, err)
+		}
+	}
+	return nil
+}
+
+func (c *client5) Disconnect(quiesce time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), quiesce)
+	defer cancel()
+	_ = c.manager.Disconnect(ctx)
+}
@@ -0,0 +1,186 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt/mqtttest"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*ClientConfig)
+		expectedErr string
+	}{
+		{
+			name:   "default",
+			modify: func(*ClientConfig) {},
+		},
+		{
+			name: "persistent session",
+			modify: func(cfg *ClientConfig) {
+				cfg.CleanSession = false
+				cfg.ClientID = "gateway-1"
+			},
+		},
+		{
+			name: "mqtt 5",
+			modify: func(cfg *ClientConfig) {
+				cfg.ProtocolVersion = ProtocolVersion5
+			},
+		},
+		{
+			name: "invalid",
+			modify: func(cfg *ClientConfig) {
+				cfg.Endpoint = ""
+				cfg.ProtocolVersion = 6
+				cfg.CleanSession = false
+				cfg.KeepAlive = -time.Second
+				cfg.ConnectTimeout = 0
+			},
+			expectedErr: "endpoint must be specified\n" +
+				"unsupported protocol_version 6, it must be 3 (MQTT 3.1), 4 (MQTT 3.1.1) or 5 (MQTT 5)\n" +
+				"client_id must be specified when clean_session is false\n" +
+				"keep_alive must not be negative\n" +
+				"connect_timeout must be greater than 0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewDefaultClientConfig()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+// protocolVersions are the protocol versions of the clients, by name.
+var protocolVersions = map[string]uint{
+	"mqtt_3.1":   ProtocolVersion31,
+	"mqtt_3.1.1": ProtocolVersion311,
+	"mqtt_5":     ProtocolVersion5,
+}
+
+func connect(t *testing.T, broker *mqtttest.Broker, version uint) Client {
+	cfg := NewDefaultClientConfig()
+	cfg.Endpoint = broker.Endpoint()
+	cfg.ProtocolVersion = version
+	client, err := Connect(context.Background(), &cfg, "otelcol-test-", zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Disconnect(0) })
+	return client
+}
+
+func TestConnect(t *testing.T) {
+	for name, version := range protocolVersions {
+		t.Run(name, func(t *testing.T) {
+			broker := mqtttest.NewBroker(t)
+			client := connect(t, broker, version)
+
+			for qos := byte(0); qos <= 2; qos++ {
+				require.NoError(t, client.Publish(context.Background(), "devices/1", qos, qos == 2, []byte{qos}))
+			}
+			require.Eventually(t, func() bool {
+				return len(broker.Messages()) == 3
+			}, 5*time.Second, 10*time.Millisecond)
+			assert.Equal(t, []mqtttest.Message{
+				{Topic: "devices/1", Payload: []byte{0}, QoS: 0},
+				{Topic: "devices/1", Payload: []byte{1}, QoS: 1},
+				{Topic: "devices/1", Payload: []byte{2}, QoS: 2, Retain: true},
+			}, broker.Messages())
+			assert.Equal(t, []byte{byte(version)}, broker.ProtocolVersions())
+
+			clientIDs := broker.ClientIDs()
+			require.Len(t, clientIDs, 1)
+			assert.True(t, strings.HasPrefix(clientIDs[0], "otelcol-test-"))
+			assert.Len(t, clientIDs[0], len("otelcol-test-")+16)
+		})
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	for name, version := range protocolVersions {
+		t.Run(name, func(t *testing.T) {
+			broker := mqtttest.NewBroker(t)
+			client := connect(t, broker, version)
+
+			received := make(chan mqtttest.Message, 10)
+			handler := func(topic string, payload []byte) {
+				received <- mqtttest.Message{Topic: topic, Payload: payload}
+			}
+			require.NoError(t, client.Subscribe(context.Background(), 1, []Subscription{
+				{Filter: "devices/+/logs", Handler: handler},
+				{Filter: "devices/+/metrics", Handler: handler},
+			}))
+			assert.ElementsMatch(t, []string{"devices/+/logs", "devices/+/metrics"}, broker.Subscriptions())
+
+			receive := func(topic string) {
+				broker.Publish(mqtttest.Message{Topic: topic, Payload: []byte(topic), QoS: 1})
+				select {
+				case msg := <-received:
+					assert.Equal(t, mqtttest.Message{Topic: topic, Payload: []byte(topic)}, msg)
+				case <-time.After(5 * time.Second):
+					t.Fatalf("message published to %q not received", topic)
+				}
+			}
+			receive("devices/1/logs")
+			receive("devices/2/metrics")
+
+			// The client subscribes again once reconnected.
+			broker.DisconnectClients()
+			require.Eventually(t, func() bool {
+				return len(broker.ClientIDs()) == 2 && len(broker.Subscriptions()) == 2
+			}, 10*time.Second, 10*time.Millisecond)
+			receive("devices/3/logs")
+		})
+	}
+}
+
+func TestConnectErrors(t *testing.T) {
+	for name, version := range protocolVersions {
+		t.Run(name, func(t *testing.T) {
+			cfg := NewDefaultClientConfig()
+			cfg.Endpoint = "tcp://127.0.0.1:1"
+			cfg.ProtocolVersion = version
+			cfg.ConnectTimeout = time.Second
+			_, err := Connect(context.Background(), &cfg, "otelcol-", zap.NewNop())
+			assert.ErrorContains(t, err, "failed to connect to the MQTT broker")
+
+			cfg.TLS = &configtls.ClientConfig{Config: configtls.Config{CAFile: "missing.pem"}}
+			_, err = Connect(context.Background(), &cfg, "otelcol-", zap.NewNop())
+			assert.ErrorContains(t, err, "failed to load the TLS config")
+		})
+	}
+}
+
+// pendingToken is a token whose operation never completes.
+type pendingToken struct {
+	pahomqtt.Token
+}
+
+func (pendingToken) Done() <-chan struct{} {
+	return make(chan struct{})
+}
+
+func TestWaitForTokenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, waitForToken(ctx, pendingToken{}), context.Canceled)
+}
@@ -0,0 +1,478 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package mqtttest provides an in-process MQTT 3.1.1 and 5 broker to test the components connecting to MQTT brokers.
+package mqtttest // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt/mqtttest"
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	packets5 "github.com/eclipse/paho.golang/packets"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+)
+
+// protocolVersion5 is the protocol version of MQTT 5.
+const protocolVersion5 = 5
+
+// Message is a message published to the broker.
+type Message struct {
+	Topic   string
+	Payload []byte
+	QoS     byte
+	Retain  bool
+}
+
+// Broker is a minimal in-process MQTT broker. It supports MQTT 3.1, 3.1.1 and 5 clients, QoS 0, 1 and 2,
+// and retained messages. Sessions are not persisted: subscriptions are dropped when clients disconnect.
+type Broker struct {
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu        sync.Mutex
+	conns     map[*brokerConn]struct{}
+	messages  []Message
+	retained  map[string]Message
+	connected []string
+	versions  []byte
+}
+
+type brokerConn struct {
+	conn net.Conn
+	// version is the protocol version of the client.
+	version byte
+	mu      sync.Mutex
+	nextID  uint16
+	// subscriptions maps the topic filters of the client to their QoS.
+	subscriptions map[string]byte
+}
+
+// NewBroker starts a broker listening on a random local port. It is stopped when the test ends.
+func NewBroker(tb testing.TB) *Broker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatalf("failed to listen: %v", err)
+	}
+	b := &Broker{
+		listener: listener,
+		conns:    map[*brokerConn]struct{}{},
+		retained: map[string]Message{},
+	}
+	b.wg.Add(1)
+	go b.accept()
+	tb.Cleanup(b.Close)
+	return b
+}
+
+// Endpoint returns the URL clients connect to.
+func (b *Broker) Endpoint() string {
+	return "tcp://" + b.listener.Addr().String()
+}
+
+// Messages returns the messages published by the clients.
+func (b *Broker) Messages() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Message(nil), b.messages...)
+}
+
+// Retained returns the message retained for a topic.
+func (b *Broker) Retained(topic string) (Message, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	msg, ok := b.retained[topic]
+	return msg, ok
+}
+
+// ClientIDs returns the IDs of the clients that connected to the broker.
+func (b *Broker) ClientIDs() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.connected...)
+}
+
+// ProtocolVersions returns the protocol versions of the clients that connected to the broker.
+func (b *Broker) ProtocolVersions() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.versions...)
+}
+
+// Subscriptions returns the topic filters the connected clients subscribed to.
+func (b *Broker) Subscriptions() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var filters []string
+	for c := range b.conns {
+		c.mu.Lock()
+		for filter := range c.subscriptions {
+			filters = append(filters, filter)
+		}
+		c.mu.Unlock()
+	}
+	return filters
+}
+
+// Publish publishes a message to the subscribed clients as if a client had published it.
+func (b *Broker) Publish(msg Message) {
+	b.route(msg)
+}
+
+// DisconnectClients closes the connections of all the clients.
+func (b *Broker) DisconnectClients() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for c := range b.conns {
+		_ = c.conn.Close()
+	}
+}
+
+// Close stops the broker and closes the connections of the clients.
+func (b *Broker) Close() {
+	_ = b.listener.Close()
+	b.DisconnectClients()
+	b.wg.Wait()
+}
+
+func (b *Broker) accept() {
+	defer b.wg.Done()
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		c := &brokerConn{conn: conn, subscriptions: map[string]byte{}}
+		b.mu.Lock()
+		b.conns[c] = struct{}{}
+		b.mu.Unlock()
+
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			b.serve(c)
+			b.mu.Lock()
+			delete(b.conns, c)
+			b.mu.Unlock()
+			_ = conn.Close()
+		}()
+	}
+}
+
+func (b *Broker) serve(c *brokerConn) {
+	r := bufio.NewReader(c.conn)
+	version, err := peekProtocolVersion(r)
+	if err != nil {
+		return
+	}
+	c.version = version
+	if version == protocolVersion5 {
+		b.serve5(c, r)
+	} else {
+		b.serve3(c, r)
+	}
+}
+
+// peekProtocolVersion returns the protocol version of the CONNECT packet the client sends first, which
+// defines the format of the other packets.
+func peekProtocolVersion(r *bufio.Reader) (byte, error) {
+	header, err := r.Peek(5)
+	if err != nil && len(header) < 2 {
+		return 0, err
+	}
+	// The fixed header is followed by the remaining length, encoded on 1 to 4 bytes.
+	offset := 1
+	for offset < len(header) && header[offset]&0x80 != 0 {
+		offset++
+	}
+	offset++
+	// The variable header starts with the protocol name, prefixed by its length, then the protocol version.
+	buf, err := r.Peek(offset + 2)
+	if err != nil {
+		return 0, err
+	}
+	nameLength := int(buf[offset])<<8 | int(buf[offset+1])
+	buf, err = r.Peek(offset + 2 + nameLength + 1)
+	if err != nil {
+		return 0, err
+	}
+	return buf[len(buf)-1], nil
+}
+
+func (b *Broker) addClient(clientID string, version byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.connected = append(b.connected, clientID)
+	b.versions = append(b.versions, version)
+}
+
+func (b *Broker) serve3(c *brokerConn, r io.Reader) {
+	for {
+		packet, err := packets.ReadPacket(r)
+		if err != nil {
+			return
+		}
+		switch p := packet.(type) {
+		case *packets.ConnectPacket:
+			b.addClient(p.ClientIdentifier, p.ProtocolVersion)
+			connack := packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+			connack.ReturnCode = p.Validate()
+			if c.write3(connack) != nil || connack.ReturnCode != packets.Accepted {
+				return
+			}
+		case *packets.SubscribePacket:
+			suback := packets.NewControlPacket(packets.Suback).(*packets.SubackPacket)
+			suback.MessageID = p.MessageID
+			c.mu.Lock()
+			for i, filter := range p.Topics {
+				c.subscriptions[filter] = p.Qoss[i]
+			}
+			c.mu.Unlock()
+			suback.ReturnCodes = p.Qoss
+			if c.write3(suback) != nil {
+				return
+			}
+			b.sendRetained(c, p.Topics)
+		case *packets.UnsubscribePacket:
+			c.mu.Lock()
+			for _, filter := range p.Topics {
+				delete(c.subscriptions, filter)
+			}
+			c.mu.Unlock()
+			unsuback := packets.NewControlPacket(packets.Unsuback).(*packets.UnsubackPacket)
+			unsuback.MessageID = p.MessageID
+			if c.write3(unsuback) != nil {
+				return
+			}
+		case *packets.PublishPacket:
+			msg := Message{Topic: p.TopicName, Payload: p.Payload, QoS: p.Qos, Retain: p.Retain}
+			b.mu.Lock()
+			b.messages = append(b.messages, msg)
+			b.mu.Unlock()
+			b.route(msg)
+
+			var ack packets.ControlPacket
+			switch p.Qos {
+			case 1:
+				puback := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+				puback.MessageID = p.MessageID
+				ack = puback
+			case 2:
+				pubrec := packets.NewControlPacket(packets.Pubrec).(*packets.PubrecPacket)
+				pubrec.MessageID = p.MessageID
+				ack = pubrec
+			}
+			if ack != nil && c.write3(ack) != nil {
+				return
+			}
+		case *packets.PubrelPacket:
+			pubcomp := packets.NewControlPacket(packets.Pubcomp).(*packets.PubcompPacket)
+			pubcomp.MessageID = p.MessageID
+			if c.write3(pubcomp) != nil {
+				return
+			}
+		case *packets.PubrecPacket:
+			pubrel := packets.NewControlPacket(packets.Pubrel).(*packets.PubrelPacket)
+			pubrel.MessageID = p.MessageID
+			if c.write3(pubrel) != nil {
+				return
+			}
+		case *packets.PingreqPacket:
+			if c.write3(packets.NewControlPacket(packets.Pingresp)) != nil {
+				return
+			}
+		case *packets.DisconnectPacket:
+			return
+		}
+	}
+}
+
+func (b *Broker) serve5(c *brokerConn, r io.Reader) {
+	for {
+		packet, err := packets5.ReadPacket(r)
+		if err != nil {
+			return
+		}
+		var response interface {
+			WriteTo(io.Writer) (int64, error)
+		}
+		switch p := packet.Content.(type) {
+		case *packets5.Connect:
+			b.addClient(p.ClientID, p.ProtocolVersion)
+			response = &packets5.Connack{Properties: &packets5.Properties{}, ReasonCode: packets5.ConnackSuccess}
+		case *packets5.Subscribe:
+			suback := &packets5.Suback{Properties: &packets5.Properties{}, PacketID: p.PacketID}
+			var filters []string
+			c.mu.Lock()
+			for _, s := range p.Subscriptions {
+				c.subscriptions[s.Topic] = s.QoS
+				suback.Reasons = append(suback.Reasons, s.QoS)
+				filters = append(filters, s.Topic)
+			}
+			c.mu.Unlock()
+			if c.write(suback.WriteTo) != nil {
+				return
+			}
+			b.sendRetained(c, filters)
+		case *packets5.Unsubscribe:
+			unsuback := &packets5.Unsuback{Properties: &packets5.Properties{}, PacketID: p.PacketID}
+			c.mu.Lock()
+			for _, filter := range p.Topics {
+				delete(c.subscriptions, filter)
+				unsuback.Reasons = append(unsuback.Reasons, packets5.UnsubackSuccess)
+			}
+			c.mu.Unlock()
+			response = unsuback
+		case *packets5.Publish:
+			msg := Message{Topic: p.Topic, Payload: p.Payload, QoS: p.QoS, Retain: p.Retain}
+			b.mu.Lock()
+			b.messages = append(b.messages, msg)
+			b.mu.Unlock()
+			b.route(msg)
+
+			switch p.QoS {
+			case 1:
+				response = &packets5.Puback{Properties: &packets5.Properties{}, PacketID: p.PacketID}
+			case 2:
+				response = &packets5.Pubrec{Properties: &packets5.Properties{}, PacketID: p.PacketID}
+			}
+		case *packets5.Pubrel:
+			response = &packets5.Pubcomp{Properties: &packets5.Properties{}, PacketID: p.PacketID}
+		case *packets5.Pubrec:
+			response = &packets5.Pubrel{Properties: &packets5.Properties{}, PacketID: p.PacketID}
+		case *packets5.Pingreq:
+			response = packets5.NewControlPacket(packets5.PINGRESP)
+		case *packets5.Disconnect:
+			return
+		}
+		if response != nil && c.write(response.WriteTo) != nil {
+			return
+		}
+	}
+}
+
+// route sends a message to the clients subscribed to its topic, and retains it if requested.
+func (b *Broker) route(msg Message) {
+	b.mu.Lock()
+	if msg.Retain {
+		if len(msg.Payload) == 0 {
+			delete(b.retained, msg.Topic)
+		} else {
+			b.retained[msg.Topic] = msg
+		}
+	}
+	conns := make([]*brokerConn, 0, len(b.conns))
+	for c := range b.conns {
+		conns = append(conns, c)
+	}
+	b.mu.Unlock()
+
+	for _, c := range conns {
+		if qos, ok := c.subscribed(msg.Topic); ok {
+			_ = c.publish(msg, min(qos, msg.QoS), false)
+		}
+	}
+}
+
+func (b *Broker) sendRetained(c *brokerConn, filters []string) {
+	b.mu.Lock()
+	var retained []Message
+	for _, msg := range b.retained {
+		for _, filter := range filters {
+			if MatchTopic(filter, msg.Topic) {
+				retained = append(retained, msg)
+				break
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	for _, msg := range retained {
+		if qos, ok := c.subscribed(msg.Topic); ok {
+			_ = c.publish(msg, min(qos, msg.QoS), true)
+		}
+	}
+}
+
+// subscribed returns the highest QoS of the subscriptions matching the topic.
+func (c *brokerConn) subscribed(topic string) (byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var qos byte
+	matched := false
+	for filter, q := range c.subscriptions {
+		if MatchTopic(filter, topic) {
+			matched = true
+			qos = max(qos, q)
+		}
+	}
+	return qos, matched
+}
+
+func (c *brokerConn) publish(msg Message, qos byte, retain bool) error {
+	var packetID uint16
+	if qos > 0 {
+		c.mu.Lock()
+		c.nextID++
+		if c.nextID == 0 {
+			c.nextID = 1
+		}
+		packetID = c.nextID
+		c.mu.Unlock()
+	}
+	if c.version == protocolVersion5 {
+		p := &packets5.Publish{
+			Topic:      msg.Topic,
+			Payload:    msg.Payload,
+			QoS:        qos,
+			Retain:     retain,
+			PacketID:   packetID,
+			Properties: &packets5.Properties{},
+		}
+		return c.write(p.WriteTo)
+	}
+	p := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+	p.TopicName = msg.Topic
+	p.Payload = msg.Payload
+	p.Qos = qos
+	p.Retain = retain
+	p.MessageID = packetID
+	return c.write3(p)
+}
+
+func (c *brokerConn) write3(p packets.ControlPacket) error {
+	return c.write(func(w io.Writer) (int64, error) {
+		return 0, p.Write(w)
+	})
+}
+
+func (c *brokerConn) write(writeTo func(io.Writer) (int64, error)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := writeTo(c.conn); err != nil {
+		return errors.Join(err, c.conn.Close())
+	}
+	return nil
+}
+
+// MatchTopic returns whether a topic matches a topic filter, which can contain the + and # wildcards.
+func MatchTopic(filter, topic string) bool {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	for i, level := range filterLevels {
+		if level == "#" {
+			return true
+		}
+		if i >= len(topicLevels) {
+			return false
+		}
+		if level != "+" && level != topicLevels[i] {
+			return false
+		}
+	}
+	return len(filterLevels) == len(topicLevels)
+}
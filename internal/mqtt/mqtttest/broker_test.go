@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtttest
+
+import (
+	"testing"
+	"time"
+
+	pahomqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		filter string
+		topic  string
+		match  bool
+	}{
+		{filter: "devices/1/logs", topic: "devices/1/logs", match: true},
+		{filter: "devices/1/logs", topic: "devices/2/logs", match: false},
+		{filter: "devices/+/logs", topic: "devices/2/logs", match: true},
+		{filter: "devices/+/logs", topic: "devices/2/metrics", match: false},
+		{filter: "devices/+", topic: "devices/2/logs", match: false},
+		{filter: "devices/#", topic: "devices/2/logs", match: true},
+		{filter: "devices/#", topic: "devices", match: true},
+		{filter: "#", topic: "devices/2/logs", match: true},
+		{filter: "devices/+/logs/extra", topic: "devices/2/logs", match: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.match, MatchTopic(tt.filter, tt.topic), "%s %s", tt.filter, tt.topic)
+	}
+}
+
+func connect(t *testing.T, broker *Broker) pahomqtt.Client {
+	client := pahomqtt.NewClient(pahomqtt.NewClientOptions().AddBroker(broker.Endpoint()).SetClientID(t.Name()))
+	token := client.Connect()
+	require.True(t, token.WaitTimeout(5*time.Second))
+	require.NoError(t, token.Error())
+	t.Cleanup(func() { client.Disconnect(0) })
+	return client
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	broker := NewBroker(t)
+	publisher := connect(t, broker)
+	subscriber := connect(t, broker)
+
+	received := make(chan pahomqtt.Message, 10)
+	token := subscriber.Subscribe("devices/+/logs", 2, func(_ pahomqtt.Client, msg pahomqtt.Message) {
+		received <- msg
+	})
+	require.True(t, token.WaitTimeout(5*time.Second))
+	require.NoError(t, token.Error())
+	assert.Equal(t, []string{"devices/+/logs"}, broker.Subscriptions())
+
+	for qos := byte(0); qos <= 2; qos++ {
+		token = publisher.Publish("devices/1/logs", qos, false, []byte{qos})
+		require.True(t, token.WaitTimeout(5*time.Second))
+		require.NoError(t, token.Error())
+
+		select {
+		case msg := <-received:
+			assert.Equal(t, "devices/1/logs", msg.Topic())
+			assert.Equal(t, []byte{qos}, msg.Payload())
+			assert.Equal(t, qos, msg.Qos())
+		case <-time.After(5 * time.Second):
+			t.Fatalf("message with QoS %d not received", qos)
+		}
+	}
+
+	broker.Publish(Message{Topic: "devices/2/logs", Payload: []byte("from broker"), QoS: 1})
+	select {
+	case msg := <-received:
+		assert.Equal(t, "devices/2/logs", msg.Topic())
+	case <-time.After(5 * time.Second):
+		t.Fatal("message published by the broker not received")
+	}
+	assert.Len(t, broker.Messages(), 3)
+}
+
+func TestRetained(t *testing.T) {
+	broker := NewBroker(t)
+	publisher := connect(t, broker)
+
+	token := publisher.Publish("devices/1/status", 1, true, []byte("online"))
+	require.True(t, token.WaitTimeout(5*time.Second))
+	require.NoError(t, token.Error())
+	msg, ok := broker.Retained("devices/1/status")
+	require.True(t, ok)
+	assert.Equal(t, []byte("online"), msg.Payload)
+
+	subscriber := connect(t, broker)
+	received := make(chan pahomqtt.Message, 1)
+	subscriber.Subscribe("devices/#", 1, func(_ pahomqtt.Client, msg pahomqtt.Message) {
+		received <- msg
+	})
+	select {
+	case msg := <-received:
+		assert.True(t, msg.Retained())
+		assert.Equal(t, []byte("online"), msg.Payload())
+	case <-time.After(5 * time.Second):
+		t.Fatal("retained message not received")
+	}
+}
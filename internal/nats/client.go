@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nats // import "github.com/open-telemetry/opentelemetry-collector-contrib/internal/nats"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	gonats "github.com/nats-io/nats.go"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.uber.org/zap"
+)
+
+// ClientConfig defines the connection to a NATS server.
+type ClientConfig struct {
+	// Endpoint is the URL of the NATS server, e.g. nats://localhost:4222. Several URLs can be separated by commas.
+	Endpoint string `mapstructure:"endpoint"`
+	// TLS configures the connection to the NATS server. TLS is disabled if not set.
+	TLS *configtls.ClientConfig `mapstructure:"tls"`
+	// Auth configures the authentication to the NATS server.
+	Auth AuthConfig `mapstructure:"auth"`
+}
+
+// AuthConfig defines the authentication to the NATS server. At most one method can be set.
+type AuthConfig struct {
+	// Token authenticates with a token.
+	Token configopaque.String `mapstructure:"token"`
+	// Username and Password authenticate with a username and a password.
+	Username string              `mapstructure:"username"`
+	Password configopaque.String `mapstructure:"password"`
+	// CredentialsFile is the path to a credentials file holding a user JWT and NKey seed.
+	CredentialsFile string `mapstructure:"credentials_file"`
+}
+
+// Validate checks the client configuration is valid.
+func (cfg *ClientConfig) Validate() error {
+	var errs []error
+	if cfg.Endpoint == "" {
+		errs = append(errs, errors.New("endpoint must be specified"))
+	}
+
+	methods := 0
+	for _, set := range []bool{cfg.Auth.Token != "", cfg.Auth.Username != "", cfg.Auth.CredentialsFile != ""} {
+		if set {
+			methods++
+		}
+	}
+	if methods > 1 {
+		errs = append(errs, errors.New("only one of auth::token, auth::username or auth::credentials_file can be specified"))
+	}
+	return errors.Join(errs...)
+}
+
+// Connect connects to the NATS server. The client reconnects indefinitely once connected.
+func Connect(ctx context.Context, cfg *ClientConfig, name string, logger *zap.Logger) (*gonats.Conn, error) {
+	opts := []gonats.Option{
+		gonats.Name(name),
+		gonats.MaxReconnects(-1),
+		gonats.DisconnectErrHandler(func(_ *gonats.Conn, err error) {
+			if err != nil {
+				logger.Warn("Disconnected from the NATS server", zap.Error(err))
+			}
+		}),
+		gonats.ReconnectHandler(func(nc *gonats.Conn) {
+			logger.Info("Reconnected to the NATS server", zap.String("url", nc.ConnectedUrl()))
+		}),
+	}
+
+	if cfg.TLS != nil {
+		tlsConfig, err := cfg.TLS.LoadTLSConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the TLS config: %w", err)
+		}
+		opts = append(opts, gonats.Secure(tlsConfig))
+	}
+
+	switch {
+	case cfg.Auth.Token != "":
+		opts = append(opts, gonats.Token(string(cfg.Auth.Token)))
+	case cfg.Auth.Username != "":
+		opts = append(opts, gonats.UserInfo(cfg.Auth.Username, string(cfg.Auth.Password)))
+	case cfg.Auth.CredentialsFile != "":
+		opts = append(opts, gonats.UserCredentials(cfg.Auth.CredentialsFile))
+	}
+
+	nc, err := gonats.Connect(cfg.Endpoint, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the NATS server: %w", err)
+	}
+	return nc, nil
+}
@@ -0,0 +1,57 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package nats
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.uber.org/zap"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      ClientConfig
+		expectedErr string
+	}{
+		{
+			name:   "valid",
+			config: ClientConfig{Endpoint: "nats://localhost:4222", Auth: AuthConfig{Username: "user", Password: "pass"}},
+		},
+		{
+			name:        "missing endpoint",
+			config:      ClientConfig{},
+			expectedErr: "endpoint must be specified",
+		},
+		{
+			name:        "several auth methods",
+			config:      ClientConfig{Endpoint: "nats://localhost:4222", Auth: AuthConfig{Token: "token", CredentialsFile: "user.creds"}},
+			expectedErr: "only one of auth::token, auth::username or auth::credentials_file can be specified",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestConnectErrors(t *testing.T) {
+	_, err := Connect(context.Background(), &ClientConfig{Endpoint: "nats://127.0.0.1:1"}, "test", zap.NewNop())
+	assert.ErrorContains(t, err, "failed to connect to the NATS server")
+
+	_, err = Connect(context.Background(), &ClientConfig{
+		Endpoint: "nats://127.0.0.1:1",
+		TLS:      &configtls.ClientConfig{Config: configtls.Config{CAFile: "missing.pem"}},
+	}, "test", zap.NewNop())
+	assert.ErrorContains(t, err, "failed to load the TLS config")
+}
@@ -639,6 +639,36 @@ func Test_e2e_converters(t *testing.T) {
 				m.AppendEmpty().SetStr("value2")
 			},
 		},
+		{
+			statement: `set(attributes["test"], ParseCEF("CEF:0|Vendor|Product|1.0|100|name|5|src=10.0.0.1 cs1Label=Rule cs1=allow all"))`,
+			want: func(tCtx ottllog.TransformContext) {
+				m := tCtx.GetLogRecord().Attributes().PutEmptyMap("test")
+				m.PutStr("version", "0")
+				m.PutStr("device_vendor", "Vendor")
+				m.PutStr("device_product", "Product")
+				m.PutStr("device_version", "1.0")
+				m.PutStr("device_event_class_id", "100")
+				m.PutStr("name", "name")
+				m.PutStr("severity", "5")
+				ext := m.PutEmptyMap("extensions")
+				ext.PutStr("src", "10.0.0.1")
+				ext.PutStr("Rule", "allow all")
+			},
+		},
+		{
+			statement: `set(attributes["test"], ParseLEEF("LEEF:2.0|Vendor|Product|1.0|41|^|src=10.0.0.1^usrName=alice"))`,
+			want: func(tCtx ottllog.TransformContext) {
+				m := tCtx.GetLogRecord().Attributes().PutEmptyMap("test")
+				m.PutStr("version", "2.0")
+				m.PutStr("vendor", "Vendor")
+				m.PutStr("product", "Product")
+				m.PutStr("product_version", "1.0")
+				m.PutStr("event_id", "41")
+				attrs := m.PutEmptyMap("attributes")
+				attrs.PutStr("src", "10.0.0.1")
+				attrs.PutStr("usrName", "alice")
+			},
+		},
 		{
 			statement: `set(attributes["test"], ParseKeyValue("k1=v1 k2=v2"))`,
 			want: func(tCtx ottllog.TransformContext) {
@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package ottlfuncs // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/ottlfuncs"
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
+)
+
+type AuthAttributeArguments struct {
+	Name string
+}
+
+func NewAuthAttributeFactory[K any]() ottl.Factory[K] {
+	return ottl.NewFactory("AuthAttribute", &AuthAttributeArguments{}, createAuthAttributeFunction[K])
+}
+
+func createAuthAttributeFunction[K any](_ ottl.FunctionContext, oArgs ottl.Arguments) (ottl.ExprFunc[K], error) {
+	args, ok := oArgs.(*AuthAttributeArguments)
+
+	if !ok {
+		return nil, fmt.Errorf("AuthAttributeFactory args must be of type *AuthAttributeArguments")
+	}
+
+	return authAttribute[K](args.Name), nil
+}
+
+func authAttribute[K any](name string) ottl.ExprFunc[K] {
+	return func(ctx context.Context, _ K) (any, error) {
+		authData := client.FromContext(ctx).Auth
+		if authData == nil {
+			return nil, nil
+		}
+		switch attr := authData.GetAttribute(name).(type) {
+		case string:
+			return attr, nil
+		case []string:
+			values := pcommon.NewSlice()
+			values.EnsureCapacity(len(attr))
+			for _, value := range attr {
+				values.AppendEmpty().SetStr(value)
+			}
+			return values, nil
+		default:
+			return nil, nil
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package ottlfuncs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+)
+
+type testAuthData map[string]any
+
+func (a testAuthData) GetAttribute(name string) any {
+	return a[name]
+}
+
+func (a testAuthData) GetAttributeNames() []string {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	return names
+}
+
+func Test_AuthAttribute(t *testing.T) {
+	authData := testAuthData{
+		"spiffe_id": "spiffe://example.org/collector",
+		"dns_names": []string{"collector.example.org", "collector"},
+		"verified":  true,
+	}
+	ctx := client.NewContext(context.Background(), client.Info{Auth: authData})
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected any
+	}{
+		{
+			name:     "spiffe_id",
+			ctx:      ctx,
+			expected: "spiffe://example.org/collector",
+		},
+		{
+			name:     "dns_names",
+			ctx:      ctx,
+			expected: []any{"collector.example.org", "collector"},
+		},
+		{
+			name: "verified",
+			ctx:  ctx,
+		},
+		{
+			name: "missing",
+			ctx:  ctx,
+		},
+		{
+			name: "spiffe_id",
+			ctx:  context.Background(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exprFunc := authAttribute[any](tt.name)
+			result, err := exprFunc(tt.ctx, nil)
+			require.NoError(t, err)
+			if values, ok := result.(interface{ AsRaw() []any }); ok {
+				result = values.AsRaw()
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package ottlfuncs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
+)
+
+func Test_ParseCEF(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		expected map[string]any
+	}{
+		{
+			name:   "simple",
+			target: `CEF:0|Security|threatmanager|1.0|100|worm stopped|10|src=10.0.0.1 dst=2.1.2.2`,
+			expected: map[string]any{
+				"version":               "0",
+				"device_vendor":         "Security",
+				"device_product":        "threatmanager",
+				"device_version":        "1.0",
+				"device_event_class_id": "100",
+				"name":                  "worm stopped",
+				"severity":              "10",
+				"extensions": map[string]any{
+					"src": "10.0.0.1",
+					"dst": "2.1.2.2",
+				},
+			},
+		},
+		{
+			name:   "custom labels and escaping",
+			target: `<134>fw01 CEF:0|V|P\|X|1|id|n|5|cs1Label=Rule Name cs1=Block all msg=a\=b`,
+			expected: map[string]any{
+				"version":               "0",
+				"device_vendor":         "V",
+				"device_product":        "P|X",
+				"device_version":        "1",
+				"device_event_class_id": "id",
+				"name":                  "n",
+				"severity":              "5",
+				"extensions": map[string]any{
+					"Rule Name": "Block all",
+					"msg":       "a=b",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := ottl.StandardStringGetter[any]{
+				Getter: func(_ context.Context, _ any) (any, error) {
+					return tt.target, nil
+				},
+			}
+			exprFunc := parseCEF[any](target)
+			result, err := exprFunc(context.Background(), nil)
+			require.NoError(t, err)
+
+			resultMap, ok := result.(pcommon.Map)
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, resultMap.AsRaw())
+		})
+	}
+}
+
+func Test_ParseCEF_error(t *testing.T) {
+	target := ottl.StandardStringGetter[any]{
+		Getter: func(_ context.Context, _ any) (any, error) {
+			return "CEF:0|V|P", nil
+		},
+	}
+	exprFunc := parseCEF[any](target)
+	_, err := exprFunc(context.Background(), nil)
+	assert.ErrorContains(t, err, "invalid CEF header")
+}
@@ -0,0 +1,556 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package helper // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/helper"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/timeutils"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/errors"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/helper/internal/metadata"
+)
+
+// Types of parsed fields.
+const (
+	StringType    = "string"
+	IntType       = "int"
+	FloatType     = "float"
+	BoolType      = "bool"
+	TimestampType = "timestamp"
+	DurationType  = "duration"
+	BytesType     = "bytes"
+)
+
+// Behaviors when a parsed field cannot be converted to its type.
+const (
+	KeepOnConflict   = "keep"
+	RemoveOnConflict = "remove"
+	ErrorOnConflict  = "error"
+)
+
+// maxInferredFields limits the number of fields for which types are inferred,
+// so that parsing arbitrary keys does not grow the learned schema without bound.
+const maxInferredFields = 1000
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+var byteUnits = map[string]float64{
+	"b":   1,
+	"kb":  1000,
+	"kib": 1024,
+	"mb":  1000 * 1000,
+	"mib": 1024 * 1024,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1024 * 1024 * 1024,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"tib": 1024 * 1024 * 1024 * 1024,
+}
+
+// SchemaConfig is the configuration of the types of the fields produced by a parser.
+type SchemaConfig struct {
+	Fields     map[string]FieldType `mapstructure:"fields"`
+	Infer      bool                 `mapstructure:"infer"`
+	OnConflict string               `mapstructure:"on_conflict"`
+}
+
+// FieldType is the type of a parsed field. It can be configured as just the
+// name of the type.
+type FieldType struct {
+	Type       string `mapstructure:"type"`
+	Unit       string `mapstructure:"unit"`
+	Layout     string `mapstructure:"layout"`
+	LayoutType string `mapstructure:"layout_type"`
+}
+
+// UnmarshalText sets the type from its name.
+func (f *FieldType) UnmarshalText(text []byte) error {
+	f.Type = string(text)
+	return nil
+}
+
+// Build will build a schema. A nil config builds a nil schema, which leaves
+// parsed values unchanged.
+func (c *SchemaConfig) Build(set component.TelemetrySettings, operatorID string) (*Schema, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	onConflict := c.OnConflict
+	switch onConflict {
+	case "":
+		onConflict = KeepOnConflict
+	case KeepOnConflict, RemoveOnConflict, ErrorOnConflict:
+	default:
+		return nil, errors.NewError(
+			fmt.Sprintf("invalid `on_conflict` value '%s'", c.OnConflict),
+			"specify 'keep', 'remove' or 'error'",
+		)
+	}
+
+	s := &Schema{
+		logger:     set.Logger.With(zap.String("operator_id", operatorID)),
+		onConflict: onConflict,
+		infer:      c.Infer,
+		learned:    map[string]string{},
+		reported:   map[string]struct{}{},
+		attrs:      metric.WithAttributeSet(attribute.NewSet(attribute.String("operator_id", operatorID))),
+	}
+
+	paths := make([]string, 0, len(c.Fields))
+	for path := range c.Fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		coercer, err := c.Fields[path].build()
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("schema field '%s'", path))
+		}
+		s.fields = append(s.fields, schemaField{path: path, keys: strings.Split(path, "."), coercer: coercer})
+	}
+
+	telemetryBuilder, err := metadata.NewTelemetryBuilder(set)
+	if err != nil {
+		return nil, err
+	}
+	s.telemetryBuilder = telemetryBuilder
+
+	return s, nil
+}
+
+func (f FieldType) build() (coercer, error) {
+	switch f.Type {
+	case StringType:
+		return coerceString, nil
+	case IntType:
+		return coerceInt, nil
+	case FloatType:
+		return coerceFloat, nil
+	case BoolType:
+		return coerceBool, nil
+	case DurationType:
+		unit := f.Unit
+		if unit == "" {
+			unit = "ms"
+		}
+		d, ok := durationUnits[unit]
+		if !ok {
+			return nil, errors.NewError(fmt.Sprintf("invalid duration unit '%s'", f.Unit), "specify 'ns', 'us', 'ms', 's', 'm' or 'h'")
+		}
+		return durationCoercer(d), nil
+	case BytesType:
+		unit := strings.ToLower(f.Unit)
+		if unit == "" {
+			unit = "b"
+		}
+		multiplier, ok := byteUnits[unit]
+		if !ok {
+			return nil, errors.NewError(fmt.Sprintf("invalid bytes unit '%s'", f.Unit), "specify 'B', 'KB', 'KiB', 'MB', 'MiB', 'GB', 'GiB', 'TB' or 'TiB'")
+		}
+		return bytesCoercer(multiplier), nil
+	case TimestampType:
+		return f.buildTimestamp()
+	default:
+		return nil, errors.NewError(
+			fmt.Sprintf("invalid type '%s'", f.Type),
+			"specify 'string', 'int', 'float', 'bool', 'timestamp', 'duration' or 'bytes'",
+		)
+	}
+}
+
+func (f FieldType) buildTimestamp() (coercer, error) {
+	unit := f.Unit
+	if unit == "" {
+		unit = "s"
+	}
+	toTimeFn, ok := toTime[unit]
+	if !ok {
+		return nil, errors.NewError(fmt.Sprintf("invalid timestamp unit '%s'", f.Unit), "specify 's', 'ms', 'us' or 'ns'")
+	}
+
+	layout := time.RFC3339Nano
+	if f.Layout != "" {
+		layout = f.Layout
+		switch f.LayoutType {
+		case "", StrptimeKey:
+			var err error
+			if layout, err = timeutils.StrptimeToGotime(f.Layout); err != nil {
+				return nil, errors.Wrap(err, "parse strptime layout")
+			}
+		case GotimeKey:
+			if err := timeutils.ValidateGotime(layout); err != nil {
+				return nil, errors.Wrap(err, "invalid gotime layout")
+			}
+		default:
+			return nil, errors.NewError(fmt.Sprintf("unsupported layout_type %s", f.LayoutType), "valid values are 'strptime' and 'gotime'")
+		}
+	}
+
+	return func(value any) (any, error) {
+		var t time.Time
+		if s, ok := value.(string); ok {
+			parsed, err := time.Parse(layout, s)
+			if err != nil {
+				epoch, numErr := strconv.ParseInt(s, 10, 64)
+				if numErr != nil {
+					return nil, err
+				}
+				parsed = toTimeFn(epoch)
+			}
+			t = parsed
+		} else {
+			f, ok := toFloat64(value)
+			if !ok {
+				return nil, fmt.Errorf("type %T cannot be converted to a timestamp", value)
+			}
+			t = toTimeFn(int64(f))
+		}
+		return t.UTC().Format(time.RFC3339Nano), nil
+	}, nil
+}
+
+// Schema converts the fields of parsed values to their configured types, and
+// optionally to the types inferred from the first values of other fields.
+type Schema struct {
+	fields     []schemaField
+	infer      bool
+	onConflict string
+	logger     *zap.Logger
+	attrs      metric.MeasurementOption
+
+	telemetryBuilder *metadata.TelemetryBuilder
+
+	mu       sync.Mutex
+	learned  map[string]string
+	reported map[string]struct{}
+}
+
+type schemaField struct {
+	path    string
+	keys    []string
+	coercer coercer
+}
+
+type coercer func(any) (any, error)
+
+// Apply converts the fields of a parsed value in place and returns it.
+func (s *Schema) Apply(value any) (any, error) {
+	if s == nil {
+		return value, nil
+	}
+	parsed, ok := value.(map[string]any)
+	if !ok {
+		return value, nil
+	}
+
+	var configured map[string]struct{}
+	for _, field := range s.fields {
+		parent, ok := lookupParent(parsed, field.keys)
+		if !ok {
+			continue
+		}
+		key := field.keys[len(field.keys)-1]
+		v, ok := parent[key]
+		if !ok {
+			continue
+		}
+		if configured == nil {
+			configured = make(map[string]struct{}, len(s.fields))
+		}
+		configured[field.path] = struct{}{}
+		if err := s.coerce(parent, key, field.path, v, field.coercer); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.infer {
+		return parsed, s.inferMap(parsed, "", configured)
+	}
+	return parsed, nil
+}
+
+func (s *Schema) inferMap(m map[string]any, prefix string, configured map[string]struct{}) error {
+	for key, v := range m {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if _, ok := configured[path]; ok {
+			continue
+		}
+		if nested, ok := v.(map[string]any); ok {
+			if err := s.inferMap(nested, path, configured); err != nil {
+				return err
+			}
+			continue
+		}
+
+		typ := typeOf(v)
+		if typ == "" {
+			continue
+		}
+		s.mu.Lock()
+		learned, ok := s.learned[path]
+		if !ok && len(s.learned) < maxInferredFields {
+			s.learned[path] = typ
+		}
+		s.mu.Unlock()
+		if !ok || learned == typ {
+			continue
+		}
+		if err := s.coerce(m, key, path, v, basicCoercers[learned]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// coerce replaces the value of a key by its converted value, and handles the
+// value according to on_conflict if it cannot be converted.
+func (s *Schema) coerce(m map[string]any, key, path string, value any, c coercer) error {
+	converted, err := c(value)
+	if err == nil {
+		m[key] = converted
+		return nil
+	}
+
+	s.telemetryBuilder.StanzaParserTypeConflicts.Add(context.Background(), 1, s.attrs)
+	s.mu.Lock()
+	_, reported := s.reported[path]
+	if !reported && len(s.reported) < maxInferredFields {
+		s.reported[path] = struct{}{}
+	}
+	s.mu.Unlock()
+	if !reported {
+		s.logger.Warn("Parsed field does not match its type", zap.String("field", path), zap.Error(err))
+	}
+
+	switch s.onConflict {
+	case RemoveOnConflict:
+		delete(m, key)
+	case ErrorOnConflict:
+		return fmt.Errorf("field '%s': %w", path, err)
+	}
+	return nil
+}
+
+func lookupParent(m map[string]any, keys []string) (map[string]any, bool) {
+	for _, key := range keys[:len(keys)-1] {
+		nested, ok := m[key].(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		m = nested
+	}
+	return m, true
+}
+
+var basicCoercers = map[string]coercer{
+	StringType: coerceString,
+	IntType:    coerceInt,
+	FloatType:  coerceFloat,
+	BoolType:   coerceBool,
+}
+
+// typeOf returns the basic type of a parsed value, or an empty string for
+// values that are not inferred, such as arrays.
+func typeOf(value any) string {
+	switch value.(type) {
+	case string:
+		return StringType
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return IntType
+	case float32, float64:
+		return FloatType
+	case bool:
+		return BoolType
+	default:
+		return ""
+	}
+}
+
+func toFloat64(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func coerceString(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case map[string]any, []any:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	}
+	if typeOf(value) == IntType {
+		return fmt.Sprintf("%d", value), nil
+	}
+	return nil, fmt.Errorf("type %T cannot be converted to a string", value)
+}
+
+func coerceInt(value any) (any, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case string:
+		s := strings.TrimSpace(v)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' cannot be converted to an int", v)
+		}
+		value = f
+	case uint64:
+		if v > math.MaxInt64 {
+			return nil, fmt.Errorf("%d overflows an int", v)
+		}
+		return int64(v), nil
+	}
+	f, ok := toFloat64(value)
+	if !ok {
+		return nil, fmt.Errorf("type %T cannot be converted to an int", value)
+	}
+	if f != math.Trunc(f) || f > math.MaxInt64 || f < math.MinInt64 {
+		return nil, fmt.Errorf("%v cannot be converted to an int", f)
+	}
+	return int64(f), nil
+}
+
+func coerceFloat(value any) (any, error) {
+	if s, ok := value.(string); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' cannot be converted to a float", s)
+		}
+		return f, nil
+	}
+	f, ok := toFloat64(value)
+	if !ok {
+		return nil, fmt.Errorf("type %T cannot be converted to a float", value)
+	}
+	return f, nil
+}
+
+func coerceBool(value any) (any, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("'%s' cannot be converted to a bool", v)
+		}
+		return b, nil
+	}
+	f, ok := toFloat64(value)
+	if !ok || (f != 0 && f != 1) {
+		return nil, fmt.Errorf("%v cannot be converted to a bool", value)
+	}
+	return f == 1, nil
+}
+
+// durationCoercer converts durations to an int in the given unit. Numbers and
+// strings without a unit are taken to be in the given unit already.
+func durationCoercer(unit time.Duration) coercer {
+	return func(value any) (any, error) {
+		if s, ok := value.(string); ok {
+			s = strings.TrimSpace(s)
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return int64(f), nil
+			}
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return nil, fmt.Errorf("'%s' cannot be converted to a duration", s)
+			}
+			return int64(d / unit), nil
+		}
+		f, ok := toFloat64(value)
+		if !ok {
+			return nil, fmt.Errorf("type %T cannot be converted to a duration", value)
+		}
+		return int64(f), nil
+	}
+}
+
+// bytesCoercer converts sizes to an int in the given unit. Numbers and strings
+// without a unit are taken to be in the given unit already.
+func bytesCoercer(multiplier float64) coercer {
+	return func(value any) (any, error) {
+		if s, ok := value.(string); ok {
+			s = strings.TrimSpace(s)
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return int64(f), nil
+			}
+			var size ByteSize
+			if err := size.UnmarshalText([]byte(s)); err != nil {
+				return nil, fmt.Errorf("'%s' cannot be converted to bytes", s)
+			}
+			return int64(float64(size) / multiplier), nil
+		}
+		f, ok := toFloat64(value)
+		if !ok {
+			return nil, fmt.Errorf("type %T cannot be converted to bytes", value)
+		}
+		return int64(f), nil
+	}
+}
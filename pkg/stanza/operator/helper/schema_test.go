@@ -0,0 +1,239 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package helper
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestSchemaConfigUnmarshal(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "schema.yaml"))
+	require.NoError(t, err)
+
+	var cfg SchemaConfig
+	require.NoError(t, cm.Unmarshal(&cfg))
+	assert.Equal(t, SchemaConfig{
+		Fields: map[string]FieldType{
+			"status":        {Type: IntType},
+			"latency":       {Type: DurationType, Unit: "ms"},
+			"response.size": {Type: BytesType, Unit: "KiB"},
+			"time":          {Type: TimestampType, Layout: "%Y-%m-%d %H:%M:%S"},
+		},
+		Infer:      true,
+		OnConflict: RemoveOnConflict,
+	}, cfg)
+}
+
+func TestSchemaBuildErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		cfg  SchemaConfig
+		err  string
+	}{
+		{
+			name: "type",
+			cfg:  SchemaConfig{Fields: map[string]FieldType{"a": {Type: "number"}}},
+			err:  "invalid type 'number'",
+		},
+		{
+			name: "duration unit",
+			cfg:  SchemaConfig{Fields: map[string]FieldType{"a": {Type: DurationType, Unit: "days"}}},
+			err:  "invalid duration unit 'days'",
+		},
+		{
+			name: "bytes unit",
+			cfg:  SchemaConfig{Fields: map[string]FieldType{"a": {Type: BytesType, Unit: "bits"}}},
+			err:  "invalid bytes unit 'bits'",
+		},
+		{
+			name: "timestamp layout type",
+			cfg:  SchemaConfig{Fields: map[string]FieldType{"a": {Type: TimestampType, Layout: "x", LayoutType: "epoch"}}},
+			err:  "unsupported layout_type epoch",
+		},
+		{
+			name: "on_conflict",
+			cfg:  SchemaConfig{OnConflict: "ignore"},
+			err:  "invalid `on_conflict` value 'ignore'",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.cfg.Build(componenttest.NewNopTelemetrySettings(), "test")
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestNilSchema(t *testing.T) {
+	var cfg *SchemaConfig
+	schema, err := cfg.Build(componenttest.NewNopTelemetrySettings(), "test")
+	require.NoError(t, err)
+	value := map[string]any{"a": "1"}
+	result, err := schema.Apply(value)
+	require.NoError(t, err)
+	assert.Equal(t, value, result)
+}
+
+func TestSchemaCoercion(t *testing.T) {
+	cfg := SchemaConfig{
+		Fields: map[string]FieldType{
+			"str":          {Type: StringType},
+			"int":          {Type: IntType},
+			"float":        {Type: FloatType},
+			"bool":         {Type: BoolType},
+			"latency":      {Type: DurationType},
+			"latency_s":    {Type: DurationType, Unit: "s"},
+			"size":         {Type: BytesType},
+			"size_kib":     {Type: BytesType, Unit: "KiB"},
+			"epoch":        {Type: TimestampType, Unit: "ms"},
+			"time":         {Type: TimestampType, Layout: "%Y-%m-%d %H:%M:%S"},
+			"rfc3339":      {Type: TimestampType},
+			"http.status":  {Type: IntType},
+			"missing.path": {Type: IntType},
+		},
+	}
+	schema, err := cfg.Build(componenttest.NewNopTelemetrySettings(), "test")
+	require.NoError(t, err)
+
+	result, err := schema.Apply(map[string]any{
+		"str":       map[string]any{"a": 1.0},
+		"int":       " 42 ",
+		"float":     int64(3),
+		"bool":      "true",
+		"latency":   "1.5s",
+		"latency_s": 90.0,
+		"size":      "2KiB",
+		"size_kib":  "3MiB",
+		"epoch":     1700000000123.0,
+		"time":      "2024-01-02 03:04:05",
+		"rfc3339":   "2024-01-02T03:04:05+02:00",
+		"http":      map[string]any{"status": 200.0},
+		"missing":   "path",
+		"other":     "untouched",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"str":       `{"a":1}`,
+		"int":       int64(42),
+		"float":     3.0,
+		"bool":      true,
+		"latency":   int64(1500),
+		"latency_s": int64(90),
+		"size":      int64(2048),
+		"size_kib":  int64(3072),
+		"epoch":     "2023-11-14T22:13:20.123Z",
+		"time":      "2024-01-02T03:04:05Z",
+		"rfc3339":   "2024-01-02T01:04:05Z",
+		"http":      map[string]any{"status": int64(200)},
+		"missing":   "path",
+		"other":     "untouched",
+	}, result)
+}
+
+func TestSchemaConflicts(t *testing.T) {
+	newValue := func() map[string]any {
+		return map[string]any{"status": "OK", "latency": "12"}
+	}
+	fields := map[string]FieldType{"status": {Type: IntType}, "latency": {Type: IntType}}
+
+	testCases := []struct {
+		onConflict string
+		expected   map[string]any
+		err        string
+	}{
+		{onConflict: "", expected: map[string]any{"status": "OK", "latency": int64(12)}},
+		{onConflict: RemoveOnConflict, expected: map[string]any{"latency": int64(12)}},
+		{onConflict: ErrorOnConflict, err: "field 'status': 'OK' cannot be converted to an int"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.onConflict, func(t *testing.T) {
+			tt := setupTestTelemetry()
+			schema, err := (&SchemaConfig{Fields: fields, OnConflict: tc.onConflict}).Build(tt.newTelemetrySettings(), "test")
+			require.NoError(t, err)
+			result, err := schema.Apply(newValue())
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, result)
+			}
+			assert.Equal(t, int64(1), conflictCount(t, tt.reader))
+		})
+	}
+}
+
+func TestSchemaInfer(t *testing.T) {
+	tt := setupTestTelemetry()
+	cfg := SchemaConfig{
+		Fields: map[string]FieldType{"id": {Type: StringType}},
+		Infer:  true,
+	}
+	schema, err := cfg.Build(tt.newTelemetrySettings(), "test")
+	require.NoError(t, err)
+
+	// The types are learned from the first values.
+	result, err := schema.Apply(map[string]any{
+		"id":       int64(1),
+		"status":   int64(200),
+		"ok":       true,
+		"user":     map[string]any{"name": "alice"},
+		"tags":     []any{"a"},
+		"duration": 1.5,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":       "1",
+		"status":   int64(200),
+		"ok":       true,
+		"user":     map[string]any{"name": "alice"},
+		"tags":     []any{"a"},
+		"duration": 1.5,
+	}, result)
+	assert.Equal(t, int64(0), conflictCount(t, tt.reader))
+
+	// Later values are converted to the learned types, if possible.
+	result, err = schema.Apply(map[string]any{
+		"id":       "2",
+		"status":   "404",
+		"ok":       "yes",
+		"user":     map[string]any{"name": int64(7)},
+		"duration": int64(2),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":       "2",
+		"status":   int64(404),
+		"ok":       "yes",
+		"user":     map[string]any{"name": "7"},
+		"duration": 2.0,
+	}, result)
+	assert.Equal(t, int64(1), conflictCount(t, tt.reader))
+}
+
+func conflictCount(t *testing.T, reader *sdkmetric.ManualReader) int64 {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "otelcol_stanza_parser_type_conflicts" {
+				continue
+			}
+			var count int64
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				count += dp.Value
+			}
+			return count
+		}
+	}
+	return 0
+}
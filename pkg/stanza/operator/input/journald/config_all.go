@@ -9,6 +9,13 @@ import (
 
 const operatorType = "journald_input"
 
+const (
+	// readerJournalctl reads entries by following the output of the journalctl binary.
+	readerJournalctl = "journalctl"
+	// readerNative reads entries directly from the journal files.
+	readerNative = "native"
+)
+
 // NewConfig creates a new input config with default values
 func NewConfig() *Config {
 	return NewConfigWithID(operatorType)
@@ -20,6 +27,7 @@ func NewConfigWithID(operatorID string) *Config {
 		InputConfig: helper.NewInputConfig(operatorID, operatorType),
 		StartAt:     "end",
 		Priority:    "info",
+		Reader:      readerJournalctl,
 	}
 }
 
@@ -39,6 +47,7 @@ type Config struct {
 	All                 bool          `mapstructure:"all,omitempty"`
 	Namespace           string        `mapstructure:"namespace,omitempty"`
 	ConvertMessageBytes bool          `mapstructure:"convert_message_bytes,omitempty"`
+	Reader              string        `mapstructure:"reader,omitempty"`
 }
 
 type MatchConfig map[string]string
@@ -0,0 +1,487 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package journalfile reads entries from systemd journal files without relying on
+// libsystemd or journalctl. The on-disk format is described at
+// https://systemd.io/JOURNAL_FILE_FORMAT/.
+package journalfile // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/journald/internal/journalfile"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// Signature is the magic value at the start of every journal file.
+var Signature = [8]byte{'L', 'P', 'K', 'S', 'H', 'H', 'R', 'H'}
+
+// Incompatible header flags.
+const (
+	IncompatibleCompressedXZ   uint32 = 1 << 0
+	IncompatibleCompressedLZ4  uint32 = 1 << 1
+	IncompatibleKeyedHash      uint32 = 1 << 2
+	IncompatibleCompressedZSTD uint32 = 1 << 3
+	IncompatibleCompact        uint32 = 1 << 4
+
+	supportedIncompatible = IncompatibleCompressedXZ | IncompatibleCompressedLZ4 |
+		IncompatibleKeyedHash | IncompatibleCompressedZSTD | IncompatibleCompact
+)
+
+// File states.
+const (
+	StateOffline  uint8 = 0
+	StateOnline   uint8 = 1
+	StateArchived uint8 = 2
+)
+
+// Object types.
+const (
+	ObjectData       uint8 = 1
+	ObjectField      uint8 = 2
+	ObjectEntry      uint8 = 3
+	ObjectEntryArray uint8 = 6
+)
+
+// Object flags describing the compression of a data object payload.
+const (
+	ObjectCompressedXZ   uint8 = 1 << 0
+	ObjectCompressedLZ4  uint8 = 1 << 1
+	ObjectCompressedZSTD uint8 = 1 << 2
+)
+
+const (
+	// HeaderMinSize is the size of the header fields used by this package.
+	HeaderMinSize = 208
+
+	objectHeaderSize     = 16
+	dataPayloadOffset    = 64
+	dataPayloadCompact   = 72
+	entryItemsOffset     = 64
+	entryArrayItemOffset = 24
+
+	// maxObjectSize guards against corrupted size fields causing huge allocations.
+	maxObjectSize = 256 << 20
+)
+
+var (
+	errNotJournal  = errors.New("not a journal file")
+	errUnsupported = errors.New("unsupported journal file")
+)
+
+// ID is a 128-bit identifier as used for file, machine, boot and sequence number IDs.
+type ID [16]byte
+
+// String returns the ID in the lower-case hex form used by journalctl.
+func (id ID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// Header holds the fields of the journal file header used for reading entries.
+type Header struct {
+	CompatibleFlags   uint32
+	IncompatibleFlags uint32
+	State             uint8
+	FileID            ID
+	MachineID         ID
+	TailEntryBootID   ID
+	SeqnumID          ID
+	HeaderSize        uint64
+	ArenaSize         uint64
+	TailObjectOffset  uint64
+	NObjects          uint64
+	NEntries          uint64
+	TailEntrySeqnum   uint64
+	HeadEntrySeqnum   uint64
+	EntryArrayOffset  uint64
+	HeadEntryRealtime uint64
+	TailEntryRealtime uint64
+}
+
+// Compact reports whether the file uses the compact format with 32-bit offsets.
+func (h Header) Compact() bool {
+	return h.IncompatibleFlags&IncompatibleCompact != 0
+}
+
+// ParseHeader decodes a journal file header.
+func ParseHeader(buf []byte) (Header, error) {
+	if len(buf) < HeaderMinSize {
+		return Header{}, fmt.Errorf("%w: header too short", errNotJournal)
+	}
+	if !bytes.Equal(buf[0:8], Signature[:]) {
+		return Header{}, fmt.Errorf("%w: invalid signature", errNotJournal)
+	}
+	le := binary.LittleEndian
+	h := Header{
+		CompatibleFlags:   le.Uint32(buf[8:]),
+		IncompatibleFlags: le.Uint32(buf[12:]),
+		State:             buf[16],
+		HeaderSize:        le.Uint64(buf[88:]),
+		ArenaSize:         le.Uint64(buf[96:]),
+		TailObjectOffset:  le.Uint64(buf[136:]),
+		NObjects:          le.Uint64(buf[144:]),
+		NEntries:          le.Uint64(buf[152:]),
+		TailEntrySeqnum:   le.Uint64(buf[160:]),
+		HeadEntrySeqnum:   le.Uint64(buf[168:]),
+		EntryArrayOffset:  le.Uint64(buf[176:]),
+		HeadEntryRealtime: le.Uint64(buf[184:]),
+		TailEntryRealtime: le.Uint64(buf[192:]),
+	}
+	copy(h.FileID[:], buf[24:40])
+	copy(h.MachineID[:], buf[40:56])
+	copy(h.TailEntryBootID[:], buf[56:72])
+	copy(h.SeqnumID[:], buf[72:88])
+
+	if unknown := h.IncompatibleFlags &^ supportedIncompatible; unknown != 0 {
+		return Header{}, fmt.Errorf("%w: unknown incompatible flags 0x%x", errUnsupported, unknown)
+	}
+	if h.HeaderSize < HeaderMinSize {
+		return Header{}, fmt.Errorf("%w: header size %d is too small", errNotJournal, h.HeaderSize)
+	}
+	return h, nil
+}
+
+// Field is a single FIELD=value pair of an entry.
+type Field struct {
+	Name  string
+	Value []byte
+}
+
+// Entry is a journal entry with its metadata and fields.
+type Entry struct {
+	Seqnum    uint64
+	Realtime  uint64
+	Monotonic uint64
+	BootID    ID
+	XorHash   uint64
+	Fields    []Field
+}
+
+// File is an open journal file.
+type File struct {
+	f      *os.File
+	header Header
+	zstd   *zstd.Decoder
+}
+
+// Open opens the journal file at path and reads its header.
+func Open(path string) (*File, error) {
+	f, err := os.Open(path) // #nosec G304 - journal files are configured by the user
+	if err != nil {
+		return nil, err
+	}
+	jf := &File{f: f}
+	if err = jf.Refresh(); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return jf, nil
+}
+
+// Header returns the last header read from the file.
+func (jf *File) Header() Header {
+	return jf.header
+}
+
+// Stat returns the file info of the underlying file.
+func (jf *File) Stat() (os.FileInfo, error) {
+	return jf.f.Stat()
+}
+
+// Refresh re-reads the header, picking up entries appended since the last call.
+func (jf *File) Refresh() error {
+	buf := make([]byte, HeaderMinSize)
+	if _, err := jf.f.ReadAt(buf, 0); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: header too short", errNotJournal)
+		}
+		return err
+	}
+	h, err := ParseHeader(buf)
+	if err != nil {
+		return err
+	}
+	jf.header = h
+	return nil
+}
+
+// Close closes the underlying file.
+func (jf *File) Close() error {
+	if jf.zstd != nil {
+		jf.zstd.Close()
+	}
+	return jf.f.Close()
+}
+
+type objectHeader struct {
+	typ   uint8
+	flags uint8
+	size  uint64
+}
+
+func (jf *File) readObjectHeader(offset uint64) (objectHeader, error) {
+	if offset == 0 || offset%8 != 0 {
+		return objectHeader{}, fmt.Errorf("invalid object offset %d", offset)
+	}
+	var buf [objectHeaderSize]byte
+	if _, err := jf.f.ReadAt(buf[:], int64(offset)); err != nil {
+		return objectHeader{}, fmt.Errorf("read object header at %d: %w", offset, err)
+	}
+	oh := objectHeader{typ: buf[0], flags: buf[1], size: binary.LittleEndian.Uint64(buf[8:])}
+	if oh.size < objectHeaderSize || oh.size > maxObjectSize {
+		return objectHeader{}, fmt.Errorf("invalid object size %d at offset %d", oh.size, offset)
+	}
+	return oh, nil
+}
+
+func (jf *File) readObject(offset uint64, typ uint8) (objectHeader, []byte, error) {
+	oh, err := jf.readObjectHeader(offset)
+	if err != nil {
+		return objectHeader{}, nil, err
+	}
+	if oh.typ != typ {
+		return objectHeader{}, nil, fmt.Errorf("object at offset %d has type %d, expected %d", offset, oh.typ, typ)
+	}
+	buf := make([]byte, oh.size)
+	if _, err := jf.f.ReadAt(buf, int64(offset)); err != nil {
+		return objectHeader{}, nil, fmt.Errorf("read object at %d: %w", offset, err)
+	}
+	return oh, buf, nil
+}
+
+// ReadEntry reads the entry object at offset together with all of its data objects.
+func (jf *File) ReadEntry(offset uint64) (*Entry, error) {
+	_, buf, err := jf.readObject(offset, ObjectEntry)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < entryItemsOffset {
+		return nil, fmt.Errorf("entry object at offset %d is too short", offset)
+	}
+	le := binary.LittleEndian
+	e := &Entry{
+		Seqnum:    le.Uint64(buf[16:]),
+		Realtime:  le.Uint64(buf[24:]),
+		Monotonic: le.Uint64(buf[32:]),
+		XorHash:   le.Uint64(buf[56:]),
+	}
+	copy(e.BootID[:], buf[40:56])
+
+	items := buf[entryItemsOffset:]
+	itemSize := 16
+	if jf.header.Compact() {
+		itemSize = 4
+	}
+	e.Fields = make([]Field, 0, len(items)/itemSize)
+	for i := 0; i+itemSize <= len(items); i += itemSize {
+		var dataOffset uint64
+		if itemSize == 4 {
+			dataOffset = uint64(le.Uint32(items[i:]))
+		} else {
+			dataOffset = le.Uint64(items[i:])
+		}
+		field, err := jf.readData(dataOffset)
+		if err != nil {
+			return nil, err
+		}
+		e.Fields = append(e.Fields, field)
+	}
+	return e, nil
+}
+
+func (jf *File) readData(offset uint64) (Field, error) {
+	oh, buf, err := jf.readObject(offset, ObjectData)
+	if err != nil {
+		return Field{}, err
+	}
+	payloadOffset := dataPayloadOffset
+	if jf.header.Compact() {
+		payloadOffset = dataPayloadCompact
+	}
+	if len(buf) < payloadOffset {
+		return Field{}, fmt.Errorf("data object at offset %d is too short", offset)
+	}
+	payload, err := jf.decompress(oh.flags, buf[payloadOffset:])
+	if err != nil {
+		return Field{}, fmt.Errorf("data object at offset %d: %w", offset, err)
+	}
+	name, value, ok := bytes.Cut(payload, []byte{'='})
+	if !ok {
+		return Field{}, fmt.Errorf("data object at offset %d is not a FIELD=value pair", offset)
+	}
+	return Field{Name: string(name), Value: value}, nil
+}
+
+func (jf *File) decompress(flags uint8, payload []byte) ([]byte, error) {
+	switch {
+	case flags&ObjectCompressedZSTD != 0:
+		if jf.zstd == nil {
+			d, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+			if err != nil {
+				return nil, err
+			}
+			jf.zstd = d
+		}
+		return jf.zstd.DecodeAll(payload, nil)
+	case flags&ObjectCompressedLZ4 != 0:
+		// systemd prefixes LZ4 blocks with the uncompressed size as a 64-bit integer.
+		if len(payload) < 8 {
+			return nil, errors.New("lz4 payload too short")
+		}
+		size := binary.LittleEndian.Uint64(payload)
+		if size > maxObjectSize {
+			return nil, fmt.Errorf("lz4 payload size %d too large", size)
+		}
+		out := make([]byte, size)
+		n, err := lz4.UncompressBlock(payload[8:], out)
+		if err != nil {
+			return nil, fmt.Errorf("lz4: %w", err)
+		}
+		return out[:n], nil
+	case flags&ObjectCompressedXZ != 0:
+		return nil, fmt.Errorf("%w: xz compressed data objects", errUnsupported)
+	default:
+		return payload, nil
+	}
+}
+
+// Iterator walks the entry array chain of a file in sequence number order.
+// The zero value starts at the first entry of the file.
+type Iterator struct {
+	arrayOffset uint64
+	arrayIndex  uint64
+	consumed    uint64
+}
+
+// Consumed returns how many entries the iterator has returned so far.
+func (it *Iterator) Consumed() uint64 {
+	return it.consumed
+}
+
+// Next returns the offset of the next entry object, or false if all entries
+// currently referenced by the header have been returned.
+func (jf *File) Next(it *Iterator) (uint64, bool, error) {
+	if it.consumed >= jf.header.NEntries {
+		return 0, false, nil
+	}
+	if it.arrayOffset == 0 {
+		if jf.header.EntryArrayOffset == 0 {
+			return 0, false, nil
+		}
+		it.arrayOffset = jf.header.EntryArrayOffset
+	}
+	itemSize := uint64(8)
+	if jf.header.Compact() {
+		itemSize = 4
+	}
+	le := binary.LittleEndian
+	for {
+		oh, err := jf.readObjectHeader(it.arrayOffset)
+		if err != nil {
+			return 0, false, err
+		}
+		if oh.typ != ObjectEntryArray {
+			return 0, false, fmt.Errorf("object at offset %d has type %d, expected entry array", it.arrayOffset, oh.typ)
+		}
+		if oh.size < entryArrayItemOffset {
+			return 0, false, fmt.Errorf("entry array at offset %d is too short", it.arrayOffset)
+		}
+		capacity := (oh.size - entryArrayItemOffset) / itemSize
+		if it.arrayIndex < capacity {
+			buf := make([]byte, itemSize)
+			pos := it.arrayOffset + entryArrayItemOffset + it.arrayIndex*itemSize
+			if _, err := jf.f.ReadAt(buf, int64(pos)); err != nil {
+				return 0, false, fmt.Errorf("read entry array item at %d: %w", pos, err)
+			}
+			var offset uint64
+			if itemSize == 4 {
+				offset = uint64(le.Uint32(buf))
+			} else {
+				offset = le.Uint64(buf)
+			}
+			if offset == 0 {
+				// The slot has been allocated but not written yet.
+				return 0, false, nil
+			}
+			it.arrayIndex++
+			it.consumed++
+			return offset, true, nil
+		}
+
+		var buf [8]byte
+		if _, err := jf.f.ReadAt(buf[:], int64(it.arrayOffset+objectHeaderSize)); err != nil {
+			return 0, false, fmt.Errorf("read entry array at %d: %w", it.arrayOffset, err)
+		}
+		next := le.Uint64(buf[:])
+		if next == 0 {
+			return 0, false, nil
+		}
+		it.arrayOffset = next
+		it.arrayIndex = 0
+	}
+}
+
+// Cursor returns the journalctl compatible cursor string of an entry read from a
+// file with the given sequence number ID.
+func Cursor(seqnumID ID, e *Entry) string {
+	return fmt.Sprintf("s=%s;i=%x;b=%s;m=%x;t=%x;x=%x", seqnumID, e.Seqnum, e.BootID, e.Monotonic, e.Realtime, e.XorHash)
+}
+
+// ParsedCursor holds the parts of a cursor string needed to resume reading.
+type ParsedCursor struct {
+	SeqnumID ID
+	Seqnum   uint64
+	Realtime uint64
+}
+
+// ParseCursor parses a cursor string as produced by Cursor or journalctl.
+func ParseCursor(cursor string) (ParsedCursor, error) {
+	var c ParsedCursor
+	var hasSeqnumID, hasSeqnum, hasRealtime bool
+	for _, part := range strings.Split(cursor, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return ParsedCursor{}, fmt.Errorf("invalid cursor part %q", part)
+		}
+		var err error
+		switch key {
+		case "s":
+			var b []byte
+			if b, err = hex.DecodeString(value); err == nil && len(b) != len(c.SeqnumID) {
+				err = errors.New("invalid length")
+			}
+			copy(c.SeqnumID[:], b)
+			hasSeqnumID = true
+		case "i":
+			c.Seqnum, err = strconv.ParseUint(value, 16, 64)
+			hasSeqnum = true
+		case "t":
+			c.Realtime, err = strconv.ParseUint(value, 16, 64)
+			hasRealtime = true
+		}
+		if err != nil {
+			return ParsedCursor{}, fmt.Errorf("invalid cursor field %q: %w", key, err)
+		}
+	}
+	if !hasSeqnumID || !hasSeqnum || !hasRealtime {
+		return ParsedCursor{}, fmt.Errorf("cursor %q is missing required fields", cursor)
+	}
+	return c, nil
+}
+
+// After reports whether an entry read from a file with the given sequence number
+// ID comes after the cursor position.
+func (c ParsedCursor) After(seqnumID ID, e *Entry) bool {
+	if seqnumID == c.SeqnumID {
+		return e.Seqnum > c.Seqnum
+	}
+	return e.Realtime > c.Realtime
+}
@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package journalfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/journald/internal/journalfile"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/journald/internal/journaltest"
+)
+
+func readAll(t *testing.T, jf *journalfile.File, it *journalfile.Iterator) []*journalfile.Entry {
+	require.NoError(t, jf.Refresh())
+	var entries []*journalfile.Entry
+	for {
+		offset, ok, err := jf.Next(it)
+		require.NoError(t, err)
+		if !ok {
+			return entries
+		}
+		e, err := jf.ReadEntry(offset)
+		require.NoError(t, err)
+		entries = append(entries, e)
+	}
+}
+
+func TestReadEntries(t *testing.T) {
+	for _, opts := range []journaltest.Options{
+		{},
+		{Compact: true},
+		{ZSTD: true},
+		{Compact: true, ZSTD: true},
+	} {
+		opts.SeqnumID = journalfile.ID{1, 2, 3}
+		opts.BootID = journalfile.ID{4, 5, 6}
+		path := filepath.Join(t.TempDir(), "system.journal")
+		w := journaltest.NewWriter(t, path, opts)
+		ts := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+		jf, err := journalfile.Open(path)
+		require.NoError(t, err)
+		defer jf.Close()
+
+		var it journalfile.Iterator
+		assert.Empty(t, readAll(t, jf, &it))
+
+		for i := 0; i < 6; i++ {
+			w.Append(ts.Add(time.Duration(i)*time.Second), map[string]string{
+				"MESSAGE":           "hello=world",
+				"SYSLOG_IDENTIFIER": "test",
+			})
+		}
+		entries := readAll(t, jf, &it)
+		require.Len(t, entries, 6)
+		assert.Equal(t, uint64(1), entries[0].Seqnum)
+		assert.Equal(t, uint64(ts.UnixMicro()), entries[0].Realtime)
+		assert.Equal(t, journalfile.ID{4, 5, 6}, entries[0].BootID)
+		assert.Equal(t, []journalfile.Field{
+			{Name: "MESSAGE", Value: []byte("hello=world")},
+			{Name: "SYSLOG_IDENTIFIER", Value: []byte("test")},
+		}, entries[0].Fields)
+		assert.Equal(t, uint64(6), entries[5].Seqnum)
+
+		// Entries appended later are picked up by the same iterator, including
+		// those in a newly chained entry array.
+		w.Append(ts.Add(time.Minute), map[string]string{"MESSAGE": "later"})
+		w.Append(ts.Add(time.Minute), map[string]string{"MESSAGE": "even later"})
+		w.Append(ts.Add(time.Minute), map[string]string{"MESSAGE": "latest"})
+		entries = readAll(t, jf, &it)
+		require.Len(t, entries, 3)
+		assert.Equal(t, []byte("latest"), entries[2].Fields[0].Value)
+		assert.Equal(t, uint64(9), it.Consumed())
+		assert.Equal(t, opts.Compact, jf.Header().Compact())
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.journal")
+	require.NoError(t, os.WriteFile(path, []byte("not a journal"), 0o600))
+	_, err := journalfile.Open(path)
+	assert.ErrorContains(t, err, "not a journal file")
+
+	buf := make([]byte, journalfile.HeaderMinSize)
+	copy(buf, journalfile.Signature[:])
+	buf[12] = 0x80
+	_, err = journalfile.ParseHeader(buf)
+	assert.ErrorContains(t, err, "unknown incompatible flags")
+}
+
+func TestCursor(t *testing.T) {
+	seqnumID := journalfile.ID{0xb1, 0xe7, 0x13}
+	e := &journalfile.Entry{Seqnum: 0x1eed30, Realtime: 0x5a369604ee333, Monotonic: 0x9f9d630205, XorHash: 0x16c2d4fd4fdb7c36}
+	cursor := journalfile.Cursor(seqnumID, e)
+	assert.Equal(t, "s=b1e71300000000000000000000000000;i=1eed30;b=00000000000000000000000000000000;m=9f9d630205;t=5a369604ee333;x=16c2d4fd4fdb7c36", cursor)
+
+	parsed, err := journalfile.ParseCursor(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, journalfile.ParsedCursor{SeqnumID: seqnumID, Seqnum: 0x1eed30, Realtime: 0x5a369604ee333}, parsed)
+
+	assert.False(t, parsed.After(seqnumID, e))
+	assert.True(t, parsed.After(seqnumID, &journalfile.Entry{Seqnum: 0x1eed31}))
+	assert.False(t, parsed.After(journalfile.ID{9}, &journalfile.Entry{Seqnum: 0x1eed31, Realtime: 0x5a369604ee333}))
+	assert.True(t, parsed.After(journalfile.ID{9}, &journalfile.Entry{Seqnum: 1, Realtime: 0x5a369604ee334}))
+
+	_, err = journalfile.ParseCursor("s=zz;i=1;t=1")
+	assert.Error(t, err)
+	_, err = journalfile.ParseCursor("i=1;t=1")
+	assert.Error(t, err)
+}
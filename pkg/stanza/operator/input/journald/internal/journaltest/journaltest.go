@@ -0,0 +1,226 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package journaltest writes minimal systemd journal files for tests.
+package journaltest // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/journald/internal/journaltest"
+
+import (
+	"encoding/binary"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/journald/internal/journalfile"
+)
+
+const (
+	headerSize    = 272
+	arrayCapacity = 4
+)
+
+// Options controls the layout of a written journal file.
+type Options struct {
+	FileID   journalfile.ID
+	SeqnumID journalfile.ID
+	BootID   journalfile.ID
+	// FirstSeqnum is the sequence number of the first appended entry, defaults to 1.
+	FirstSeqnum uint64
+	Compact     bool
+	ZSTD        bool
+}
+
+// Writer appends entries to a journal file, keeping the offsets of previously
+// written objects stable so that readers can follow the file.
+type Writer struct {
+	t    testing.TB
+	f    *os.File
+	opts Options
+	le   binary.ByteOrder
+
+	encoder      *zstd.Encoder
+	incompatible uint32
+	state        uint8
+	offset       uint64
+	nextSeqnum   uint64
+	monotonic    uint64
+	nEntries     uint64
+	headArray    uint64
+	tailArray    uint64
+	tailArrayN   uint64
+	headSeqnum   uint64
+	tailSeqnum   uint64
+	headRT       uint64
+	tailRT       uint64
+}
+
+// NewWriter creates a journal file at path.
+func NewWriter(t testing.TB, path string, opts Options) *Writer {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	if opts.FirstSeqnum == 0 {
+		opts.FirstSeqnum = 1
+	}
+	w := &Writer{
+		t:          t,
+		f:          f,
+		opts:       opts,
+		le:         binary.LittleEndian,
+		offset:     headerSize,
+		nextSeqnum: opts.FirstSeqnum,
+		state:      journalfile.StateOnline,
+	}
+	if opts.Compact {
+		w.incompatible |= journalfile.IncompatibleCompact
+	}
+	if opts.ZSTD {
+		w.incompatible |= journalfile.IncompatibleCompressedZSTD
+		w.encoder, err = zstd.NewWriter(nil)
+		require.NoError(t, err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	w.writeHeader()
+	return w
+}
+
+// Append writes an entry with the given fields. Fields are written in sorted order.
+func (w *Writer) Append(ts time.Time, fields map[string]string) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dataOffsets := make([]uint64, 0, len(names))
+	for _, name := range names {
+		dataOffsets = append(dataOffsets, w.writeData(name+"="+fields[name]))
+	}
+
+	realtime := uint64(ts.UnixMicro())
+	w.monotonic += 1000
+	seqnum := w.nextSeqnum
+	w.nextSeqnum++
+
+	itemSize := 16
+	if w.opts.Compact {
+		itemSize = 4
+	}
+	obj := make([]byte, 64+itemSize*len(dataOffsets))
+	obj[0] = journalfile.ObjectEntry
+	w.le.PutUint64(obj[8:], uint64(len(obj)))
+	w.le.PutUint64(obj[16:], seqnum)
+	w.le.PutUint64(obj[24:], realtime)
+	w.le.PutUint64(obj[32:], w.monotonic)
+	copy(obj[40:56], w.opts.BootID[:])
+	w.le.PutUint64(obj[56:], seqnum*31)
+	for i, off := range dataOffsets {
+		if w.opts.Compact {
+			w.le.PutUint32(obj[64+i*itemSize:], uint32(off))
+		} else {
+			w.le.PutUint64(obj[64+i*itemSize:], off)
+		}
+	}
+	entryOffset := w.writeObject(obj)
+	w.link(entryOffset)
+
+	if w.nEntries == 0 {
+		w.headRT = realtime
+		w.headSeqnum = seqnum
+	}
+	w.nEntries++
+	w.tailRT = realtime
+	w.tailSeqnum = seqnum
+	w.writeHeader()
+}
+
+// Archive marks the file as archived, as journald does before rotating it.
+func (w *Writer) Archive() {
+	w.state = journalfile.StateArchived
+	w.writeHeader()
+}
+
+func (w *Writer) writeData(payload string) uint64 {
+	var flags uint8
+	body := []byte(payload)
+	if w.encoder != nil {
+		body = w.encoder.EncodeAll(body, nil)
+		flags = journalfile.ObjectCompressedZSTD
+	}
+	payloadOffset := 64
+	if w.opts.Compact {
+		payloadOffset = 72
+	}
+	obj := make([]byte, payloadOffset+len(body))
+	obj[0] = journalfile.ObjectData
+	obj[1] = flags
+	w.le.PutUint64(obj[8:], uint64(len(obj)))
+	copy(obj[payloadOffset:], body)
+	return w.writeObject(obj)
+}
+
+// link stores the entry offset in the entry array chain, allocating a new array
+// once the tail array is full.
+func (w *Writer) link(entryOffset uint64) {
+	itemSize := uint64(8)
+	if w.opts.Compact {
+		itemSize = 4
+	}
+	if w.tailArray == 0 || w.tailArrayN == arrayCapacity {
+		obj := make([]byte, 24+itemSize*arrayCapacity)
+		obj[0] = journalfile.ObjectEntryArray
+		w.le.PutUint64(obj[8:], uint64(len(obj)))
+		arrayOffset := w.writeObject(obj)
+		if w.tailArray == 0 {
+			w.headArray = arrayOffset
+		} else {
+			var next [8]byte
+			w.le.PutUint64(next[:], arrayOffset)
+			w.writeAt(next[:], w.tailArray+16)
+		}
+		w.tailArray = arrayOffset
+		w.tailArrayN = 0
+	}
+	item := make([]byte, itemSize)
+	if w.opts.Compact {
+		w.le.PutUint32(item, uint32(entryOffset))
+	} else {
+		w.le.PutUint64(item, entryOffset)
+	}
+	w.writeAt(item, w.tailArray+24+w.tailArrayN*itemSize)
+	w.tailArrayN++
+}
+
+func (w *Writer) writeObject(obj []byte) uint64 {
+	offset := w.offset
+	w.writeAt(obj, offset)
+	w.offset += (uint64(len(obj)) + 7) &^ 7
+	return offset
+}
+
+func (w *Writer) writeAt(buf []byte, offset uint64) {
+	_, err := w.f.WriteAt(buf, int64(offset))
+	require.NoError(w.t, err)
+}
+
+func (w *Writer) writeHeader() {
+	h := make([]byte, headerSize)
+	copy(h[0:8], journalfile.Signature[:])
+	w.le.PutUint32(h[12:], w.incompatible)
+	h[16] = w.state
+	copy(h[24:40], w.opts.FileID[:])
+	copy(h[56:72], w.opts.BootID[:])
+	copy(h[72:88], w.opts.SeqnumID[:])
+	w.le.PutUint64(h[88:], headerSize)
+	w.le.PutUint64(h[96:], w.offset-headerSize)
+	w.le.PutUint64(h[152:], w.nEntries)
+	w.le.PutUint64(h[160:], w.tailSeqnum)
+	w.le.PutUint64(h[168:], w.headSeqnum)
+	w.le.PutUint64(h[176:], w.headArray)
+	w.le.PutUint64(h[184:], w.headRT)
+	w.le.PutUint64(h[192:], w.tailRT)
+	w.le.PutUint64(h[200:], w.monotonic)
+	w.writeAt(h, 0)
+}
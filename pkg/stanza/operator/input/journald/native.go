@@ -0,0 +1,378 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package journald // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/journald"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/helper"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/journald/internal/journalfile"
+)
+
+const (
+	nativePollInterval = 250 * time.Millisecond
+	// maxEntriesPerPoll bounds how many entries are read from a single file before
+	// they are emitted, so that reading a large journal from the beginning does not
+	// load it into memory at once.
+	maxEntriesPerPoll = 1000
+)
+
+// defaultJournalRoots are the directories searched when neither directory nor files are configured.
+var defaultJournalRoots = []string{"/run/log/journal", "/var/log/journal"}
+
+var machineIDPattern = regexp.MustCompile("^[0-9a-f]{32}$")
+
+// NativeInput is an operator that reads journal files directly, without journalctl.
+type NativeInput struct {
+	helper.InputOperator
+
+	discover         func() ([]string, error)
+	filter           *nativeFilter
+	startAtBeginning bool
+	pollInterval     time.Duration
+
+	persister operator.Persister
+	journals  map[journalfile.ID]*journal
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// journal is a journal file tracked across polls. Files are identified by their
+// file ID, so a file that is renamed by journald during rotation keeps its position.
+type journal struct {
+	path string
+	info os.FileInfo
+	file *journalfile.File
+	iter journalfile.Iterator
+	// skip marks entries of the file as read without emitting them.
+	skip bool
+	// resume is the persisted cursor entries must come after.
+	resume *journalfile.ParsedCursor
+}
+
+type pendingEntry struct {
+	entry    *journalfile.Entry
+	seqnumID journalfile.ID
+}
+
+func (c Config) buildNative(inputOperator helper.InputOperator) (operator.Operator, error) {
+	var startAtBeginning bool
+	switch c.StartAt {
+	case "end":
+	case "beginning":
+		startAtBeginning = true
+	default:
+		return nil, fmt.Errorf("invalid value '%s' for parameter 'start_at'", c.StartAt)
+	}
+
+	filter, err := c.buildNativeFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	var discover func() ([]string, error)
+	switch {
+	case c.Directory != nil:
+		discover = func() ([]string, error) { return findJournals([]string{*c.Directory}, c.Namespace) }
+	case len(c.Files) > 0:
+		files := append([]string{}, c.Files...)
+		discover = func() ([]string, error) { return globJournals(files) }
+	default:
+		discover = func() ([]string, error) { return findJournals(defaultJournalRoots, c.Namespace) }
+	}
+
+	return &NativeInput{
+		InputOperator:    inputOperator,
+		discover:         discover,
+		filter:           filter,
+		startAtBeginning: startAtBeginning,
+		pollInterval:     nativePollInterval,
+		journals:         map[journalfile.ID]*journal{},
+	}, nil
+}
+
+// findJournals returns the journal files in each root directory and in its
+// machine ID subdirectories, as journalctl does.
+func findJournals(roots []string, namespace string) ([]string, error) {
+	var paths []string
+	for _, root := range roots {
+		dirEntries, err := os.ReadDir(root)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for _, de := range dirEntries {
+			name := de.Name()
+			switch {
+			case !de.IsDir() && strings.HasSuffix(name, ".journal"):
+				paths = append(paths, filepath.Join(root, name))
+			case de.IsDir() && isJournalDir(name, namespace):
+				matches, err := filepath.Glob(filepath.Join(root, name, "*.journal"))
+				if err != nil {
+					return nil, err
+				}
+				paths = append(paths, matches...)
+			}
+		}
+	}
+	return paths, nil
+}
+
+func isJournalDir(name, namespace string) bool {
+	if namespace == "" {
+		return machineIDPattern.MatchString(name)
+	}
+	machineID, ns, ok := strings.Cut(name, ".")
+	return ok && ns == namespace && machineIDPattern.MatchString(machineID)
+}
+
+func globJournals(patterns []string) ([]string, error) {
+	var paths []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// Start will start reading journal files.
+func (n *NativeInput) Start(persister operator.Persister) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	n.cancel = cancel
+	n.persister = persister
+
+	var resume *journalfile.ParsedCursor
+	cursor, err := persister.Get(ctx, lastReadCursorKey)
+	if err != nil {
+		return fmt.Errorf("failed to get journald state: %w", err)
+	}
+	if cursor != nil {
+		parsed, err := journalfile.ParseCursor(string(cursor))
+		if err != nil {
+			n.Logger().Warn("Ignoring invalid journal cursor", zap.ByteString("cursor", cursor), zap.Error(err))
+		} else {
+			resume = &parsed
+		}
+	}
+
+	n.wg.Add(1)
+	go n.run(ctx, resume)
+	return nil
+}
+
+func (n *NativeInput) run(ctx context.Context, resume *journalfile.ParsedCursor) {
+	defer n.wg.Done()
+
+	first := true
+	for {
+		more := n.poll(ctx, first, resume)
+		first = false
+		if more {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(n.pollInterval):
+		}
+	}
+}
+
+// poll discovers journal files, emits new entries in timestamp order and persists
+// the cursor of the last entry. It returns true if there are more entries to read.
+func (n *NativeInput) poll(ctx context.Context, first bool, resume *journalfile.ParsedCursor) bool {
+	var seen map[journalfile.ID]struct{}
+	if first {
+		seen = n.updateJournals(resume == nil && !n.startAtBeginning, resume)
+	} else {
+		seen = n.updateJournals(false, nil)
+	}
+
+	var pending []pendingEntry
+	var more bool
+	for id, j := range n.journals {
+		if err := j.file.Refresh(); err != nil {
+			n.Logger().Warn("Failed to read journal header", zap.String("path", j.path), zap.Error(err))
+			continue
+		}
+		seqnumID := j.file.Header().SeqnumID
+		drained := false
+		for read := 0; ; read++ {
+			if read == maxEntriesPerPoll {
+				more = true
+				break
+			}
+			offset, ok, err := j.file.Next(&j.iter)
+			if err != nil {
+				n.Logger().Warn("Failed to read journal entry array", zap.String("path", j.path), zap.Error(err))
+				drained = true
+				break
+			}
+			if !ok {
+				j.skip, j.resume = false, nil
+				drained = true
+				break
+			}
+			if j.skip {
+				continue
+			}
+			e, err := j.file.ReadEntry(offset)
+			if err != nil {
+				n.Logger().Warn("Failed to read journal entry", zap.String("path", j.path), zap.Error(err))
+				continue
+			}
+			if j.resume != nil && !j.resume.After(seqnumID, e) {
+				continue
+			}
+			pending = append(pending, pendingEntry{entry: e, seqnumID: seqnumID})
+		}
+		// Files that are no longer discovered have been deleted or rotated out of
+		// the configured paths. Their remaining entries were read above.
+		if _, ok := seen[id]; !ok && drained {
+			n.Logger().Debug("Closing journal file", zap.String("path", j.path))
+			_ = j.file.Close()
+			delete(n.journals, id)
+		}
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		if pending[i].entry.Realtime != pending[j].entry.Realtime {
+			return pending[i].entry.Realtime < pending[j].entry.Realtime
+		}
+		return pending[i].entry.Seqnum < pending[j].entry.Seqnum
+	})
+
+	var cursor string
+	for _, p := range pending {
+		if ctx.Err() != nil {
+			return false
+		}
+		cursor = journalfile.Cursor(p.seqnumID, p.entry)
+		body := nativeBody(p.entry, cursor)
+		if !n.filter.match(body) {
+			continue
+		}
+		ent, err := n.NewEntry(body)
+		if err != nil {
+			n.Logger().Error("failed to create entry", zap.Error(err))
+			continue
+		}
+		ent.Timestamp = time.UnixMicro(int64(p.entry.Realtime))
+		if err = n.Write(ctx, ent); err != nil {
+			n.Logger().Error("failed to write entry", zap.Error(err))
+		}
+	}
+	if cursor != "" {
+		if err := n.persister.Set(ctx, lastReadCursorKey, []byte(cursor)); err != nil {
+			n.Logger().Warn("Failed to set offset", zap.Error(err))
+		}
+	}
+	return more && ctx.Err() == nil
+}
+
+// updateJournals opens newly discovered journal files and returns the IDs of all
+// files that are currently discovered. If skipExisting is set, entries already in
+// newly opened files are not emitted. If resume is set, only entries after it are.
+func (n *NativeInput) updateJournals(skipExisting bool, resume *journalfile.ParsedCursor) map[journalfile.ID]struct{} {
+	seen := map[journalfile.ID]struct{}{}
+	paths, err := n.discover()
+	if err != nil {
+		n.Logger().Warn("Failed to find journal files", zap.Error(err))
+		// Keep reading the files that are already open.
+		for id := range n.journals {
+			seen[id] = struct{}{}
+		}
+		return seen
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if id, ok := n.journalAt(path, info); ok {
+			seen[id] = struct{}{}
+			continue
+		}
+
+		jf, err := journalfile.Open(path)
+		if err != nil {
+			n.Logger().Debug("Failed to open journal file", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		id := jf.Header().FileID
+		seen[id] = struct{}{}
+		if j, ok := n.journals[id]; ok {
+			// The file was renamed, which is how journald archives the active file.
+			j.path, j.info = path, info
+			_ = jf.Close()
+			continue
+		}
+		n.Logger().Debug("Opened journal file", zap.String("path", path))
+		n.journals[id] = &journal{path: path, info: info, file: jf, skip: skipExisting, resume: resume}
+	}
+	return seen
+}
+
+func (n *NativeInput) journalAt(path string, info os.FileInfo) (journalfile.ID, bool) {
+	for id, j := range n.journals {
+		if j.path == path && os.SameFile(j.info, info) {
+			return id, true
+		}
+	}
+	return journalfile.ID{}, false
+}
+
+// nativeBody builds an entry body with the same shape as the journalctl JSON output.
+func nativeBody(e *journalfile.Entry, cursor string) map[string]any {
+	body := make(map[string]any, len(e.Fields)+3)
+	for _, f := range e.Fields {
+		value := string(f.Value)
+		switch existing := body[f.Name].(type) {
+		case nil:
+			body[f.Name] = value
+		case string:
+			body[f.Name] = []any{existing, value}
+		case []any:
+			body[f.Name] = append(existing, value)
+		}
+	}
+	body["__CURSOR"] = cursor
+	body["__MONOTONIC_TIMESTAMP"] = strconv.FormatUint(e.Monotonic, 10)
+	body["_BOOT_ID"] = e.BootID.String()
+	return body
+}
+
+// Stop will stop reading journal files.
+func (n *NativeInput) Stop() error {
+	if n.cancel != nil {
+		n.cancel()
+	}
+	n.wg.Wait()
+	for id, j := range n.journals {
+		_ = j.file.Close()
+		delete(n.journals, id)
+	}
+	return nil
+}
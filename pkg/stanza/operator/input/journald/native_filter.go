@@ -0,0 +1,239 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package journald // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/journald"
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var priorityNames = map[string]int{
+	"emerg":   0,
+	"alert":   1,
+	"crit":    2,
+	"err":     3,
+	"warning": 4,
+	"notice":  5,
+	"info":    6,
+	"debug":   7,
+}
+
+// nativeFilter applies the journalctl filtering options to entries read by the native reader.
+type nativeFilter struct {
+	minPriority int
+	maxPriority int
+	units       []string
+	identifiers map[string]struct{}
+	matches     []MatchConfig
+	grep        *regexp.Regexp
+	dmesg       bool
+}
+
+func (c Config) buildNativeFilter() (*nativeFilter, error) {
+	f := &nativeFilter{
+		minPriority: 0,
+		maxPriority: 7,
+		dmesg:       c.Dmesg,
+	}
+
+	if c.Priority != "" {
+		var err error
+		if f.minPriority, f.maxPriority, err = parsePriorityRange(c.Priority); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, unit := range c.Units {
+		f.units = append(f.units, mangleUnitName(unit))
+	}
+
+	if len(c.Identifiers) > 0 {
+		f.identifiers = make(map[string]struct{}, len(c.Identifiers))
+		for _, identifier := range c.Identifiers {
+			f.identifiers[identifier] = struct{}{}
+		}
+	}
+
+	// Reuse the journalctl argument validation for the match field names.
+	if _, err := c.buildMatchesConfig(); err != nil {
+		return nil, err
+	}
+	f.matches = c.Matches
+
+	if c.Grep != "" {
+		pattern := c.Grep
+		// journalctl matches case insensitively unless the pattern contains uppercase characters.
+		if !strings.ContainsFunc(pattern, unicode.IsUpper) {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value '%s' for parameter 'grep': %w", c.Grep, err)
+		}
+		f.grep = re
+	}
+
+	return f, nil
+}
+
+// parsePriorityRange parses a priority such as "info", "6" or "emerg..err".
+// A single priority selects it and all more important ones.
+func parsePriorityRange(value string) (int, int, error) {
+	from, to, isRange := strings.Cut(value, "..")
+	if !isRange {
+		p, err := parsePriority(value)
+		return 0, p, err
+	}
+	minPriority, err := parsePriority(from)
+	if err != nil {
+		return 0, 0, err
+	}
+	maxPriority, err := parsePriority(to)
+	if err != nil {
+		return 0, 0, err
+	}
+	if minPriority > maxPriority {
+		minPriority, maxPriority = maxPriority, minPriority
+	}
+	return minPriority, maxPriority, nil
+}
+
+func parsePriority(value string) (int, error) {
+	if p, ok := priorityNames[value]; ok {
+		return p, nil
+	}
+	if p, err := strconv.Atoi(value); err == nil && p >= 0 && p <= 7 {
+		return p, nil
+	}
+	return 0, fmt.Errorf("invalid value '%s' for parameter 'priority'", value)
+}
+
+// mangleUnitName appends the .service suffix to unit names without a type, as journalctl does.
+func mangleUnitName(unit string) string {
+	if strings.Contains(unit, ".") || strings.ContainsAny(unit, "*?[") {
+		return unit
+	}
+	return unit + ".service"
+}
+
+func (f *nativeFilter) match(body map[string]any) bool {
+	if !f.matchPriority(body) {
+		return false
+	}
+	if f.dmesg && !hasValue(body, "_TRANSPORT", "kernel") {
+		return false
+	}
+	if len(f.units) > 0 && !f.matchUnits(body) {
+		return false
+	}
+	if f.identifiers != nil && !f.matchIdentifiers(body) {
+		return false
+	}
+	if len(f.matches) > 0 && !f.matchMatches(body) {
+		return false
+	}
+	if f.grep != nil && !f.matchGrep(body) {
+		return false
+	}
+	return true
+}
+
+func (f *nativeFilter) matchPriority(body map[string]any) bool {
+	if f.minPriority == 0 && f.maxPriority == 7 {
+		return true
+	}
+	for _, v := range fieldValues(body, "PRIORITY") {
+		if p, err := strconv.Atoi(v); err == nil && p >= f.minPriority && p <= f.maxPriority {
+			return true
+		}
+	}
+	return false
+}
+
+// matchUnits matches entries logged by a unit and messages logged by systemd about it.
+func (f *nativeFilter) matchUnits(body map[string]any) bool {
+	for _, unit := range f.units {
+		if matchUnit(body, "_SYSTEMD_UNIT", unit) ||
+			(hasValue(body, "_PID", "1") && matchUnit(body, "UNIT", unit)) ||
+			(hasValue(body, "_UID", "0") && matchUnit(body, "OBJECT_SYSTEMD_UNIT", unit)) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchUnit(body map[string]any, field, unit string) bool {
+	for _, v := range fieldValues(body, field) {
+		if ok, _ := path.Match(unit, v); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *nativeFilter) matchIdentifiers(body map[string]any) bool {
+	for _, v := range fieldValues(body, "SYSLOG_IDENTIFIER") {
+		if _, ok := f.identifiers[v]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *nativeFilter) matchMatches(body map[string]any) bool {
+	for _, mc := range f.matches {
+		matched := true
+		for field, value := range mc {
+			if !hasValue(body, field, value) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *nativeFilter) matchGrep(body map[string]any) bool {
+	for _, v := range fieldValues(body, "MESSAGE") {
+		if f.grep.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasValue(body map[string]any, field, value string) bool {
+	for _, v := range fieldValues(body, field) {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldValues(body map[string]any, field string) []string {
+	switch v := body[field].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build linux
+
+package journald
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/entry"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/journald/internal/journalfile"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/journald/internal/journaltest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/testutil"
+)
+
+const testMachineID = "d777d00e7caf45fbadedceba3975520d"
+
+var (
+	testSeqnumID = journalfile.ID{0xb1, 0xe7, 0x13, 0xb5}
+	testBootID   = journalfile.ID{0xc4, 0xfa, 0x36, 0xde}
+	testTime     = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+)
+
+func startNative(t *testing.T, cfg *Config, persister operator.Persister) (operator.Operator, chan *entry.Entry) {
+	cfg.OutputIDs = []string{"output"}
+	cfg.Reader = readerNative
+	op, err := cfg.Build(componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	op.(*NativeInput).pollInterval = 10 * time.Millisecond
+
+	mockOutput := testutil.NewMockOperator("output")
+	received := make(chan *entry.Entry, 100)
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		received <- args.Get(1).(*entry.Entry)
+	}).Return(nil)
+	require.NoError(t, op.SetOutputs([]operator.Operator{mockOutput}))
+
+	require.NoError(t, op.Start(persister))
+	t.Cleanup(func() { require.NoError(t, op.Stop()) })
+	return op, received
+}
+
+func expectMessages(t *testing.T, received chan *entry.Entry, messages ...string) []*entry.Entry {
+	entries := make([]*entry.Entry, 0, len(messages))
+	for _, msg := range messages {
+		select {
+		case e := <-received:
+			require.Equal(t, msg, e.Body.(map[string]any)["MESSAGE"])
+			entries = append(entries, e)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "Timed out waiting for entry", msg)
+		}
+	}
+	select {
+	case e := <-received:
+		require.FailNow(t, "Received unexpected entry", "%v", e.Body)
+	case <-time.After(100 * time.Millisecond):
+	}
+	return entries
+}
+
+func message(msg string) map[string]string {
+	return map[string]string{"MESSAGE": msg, "PRIORITY": "6", "_SYSTEMD_UNIT": "ssh.service"}
+}
+
+func newJournalDir(t *testing.T) (string, string) {
+	root := t.TempDir()
+	dir := filepath.Join(root, testMachineID)
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	return root, dir
+}
+
+func TestNativeInputBeginning(t *testing.T) {
+	root, dir := newJournalDir(t)
+	w := journaltest.NewWriter(t, filepath.Join(dir, "system.journal"), journaltest.Options{
+		FileID: journalfile.ID{1}, SeqnumID: testSeqnumID, BootID: testBootID, Compact: true, ZSTD: true,
+	})
+	w.Append(testTime, map[string]string{
+		"MESSAGE":           "Accepted publickey",
+		"PRIORITY":          "6",
+		"SYSLOG_IDENTIFIER": "sshd",
+		"_SYSTEMD_UNIT":     "ssh.service",
+	})
+
+	cfg := NewConfigWithID("my_journald_input")
+	cfg.Directory = &root
+	cfg.StartAt = "beginning"
+	_, received := startNative(t, cfg, testutil.NewUnscopedMockPersister())
+
+	entries := expectMessages(t, received, "Accepted publickey")
+	assert.Equal(t, map[string]any{
+		"MESSAGE":               "Accepted publickey",
+		"PRIORITY":              "6",
+		"SYSLOG_IDENTIFIER":     "sshd",
+		"_SYSTEMD_UNIT":         "ssh.service",
+		"_BOOT_ID":              "c4fa36de000000000000000000000000",
+		"__MONOTONIC_TIMESTAMP": "1000",
+		"__CURSOR":              fmt.Sprintf("s=b1e713b5000000000000000000000000;i=1;b=c4fa36de000000000000000000000000;m=3e8;t=%x;x=1f", testTime.UnixMicro()),
+	}, entries[0].Body)
+	assert.Equal(t, testTime, entries[0].Timestamp.UTC())
+
+	w.Append(testTime.Add(time.Second), message("followed"))
+	expectMessages(t, received, "followed")
+}
+
+func TestNativeInputEnd(t *testing.T) {
+	root, dir := newJournalDir(t)
+	w := journaltest.NewWriter(t, filepath.Join(dir, "system.journal"), journaltest.Options{
+		FileID: journalfile.ID{1}, SeqnumID: testSeqnumID,
+	})
+	w.Append(testTime, message("old"))
+
+	cfg := NewConfigWithID("my_journald_input")
+	cfg.Directory = &root
+	_, received := startNative(t, cfg, testutil.NewUnscopedMockPersister())
+
+	expectMessages(t, received)
+	w.Append(testTime.Add(time.Second), message("new"))
+	expectMessages(t, received, "new")
+}
+
+func TestNativeInputRotation(t *testing.T) {
+	_, dir := newJournalDir(t)
+	active := filepath.Join(dir, "system.journal")
+	w := journaltest.NewWriter(t, active, journaltest.Options{FileID: journalfile.ID{1}, SeqnumID: testSeqnumID})
+	w.Append(testTime, message("first"))
+
+	cfg := NewConfigWithID("my_journald_input")
+	cfg.Directory = &dir
+	cfg.StartAt = "beginning"
+	_, received := startNative(t, cfg, testutil.NewUnscopedMockPersister())
+	expectMessages(t, received, "first")
+
+	// journald archives the active file by renaming it and starts a new one
+	// which continues the sequence numbers.
+	w.Append(testTime.Add(time.Second), message("before rotation"))
+	w.Archive()
+	require.NoError(t, os.Rename(active, filepath.Join(dir, "system@b1e713b5-0000000000000001-0006180ab9aaa240.journal")))
+	w2 := journaltest.NewWriter(t, active, journaltest.Options{FileID: journalfile.ID{2}, SeqnumID: testSeqnumID, FirstSeqnum: 3})
+	w2.Append(testTime.Add(2*time.Second), message("after rotation"))
+
+	expectMessages(t, received, "before rotation", "after rotation")
+}
+
+func TestNativeInputResume(t *testing.T) {
+	_, dir := newJournalDir(t)
+	w := journaltest.NewWriter(t, filepath.Join(dir, "system.journal"), journaltest.Options{FileID: journalfile.ID{1}, SeqnumID: testSeqnumID})
+	w.Append(testTime, message("one"))
+	w.Append(testTime.Add(time.Second), message("two"))
+
+	persister := testutil.NewUnscopedMockPersister()
+	cfg := NewConfigWithID("my_journald_input")
+	cfg.Files = []string{filepath.Join(dir, "*.journal")}
+	cfg.StartAt = "beginning"
+	op, received := startNative(t, cfg, persister)
+	entries := expectMessages(t, received, "one", "two")
+	require.NoError(t, op.Stop())
+
+	cursor := entries[1].Body.(map[string]any)["__CURSOR"]
+	require.Eventually(t, func() bool {
+		stored, err := persister.Get(context.Background(), lastReadCursorKey)
+		return err == nil && string(stored) == cursor
+	}, time.Second, 10*time.Millisecond)
+
+	// The persisted cursor takes precedence over start_at.
+	w.Append(testTime.Add(2*time.Second), message("three"))
+	cfg = NewConfigWithID("my_journald_input")
+	cfg.Files = []string{filepath.Join(dir, "*.journal")}
+	_, received = startNative(t, cfg, persister)
+	expectMessages(t, received, "three")
+}
+
+func TestNativeInputFilters(t *testing.T) {
+	_, dir := newJournalDir(t)
+	w := journaltest.NewWriter(t, filepath.Join(dir, "system.journal"), journaltest.Options{FileID: journalfile.ID{1}, SeqnumID: testSeqnumID})
+	w.Append(testTime, map[string]string{"MESSAGE": "debug from ssh", "PRIORITY": "7", "_SYSTEMD_UNIT": "ssh.service"})
+	w.Append(testTime, map[string]string{"MESSAGE": "Error from ssh", "PRIORITY": "3", "_SYSTEMD_UNIT": "ssh.service"})
+	w.Append(testTime, map[string]string{"MESSAGE": "error from cron", "PRIORITY": "3", "_SYSTEMD_UNIT": "cron.service"})
+	w.Append(testTime, map[string]string{"MESSAGE": "stopped ssh", "PRIORITY": "6", "_PID": "1", "UNIT": "ssh.service"})
+	w.Append(testTime, map[string]string{"MESSAGE": "info from ssh", "PRIORITY": "6", "_SYSTEMD_UNIT": "ssh.service"})
+
+	cfg := NewConfigWithID("my_journald_input")
+	cfg.Directory = &dir
+	cfg.StartAt = "beginning"
+	cfg.Units = []string{"ssh"}
+	_, received := startNative(t, cfg, testutil.NewUnscopedMockPersister())
+	expectMessages(t, received, "Error from ssh", "stopped ssh", "info from ssh")
+
+	cfg = NewConfigWithID("my_journald_input")
+	cfg.Directory = &dir
+	cfg.StartAt = "beginning"
+	cfg.Priority = "err"
+	cfg.Grep = "error"
+	_, received = startNative(t, cfg, testutil.NewUnscopedMockPersister())
+	expectMessages(t, received, "Error from ssh", "error from cron")
+}
+
+func TestNativeFilter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   func(*Config)
+		body     map[string]any
+		expected bool
+	}{
+		{
+			name:     "priority range",
+			config:   func(cfg *Config) { cfg.Priority = "warning..err" },
+			body:     map[string]any{"PRIORITY": "4"},
+			expected: true,
+		},
+		{
+			name:     "priority outside range",
+			config:   func(cfg *Config) { cfg.Priority = "warning..err" },
+			body:     map[string]any{"PRIORITY": "2"},
+			expected: false,
+		},
+		{
+			name:     "missing priority",
+			config:   func(_ *Config) {},
+			body:     map[string]any{"MESSAGE": "no priority"},
+			expected: false,
+		},
+		{
+			name:     "identifier",
+			config:   func(cfg *Config) { cfg.Identifiers = []string{"sshd"} },
+			body:     map[string]any{"PRIORITY": "6", "SYSLOG_IDENTIFIER": "sshd"},
+			expected: true,
+		},
+		{
+			name: "matches",
+			config: func(cfg *Config) {
+				cfg.Matches = []MatchConfig{{"_SYSTEMD_UNIT": "ssh.service"}, {"_SYSTEMD_UNIT": "kubelet.service", "_UID": "1000"}}
+			},
+			body:     map[string]any{"PRIORITY": "6", "_SYSTEMD_UNIT": "kubelet.service", "_UID": "0"},
+			expected: false,
+		},
+		{
+			name:     "repeated field",
+			config:   func(cfg *Config) { cfg.Matches = []MatchConfig{{"TAG": "b"}} },
+			body:     map[string]any{"PRIORITY": "6", "TAG": []any{"a", "b"}},
+			expected: true,
+		},
+		{
+			name:     "unit glob",
+			config:   func(cfg *Config) { cfg.Units = []string{"user@*.service"} },
+			body:     map[string]any{"PRIORITY": "6", "_SYSTEMD_UNIT": "user@1000.service"},
+			expected: true,
+		},
+		{
+			name:     "grep with uppercase is case sensitive",
+			config:   func(cfg *Config) { cfg.Grep = "Fail" },
+			body:     map[string]any{"PRIORITY": "6", "MESSAGE": "failed"},
+			expected: false,
+		},
+		{
+			name:     "dmesg",
+			config:   func(cfg *Config) { cfg.Dmesg = true },
+			body:     map[string]any{"PRIORITY": "6", "_TRANSPORT": "kernel"},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewConfigWithID("my_journald_input")
+			tc.config(cfg)
+			f, err := cfg.buildNativeFilter()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, f.match(tc.body))
+		})
+	}
+}
+
+func TestBuildNativeConfigErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        func(*Config)
+		expectedError string
+	}{
+		{
+			name:          "invalid reader",
+			config:        func(cfg *Config) { cfg.Reader = "libsystemd" },
+			expectedError: "invalid value 'libsystemd' for parameter 'reader'",
+		},
+		{
+			name: "invalid priority",
+			config: func(cfg *Config) {
+				cfg.Reader = readerNative
+				cfg.Priority = "verbose"
+			},
+			expectedError: "invalid value 'verbose' for parameter 'priority'",
+		},
+		{
+			name: "invalid start_at",
+			config: func(cfg *Config) {
+				cfg.Reader = readerNative
+				cfg.StartAt = "middle"
+			},
+			expectedError: "invalid value 'middle' for parameter 'start_at'",
+		},
+		{
+			name: "invalid match",
+			config: func(cfg *Config) {
+				cfg.Reader = readerNative
+				cfg.Matches = []MatchConfig{{"-SYSTEMD_UNIT": "dbus.service"}}
+			},
+			expectedError: "'-SYSTEMD_UNIT' is not a valid Systemd field name",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := NewConfigWithID("my_journald_input")
+			tc.config(cfg)
+			_, err := cfg.Build(componenttest.NewNopTelemetrySettings())
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
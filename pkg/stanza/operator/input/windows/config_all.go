@@ -37,6 +37,7 @@ type Config struct {
 	SuppressRenderingInfo bool          `mapstructure:"suppress_rendering_info,omitempty"`
 	ExcludeProviders      []string      `mapstructure:"exclude_providers,omitempty"`
 	Remote                RemoteConfig  `mapstructure:"remote,omitempty"`
+	EVTX                  EVTXConfig    `mapstructure:"evtx,omitempty"`
 }
 
 // RemoteConfig is the configuration for a remote server.
@@ -46,3 +47,11 @@ type RemoteConfig struct {
 	Password string `mapstructure:"password"`
 	Domain   string `mapstructure:"domain,omitempty"`
 }
+
+func excludeProvidersSet(providers []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(providers))
+	for _, provider := range providers {
+		set[provider] = struct{}{}
+	}
+	return set
+}
@@ -24,6 +24,10 @@ func (c *Config) Build(set component.TelemetrySettings) (operator.Operator, erro
 		return nil, err
 	}
 
+	if len(c.EVTX.Include) > 0 {
+		return c.buildEVTX(inputOperator)
+	}
+
 	if c.Channel == "" {
 		return nil, fmt.Errorf("missing required `channel` field")
 	}
@@ -62,11 +66,3 @@ func (c *Config) Build(set component.TelemetrySettings) (operator.Operator, erro
 
 	return input, nil
 }
-
-func excludeProvidersSet(providers []string) map[string]struct{} {
-	set := make(map[string]struct{}, len(providers))
-	for _, provider := range providers {
-		set[provider] = struct{}{}
-	}
-	return set
-}
@@ -0,0 +1,265 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package windows // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/windows"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/helper"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/windows/internal/evtx"
+)
+
+// evtxOffsetsKey is the key under which the last read record of every file is persisted.
+const evtxOffsetsKey = "evtx_offsets"
+
+// EVTXConfig is the configuration for reading exported event log files.
+type EVTXConfig struct {
+	Include []string `mapstructure:"include,omitempty"`
+	Exclude []string `mapstructure:"exclude,omitempty"`
+}
+
+func (c *Config) buildEVTX(inputOperator helper.InputOperator) (operator.Operator, error) {
+	if c.Channel != "" {
+		return nil, fmt.Errorf("the `channel` and `evtx` fields cannot be used together")
+	}
+	if c.Remote.Server != "" {
+		return nil, fmt.Errorf("the `remote` and `evtx` fields cannot be used together")
+	}
+	if c.StartAt != "end" && c.StartAt != "beginning" {
+		return nil, fmt.Errorf("the `start_at` field must be set to `beginning` or `end`")
+	}
+	if c.MaxReads < 1 {
+		return nil, fmt.Errorf("the `max_reads` field must be greater than zero")
+	}
+	for _, pattern := range append(append([]string{}, c.EVTX.Include...), c.EVTX.Exclude...) {
+		if !doublestar.ValidatePathPattern(pattern) {
+			return nil, fmt.Errorf("invalid `evtx` glob pattern %q", pattern)
+		}
+	}
+
+	return &evtxInput{
+		InputOperator:    inputOperator,
+		include:          c.EVTX.Include,
+		exclude:          c.EVTX.Exclude,
+		maxReads:         c.MaxReads,
+		startAtBeginning: c.StartAt == "beginning",
+		pollInterval:     c.PollInterval,
+		raw:              c.Raw,
+		excludeProviders: excludeProvidersSet(c.ExcludeProviders),
+	}, nil
+}
+
+// evtxInput is an operator that creates entries from exported event log files.
+// It does not depend on the windows event log api, so rendering information
+// such as messages is not available.
+type evtxInput struct {
+	helper.InputOperator
+	include          []string
+	exclude          []string
+	maxReads         int
+	startAtBeginning bool
+	pollInterval     time.Duration
+	raw              bool
+	excludeProviders map[string]struct{}
+
+	persister operator.Persister
+	offsets   map[string]uint64
+	firstPoll bool
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// Start will start reading events from the matching files.
+func (i *evtxInput) Start(persister operator.Persister) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	i.cancel = cancel
+	i.persister = persister
+
+	i.offsets = map[string]uint64{}
+	data, err := persister.Get(ctx, evtxOffsetsKey)
+	if err != nil {
+		return fmt.Errorf("get offsets: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &i.offsets); err != nil {
+			i.Logger().Warn("Failed to unmarshal offsets, reading from configured start position", zap.Error(err))
+			i.offsets = map[string]uint64{}
+		}
+	}
+	// Files that were read before are resumed even when starting at the end.
+	i.firstPoll = len(i.offsets) == 0
+
+	i.wg.Add(1)
+	go i.readOnInterval(ctx)
+	return nil
+}
+
+// Stop will stop reading events.
+func (i *evtxInput) Stop() error {
+	if i.cancel != nil {
+		i.cancel()
+	}
+	i.wg.Wait()
+	return nil
+}
+
+// readOnInterval will read the matching files with respect to the polling interval.
+func (i *evtxInput) readOnInterval(ctx context.Context) {
+	defer i.wg.Done()
+
+	i.poll(ctx)
+
+	ticker := time.NewTicker(i.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			i.poll(ctx)
+		}
+	}
+}
+
+// poll reads new records from all matching files and persists the resulting offsets.
+func (i *evtxInput) poll(ctx context.Context) {
+	paths, err := i.findFiles()
+	if err != nil {
+		i.Logger().Warn("Failed to find some evtx files", zap.Error(err))
+	}
+
+	seen := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		seen[path] = struct{}{}
+		if ctx.Err() != nil {
+			return
+		}
+		if err := i.readFile(ctx, path); err != nil {
+			i.Logger().Error("Failed to read evtx file", zap.String("path", path), zap.Error(err))
+		}
+	}
+	i.firstPoll = false
+
+	// Forget about files that are gone, so that a new file at the same path is read in full.
+	for path := range i.offsets {
+		if _, ok := seen[path]; !ok {
+			delete(i.offsets, path)
+		}
+	}
+
+	data, err := json.Marshal(i.offsets)
+	if err != nil {
+		i.Logger().Error("Failed to marshal offsets", zap.Error(err))
+		return
+	}
+	if err := i.persister.Set(ctx, evtxOffsetsKey, data); err != nil {
+		i.Logger().Error("Failed to persist offsets", zap.Error(err))
+	}
+}
+
+// findFiles returns the files that match the include patterns and none of the exclude patterns.
+func (i *evtxInput) findFiles() ([]string, error) {
+	var errs error
+	var paths []string
+	seen := map[string]struct{}{}
+	for _, include := range i.include {
+		matches, err := doublestar.FilepathGlob(include, doublestar.WithFilesOnly(), doublestar.WithFailOnIOErrors())
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("find files with '%s' pattern: %w", include, err))
+			matches, _ = doublestar.FilepathGlob(include, doublestar.WithFilesOnly())
+		}
+	MATCH:
+		for _, match := range matches {
+			for _, exclude := range i.exclude {
+				if excluded, _ := doublestar.PathMatch(exclude, match); excluded {
+					continue MATCH
+				}
+			}
+			if _, ok := seen[match]; ok {
+				continue
+			}
+			seen[match] = struct{}{}
+			paths = append(paths, match)
+		}
+	}
+	sort.Strings(paths)
+	return paths, errs
+}
+
+// readFile sends the records of a file that were not read yet, at most maxReads per poll.
+func (i *evtxInput) readFile(ctx context.Context, path string) error {
+	f, err := evtx.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	after, ok := i.offsets[path]
+	if !ok && i.firstPoll && !i.startAtBeginning {
+		last, err := f.LastRecordID()
+		if err != nil {
+			return err
+		}
+		i.offsets[path] = last
+		return nil
+	}
+	// Remember files without records too, so that their first records are read
+	// even if they only appear after the first poll.
+	i.offsets[path] = after
+
+	errDone := errors.New("done")
+	reads := 0
+	err = f.Records(after, func(record evtx.Record) error {
+		if err := i.processRecord(ctx, record); err != nil {
+			i.Logger().Error("process event", zap.String("path", path), zap.Uint64("record_id", record.ID), zap.Error(err))
+		}
+		i.offsets[path] = record.ID
+		reads++
+		if reads >= i.maxReads || ctx.Err() != nil {
+			return errDone
+		}
+		return nil
+	})
+	if errors.Is(err, errDone) {
+		return nil
+	}
+	return err
+}
+
+// processRecord will send a record unless its provider is excluded.
+func (i *evtxInput) processRecord(ctx context.Context, record evtx.Record) error {
+	eventXML, err := unmarshalEventXML([]byte(record.XML))
+	if err != nil {
+		return err
+	}
+	if _, exclude := i.excludeProviders[eventXML.Provider.Name]; exclude {
+		return nil
+	}
+
+	var body any = eventXML.Original
+	if !i.raw {
+		body = formattedBody(eventXML)
+	}
+
+	e, err := i.NewEntry(body)
+	if err != nil {
+		return fmt.Errorf("create entry: %w", err)
+	}
+
+	e.Timestamp = parseTimestamp(eventXML.TimeCreated.SystemTime)
+	e.Severity = parseSeverity(eventXML.RenderedLevel, eventXML.Level)
+
+	return i.Write(ctx, e)
+}
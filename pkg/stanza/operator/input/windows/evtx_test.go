@@ -0,0 +1,236 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package windows
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/entry"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/windows/internal/evtx/evtxtest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/testutil"
+)
+
+var evtxTestTime = time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)
+
+func evtxEvent(provider string, id uint64) evtxtest.Event {
+	return evtxtest.Event{
+		Provider:    provider,
+		EventID:     7036,
+		Level:       4,
+		Keywords:    0x8080000000000000,
+		TimeCreated: evtxTestTime.Add(time.Duration(id) * time.Second),
+		RecordID:    id,
+		ProcessID:   640,
+		ThreadID:    1024,
+		Channel:     "System",
+		Computer:    "WIN-HOST",
+		Data:        [2]string{"Windows Update", "running"},
+	}
+}
+
+func writeEVTX(t *testing.T, path string, events ...evtxtest.Event) {
+	tmpl := evtxtest.EventTemplate()
+	records := make([]evtxtest.Record, 0, len(events))
+	for _, e := range events {
+		records = append(records, e.Record(tmpl))
+	}
+	require.NoError(t, evtxtest.Write(path, records))
+}
+
+func startEVTX(t *testing.T, cfg *Config, persister operator.Persister) chan *entry.Entry {
+	cfg.OutputIDs = []string{"output"}
+	op, err := cfg.Build(componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+
+	mockOutput := testutil.NewMockOperator("output")
+	received := make(chan *entry.Entry, 100)
+	mockOutput.On("Process", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		received <- args.Get(1).(*entry.Entry)
+	}).Return(nil)
+	require.NoError(t, op.SetOutputs([]operator.Operator{mockOutput}))
+
+	require.NoError(t, op.Start(persister))
+	t.Cleanup(func() { require.NoError(t, op.Stop()) })
+	return received
+}
+
+func expectRecordIDs(t *testing.T, received chan *entry.Entry, ids ...uint64) []*entry.Entry {
+	entries := make([]*entry.Entry, 0, len(ids))
+	for _, id := range ids {
+		select {
+		case e := <-received:
+			require.Equal(t, id, e.Body.(map[string]any)["record_id"])
+			entries = append(entries, e)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "Timed out waiting for entry", "record %d", id)
+		}
+	}
+	select {
+	case e := <-received:
+		require.FailNow(t, "Received unexpected entry", "%v", e.Body)
+	case <-time.After(100 * time.Millisecond):
+	}
+	return entries
+}
+
+func newEVTXConfig(dir string) *Config {
+	cfg := NewConfigWithID("test")
+	cfg.EVTX.Include = []string{filepath.Join(dir, "*.evtx")}
+	cfg.PollInterval = 10 * time.Millisecond
+	return cfg
+}
+
+func TestEVTXInputBeginning(t *testing.T) {
+	dir := t.TempDir()
+	writeEVTX(t, filepath.Join(dir, "System.evtx"), evtxEvent("Service Control Manager", 1), evtxEvent("Service Control Manager", 2))
+
+	cfg := newEVTXConfig(dir)
+	cfg.StartAt = "beginning"
+	received := startEVTX(t, cfg, testutil.NewUnscopedMockPersister())
+
+	entries := expectRecordIDs(t, received, 1, 2)
+	e := entries[0]
+	assert.Equal(t, evtxTestTime.Add(time.Second), e.Timestamp)
+	assert.Equal(t, entry.Info, e.Severity)
+	body := e.Body.(map[string]any)
+	assert.Equal(t, map[string]any{"name": "Service Control Manager", "guid": "", "event_source": ""}, body["provider"])
+	assert.Equal(t, map[string]any{"id": uint32(7036), "qualifiers": uint16(0)}, body["event_id"])
+	assert.Equal(t, "System", body["channel"])
+	assert.Equal(t, "WIN-HOST", body["computer"])
+	assert.Equal(t, "4", body["level"])
+	assert.Equal(t, []string{"0x8080000000000000"}, body["keywords"])
+	assert.Equal(t, map[string]any{
+		"data": []any{
+			map[string]any{"SubjectUserName": "Windows Update"},
+			map[string]any{"TargetUserName": "running"},
+		},
+	}, body["event_data"])
+	assert.NotContains(t, body, "security")
+}
+
+func TestEVTXInputEnd(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "System.evtx")
+	writeEVTX(t, path, evtxEvent("a", 1), evtxEvent("a", 2))
+
+	received := startEVTX(t, newEVTXConfig(dir), testutil.NewUnscopedMockPersister())
+	expectRecordIDs(t, received)
+
+	// New records in known files and all records in new files are read.
+	writeEVTX(t, path, evtxEvent("a", 1), evtxEvent("a", 2), evtxEvent("a", 3))
+	expectRecordIDs(t, received, 3)
+	writeEVTX(t, filepath.Join(dir, "Archive.evtx"), evtxEvent("a", 1))
+	expectRecordIDs(t, received, 1)
+}
+
+func TestEVTXInputResume(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "System.evtx")
+	writeEVTX(t, path, evtxEvent("a", 1), evtxEvent("a", 2))
+	persister := testutil.NewUnscopedMockPersister()
+
+	cfg := newEVTXConfig(dir)
+	cfg.StartAt = "beginning"
+	cfg.OutputIDs = []string{"output"}
+	op, err := cfg.Build(componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	mockOutput := testutil.NewMockOperator("output")
+	mockOutput.On("Process", mock.Anything, mock.Anything).Return(nil)
+	require.NoError(t, op.SetOutputs([]operator.Operator{mockOutput}))
+	require.NoError(t, op.Start(persister))
+	require.Eventually(t, func() bool {
+		data, err := persister.Get(context.Background(), evtxOffsetsKey)
+		return err == nil && string(data) != "" && string(data) != "{}"
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, op.Stop())
+
+	writeEVTX(t, path, evtxEvent("a", 1), evtxEvent("a", 2), evtxEvent("a", 3))
+	cfg = newEVTXConfig(dir)
+	cfg.StartAt = "beginning"
+	received := startEVTX(t, cfg, persister)
+	expectRecordIDs(t, received, 3)
+}
+
+func TestEVTXInputOptions(t *testing.T) {
+	dir := t.TempDir()
+	writeEVTX(t, filepath.Join(dir, "System.evtx"), evtxEvent("a", 1), evtxEvent("b", 2), evtxEvent("a", 3), evtxEvent("a", 4))
+	writeEVTX(t, filepath.Join(dir, "Excluded.evtx"), evtxEvent("a", 1))
+
+	cfg := newEVTXConfig(dir)
+	cfg.StartAt = "beginning"
+	cfg.EVTX.Exclude = []string{filepath.Join(dir, "Excluded.evtx")}
+	cfg.ExcludeProviders = []string{"b"}
+	cfg.MaxReads = 1
+	cfg.Raw = true
+	cfg.OutputIDs = []string{"output"}
+	received := startEVTX(t, cfg, testutil.NewUnscopedMockPersister())
+
+	for _, id := range []string{"1", "3", "4"} {
+		select {
+		case e := <-received:
+			body, ok := e.Body.(string)
+			require.True(t, ok)
+			assert.Contains(t, body, "<EventRecordID>"+id+"</EventRecordID>")
+			assert.Equal(t, entry.Info, e.Severity)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "Timed out waiting for entry", id)
+		}
+	}
+	select {
+	case e := <-received:
+		require.FailNow(t, "Received unexpected entry", "%v", e.Body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBuildEVTXConfigErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(*Config)
+		err    string
+	}{
+		{
+			name:   "channel",
+			modify: func(c *Config) { c.Channel = "System" },
+			err:    "the `channel` and `evtx` fields cannot be used together",
+		},
+		{
+			name:   "remote",
+			modify: func(c *Config) { c.Remote.Server = "host" },
+			err:    "the `remote` and `evtx` fields cannot be used together",
+		},
+		{
+			name:   "start_at",
+			modify: func(c *Config) { c.StartAt = "middle" },
+			err:    "the `start_at` field must be set to `beginning` or `end`",
+		},
+		{
+			name:   "max_reads",
+			modify: func(c *Config) { c.MaxReads = 0 },
+			err:    "the `max_reads` field must be greater than zero",
+		},
+		{
+			name:   "pattern",
+			modify: func(c *Config) { c.EVTX.Exclude = []string{"[a"} },
+			err:    "invalid `evtx` glob pattern",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := newEVTXConfig(t.TempDir())
+			tc.modify(cfg)
+			_, err := cfg.Build(componenttest.NewNopTelemetrySettings())
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,467 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package evtx // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/windows/internal/evtx"
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// BinXML tokens. The 0x40 bit of some tokens marks that more data follows,
+// such as attributes of an element.
+const (
+	tokenEOF                  = 0x00
+	tokenOpenStartElement     = 0x01
+	tokenCloseStartElement    = 0x02
+	tokenCloseEmptyElement    = 0x03
+	tokenEndElement           = 0x04
+	tokenValue                = 0x05
+	tokenAttribute            = 0x06
+	tokenCDATASection         = 0x07
+	tokenCharRef              = 0x08
+	tokenEntityRef            = 0x09
+	tokenPITarget             = 0x0a
+	tokenPIData               = 0x0b
+	tokenTemplateInstance     = 0x0c
+	tokenNormalSubstitution   = 0x0d
+	tokenOptionalSubstitution = 0x0e
+	tokenFragmentHeader       = 0x0f
+
+	tokenHasMoreData = 0x40
+
+	// noDependency marks elements that are rendered regardless of substitution values.
+	noDependency = 0xffff
+
+	// maxDepth bounds the nesting of elements and embedded BinXML values.
+	maxDepth = 64
+)
+
+var errTruncated = errors.New("truncated binary xml")
+
+// substitution is a value of a template instance, located in the chunk.
+type substitution struct {
+	typ    uint8
+	offset int
+	size   int
+}
+
+func (s substitution) null() bool {
+	return s.typ == typeNull || s.size == 0
+}
+
+// renderer renders the binary XML of a record. Offsets of names and templates
+// are relative to the chunk, so the renderer works on the whole chunk.
+type renderer struct {
+	chunk []byte
+	names map[int]string
+	end   int
+	depth int
+}
+
+func (r *renderer) render(pos int) (string, error) {
+	var sb strings.Builder
+	if _, err := r.fragment(&sb, pos, nil); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// fragment renders a binary XML fragment until its end of file token.
+func (r *renderer) fragment(w io.StringWriter, pos int, subs []substitution) (int, error) {
+	r.depth++
+	defer func() { r.depth-- }()
+	if r.depth > maxDepth {
+		return 0, errors.New("binary xml nested too deeply")
+	}
+
+	for {
+		tok, err := r.byte(pos)
+		if err != nil {
+			return 0, err
+		}
+		switch tok &^ tokenHasMoreData {
+		case tokenEOF:
+			return pos + 1, nil
+		case tokenFragmentHeader:
+			pos += 4
+		case tokenTemplateInstance:
+			if pos, err = r.templateInstance(w, pos); err != nil {
+				return 0, err
+			}
+		case tokenOpenStartElement:
+			if pos, err = r.element(w, pos, subs); err != nil {
+				return 0, err
+			}
+		default:
+			if pos, err = r.content(w, pos, subs); err != nil {
+				return 0, err
+			}
+		}
+		if pos >= r.end {
+			// Some records end without an end of file token.
+			return pos, nil
+		}
+	}
+}
+
+func (r *renderer) element(w io.StringWriter, pos int, subs []substitution) (int, error) {
+	r.depth++
+	defer func() { r.depth-- }()
+	if r.depth > maxDepth {
+		return 0, errors.New("binary xml nested too deeply")
+	}
+
+	tok, err := r.byte(pos)
+	if err != nil {
+		return 0, err
+	}
+	dependency, err := r.uint16(pos + 1)
+	if err != nil {
+		return 0, err
+	}
+	nameOffset, err := r.uint32(pos + 7)
+	if err != nil {
+		return 0, err
+	}
+	name, pos, err := r.name(int(nameOffset), pos+11)
+	if err != nil {
+		return 0, err
+	}
+
+	// Elements depending on a substitution without a value are not rendered.
+	if dependency != noDependency && (int(dependency) >= len(subs) || subs[dependency].null()) {
+		w = discard{}
+	}
+
+	_, _ = w.WriteString("<" + name)
+	if tok&tokenHasMoreData != 0 {
+		size, err := r.uint32(pos)
+		if err != nil {
+			return 0, err
+		}
+		pos += 4
+		end := pos + int(size)
+		for pos < end {
+			if pos, err = r.attribute(w, pos, subs); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	tok, err = r.byte(pos)
+	if err != nil {
+		return 0, err
+	}
+	switch tok {
+	case tokenCloseEmptyElement:
+		_, _ = w.WriteString("/>")
+		return pos + 1, nil
+	case tokenCloseStartElement:
+		_, _ = w.WriteString(">")
+		pos++
+	default:
+		return 0, fmt.Errorf("unexpected token 0x%02x after element %q", tok, name)
+	}
+
+	for {
+		tok, err := r.byte(pos)
+		if err != nil {
+			return 0, err
+		}
+		switch tok &^ tokenHasMoreData {
+		case tokenEndElement:
+			_, _ = w.WriteString("</" + name + ">")
+			return pos + 1, nil
+		case tokenOpenStartElement:
+			pos, err = r.element(w, pos, subs)
+		case tokenTemplateInstance:
+			pos, err = r.templateInstance(w, pos)
+		default:
+			pos, err = r.content(w, pos, subs)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (r *renderer) attribute(w io.StringWriter, pos int, subs []substitution) (int, error) {
+	tok, err := r.byte(pos)
+	if err != nil {
+		return 0, err
+	}
+	if tok&^tokenHasMoreData != tokenAttribute {
+		return 0, fmt.Errorf("unexpected token 0x%02x in attribute list", tok)
+	}
+	nameOffset, err := r.uint32(pos + 1)
+	if err != nil {
+		return 0, err
+	}
+	name, pos, err := r.name(int(nameOffset), pos+5)
+	if err != nil {
+		return 0, err
+	}
+
+	var value strings.Builder
+	omit := false
+	for {
+		tok, err := r.byte(pos)
+		if err != nil {
+			return 0, err
+		}
+		switch tok &^ tokenHasMoreData {
+		case tokenValue, tokenNormalSubstitution, tokenCharRef, tokenEntityRef:
+		case tokenOptionalSubstitution:
+			// Attributes with an optional substitution without a value are omitted.
+			id, err := r.uint16(pos + 1)
+			if err != nil {
+				return 0, err
+			}
+			if int(id) >= len(subs) || subs[id].null() {
+				omit = true
+			}
+		default:
+			if !omit {
+				_, _ = w.WriteString(" " + name + `="` + value.String() + `"`)
+			}
+			return pos, nil
+		}
+		if pos, err = r.content(&value, pos, subs); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// content renders a node that is not an element, such as a value or a substitution.
+func (r *renderer) content(w io.StringWriter, pos int, subs []substitution) (int, error) {
+	tok, err := r.byte(pos)
+	if err != nil {
+		return 0, err
+	}
+	switch tok &^ tokenHasMoreData {
+	case tokenValue:
+		typ, err := r.byte(pos + 1)
+		if err != nil {
+			return 0, err
+		}
+		if typ != typeString {
+			return 0, fmt.Errorf("unsupported value type 0x%02x in value token", typ)
+		}
+		s, next, err := r.prefixedString(pos + 2)
+		if err != nil {
+			return 0, err
+		}
+		_, _ = w.WriteString(escape(s))
+		return next, nil
+	case tokenNormalSubstitution, tokenOptionalSubstitution:
+		id, err := r.uint16(pos + 1)
+		if err != nil {
+			return 0, err
+		}
+		if int(id) < len(subs) {
+			if err := r.substitution(w, subs[id]); err != nil {
+				return 0, err
+			}
+		}
+		return pos + 4, nil
+	case tokenCharRef:
+		v, err := r.uint16(pos + 1)
+		if err != nil {
+			return 0, err
+		}
+		_, _ = w.WriteString(fmt.Sprintf("&#%d;", v))
+		return pos + 3, nil
+	case tokenEntityRef:
+		nameOffset, err := r.uint32(pos + 1)
+		if err != nil {
+			return 0, err
+		}
+		name, next, err := r.name(int(nameOffset), pos+5)
+		if err != nil {
+			return 0, err
+		}
+		_, _ = w.WriteString("&" + name + ";")
+		return next, nil
+	case tokenCDATASection:
+		s, next, err := r.prefixedString(pos + 1)
+		if err != nil {
+			return 0, err
+		}
+		_, _ = w.WriteString("<![CDATA[" + s + "]]>")
+		return next, nil
+	case tokenPITarget:
+		nameOffset, err := r.uint32(pos + 1)
+		if err != nil {
+			return 0, err
+		}
+		name, next, err := r.name(int(nameOffset), pos+5)
+		if err != nil {
+			return 0, err
+		}
+		_, _ = w.WriteString("<?" + name)
+		return next, nil
+	case tokenPIData:
+		s, next, err := r.prefixedString(pos + 1)
+		if err != nil {
+			return 0, err
+		}
+		_, _ = w.WriteString(" " + s + "?>")
+		return next, nil
+	default:
+		return 0, fmt.Errorf("unexpected token 0x%02x at offset %d", tok, pos)
+	}
+}
+
+// templateInstance renders a template with the substitution values that follow it.
+func (r *renderer) templateInstance(w io.StringWriter, pos int) (int, error) {
+	defOffset, err := r.uint32(pos + 6)
+	if err != nil {
+		return 0, err
+	}
+	next := pos + 10
+	dataSize, err := r.uint32(int(defOffset) + 20)
+	if err != nil {
+		return 0, err
+	}
+	if int(defOffset) > pos {
+		// The template definition is resident and directly follows the instance.
+		next = int(defOffset) + 24 + int(dataSize)
+	}
+
+	count, err := r.uint32(next)
+	if err != nil {
+		return 0, err
+	}
+	next += 4
+	if int(count) > (len(r.chunk)-next)/4 {
+		return 0, errTruncated
+	}
+	subs := make([]substitution, count)
+	dataOffset := next + 4*int(count)
+	for i := range subs {
+		size, err := r.uint16(next)
+		if err != nil {
+			return 0, err
+		}
+		typ, err := r.byte(next + 2)
+		if err != nil {
+			return 0, err
+		}
+		subs[i] = substitution{typ: typ, offset: dataOffset, size: int(size)}
+		dataOffset += int(size)
+		next += 4
+	}
+	if dataOffset > len(r.chunk) {
+		return 0, errTruncated
+	}
+
+	if _, err := r.fragment(w, int(defOffset)+24, subs); err != nil {
+		return 0, fmt.Errorf("template at offset %d: %w", defOffset, err)
+	}
+	return dataOffset, nil
+}
+
+func (r *renderer) substitution(w io.StringWriter, s substitution) error {
+	if s.null() {
+		return nil
+	}
+	data := r.chunk[s.offset : s.offset+s.size]
+	if s.typ == typeBinXML {
+		end := r.end
+		r.end = s.offset + s.size
+		defer func() { r.end = end }()
+		_, err := r.fragment(w, s.offset, nil)
+		return err
+	}
+	v, err := formatValue(s.typ, data)
+	if err != nil {
+		return err
+	}
+	_, _ = w.WriteString(escape(v))
+	return nil
+}
+
+// name returns the name at offset. Names are defined the first time they are
+// used, in which case the name directly follows and pos is advanced past it.
+func (r *renderer) name(offset, pos int) (string, int, error) {
+	if offset != pos {
+		if name, ok := r.names[offset]; ok {
+			return name, pos, nil
+		}
+	}
+	length, err := r.uint16(offset + 6)
+	if err != nil {
+		return "", 0, err
+	}
+	end := offset + 8 + 2*int(length)
+	if end > len(r.chunk) {
+		return "", 0, errTruncated
+	}
+	name := decodeUTF16(r.chunk[offset+8 : end])
+	r.names[offset] = name
+	if offset == pos {
+		// Skip the name and its terminating null character.
+		pos = end + 2
+	}
+	return name, pos, nil
+}
+
+// prefixedString reads a UTF-16 string prefixed with its number of characters.
+func (r *renderer) prefixedString(pos int) (string, int, error) {
+	length, err := r.uint16(pos)
+	if err != nil {
+		return "", 0, err
+	}
+	end := pos + 2 + 2*int(length)
+	if end > len(r.chunk) {
+		return "", 0, errTruncated
+	}
+	return decodeUTF16(r.chunk[pos+2 : end]), end, nil
+}
+
+func (r *renderer) byte(pos int) (byte, error) {
+	if pos < 0 || pos >= len(r.chunk) {
+		return 0, errTruncated
+	}
+	return r.chunk[pos], nil
+}
+
+func (r *renderer) uint16(pos int) (uint16, error) {
+	if pos < 0 || pos+2 > len(r.chunk) {
+		return 0, errTruncated
+	}
+	return binary.LittleEndian.Uint16(r.chunk[pos:]), nil
+}
+
+func (r *renderer) uint32(pos int) (uint32, error) {
+	if pos < 0 || pos+4 > len(r.chunk) {
+		return 0, errTruncated
+	}
+	return binary.LittleEndian.Uint32(r.chunk[pos:]), nil
+}
+
+func decodeUTF16(b []byte) string {
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(u))
+}
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// escape escapes text and attribute values the way the event log API renders them.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+type discard struct{}
+
+func (discard) WriteString(s string) (int, error) {
+	return len(s), nil
+}
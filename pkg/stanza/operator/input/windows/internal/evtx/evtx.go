@@ -0,0 +1,171 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package evtx reads Windows XML Event Log (EVTX) files and renders their
+// records as event XML, independently of the Windows event log API.
+package evtx // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/windows/internal/evtx"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	fileHeaderSize  = 4096
+	chunkSize       = 65536
+	chunkHeaderSize = 512
+	recordHeader    = 24
+)
+
+var (
+	fileSignature   = []byte("ElfFile\x00")
+	chunkSignature  = []byte("ElfChnk\x00")
+	recordSignature = []byte{0x2a, 0x2a, 0x00, 0x00}
+
+	errNotEVTX = errors.New("not an evtx file")
+)
+
+// Record is an event record with its rendered event XML.
+type Record struct {
+	ID      uint64
+	Written time.Time
+	XML     string
+}
+
+// File is an open EVTX file.
+type File struct {
+	r      io.ReaderAt
+	size   int64
+	closer io.Closer
+}
+
+// Open opens the EVTX file at path.
+func Open(path string) (*File, error) {
+	f, err := os.Open(path) // #nosec G304 - evtx files are configured by the user
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	file, err := NewFile(f, info.Size())
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	file.closer = f
+	return file, nil
+}
+
+// NewFile reads an EVTX file of the given size from r.
+func NewFile(r io.ReaderAt, size int64) (*File, error) {
+	header := make([]byte, len(fileSignature))
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("%w: %w", errNotEVTX, err)
+	}
+	if !bytes.Equal(header, fileSignature) {
+		return nil, fmt.Errorf("%w: invalid signature", errNotEVTX)
+	}
+	return &File{r: r, size: size}, nil
+}
+
+// Close closes the underlying file, if it was opened by Open.
+func (f *File) Close() error {
+	if f.closer == nil {
+		return nil
+	}
+	return f.closer.Close()
+}
+
+// Records calls fn for every record with an identifier greater than after, in
+// file order. Chunks that are not in use, as in files that were not closed
+// cleanly, are skipped.
+func (f *File) Records(after uint64, fn func(Record) error) error {
+	buf := make([]byte, chunkSize)
+	for offset := int64(fileHeaderSize); offset+chunkSize <= f.size; offset += chunkSize {
+		if _, err := f.r.ReadAt(buf, offset); err != nil {
+			return fmt.Errorf("read chunk at %d: %w", offset, err)
+		}
+		if !bytes.Equal(buf[:len(chunkSignature)], chunkSignature) {
+			continue
+		}
+		if lastID := binary.LittleEndian.Uint64(buf[32:]); lastID <= after {
+			continue
+		}
+		if err := readChunk(buf, after, fn); err != nil {
+			return fmt.Errorf("chunk at %d: %w", offset, err)
+		}
+	}
+	return nil
+}
+
+// LastRecordID returns the identifier of the last record in the file, or zero if
+// the file has no records.
+func (f *File) LastRecordID() (uint64, error) {
+	var last uint64
+	header := make([]byte, 40)
+	for offset := int64(fileHeaderSize); offset+chunkSize <= f.size; offset += chunkSize {
+		if _, err := f.r.ReadAt(header, offset); err != nil {
+			return 0, fmt.Errorf("read chunk header at %d: %w", offset, err)
+		}
+		if !bytes.Equal(header[:len(chunkSignature)], chunkSignature) {
+			continue
+		}
+		last = max(last, binary.LittleEndian.Uint64(header[32:]))
+	}
+	return last, nil
+}
+
+func readChunk(chunk []byte, after uint64, fn func(Record) error) error {
+	le := binary.LittleEndian
+	freeSpace := int(le.Uint32(chunk[48:]))
+	if freeSpace > len(chunk) || freeSpace < chunkHeaderSize {
+		freeSpace = len(chunk)
+	}
+	names := map[int]string{}
+
+	for pos := chunkHeaderSize; pos+recordHeader <= freeSpace; {
+		if !bytes.Equal(chunk[pos:pos+4], recordSignature) {
+			return nil
+		}
+		size := int(le.Uint32(chunk[pos+4:]))
+		if size < recordHeader+4 || pos+size > len(chunk) {
+			return fmt.Errorf("invalid record size %d at offset %d", size, pos)
+		}
+		id := le.Uint64(chunk[pos+8:])
+		if id > after {
+			r := &renderer{chunk: chunk, names: names, end: pos + size - 4}
+			xml, err := r.render(pos + recordHeader)
+			if err != nil {
+				return fmt.Errorf("record %d: %w", id, err)
+			}
+			record := Record{
+				ID:      id,
+				Written: filetime(le.Uint64(chunk[pos+16:])),
+				XML:     xml,
+			}
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		pos += size
+	}
+	return nil
+}
+
+// filetime converts a FILETIME, the number of 100ns intervals since 1601-01-01, to a time.
+func filetime(ft uint64) time.Time {
+	const epochDelta = 116444736000000000 // 1601-01-01 to 1970-01-01 in 100ns intervals
+	if ft < epochDelta {
+		return time.Time{}
+	}
+	ticks := ft - epochDelta
+	return time.Unix(int64(ticks/1e7), int64(ticks%1e7)*100).UTC()
+}
@@ -0,0 +1,206 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package evtx
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/windows/internal/evtx/evtxtest"
+)
+
+var testTime = time.Date(2024, 3, 4, 5, 6, 7, 123456700, time.UTC)
+
+func testEvent(id uint64) evtxtest.Event {
+	qualifiers := uint16(16384)
+	return evtxtest.Event{
+		Provider:     "Microsoft-Windows-Security-Auditing",
+		ProviderGUID: "{54849625-5478-4994-A5BA-3E3B0328C30D}",
+		Qualifiers:   &qualifiers,
+		EventID:      4624,
+		Level:        0,
+		Task:         12544,
+		Opcode:       0,
+		Keywords:     0x8020000000000000,
+		TimeCreated:  testTime.Add(time.Duration(id) * time.Second),
+		RecordID:     id,
+		ProcessID:    712,
+		ThreadID:     2948,
+		Channel:      "Security",
+		Computer:     "WIN-HOST",
+		UserID:       "S-1-5-18",
+		Data:         [2]string{"WIN-HOST$", "alice & <bob>"},
+	}
+}
+
+func readRecords(t *testing.T, path string, after uint64) []Record {
+	f, err := Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var records []Record
+	require.NoError(t, f.Records(after, func(r Record) error {
+		records = append(records, r)
+		return nil
+	}))
+	return records
+}
+
+func TestRecords(t *testing.T) {
+	tmpl := evtxtest.EventTemplate()
+	withoutUser := testEvent(2)
+	withoutUser.UserID = ""
+	withoutUser.ProviderGUID = ""
+	withoutUser.Qualifiers = nil
+
+	path := filepath.Join(t.TempDir(), "Security.evtx")
+	require.NoError(t, evtxtest.Write(path,
+		[]evtxtest.Record{testEvent(1).Record(tmpl), withoutUser.Record(tmpl)},
+		[]evtxtest.Record{testEvent(3).Record(tmpl)},
+	))
+
+	records := readRecords(t, path, 0)
+	require.Len(t, records, 3)
+
+	assert.Equal(t, uint64(1), records[0].ID)
+	assert.Equal(t, testTime.Add(time.Second), records[0].Written)
+	assert.Equal(t, `<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event">`+
+		`<System>`+
+		`<Provider Name="Microsoft-Windows-Security-Auditing" Guid="{54849625-5478-4994-A5BA-3E3B0328C30D}"/>`+
+		`<EventID Qualifiers="16384">4624</EventID>`+
+		`<Level>0</Level>`+
+		`<Task>12544</Task>`+
+		`<Opcode>0</Opcode>`+
+		`<Keywords>0x8020000000000000</Keywords>`+
+		`<TimeCreated SystemTime="2024-03-04T05:06:08.1234567Z"/>`+
+		`<EventRecordID>1</EventRecordID>`+
+		`<Execution ProcessID="712" ThreadID="2948"/>`+
+		`<Channel>Security</Channel>`+
+		`<Computer>WIN-HOST</Computer>`+
+		`<Security UserID="S-1-5-18"/>`+
+		`</System>`+
+		`<EventData>`+
+		`<Data Name="SubjectUserName">WIN-HOST$</Data>`+
+		`<Data Name="TargetUserName">alice &amp; &lt;bob&gt;</Data>`+
+		`</EventData>`+
+		`</Event>`, records[0].XML)
+
+	// The second record references the template defined by the first one, and
+	// omits the attributes and elements without values.
+	assert.Contains(t, records[1].XML, `<Provider Name="Microsoft-Windows-Security-Auditing"/><EventID>4624</EventID>`)
+	assert.NotContains(t, records[1].XML, "<Security")
+	assert.Contains(t, records[1].XML, "<EventRecordID>2</EventRecordID>")
+
+	// The third record is in a second chunk, which defines the template again.
+	assert.Contains(t, records[2].XML, "<EventRecordID>3</EventRecordID>")
+	for _, r := range records {
+		require.NoError(t, xml.Unmarshal([]byte(r.XML), new(any)), r.XML)
+	}
+
+	f, err := Open(path)
+	require.NoError(t, err)
+	last, err := f.LastRecordID()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), last)
+	require.NoError(t, f.Close())
+
+	records = readRecords(t, path, 2)
+	require.Len(t, records, 1)
+	assert.Equal(t, uint64(3), records[0].ID)
+}
+
+// TestExportedFiles reads the event log files exported by Windows in testdata.
+func TestExportedFiles(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "*.evtx"))
+	require.NoError(t, err)
+	if len(paths) == 0 {
+		t.Skip("no exported event log file in testdata")
+	}
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			expected, err := os.ReadFile(strings.TrimSuffix(path, ".evtx") + ".xml")
+			require.NoError(t, err)
+			var events []string
+			for _, event := range strings.Split(string(expected), "\n") {
+				if event = strings.TrimSpace(event); event != "" {
+					events = append(events, event)
+				}
+			}
+
+			records := readRecords(t, path, 0)
+			xmls := make([]string, 0, len(records))
+			for _, r := range records {
+				xmls = append(xmls, r.XML)
+			}
+			assert.Equal(t, events, xmls)
+		})
+	}
+}
+
+func TestNotEVTX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.evtx")
+	require.NoError(t, os.WriteFile(path, []byte("ElfChnk\x00 not a file header"), 0o600))
+	_, err := Open(path)
+	assert.ErrorContains(t, err, "not an evtx file")
+}
+
+func TestTruncatedRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.evtx")
+	require.NoError(t, evtxtest.Write(path, []evtxtest.Record{testEvent(1).Record(evtxtest.EventTemplate())}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	// Point the template definition of the first record outside of the chunk.
+	copy(data[fileHeaderSize+chunkHeaderSize+recordHeader+10:], []byte{0xf0, 0xff, 0x00, 0x00})
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	f, err := Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	err = f.Records(0, func(Record) error { return nil })
+	assert.ErrorContains(t, err, "record 1")
+}
+
+func TestFormatValue(t *testing.T) {
+	testCases := []struct {
+		name     string
+		typ      uint8
+		data     []byte
+		expected string
+	}{
+		{name: "int8", typ: typeInt8, data: []byte{0xff}, expected: "-1"},
+		{name: "int32", typ: typeInt32, data: []byte{0xfe, 0xff, 0xff, 0xff}, expected: "-2"},
+		{name: "bool", typ: typeBool, data: []byte{1, 0, 0, 0}, expected: "true"},
+		{name: "binary", typ: typeBinary, data: []byte{0xab, 0x01}, expected: "AB01"},
+		{name: "hexint32", typ: typeHexInt32, data: []byte{0x10, 0, 0, 0}, expected: "0x10"},
+		{name: "sizet", typ: typeSizeT, data: []byte{0xff, 0, 0, 0, 0, 0, 0, 0}, expected: "0xff"},
+		{name: "ansi string", typ: typeAnsiString, data: []byte("abc\x00"), expected: "abc"},
+		{name: "real64", typ: typeReal64, data: []byte{0, 0, 0, 0, 0, 0, 0xf8, 0x3f}, expected: "1.5"},
+		{
+			name:     "systemtime",
+			typ:      typeSystemTime,
+			data:     []byte{0xe8, 0x07, 3, 0, 1, 0, 4, 0, 5, 0, 6, 0, 7, 0, 0x7b, 0},
+			expected: "2024-03-04T05:06:07.1230000Z",
+		},
+		{name: "string array", typ: typeArray | typeString, data: evtxtest.String("a\x00b\x00").Data, expected: "a,b"},
+		{name: "uint16 array", typ: typeArray | typeUint16, data: []byte{1, 0, 2, 0}, expected: "1,2"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := formatValue(tc.typ, tc.data)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, v)
+		})
+	}
+
+	_, err := formatValue(typeUint32, []byte{1})
+	assert.Error(t, err)
+	_, err = formatValue(0x7f, []byte{1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,433 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package evtxtest writes EVTX files for tests. Records are encoded the way the
+// event log service does: every record is a template instance whose template is
+// defined in the chunk the first time it is used.
+package evtxtest // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/windows/internal/evtx/evtxtest"
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	fileHeaderSize  = 4096
+	chunkSize       = 65536
+	chunkHeaderSize = 512
+)
+
+var le = binary.LittleEndian
+
+// Value is a substitution value of a template instance.
+type Value struct {
+	Type uint8
+	Data []byte
+}
+
+// Null is a substitution without a value.
+var Null = Value{}
+
+// String returns a string value.
+func String(s string) Value {
+	return Value{Type: 0x01, Data: utf16Bytes(s)}
+}
+
+// Uint8 returns an unsigned 8-bit value.
+func Uint8(v uint8) Value {
+	return Value{Type: 0x04, Data: []byte{v}}
+}
+
+// Uint16 returns an unsigned 16-bit value.
+func Uint16(v uint16) Value {
+	return Value{Type: 0x06, Data: le.AppendUint16(nil, v)}
+}
+
+// Uint32 returns an unsigned 32-bit value.
+func Uint32(v uint32) Value {
+	return Value{Type: 0x08, Data: le.AppendUint32(nil, v)}
+}
+
+// Uint64 returns an unsigned 64-bit value.
+func Uint64(v uint64) Value {
+	return Value{Type: 0x0a, Data: le.AppendUint64(nil, v)}
+}
+
+// HexInt64 returns a 64-bit value rendered in hexadecimal.
+func HexInt64(v uint64) Value {
+	return Value{Type: 0x15, Data: le.AppendUint64(nil, v)}
+}
+
+// FileTime returns a FILETIME value.
+func FileTime(t time.Time) Value {
+	return Value{Type: 0x11, Data: le.AppendUint64(nil, filetime(t))}
+}
+
+// GUID returns a GUID value from its {XXXXXXXX-XXXX-XXXX-XXXX-XXXXXXXXXXXX} form.
+func GUID(s string) Value {
+	s = strings.Trim(s, "{}")
+	parts := strings.Split(s, "-")
+	parse := func(p string) uint64 {
+		v, _ := strconv.ParseUint(p, 16, 64)
+		return v
+	}
+	b := le.AppendUint32(nil, uint32(parse(parts[0])))
+	b = le.AppendUint16(b, uint16(parse(parts[1])))
+	b = le.AppendUint16(b, uint16(parse(parts[2])))
+	b = binary.BigEndian.AppendUint16(b, uint16(parse(parts[3])))
+	tail := parse(parts[4])
+	for i := 5; i >= 0; i-- {
+		b = append(b, byte(tail>>(8*i)))
+	}
+	return Value{Type: 0x0f, Data: b}
+}
+
+// SID returns a security identifier value from its S-R-I-S... form.
+func SID(s string) Value {
+	parts := strings.Split(s, "-")
+	revision, _ := strconv.Atoi(parts[1])
+	authority, _ := strconv.ParseUint(parts[2], 10, 48)
+	b := []byte{byte(revision), byte(len(parts) - 3)}
+	for i := 5; i >= 0; i-- {
+		b = append(b, byte(authority>>(8*i)))
+	}
+	for _, p := range parts[3:] {
+		v, _ := strconv.ParseUint(p, 10, 32)
+		b = le.AppendUint32(b, uint32(v))
+	}
+	return Value{Type: 0x13, Data: b}
+}
+
+// Node is a node of a template.
+type Node interface {
+	encode(c *chunk)
+}
+
+// Attr is an attribute of an element.
+type Attr struct {
+	Name  string
+	Value Node
+}
+
+// Element is an element of a template. Elements with a dependency are only
+// rendered if the substitution with that index has a value.
+type Element struct {
+	Name       string
+	Attrs      []Attr
+	Children   []Node
+	Dependency *uint16
+}
+
+// Text is a literal text value.
+type Text string
+
+// Sub is a substitution of the value with the given index.
+type Sub struct {
+	Index    uint16
+	Type     uint8
+	Optional bool
+}
+
+// Template is a template definition with a unique ID.
+type Template struct {
+	ID   [16]byte
+	Root Element
+}
+
+// Record is an event record as an instance of a template.
+type Record struct {
+	ID       uint64
+	Written  time.Time
+	Template *Template
+	Values   []Value
+}
+
+type chunk struct {
+	buf       []byte
+	pos       int
+	names     map[string]int
+	templates map[*Template]int
+}
+
+func (c *chunk) bytes(b ...byte) {
+	copy(c.buf[c.pos:], b)
+	c.pos += len(b)
+}
+
+func (c *chunk) u16(v uint16) { c.bytes(le.AppendUint16(nil, v)...) }
+func (c *chunk) u32(v uint32) { c.bytes(le.AppendUint32(nil, v)...) }
+
+// name writes a reference to a name, defining it in place if it was not used before.
+func (c *chunk) name(name string) {
+	if offset, ok := c.names[name]; ok {
+		c.u32(uint32(offset))
+		return
+	}
+	offset := c.pos + 4
+	c.names[name] = offset
+	c.u32(uint32(offset))
+	c.u32(0) // next offset in the common string table
+	c.u16(0) // hash
+	c.u16(uint16(len(utf16.Encode([]rune(name)))))
+	c.bytes(utf16Bytes(name)...)
+	c.u16(0)
+}
+
+func (e Element) encode(c *chunk) {
+	token := byte(0x01)
+	if len(e.Attrs) > 0 {
+		token |= 0x40
+	}
+	c.bytes(token)
+	dependency := uint16(0xffff)
+	if e.Dependency != nil {
+		dependency = *e.Dependency
+	}
+	c.u16(dependency)
+	sizePos := c.pos
+	c.u32(0)
+	c.name(e.Name)
+	start := c.pos
+	if len(e.Attrs) > 0 {
+		attrSizePos := c.pos
+		c.u32(0)
+		attrStart := c.pos
+		for i, a := range e.Attrs {
+			token := byte(0x06)
+			if i < len(e.Attrs)-1 {
+				token |= 0x40
+			}
+			c.bytes(token)
+			c.name(a.Name)
+			a.Value.encode(c)
+		}
+		le.PutUint32(c.buf[attrSizePos:], uint32(c.pos-attrStart))
+	}
+	if len(e.Children) == 0 {
+		c.bytes(0x03)
+	} else {
+		c.bytes(0x02)
+		for _, child := range e.Children {
+			child.encode(c)
+		}
+		c.bytes(0x04)
+	}
+	le.PutUint32(c.buf[sizePos:], uint32(c.pos-start))
+}
+
+func (t Text) encode(c *chunk) {
+	c.bytes(0x05, 0x01)
+	c.u16(uint16(len(utf16.Encode([]rune(string(t))))))
+	c.bytes(utf16Bytes(string(t))...)
+}
+
+func (s Sub) encode(c *chunk) {
+	token := byte(0x0d)
+	if s.Optional {
+		token = 0x0e
+	}
+	c.bytes(token)
+	c.u16(s.Index)
+	c.bytes(s.Type)
+}
+
+func (c *chunk) record(r Record) {
+	start := c.pos
+	c.bytes(0x2a, 0x2a, 0x00, 0x00)
+	c.u32(0)
+	c.bytes(le.AppendUint64(nil, r.ID)...)
+	c.bytes(le.AppendUint64(nil, filetime(r.Written))...)
+
+	c.bytes(0x0f, 0x01, 0x01, 0x00)
+	c.bytes(0x0c, 0x01)
+	c.bytes(r.Template.ID[:4]...)
+	if offset, ok := c.templates[r.Template]; ok {
+		c.u32(uint32(offset))
+	} else {
+		offset = c.pos + 4
+		c.templates[r.Template] = offset
+		c.u32(uint32(offset))
+		c.u32(0) // next template offset
+		c.bytes(r.Template.ID[:]...)
+		sizePos := c.pos
+		c.u32(0)
+		dataStart := c.pos
+		c.bytes(0x0f, 0x01, 0x01, 0x00)
+		r.Template.Root.encode(c)
+		c.bytes(0x00)
+		le.PutUint32(c.buf[sizePos:], uint32(c.pos-dataStart))
+	}
+
+	c.u32(uint32(len(r.Values)))
+	for _, v := range r.Values {
+		c.u16(uint16(len(v.Data)))
+		c.bytes(v.Type, 0)
+	}
+	for _, v := range r.Values {
+		c.bytes(v.Data...)
+	}
+	c.bytes(0x00)
+
+	size := c.pos - start + 4
+	c.u32(uint32(size))
+	le.PutUint32(c.buf[start+4:], uint32(size))
+}
+
+// Write writes the records to an EVTX file at path, one chunk per slice of records.
+func Write(path string, chunks ...[]Record) error {
+	out := make([]byte, fileHeaderSize)
+	var nextID uint64 = 1
+	for _, records := range chunks {
+		c := &chunk{
+			buf:       make([]byte, chunkSize),
+			pos:       chunkHeaderSize,
+			names:     map[string]int{},
+			templates: map[*Template]int{},
+		}
+		copy(c.buf, "ElfChnk\x00")
+		var lastRecord int
+		for _, r := range records {
+			lastRecord = c.pos
+			c.record(r)
+			nextID = r.ID + 1
+		}
+		if len(records) > 0 {
+			first, last := records[0].ID, records[len(records)-1].ID
+			le.PutUint64(c.buf[8:], first)
+			le.PutUint64(c.buf[16:], last)
+			le.PutUint64(c.buf[24:], first)
+			le.PutUint64(c.buf[32:], last)
+		}
+		le.PutUint32(c.buf[40:], 128)
+		le.PutUint32(c.buf[44:], uint32(lastRecord))
+		le.PutUint32(c.buf[48:], uint32(c.pos))
+		le.PutUint32(c.buf[52:], crc32.ChecksumIEEE(c.buf[chunkHeaderSize:c.pos]))
+		headerCRC := crc32.NewIEEE()
+		_, _ = headerCRC.Write(c.buf[:120])
+		_, _ = headerCRC.Write(c.buf[128:chunkHeaderSize])
+		le.PutUint32(c.buf[124:], headerCRC.Sum32())
+		out = append(out, c.buf...)
+	}
+
+	copy(out, "ElfFile\x00")
+	le.PutUint64(out[8:], 0)
+	le.PutUint64(out[16:], uint64(len(chunks)-1))
+	le.PutUint64(out[24:], nextID)
+	le.PutUint32(out[32:], 128)
+	le.PutUint16(out[36:], 1)
+	le.PutUint16(out[38:], 3)
+	le.PutUint16(out[40:], fileHeaderSize)
+	le.PutUint16(out[42:], uint16(len(chunks)))
+	le.PutUint32(out[124:], crc32.ChecksumIEEE(out[:120]))
+	return os.WriteFile(path, out, 0o600)
+}
+
+func utf16Bytes(s string) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = le.AppendUint16(b, u)
+	}
+	return b
+}
+
+func filetime(t time.Time) uint64 {
+	return uint64(t.UnixNano()/100) + 116444736000000000
+}
+
+// Event holds the values of the EventTemplate substitutions.
+type Event struct {
+	Provider     string
+	ProviderGUID string
+	Qualifiers   *uint16
+	EventID      uint16
+	Level        uint8
+	Task         uint16
+	Opcode       uint8
+	Keywords     uint64
+	TimeCreated  time.Time
+	RecordID     uint64
+	ProcessID    uint32
+	ThreadID     uint32
+	Channel      string
+	Computer     string
+	UserID       string
+	Data         [2]string
+}
+
+// EventTemplate returns a template similar to those of manifest based providers,
+// with two named event data values.
+func EventTemplate() *Template {
+	sub := func(index uint16, typ uint8) Sub { return Sub{Index: index, Type: typ} }
+	opt := func(index uint16, typ uint8) Sub { return Sub{Index: index, Type: typ, Optional: true} }
+	securityDependency := uint16(14)
+	return &Template{
+		ID: [16]byte{0xde, 0xad, 0xbe, 0xef, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+		Root: Element{
+			Name:  "Event",
+			Attrs: []Attr{{Name: "xmlns", Value: Text("http://schemas.microsoft.com/win/2004/08/events/event")}},
+			Children: []Node{
+				Element{Name: "System", Children: []Node{
+					Element{Name: "Provider", Attrs: []Attr{{Name: "Name", Value: sub(0, 0x01)}, {Name: "Guid", Value: opt(1, 0x0f)}}},
+					Element{Name: "EventID", Attrs: []Attr{{Name: "Qualifiers", Value: opt(2, 0x06)}}, Children: []Node{sub(3, 0x06)}},
+					Element{Name: "Level", Children: []Node{sub(4, 0x04)}},
+					Element{Name: "Task", Children: []Node{sub(5, 0x06)}},
+					Element{Name: "Opcode", Children: []Node{sub(6, 0x04)}},
+					Element{Name: "Keywords", Children: []Node{sub(7, 0x15)}},
+					Element{Name: "TimeCreated", Attrs: []Attr{{Name: "SystemTime", Value: sub(8, 0x11)}}},
+					Element{Name: "EventRecordID", Children: []Node{sub(9, 0x0a)}},
+					Element{Name: "Execution", Attrs: []Attr{{Name: "ProcessID", Value: sub(10, 0x08)}, {Name: "ThreadID", Value: sub(11, 0x08)}}},
+					Element{Name: "Channel", Children: []Node{sub(12, 0x01)}},
+					Element{Name: "Computer", Children: []Node{sub(13, 0x01)}},
+					Element{Name: "Security", Attrs: []Attr{{Name: "UserID", Value: opt(14, 0x13)}}, Dependency: &securityDependency},
+				}},
+				Element{Name: "EventData", Children: []Node{
+					Element{Name: "Data", Attrs: []Attr{{Name: "Name", Value: Text("SubjectUserName")}}, Children: []Node{sub(15, 0x01)}},
+					Element{Name: "Data", Attrs: []Attr{{Name: "Name", Value: Text("TargetUserName")}}, Children: []Node{sub(16, 0x01)}},
+				}},
+			},
+		},
+	}
+}
+
+// Record returns a record of the event as an instance of the template.
+func (e Event) Record(t *Template) Record {
+	guid, qualifiers, user := Null, Null, Null
+	if e.ProviderGUID != "" {
+		guid = GUID(e.ProviderGUID)
+	}
+	if e.Qualifiers != nil {
+		qualifiers = Uint16(*e.Qualifiers)
+	}
+	if e.UserID != "" {
+		user = SID(e.UserID)
+	}
+	return Record{
+		ID:       e.RecordID,
+		Written:  e.TimeCreated,
+		Template: t,
+		Values: []Value{
+			String(e.Provider),
+			guid,
+			qualifiers,
+			Uint16(e.EventID),
+			Uint8(e.Level),
+			Uint16(e.Task),
+			Uint8(e.Opcode),
+			HexInt64(e.Keywords),
+			FileTime(e.TimeCreated),
+			Uint64(e.RecordID),
+			Uint32(e.ProcessID),
+			Uint32(e.ThreadID),
+			String(e.Channel),
+			String(e.Computer),
+			user,
+			String(e.Data[0]),
+			String(e.Data[1]),
+		},
+	}
+}
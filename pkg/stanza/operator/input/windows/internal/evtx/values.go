@@ -0,0 +1,201 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package evtx // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/input/windows/internal/evtx"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Value types of substitutions.
+const (
+	typeNull       = 0x00
+	typeString     = 0x01
+	typeAnsiString = 0x02
+	typeInt8       = 0x03
+	typeUint8      = 0x04
+	typeInt16      = 0x05
+	typeUint16     = 0x06
+	typeInt32      = 0x07
+	typeUint32     = 0x08
+	typeInt64      = 0x09
+	typeUint64     = 0x0a
+	typeReal32     = 0x0b
+	typeReal64     = 0x0c
+	typeBool       = 0x0d
+	typeBinary     = 0x0e
+	typeGUID       = 0x0f
+	typeSizeT      = 0x10
+	typeFileTime   = 0x11
+	typeSystemTime = 0x12
+	typeSID        = 0x13
+	typeHexInt32   = 0x14
+	typeHexInt64   = 0x15
+	typeBinXML     = 0x21
+
+	typeArray = 0x80
+)
+
+// fixedSizes are the sizes of value types that can be used in arrays of fixed size elements.
+var fixedSizes = map[uint8]int{
+	typeInt8:       1,
+	typeUint8:      1,
+	typeInt16:      2,
+	typeUint16:     2,
+	typeInt32:      4,
+	typeUint32:     4,
+	typeInt64:      8,
+	typeUint64:     8,
+	typeReal32:     4,
+	typeReal64:     8,
+	typeBool:       4,
+	typeGUID:       16,
+	typeFileTime:   8,
+	typeSystemTime: 16,
+	typeHexInt32:   4,
+	typeHexInt64:   8,
+}
+
+// formatValue renders a substitution value as the event log API does.
+func formatValue(typ uint8, data []byte) (string, error) {
+	if typ&typeArray != 0 {
+		return formatArray(typ&^typeArray, data)
+	}
+
+	le := binary.LittleEndian
+	if size, ok := fixedSizes[typ]; ok && len(data) < size {
+		return "", fmt.Errorf("value of type 0x%02x is too short: %d bytes", typ, len(data))
+	}
+	switch typ {
+	case typeNull:
+		return "", nil
+	case typeString:
+		return strings.TrimRight(decodeUTF16(data), "\x00"), nil
+	case typeAnsiString:
+		return string(bytes.TrimRight(data, "\x00")), nil
+	case typeInt8:
+		return strconv.FormatInt(int64(int8(data[0])), 10), nil
+	case typeUint8:
+		return strconv.FormatUint(uint64(data[0]), 10), nil
+	case typeInt16:
+		return strconv.FormatInt(int64(int16(le.Uint16(data))), 10), nil
+	case typeUint16:
+		return strconv.FormatUint(uint64(le.Uint16(data)), 10), nil
+	case typeInt32:
+		return strconv.FormatInt(int64(int32(le.Uint32(data))), 10), nil
+	case typeUint32:
+		return strconv.FormatUint(uint64(le.Uint32(data)), 10), nil
+	case typeInt64:
+		return strconv.FormatInt(int64(le.Uint64(data)), 10), nil
+	case typeUint64:
+		return strconv.FormatUint(le.Uint64(data), 10), nil
+	case typeReal32:
+		return strconv.FormatFloat(float64(math.Float32frombits(le.Uint32(data))), 'g', -1, 32), nil
+	case typeReal64:
+		return strconv.FormatFloat(math.Float64frombits(le.Uint64(data)), 'g', -1, 64), nil
+	case typeBool:
+		return strconv.FormatBool(le.Uint32(data) != 0), nil
+	case typeBinary:
+		return strings.ToUpper(hex.EncodeToString(data)), nil
+	case typeGUID:
+		return formatGUID(data), nil
+	case typeSizeT:
+		switch len(data) {
+		case 4:
+			return fmt.Sprintf("0x%x", le.Uint32(data)), nil
+		case 8:
+			return fmt.Sprintf("0x%x", le.Uint64(data)), nil
+		default:
+			return "", fmt.Errorf("invalid size_t value of %d bytes", len(data))
+		}
+	case typeFileTime:
+		return formatTime(filetime(le.Uint64(data))), nil
+	case typeSystemTime:
+		return formatSystemTime(data), nil
+	case typeSID:
+		return formatSID(data)
+	case typeHexInt32:
+		return fmt.Sprintf("0x%x", le.Uint32(data)), nil
+	case typeHexInt64:
+		return fmt.Sprintf("0x%x", le.Uint64(data)), nil
+	default:
+		return "", fmt.Errorf("unsupported value type 0x%02x", typ)
+	}
+}
+
+// formatArray renders an array value as its elements separated by commas.
+func formatArray(typ uint8, data []byte) (string, error) {
+	var items []string
+	switch typ {
+	case typeString:
+		s := strings.TrimRight(decodeUTF16(data), "\x00")
+		items = strings.Split(s, "\x00")
+	case typeAnsiString:
+		items = strings.Split(string(bytes.TrimRight(data, "\x00")), "\x00")
+	default:
+		size, ok := fixedSizes[typ]
+		if !ok {
+			return "", fmt.Errorf("unsupported array value type 0x%02x", typ)
+		}
+		for i := 0; i+size <= len(data); i += size {
+			item, err := formatValue(typ, data[i:i+size])
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+	}
+	return strings.Join(items, ","), nil
+}
+
+func formatGUID(b []byte) string {
+	le := binary.LittleEndian
+	return fmt.Sprintf("{%08X-%04X-%04X-%X-%X}", le.Uint32(b), le.Uint16(b[4:]), le.Uint16(b[6:]), b[8:10], b[10:16])
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.0000000Z")
+}
+
+func formatSystemTime(b []byte) string {
+	le := binary.LittleEndian
+	t := time.Date(
+		int(le.Uint16(b[0:])),
+		time.Month(le.Uint16(b[2:])),
+		int(le.Uint16(b[6:])),
+		int(le.Uint16(b[8:])),
+		int(le.Uint16(b[10:])),
+		int(le.Uint16(b[12:])),
+		int(le.Uint16(b[14:]))*int(time.Millisecond),
+		time.UTC,
+	)
+	return formatTime(t)
+}
+
+// formatSID renders a security identifier in its S-R-I-S... string form.
+func formatSID(b []byte) (string, error) {
+	if len(b) < 8 {
+		return "", fmt.Errorf("invalid sid of %d bytes", len(b))
+	}
+	count := int(b[1])
+	if len(b) < 8+4*count {
+		return "", fmt.Errorf("invalid sid of %d bytes with %d sub authorities", len(b), count)
+	}
+	var authority uint64
+	for _, v := range b[2:8] {
+		authority = authority<<8 | uint64(v)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "S-%d-%d", b[0], authority)
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&sb, "-%d", binary.LittleEndian.Uint32(b[8+4*i:]))
+	}
+	return sb.String(), nil
+}
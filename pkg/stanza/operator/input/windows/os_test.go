@@ -9,11 +9,17 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
 )
 
-func TestWindowsOnly(t *testing.T) {
-	_, ok := operator.Lookup("windows_eventlog_input")
-	require.False(t, ok, "'windows_eventlog_input' should only be available on windows")
+func TestChannelsWindowsOnly(t *testing.T) {
+	builder, ok := operator.Lookup("windows_eventlog_input")
+	require.True(t, ok, "'windows_eventlog_input' should be available to read evtx files")
+
+	cfg := builder().(*Config)
+	cfg.Channel = "System"
+	_, err := cfg.Build(componenttest.NewNopTelemetrySettings())
+	require.ErrorContains(t, err, "reading event log channels is only supported on Windows")
 }
@@ -20,7 +20,7 @@ import (
 
 const (
 	operatorType                       = "container"
-	recombineSourceIdentifier          = "log.file.path"
+	recombineSourceIdentifier          = "log.container.source"
 	recombineIsLastEntry               = "attributes.logtag == 'F'"
 	removeOriginalTimeFieldFeatureFlag = "filelog.container.removeOriginalTimeField"
 )
@@ -47,6 +47,7 @@ func NewConfigWithID(operatorID string) *Config {
 		ParserConfig:            helper.NewParserConfig(operatorID, operatorType),
 		Format:                  "",
 		AddMetadataFromFilePath: true,
+		ContainerdStateDir:      defaultContainerdStateDir,
 		MaxLogSize:              0,
 	}
 }
@@ -55,9 +56,12 @@ func NewConfigWithID(operatorID string) *Config {
 type Config struct {
 	helper.ParserConfig `mapstructure:",squash"`
 
-	Format                  string          `mapstructure:"format"`
-	AddMetadataFromFilePath bool            `mapstructure:"add_metadata_from_filepath"`
-	MaxLogSize              helper.ByteSize `mapstructure:"max_log_size,omitempty"`
+	Format                    string          `mapstructure:"format"`
+	AddMetadataFromFilePath   bool            `mapstructure:"add_metadata_from_filepath"`
+	AddMetadataFromDocker     bool            `mapstructure:"add_metadata_from_docker"`
+	AddMetadataFromContainerd bool            `mapstructure:"add_metadata_from_containerd"`
+	ContainerdStateDir        string          `mapstructure:"containerd_state_dir"`
+	MaxLogSize                helper.ByteSize `mapstructure:"max_log_size,omitempty"`
 }
 
 // Build will build a Container parser operator.
@@ -94,17 +98,26 @@ func (c Config) Build(set component.TelemetrySettings) (operator.Operator, error
 		format:                  c.Format,
 		addMetadataFromFilepath: c.AddMetadataFromFilePath,
 		criConsumers:            &wg,
+		recombineParsers:        make(map[string]operator.Operator, len(criStreams)),
+	}
+	if c.AddMetadataFromDocker {
+		p.dockerMetadata = newMetadataCache(set.Logger, "docker", readDockerMetadata)
+	}
+	if c.AddMetadataFromContainerd {
+		p.containerdMetadata = newMetadataCache(set.Logger, "containerd", newContainerdMetadataReader(c.ContainerdStateDir))
 	}
 
 	cLogEmitter := helper.NewLogEmitter(set, p.consumeEntries)
 	p.criLogEmitter = cLogEmitter
-	recombineParser, err := createRecombine(set, c, cLogEmitter)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create internal recombine config: %w", err)
+	// Partial lines are recombined per stream, as the lines of stdout and stderr can be interleaved.
+	for _, stream := range criStreams {
+		recombineParser, err := createRecombine(set, c, cLogEmitter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create internal recombine config: %w", err)
+		}
+		p.recombineParsers[stream] = recombineParser
 	}
 
-	p.recombineParser = recombineParser
-
 	return p, nil
 }
 
@@ -115,7 +128,7 @@ func (c Config) Build(set component.TelemetrySettings) (operator.Operator, error
 //	combine_with: ""
 //	is_last_entry: attributes.logtag == 'F'
 //	max_log_size: 102400
-//	source_identifier: attributes["log.file.path"]
+//	source_identifier: attributes["log.container.source"]
 //	type: recombine
 func createRecombine(set component.TelemetrySettings, c Config, cLogEmitter *helper.LogEmitter) (operator.Operator, error) {
 	recombineParserCfg := createRecombineConfig(c)
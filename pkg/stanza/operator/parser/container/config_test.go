@@ -110,6 +110,23 @@ func TestConfig(t *testing.T) {
 					return p
 				}(),
 			},
+			{
+				Name: "add_metadata_from_docker",
+				Expect: func() *Config {
+					cfg := NewConfig()
+					cfg.AddMetadataFromDocker = true
+					return cfg
+				}(),
+			},
+			{
+				Name: "add_metadata_from_containerd",
+				Expect: func() *Config {
+					cfg := NewConfig()
+					cfg.AddMetadataFromContainerd = true
+					cfg.ContainerdStateDir = "/host/run/containerd/io.containerd.runtime.v2.task"
+					return cfg
+				}(),
+			},
 		},
 	}.Run(t)
 }
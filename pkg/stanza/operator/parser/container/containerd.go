@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package container // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/parser/container"
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	// defaultContainerdStateDir is the directory in which containerd creates the bundles of the running containers,
+	// in a directory per namespace.
+	defaultContainerdStateDir = "/run/containerd/io.containerd.runtime.v2.task"
+	// containerdBundleConfigFile is the OCI runtime configuration of a container in its bundle.
+	containerdBundleConfigFile = "config.json"
+)
+
+// containerdLogPathMatcher matches the files named after the ID of a containerd container: the
+// /var/log/containers/<pod>_<namespace>_<container>-<id>.log links created by the kubelet, and the
+// <id>-json.log files written by nerdctl, including rotated ones.
+var containerdLogPathMatcher = regexp.MustCompile(`(?:^|[\\/_-])([a-f0-9]{64})(?:-json)?\.log(?:\.\d+)?$`)
+
+// containerdAnnotations maps the annotations set by the CRI plugin of containerd and by nerdctl to resource attributes.
+var containerdAnnotations = map[string][]string{
+	"io.kubernetes.cri.container-name":    {"container.name", "k8s.container.name"},
+	"io.kubernetes.cri.sandbox-name":      {"k8s.pod.name"},
+	"io.kubernetes.cri.sandbox-namespace": {"k8s.namespace.name"},
+	"io.kubernetes.cri.sandbox-uid":       {"k8s.pod.uid"},
+	"nerdctl/name":                        {"container.name"},
+}
+
+// containerdImageAnnotation is the annotation holding the image of a container started by the CRI plugin of containerd.
+const containerdImageAnnotation = "io.kubernetes.cri.image-name"
+
+// containerdBundleConfig holds the fields of the OCI runtime configuration that are added as metadata.
+type containerdBundleConfig struct {
+	Annotations map[string]string `json:"annotations"`
+}
+
+// containerdContainerID returns the ID of the container that wrote the log file at path,
+// if the file is named after it.
+func containerdContainerID(path string) (string, bool) {
+	match := containerdLogPathMatcher.FindStringSubmatch(path)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// newContainerdMetadataReader returns a reader of the metadata of the containers whose bundle is in stateDir.
+func newContainerdMetadataReader(stateDir string) metadataReader {
+	return func(_, containerID string) *containerMetadata {
+		return readContainerdMetadata(stateDir, containerID)
+	}
+}
+
+// readContainerdMetadata reads the metadata of a container from the OCI runtime configuration of its bundle.
+// Bundles only exist while containers run, so the metadata of stopped containers cannot be read.
+func readContainerdMetadata(stateDir, containerID string) *containerMetadata {
+	paths, err := filepath.Glob(filepath.Join(stateDir, "*", containerID, containerdBundleConfigFile))
+	if err != nil {
+		return &containerMetadata{err: err}
+	}
+	if len(paths) == 0 {
+		return &containerMetadata{err: fmt.Errorf("no bundle of container %s in %s", containerID, stateDir)}
+	}
+	if len(paths) > 1 {
+		return &containerMetadata{err: errors.New("the container has a bundle in several namespaces")}
+	}
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		return &containerMetadata{err: err}
+	}
+	var cfg containerdBundleConfig
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return &containerMetadata{err: fmt.Errorf("failed to unmarshal %s: %w", paths[0], err)}
+	}
+
+	attributes := map[string]string{
+		"container.id": containerID,
+	}
+	for annotation, keys := range containerdAnnotations {
+		for _, key := range keys {
+			if value := cfg.Annotations[annotation]; value != "" {
+				attributes[key] = value
+			}
+		}
+	}
+	imageName, imageTag := splitImage(cfg.Annotations[containerdImageAnnotation])
+	attributes["container.image.name"] = imageName
+	removeEmpty(attributes)
+	return &containerMetadata{attributes: attributes, imageTag: imageTag}
+}
@@ -0,0 +1,107 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/entry"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/testutil"
+)
+
+const testBundleConfig = `{
+	"ociVersion": "1.1.0",
+	"process": {"args": ["nginx"]},
+	"annotations": {
+		"io.kubernetes.cri.container-type": "container",
+		"io.kubernetes.cri.container-name": "web",
+		"io.kubernetes.cri.image-name": "registry.local:5000/team/nginx:1.25",
+		"io.kubernetes.cri.sandbox-id": "0123456789abcdef",
+		"io.kubernetes.cri.sandbox-name": "web-7d4b9c",
+		"io.kubernetes.cri.sandbox-namespace": "shop",
+		"io.kubernetes.cri.sandbox-uid": "49cc7c1fd3702c40b2686ea7486091d3"
+	}
+}`
+
+func writeBundleConfig(t *testing.T, namespace, config string) string {
+	stateDir := t.TempDir()
+	dir := filepath.Join(stateDir, namespace, testContainerID)
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, containerdBundleConfigFile), []byte(config), 0o600))
+	return stateDir
+}
+
+func TestContainerdContainerID(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/var/log/containers/web-7d4b9c_shop_web-" + testContainerID + ".log":                                     testContainerID,
+		"/var/lib/nerdctl/1935db59/containers/default/" + testContainerID + "/" + testContainerID + "-json.log":   testContainerID,
+		"/var/lib/nerdctl/1935db59/containers/default/" + testContainerID + "/" + testContainerID + "-json.log.2": testContainerID,
+		"/var/log/pods/ns_pod_49cc7c1fd3702c40b2686ea7486091d3/container/1.log":                                   "",
+		"/var/log/containers/web_shop_web-abc.log":                                                                "",
+	} {
+		id, ok := containerdContainerID(path)
+		assert.Equal(t, expected != "", ok, path)
+		assert.Equal(t, expected, id, path)
+	}
+}
+
+func TestReadContainerdMetadata(t *testing.T) {
+	stateDir := writeBundleConfig(t, "default", `{"annotations": {"nerdctl/name": "web", "nerdctl/platform": "linux/amd64"}}`)
+	metadata := readContainerdMetadata(stateDir, testContainerID)
+	require.NoError(t, metadata.err)
+	assert.Equal(t, map[string]string{"container.id": testContainerID, "container.name": "web"}, metadata.attributes)
+	assert.Empty(t, metadata.imageTag)
+
+	metadata = readContainerdMetadata(t.TempDir(), testContainerID)
+	assert.ErrorContains(t, metadata.err, "no bundle of container "+testContainerID)
+
+	metadata = readContainerdMetadata(writeBundleConfig(t, "k8s.io", "{"), testContainerID)
+	assert.ErrorContains(t, metadata.err, "failed to unmarshal")
+}
+
+func TestProcessWithContainerdMetadata(t *testing.T) {
+	cfg := NewConfigWithID("test_id")
+	cfg.AddMetadataFromContainerd = true
+	cfg.ContainerdStateDir = writeBundleConfig(t, "k8s.io", testBundleConfig)
+	op, err := cfg.Build(componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, op.Stop()) }()
+	p := op.(*Parser)
+	fake := testutil.NewFakeOutput(t)
+	p.OutputOperators = []operator.Operator{fake}
+
+	logPath := "/var/log/containers/web-7d4b9c_shop_web-" + testContainerID + ".log"
+	require.NoError(t, p.Process(context.Background(), &entry.Entry{
+		Body:       `2024-04-13T07:59:37.505201169Z stdout F GET /`,
+		Attributes: map[string]any{"log.file.path": logPath},
+	}))
+	fake.ExpectEntry(t, &entry.Entry{
+		Attributes: map[string]any{
+			"log.iostream":  "stdout",
+			"logtag":        "F",
+			"log.file.path": logPath,
+		},
+		Body: "GET /",
+		Resource: map[string]any{
+			"container.id":         testContainerID,
+			"container.name":       "web",
+			"container.image.name": "registry.local:5000/team/nginx",
+			"container.image.tags": []any{"1.25"},
+			"k8s.container.name":   "web",
+			"k8s.pod.name":         "web-7d4b9c",
+			"k8s.pod.uid":          "49cc7c1fd3702c40b2686ea7486091d3",
+			"k8s.namespace.name":   "shop",
+		},
+		Timestamp: time.Date(2024, time.April, 13, 7, 59, 37, 505201169, time.UTC),
+	})
+}
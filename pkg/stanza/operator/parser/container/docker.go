@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package container // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/parser/container"
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	// dockerConfigFile is the file next to the logs of a container in which the docker engine stores its configuration.
+	dockerConfigFile = "config.v2.json"
+	// dockerLabelPrefix is the prefix of the resource attributes that hold the labels of a container.
+	dockerLabelPrefix = "container.label."
+)
+
+// dockerLogPathMatcher matches the files written by the docker json-file log driver, including rotated ones.
+var dockerLogPathMatcher = regexp.MustCompile(`(?:^|[\\/])([a-f0-9]{64})-json\.log(?:\.\d+)?$`)
+
+// dockerConfig holds the fields of config.v2.json that are added as metadata.
+type dockerConfig struct {
+	ID     string `json:"ID"`
+	Name   string `json:"Name"`
+	Image  string `json:"Image"`
+	Config struct {
+		Image  string            `json:"Image"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+// dockerContainerID returns the ID of the container that wrote the log file at path,
+// if it was written by the docker json-file log driver.
+func dockerContainerID(path string) (string, bool) {
+	match := dockerLogPathMatcher.FindStringSubmatch(path)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// readDockerMetadata reads the metadata of a container from the config.v2.json file next to its logs.
+func readDockerMetadata(logPath, _ string) *containerMetadata {
+	path := filepath.Join(filepath.Dir(logPath), dockerConfigFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return &containerMetadata{err: err}
+	}
+	var cfg dockerConfig
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return &containerMetadata{err: fmt.Errorf("failed to unmarshal %s: %w", path, err)}
+	}
+
+	imageName, imageTag := splitImage(cfg.Config.Image)
+	attributes := map[string]string{
+		"container.id":         cfg.ID,
+		"container.name":       strings.TrimPrefix(cfg.Name, "/"),
+		"container.image.id":   cfg.Image,
+		"container.image.name": imageName,
+	}
+	removeEmpty(attributes)
+	for key, value := range cfg.Config.Labels {
+		attributes[dockerLabelPrefix+key] = value
+	}
+	return &containerMetadata{attributes: attributes, imageTag: imageTag}
+}
+
+// splitImage splits an image reference such as registry:5000/app:1.0 into its name and tag.
+// References by digest have no tag.
+func splitImage(image string) (string, string) {
+	if name, _, ok := strings.Cut(image, "@"); ok {
+		return name, ""
+	}
+	slash := strings.LastIndex(image, "/")
+	if colon := strings.LastIndex(image, ":"); colon > slash {
+		return image[:colon], image[colon+1:]
+	}
+	return image, ""
+}
@@ -0,0 +1,131 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package container
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/entry"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/testutil"
+)
+
+const (
+	testContainerID     = "3f2a9b1c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a"
+	testContainerConfig = `{
+	"ID": "3f2a9b1c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a",
+	"Name": "/web",
+	"Image": "sha256:0123456789abcdef",
+	"Config": {
+		"Image": "registry.local:5000/team/nginx:1.25",
+		"Labels": {"com.docker.compose.service": "web", "empty": ""}
+	}
+}`
+)
+
+func writeContainerConfig(t *testing.T, config string) string {
+	dir := filepath.Join(t.TempDir(), "containers", testContainerID)
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, dockerConfigFile), []byte(config), 0o600))
+	return filepath.Join(dir, testContainerID+"-json.log")
+}
+
+func TestDockerContainerID(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/var/lib/docker/containers/" + testContainerID + "/" + testContainerID + "-json.log":       testContainerID,
+		"/var/lib/docker/containers/" + testContainerID + "/" + testContainerID + "-json.log.3":     testContainerID,
+		`C:\ProgramData\docker\containers\` + testContainerID + `\` + testContainerID + "-json.log": testContainerID,
+		"/var/log/pods/ns_pod_49cc7c1fd3702c40b2686ea7486091d3/container/1.log":                     "",
+		"/var/lib/docker/containers/abc/abc-json.log":                                               "",
+	} {
+		id, ok := dockerContainerID(path)
+		assert.Equal(t, expected != "", ok, path)
+		assert.Equal(t, expected, id, path)
+	}
+}
+
+func TestSplitImage(t *testing.T) {
+	for image, expected := range map[string][2]string{
+		"nginx":                              {"nginx", ""},
+		"nginx:1.25":                         {"nginx", "1.25"},
+		"registry.local:5000/team/nginx":     {"registry.local:5000/team/nginx", ""},
+		"registry.local:5000/team/nginx:1.0": {"registry.local:5000/team/nginx", "1.0"},
+		"nginx@sha256:0123456789abcdef":      {"nginx", ""},
+	} {
+		name, tag := splitImage(image)
+		assert.Equal(t, expected, [2]string{name, tag}, image)
+	}
+}
+
+func TestProcessWithDockerMetadata(t *testing.T) {
+	logPath := writeContainerConfig(t, testContainerConfig)
+
+	cfg := NewConfigWithID("test_id")
+	cfg.AddMetadataFromDocker = true
+	op, err := cfg.Build(componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	defer func() { require.NoError(t, op.Stop()) }()
+	p := op.(*Parser)
+	fake := testutil.NewFakeOutput(t)
+	p.OutputOperators = []operator.Operator{fake}
+
+	for _, path := range []string{logPath, logPath + ".1"} {
+		require.NoError(t, p.Process(context.Background(), &entry.Entry{
+			Body:       `{"log":"GET /\n","stream":"stdout","time":"2029-03-30T08:31:20.545192187Z"}`,
+			Attributes: map[string]any{"log.file.path": path},
+		}))
+		fake.ExpectEntry(t, &entry.Entry{
+			Attributes: map[string]any{
+				"log.iostream":  "stdout",
+				"log.file.path": path,
+			},
+			Body: "GET /\n",
+			Resource: map[string]any{
+				"container.id":                               testContainerID,
+				"container.name":                             "web",
+				"container.image.id":                         "sha256:0123456789abcdef",
+				"container.image.name":                       "registry.local:5000/team/nginx",
+				"container.image.tags":                       []any{"1.25"},
+				"container.label.com.docker.compose.service": "web",
+				"container.label.empty":                      "",
+			},
+			Timestamp: time.Date(2029, time.March, 30, 8, 31, 20, 545192187, time.UTC),
+		})
+	}
+}
+
+func TestDockerMetadataCache(t *testing.T) {
+	logPath := writeContainerConfig(t, "{")
+	configPath := filepath.Join(filepath.Dir(logPath), dockerConfigFile)
+
+	now := time.Now()
+	cache := newMetadataCache(zap.NewNop(), "docker", readDockerMetadata)
+	cache.now = func() time.Time { return now }
+
+	// Entries are sent without metadata if it cannot be read.
+	e := entry.New()
+	require.NoError(t, cache.addMetadata(e, logPath, testContainerID))
+	assert.Nil(t, e.Resource)
+
+	// Failures are retried after an interval.
+	require.NoError(t, os.WriteFile(configPath, []byte(testContainerConfig), 0o600))
+	require.Error(t, cache.get(logPath, testContainerID).err)
+	now = now.Add(metadataRetryInterval)
+	require.NoError(t, cache.get(logPath, testContainerID).err)
+
+	// Metadata that was read is cached.
+	require.NoError(t, os.WriteFile(configPath, []byte(strings.Replace(testContainerConfig, "/web", "/renamed", 1)), 0o600))
+	now = now.Add(time.Hour)
+	assert.Equal(t, "web", cache.get(logPath, testContainerID).attributes["container.name"])
+}
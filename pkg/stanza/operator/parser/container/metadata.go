@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package container // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/parser/container"
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/entry"
+)
+
+const (
+	// metadataCacheSize is the maximum number of containers for which metadata is cached.
+	metadataCacheSize = 1024
+	// metadataRetryInterval is the time after which the metadata of a container is read again if it failed.
+	metadataRetryInterval = time.Minute
+)
+
+// containerMetadata is the metadata of a container, or the error that occurred while reading it.
+type containerMetadata struct {
+	attributes map[string]string
+	imageTag   string
+	err        error
+	readAt     time.Time
+}
+
+// metadataReader reads the metadata of the container with the given ID that wrote the log file at path.
+type metadataReader func(path, containerID string) *containerMetadata
+
+// metadataCache reads and caches the metadata of containers by their ID.
+type metadataCache struct {
+	logger  *zap.Logger
+	runtime string
+	read    metadataReader
+	now     func() time.Time
+
+	mu         sync.Mutex
+	containers map[string]*containerMetadata
+}
+
+func newMetadataCache(logger *zap.Logger, runtime string, read metadataReader) *metadataCache {
+	return &metadataCache{
+		logger:     logger,
+		runtime:    runtime,
+		read:       read,
+		now:        time.Now,
+		containers: map[string]*containerMetadata{},
+	}
+}
+
+// addMetadata adds the metadata of the container that wrote the log file at path
+// to the resource of the entry. Containers whose metadata cannot be read are
+// skipped, so that their logs are still sent.
+func (c *metadataCache) addMetadata(e *entry.Entry, path, containerID string) error {
+	metadata := c.get(path, containerID)
+	if metadata.err != nil {
+		return nil
+	}
+
+	for key, value := range metadata.attributes {
+		if err := entry.NewResourceField(key).Set(e, value); err != nil {
+			return fmt.Errorf("failed to set %v as metadata", key)
+		}
+	}
+	if metadata.imageTag != "" {
+		if err := entry.NewResourceField("container.image.tags").Set(e, []any{metadata.imageTag}); err != nil {
+			return fmt.Errorf("failed to set container.image.tags as metadata")
+		}
+	}
+	return nil
+}
+
+func (c *metadataCache) get(path, containerID string) *containerMetadata {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	metadata, ok := c.containers[containerID]
+	if ok && (metadata.err == nil || now.Sub(metadata.readAt) < metadataRetryInterval) {
+		return metadata
+	}
+
+	metadata = c.read(path, containerID)
+	metadata.readAt = now
+	if metadata.err != nil {
+		c.logger.Warn("Failed to read container metadata", zap.String("runtime", c.runtime),
+			zap.String("container_id", containerID), zap.Error(metadata.err))
+	}
+	if !ok && len(c.containers) >= metadataCacheSize {
+		for id := range c.containers {
+			delete(c.containers, id)
+			break
+		}
+	}
+	c.containers[containerID] = metadata
+	return metadata
+}
+
+// removeEmpty removes the attributes whose value is empty.
+func removeEmpty(attributes map[string]string) {
+	for key, value := range attributes {
+		if value == "" {
+			delete(attributes, key)
+		}
+	}
+}
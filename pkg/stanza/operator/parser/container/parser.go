@@ -33,13 +33,18 @@ const (
 	logPathField        = "log.file.path"
 	crioTimeLayout      = "2006-01-02T15:04:05.999999999Z07:00"
 	goTimeLayout        = "2006-01-02T15:04:05.999Z"
+	rotatedPathPattern  = "\\.log(\\.\\d+|\\.\\d{8}-\\d{6})$"
 )
 
+// criStreams are the streams that lines in the cri-o and containerd formats can be written to.
+var criStreams = []string{"stdout", "stderr"}
+
 var (
 	dockerMatcher     = regexp.MustCompile(dockerPattern)
 	crioMatcher       = regexp.MustCompile(crioPattern)
 	containerdMatcher = regexp.MustCompile(containerdPattern)
 	pathMatcher       = regexp.MustCompile(logpathPattern)
+	rotatedMatcher    = regexp.MustCompile(rotatedPathPattern)
 )
 
 var (
@@ -58,9 +63,11 @@ var (
 // Parser is an operator that parses Container logs.
 type Parser struct {
 	helper.ParserOperator
-	recombineParser         operator.Operator
+	recombineParsers        map[string]operator.Operator
 	format                  string
 	addMetadataFromFilepath bool
+	dockerMetadata          *metadataCache
+	containerdMetadata      *metadataCache
 	criLogEmitter           *helper.LogEmitter
 	asyncConsumerStarted    bool
 	criConsumerStartOnce    sync.Once
@@ -92,10 +99,12 @@ func (p *Parser) Process(ctx context.Context, entry *entry.Entry) (err error) {
 				p.Logger().Error("unable to start the internal LogEmitter", zap.Error(err))
 				return
 			}
-			err = p.recombineParser.Start(nil)
-			if err != nil {
-				p.Logger().Error("unable to start the internal recombine operator", zap.Error(err))
-				return
+			for _, stream := range criStreams {
+				err = p.recombineParsers[stream].Start(nil)
+				if err != nil {
+					p.Logger().Error("unable to start the internal recombine operator", zap.Error(err))
+					return
+				}
 			}
 			p.asyncConsumerStarted = true
 		})
@@ -130,8 +139,14 @@ func (p *Parser) Process(ctx context.Context, entry *entry.Entry) (err error) {
 			return fmt.Errorf("failed to handle attribute mappings: %w", err)
 		}
 
-		// send it to the recombine operator
-		err = p.recombineParser.Process(ctx, entry)
+		// send it to the recombine operator of its stream, which recombines the lines of each container
+		entry.AddAttribute(recombineSourceIdentifier, recombineSource(entry))
+		stream, _ := entry.Attributes[logFieldsMapping["stream"]].(string)
+		recombineParser, ok := p.recombineParsers[stream]
+		if !ok {
+			return fmt.Errorf("failed to recombine the crio log: unknown stream '%s'", stream)
+		}
+		err = recombineParser.Process(ctx, entry)
 		if err != nil {
 			return fmt.Errorf("failed to recombine the crio log: %w", err)
 		}
@@ -151,15 +166,17 @@ func (p *Parser) Stop() error {
 		return nil
 	}
 	var stopErrs []error
-	err := p.recombineParser.Stop()
-	if err != nil {
-		stopErrs = append(stopErrs, fmt.Errorf("unable to stop the internal recombine operator: %w", err))
+	for _, stream := range criStreams {
+		err := p.recombineParsers[stream].Stop()
+		if err != nil {
+			stopErrs = append(stopErrs, fmt.Errorf("unable to stop the internal recombine operator: %w", err))
+		}
 	}
-	// the recombineParser will call the Process of the criLogEmitter synchronously so the entries will be first
+	// the recombineParsers will call the Process of the criLogEmitter synchronously so the entries will be first
 	// written to the channel before the Stop of the recombineParser returns. Then since the criLogEmitter handles
 	// the entries synchronously it is safe to call its Stop.
 	// After criLogEmitter is stopped the crioConsumer will consume the remaining messages and return.
-	err = p.criLogEmitter.Stop()
+	err := p.criLogEmitter.Stop()
 	if err != nil {
 		stopErrs = append(stopErrs, fmt.Errorf("unable to stop the internal LogEmitter: %w", err))
 	}
@@ -236,7 +253,7 @@ func (p *Parser) handleTimeAndAttributeMappings(e *entry.Entry) error {
 	if err != nil {
 		return err
 	}
-	err = p.extractk8sMetaFromFilePath(e)
+	err = p.extractMetadata(e)
 	if err != nil {
 		return err
 	}
@@ -244,6 +261,35 @@ func (p *Parser) handleTimeAndAttributeMappings(e *entry.Entry) error {
 	return nil
 }
 
+// extractMetadata adds the docker or containerd metadata of the container if the entry comes
+// from a file named after the container and otherwise the k8s metadata from the file path
+func (p *Parser) extractMetadata(e *entry.Entry) error {
+	logPath, ok := e.Attributes[logPathField].(string)
+	if ok && p.dockerMetadata != nil {
+		if containerID, ok := dockerContainerID(logPath); ok {
+			return p.dockerMetadata.addMetadata(e, logPath, containerID)
+		}
+	}
+	if ok && p.containerdMetadata != nil {
+		if containerID, ok := containerdContainerID(logPath); ok {
+			return p.containerdMetadata.addMetadata(e, logPath, containerID)
+		}
+	}
+	return p.extractk8sMetaFromFilePath(e)
+}
+
+// recombineSource identifies the container that wrote the entry, so that its partial lines are
+// recombined even when they are read from different files after the log file was rotated: the
+// container ID if the file is named after it, and otherwise the path of the file without the
+// suffix of the files rotated by the kubelet or the docker json-file log driver.
+func recombineSource(e *entry.Entry) string {
+	logPath, _ := e.Attributes[logPathField].(string)
+	if containerID, ok := containerdContainerID(logPath); ok {
+		return containerID
+	}
+	return rotatedMatcher.ReplaceAllString(logPath, ".log")
+}
+
 // handleMoveAttributes moves fields to final attributes
 func (p *Parser) handleMoveAttributes(e *entry.Entry) error {
 	// move `log` to `body` explicitly first to avoid
@@ -290,6 +336,7 @@ func (p *Parser) extractk8sMetaFromFilePath(e *entry.Entry) error {
 
 func (p *Parser) consumeEntries(ctx context.Context, entries []*entry.Entry) {
 	for _, e := range entries {
+		e.Delete(entry.NewAttributeField(recombineSourceIdentifier))
 		err := p.Write(ctx, e)
 		if err != nil {
 			p.Logger().Error("failed to write entry", zap.Error(err))
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/featuregate"
@@ -83,7 +84,7 @@ func TestInternalRecombineCfg(t *testing.T) {
 	expected.IsLastEntry = "attributes.logtag == 'F'"
 	expected.CombineField = entry.NewBodyField()
 	expected.CombineWith = ""
-	expected.SourceIdentifier = entry.NewAttributeField("log.file.path")
+	expected.SourceIdentifier = entry.NewAttributeField("log.container.source")
 	expected.MaxLogSize = 102400
 	require.Equal(t, expected, cfg)
 }
@@ -298,6 +299,89 @@ func TestRecombineProcess(t *testing.T) {
 				},
 			},
 		},
+		{
+			"containerd_interleaved_streams",
+			func() (operator.Operator, error) {
+				cfg := NewConfigWithID("test_id")
+				cfg.AddMetadataFromFilePath = false
+				set := componenttest.NewNopTelemetrySettings()
+				return cfg.Build(set)
+			},
+			[]*entry.Entry{
+				{
+					Body: `2024-04-13T07:59:37.505201169Z stdout P partial stdout line `,
+					Attributes: map[string]any{
+						"log.file.path": "/var/log/pods/some_kube-scheduler-kind-control-plane_49cc7c1fd3702c40b2686ea7486091d3/kube-scheduler44/1.log",
+					},
+				},
+				{
+					Body: `2024-04-13T07:59:38.505201169Z stderr F full stderr line`,
+					Attributes: map[string]any{
+						"log.file.path": "/var/log/pods/some_kube-scheduler-kind-control-plane_49cc7c1fd3702c40b2686ea7486091d3/kube-scheduler44/1.log",
+					},
+				},
+				{
+					Body: `2024-04-13T07:59:39.505201169Z stdout F continued`,
+					Attributes: map[string]any{
+						"log.file.path": "/var/log/pods/some_kube-scheduler-kind-control-plane_49cc7c1fd3702c40b2686ea7486091d3/kube-scheduler44/1.log",
+					},
+				},
+			},
+			[]*entry.Entry{
+				{
+					Attributes: map[string]any{
+						"log.iostream":  "stderr",
+						"logtag":        "F",
+						"log.file.path": "/var/log/pods/some_kube-scheduler-kind-control-plane_49cc7c1fd3702c40b2686ea7486091d3/kube-scheduler44/1.log",
+					},
+					Body:      "full stderr line",
+					Timestamp: time.Date(2024, time.April, 13, 7, 59, 38, 505201169, time.UTC),
+				},
+				{
+					Attributes: map[string]any{
+						"log.iostream":  "stdout",
+						"logtag":        "P",
+						"log.file.path": "/var/log/pods/some_kube-scheduler-kind-control-plane_49cc7c1fd3702c40b2686ea7486091d3/kube-scheduler44/1.log",
+					},
+					Body:      "partial stdout line continued",
+					Timestamp: time.Date(2024, time.April, 13, 7, 59, 37, 505201169, time.UTC),
+				},
+			},
+		},
+		{
+			"containerd_partial_line_across_rotation",
+			func() (operator.Operator, error) {
+				cfg := NewConfigWithID("test_id")
+				cfg.AddMetadataFromFilePath = false
+				set := componenttest.NewNopTelemetrySettings()
+				return cfg.Build(set)
+			},
+			[]*entry.Entry{
+				{
+					Body: `2024-04-13T07:59:37.505201169Z stdout P line written before the rotation `,
+					Attributes: map[string]any{
+						"log.file.path": "/var/log/pods/some_kube-scheduler-kind-control-plane_49cc7c1fd3702c40b2686ea7486091d3/kube-scheduler44/1.log.20240413-075937",
+					},
+				},
+				{
+					Body: `2024-04-13T07:59:38.505201169Z stdout F and after`,
+					Attributes: map[string]any{
+						"log.file.path": "/var/log/pods/some_kube-scheduler-kind-control-plane_49cc7c1fd3702c40b2686ea7486091d3/kube-scheduler44/1.log",
+					},
+				},
+			},
+			[]*entry.Entry{
+				{
+					Attributes: map[string]any{
+						"log.iostream":  "stdout",
+						"logtag":        "P",
+						"log.file.path": "/var/log/pods/some_kube-scheduler-kind-control-plane_49cc7c1fd3702c40b2686ea7486091d3/kube-scheduler44/1.log.20240413-075937",
+					},
+					Body:      "line written before the rotation and after",
+					Timestamp: time.Date(2024, time.April, 13, 7, 59, 37, 505201169, time.UTC),
+				},
+			},
+		},
 		{
 			"containerd_multiple_with_auto_detection_and_metadata_from_file_path",
 			func() (operator.Operator, error) {
@@ -408,6 +492,23 @@ func TestRecombineProcess(t *testing.T) {
 	}
 }
 
+func TestRecombineSource(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/var/log/pods/ns_pod_49cc7c1fd3702c40b2686ea7486091d3/container/1.log":                 "/var/log/pods/ns_pod_49cc7c1fd3702c40b2686ea7486091d3/container/1.log",
+		"/var/log/pods/ns_pod_49cc7c1fd3702c40b2686ea7486091d3/container/1.log.20240413-075937": "/var/log/pods/ns_pod_49cc7c1fd3702c40b2686ea7486091d3/container/1.log",
+		"/var/log/containers/pod_ns_container-" + testContainerID + ".log":                      testContainerID,
+		"/var/lib/docker/containers/" + testContainerID + "/" + testContainerID + "-json.log.1": testContainerID,
+		"/var/log/app.log.2": "/var/log/app.log",
+		"":                   "",
+	} {
+		e := entry.New()
+		if path != "" {
+			e.Attributes = map[string]any{"log.file.path": path}
+		}
+		assert.Equal(t, expected, recombineSource(e), path)
+	}
+}
+
 func TestProcessWithDockerTime(t *testing.T) {
 	cases := []struct {
 		name           string
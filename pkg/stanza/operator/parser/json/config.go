@@ -32,7 +32,8 @@ func NewConfigWithID(operatorID string) *Config {
 type Config struct {
 	helper.ParserConfig `mapstructure:",squash"`
 
-	ParseInts bool `mapstructure:"parse_ints"`
+	ParseInts bool                 `mapstructure:"parse_ints"`
+	Schema    *helper.SchemaConfig `mapstructure:"schema,omitempty"`
 }
 
 // Build will build a JSON parser operator.
@@ -42,8 +43,14 @@ func (c Config) Build(set component.TelemetrySettings) (operator.Operator, error
 		return nil, err
 	}
 
+	schema, err := c.Schema.Build(set, c.OperatorID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Parser{
 		ParserOperator: parserOperator,
 		parseInts:      c.ParseInts,
+		schema:         schema,
 	}, nil
 }
@@ -19,6 +19,7 @@ type Parser struct {
 	helper.ParserOperator
 
 	parseInts bool
+	schema    *helper.Schema
 }
 
 // Process will parse an entry for JSON.
@@ -52,7 +53,7 @@ func (p *Parser) parse(value any) (any, error) {
 		return nil, fmt.Errorf("type %T cannot be parsed as JSON", value)
 	}
 
-	return parsedValue, nil
+	return p.schema.Apply(parsedValue)
 }
 
 func convertNumbers(parsedValue map[string]any) {
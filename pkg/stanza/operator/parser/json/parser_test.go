@@ -137,6 +137,29 @@ func TestParser(t *testing.T) {
 				ScopeName: "logger",
 			},
 		},
+		{
+			"with_schema",
+			func(p *Config) {
+				p.Schema = &helper.SchemaConfig{
+					Fields: map[string]helper.FieldType{
+						"status":       {Type: helper.IntType},
+						"http.latency": {Type: helper.DurationType},
+					},
+				}
+			},
+			&entry.Entry{
+				Body: `{"status":"200","http":{"latency":"1.5s"}}`,
+			},
+			&entry.Entry{
+				Attributes: map[string]any{
+					"status": int64(200),
+					"http": map[string]any{
+						"latency": int64(1500),
+					},
+				},
+				Body: `{"status":"200","http":{"latency":"1.5s"}}`,
+			},
+		},
 		{
 			"parse_ints_disabled",
 			func(_ *Config) {},
@@ -35,8 +35,9 @@ func NewConfigWithID(operatorID string) *Config {
 type Config struct {
 	helper.ParserConfig `mapstructure:",squash"`
 
-	Delimiter     string `mapstructure:"delimiter"`
-	PairDelimiter string `mapstructure:"pair_delimiter"`
+	Delimiter     string               `mapstructure:"delimiter"`
+	PairDelimiter string               `mapstructure:"pair_delimiter"`
+	Schema        *helper.SchemaConfig `mapstructure:"schema,omitempty"`
 }
 
 // Build will build a key value parser operator.
@@ -59,9 +60,15 @@ func (c Config) Build(set component.TelemetrySettings) (operator.Operator, error
 		return nil, errors.New("delimiter and pair_delimiter cannot be the same value")
 	}
 
+	schema, err := c.Schema.Build(set, c.OperatorID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Parser{
 		ParserOperator: parserOperator,
 		delimiter:      c.Delimiter,
 		pairDelimiter:  pairDelimiter,
+		schema:         schema,
 	}, nil
 }
@@ -124,6 +124,21 @@ func TestConfig(t *testing.T) {
 					return p
 				}(),
 			},
+			{
+				Name: "schema",
+				Expect: func() *Config {
+					p := NewConfig()
+					p.Schema = &helper.SchemaConfig{
+						Fields: map[string]helper.FieldType{
+							"status":  {Type: helper.IntType},
+							"latency": {Type: helper.DurationType, Unit: "s"},
+						},
+						Infer:      true,
+						OnConflict: helper.RemoveOnConflict,
+					}
+					return p
+				}(),
+			},
 		},
 	}.Run(t)
 }
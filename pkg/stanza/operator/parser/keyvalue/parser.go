@@ -17,6 +17,7 @@ type Parser struct {
 	helper.ParserOperator
 	delimiter     string
 	pairDelimiter string
+	schema        *helper.Schema
 }
 
 // Process will parse an entry for key value pairs.
@@ -44,5 +45,14 @@ func (p *Parser) parser(input string, delimiter string, pairDelimiter string) (m
 		return nil, fmt.Errorf("failed to parse pairs from input: %w", err)
 	}
 
-	return parseutils.ParseKeyValuePairs(pairs, delimiter)
+	parsed, err := parseutils.ParseKeyValuePairs(pairs, delimiter)
+	if err != nil || p.schema == nil {
+		return parsed, err
+	}
+
+	result, err := p.schema.Apply(parsed)
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]any), nil
 }
@@ -14,6 +14,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/entry"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/helper"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/testutil"
 )
 
@@ -194,6 +195,47 @@ func TestParser(t *testing.T) {
 			false,
 			false,
 		},
+		{
+			"schema",
+			func(kv *Config) {
+				kv.Schema = &helper.SchemaConfig{
+					Fields: map[string]helper.FieldType{
+						"age":   {Type: helper.IntType},
+						"admin": {Type: helper.BoolType},
+					},
+				}
+			},
+			&entry.Entry{
+				Body: "name=stanza age=2 admin=true",
+			},
+			&entry.Entry{
+				Attributes: map[string]any{
+					"name":  "stanza",
+					"age":   int64(2),
+					"admin": true,
+				},
+				Body: "name=stanza age=2 admin=true",
+			},
+			false,
+			false,
+		},
+		{
+			"schema-conflict",
+			func(kv *Config) {
+				kv.Schema = &helper.SchemaConfig{
+					Fields:     map[string]helper.FieldType{"age": {Type: helper.IntType}},
+					OnConflict: helper.ErrorOnConflict,
+				}
+			},
+			&entry.Entry{
+				Body: "name=stanza age=two",
+			},
+			&entry.Entry{
+				Body: "name=stanza age=two",
+			},
+			true,
+			false,
+		},
 		{
 			"parse-from",
 			func(kv *Config) {
@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package leef
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/entry"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+)
+
+const testMessage = "LEEF:2.0|IBM|QRadar|7.5|Login|^|usrName=alice^src=10.0.0.1^devCustomLabel=Zone^devCustom=dmz"
+
+func newTestParser(t *testing.T) *Parser {
+	cfg := NewConfigWithID("test")
+	set := componenttest.NewNopTelemetrySettings()
+	op, err := cfg.Build(set)
+	require.NoError(t, err)
+	return op.(*Parser)
+}
+
+func TestInit(t *testing.T) {
+	builder, ok := operator.DefaultRegistry.Lookup("leef_parser")
+	require.True(t, ok, "expected leef_parser to be registered")
+	require.Equal(t, "leef_parser", builder().Type())
+}
+
+func TestParserBuildFailure(t *testing.T) {
+	cfg := NewConfigWithID("test")
+	cfg.OnError = "invalid_on_error"
+	set := componenttest.NewNopTelemetrySettings()
+	_, err := cfg.Build(set)
+	require.ErrorContains(t, err, "invalid `on_error` field")
+}
+
+func TestParserStringFailure(t *testing.T) {
+	parser := newTestParser(t)
+	_, err := parser.parse("LEEF:1.0|IBM")
+	require.ErrorContains(t, err, "invalid LEEF header")
+}
+
+func TestParserInvalidType(t *testing.T) {
+	parser := newTestParser(t)
+	_, err := parser.parse([]int{})
+	require.ErrorContains(t, err, "type '[]int' cannot be parsed as LEEF")
+}
+
+func TestProcess(t *testing.T) {
+	op, err := NewConfigWithID("test_id").Build(componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+
+	e := &entry.Entry{Body: testMessage}
+	require.NoError(t, op.Process(context.Background(), e))
+	require.Equal(t, &entry.Entry{
+		Attributes: map[string]any{
+			"version":         "2.0",
+			"vendor":          "IBM",
+			"product":         "QRadar",
+			"product_version": "7.5",
+			"event_id":        "Login",
+			"attributes": map[string]any{
+				"usrName": "alice",
+				"src":     "10.0.0.1",
+				"Zone":    "dmz",
+			},
+		},
+		Body: testMessage,
+	}, e)
+}
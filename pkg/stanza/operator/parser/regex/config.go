@@ -37,7 +37,8 @@ func NewConfigWithID(operatorID string) *Config {
 type Config struct {
 	helper.ParserConfig `mapstructure:",squash"`
 
-	Regex string `mapstructure:"regex"`
+	Regex  string               `mapstructure:"regex"`
+	Schema *helper.SchemaConfig `mapstructure:"schema,omitempty"`
 
 	Cache struct {
 		Size uint16 `mapstructure:"size"`
@@ -73,9 +74,15 @@ func (c Config) Build(set component.TelemetrySettings) (operator.Operator, error
 		)
 	}
 
+	schema, err := c.Schema.Build(set, c.OperatorID)
+	if err != nil {
+		return nil, err
+	}
+
 	op := &Parser{
 		ParserOperator: parserOperator,
 		regexp:         r,
+		schema:         schema,
 	}
 
 	if c.Cache.Size > 0 {
@@ -17,6 +17,7 @@ type Parser struct {
 	helper.ParserOperator
 	regexp *regexp.Regexp
 	cache  cache
+	schema *helper.Schema
 }
 
 func (p *Parser) Stop() error {
@@ -40,7 +41,17 @@ func (p *Parser) parse(value any) (any, error) {
 	default:
 		return nil, fmt.Errorf("type '%T' cannot be parsed as regex", value)
 	}
-	return p.match(raw)
+	parsed, err := p.match(raw)
+	if err != nil || p.schema == nil {
+		return parsed, err
+	}
+	// Cached values are shared between entries, so the schema is applied to a copy.
+	parsedValues := parsed.(map[string]any)
+	values := make(map[string]any, len(parsedValues))
+	for k, v := range parsedValues {
+		values[k] = v
+	}
+	return p.schema.Apply(values)
 }
 
 func (p *Parser) match(value string) (any, error) {
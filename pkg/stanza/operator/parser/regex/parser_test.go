@@ -16,6 +16,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/entry"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/helper"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/testutil"
 )
 
@@ -90,6 +91,25 @@ func TestParserRegex(t *testing.T) {
 				},
 			},
 		},
+		{
+			"Schema",
+			func(p *Config) {
+				p.Regex = "a=(?P<a>.*)"
+				p.Cache.Size = 100
+				p.Schema = &helper.SchemaConfig{
+					Fields: map[string]helper.FieldType{"a": {Type: helper.IntType}},
+				}
+			},
+			&entry.Entry{
+				Body: "a=1",
+			},
+			&entry.Entry{
+				Body: "a=1",
+				Attributes: map[string]any{
+					"a": int64(1),
+				},
+			},
+		},
 		{
 			"MemeoryCache",
 			func(p *Config) {
@@ -0,0 +1,23 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package adaptivesamplingprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/adaptivesamplingprocessor"
+
+import (
+	"go.opentelemetry.io/collector/component"
+)
+
+// Config defines the configuration of the adaptive sampling processor.
+type Config struct {
+	// Extension is the jaegerremotesampling extension calculating the adaptive sampling strategies.
+	// It must use the `adaptive` source.
+	Extension component.ID `mapstructure:"extension"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		Extension: component.MustNewID("jaegerremotesampling"),
+	}
+}
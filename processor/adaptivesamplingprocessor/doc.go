@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+// Package adaptivesamplingprocessor records the throughput of the root spans passing through it, from which
+// the jaegerremotesampling extension calculates adaptive sampling strategies.
+package adaptivesamplingprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/adaptivesamplingprocessor"
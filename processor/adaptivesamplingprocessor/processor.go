@@ -0,0 +1,37 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package adaptivesamplingprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/adaptivesamplingprocessor"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/jaegerremotesampling"
+)
+
+type adaptiveSamplingProcessor struct {
+	cfg      *Config
+	recorder jaegerremotesampling.ThroughputRecorder
+}
+
+func newProcessor(cfg *Config) *adaptiveSamplingProcessor {
+	return &adaptiveSamplingProcessor{cfg: cfg}
+}
+
+func (p *adaptiveSamplingProcessor) start(_ context.Context, host component.Host) error {
+	recorder, err := jaegerremotesampling.GetThroughputRecorder(host, p.cfg.Extension)
+	if err != nil {
+		return err
+	}
+	p.recorder = recorder
+	return nil
+}
+
+// processTraces records the throughput of the traces, which are passed on unchanged.
+func (p *adaptiveSamplingProcessor) processTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	p.recorder.RecordTraces(td)
+	return td, nil
+}
@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package adaptivesamplingprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jaegertracing/jaeger/plugin/sampling/strategyprovider/adaptive"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processortest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/jaegerremotesampling"
+)
+
+type testHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h *testHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+func TestProcessTraces(t *testing.T) {
+	ctx := context.Background()
+	extID := component.MustNewID("jaegerremotesampling")
+	extFactory := jaegerremotesampling.NewFactory()
+	extCfg := extFactory.CreateDefaultConfig().(*jaegerremotesampling.Config)
+	extCfg.HTTPServerConfig.Endpoint = "127.0.0.1:0"
+	extCfg.GRPCServerConfig = nil
+	extCfg.Source.Adaptive = &jaegerremotesampling.AdaptiveConfig{Options: adaptive.DefaultOptions()}
+	ext, err := extFactory.Create(ctx, extensiontest.NewNopSettings(), extCfg)
+	require.NoError(t, err)
+	host := &testHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{extID: ext},
+	}
+	require.NoError(t, ext.Start(ctx, host))
+	defer func() {
+		assert.NoError(t, ext.Shutdown(ctx))
+	}()
+
+	sink := &consumertest.TracesSink{}
+	p, err := NewFactory().CreateTraces(ctx, processortest.NewNopSettings(), createDefaultConfig(), sink)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(ctx, host))
+
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", "foo")
+	span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName("GET /")
+	span.Attributes().PutStr("sampler.type", "probabilistic")
+	span.Attributes().PutDouble("sampler.param", 0.001)
+
+	// the traces are passed on unchanged
+	require.NoError(t, p.ConsumeTraces(ctx, td))
+	require.Len(t, sink.AllTraces(), 1)
+	assert.Equal(t, td, sink.AllTraces()[0])
+	assert.NoError(t, p.Shutdown(ctx))
+}
+
+func TestStartWithoutAdaptiveExtension(t *testing.T) {
+	ctx := context.Background()
+	p, err := NewFactory().CreateTraces(ctx, processortest.NewNopSettings(), createDefaultConfig(), consumertest.NewNop())
+	require.NoError(t, err)
+	assert.EqualError(t, p.Start(ctx, componenttest.NewNopHost()), "extension jaegerremotesampling not found")
+	assert.NoError(t, p.Shutdown(ctx))
+}
@@ -5,23 +5,23 @@ package remotetapprocessor // import "github.com/open-telemetry/opentelemetry-co
 
 import "sync"
 
-// channelSet is a collection of byte channels where adding, removing, and writing to
+// channelSet is a collection of channels where adding, removing, and writing to
 // the channels is synchronized.
-type channelSet struct {
+type channelSet[T any] struct {
 	i       int
 	mu      sync.RWMutex
-	chanmap map[int]chan []byte
+	chanmap map[int]chan T
 }
 
-func newChannelSet() *channelSet {
-	return &channelSet{
-		chanmap: map[int]chan []byte{},
+func newChannelSet[T any]() *channelSet[T] {
+	return &channelSet[T]{
+		chanmap: map[int]chan T{},
 	}
 }
 
 // add adds the channel to the channelSet and returns a key (just an int) used to
 // remove the channel later.
-func (c *channelSet) add(ch chan []byte) int {
+func (c *channelSet[T]) add(ch chan T) int {
 	c.mu.Lock()
 	idx := c.i
 	c.chanmap[idx] = ch
@@ -30,26 +30,49 @@ func (c *channelSet) add(ch chan []byte) int {
 	return idx
 }
 
-// writeBytes writes the passed in bytes to all of the channels in the
+// write writes the passed in value to all of the channels in the
 // channelSet.
-func (c *channelSet) writeBytes(bytes []byte) {
+func (c *channelSet[T]) write(v T) {
 	c.mu.RLock()
 	for _, ch := range c.chanmap {
-		ch <- bytes
+		ch <- v
 	}
 	c.mu.RUnlock()
 }
 
+// tryWrite writes the passed in value to the channels of the channelSet that
+// are ready to receive it, without blocking. It returns the keys of the
+// channels the value couldn't be written to.
+func (c *channelSet[T]) tryWrite(v T) (skipped []int) {
+	c.mu.RLock()
+	for key, ch := range c.chanmap {
+		select {
+		case ch <- v:
+		default:
+			skipped = append(skipped, key)
+		}
+	}
+	c.mu.RUnlock()
+	return skipped
+}
+
+// len returns the number of channels in the channelSet.
+func (c *channelSet[T]) len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.chanmap)
+}
+
 // closeAndRemove closes then removes the channel associated with the passed in
 // key. Panics if an invalid key is passed in.
-func (c *channelSet) closeAndRemove(key int) {
+func (c *channelSet[T]) closeAndRemove(key int) {
 	c.mu.Lock()
 	close(c.chanmap[key])
 	delete(c.chanmap, key)
 	c.mu.Unlock()
 }
 
-func (c *channelSet) shutdown() {
+func (c *channelSet[T]) shutdown() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -62,7 +85,7 @@ func (c *channelSet) shutdown() {
 		i++
 	}
 
-	for key := range keys {
+	for _, key := range keys {
 		close(c.chanmap[key])
 		delete(c.chanmap, key)
 	}
@@ -11,14 +11,24 @@ import (
 )
 
 func TestChannelset(t *testing.T) {
-	cs := newChannelSet()
+	cs := newChannelSet[[]byte]()
 	ch := make(chan []byte)
 	key := cs.add(ch)
 	go func() {
-		cs.writeBytes([]byte("hello"))
+		cs.write([]byte("hello"))
 	}()
 	assert.Eventually(t, func() bool {
 		return assert.Equal(t, []byte("hello"), <-ch)
 	}, time.Second, time.Millisecond*10)
 	cs.closeAndRemove(key)
 }
+
+func TestChannelsetTryWrite(t *testing.T) {
+	cs := newChannelSet[[]byte]()
+	ready := cs.add(make(chan []byte, 1))
+	busy := cs.add(make(chan []byte))
+
+	assert.Equal(t, []int{busy}, cs.tryWrite([]byte("hello")))
+	assert.ElementsMatch(t, []int{ready, busy}, cs.tryWrite([]byte("hello")))
+	cs.shutdown()
+}
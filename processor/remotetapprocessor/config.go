@@ -19,6 +19,10 @@ type Config struct {
 	// Limit is a float that indicates the maximum number of messages repeated
 	// through the websocket by this processor in messages per second. Defaults to 1.
 	Limit rate.Limit `mapstructure:"limit"`
+
+	// OpAMP is the ID of the OpAMP extension the OpAMP server requests tapped data through.
+	// The data is sent to the OpAMP server with custom messages, rate limited by Limit.
+	OpAMP *component.ID `mapstructure:"opampextension"`
 }
 
 func createDefaultConfig() component.Config {
@@ -13,4 +13,5 @@ func TestCreateDefaultConfig(t *testing.T) {
 	cfg := createDefaultConfig().(*Config)
 	assert.Equal(t, "localhost:12001", cfg.Endpoint)
 	assert.EqualValues(t, 1, cfg.Limit)
+	assert.Nil(t, cfg.OpAMP)
 }
@@ -0,0 +1,411 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package remotetapprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/remotetapprocessor"
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/open-telemetry/opamp-go/client/types"
+	"github.com/open-telemetry/opamp-go/protobufs"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/opampcustommessages"
+)
+
+const (
+	// CustomCapability is the OpAMP custom capability through which the OpAMP server requests tapped data.
+	CustomCapability = "org.opentelemetry.collector.processor.remotetap"
+
+	// TapMessageType is the type of the custom messages sent by the OpAMP server to start a tap session.
+	TapMessageType = "tap"
+	// StopMessageType is the type of the custom messages sent by the OpAMP server to stop a tap session.
+	StopMessageType = "stop"
+	// DataMessageType is the type of the custom messages holding the data tapped by a session.
+	DataMessageType = "data"
+	// DoneMessageType is the type of the custom message sent once a session has ended.
+	DoneMessageType = "done"
+
+	signalLogs    = "logs"
+	signalMetrics = "metrics"
+	signalTraces  = "traces"
+
+	defaultTapCount   = 100
+	maxTapCount       = 10000
+	defaultTapTimeout = time.Minute
+	maxTapTimeout     = 10 * time.Minute
+	maxTapSessions    = 10
+	// tapSessionBuffer is the number of batches a session can queue while it's sending data to the server.
+	tapSessionBuffer = 16
+
+	maxDoneMessageAttempts = 3
+)
+
+// Reasons for which a tap session ends.
+const (
+	doneReasonCompleted = "completed"
+	doneReasonTimeout   = "timeout"
+	doneReasonStopped   = "stopped"
+	doneReasonShutdown  = "shutdown"
+	doneReasonRejected  = "rejected"
+)
+
+// tapRequest is the body of the TapMessageType and StopMessageType messages.
+type tapRequest struct {
+	// RequestID identifies the session in the messages sent back to the server.
+	RequestID string `json:"request_id"`
+	// Processor is the ID of the remotetap processor to tap, e.g. "remotetap/logs".
+	Processor string `json:"processor"`
+	// Signal restricts the session to "logs", "metrics" or "traces". All signals are tapped when empty.
+	Signal string `json:"signal,omitempty"`
+	// Count is the number of log records, data points or spans after which the session ends.
+	Count int `json:"count,omitempty"`
+	// Limit is the maximum number of data messages per second. It can't exceed the limit of the processor.
+	Limit float64 `json:"limit,omitempty"`
+	// Timeout is the duration, e.g. "30s", after which the session ends.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// tapDataMessage is the body of the DataMessageType messages.
+type tapDataMessage struct {
+	RequestID string          `json:"request_id"`
+	Signal    string          `json:"signal"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// tapDoneMessage is the body of the DoneMessageType messages.
+type tapDoneMessage struct {
+	RequestID string `json:"request_id"`
+	Reason    string `json:"reason"`
+	Items     int    `json:"items"`
+	Dropped   int    `json:"dropped"`
+	Error     string `json:"error,omitempty"`
+}
+
+// tappedData is written to the channels of the tap sessions for every batch going through the processor.
+type tappedData struct {
+	signal string
+	items  int
+	data   []byte
+}
+
+// opampTapper starts tap sessions on the requests of the OpAMP server, and sends the tapped data back as
+// custom messages.
+type opampTapper struct {
+	logger           *zap.Logger
+	id               component.ID
+	opampExtensionID component.ID
+	limit            rate.Limit
+	taps             *channelSet[tappedData]
+	handler          opampcustommessages.CustomCapabilityHandler
+
+	mu       sync.Mutex
+	sessions map[string]chan struct{}
+	// running are the running sessions by the key of their channel in taps.
+	running map[int]*tapSession
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newOpAMPTapper(id, opampExtensionID component.ID, limit rate.Limit, taps *channelSet[tappedData], logger *zap.Logger) *opampTapper {
+	return &opampTapper{
+		logger:           logger,
+		id:               id,
+		opampExtensionID: opampExtensionID,
+		limit:            limit,
+		taps:             taps,
+		sessions:         map[string]chan struct{}{},
+		running:          map[int]*tapSession{},
+		done:             make(chan struct{}),
+	}
+}
+
+func (t *opampTapper) Start(host component.Host) error {
+	ext, ok := host.GetExtensions()[t.opampExtensionID]
+	if !ok {
+		return fmt.Errorf("extension %q does not exist", t.opampExtensionID)
+	}
+
+	registry, ok := ext.(opampcustommessages.CustomCapabilityRegistry)
+	if !ok {
+		return fmt.Errorf("extension %q is not a custom message registry", t.opampExtensionID)
+	}
+
+	handler, err := registry.Register(CustomCapability)
+	if err != nil {
+		return fmt.Errorf("failed to register custom capability: %w", err)
+	}
+	if handler == nil {
+		return errors.New("custom capability handler is nil")
+	}
+	t.handler = handler
+
+	t.wg.Add(1)
+	go t.receiveMessages()
+	return nil
+}
+
+// Shutdown ends the running sessions and unregisters the custom capability.
+func (t *opampTapper) Shutdown() {
+	if t.handler == nil {
+		return
+	}
+	close(t.done)
+	t.wg.Wait()
+	t.handler.Unregister()
+}
+
+func (t *opampTapper) receiveMessages() {
+	defer t.wg.Done()
+	for {
+		select {
+		case msg := <-t.handler.Message():
+			t.handleMessage(msg)
+		case <-t.done:
+			return
+		}
+	}
+}
+
+func (t *opampTapper) handleMessage(msg *protobufs.CustomMessage) {
+	var req tapRequest
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		t.logger.Debug("Ignoring invalid tap request", zap.String("type", msg.Type), zap.Error(err))
+		return
+	}
+	// The capability is shared by every remotetap processor of the collector.
+	if req.Processor != t.id.String() {
+		return
+	}
+
+	switch msg.Type {
+	case TapMessageType:
+		if err := t.startSession(req); err != nil {
+			t.logger.Debug("Rejecting tap request", zap.String("request_id", req.RequestID), zap.Error(err))
+			t.sendDone(tapDoneMessage{RequestID: req.RequestID, Reason: doneReasonRejected, Error: err.Error()})
+		}
+	case StopMessageType:
+		t.mu.Lock()
+		if stop, ok := t.sessions[req.RequestID]; ok {
+			close(stop)
+			delete(t.sessions, req.RequestID)
+		}
+		t.mu.Unlock()
+	default:
+		t.logger.Debug("Ignoring unknown message type", zap.String("type", msg.Type))
+	}
+}
+
+func (t *opampTapper) startSession(req tapRequest) error {
+	if req.RequestID == "" {
+		return errors.New("the request_id is missing")
+	}
+	switch req.Signal {
+	case "", signalLogs, signalMetrics, signalTraces:
+	default:
+		return fmt.Errorf("unknown signal %q", req.Signal)
+	}
+
+	count := req.Count
+	switch {
+	case count < 0:
+		return errors.New("the count must be positive")
+	case count == 0:
+		count = defaultTapCount
+	case count > maxTapCount:
+		count = maxTapCount
+	}
+
+	limit := t.limit
+	switch {
+	case req.Limit < 0:
+		return errors.New("the limit must be positive")
+	case req.Limit > 0 && rate.Limit(req.Limit) < limit:
+		limit = rate.Limit(req.Limit)
+	}
+
+	timeout := defaultTapTimeout
+	if req.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(req.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+		if timeout <= 0 {
+			return errors.New("the timeout must be positive")
+		}
+		timeout = min(timeout, maxTapTimeout)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.sessions[req.RequestID]; ok {
+		return fmt.Errorf("the session %q is already running", req.RequestID)
+	}
+	if len(t.sessions) >= maxTapSessions {
+		return fmt.Errorf("too many sessions, at most %d sessions can run at the same time", maxTapSessions)
+	}
+	stop := make(chan struct{})
+	t.sessions[req.RequestID] = stop
+
+	s := &tapSession{
+		requestID: req.RequestID,
+		signal:    req.Signal,
+		count:     count,
+		limiter:   rate.NewLimiter(limit, max(1, int(limit))),
+	}
+	t.wg.Add(1)
+	go t.runSession(s, timeout, stop)
+	return nil
+}
+
+type tapSession struct {
+	requestID string
+	signal    string
+	count     int
+	limiter   *rate.Limiter
+	items     int
+	dropped   int
+	// overflow is the number of items the processor dropped because the session was busy.
+	overflow atomic.Int64
+}
+
+// drop records the data dropped by the processor for the sessions of the given keys.
+func (t *opampTapper) drop(keys []int, data tappedData) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, key := range keys {
+		if s, ok := t.running[key]; ok && (s.signal == "" || s.signal == data.signal) {
+			s.overflow.Add(int64(data.items))
+		}
+	}
+}
+
+func (t *opampTapper) runSession(s *tapSession, timeout time.Duration, stop chan struct{}) {
+	defer t.wg.Done()
+
+	ch := make(chan tappedData, tapSessionBuffer)
+	idx := t.taps.add(ch)
+	t.mu.Lock()
+	t.running[idx] = s
+	t.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	reason := doneReasonCompleted
+loop:
+	for s.items < s.count {
+		select {
+		case data := <-ch:
+			if r := t.tap(s, data, timer.C, stop); r != "" {
+				reason = r
+				break loop
+			}
+		case <-timer.C:
+			reason = doneReasonTimeout
+			break loop
+		case <-stop:
+			reason = doneReasonStopped
+			break loop
+		case <-t.done:
+			reason = doneReasonShutdown
+			break loop
+		}
+	}
+
+	// The session is removed before sending the done message, which can wait for the OpAMP connection.
+	t.taps.closeAndRemove(idx)
+	t.mu.Lock()
+	delete(t.running, idx)
+	if t.sessions[s.requestID] == stop {
+		delete(t.sessions, s.requestID)
+	}
+	t.mu.Unlock()
+	for data := range ch {
+		if s.signal == "" || s.signal == data.signal {
+			s.dropped += data.items
+		}
+	}
+	s.dropped += int(s.overflow.Load())
+
+	t.sendDone(tapDoneMessage{RequestID: s.requestID, Reason: reason, Items: s.items, Dropped: s.dropped})
+}
+
+// tap sends the data to the OpAMP server. Data exceeding the rate limit of the session is dropped. While a
+// previous message is still being sent, the session waits for it to be sent, and the next batches are queued
+// in the buffer of the session, so that the pipeline is never blocked. tap returns the reason for which the
+// session ended while waiting, if it did.
+func (t *opampTapper) tap(s *tapSession, data tappedData, timeout <-chan time.Time, stop <-chan struct{}) string {
+	if s.signal != "" && s.signal != data.signal {
+		return ""
+	}
+	if !s.limiter.Allow() {
+		s.dropped += data.items
+		return ""
+	}
+
+	b, err := json.Marshal(tapDataMessage{RequestID: s.requestID, Signal: data.signal, Data: data.data})
+	if err != nil {
+		t.logger.Debug("Error serializing tap data", zap.Error(err))
+		return ""
+	}
+	for {
+		sendingChan, sendingErr := t.handler.SendMessage(DataMessageType, b)
+		switch {
+		case sendingErr == nil:
+			s.items += data.items
+			return ""
+		case errors.Is(sendingErr, types.ErrCustomMessagePending):
+			select {
+			case <-sendingChan:
+				continue
+			case <-timeout:
+				s.dropped += data.items
+				return doneReasonTimeout
+			case <-stop:
+				s.dropped += data.items
+				return doneReasonStopped
+			case <-t.done:
+				s.dropped += data.items
+				return doneReasonShutdown
+			}
+		default:
+			t.logger.Debug("Failed to send tap data", zap.String("request_id", s.requestID), zap.Error(sendingErr))
+			s.dropped += data.items
+			return ""
+		}
+	}
+}
+
+func (t *opampTapper) sendDone(msg tapDoneMessage) {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		t.logger.Debug("Error serializing tap done message", zap.Error(err))
+		return
+	}
+	for attempt := 0; attempt < maxDoneMessageAttempts; attempt++ {
+		sendingChan, sendingErr := t.handler.SendMessage(DoneMessageType, b)
+		switch {
+		case sendingErr == nil:
+			return
+		case errors.Is(sendingErr, types.ErrCustomMessagePending):
+			select {
+			case <-sendingChan:
+			case <-t.done:
+				// Don't hold the shutdown of the processor.
+				return
+			}
+		default:
+			t.logger.Debug("Failed to send tap done message", zap.String("request_id", msg.RequestID), zap.Error(sendingErr))
+			return
+		}
+	}
+	t.logger.Debug("Failed to send tap done message after multiple attempts", zap.String("request_id", msg.RequestID))
+}
@@ -0,0 +1,455 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package remotetapprocessor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opamp-go/client/types"
+	"github.com/open-telemetry/opamp-go/protobufs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processortest"
+	"golang.org/x/time/rate"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/opampcustommessages"
+)
+
+var opampID = component.MustNewID("opamp")
+
+type mockCustomCapabilityRegistry struct {
+	component.Component
+
+	shouldFailRegister             bool
+	shouldRegisterReturnNilHandler bool
+	shouldReturnPending            bool
+	shouldReturnDonePending        bool
+	// dataSending is notified of the data messages, whose sending waits for dataRelease when it is set.
+	dataSending chan struct{}
+	dataRelease chan struct{}
+	// dataPending is returned as the sending channel of the first data message, which is pending until it's closed.
+	dataPending chan struct{}
+
+	registeredCapability string
+	unregisterCalled     bool
+	messages             chan *protobufs.CustomMessage
+	sentMessages         chan customMessage
+}
+
+type customMessage struct {
+	messageType string
+	message     []byte
+}
+
+func newMockCustomCapabilityRegistry() *mockCustomCapabilityRegistry {
+	return &mockCustomCapabilityRegistry{
+		messages:     make(chan *protobufs.CustomMessage),
+		sentMessages: make(chan customMessage, 100),
+	}
+}
+
+func (m *mockCustomCapabilityRegistry) Register(capability string, _ ...opampcustommessages.CustomCapabilityRegisterOption) (opampcustommessages.CustomCapabilityHandler, error) {
+	if m.shouldFailRegister {
+		return nil, errors.New("register failed")
+	}
+	if m.shouldRegisterReturnNilHandler {
+		return nil, nil
+	}
+	m.registeredCapability = capability
+	return m, nil
+}
+
+func (m *mockCustomCapabilityRegistry) Message() <-chan *protobufs.CustomMessage {
+	return m.messages
+}
+
+func (m *mockCustomCapabilityRegistry) SendMessage(messageType string, message []byte) (chan struct{}, error) {
+	if m.dataRelease != nil && messageType == DataMessageType {
+		m.dataSending <- struct{}{}
+		<-m.dataRelease
+	}
+	if m.dataPending != nil && messageType == DataMessageType {
+		pending := m.dataPending
+		m.dataPending = nil
+		m.dataSending <- struct{}{}
+		return pending, types.ErrCustomMessagePending
+	}
+	if m.shouldReturnPending && messageType == DataMessageType {
+		return make(chan struct{}), types.ErrCustomMessagePending
+	}
+	if m.shouldReturnDonePending && messageType == DoneMessageType {
+		return make(chan struct{}), types.ErrCustomMessagePending
+	}
+	m.sentMessages <- customMessage{messageType: messageType, message: message}
+	sent := make(chan struct{})
+	close(sent)
+	return sent, nil
+}
+
+func (m *mockCustomCapabilityRegistry) Unregister() {
+	m.unregisterCalled = true
+}
+
+type hostWithExtensions struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h hostWithExtensions) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+func newOpAMPTestProcessor(t *testing.T, limit rate.Limit, registry *mockCustomCapabilityRegistry) *wsprocessor {
+	t.Helper()
+
+	settings := processortest.NewNopSettings()
+	settings.ID = component.MustNewIDWithName("remotetap", "logs")
+	processor := newProcessor(settings, &Config{Limit: limit, OpAMP: &opampID})
+	host := hostWithExtensions{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{opampID: registry},
+	}
+	require.NoError(t, processor.opamp.Start(host))
+	t.Cleanup(func() {
+		require.NoError(t, processor.Shutdown(context.Background()))
+	})
+	return processor
+}
+
+func sendRequest(t *testing.T, registry *mockCustomCapabilityRegistry, messageType string, req tapRequest) {
+	t.Helper()
+
+	b, err := json.Marshal(req)
+	require.NoError(t, err)
+	registry.messages <- &protobufs.CustomMessage{Capability: CustomCapability, Type: messageType, Data: b}
+}
+
+func waitForSessions(t *testing.T, processor *wsprocessor, sessions int) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		return processor.taps.len() == sessions
+	}, time.Second, 10*time.Millisecond)
+}
+
+func receiveDone(t *testing.T, registry *mockCustomCapabilityRegistry) tapDoneMessage {
+	t.Helper()
+
+	msg := <-registry.sentMessages
+	require.Equal(t, DoneMessageType, msg.messageType)
+	var done tapDoneMessage
+	require.NoError(t, json.Unmarshal(msg.message, &done))
+	return done
+}
+
+func testLogs(records int) plog.Logs {
+	logs := plog.NewLogs()
+	lrs := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for i := 0; i < records; i++ {
+		lrs.AppendEmpty().Body().SetStr("foo")
+	}
+	return logs
+}
+
+func TestOpAMPTapperStart(t *testing.T) {
+	testCases := []struct {
+		name          string
+		extensions    map[component.ID]component.Component
+		expectedError string
+	}{
+		{
+			name:          "Extension does not exist",
+			extensions:    map[component.ID]component.Component{},
+			expectedError: `extension "opamp" does not exist`,
+		},
+		{
+			name:          "Extension is not a registry",
+			extensions:    map[component.ID]component.Component{opampID: struct{ component.Component }{}},
+			expectedError: `extension "opamp" is not a custom message registry`,
+		},
+		{
+			name:          "Register fails",
+			extensions:    map[component.ID]component.Component{opampID: &mockCustomCapabilityRegistry{shouldFailRegister: true}},
+			expectedError: "failed to register custom capability: register failed",
+		},
+		{
+			name:          "Register returns a nil handler",
+			extensions:    map[component.ID]component.Component{opampID: &mockCustomCapabilityRegistry{shouldRegisterReturnNilHandler: true}},
+			expectedError: "custom capability handler is nil",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tapper := newOpAMPTapper(component.MustNewID("remotetap"), opampID, 1, newChannelSet[tappedData](), nil)
+			err := tapper.Start(hostWithExtensions{extensions: tc.extensions})
+			assert.EqualError(t, err, tc.expectedError)
+			tapper.Shutdown()
+		})
+	}
+}
+
+func TestOpAMPTapSession(t *testing.T) {
+	registry := newMockCustomCapabilityRegistry()
+	processor := newOpAMPTestProcessor(t, 100, registry)
+	assert.Equal(t, CustomCapability, registry.registeredCapability)
+
+	// Requests for other processors are ignored
+	sendRequest(t, registry, TapMessageType, tapRequest{RequestID: "other", Processor: "remotetap/traces"})
+	sendRequest(t, registry, TapMessageType, tapRequest{RequestID: "1", Processor: "remotetap/logs", Count: 3})
+	waitForSessions(t, processor, 1)
+
+	for i := 0; i < 2; i++ {
+		_, err := processor.ConsumeLogs(context.Background(), testLogs(2))
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		msg := <-registry.sentMessages
+		require.Equal(t, DataMessageType, msg.messageType)
+		var data tapDataMessage
+		require.NoError(t, json.Unmarshal(msg.message, &data))
+		assert.Equal(t, "1", data.RequestID)
+		assert.Equal(t, signalLogs, data.Signal)
+
+		logs, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs(data.Data)
+		require.NoError(t, err)
+		assert.Equal(t, 2, logs.LogRecordCount())
+	}
+
+	assert.Equal(t, tapDoneMessage{RequestID: "1", Reason: doneReasonCompleted, Items: 4}, receiveDone(t, registry))
+	waitForSessions(t, processor, 0)
+}
+
+func TestOpAMPTapSessionSignal(t *testing.T) {
+	registry := newMockCustomCapabilityRegistry()
+	processor := newOpAMPTestProcessor(t, 100, registry)
+
+	sendRequest(t, registry, TapMessageType, tapRequest{RequestID: "1", Processor: "remotetap/logs", Signal: signalTraces, Count: 1})
+	waitForSessions(t, processor, 1)
+
+	_, err := processor.ConsumeLogs(context.Background(), testLogs(1))
+	require.NoError(t, err)
+	td := ptrace.NewTraces()
+	td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("foo")
+	_, err = processor.ConsumeTraces(context.Background(), td)
+	require.NoError(t, err)
+
+	msg := <-registry.sentMessages
+	require.Equal(t, DataMessageType, msg.messageType)
+	var data tapDataMessage
+	require.NoError(t, json.Unmarshal(msg.message, &data))
+	assert.Equal(t, signalTraces, data.Signal)
+
+	assert.Equal(t, tapDoneMessage{RequestID: "1", Reason: doneReasonCompleted, Items: 1}, receiveDone(t, registry))
+}
+
+func TestOpAMPTapSessionRateLimit(t *testing.T) {
+	registry := newMockCustomCapabilityRegistry()
+	processor := newOpAMPTestProcessor(t, 100, registry)
+
+	// The limit of the request applies, as it is lower than the processor's
+	sendRequest(t, registry, TapMessageType, tapRequest{RequestID: "1", Processor: "remotetap/logs", Limit: 0.001})
+	waitForSessions(t, processor, 1)
+	for i := 0; i < 3; i++ {
+		_, err := processor.ConsumeLogs(context.Background(), testLogs(1))
+		require.NoError(t, err)
+	}
+
+	msg := <-registry.sentMessages
+	require.Equal(t, DataMessageType, msg.messageType)
+
+	sendRequest(t, registry, StopMessageType, tapRequest{RequestID: "1", Processor: "remotetap/logs"})
+	assert.Equal(t, tapDoneMessage{RequestID: "1", Reason: doneReasonStopped, Items: 1, Dropped: 2}, receiveDone(t, registry))
+}
+
+// The session waits for the pending message until it ends.
+func TestOpAMPTapSessionPendingMessage(t *testing.T) {
+	registry := newMockCustomCapabilityRegistry()
+	registry.shouldReturnPending = true
+	processor := newOpAMPTestProcessor(t, 100, registry)
+
+	sendRequest(t, registry, TapMessageType, tapRequest{RequestID: "1", Processor: "remotetap/logs", Timeout: "100ms"})
+	waitForSessions(t, processor, 1)
+	_, err := processor.ConsumeLogs(context.Background(), testLogs(2))
+	require.NoError(t, err)
+
+	assert.Equal(t, tapDoneMessage{RequestID: "1", Reason: doneReasonTimeout, Dropped: 2}, receiveDone(t, registry))
+}
+
+func TestOpAMPTapSessionPendingMessageSent(t *testing.T) {
+	registry := newMockCustomCapabilityRegistry()
+	pending := make(chan struct{})
+	registry.dataSending = make(chan struct{}, 1)
+	registry.dataPending = pending
+	processor := newOpAMPTestProcessor(t, 100, registry)
+
+	sendRequest(t, registry, TapMessageType, tapRequest{RequestID: "1", Processor: "remotetap/logs", Signal: signalLogs})
+	waitForSessions(t, processor, 1)
+	processor.tap(tappedData{signal: signalLogs, items: 2})
+	<-registry.dataSending
+
+	// The batches are queued while the previous message is pending, and sent once it has been sent.
+	for i := 0; i < 3; i++ {
+		processor.tap(tappedData{signal: signalLogs, items: 1})
+	}
+	close(pending)
+	for i := 0; i < 4; i++ {
+		msg := <-registry.sentMessages
+		require.Equal(t, DataMessageType, msg.messageType)
+	}
+
+	sendRequest(t, registry, StopMessageType, tapRequest{RequestID: "1", Processor: "remotetap/logs"})
+	assert.Equal(t, tapDoneMessage{RequestID: "1", Reason: doneReasonStopped, Items: 5}, receiveDone(t, registry))
+}
+
+func TestOpAMPTapSessionPendingDoneMessage(t *testing.T) {
+	registry := newMockCustomCapabilityRegistry()
+	registry.shouldReturnDonePending = true
+	processor := newOpAMPTestProcessor(t, 100, registry)
+
+	sendRequest(t, registry, TapMessageType, tapRequest{RequestID: "1", Processor: "remotetap/logs"})
+	waitForSessions(t, processor, 1)
+	sendRequest(t, registry, StopMessageType, tapRequest{RequestID: "1", Processor: "remotetap/logs"})
+
+	// The session waits for the done message to be sent, which must not block the pipeline.
+	waitForSessions(t, processor, 0)
+	consumed := make(chan struct{})
+	go func() {
+		defer close(consumed)
+		for i := 0; i < 2*tapSessionBuffer; i++ {
+			_, err := processor.ConsumeLogs(context.Background(), testLogs(1))
+			assert.NoError(t, err)
+		}
+	}()
+	select {
+	case <-consumed:
+	case <-time.After(time.Second):
+		t.Fatal("the pipeline is blocked by the session")
+	}
+}
+
+func TestOpAMPTapSessionBusy(t *testing.T) {
+	registry := newMockCustomCapabilityRegistry()
+	registry.dataSending = make(chan struct{}, 100)
+	registry.dataRelease = make(chan struct{})
+	processor := newOpAMPTestProcessor(t, 100, registry)
+
+	sendRequest(t, registry, TapMessageType, tapRequest{RequestID: "1", Processor: "remotetap/logs", Signal: signalLogs})
+	waitForSessions(t, processor, 1)
+	processor.tap(tappedData{signal: signalLogs, items: 1})
+	<-registry.dataSending
+
+	// The session is sending the first batch, the next ones are buffered until the buffer is full.
+	for i := 0; i < tapSessionBuffer+2; i++ {
+		processor.tap(tappedData{signal: signalLogs, items: 1})
+	}
+	processor.tap(tappedData{signal: signalTraces, items: 1})
+	close(registry.dataRelease)
+	for i := 0; i < tapSessionBuffer; i++ {
+		<-registry.dataSending
+	}
+	sendRequest(t, registry, StopMessageType, tapRequest{RequestID: "1", Processor: "remotetap/logs"})
+
+	// The batches exceeding the buffer were dropped by the processor.
+	var done tapDoneMessage
+	for msg := range registry.sentMessages {
+		if msg.messageType == DoneMessageType {
+			require.NoError(t, json.Unmarshal(msg.message, &done))
+			break
+		}
+	}
+	assert.Equal(t, tapDoneMessage{RequestID: "1", Reason: doneReasonStopped, Items: tapSessionBuffer + 1, Dropped: 2}, done)
+}
+
+func TestOpAMPTapSessionShutdown(t *testing.T) {
+	registry := newMockCustomCapabilityRegistry()
+	settings := processortest.NewNopSettings()
+	settings.ID = component.MustNewIDWithName("remotetap", "logs")
+	processor := newProcessor(settings, &Config{Limit: 1, OpAMP: &opampID})
+	require.NoError(t, processor.opamp.Start(hostWithExtensions{extensions: map[component.ID]component.Component{opampID: registry}}))
+
+	sendRequest(t, registry, TapMessageType, tapRequest{RequestID: "1", Processor: "remotetap/logs"})
+	waitForSessions(t, processor, 1)
+
+	require.NoError(t, processor.Shutdown(context.Background()))
+	assert.Equal(t, tapDoneMessage{RequestID: "1", Reason: doneReasonShutdown}, receiveDone(t, registry))
+	assert.True(t, registry.unregisterCalled)
+	assert.Equal(t, 0, processor.taps.len())
+}
+
+func TestOpAMPTapRequestRejected(t *testing.T) {
+	testCases := []struct {
+		name          string
+		req           tapRequest
+		expectedError string
+	}{
+		{
+			name:          "Missing request ID",
+			req:           tapRequest{},
+			expectedError: "the request_id is missing",
+		},
+		{
+			name:          "Unknown signal",
+			req:           tapRequest{RequestID: "1", Signal: "profiles"},
+			expectedError: `unknown signal "profiles"`,
+		},
+		{
+			name:          "Negative count",
+			req:           tapRequest{RequestID: "1", Count: -1},
+			expectedError: "the count must be positive",
+		},
+		{
+			name:          "Negative limit",
+			req:           tapRequest{RequestID: "1", Limit: -1},
+			expectedError: "the limit must be positive",
+		},
+		{
+			name:          "Invalid timeout",
+			req:           tapRequest{RequestID: "1", Timeout: "soon"},
+			expectedError: `invalid timeout: time: invalid duration "soon"`,
+		},
+		{
+			name:          "Negative timeout",
+			req:           tapRequest{RequestID: "1", Timeout: "-1s"},
+			expectedError: "the timeout must be positive",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := newMockCustomCapabilityRegistry()
+			newOpAMPTestProcessor(t, 1, registry)
+
+			tc.req.Processor = "remotetap/logs"
+			sendRequest(t, registry, TapMessageType, tc.req)
+			assert.Equal(t, tapDoneMessage{RequestID: tc.req.RequestID, Reason: doneReasonRejected, Error: tc.expectedError}, receiveDone(t, registry))
+		})
+	}
+}
+
+func TestOpAMPTapRequestRejectedSessions(t *testing.T) {
+	registry := newMockCustomCapabilityRegistry()
+	processor := newOpAMPTestProcessor(t, 1, registry)
+
+	sendRequest(t, registry, TapMessageType, tapRequest{RequestID: "0", Processor: "remotetap/logs"})
+	sendRequest(t, registry, TapMessageType, tapRequest{RequestID: "0", Processor: "remotetap/logs"})
+	assert.Equal(t, tapDoneMessage{RequestID: "0", Reason: doneReasonRejected, Error: `the session "0" is already running`}, receiveDone(t, registry))
+
+	for i := 1; i < maxTapSessions; i++ {
+		sendRequest(t, registry, TapMessageType, tapRequest{RequestID: string(rune('0' + i)), Processor: "remotetap/logs"})
+	}
+	waitForSessions(t, processor, maxTapSessions)
+	sendRequest(t, registry, TapMessageType, tapRequest{RequestID: "overflow", Processor: "remotetap/logs"})
+	assert.Equal(t, tapDoneMessage{RequestID: "overflow", Reason: doneReasonRejected, Error: "too many sessions, at most 10 sessions can run at the same time"}, receiveDone(t, registry))
+
+}
@@ -28,8 +28,11 @@ type wsprocessor struct {
 	telemetrySettings component.TelemetrySettings
 	server            *http.Server
 	shutdownWG        sync.WaitGroup
-	cs                *channelSet
+	cs                *channelSet[[]byte]
 	limiter           *rate.Limiter
+	// taps are the channels of the tap sessions requested over OpAMP.
+	taps  *channelSet[tappedData]
+	opamp *opampTapper
 }
 
 var (
@@ -39,15 +42,26 @@ var (
 )
 
 func newProcessor(settings processor.Settings, config *Config) *wsprocessor {
-	return &wsprocessor{
+	w := &wsprocessor{
 		config:            config,
 		telemetrySettings: settings.TelemetrySettings,
-		cs:                newChannelSet(),
+		cs:                newChannelSet[[]byte](),
 		limiter:           rate.NewLimiter(config.Limit, int(config.Limit)),
+		taps:              newChannelSet[tappedData](),
 	}
+	if config.OpAMP != nil {
+		w.opamp = newOpAMPTapper(settings.ID, *config.OpAMP, config.Limit, w.taps, settings.Logger)
+	}
+	return w
 }
 
 func (w *wsprocessor) Start(ctx context.Context, host component.Host) error {
+	if w.opamp != nil {
+		if err := w.opamp.Start(host); err != nil {
+			return err
+		}
+	}
+
 	var err error
 	var ln net.Listener
 	ln, err = w.config.ServerConfig.ToListener(ctx)
@@ -89,6 +103,15 @@ func (w *wsprocessor) handleConn(conn *websocket.Conn) {
 func (w *wsprocessor) Shutdown(ctx context.Context) error {
 	var err error
 
+	// Tap sessions are stopped first, so that their channels are removed
+	// from the channelset before it is shutdown.
+	if w.opamp != nil {
+		w.opamp.Shutdown()
+	}
+	if w.taps != nil {
+		w.taps.shutdown()
+	}
+
 	if w.server != nil {
 		err = w.server.Shutdown(ctx)
 		w.shutdownWG.Wait()
@@ -104,12 +127,19 @@ func (w *wsprocessor) Shutdown(ctx context.Context) error {
 }
 
 func (w *wsprocessor) ConsumeMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
-	if w.limiter.Allow() {
+	allowed := w.limiter.Allow()
+	tapped := w.taps.len() > 0
+	if allowed || tapped {
 		b, err := metricMarshaler.MarshalMetrics(md)
 		if err != nil {
 			w.telemetrySettings.Logger.Debug("Error serializing to JSON", zap.Error(err))
 		} else {
-			w.cs.writeBytes(b)
+			if allowed {
+				w.cs.write(b)
+			}
+			if tapped {
+				w.tap(tappedData{signal: signalMetrics, items: md.DataPointCount(), data: b})
+			}
 		}
 	}
 
@@ -117,12 +147,19 @@ func (w *wsprocessor) ConsumeMetrics(_ context.Context, md pmetric.Metrics) (pme
 }
 
 func (w *wsprocessor) ConsumeLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
-	if w.limiter.Allow() {
+	allowed := w.limiter.Allow()
+	tapped := w.taps.len() > 0
+	if allowed || tapped {
 		b, err := logMarshaler.MarshalLogs(ld)
 		if err != nil {
 			w.telemetrySettings.Logger.Debug("Error serializing to JSON", zap.Error(err))
 		} else {
-			w.cs.writeBytes(b)
+			if allowed {
+				w.cs.write(b)
+			}
+			if tapped {
+				w.tap(tappedData{signal: signalLogs, items: ld.LogRecordCount(), data: b})
+			}
 		}
 	}
 
@@ -130,14 +167,30 @@ func (w *wsprocessor) ConsumeLogs(_ context.Context, ld plog.Logs) (plog.Logs, e
 }
 
 func (w *wsprocessor) ConsumeTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
-	if w.limiter.Allow() {
+	allowed := w.limiter.Allow()
+	tapped := w.taps.len() > 0
+	if allowed || tapped {
 		b, err := traceMarshaler.MarshalTraces(td)
 		if err != nil {
 			w.telemetrySettings.Logger.Debug("Error serializing to JSON", zap.Error(err))
 		} else {
-			w.cs.writeBytes(b)
+			if allowed {
+				w.cs.write(b)
+			}
+			if tapped {
+				w.tap(tappedData{signal: signalTraces, items: td.SpanCount(), data: b})
+			}
 		}
 	}
 
 	return td, nil
 }
+
+// tap hands the data to the tap sessions without blocking the pipeline. The
+// data is dropped for the sessions which are busy sending data to the OpAMP
+// server.
+func (w *wsprocessor) tap(data tappedData) {
+	if skipped := w.taps.tryWrite(data); len(skipped) > 0 {
+		w.opamp.drop(skipped, data)
+	}
+}
@@ -27,6 +27,7 @@ require (
 )
 
 require (
+	go.opentelemetry.io/collector/client v1.22.1-0.20241220212031-7c2639723f67 // indirect
 	go.opentelemetry.io/collector/component/componenttest v0.116.1-0.20241220212031-7c2639723f67
 	go.opentelemetry.io/collector/consumer/consumertest v0.116.1-0.20241220212031-7c2639723f67
 	go.opentelemetry.io/collector/processor/processortest v0.116.1-0.20241220212031-7c2639723f67
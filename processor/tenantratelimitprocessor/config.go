@@ -0,0 +1,22 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tenantratelimitprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/tenantratelimitprocessor"
+
+import (
+	"go.opentelemetry.io/collector/component"
+)
+
+// Config defines the configuration of the tenant rate limit processor.
+type Config struct {
+	// Extension is the tenantauth extension authenticating the requests, whose tenants and rate limits are used.
+	Extension component.ID `mapstructure:"extension"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		Extension: component.MustNewID("tenantauth"),
+	}
+}
@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+// Package tenantratelimitprocessor enforces the rate limits of the tenants authenticated by the tenantauth
+// extension, rejecting the requests of the tenants exceeding them with a ResourceExhausted error.
+package tenantratelimitprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/tenantratelimitprocessor"
@@ -0,0 +1,48 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tenantratelimitprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/tenantratelimitprocessor"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/tenantauthextension"
+)
+
+type tenantRateLimitProcessor struct {
+	cfg     *Config
+	limiter tenantauthextension.RateLimiter
+}
+
+func newProcessor(cfg *Config) *tenantRateLimitProcessor {
+	return &tenantRateLimitProcessor{cfg: cfg}
+}
+
+func (p *tenantRateLimitProcessor) start(_ context.Context, host component.Host) error {
+	limiter, err := tenantauthextension.GetRateLimiter(host, p.cfg.Extension)
+	if err != nil {
+		return err
+	}
+	p.limiter = limiter
+	return nil
+}
+
+// The data of the requests within the rate limit of their tenant is passed on unchanged. The error of
+// the other requests is returned as is, so that receivers report its ResourceExhausted status code.
+
+func (p *tenantRateLimitProcessor) processTraces(ctx context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	return td, p.limiter.Allow(ctx)
+}
+
+func (p *tenantRateLimitProcessor) processMetrics(ctx context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	return md, p.limiter.Allow(ctx)
+}
+
+func (p *tenantRateLimitProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	return ld, p.limiter.Allow(ctx)
+}
@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tenantratelimitprocessor
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/auth"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"go.opentelemetry.io/collector/processor/processortest"
+	"go.opentelemetry.io/collector/receiver/otlpreceiver"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/tenantauthextension"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/testutil"
+)
+
+var tenantAuthID = component.MustNewID("tenantauth")
+
+type testHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h *testHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+// newTestHost returns a host with a started tenantauth extension, whose tenant team-a is identified by the
+// token-a bearer token and is allowed a single request.
+func newTestHost(t *testing.T) component.Host {
+	extFactory := tenantauthextension.NewFactory()
+	extCfg := extFactory.CreateDefaultConfig().(*tenantauthextension.Config)
+	extCfg.Tenants = map[string]tenantauthextension.TenantSettings{
+		"team-a": {
+			Tokens:    []configopaque.String{"token-a"},
+			RateLimit: &tenantauthextension.RateLimitSettings{RequestsPerSecond: 0.001},
+		},
+		"team-b": {Tokens: []configopaque.String{"token-b"}},
+	}
+	ext, err := extFactory.Create(context.Background(), extensiontest.NewNopSettings(), extCfg)
+	require.NoError(t, err)
+	host := &testHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{tenantAuthID: ext},
+	}
+	require.NoError(t, ext.Start(context.Background(), host))
+	t.Cleanup(func() {
+		assert.NoError(t, ext.Shutdown(context.Background()))
+	})
+	return host
+}
+
+func TestProcess(t *testing.T) {
+	ctx := context.Background()
+	host := newTestHost(t)
+	authenticate := func(token string) context.Context {
+		authCtx, err := host.GetExtensions()[tenantAuthID].(auth.Server).Authenticate(ctx,
+			map[string][]string{"authorization": {"Bearer " + token}})
+		require.NoError(t, err)
+		return authCtx
+	}
+	ctxA, ctxB := authenticate("token-a"), authenticate("token-b")
+
+	tracesSink := &consumertest.TracesSink{}
+	traces, err := NewFactory().CreateTraces(ctx, processortest.NewNopSettings(), createDefaultConfig(), tracesSink)
+	require.NoError(t, err)
+	require.NoError(t, traces.Start(ctx, host))
+	metricsSink := &consumertest.MetricsSink{}
+	metrics, err := NewFactory().CreateMetrics(ctx, processortest.NewNopSettings(), createDefaultConfig(), metricsSink)
+	require.NoError(t, err)
+	require.NoError(t, metrics.Start(ctx, host))
+	logsSink := &consumertest.LogsSink{}
+	logs, err := NewFactory().CreateLogs(ctx, processortest.NewNopSettings(), createDefaultConfig(), logsSink)
+	require.NoError(t, err)
+	require.NoError(t, logs.Start(ctx, host))
+
+	// The requests of every signal share the rate limit of the tenant.
+	require.NoError(t, traces.ConsumeTraces(ctxA, ptrace.NewTraces()))
+	err = metrics.ConsumeMetrics(ctxA, pmetric.NewMetrics())
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	err = logs.ConsumeLogs(ctxA, plog.NewLogs())
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Len(t, tracesSink.AllTraces(), 1)
+	assert.Empty(t, metricsSink.AllMetrics())
+	assert.Empty(t, logsSink.AllLogs())
+
+	// The requests of tenants without a rate limit, or without a tenant, are passed on.
+	require.NoError(t, metrics.ConsumeMetrics(ctxB, pmetric.NewMetrics()))
+	require.NoError(t, logs.ConsumeLogs(ctx, plog.NewLogs()))
+	assert.Len(t, metricsSink.AllMetrics(), 1)
+	assert.Len(t, logsSink.AllLogs(), 1)
+
+	assert.NoError(t, traces.Shutdown(ctx))
+	assert.NoError(t, metrics.Shutdown(ctx))
+	assert.NoError(t, logs.Shutdown(ctx))
+}
+
+// TestOTLPReceiver checks the errors reported to the clients of the OTLP receiver.
+func TestOTLPReceiver(t *testing.T) {
+	ctx := context.Background()
+	host := newTestHost(t)
+
+	sink := &consumertest.TracesSink{}
+	p, err := NewFactory().CreateTraces(ctx, processortest.NewNopSettings(), createDefaultConfig(), sink)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(ctx, host))
+	defer func() {
+		assert.NoError(t, p.Shutdown(ctx))
+	}()
+
+	grpcEndpoint := testutil.GetAvailableLocalAddress(t)
+	httpEndpoint := testutil.GetAvailableLocalAddress(t)
+	factory := otlpreceiver.NewFactory()
+	cfg := factory.CreateDefaultConfig().(*otlpreceiver.Config)
+	cfg.GRPC.NetAddr.Endpoint = grpcEndpoint
+	cfg.GRPC.Auth = &configauth.Authentication{AuthenticatorID: tenantAuthID}
+	cfg.HTTP.ServerConfig.Endpoint = httpEndpoint
+	cfg.HTTP.ServerConfig.Auth = &confighttp.AuthConfig{Authentication: configauth.Authentication{AuthenticatorID: tenantAuthID}}
+	rcv, err := factory.CreateTraces(ctx, receivertest.NewNopSettings(), cfg, p)
+	require.NoError(t, err)
+	require.NoError(t, rcv.Start(ctx, host))
+	defer func() {
+		assert.NoError(t, rcv.Shutdown(ctx))
+	}()
+
+	// The receivers don't pass on empty requests.
+	td := ptrace.NewTraces()
+	td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("span")
+	request := ptraceotlp.NewExportRequestFromTraces(td)
+
+	// gRPC clients get the ResourceExhausted status code, with a RetryInfo telling when to retry.
+	conn, err := grpc.NewClient(grpcEndpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := ptraceotlp.NewGRPCClient(conn)
+	grpcCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer token-a")
+	_, err = client.Export(grpcCtx, request)
+	require.NoError(t, err)
+	_, err = client.Export(grpcCtx, request)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Len(t, status.Convert(err).Details(), 1)
+
+	// HTTP clients get 429 Too Many Requests.
+	body, err := request.MarshalProto()
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "http://"+httpEndpoint+"/v1/traces", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Authorization", "Bearer token-a")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	assert.Len(t, sink.AllTraces(), 1)
+}
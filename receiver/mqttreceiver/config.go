@@ -0,0 +1,64 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqttreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/mqttreceiver"
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+
+	internalmqtt "github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt"
+)
+
+// Config defines configuration for the MQTT receiver.
+type Config struct {
+	internalmqtt.ClientConfig `mapstructure:",squash"`
+
+	// QoS is the maximum quality of service of the messages delivered by the broker: 0, 1 or 2.
+	QoS byte `mapstructure:"qos"`
+
+	Traces  SignalConfig `mapstructure:"traces"`
+	Metrics SignalConfig `mapstructure:"metrics"`
+	Logs    SignalConfig `mapstructure:"logs"`
+}
+
+// SignalConfig defines the subscription of a signal.
+type SignalConfig struct {
+	// Topic is the topic filter subscribed to. Single-level wildcards can be named after the resource
+	// attribute set to the level of the topic, e.g. devices/{device.id}/logs.
+	Topic string `mapstructure:"topic"`
+	// Encoding of the payloads: otlp_proto, otlp_json, or the ID of an encoding extension.
+	Encoding string `mapstructure:"encoding"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks the receiver configuration is valid.
+func (cfg *Config) Validate() error {
+	var errs []error
+	if cfg.QoS > 2 {
+		errs = append(errs, fmt.Errorf("invalid qos %d, it must be 0, 1 or 2", cfg.QoS))
+	}
+	if err := cfg.Traces.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("traces: %w", err))
+	}
+	if err := cfg.Metrics.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("metrics: %w", err))
+	}
+	if err := cfg.Logs.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("logs: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func (cfg SignalConfig) validate() error {
+	if _, err := parseTopicFilter(cfg.Topic); err != nil {
+		return err
+	}
+	if cfg.Encoding == "" {
+		return errors.New("encoding must be specified")
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqttreceiver
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+
+	internalmqtt "github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/mqttreceiver/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	defaultConfig := createDefaultConfig().(*Config)
+
+	tests := []struct {
+		id          component.ID
+		expected    component.Config
+		expectedErr string
+	}{
+		{
+			id:       component.NewID(metadata.Type),
+			expected: defaultConfig,
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "all_fields"),
+			expected: &Config{
+				ClientConfig: internalmqtt.ClientConfig{
+					Endpoint: "ssl://broker.example.com:8883",
+					ClientID: "gateway-1",
+					TLS: &configtls.ClientConfig{
+						Config: configtls.Config{CAFile: "ca.pem"},
+					},
+					Username:        "collector",
+					Password:        "secret",
+					ProtocolVersion: internalmqtt.ProtocolVersion31,
+					CleanSession:    false,
+					KeepAlive:       time.Minute,
+					ConnectTimeout:  5 * time.Second,
+				},
+				QoS:    2,
+				Traces: defaultConfig.Traces,
+				Metrics: SignalConfig{
+					Topic:    "sites/{site.id}/devices/{device.id}/metrics",
+					Encoding: "otlp_json",
+				},
+				Logs: SignalConfig{
+					Topic:    "sites/+/devices/{device.id}/logs/#",
+					Encoding: "text_encoding",
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "invalid"),
+			expectedErr: "invalid qos 3, it must be 0, 1 or 2\n" +
+				`traces: invalid topic "devices/#/traces": '#' must be the last level` + "\n" +
+				`metrics: invalid topic "devices/{}/metrics": invalid attribute placeholder "{}"` + "\n" +
+				`logs: invalid topic "devices/device{id}/logs": level "device{id}" must either be a wildcard, an attribute placeholder, or must not contain '{', '}', '+' or '#'; ` +
+				"unsupported protocol_version 6, it must be 3 (MQTT 3.1), 4 (MQTT 3.1.1) or 5 (MQTT 5)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, component.ValidateConfig(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, component.ValidateConfig(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
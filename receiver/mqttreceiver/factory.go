@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqttreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/mqttreceiver"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/encoding"
+	internalmqtt "github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/sharedcomponent"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/mqttreceiver/internal/metadata"
+)
+
+const defaultQoS = 1
+
+// NewFactory creates a factory for the MQTT receiver.
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		receiver.WithTraces(createTracesReceiver, metadata.TracesStability),
+		receiver.WithMetrics(createMetricsReceiver, metadata.MetricsStability),
+		receiver.WithLogs(createLogsReceiver, metadata.LogsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		ClientConfig: internalmqtt.NewDefaultClientConfig(),
+		QoS:          defaultQoS,
+		Traces: SignalConfig{
+			Topic:    "otlp/traces",
+			Encoding: encoding.OTLPProto,
+		},
+		Metrics: SignalConfig{
+			Topic:    "otlp/metrics",
+			Encoding: encoding.OTLPProto,
+		},
+		Logs: SignalConfig{
+			Topic:    "otlp/logs",
+			Encoding: encoding.OTLPProto,
+		},
+	}
+}
+
+func createTracesReceiver(_ context.Context, set receiver.Settings, cfg component.Config, next consumer.Traces) (receiver.Traces, error) {
+	r, err := getOrAddReceiver(cfg.(*Config), set)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Unwrap().(*mqttReceiver).registerTracesConsumer(next); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func createMetricsReceiver(_ context.Context, set receiver.Settings, cfg component.Config, next consumer.Metrics) (receiver.Metrics, error) {
+	r, err := getOrAddReceiver(cfg.(*Config), set)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Unwrap().(*mqttReceiver).registerMetricsConsumer(next); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func createLogsReceiver(_ context.Context, set receiver.Settings, cfg component.Config, next consumer.Logs) (receiver.Logs, error) {
+	r, err := getOrAddReceiver(cfg.(*Config), set)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Unwrap().(*mqttReceiver).registerLogsConsumer(next); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// getOrAddReceiver returns the receiver of a configuration, so that the signals share a single client.
+func getOrAddReceiver(cfg *Config, set receiver.Settings) (*sharedcomponent.SharedComponent, error) {
+	var err error
+	r := receivers.GetOrAdd(cfg, func() component.Component {
+		var rcv *mqttReceiver
+		rcv, err = newMQTTReceiver(cfg, set)
+		return rcv
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+var receivers = sharedcomponent.NewSharedComponents()
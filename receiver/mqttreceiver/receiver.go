@@ -0,0 +1,199 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqttreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/mqttreceiver"
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/encoding"
+	internalmqtt "github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt"
+)
+
+const (
+	transport = "mqtt"
+
+	// disconnectQuiesce is the time given to the client to complete the work in progress when disconnecting.
+	disconnectQuiesce = 250 * time.Millisecond
+)
+
+// consumeFunc unmarshals a payload, sets the attributes captured from its topic, and passes it to the
+// next consumer. It returns the number of spans, data points or log records of the payload.
+type consumeFunc func(ctx context.Context, topic string, payload []byte) (int, error)
+
+// subscription is the subscription of a signal to its topic.
+type subscription struct {
+	signal     SignalConfig
+	topic      *topicFilter
+	newConsume func(host component.Host) (consumeFunc, error)
+	startOp    func(context.Context) context.Context
+	endOp      func(ctx context.Context, format string, count int, err error)
+	consume    consumeFunc
+}
+
+// mqttReceiver subscribes to the topics of the signals of its pipelines with a single client.
+type mqttReceiver struct {
+	config        *Config
+	settings      receiver.Settings
+	obsrecv       *receiverhelper.ObsReport
+	subscriptions []*subscription
+
+	client internalmqtt.Client
+}
+
+func newMQTTReceiver(config *Config, set receiver.Settings) (*mqttReceiver, error) {
+	obsrecv, err := receiverhelper.NewObsReport(receiverhelper.ObsReportSettings{
+		ReceiverID:             set.ID,
+		Transport:              transport,
+		ReceiverCreateSettings: set,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &mqttReceiver{
+		config:   config,
+		settings: set,
+		obsrecv:  obsrecv,
+	}, nil
+}
+
+func (r *mqttReceiver) registerTracesConsumer(next consumer.Traces) error {
+	return r.register(r.config.Traces, r.obsrecv.StartTracesOp, r.obsrecv.EndTracesOp, func(host component.Host, topic *topicFilter) (consumeFunc, error) {
+		unmarshaler, err := encoding.NewTracesUnmarshaler(r.config.Traces.Encoding, host)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, name string, payload []byte) (int, error) {
+			traces, err := unmarshaler.UnmarshalTraces(payload)
+			if err != nil {
+				return 0, fmt.Errorf("failed to unmarshal traces: %w", err)
+			}
+			rss := traces.ResourceSpans()
+			for i := 0; i < rss.Len(); i++ {
+				topic.putAttributes(name, rss.At(i).Resource().Attributes())
+			}
+			return traces.SpanCount(), next.ConsumeTraces(ctx, traces)
+		}, nil
+	})
+}
+
+func (r *mqttReceiver) registerMetricsConsumer(next consumer.Metrics) error {
+	return r.register(r.config.Metrics, r.obsrecv.StartMetricsOp, r.obsrecv.EndMetricsOp, func(host component.Host, topic *topicFilter) (consumeFunc, error) {
+		unmarshaler, err := encoding.NewMetricsUnmarshaler(r.config.Metrics.Encoding, host)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, name string, payload []byte) (int, error) {
+			metrics, err := unmarshaler.UnmarshalMetrics(payload)
+			if err != nil {
+				return 0, fmt.Errorf("failed to unmarshal metrics: %w", err)
+			}
+			rms := metrics.ResourceMetrics()
+			for i := 0; i < rms.Len(); i++ {
+				topic.putAttributes(name, rms.At(i).Resource().Attributes())
+			}
+			return metrics.DataPointCount(), next.ConsumeMetrics(ctx, metrics)
+		}, nil
+	})
+}
+
+func (r *mqttReceiver) registerLogsConsumer(next consumer.Logs) error {
+	return r.register(r.config.Logs, r.obsrecv.StartLogsOp, r.obsrecv.EndLogsOp, func(host component.Host, topic *topicFilter) (consumeFunc, error) {
+		unmarshaler, err := encoding.NewLogsUnmarshaler(r.config.Logs.Encoding, host)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, name string, payload []byte) (int, error) {
+			logs, err := unmarshaler.UnmarshalLogs(payload)
+			if err != nil {
+				return 0, fmt.Errorf("failed to unmarshal logs: %w", err)
+			}
+			rls := logs.ResourceLogs()
+			for i := 0; i < rls.Len(); i++ {
+				topic.putAttributes(name, rls.At(i).Resource().Attributes())
+			}
+			return logs.LogRecordCount(), next.ConsumeLogs(ctx, logs)
+		}, nil
+	})
+}
+
+func (r *mqttReceiver) register(
+	signal SignalConfig,
+	startOp func(context.Context) context.Context,
+	endOp func(ctx context.Context, format string, count int, err error),
+	newConsume func(host component.Host, topic *topicFilter) (consumeFunc, error),
+) error {
+	topic, err := parseTopicFilter(signal.Topic)
+	if err != nil {
+		return err
+	}
+	for _, s := range r.subscriptions {
+		if s.topic.filter == topic.filter {
+			return fmt.Errorf("the topic %q is already subscribed to by another signal", signal.Topic)
+		}
+	}
+	r.subscriptions = append(r.subscriptions, &subscription{
+		signal:  signal,
+		topic:   topic,
+		startOp: startOp,
+		endOp:   endOp,
+		newConsume: func(host component.Host) (consumeFunc, error) {
+			return newConsume(host, topic)
+		},
+	})
+	return nil
+}
+
+// Start connects to the broker and subscribes to the topics of the signals.
+func (r *mqttReceiver) Start(ctx context.Context, host component.Host) error {
+	for _, s := range r.subscriptions {
+		consume, err := s.newConsume(host)
+		if err != nil {
+			return err
+		}
+		s.consume = consume
+	}
+
+	var err error
+	r.client, err = internalmqtt.Connect(ctx, &r.config.ClientConfig, "otelcol-receiver-", r.settings.Logger)
+	if err != nil {
+		return err
+	}
+	subscriptions := make([]internalmqtt.Subscription, 0, len(r.subscriptions))
+	for _, s := range r.subscriptions {
+		subscriptions = append(subscriptions, internalmqtt.Subscription{
+			Filter: s.topic.filter,
+			Handler: func(topic string, payload []byte) {
+				r.handleMessage(s, topic, payload)
+			},
+		})
+	}
+	return r.client.Subscribe(ctx, r.config.QoS, subscriptions)
+}
+
+// handleMessage consumes a message. The message is acknowledged once the handler returns, so that the
+// broker doesn't deliver more messages than the receiver consumes.
+func (r *mqttReceiver) handleMessage(s *subscription, topic string, payload []byte) {
+	ctx := s.startOp(context.Background())
+	count, err := s.consume(ctx, topic, payload)
+	s.endOp(ctx, s.signal.Encoding, count, err)
+	if err != nil {
+		r.settings.Logger.Error("Failed to consume a message", zap.String("topic", topic), zap.Error(err))
+	}
+}
+
+// Shutdown disconnects from the broker.
+func (r *mqttReceiver) Shutdown(context.Context) error {
+	if r.client != nil {
+		r.client.Disconnect(disconnectQuiesce)
+	}
+	return nil
+}
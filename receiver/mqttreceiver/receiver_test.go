@@ -0,0 +1,264 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqttreceiver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/encoding"
+	internalmqtt "github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/mqtt/mqtttest"
+)
+
+type testHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h *testHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+type textUnmarshaler struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+func (textUnmarshaler) UnmarshalLogs(data []byte) (plog.Logs, error) {
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr(string(data))
+	return logs, nil
+}
+
+func newTestConfig(broker *mqtttest.Broker) *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = broker.Endpoint()
+	return cfg
+}
+
+// waitForSubscriptions waits for the broker to register the subscriptions of the receiver.
+func waitForSubscriptions(t *testing.T, broker *mqtttest.Broker, count int) {
+	require.Eventually(t, func() bool {
+		return len(broker.Subscriptions()) == count
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestReceiveOTLP(t *testing.T) {
+	broker := mqtttest.NewBroker(t)
+	cfg := newTestConfig(broker)
+	cfg.Metrics.Encoding = encoding.OTLPJSON
+	set := receivertest.NewNopSettings()
+	factory := NewFactory()
+
+	tracesSink := &consumertest.TracesSink{}
+	tracesReceiver, err := factory.CreateTraces(context.Background(), set, cfg, tracesSink)
+	require.NoError(t, err)
+	metricsSink := &consumertest.MetricsSink{}
+	metricsReceiver, err := factory.CreateMetrics(context.Background(), set, cfg, metricsSink)
+	require.NoError(t, err)
+	logsSink := &consumertest.LogsSink{}
+	logsReceiver, err := factory.CreateLogs(context.Background(), set, cfg, logsSink)
+	require.NoError(t, err)
+	assert.Same(t, tracesReceiver, metricsReceiver)
+	assert.Same(t, tracesReceiver, logsReceiver)
+
+	require.NoError(t, tracesReceiver.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, tracesReceiver.Shutdown(context.Background()))
+	}()
+	waitForSubscriptions(t, broker, 3)
+	assert.Len(t, broker.ClientIDs(), 1)
+
+	traces := ptrace.NewTraces()
+	traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("span")
+	data, err := (&ptrace.ProtoMarshaler{}).MarshalTraces(traces)
+	require.NoError(t, err)
+	broker.Publish(mqtttest.Message{Topic: "otlp/traces", Payload: data, QoS: 1})
+
+	metrics := pmetric.NewMetrics()
+	metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(1)
+	data, err = (&pmetric.JSONMarshaler{}).MarshalMetrics(metrics)
+	require.NoError(t, err)
+	broker.Publish(mqtttest.Message{Topic: "otlp/metrics", Payload: data, QoS: 1})
+
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("log")
+	data, err = (&plog.ProtoMarshaler{}).MarshalLogs(logs)
+	require.NoError(t, err)
+	broker.Publish(mqtttest.Message{Topic: "otlp/logs", Payload: data, QoS: 0})
+
+	require.Eventually(t, func() bool {
+		return tracesSink.SpanCount() == 1 && metricsSink.DataPointCount() == 1 && logsSink.LogRecordCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "span", tracesSink.AllTraces()[0].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+	assert.Equal(t, "log", logsSink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+}
+
+func TestReceiveTopicAttributes(t *testing.T) {
+	for _, version := range []byte{internalmqtt.ProtocolVersion311, internalmqtt.ProtocolVersion5} {
+		t.Run(fmt.Sprintf("mqtt_%d", version), func(t *testing.T) {
+			broker := mqtttest.NewBroker(t)
+			cfg := newTestConfig(broker)
+			cfg.ProtocolVersion = uint(version)
+			cfg.Logs = SignalConfig{Topic: "sites/{site.id}/devices/{device.id}/logs/#", Encoding: "text_encoding"}
+			host := &testHost{
+				Host:       componenttest.NewNopHost(),
+				extensions: map[component.ID]component.Component{component.MustNewID("text_encoding"): textUnmarshaler{}},
+			}
+
+			sink := &consumertest.LogsSink{}
+			r, err := NewFactory().CreateLogs(context.Background(), receivertest.NewNopSettings(), cfg, sink)
+			require.NoError(t, err)
+			require.NoError(t, r.Start(context.Background(), host))
+			defer func() {
+				assert.NoError(t, r.Shutdown(context.Background()))
+			}()
+			waitForSubscriptions(t, broker, 1)
+			assert.Equal(t, []string{"sites/+/devices/+/logs/#"}, broker.Subscriptions())
+
+			broker.Publish(mqtttest.Message{Topic: "sites/paris/devices/42/logs/kernel", Payload: []byte("booted"), QoS: 1})
+			broker.Publish(mqtttest.Message{Topic: "sites/paris/devices/42/metrics", Payload: []byte("ignored"), QoS: 1})
+
+			require.Eventually(t, func() bool {
+				return sink.LogRecordCount() == 1
+			}, 5*time.Second, 10*time.Millisecond)
+			rl := sink.AllLogs()[0].ResourceLogs().At(0)
+			assert.Equal(t, map[string]any{"site.id": "paris", "device.id": "42"}, rl.Resource().Attributes().AsRaw())
+			assert.Equal(t, "booted", rl.ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+		})
+	}
+}
+
+func TestReceiveInvalidPayload(t *testing.T) {
+	broker := mqtttest.NewBroker(t)
+	cfg := newTestConfig(broker)
+	core, observed := observer.New(zap.ErrorLevel)
+	set := receivertest.NewNopSettings()
+	set.Logger = zap.New(core)
+
+	sink := &consumertest.LogsSink{}
+	r, err := NewFactory().CreateLogs(context.Background(), set, cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, r.Shutdown(context.Background()))
+	}()
+	waitForSubscriptions(t, broker, 1)
+
+	broker.Publish(mqtttest.Message{Topic: "otlp/logs", Payload: []byte("not protobuf"), QoS: 1})
+
+	require.Eventually(t, func() bool {
+		return observed.FilterMessage("Failed to consume a message").Len() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	entry := observed.FilterMessage("Failed to consume a message").All()[0]
+	assert.Equal(t, "otlp/logs", entry.ContextMap()["topic"])
+	assert.Zero(t, sink.LogRecordCount())
+}
+
+func TestResubscribeAfterReconnecting(t *testing.T) {
+	for _, version := range []byte{internalmqtt.ProtocolVersion311, internalmqtt.ProtocolVersion5} {
+		t.Run(fmt.Sprintf("mqtt_%d", version), func(t *testing.T) {
+			broker := mqtttest.NewBroker(t)
+			cfg := newTestConfig(broker)
+			cfg.ProtocolVersion = uint(version)
+
+			sink := &consumertest.LogsSink{}
+			r, err := NewFactory().CreateLogs(context.Background(), receivertest.NewNopSettings(), cfg, sink)
+			require.NoError(t, err)
+			require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+			defer func() {
+				assert.NoError(t, r.Shutdown(context.Background()))
+			}()
+			waitForSubscriptions(t, broker, 1)
+
+			broker.DisconnectClients()
+			require.Eventually(t, func() bool {
+				return len(broker.ClientIDs()) == 2 && len(broker.Subscriptions()) == 1
+			}, 10*time.Second, 10*time.Millisecond)
+			assert.Equal(t, []byte{version, version}, broker.ProtocolVersions())
+
+			logs := plog.NewLogs()
+			logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("log")
+			data, err := (&plog.ProtoMarshaler{}).MarshalLogs(logs)
+			require.NoError(t, err)
+			broker.Publish(mqtttest.Message{Topic: "otlp/logs", Payload: data, QoS: 1})
+
+			require.Eventually(t, func() bool {
+				return sink.LogRecordCount() == 1
+			}, 5*time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestDuplicateTopics(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Logs.Topic = "devices/{device.id}/otlp"
+	cfg.Metrics.Topic = "devices/+/otlp"
+	set := receivertest.NewNopSettings()
+
+	_, err := NewFactory().CreateLogs(context.Background(), set, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	_, err = NewFactory().CreateMetrics(context.Background(), set, cfg, consumertest.NewNop())
+	assert.EqualError(t, err, `the topic "devices/+/otlp" is already subscribed to by another signal`)
+}
+
+func TestEncodingErrors(t *testing.T) {
+	broker := mqtttest.NewBroker(t)
+	host := &testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{
+			component.MustNewID("text_encoding"): textUnmarshaler{},
+			component.MustNewID("nop"): struct {
+				component.StartFunc
+				component.ShutdownFunc
+			}{},
+		},
+	}
+	tests := []struct {
+		name        string
+		encoding    string
+		expectedErr string
+	}{
+		{
+			name:        "missing extension",
+			encoding:    "json_log_encoding",
+			expectedErr: `unknown encoding extension "json_log_encoding"`,
+		},
+		{
+			name:        "not an unmarshaler",
+			encoding:    "nop",
+			expectedErr: `extension "nop" is not an unmarshaler`,
+		},
+		{
+			name:        "invalid extension ID",
+			encoding:    "1nvalid",
+			expectedErr: `invalid encoding "1nvalid"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(broker)
+			cfg.Logs.Encoding = tt.encoding
+			r, err := NewFactory().CreateLogs(context.Background(), receivertest.NewNopSettings(), cfg, consumertest.NewNop())
+			require.NoError(t, err)
+			assert.ErrorContains(t, r.Start(context.Background(), host), tt.expectedErr)
+			assert.NoError(t, r.Shutdown(context.Background()))
+		})
+	}
+}
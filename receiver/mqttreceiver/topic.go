@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqttreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/mqttreceiver"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// topicFilter is a topic filter whose single-level wildcards can be named after a resource attribute,
+// e.g. devices/{device.id}/logs subscribes to devices/+/logs and sets device.id to the second level of the topic.
+type topicFilter struct {
+	// filter is the topic filter subscribed to, where named wildcards are replaced by +.
+	filter string
+	// attributes maps the index of the named levels to their attribute.
+	attributes map[int]string
+}
+
+func parseTopicFilter(topic string) (*topicFilter, error) {
+	if topic == "" {
+		return nil, errors.New("topic must be specified")
+	}
+
+	levels := strings.Split(topic, "/")
+	f := &topicFilter{attributes: map[int]string{}}
+	for i, level := range levels {
+		switch {
+		case level == "+":
+		case level == "#":
+			if i != len(levels)-1 {
+				return nil, fmt.Errorf("invalid topic %q: '#' must be the last level", topic)
+			}
+		case strings.HasPrefix(level, "{") && strings.HasSuffix(level, "}"):
+			attribute := level[1 : len(level)-1]
+			if attribute == "" || strings.ContainsAny(attribute, "{}+#") {
+				return nil, fmt.Errorf("invalid topic %q: invalid attribute placeholder %q", topic, level)
+			}
+			f.attributes[i] = attribute
+			levels[i] = "+"
+		case strings.ContainsAny(level, "{}+#"):
+			return nil, fmt.Errorf("invalid topic %q: level %q must either be a wildcard, an attribute placeholder, or must not contain '{', '}', '+' or '#'", topic, level)
+		}
+	}
+	f.filter = strings.Join(levels, "/")
+	return f, nil
+}
+
+// putAttributes sets the attributes named in the filter from the levels of a topic the filter matches.
+func (f *topicFilter) putAttributes(topic string, attrs pcommon.Map) {
+	if len(f.attributes) == 0 {
+		return
+	}
+	levels := strings.Split(topic, "/")
+	for i, attribute := range f.attributes {
+		if i < len(levels) {
+			attrs.PutStr(attribute, levels[i])
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package mqttreceiver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestParseTopicFilter(t *testing.T) {
+	tests := []struct {
+		topic      string
+		filter     string
+		attributes map[int]string
+	}{
+		{
+			topic:      "otlp/logs",
+			filter:     "otlp/logs",
+			attributes: map[int]string{},
+		},
+		{
+			topic:      "devices/+/logs/#",
+			filter:     "devices/+/logs/#",
+			attributes: map[int]string{},
+		},
+		{
+			topic:      "sites/{site.id}/devices/{device.id}/logs",
+			filter:     "sites/+/devices/+/logs",
+			attributes: map[int]string{1: "site.id", 3: "device.id"},
+		},
+		{
+			topic:      "{tenant}/#",
+			filter:     "+/#",
+			attributes: map[int]string{0: "tenant"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			f, err := parseTopicFilter(tt.topic)
+			require.NoError(t, err)
+			assert.Equal(t, tt.filter, f.filter)
+			assert.Equal(t, tt.attributes, f.attributes)
+		})
+	}
+}
+
+func TestParseTopicFilterErrors(t *testing.T) {
+	tests := []struct {
+		topic       string
+		expectedErr string
+	}{
+		{
+			topic:       "",
+			expectedErr: "topic must be specified",
+		},
+		{
+			topic:       "#/logs",
+			expectedErr: `invalid topic "#/logs": '#' must be the last level`,
+		},
+		{
+			topic:       "devices/{}/logs",
+			expectedErr: `invalid topic "devices/{}/logs": invalid attribute placeholder "{}"`,
+		},
+		{
+			topic:       "devices/{device+}/logs",
+			expectedErr: `invalid topic "devices/{device+}/logs": invalid attribute placeholder "{device+}"`,
+		},
+		{
+			topic:       "devices/dev+/logs",
+			expectedErr: `invalid topic "devices/dev+/logs": level "dev+" must either be a wildcard, an attribute placeholder, or must not contain '{', '}', '+' or '#'`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			_, err := parseTopicFilter(tt.topic)
+			assert.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestPutAttributes(t *testing.T) {
+	f, err := parseTopicFilter("sites/{site.id}/devices/{device.id}/#")
+	require.NoError(t, err)
+
+	attrs := pcommon.NewMap()
+	attrs.PutStr("device.id", "overwritten")
+	attrs.PutStr("service.name", "sensor")
+	f.putAttributes("sites/paris/devices/42/logs/kernel", attrs)
+	assert.Equal(t, map[string]any{
+		"site.id":      "paris",
+		"device.id":    "42",
+		"service.name": "sensor",
+	}, attrs.AsRaw())
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package natsjetstreamreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/natsjetstreamreceiver"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+
+	internalnats "github.com/open-telemetry/opentelemetry-collector-contrib/internal/nats"
+)
+
+// Config defines configuration for the NATS JetStream receiver.
+type Config struct {
+	internalnats.ClientConfig `mapstructure:",squash"`
+
+	// Stream is the name of the JetStream stream the messages are consumed from.
+	Stream string `mapstructure:"stream"`
+	// BatchSize is the maximum number of messages pulled at once from the stream.
+	BatchSize int `mapstructure:"batch_size"`
+	// AckWait is the time the server waits for a message to be acknowledged before delivering it again.
+	AckWait time.Duration `mapstructure:"ack_wait"`
+	// MaxDeliver is the maximum number of deliveries of a message. Messages are delivered until acknowledged if not set.
+	MaxDeliver int `mapstructure:"max_deliver"`
+	// RetryDelay is the delay before a message that the next consumer failed to accept is delivered again. It
+	// doubles with each delivery of the message, up to MaxRetryDelay.
+	RetryDelay time.Duration `mapstructure:"retry_delay"`
+	// MaxRetryDelay is the maximum delay before a message is delivered again.
+	MaxRetryDelay time.Duration `mapstructure:"max_retry_delay"`
+
+	Traces  SignalConfig `mapstructure:"traces"`
+	Metrics SignalConfig `mapstructure:"metrics"`
+	Logs    SignalConfig `mapstructure:"logs"`
+}
+
+// SignalConfig defines the durable consumer of a signal.
+type SignalConfig struct {
+	// Subject filters the subjects of the stream delivered to the consumer, e.g. otlp.traces.>
+	Subject string `mapstructure:"subject"`
+	// Consumer is the name of the durable pull consumer, which is created if it does not exist.
+	Consumer string `mapstructure:"consumer"`
+	// Encoding of the messages: otlp_proto, otlp_json, or the ID of an encoding extension.
+	Encoding string `mapstructure:"encoding"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks the receiver configuration is valid.
+func (cfg *Config) Validate() error {
+	var errs []error
+	if cfg.Stream == "" {
+		errs = append(errs, errors.New("stream must be specified"))
+	}
+	if cfg.BatchSize <= 0 {
+		errs = append(errs, errors.New("batch_size must be greater than 0"))
+	}
+	if cfg.AckWait < 0 {
+		errs = append(errs, errors.New("ack_wait must not be negative"))
+	}
+	if cfg.RetryDelay < 0 {
+		errs = append(errs, errors.New("retry_delay must not be negative"))
+	}
+	if cfg.MaxRetryDelay < cfg.RetryDelay {
+		errs = append(errs, errors.New("max_retry_delay must not be less than retry_delay"))
+	}
+
+	if err := cfg.Traces.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("traces: %w", err))
+	}
+	if err := cfg.Metrics.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("metrics: %w", err))
+	}
+	if err := cfg.Logs.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("logs: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func (cfg SignalConfig) validate() error {
+	if cfg.Consumer == "" {
+		return errors.New("consumer must be specified")
+	}
+	if strings.ContainsAny(cfg.Consumer, " \t.*>/\\") {
+		return fmt.Errorf("invalid consumer %q, it must not contain whitespaces, '.', '*', '>' or path separators", cfg.Consumer)
+	}
+	if cfg.Encoding == "" {
+		return errors.New("encoding must be specified")
+	}
+	return nil
+}
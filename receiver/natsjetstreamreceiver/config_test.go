@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package natsjetstreamreceiver
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+
+	internalnats "github.com/open-telemetry/opentelemetry-collector-contrib/internal/nats"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/natsjetstreamreceiver/internal/metadata"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	defaultConfig := createDefaultConfig().(*Config)
+	defaultConfig.Stream = "OTEL"
+
+	tests := []struct {
+		id          component.ID
+		expected    component.Config
+		expectedErr string
+	}{
+		{
+			id:       component.NewID(metadata.Type),
+			expected: defaultConfig,
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "all_fields"),
+			expected: &Config{
+				ClientConfig: internalnats.ClientConfig{
+					Endpoint: "tls://nats.example.com:4222",
+					TLS: &configtls.ClientConfig{
+						Config: configtls.Config{CAFile: "ca.pem"},
+					},
+					Auth: internalnats.AuthConfig{CredentialsFile: "user.creds"},
+				},
+				Stream:        "TELEMETRY",
+				BatchSize:     500,
+				AckWait:       time.Minute,
+				MaxDeliver:    10,
+				RetryDelay:    5 * time.Second,
+				MaxRetryDelay: 5 * time.Minute,
+				Traces: SignalConfig{
+					Subject:  "otlp.traces.>",
+					Consumer: "edge_traces",
+					Encoding: "otlp_json",
+				},
+				Metrics: defaultConfig.Metrics,
+				Logs: SignalConfig{
+					Subject:  "logs.>",
+					Consumer: "edge_logs",
+					Encoding: "text_encoding/utf8",
+				},
+			},
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "missing_stream"),
+			expectedErr: "stream must be specified",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "invalid"),
+			expectedErr: "batch_size must be greater than 0\n" +
+				"max_retry_delay must not be less than retry_delay\n" +
+				`traces: invalid consumer "edge.traces", it must not contain whitespaces, '.', '*', '>' or path separators` + "\n" +
+				"logs: encoding must be specified; " +
+				"only one of auth::token, auth::username or auth::credentials_file can be specified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig()
+
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, component.ValidateConfig(cfg), tt.expectedErr)
+				return
+			}
+			assert.NoError(t, component.ValidateConfig(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
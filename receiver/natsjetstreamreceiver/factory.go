@@ -0,0 +1,75 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package natsjetstreamreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/natsjetstreamreceiver"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/receiver"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/encoding"
+	internalnats "github.com/open-telemetry/opentelemetry-collector-contrib/internal/nats"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/natsjetstreamreceiver/internal/metadata"
+)
+
+const (
+	defaultEndpoint      = "nats://localhost:4222"
+	defaultBatchSize     = 100
+	defaultAckWait       = 30 * time.Second
+	defaultRetryDelay    = time.Second
+	defaultMaxRetryDelay = time.Minute
+)
+
+// NewFactory creates a factory for the NATS JetStream receiver.
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		receiver.WithTraces(createTracesReceiver, metadata.TracesStability),
+		receiver.WithMetrics(createMetricsReceiver, metadata.MetricsStability),
+		receiver.WithLogs(createLogsReceiver, metadata.LogsStability),
+	)
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		ClientConfig: internalnats.ClientConfig{
+			Endpoint: defaultEndpoint,
+		},
+		BatchSize:     defaultBatchSize,
+		AckWait:       defaultAckWait,
+		RetryDelay:    defaultRetryDelay,
+		MaxRetryDelay: defaultMaxRetryDelay,
+		Traces: SignalConfig{
+			Subject:  "otlp.traces",
+			Consumer: "otelcol_traces",
+			Encoding: encoding.OTLPProto,
+		},
+		Metrics: SignalConfig{
+			Subject:  "otlp.metrics",
+			Consumer: "otelcol_metrics",
+			Encoding: encoding.OTLPProto,
+		},
+		Logs: SignalConfig{
+			Subject:  "otlp.logs",
+			Consumer: "otelcol_logs",
+			Encoding: encoding.OTLPProto,
+		},
+	}
+}
+
+func createTracesReceiver(_ context.Context, set receiver.Settings, cfg component.Config, next consumer.Traces) (receiver.Traces, error) {
+	return newTracesReceiver(cfg.(*Config), set, next)
+}
+
+func createMetricsReceiver(_ context.Context, set receiver.Settings, cfg component.Config, next consumer.Metrics) (receiver.Metrics, error) {
+	return newMetricsReceiver(cfg.(*Config), set, next)
+}
+
+func createLogsReceiver(_ context.Context, set receiver.Settings, cfg component.Config, next consumer.Logs) (receiver.Logs, error) {
+	return newLogsReceiver(cfg.(*Config), set, next)
+}
@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package natsjetstreamreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/encoding"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.Equal(t, defaultEndpoint, cfg.Endpoint)
+	assert.Equal(t, defaultBatchSize, cfg.BatchSize)
+	assert.Equal(t, 30*time.Second, cfg.AckWait)
+	assert.Equal(t, SignalConfig{Subject: "otlp.traces", Consumer: "otelcol_traces", Encoding: encoding.OTLPProto}, cfg.Traces)
+	assert.Equal(t, SignalConfig{Subject: "otlp.metrics", Consumer: "otelcol_metrics", Encoding: encoding.OTLPProto}, cfg.Metrics)
+	assert.Equal(t, SignalConfig{Subject: "otlp.logs", Consumer: "otelcol_logs", Encoding: encoding.OTLPProto}, cfg.Logs)
+}
+
+func TestCreateReceivers(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Stream = "OTEL"
+	set := receivertest.NewNopSettings()
+
+	traces, err := factory.CreateTraces(context.Background(), set, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.NotNil(t, traces)
+
+	metrics, err := factory.CreateMetrics(context.Background(), set, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.NotNil(t, metrics)
+
+	logs, err := factory.CreateLogs(context.Background(), set, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.NotNil(t, logs)
+}
+
+func TestStartFailsWithoutServer(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "nats://127.0.0.1:1"
+	cfg.Stream = "OTEL"
+
+	r, err := NewFactory().CreateLogs(context.Background(), receivertest.NewNopSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.ErrorContains(t, r.Start(context.Background(), componenttest.NewNopHost()), "failed to connect to the NATS server")
+	assert.NoError(t, r.Shutdown(context.Background()))
+}
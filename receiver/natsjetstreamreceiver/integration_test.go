@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package natsjetstreamreceiver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func TestReceiveLogs(t *testing.T) {
+	endpoint := startNATSContainer(t)
+
+	nc, err := nats.Connect(endpoint)
+	require.NoError(t, err)
+	defer nc.Close()
+	js, err := jetstream.New(nc)
+	require.NoError(t, err)
+	_, err = js.CreateStream(context.Background(), jetstream.StreamConfig{
+		Name:     "OTEL",
+		Subjects: []string{"otlp.>"},
+	})
+	require.NoError(t, err)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = endpoint
+	cfg.Stream = "OTEL"
+	sink := &consumertest.LogsSink{}
+	r, err := NewFactory().CreateLogs(context.Background(), receivertest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, r.Shutdown(context.Background()))
+	}()
+
+	data, err := (&plog.ProtoMarshaler{}).MarshalLogs(newTestLogs())
+	require.NoError(t, err)
+	_, err = js.Publish(context.Background(), "otlp.logs", data)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 2
+	}, 10*time.Second, 100*time.Millisecond)
+
+	// The message must have been acknowledged.
+	cons, err := js.Consumer(context.Background(), "OTEL", cfg.Logs.Consumer)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		info, err := cons.Info(context.Background())
+		return err == nil && info.NumAckPending == 0 && info.NumPending == 0
+	}, 10*time.Second, 100*time.Millisecond)
+}
+
+func startNATSContainer(t *testing.T) string {
+	req := testcontainers.ContainerRequest{
+		Image:        "nats:latest",
+		Cmd:          []string{"-js"},
+		ExposedPorts: []string{"4222/tcp"},
+		WaitingFor:   wait.ForLog("Server is ready").WithStartupTimeout(time.Minute),
+	}
+	container, err := testcontainers.GenericContainer(context.Background(), testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, container.Terminate(context.Background()))
+	})
+
+	host, err := container.Host(context.Background())
+	require.NoError(t, err)
+	port, err := container.MappedPort(context.Background(), "4222")
+	require.NoError(t, err)
+	return fmt.Sprintf("nats://%s:%s", host, port.Port())
+}
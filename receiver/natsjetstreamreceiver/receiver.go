@@ -0,0 +1,224 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package natsjetstreamreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/natsjetstreamreceiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/encoding"
+	internalnats "github.com/open-telemetry/opentelemetry-collector-contrib/internal/nats"
+)
+
+const transport = "nats"
+
+// consumeFunc unmarshals the data of a message and passes it to the next consumer. It returns the number of
+// spans, data points or log records of the message.
+type consumeFunc func(ctx context.Context, data []byte) (int, error)
+
+// jetstreamReceiver consumes the messages of a signal from a durable pull consumer. Messages are acknowledged
+// once the next consumer has accepted their data, so that they are delivered again if the collector fails.
+type jetstreamReceiver struct {
+	config     *Config
+	signal     SignalConfig
+	settings   receiver.Settings
+	obsrecv    *receiverhelper.ObsReport
+	newConsume func(host component.Host) (consumeFunc, error)
+	startOp    func(context.Context) context.Context
+	endOp      func(ctx context.Context, format string, count int, err error)
+
+	consume    consumeFunc
+	conn       *nats.Conn
+	consumeCtx jetstream.ConsumeContext
+}
+
+func newJetstreamReceiver(config *Config, signal SignalConfig, set receiver.Settings) (*jetstreamReceiver, error) {
+	obsrecv, err := receiverhelper.NewObsReport(receiverhelper.ObsReportSettings{
+		ReceiverID:             set.ID,
+		Transport:              transport,
+		ReceiverCreateSettings: set,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &jetstreamReceiver{
+		config:   config,
+		signal:   signal,
+		settings: set,
+		obsrecv:  obsrecv,
+	}, nil
+}
+
+func newTracesReceiver(config *Config, set receiver.Settings, next consumer.Traces) (*jetstreamReceiver, error) {
+	r, err := newJetstreamReceiver(config, config.Traces, set)
+	if err != nil {
+		return nil, err
+	}
+	r.startOp = r.obsrecv.StartTracesOp
+	r.endOp = r.obsrecv.EndTracesOp
+	r.newConsume = func(host component.Host) (consumeFunc, error) {
+		unmarshaler, err := encoding.NewTracesUnmarshaler(config.Traces.Encoding, host)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, data []byte) (int, error) {
+			traces, err := unmarshaler.UnmarshalTraces(data)
+			if err != nil {
+				return 0, consumererror.NewPermanent(fmt.Errorf("failed to unmarshal traces: %w", err))
+			}
+			return traces.SpanCount(), next.ConsumeTraces(ctx, traces)
+		}, nil
+	}
+	return r, nil
+}
+
+func newMetricsReceiver(config *Config, set receiver.Settings, next consumer.Metrics) (*jetstreamReceiver, error) {
+	r, err := newJetstreamReceiver(config, config.Metrics, set)
+	if err != nil {
+		return nil, err
+	}
+	r.startOp = r.obsrecv.StartMetricsOp
+	r.endOp = r.obsrecv.EndMetricsOp
+	r.newConsume = func(host component.Host) (consumeFunc, error) {
+		unmarshaler, err := encoding.NewMetricsUnmarshaler(config.Metrics.Encoding, host)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, data []byte) (int, error) {
+			metrics, err := unmarshaler.UnmarshalMetrics(data)
+			if err != nil {
+				return 0, consumererror.NewPermanent(fmt.Errorf("failed to unmarshal metrics: %w", err))
+			}
+			return metrics.DataPointCount(), next.ConsumeMetrics(ctx, metrics)
+		}, nil
+	}
+	return r, nil
+}
+
+func newLogsReceiver(config *Config, set receiver.Settings, next consumer.Logs) (*jetstreamReceiver, error) {
+	r, err := newJetstreamReceiver(config, config.Logs, set)
+	if err != nil {
+		return nil, err
+	}
+	r.startOp = r.obsrecv.StartLogsOp
+	r.endOp = r.obsrecv.EndLogsOp
+	r.newConsume = func(host component.Host) (consumeFunc, error) {
+		unmarshaler, err := encoding.NewLogsUnmarshaler(config.Logs.Encoding, host)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, data []byte) (int, error) {
+			logs, err := unmarshaler.UnmarshalLogs(data)
+			if err != nil {
+				return 0, consumererror.NewPermanent(fmt.Errorf("failed to unmarshal logs: %w", err))
+			}
+			return logs.LogRecordCount(), next.ConsumeLogs(ctx, logs)
+		}, nil
+	}
+	return r, nil
+}
+
+// Start creates or updates the durable consumer, then consumes its messages.
+func (r *jetstreamReceiver) Start(ctx context.Context, host component.Host) error {
+	consume, err := r.newConsume(host)
+	if err != nil {
+		return err
+	}
+	r.consume = consume
+
+	r.conn, err = internalnats.Connect(ctx, &r.config.ClientConfig, r.settings.ID.String(), r.settings.Logger)
+	if err != nil {
+		return err
+	}
+	js, err := jetstream.New(r.conn)
+	if err != nil {
+		return fmt.Errorf("failed to create the JetStream context: %w", err)
+	}
+	cons, err := js.CreateOrUpdateConsumer(ctx, r.config.Stream, jetstream.ConsumerConfig{
+		Durable:       r.signal.Consumer,
+		FilterSubject: r.signal.Subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       r.config.AckWait,
+		MaxDeliver:    r.config.MaxDeliver,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create the consumer %q of the stream %q: %w", r.signal.Consumer, r.config.Stream, err)
+	}
+
+	r.consumeCtx, err = cons.Consume(r.handleMessage,
+		jetstream.PullMaxMessages(r.config.BatchSize),
+		jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+			r.settings.Logger.Warn("Failed to pull messages", zap.Error(err))
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to consume the messages: %w", err)
+	}
+	return nil
+}
+
+// handleMessage acknowledges the message once its data has been consumed. Messages that can't be consumed
+// are delivered again after the retry delay, unless the error is permanent.
+func (r *jetstreamReceiver) handleMessage(msg jetstream.Msg) {
+	ctx := r.startOp(context.Background())
+	count, err := r.consume(ctx, msg.Data())
+	r.endOp(ctx, r.signal.Encoding, count, err)
+
+	switch {
+	case err == nil:
+		err = msg.Ack()
+	case consumererror.IsPermanent(err):
+		r.settings.Logger.Error("Failed to consume a message, it will not be delivered again", zap.String("subject", msg.Subject()), zap.Error(err))
+		err = msg.Term()
+	default:
+		delay := r.retryDelay(msg)
+		r.settings.Logger.Warn("Failed to consume a message, it will be delivered again", zap.String("subject", msg.Subject()), zap.Duration("delay", delay), zap.Error(err))
+		err = msg.NakWithDelay(delay)
+	}
+	if err != nil && !errors.Is(err, nats.ErrConnectionClosed) {
+		r.settings.Logger.Warn("Failed to acknowledge a message", zap.String("subject", msg.Subject()), zap.Error(err))
+	}
+}
+
+// retryDelay returns the delay before a message is delivered again, which doubles with each delivery so
+// that the next consumer isn't flooded with the messages it keeps failing to accept.
+func (r *jetstreamReceiver) retryDelay(msg jetstream.Msg) time.Duration {
+	delay := r.config.RetryDelay
+	meta, err := msg.Metadata()
+	if err != nil {
+		return delay
+	}
+	for i := uint64(1); i < meta.NumDelivered && delay > 0 && delay < r.config.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, r.config.MaxRetryDelay)
+}
+
+// Shutdown stops consuming, waiting for the message being consumed, and closes the connection.
+func (r *jetstreamReceiver) Shutdown(ctx context.Context) error {
+	var err error
+	if r.consumeCtx != nil {
+		r.consumeCtx.Stop()
+		select {
+		case <-r.consumeCtx.Closed():
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	if r.conn != nil {
+		r.conn.Close()
+	}
+	return err
+}
@@ -0,0 +1,283 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package natsjetstreamreceiver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/encoding"
+)
+
+// fakeMsg records how a message has been acknowledged.
+type fakeMsg struct {
+	jetstream.Msg
+	data         []byte
+	numDelivered uint64
+	acked        bool
+	naked        bool
+	nakDelay     time.Duration
+	termed       bool
+}
+
+func (m *fakeMsg) Data() []byte    { return m.data }
+func (m *fakeMsg) Subject() string { return "otlp" }
+func (m *fakeMsg) Ack() error      { m.acked = true; return nil }
+func (m *fakeMsg) Nak() error      { m.naked = true; return nil }
+func (m *fakeMsg) Term() error     { m.termed = true; return nil }
+
+func (m *fakeMsg) NakWithDelay(delay time.Duration) error {
+	m.naked = true
+	m.nakDelay = delay
+	return nil
+}
+
+func (m *fakeMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	if m.numDelivered == 0 {
+		return nil, jetstream.ErrNotJSMessage
+	}
+	return &jetstream.MsgMetadata{NumDelivered: m.numDelivered}, nil
+}
+
+type testHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h *testHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+type textUnmarshaler struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+func (textUnmarshaler) UnmarshalLogs(data []byte) (plog.Logs, error) {
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr(string(data))
+	return logs, nil
+}
+
+func newTestLogs() plog.Logs {
+	logs := plog.NewLogs()
+	lr := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	lr.AppendEmpty().Body().SetStr("first")
+	lr.AppendEmpty().Body().SetStr("second")
+	return logs
+}
+
+func TestHandleTracesMessage(t *testing.T) {
+	traces := ptrace.NewTraces()
+	traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("span")
+	data, err := (&ptrace.ProtoMarshaler{}).MarshalTraces(traces)
+	require.NoError(t, err)
+
+	cfg := createDefaultConfig().(*Config)
+	sink := &consumertest.TracesSink{}
+	r, err := newTracesReceiver(cfg, receivertest.NewNopSettings(), sink)
+	require.NoError(t, err)
+	r.consume, err = r.newConsume(componenttest.NewNopHost())
+	require.NoError(t, err)
+
+	msg := &fakeMsg{data: data}
+	r.handleMessage(msg)
+	assert.True(t, msg.acked)
+	assert.Equal(t, 1, sink.SpanCount())
+}
+
+func TestHandleMetricsMessage(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(1)
+	data, err := (&pmetric.JSONMarshaler{}).MarshalMetrics(metrics)
+	require.NoError(t, err)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Metrics.Encoding = encoding.OTLPJSON
+	sink := &consumertest.MetricsSink{}
+	r, err := newMetricsReceiver(cfg, receivertest.NewNopSettings(), sink)
+	require.NoError(t, err)
+	r.consume, err = r.newConsume(componenttest.NewNopHost())
+	require.NoError(t, err)
+
+	msg := &fakeMsg{data: data}
+	r.handleMessage(msg)
+	assert.True(t, msg.acked)
+	assert.Equal(t, 1, sink.DataPointCount())
+}
+
+func TestHandleLogsMessage(t *testing.T) {
+	data, err := (&plog.ProtoMarshaler{}).MarshalLogs(newTestLogs())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		consumeErr error
+		wantAcked  bool
+		wantNaked  bool
+		wantDelay  time.Duration
+		wantTermed bool
+		wantCount  int
+	}{
+		{
+			name:      "consumed",
+			data:      data,
+			wantAcked: true,
+			wantCount: 2,
+		},
+		{
+			name:       "next consumer fails",
+			data:       data,
+			consumeErr: errors.New("queue is full"),
+			wantNaked:  true,
+			wantDelay:  time.Second,
+		},
+		{
+			name:       "invalid data",
+			data:       []byte("not a protobuf payload"),
+			wantTermed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &consumertest.LogsSink{}
+			var next consumer.Logs = sink
+			if tt.consumeErr != nil {
+				next = consumertest.NewErr(tt.consumeErr)
+			}
+
+			cfg := createDefaultConfig().(*Config)
+			r, err := newLogsReceiver(cfg, receivertest.NewNopSettings(), next)
+			require.NoError(t, err)
+			r.consume, err = r.newConsume(componenttest.NewNopHost())
+			require.NoError(t, err)
+
+			msg := &fakeMsg{data: tt.data}
+			r.handleMessage(msg)
+			assert.Equal(t, tt.wantAcked, msg.acked)
+			assert.Equal(t, tt.wantNaked, msg.naked)
+			assert.Equal(t, tt.wantDelay, msg.nakDelay)
+			assert.Equal(t, tt.wantTermed, msg.termed)
+			assert.Equal(t, tt.wantCount, sink.LogRecordCount())
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name         string
+		retryDelay   time.Duration
+		numDelivered uint64
+		want         time.Duration
+	}{
+		{
+			name:         "first delivery",
+			retryDelay:   time.Second,
+			numDelivered: 1,
+			want:         time.Second,
+		},
+		{
+			name:         "third delivery",
+			retryDelay:   time.Second,
+			numDelivered: 3,
+			want:         4 * time.Second,
+		},
+		{
+			name:         "maximum delay",
+			retryDelay:   time.Second,
+			numDelivered: 1000,
+			want:         time.Minute,
+		},
+		{
+			name:       "without metadata",
+			retryDelay: time.Second,
+			want:       time.Second,
+		},
+		{
+			name:         "without delay",
+			numDelivered: 1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.RetryDelay = tt.retryDelay
+			r, err := newLogsReceiver(cfg, receivertest.NewNopSettings(), consumertest.NewErr(errors.New("queue is full")))
+			require.NoError(t, err)
+			r.consume, err = r.newConsume(componenttest.NewNopHost())
+			require.NoError(t, err)
+
+			data, err := (&plog.ProtoMarshaler{}).MarshalLogs(newTestLogs())
+			require.NoError(t, err)
+			msg := &fakeMsg{data: data, numDelivered: tt.numDelivered}
+			r.handleMessage(msg)
+			assert.True(t, msg.naked)
+			assert.Equal(t, tt.want, msg.nakDelay)
+		})
+	}
+}
+
+func TestEncodingExtension(t *testing.T) {
+	id := component.MustNewID("text_encoding")
+	host := &testHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{id: textUnmarshaler{}},
+	}
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Logs.Encoding = "text_encoding"
+	sink := &consumertest.LogsSink{}
+	r, err := newLogsReceiver(cfg, receivertest.NewNopSettings(), sink)
+	require.NoError(t, err)
+	r.consume, err = r.newConsume(host)
+	require.NoError(t, err)
+
+	msg := &fakeMsg{data: []byte("hello")}
+	r.handleMessage(msg)
+	assert.True(t, msg.acked)
+	require.Len(t, sink.AllLogs(), 1)
+	assert.Equal(t, "hello", sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+}
+
+func TestEncodingErrors(t *testing.T) {
+	host := &testHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{
+			component.MustNewID("text_encoding"): textUnmarshaler{},
+		},
+	}
+
+	_, err := encoding.NewLogsUnmarshaler("1nvalid", host)
+	assert.ErrorContains(t, err, `invalid encoding "1nvalid"`)
+
+	_, err = encoding.NewLogsUnmarshaler("json_log_encoding", host)
+	assert.EqualError(t, err, `unknown encoding extension "json_log_encoding"`)
+
+	_, err = encoding.NewTracesUnmarshaler("text_encoding", host)
+	assert.EqualError(t, err, `extension "text_encoding" is not an unmarshaler`)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Logs.Encoding = "json_log_encoding"
+	r, err := newLogsReceiver(cfg, receivertest.NewNopSettings(), consumertest.NewNop())
+	require.NoError(t, err)
+	assert.EqualError(t, r.Start(context.Background(), host), `unknown encoding extension "json_log_encoding"`)
+	assert.NoError(t, r.Shutdown(context.Background()))
+}
@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package replayreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/replayreceiver"
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+const (
+	formatTypeJSON  = "json"
+	formatTypeProto = "proto"
+
+	compressionZSTD = "zstd"
+
+	// pacingOriginal replays the batches with the delays between their timestamps.
+	pacingOriginal = "original"
+	// pacingNone replays the batches as fast as possible.
+	pacingNone = "none"
+)
+
+// Config defines configuration for the replay receiver.
+type Config struct {
+	// Include is the list of glob patterns of the files to replay. The files are replayed
+	// in the lexical order of their paths.
+	Include []string `mapstructure:"include"`
+
+	// FormatType is the format the files were written with by the file exporter.
+	// Options:
+	// - json[default]: OTLP JSON, one batch per line when the files aren't compressed.
+	// - proto: OTLP binary protobuf, each batch preceded by its length.
+	FormatType string `mapstructure:"format"`
+
+	// Encoding is the encoding extension decoding the batches. If specified, it overrides
+	// the decoding of FormatType, which still defines how the batches are delimited.
+	Encoding *component.ID `mapstructure:"encoding"`
+
+	// Compression is the compression of each batch, either empty or "zstd".
+	Compression string `mapstructure:"compression"`
+
+	// Pacing defines when the batches are replayed.
+	// Options:
+	// - original[default]: with the delays between the timestamps of the batches, divided by Speed.
+	// - none: as fast as possible.
+	Pacing string `mapstructure:"pacing"`
+
+	// Speed is the factor by which the original pacing is accelerated. default is 1.
+	Speed float64 `mapstructure:"speed"`
+
+	// RewriteTimestamps shifts the timestamps of each batch so that the earliest one is the
+	// time the batch is replayed.
+	RewriteTimestamps bool `mapstructure:"rewrite_timestamps"`
+}
+
+var _ component.Config = (*Config)(nil)
+
+// Validate checks if the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	var errs error
+	if len(cfg.Include) == 0 {
+		errs = errors.Join(errs, errors.New("include must not be empty"))
+	}
+	for _, pattern := range cfg.Include {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("invalid include pattern %q: %w", pattern, err))
+		}
+	}
+	if cfg.FormatType != formatTypeJSON && cfg.FormatType != formatTypeProto {
+		errs = errors.Join(errs, fmt.Errorf("format type %q is not supported", cfg.FormatType))
+	}
+	if cfg.Compression != "" && cfg.Compression != compressionZSTD {
+		errs = errors.Join(errs, fmt.Errorf("compression %q is not supported", cfg.Compression))
+	}
+	if cfg.Pacing != pacingOriginal && cfg.Pacing != pacingNone {
+		errs = errors.Join(errs, fmt.Errorf("pacing %q is not supported", cfg.Pacing))
+	}
+	if cfg.Speed <= 0 {
+		errs = errors.Join(errs, errors.New("speed must be positive"))
+	}
+	return errs
+}
+
+// lengthPrefixed reports whether each batch is preceded by its length rather than followed
+// by a new line, as written by the file exporter.
+func (cfg *Config) lengthPrefixed() bool {
+	return cfg.FormatType == formatTypeProto || cfg.Compression != ""
+}
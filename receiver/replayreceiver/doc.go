@@ -0,0 +1,8 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mdatagen metadata.yaml
+
+// Package replayreceiver implements a receiver replaying the traces, metrics and logs of the
+// files written by the file exporter, with the pacing of their timestamps.
+package replayreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/replayreceiver"
@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package replayreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/replayreceiver"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/receiver"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/replayreceiver/internal/metadata"
+)
+
+// NewFactory creates a factory for the replay receiver.
+func NewFactory() receiver.Factory {
+	return receiver.NewFactory(
+		metadata.Type,
+		createDefaultConfig,
+		receiver.WithTraces(createTracesReceiver, metadata.TracesStability),
+		receiver.WithMetrics(createMetricsReceiver, metadata.MetricsStability),
+		receiver.WithLogs(createLogsReceiver, metadata.LogsStability))
+}
+
+func createDefaultConfig() component.Config {
+	return &Config{
+		FormatType: formatTypeJSON,
+		Pacing:     pacingOriginal,
+		Speed:      1,
+	}
+}
+
+func createTracesReceiver(_ context.Context, settings receiver.Settings, cfg component.Config, next consumer.Traces) (receiver.Traces, error) {
+	conf := cfg.(*Config)
+	var unmarshaler ptrace.Unmarshaler = &ptrace.JSONUnmarshaler{}
+	if conf.FormatType == formatTypeProto {
+		unmarshaler = &ptrace.ProtoUnmarshaler{}
+	}
+	return newReplayReceiver(settings, conf, &tracesSignal{unmarshaler: unmarshaler, next: next})
+}
+
+func createMetricsReceiver(_ context.Context, settings receiver.Settings, cfg component.Config, next consumer.Metrics) (receiver.Metrics, error) {
+	conf := cfg.(*Config)
+	var unmarshaler pmetric.Unmarshaler = &pmetric.JSONUnmarshaler{}
+	if conf.FormatType == formatTypeProto {
+		unmarshaler = &pmetric.ProtoUnmarshaler{}
+	}
+	return newReplayReceiver(settings, conf, &metricsSignal{unmarshaler: unmarshaler, next: next})
+}
+
+func createLogsReceiver(_ context.Context, settings receiver.Settings, cfg component.Config, next consumer.Logs) (receiver.Logs, error) {
+	conf := cfg.(*Config)
+	var unmarshaler plog.Unmarshaler = &plog.JSONUnmarshaler{}
+	if conf.FormatType == formatTypeProto {
+		unmarshaler = &plog.ProtoUnmarshaler{}
+	}
+	return newReplayReceiver(settings, conf, &logsSignal{unmarshaler: unmarshaler, next: next})
+}
@@ -0,0 +1,107 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package replayreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/replayreceiver"
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding"
+)
+
+// batchReader reads the batches of a file written by the file exporter.
+type batchReader struct {
+	r              *bufio.Reader
+	lengthPrefixed bool
+	// decoder decompresses the batches, nil when they aren't compressed.
+	decoder *zstd.Decoder
+}
+
+func newBatchReader(r io.Reader, lengthPrefixed bool, decoder *zstd.Decoder) *batchReader {
+	return &batchReader{
+		r:              bufio.NewReader(r),
+		lengthPrefixed: lengthPrefixed,
+		decoder:        decoder,
+	}
+}
+
+// next returns the next batch, or io.EOF when the file has no more batches.
+func (br *batchReader) next() ([]byte, error) {
+	var buf []byte
+	var err error
+	if br.lengthPrefixed {
+		buf, err = br.readLengthPrefixed()
+	} else {
+		buf, err = br.readLine()
+	}
+	if err != nil || br.decoder == nil {
+		return buf, err
+	}
+	decoded, err := br.decoder.DecodeAll(buf, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress batch: %w", err)
+	}
+	return decoded, nil
+}
+
+// readLine returns the next non empty line.
+func (br *batchReader) readLine() ([]byte, error) {
+	for {
+		line, err := br.r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(line) > 0 {
+			// The last line of the file isn't terminated.
+			err = nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return line, nil
+		}
+	}
+}
+
+// readLengthPrefixed returns the next batch preceded by its length as a big endian uint32.
+func (br *batchReader) readLengthPrefixed() ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(br.r, size[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, errors.New("truncated batch length")
+		}
+		return nil, err
+	}
+	// The buffer grows with the data actually read, so that a corrupted length doesn't allocate
+	// the full size upfront.
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, br.r, int64(binary.BigEndian.Uint32(size[:])))
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("truncated batch: read %d of %d bytes", n, binary.BigEndian.Uint32(size[:]))
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newStreamReader returns the function reading the batches of a file written with a stream encoding,
+// which are written without framing and delimited by the stream. When the file is compressed, each
+// batch is a zstd frame, so the file is decompressed as a whole.
+func newStreamReader(r io.Reader, stream encoding.UnmarshalerStream, decoder *zstd.Decoder) (func() ([]byte, error), error) {
+	if decoder != nil {
+		if err := decoder.Reset(r); err != nil {
+			return nil, err
+		}
+		r = decoder
+	}
+	br := bufio.NewReader(r)
+	return func() ([]byte, error) {
+		return stream.ReadBatch(br)
+	}, nil
+}
@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package replayreceiver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lengthPrefixed frames the batches the way the file exporter does.
+func lengthPrefixed(batches ...[]byte) []byte {
+	var buf bytes.Buffer
+	for _, batch := range batches {
+		_ = binary.Write(&buf, binary.BigEndian, uint32(len(batch)))
+		buf.Write(batch)
+	}
+	return buf.Bytes()
+}
+
+func readAll(br *batchReader) ([]string, error) {
+	var batches []string
+	for {
+		batch, err := br.next()
+		if errors.Is(err, io.EOF) {
+			return batches, nil
+		}
+		if err != nil {
+			return batches, err
+		}
+		batches = append(batches, string(batch))
+	}
+}
+
+func TestBatchReaderLines(t *testing.T) {
+	br := newBatchReader(bytes.NewBufferString("{\"a\":1}\n\n{\"b\":2}\r\n{\"c\":3}"), false, nil)
+	batches, err := readAll(br)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"a":1}`, `{"b":2}`, `{"c":3}`}, batches)
+}
+
+func TestBatchReaderLengthPrefixed(t *testing.T) {
+	br := newBatchReader(bytes.NewReader(lengthPrefixed([]byte("first"), []byte{}, []byte("second\nbatch"))), true, nil)
+	batches, err := readAll(br)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "", "second\nbatch"}, batches)
+}
+
+func TestBatchReaderZstd(t *testing.T) {
+	encoder, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	defer encoder.Close()
+	decoder, err := zstd.NewReader(nil)
+	require.NoError(t, err)
+	defer decoder.Close()
+
+	data := lengthPrefixed(encoder.EncodeAll([]byte("first"), nil), encoder.EncodeAll([]byte("second"), nil))
+	batches, err := readAll(newBatchReader(bytes.NewReader(data), true, decoder))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, batches)
+
+	_, err = readAll(newBatchReader(bytes.NewReader(lengthPrefixed([]byte("not compressed"))), true, decoder))
+	assert.ErrorContains(t, err, "failed to decompress batch")
+}
+
+func TestBatchReaderTruncated(t *testing.T) {
+	data := lengthPrefixed([]byte("first"), []byte("second"))
+
+	batches, err := readAll(newBatchReader(bytes.NewReader(data[:len(data)-2]), true, nil))
+	assert.EqualError(t, err, "truncated batch: read 4 of 6 bytes")
+	assert.Equal(t, []string{"first"}, batches)
+
+	batches, err = readAll(newBatchReader(bytes.NewReader(data[:11]), true, nil))
+	assert.EqualError(t, err, "truncated batch length")
+	assert.Equal(t, []string{"first"}, batches)
+}
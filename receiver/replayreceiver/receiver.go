@@ -0,0 +1,258 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package replayreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/replayreceiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding"
+)
+
+type replayReceiver struct {
+	cfg     *Config
+	logger  *zap.Logger
+	obsrecv *receiverhelper.ObsReport
+	signal  signal
+
+	// newStream starts the stream decoding the batches of a file, with stream encodings.
+	newStream func() encoding.UnmarshalerStream
+
+	// wait waits for d or until ctx is done, replaced in the tests.
+	wait func(ctx context.Context, d time.Duration) error
+	// now is the current time, replaced in the tests.
+	now func() time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newReplayReceiver(settings receiver.Settings, cfg *Config, s signal) (*replayReceiver, error) {
+	obsrecv, err := receiverhelper.NewObsReport(receiverhelper.ObsReportSettings{
+		ReceiverID:             settings.ID,
+		Transport:              "file",
+		ReceiverCreateSettings: settings,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &replayReceiver{
+		cfg:     cfg,
+		logger:  settings.Logger,
+		obsrecv: obsrecv,
+		signal:  s,
+		wait:    wait,
+		now:     time.Now,
+	}, nil
+}
+
+func (r *replayReceiver) Start(_ context.Context, host component.Host) error {
+	if r.cfg.Encoding != nil {
+		ext, ok := host.GetExtensions()[*r.cfg.Encoding]
+		if !ok {
+			return fmt.Errorf("unknown extension %q", r.cfg.Encoding)
+		}
+		if streamExt, ok := ext.(encoding.StreamUnmarshalerExtension); ok {
+			// The batches of each file depend on the previous batches of the file, so each file is
+			// decoded with a stream of its own.
+			r.newStream = streamExt.NewUnmarshalerStream
+			stream := r.newStream()
+			err := r.signal.useEncoding(stream)
+			if err = errors.Join(err, stream.Close()); err != nil {
+				return err
+			}
+		} else if err := r.signal.useEncoding(ext); err != nil {
+			return err
+		}
+	}
+
+	files, err := r.files()
+	if err != nil {
+		return err
+	}
+	var decoder *zstd.Decoder
+	if r.cfg.Compression == compressionZSTD {
+		if decoder, err = zstd.NewReader(nil); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		if decoder != nil {
+			defer decoder.Close()
+		}
+		r.replay(ctx, files, decoder)
+	}()
+	return nil
+}
+
+func (r *replayReceiver) Shutdown(_ context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+	<-r.done
+	return nil
+}
+
+// files returns the files matching the include patterns in lexical order, which is the order of the
+// files rotated by the file exporter.
+func (r *replayReceiver) files() ([]string, error) {
+	unique := map[string]struct{}{}
+	for _, pattern := range r.cfg.Include {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			unique[match] = struct{}{}
+		}
+	}
+	files := make([]string, 0, len(unique))
+	for file := range unique {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// replay consumes the batches of the files, with the pacing of the configuration.
+func (r *replayReceiver) replay(ctx context.Context, files []string, decoder *zstd.Decoder) {
+	if len(files) == 0 {
+		r.logger.Warn("No files match the include patterns", zap.Strings("include", r.cfg.Include))
+		return
+	}
+
+	p := pacer{cfg: r.cfg, wait: r.wait, now: r.now}
+	var count int
+	for _, file := range files {
+		n, err := r.replayFile(ctx, file, decoder, &p)
+		count += n
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			r.logger.Error("Failed to replay file", zap.String("file", file), zap.Error(err))
+		}
+	}
+	r.logger.Info("Replay completed", zap.Int("files", len(files)), zap.Int("batches", count))
+}
+
+// replayFile consumes the batches of a file, and returns how many were consumed.
+func (r *replayReceiver) replayFile(ctx context.Context, file string, decoder *zstd.Decoder, p *pacer) (int, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	next := newBatchReader(f, r.cfg.lengthPrefixed(), decoder).next
+	if r.newStream != nil {
+		stream := r.newStream()
+		defer func() {
+			if err := stream.Close(); err != nil {
+				r.logger.Warn("Failed to close stream", zap.String("file", file), zap.Error(err))
+			}
+		}()
+		if err = r.signal.useEncoding(stream); err != nil {
+			return 0, err
+		}
+		if next, err = newStreamReader(f, stream, decoder); err != nil {
+			return 0, err
+		}
+	}
+
+	var count int
+	for {
+		buf, err := next()
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+
+		b, err := r.signal.unmarshal(buf)
+		if err != nil {
+			r.logger.Error("Failed to unmarshal batch", zap.String("file", file), zap.Error(err))
+			continue
+		}
+		// The batches of the other signals of the file are decoded as empty batches.
+		if b.itemCount() == 0 {
+			continue
+		}
+
+		if err = p.pace(ctx, b); err != nil {
+			return count, err
+		}
+		if err = b.consume(ctx, r.obsrecv); err != nil {
+			r.logger.Error("Failed to consume batch", zap.String("file", file), zap.Error(err))
+		}
+		count++
+	}
+}
+
+// pacer delays the batches and rewrites their timestamps.
+type pacer struct {
+	cfg  *Config
+	wait func(ctx context.Context, d time.Duration) error
+	now  func() time.Time
+
+	// first is the earliest timestamp of the first batch with timestamps.
+	first time.Time
+	// start is the time the first batch with timestamps was replayed.
+	start time.Time
+}
+
+// pace waits until the time the batch must be replayed, measured from the replay of the first batch,
+// then rewrites its timestamps if enabled.
+func (p *pacer) pace(ctx context.Context, b batch) error {
+	ts := b.earliestTimestamp()
+	if ts == 0 {
+		return nil
+	}
+	batchTime := ts.AsTime()
+	if p.first.IsZero() {
+		p.first, p.start = batchTime, p.now()
+	} else if p.cfg.Pacing == pacingOriginal {
+		replayTime := p.start.Add(time.Duration(float64(batchTime.Sub(p.first)) / p.cfg.Speed))
+		if d := replayTime.Sub(p.now()); d > 0 {
+			if err := p.wait(ctx, d); err != nil {
+				return err
+			}
+		}
+	}
+
+	if p.cfg.RewriteTimestamps {
+		b.shiftTimestamps(p.now().Sub(batchTime))
+	}
+	return nil
+}
+
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
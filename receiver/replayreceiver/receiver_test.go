@@ -0,0 +1,366 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package replayreceiver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/encoding"
+)
+
+// fakeClock records the waits of the pacing and advances its time by them.
+type fakeClock struct {
+	current time.Time
+	waits   []time.Duration
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.current
+}
+
+func (c *fakeClock) wait(_ context.Context, d time.Duration) error {
+	c.waits = append(c.waits, d)
+	c.current = c.current.Add(d)
+	return nil
+}
+
+func newTraces(name string, offset time.Duration) ptrace.Traces {
+	td := ptrace.NewTraces()
+	span := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName(name)
+	span.SetStartTimestamp(timestamp(offset))
+	span.SetEndTimestamp(timestamp(offset + 100*time.Millisecond))
+	return td
+}
+
+func newMetrics(name string, offset time.Duration) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName(name)
+	metric.SetEmptyGauge().DataPoints().AppendEmpty().SetTimestamp(timestamp(offset))
+	return md
+}
+
+func newLogs(body string, offset time.Duration) plog.Logs {
+	ld := plog.NewLogs()
+	logRecord := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	logRecord.Body().SetStr(body)
+	logRecord.SetTimestamp(timestamp(offset))
+	return ld
+}
+
+// writeFile writes the batches to a file in the format of the file exporter.
+func writeFile(t *testing.T, path string, cfg *Config, batches ...[]byte) {
+	if cfg.Compression == compressionZSTD {
+		encoder, err := zstd.NewWriter(nil)
+		require.NoError(t, err)
+		defer encoder.Close()
+		for i, batch := range batches {
+			batches[i] = encoder.EncodeAll(batch, nil)
+		}
+	}
+
+	var data []byte
+	if cfg.lengthPrefixed() {
+		data = lengthPrefixed(batches...)
+	} else {
+		for _, batch := range batches {
+			data = append(append(data, batch...), '\n')
+		}
+	}
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func marshalTraces(t *testing.T, cfg *Config, td ptrace.Traces) []byte {
+	var marshaler ptrace.Marshaler = &ptrace.JSONMarshaler{}
+	if cfg.FormatType == formatTypeProto {
+		marshaler = &ptrace.ProtoMarshaler{}
+	}
+	buf, err := marshaler.MarshalTraces(td)
+	require.NoError(t, err)
+	return buf
+}
+
+// replay starts a receiver with a fake clock, and waits until the replay completes.
+func replay(t *testing.T, rcv component.Component, host component.Host, clock *fakeClock) {
+	t.Helper()
+	r := rcv.(*replayReceiver)
+	r.wait, r.now = clock.wait, clock.now
+	require.NoError(t, r.Start(context.Background(), host))
+	<-r.done
+	require.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestReplayTraces(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		compression string
+	}{
+		{name: "json", format: formatTypeJSON},
+		{name: "json zstd", format: formatTypeJSON, compression: compressionZSTD},
+		{name: "proto", format: formatTypeProto},
+		{name: "proto zstd", format: formatTypeProto, compression: compressionZSTD},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := createDefaultConfig().(*Config)
+			cfg.Include = []string{filepath.Join(dir, "traces*")}
+			cfg.FormatType = tt.format
+			cfg.Compression = tt.compression
+			cfg.Speed = 2
+
+			// The backups rotated by the file exporter sort before the current file.
+			writeFile(t, filepath.Join(dir, "traces-2024-12-20T10-00-01.000.json"), cfg,
+				marshalTraces(t, cfg, newTraces("first", 0)),
+				marshalTraces(t, cfg, newTraces("second", time.Second)))
+			writeFile(t, filepath.Join(dir, "traces.json"), cfg,
+				marshalTraces(t, cfg, newTraces("third", 3*time.Second)))
+
+			sink := new(consumertest.TracesSink)
+			rcv, err := NewFactory().CreateTraces(context.Background(), receivertest.NewNopSettings(), cfg, sink)
+			require.NoError(t, err)
+			clock := &fakeClock{current: time.Now()}
+			replay(t, rcv, componenttest.NewNopHost(), clock)
+
+			require.Len(t, sink.AllTraces(), 3)
+			for i, name := range []string{"first", "second", "third"} {
+				assert.Equal(t, name, sink.AllTraces()[i].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+			}
+			// The second batch is replayed 1s / 2 after the first one, the third one 3s / 2.
+			assert.Equal(t, []time.Duration{500 * time.Millisecond, time.Second}, clock.waits)
+		})
+	}
+}
+
+func TestReplayMixedSignals(t *testing.T) {
+	dir := t.TempDir()
+	cfg := createDefaultConfig().(*Config)
+	cfg.Include = []string{filepath.Join(dir, "*.json")}
+	cfg.Pacing = pacingNone
+
+	tracesBuf, err := (&ptrace.JSONMarshaler{}).MarshalTraces(newTraces("span", 0))
+	require.NoError(t, err)
+	metricsBuf, err := (&pmetric.JSONMarshaler{}).MarshalMetrics(newMetrics("metric", time.Second))
+	require.NoError(t, err)
+	logsBuf, err := (&plog.JSONMarshaler{}).MarshalLogs(newLogs("log", 2*time.Second))
+	require.NoError(t, err)
+	writeFile(t, filepath.Join(dir, "all.json"), cfg, tracesBuf, []byte("not json"), metricsBuf, logsBuf)
+
+	metricsSink := new(consumertest.MetricsSink)
+	metricsRcv, err := NewFactory().CreateMetrics(context.Background(), receivertest.NewNopSettings(), cfg, metricsSink)
+	require.NoError(t, err)
+	clock := &fakeClock{current: time.Now()}
+	replay(t, metricsRcv, componenttest.NewNopHost(), clock)
+	require.Len(t, metricsSink.AllMetrics(), 1)
+	assert.Equal(t, "metric", metricsSink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Name())
+
+	logsSink := new(consumertest.LogsSink)
+	logsRcv, err := NewFactory().CreateLogs(context.Background(), receivertest.NewNopSettings(), cfg, logsSink)
+	require.NoError(t, err)
+	replay(t, logsRcv, componenttest.NewNopHost(), clock)
+	require.Len(t, logsSink.AllLogs(), 1)
+	assert.Equal(t, "log", logsSink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+
+	assert.Empty(t, clock.waits)
+}
+
+func TestReplayRewriteTimestamps(t *testing.T) {
+	dir := t.TempDir()
+	cfg := createDefaultConfig().(*Config)
+	cfg.Include = []string{filepath.Join(dir, "traces.json")}
+	cfg.RewriteTimestamps = true
+	writeFile(t, cfg.Include[0], cfg,
+		marshalTraces(t, cfg, newTraces("first", 0)),
+		marshalTraces(t, cfg, newTraces("second", time.Minute)))
+
+	sink := new(consumertest.TracesSink)
+	rcv, err := NewFactory().CreateTraces(context.Background(), receivertest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := &fakeClock{current: now}
+	replay(t, rcv, componenttest.NewNopHost(), clock)
+
+	require.Len(t, sink.AllTraces(), 2)
+	for i, replayTime := range []time.Time{now, now.Add(time.Minute)} {
+		span := sink.AllTraces()[i].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+		assert.Equal(t, pcommon.NewTimestampFromTime(replayTime), span.StartTimestamp())
+		assert.Equal(t, pcommon.NewTimestampFromTime(replayTime.Add(100*time.Millisecond)), span.EndTimestamp())
+	}
+	assert.Equal(t, []time.Duration{time.Minute}, clock.waits)
+}
+
+// testHost is a host with extensions.
+type testHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h testHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+// testEncoding is an encoding extension unmarshaling the traces from their names.
+type testEncoding struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+func (testEncoding) UnmarshalTraces(buf []byte) (ptrace.Traces, error) {
+	return newTraces(string(buf), 0), nil
+}
+
+func TestReplayEncoding(t *testing.T) {
+	dir := t.TempDir()
+	encoding := component.MustNewID("test_encoding")
+	cfg := createDefaultConfig().(*Config)
+	cfg.Include = []string{filepath.Join(dir, "traces.pb")}
+	cfg.FormatType = formatTypeProto
+	cfg.Encoding = &encoding
+	writeFile(t, cfg.Include[0], cfg, []byte("first"), []byte("second"))
+
+	sink := new(consumertest.TracesSink)
+	rcv, err := NewFactory().CreateTraces(context.Background(), receivertest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+	host := testHost{Host: componenttest.NewNopHost(), extensions: map[component.ID]component.Component{encoding: testEncoding{}}}
+	replay(t, rcv, host, &fakeClock{current: time.Now()})
+
+	require.Len(t, sink.AllTraces(), 2)
+	assert.Equal(t, "second", sink.AllTraces()[1].ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+}
+
+func TestReplayEncodingErrors(t *testing.T) {
+	encoding := component.MustNewID("test_encoding")
+	cfg := createDefaultConfig().(*Config)
+	cfg.Include = []string{filepath.Join(t.TempDir(), "*.json")}
+	cfg.Encoding = &encoding
+
+	rcv, err := NewFactory().CreateLogs(context.Background(), receivertest.NewNopSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.EqualError(t, rcv.Start(context.Background(), componenttest.NewNopHost()), `unknown extension "test_encoding"`)
+
+	host := testHost{Host: componenttest.NewNopHost(), extensions: map[component.ID]component.Component{encoding: testEncoding{}}}
+	assert.EqualError(t, rcv.Start(context.Background(), host), "extension replayreceiver.testEncoding is not a log unmarshaler")
+	require.NoError(t, rcv.Shutdown(context.Background()))
+}
+
+// testStreamEncoding is a stream encoding unmarshaling the traces from their names, numbered in their stream.
+type testStreamEncoding struct {
+	component.StartFunc
+	component.ShutdownFunc
+	closed int
+}
+
+func (e *testStreamEncoding) NewUnmarshalerStream() encoding.UnmarshalerStream {
+	return &testStream{encoding: e}
+}
+
+type testStream struct {
+	encoding *testStreamEncoding
+	count    int
+}
+
+// ReadBatch reads the batches written one per line.
+func (s *testStream) ReadBatch(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(line, []byte("\n")), nil
+}
+
+func (s *testStream) UnmarshalTraces(buf []byte) (ptrace.Traces, error) {
+	s.count++
+	return newTraces(fmt.Sprintf("%s %d", buf, s.count), 0), nil
+}
+
+func (s *testStream) Close() error {
+	s.encoding.closed++
+	return nil
+}
+
+func TestReplayStreamEncoding(t *testing.T) {
+	for _, compression := range []string{"", compressionZSTD} {
+		t.Run("compression_"+compression, func(t *testing.T) {
+			dir := t.TempDir()
+			id := component.MustNewID("test_stream_encoding")
+			cfg := createDefaultConfig().(*Config)
+			cfg.Include = []string{filepath.Join(dir, "*.txt")}
+			cfg.Compression = compression
+			cfg.Encoding = &id
+			// the batches of stream encodings are written without framing, or as concatenated zstd frames.
+			writeStreamFile(t, filepath.Join(dir, "1.txt"), cfg, []byte("a\n"), []byte("b\n"))
+			writeStreamFile(t, filepath.Join(dir, "2.txt"), cfg, []byte("c\n"), []byte("d\n"))
+
+			sink := new(consumertest.TracesSink)
+			rcv, err := NewFactory().CreateTraces(context.Background(), receivertest.NewNopSettings(), cfg, sink)
+			require.NoError(t, err)
+			ext := &testStreamEncoding{}
+			host := testHost{Host: componenttest.NewNopHost(), extensions: map[component.ID]component.Component{id: ext}}
+			replay(t, rcv, host, &fakeClock{current: time.Now()})
+
+			// each file is decoded with a stream of its own.
+			var names []string
+			for _, td := range sink.AllTraces() {
+				names = append(names, td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+			}
+			assert.Equal(t, []string{"a 1", "b 2", "c 1", "d 2"}, names)
+			assert.Equal(t, 3, ext.closed)
+		})
+	}
+}
+
+func writeStreamFile(t *testing.T, path string, cfg *Config, batches ...[]byte) {
+	var data []byte
+	for _, batch := range batches {
+		if cfg.Compression == compressionZSTD {
+			encoder, err := zstd.NewWriter(nil)
+			require.NoError(t, err)
+			batch = encoder.EncodeAll(batch, nil)
+			require.NoError(t, encoder.Close())
+		}
+		data = append(data, batch...)
+	}
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func TestReplayShutdown(t *testing.T) {
+	dir := t.TempDir()
+	cfg := createDefaultConfig().(*Config)
+	cfg.Include = []string{filepath.Join(dir, "traces.json")}
+	writeFile(t, cfg.Include[0], cfg,
+		marshalTraces(t, cfg, newTraces("first", 0)),
+		marshalTraces(t, cfg, newTraces("second", time.Hour)))
+
+	sink := new(consumertest.TracesSink)
+	rcv, err := NewFactory().CreateTraces(context.Background(), receivertest.NewNopSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, rcv.Start(context.Background(), componenttest.NewNopHost()))
+
+	// The shutdown interrupts the wait for the second batch.
+	require.Eventually(t, func() bool { return sink.SpanCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, rcv.Shutdown(context.Background()))
+	assert.Len(t, sink.AllTraces(), 1)
+}
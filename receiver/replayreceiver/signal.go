@@ -0,0 +1,288 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package replayreceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/replayreceiver"
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/replayreceiver/internal/metadata"
+)
+
+// signal decodes and consumes the batches of a signal.
+type signal interface {
+	// useEncoding decodes the batches with an encoding extension, or a stream of a stream encoding.
+	useEncoding(ext any) error
+	unmarshal(buf []byte) (batch, error)
+}
+
+// batch is a decoded batch of a signal.
+type batch interface {
+	// itemCount is the number of spans, log records or data points of the batch.
+	itemCount() int
+	// earliestTimestamp is the earliest timestamp of the batch, 0 when it has none.
+	earliestTimestamp() pcommon.Timestamp
+	// shiftTimestamps shifts all the timestamps of the batch by d.
+	shiftTimestamps(d time.Duration)
+	consume(ctx context.Context, obsrecv *receiverhelper.ObsReport) error
+}
+
+// shift shifts a timestamp by d, unless it isn't set.
+func shift(ts pcommon.Timestamp, d time.Duration) pcommon.Timestamp {
+	if ts == 0 {
+		return 0
+	}
+	return pcommon.Timestamp(int64(ts) + int64(d))
+}
+
+// earliest returns the earliest of two timestamps, ignoring the ones which aren't set.
+func earliest(a, b pcommon.Timestamp) pcommon.Timestamp {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+type tracesSignal struct {
+	unmarshaler ptrace.Unmarshaler
+	next        consumer.Traces
+}
+
+func (s *tracesSignal) useEncoding(ext any) error {
+	unmarshaler, ok := ext.(ptrace.Unmarshaler)
+	if !ok {
+		return fmt.Errorf("extension %T is not a trace unmarshaler", ext)
+	}
+	s.unmarshaler = unmarshaler
+	return nil
+}
+
+func (s *tracesSignal) unmarshal(buf []byte) (batch, error) {
+	td, err := s.unmarshaler.UnmarshalTraces(buf)
+	return &tracesBatch{td: td, next: s.next}, err
+}
+
+type tracesBatch struct {
+	td   ptrace.Traces
+	next consumer.Traces
+}
+
+func (b *tracesBatch) itemCount() int {
+	return b.td.SpanCount()
+}
+
+func (b *tracesBatch) earliestTimestamp() pcommon.Timestamp {
+	var ts pcommon.Timestamp
+	b.forEachSpan(func(span ptrace.Span) {
+		ts = earliest(ts, span.StartTimestamp())
+	})
+	return ts
+}
+
+func (b *tracesBatch) shiftTimestamps(d time.Duration) {
+	b.forEachSpan(func(span ptrace.Span) {
+		span.SetStartTimestamp(shift(span.StartTimestamp(), d))
+		span.SetEndTimestamp(shift(span.EndTimestamp(), d))
+		for i := 0; i < span.Events().Len(); i++ {
+			event := span.Events().At(i)
+			event.SetTimestamp(shift(event.Timestamp(), d))
+		}
+	})
+}
+
+func (b *tracesBatch) forEachSpan(f func(ptrace.Span)) {
+	for i := 0; i < b.td.ResourceSpans().Len(); i++ {
+		scopeSpans := b.td.ResourceSpans().At(i).ScopeSpans()
+		for j := 0; j < scopeSpans.Len(); j++ {
+			spans := scopeSpans.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				f(spans.At(k))
+			}
+		}
+	}
+}
+
+func (b *tracesBatch) consume(ctx context.Context, obsrecv *receiverhelper.ObsReport) error {
+	ctx = obsrecv.StartTracesOp(ctx)
+	err := b.next.ConsumeTraces(ctx, b.td)
+	obsrecv.EndTracesOp(ctx, metadata.Type.String(), b.itemCount(), err)
+	return err
+}
+
+type metricsSignal struct {
+	unmarshaler pmetric.Unmarshaler
+	next        consumer.Metrics
+}
+
+func (s *metricsSignal) useEncoding(ext any) error {
+	unmarshaler, ok := ext.(pmetric.Unmarshaler)
+	if !ok {
+		return fmt.Errorf("extension %T is not a metric unmarshaler", ext)
+	}
+	s.unmarshaler = unmarshaler
+	return nil
+}
+
+func (s *metricsSignal) unmarshal(buf []byte) (batch, error) {
+	md, err := s.unmarshaler.UnmarshalMetrics(buf)
+	return &metricsBatch{md: md, next: s.next}, err
+}
+
+type metricsBatch struct {
+	md   pmetric.Metrics
+	next consumer.Metrics
+}
+
+func (b *metricsBatch) itemCount() int {
+	return b.md.DataPointCount()
+}
+
+func (b *metricsBatch) earliestTimestamp() pcommon.Timestamp {
+	var ts pcommon.Timestamp
+	b.forEachDataPoint(func(dp dataPoint, _ pmetric.ExemplarSlice) {
+		ts = earliest(ts, dp.Timestamp())
+	})
+	return ts
+}
+
+func (b *metricsBatch) shiftTimestamps(d time.Duration) {
+	b.forEachDataPoint(func(dp dataPoint, exemplars pmetric.ExemplarSlice) {
+		dp.SetStartTimestamp(shift(dp.StartTimestamp(), d))
+		dp.SetTimestamp(shift(dp.Timestamp(), d))
+		for i := 0; i < exemplars.Len(); i++ {
+			exemplar := exemplars.At(i)
+			exemplar.SetTimestamp(shift(exemplar.Timestamp(), d))
+		}
+	})
+}
+
+// dataPoint is the subset of the methods of the data points of all the metric types used by the replay.
+type dataPoint interface {
+	StartTimestamp() pcommon.Timestamp
+	SetStartTimestamp(pcommon.Timestamp)
+	Timestamp() pcommon.Timestamp
+	SetTimestamp(pcommon.Timestamp)
+}
+
+// forEachDataPoint calls f with each data point and its exemplars.
+func (b *metricsBatch) forEachDataPoint(f func(dp dataPoint, exemplars pmetric.ExemplarSlice)) {
+	for i := 0; i < b.md.ResourceMetrics().Len(); i++ {
+		scopeMetrics := b.md.ResourceMetrics().At(i).ScopeMetrics()
+		for j := 0; j < scopeMetrics.Len(); j++ {
+			metrics := scopeMetrics.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				switch metric.Type() {
+				case pmetric.MetricTypeGauge:
+					for l := 0; l < metric.Gauge().DataPoints().Len(); l++ {
+						dp := metric.Gauge().DataPoints().At(l)
+						f(dp, dp.Exemplars())
+					}
+				case pmetric.MetricTypeSum:
+					for l := 0; l < metric.Sum().DataPoints().Len(); l++ {
+						dp := metric.Sum().DataPoints().At(l)
+						f(dp, dp.Exemplars())
+					}
+				case pmetric.MetricTypeHistogram:
+					for l := 0; l < metric.Histogram().DataPoints().Len(); l++ {
+						dp := metric.Histogram().DataPoints().At(l)
+						f(dp, dp.Exemplars())
+					}
+				case pmetric.MetricTypeExponentialHistogram:
+					for l := 0; l < metric.ExponentialHistogram().DataPoints().Len(); l++ {
+						dp := metric.ExponentialHistogram().DataPoints().At(l)
+						f(dp, dp.Exemplars())
+					}
+				case pmetric.MetricTypeSummary:
+					for l := 0; l < metric.Summary().DataPoints().Len(); l++ {
+						f(metric.Summary().DataPoints().At(l), pmetric.NewExemplarSlice())
+					}
+				case pmetric.MetricTypeEmpty:
+				}
+			}
+		}
+	}
+}
+
+func (b *metricsBatch) consume(ctx context.Context, obsrecv *receiverhelper.ObsReport) error {
+	ctx = obsrecv.StartMetricsOp(ctx)
+	err := b.next.ConsumeMetrics(ctx, b.md)
+	obsrecv.EndMetricsOp(ctx, metadata.Type.String(), b.itemCount(), err)
+	return err
+}
+
+type logsSignal struct {
+	unmarshaler plog.Unmarshaler
+	next        consumer.Logs
+}
+
+func (s *logsSignal) useEncoding(ext any) error {
+	unmarshaler, ok := ext.(plog.Unmarshaler)
+	if !ok {
+		return fmt.Errorf("extension %T is not a log unmarshaler", ext)
+	}
+	s.unmarshaler = unmarshaler
+	return nil
+}
+
+func (s *logsSignal) unmarshal(buf []byte) (batch, error) {
+	ld, err := s.unmarshaler.UnmarshalLogs(buf)
+	return &logsBatch{ld: ld, next: s.next}, err
+}
+
+type logsBatch struct {
+	ld   plog.Logs
+	next consumer.Logs
+}
+
+func (b *logsBatch) itemCount() int {
+	return b.ld.LogRecordCount()
+}
+
+func (b *logsBatch) earliestTimestamp() pcommon.Timestamp {
+	var ts pcommon.Timestamp
+	b.forEachLogRecord(func(lr plog.LogRecord) {
+		// The observed timestamp is the time of the log record when it has no timestamp.
+		if lr.Timestamp() != 0 {
+			ts = earliest(ts, lr.Timestamp())
+		} else {
+			ts = earliest(ts, lr.ObservedTimestamp())
+		}
+	})
+	return ts
+}
+
+func (b *logsBatch) shiftTimestamps(d time.Duration) {
+	b.forEachLogRecord(func(lr plog.LogRecord) {
+		lr.SetTimestamp(shift(lr.Timestamp(), d))
+		lr.SetObservedTimestamp(shift(lr.ObservedTimestamp(), d))
+	})
+}
+
+func (b *logsBatch) forEachLogRecord(f func(plog.LogRecord)) {
+	for i := 0; i < b.ld.ResourceLogs().Len(); i++ {
+		scopeLogs := b.ld.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < scopeLogs.Len(); j++ {
+			logRecords := scopeLogs.At(j).LogRecords()
+			for k := 0; k < logRecords.Len(); k++ {
+				f(logRecords.At(k))
+			}
+		}
+	}
+}
+
+func (b *logsBatch) consume(ctx context.Context, obsrecv *receiverhelper.ObsReport) error {
+	ctx = obsrecv.StartLogsOp(ctx)
+	err := b.next.ConsumeLogs(ctx, b.ld)
+	obsrecv.EndLogsOp(ctx, metadata.Type.String(), b.itemCount(), err)
+	return err
+}
@@ -0,0 +1,101 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package replayreceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+var baseTime = time.Date(2024, 12, 20, 10, 0, 0, 0, time.UTC)
+
+func timestamp(offset time.Duration) pcommon.Timestamp {
+	return pcommon.NewTimestampFromTime(baseTime.Add(offset))
+}
+
+func TestTracesBatchTimestamps(t *testing.T) {
+	td := ptrace.NewTraces()
+	spans := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+	span := spans.AppendEmpty()
+	span.SetStartTimestamp(timestamp(2 * time.Second))
+	span.SetEndTimestamp(timestamp(3 * time.Second))
+	span.Events().AppendEmpty().SetTimestamp(timestamp(2500 * time.Millisecond))
+	span.Events().AppendEmpty()
+	spans.AppendEmpty().SetStartTimestamp(timestamp(time.Second))
+	spans.AppendEmpty()
+
+	b := &tracesBatch{td: td}
+	assert.Equal(t, 3, b.itemCount())
+	assert.Equal(t, timestamp(time.Second), b.earliestTimestamp())
+
+	b.shiftTimestamps(time.Hour)
+	assert.Equal(t, timestamp(time.Hour+2*time.Second), span.StartTimestamp())
+	assert.Equal(t, timestamp(time.Hour+3*time.Second), span.EndTimestamp())
+	assert.Equal(t, timestamp(time.Hour+2500*time.Millisecond), span.Events().At(0).Timestamp())
+	assert.Zero(t, span.Events().At(1).Timestamp())
+	assert.Equal(t, timestamp(time.Hour+time.Second), spans.At(1).StartTimestamp())
+	assert.Zero(t, spans.At(2).StartTimestamp())
+}
+
+func TestMetricsBatchTimestamps(t *testing.T) {
+	md := pmetric.NewMetrics()
+	metrics := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	gauge := metrics.AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty()
+	gauge.SetTimestamp(timestamp(3 * time.Second))
+	sum := metrics.AppendEmpty().SetEmptySum().DataPoints().AppendEmpty()
+	sum.SetStartTimestamp(timestamp(0))
+	sum.SetTimestamp(timestamp(2 * time.Second))
+	exemplar := sum.Exemplars().AppendEmpty()
+	exemplar.SetTimestamp(timestamp(1500 * time.Millisecond))
+	histogram := metrics.AppendEmpty().SetEmptyHistogram().DataPoints().AppendEmpty()
+	histogram.SetTimestamp(timestamp(4 * time.Second))
+	exponentialHistogram := metrics.AppendEmpty().SetEmptyExponentialHistogram().DataPoints().AppendEmpty()
+	exponentialHistogram.SetTimestamp(timestamp(5 * time.Second))
+	summary := metrics.AppendEmpty().SetEmptySummary().DataPoints().AppendEmpty()
+	summary.SetTimestamp(timestamp(6 * time.Second))
+
+	b := &metricsBatch{md: md}
+	assert.Equal(t, 5, b.itemCount())
+	// The start timestamps of the cumulative data points aren't the time of the batch.
+	assert.Equal(t, timestamp(2*time.Second), b.earliestTimestamp())
+
+	b.shiftTimestamps(-time.Second)
+	assert.Equal(t, timestamp(2*time.Second), gauge.Timestamp())
+	assert.Zero(t, gauge.StartTimestamp())
+	assert.Equal(t, timestamp(-time.Second), sum.StartTimestamp())
+	assert.Equal(t, timestamp(time.Second), sum.Timestamp())
+	assert.Equal(t, timestamp(500*time.Millisecond), exemplar.Timestamp())
+	assert.Equal(t, timestamp(3*time.Second), histogram.Timestamp())
+	assert.Equal(t, timestamp(4*time.Second), exponentialHistogram.Timestamp())
+	assert.Equal(t, timestamp(5*time.Second), summary.Timestamp())
+}
+
+func TestLogsBatchTimestamps(t *testing.T) {
+	ld := plog.NewLogs()
+	logRecords := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	withTimestamp := logRecords.AppendEmpty()
+	withTimestamp.SetTimestamp(timestamp(2 * time.Second))
+	withTimestamp.SetObservedTimestamp(timestamp(time.Minute))
+	observed := logRecords.AppendEmpty()
+	observed.SetObservedTimestamp(timestamp(3 * time.Second))
+
+	b := &logsBatch{ld: ld}
+	assert.Equal(t, 2, b.itemCount())
+	assert.Equal(t, timestamp(2*time.Second), b.earliestTimestamp())
+
+	observed.SetObservedTimestamp(timestamp(time.Second))
+	assert.Equal(t, timestamp(time.Second), b.earliestTimestamp())
+
+	b.shiftTimestamps(time.Minute)
+	assert.Equal(t, timestamp(time.Minute+2*time.Second), withTimestamp.Timestamp())
+	assert.Equal(t, timestamp(2*time.Minute), withTimestamp.ObservedTimestamp())
+	assert.Zero(t, observed.Timestamp())
+	assert.Equal(t, timestamp(time.Minute+time.Second), observed.ObservedTimestamp())
+}
@@ -13,10 +13,12 @@ import (
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/receiver"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/adapter"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/windowseventlogreceiver/internal/metadata"
 )
 
-// newFactoryAdapter creates a dummy factory for windowseventlog receiver
+// newFactoryAdapter creates a factory for windowseventlog receiver that can only
+// read exported event log files, as reading channels requires the windows event log api
 func newFactoryAdapter() receiver.Factory {
 	return receiver.NewFactory(
 		metadata.Type,
@@ -25,10 +27,13 @@ func newFactoryAdapter() receiver.Factory {
 }
 
 func createLogsReceiver(
-	_ context.Context,
-	_ receiver.Settings,
-	_ component.Config,
-	_ consumer.Logs,
+	ctx context.Context,
+	set receiver.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Logs,
 ) (receiver.Logs, error) {
-	return nil, errors.New("windows eventlog receiver is only supported on Windows")
+	if len(cfg.(*WindowsLogConfig).InputConfig.EVTX.Include) == 0 {
+		return nil, errors.New("windows eventlog receiver is only supported on Windows, except for reading evtx files")
+	}
+	return adapter.NewFactory(ReceiverType{}, metadata.LogsStability).CreateLogs(ctx, set, cfg, nextConsumer)
 }
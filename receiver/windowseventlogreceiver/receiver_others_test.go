@@ -7,6 +7,7 @@ package windowseventlogreceiver
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -30,3 +31,19 @@ func TestDefaultConfigFailure(t *testing.T) {
 	require.Nil(t, receiver, "failed to create default config")
 	require.ErrorContains(t, err, "windows eventlog receiver is only supported on Windows")
 }
+
+func TestCreateEVTXReceiver(t *testing.T) {
+	factory := newFactoryAdapter()
+	cfg := factory.CreateDefaultConfig().(*WindowsLogConfig)
+	cfg.InputConfig.EVTX.Include = []string{filepath.Join(t.TempDir(), "*.evtx")}
+
+	receiver, err := factory.CreateLogs(
+		context.Background(),
+		receivertest.NewNopSettings(),
+		cfg,
+		new(consumertest.LogsSink),
+	)
+	require.NoError(t, err)
+	require.NoError(t, receiver.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, receiver.Shutdown(context.Background()))
+}